@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package admin serves the PublisherAdmin gRPC service, which operates the
+// order event publisher of the checkout service while it runs: it reports
+// the publisher stats, pauses and resumes publishing, replays the outbox and
+// changes the schema version of the order events.
+package admin
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative adminpb/admin.proto
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/admin/adminpb"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+// SchemaVersions holds the schema version of the order events being placed.
+type SchemaVersions interface {
+	SchemaVersion() int
+	// SetSchemaVersion returns the previous version
+	SetSchemaVersion(version int) int
+}
+
+// Server implements the PublisherAdmin service on the ports of the checkout
+// service.
+type Server struct {
+	adminpb.UnimplementedPublisherAdminServer
+
+	ports       *wiring.Ports
+	versions    SchemaVersions
+	deadLetters func() (int64, error)
+	logger      *slog.Logger
+}
+
+// Compile-time check that Server implements PublisherAdminServer
+var _ adminpb.PublisherAdminServer = (*Server)(nil)
+
+// Option configures optional behavior of a Server.
+type Option func(*Server)
+
+// WithDeadLetters reports the number of messages on the dead-letter topic,
+// as returned by size. Without it, the stats report none.
+func WithDeadLetters(size func() (int64, error)) Option {
+	return func(s *Server) {
+		s.deadLetters = size
+	}
+}
+
+// NewServer creates a Server operating the publisher of ports and the schema
+// version of versions.
+func NewServer(ports *wiring.Ports, versions SchemaVersions, logger *slog.Logger, opts ...Option) *Server {
+	s := &Server{ports: ports, versions: versions, logger: logger}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Server) GetPublisherStats(ctx context.Context, _ *adminpb.GetPublisherStatsRequest) (*adminpb.PublisherStats, error) {
+	return s.stats(), nil
+}
+
+// PausePublishing fails with FailedPrecondition when the publisher has no
+// spool fallback to hold the order events.
+func (s *Server) PausePublishing(ctx context.Context, _ *adminpb.PausePublishingRequest) (*adminpb.PublisherStats, error) {
+	if err := s.ports.PausePublishing(); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to pause publishing: %v", err)
+	}
+	s.logger.WarnContext(ctx, "Paused publishing order events")
+	return s.stats(), nil
+}
+
+func (s *Server) ResumePublishing(ctx context.Context, _ *adminpb.ResumePublishingRequest) (*adminpb.PublisherStats, error) {
+	s.ports.ResumePublishing()
+	s.logger.InfoContext(ctx, "Resumed publishing order events")
+	return s.stats(), nil
+}
+
+// ReplayOutbox fails with FailedPrecondition while publishing is paused, and
+// with Unavailable when the spool cannot be replayed.
+func (s *Server) ReplayOutbox(ctx context.Context, _ *adminpb.ReplayOutboxRequest) (*adminpb.ReplayOutboxResponse, error) {
+	if s.ports.PublishingPaused() {
+		return nil, status.Error(codes.FailedPrecondition, "publishing is paused")
+	}
+	resp := &adminpb.ReplayOutboxResponse{}
+	if s.ports.Outbox != nil {
+		resp.OutboxPending = int64(s.ports.Outbox.Replay())
+	} else if s.ports.Relay != nil {
+		resp.OutboxPending = int64(s.ports.Relay.Replay())
+	}
+	if chain := s.ports.Transport(); chain != nil && chain.Replayer != nil {
+		n, err := chain.Replayer.Replay(ctx)
+		resp.SpoolReplayed = int64(n)
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to replay the spool: %v", err)
+		}
+	}
+	return resp, nil
+}
+
+// SetSchemaVersion fails with InvalidArgument for a version that does not
+// exist.
+func (s *Server) SetSchemaVersion(ctx context.Context, req *adminpb.SetSchemaVersionRequest) (*adminpb.SetSchemaVersionResponse, error) {
+	version := int(req.GetSchemaVersion())
+	if version < 1 || version > adapters.LatestSchemaVersion {
+		return nil, status.Errorf(codes.InvalidArgument, "schema_version must be between 1 and %d", adapters.LatestSchemaVersion)
+	}
+	previous := s.versions.SetSchemaVersion(version)
+	s.logger.InfoContext(ctx, "Changed the schema version of order events",
+		slog.Int("previous_schema_version", previous),
+		slog.Int("schema_version", version),
+	)
+	return &adminpb.SetSchemaVersionResponse{
+		PreviousSchemaVersion: int32(previous),
+		SchemaVersion:         int32(version),
+	}, nil
+}
+
+// stats collects the stats of the current publisher chain.
+func (s *Server) stats() *adminpb.PublisherStats {
+	stats := &adminpb.PublisherStats{
+		Publisher:     s.ports.Settings().OrderEvents.Publisher,
+		Paused:        s.ports.PublishingPaused(),
+		Healthy:       true,
+		SchemaVersion: int32(s.versions.SchemaVersion()),
+	}
+	if s.ports.Outbox != nil {
+		stats.OutboxPending = int64(s.ports.Outbox.Pending())
+	} else if s.ports.Relay != nil {
+		stats.OutboxPending = int64(s.ports.Relay.Pending())
+	}
+	if s.deadLetters != nil {
+		if size, err := s.deadLetters(); err != nil {
+			stats.DeadLetterError = err.Error()
+		} else {
+			stats.DeadLetterSize = size
+		}
+	}
+
+	chain := s.ports.Transport()
+	if chain == nil {
+		stats.Publisher = "spool"
+		stats.Healthy = false
+		return stats
+	}
+	if chain.Fallback != nil {
+		stats.Healthy = chain.Fallback.Healthy()
+	}
+	if chain.Kafka != nil {
+		stats.InFlight = chain.Kafka.Stats().InFlight
+	}
+	if chain.Regions != nil {
+		stats.Region = chain.Regions.ActiveRegion()
+	}
+	if chain.Spool != nil {
+		if depth, err := chain.Spool.Depth(); err != nil {
+			s.logger.Warn("Failed to read the spool depth", slog.String("error", err.Error()))
+		} else {
+			stats.SpoolDepth = int64(depth)
+		}
+	}
+	if failure := chain.LastFailure(); failure.Err != nil {
+		stats.LastError = failure.Err.Error()
+		stats.LastErrorCode = string(errcode.Of(failure.Err))
+		stats.LastErrorTime = timestamppb.New(failure.At)
+	}
+	return stats
+}
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package admin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/admin/adminpb"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+type schemaVersion int
+
+func (v *schemaVersion) SchemaVersion() int { return int(*v) }
+
+func (v *schemaVersion) SetSchemaVersion(version int) int {
+	previous := int(*v)
+	*v = schemaVersion(version)
+	return previous
+}
+
+// newTestServer serves the ports of a webhook publisher that cannot be
+// reached, with a spool fallback and an outbox.
+func newTestServer(t *testing.T, opts ...Option) (*Server, *wiring.Ports) {
+	t.Helper()
+	cfg := &config.Config{
+		OrderEvents: config.OrderEvents{
+			Publisher: adapters.PublisherWebhook,
+			Webhook:   config.Webhook{URLs: []string{"http://127.0.0.1:1"}},
+			Fallback:  adapters.PublisherSpool,
+			SpoolPath: filepath.Join(t.TempDir(), "orders.spool"),
+			Outbox:    true,
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := wiring.NewPorts(cfg, logger, wiring.Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	t.Cleanup(func() { p.Close(context.Background()) })
+	version := schemaVersion(1)
+	return NewServer(p, &version, logger, opts...), p
+}
+
+func TestGetPublisherStats(t *testing.T) {
+	s, p := newTestServer(t, WithDeadLetters(func() (int64, error) {
+		return 0, errors.New("broker unreachable")
+	}))
+	ctx := context.Background()
+
+	// The webhook is unreachable, so the order is spooled
+	if err := p.Transport().PublishOrderCompleted(ctx, &pb.OrderResult{OrderId: "order-1"}); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	stats, err := s.GetPublisherStats(ctx, &adminpb.GetPublisherStatsRequest{})
+	if err != nil {
+		t.Fatalf("GetPublisherStats() = %v", err)
+	}
+	if stats.GetPublisher() != adapters.PublisherWebhook || stats.GetHealthy() || stats.GetSpoolDepth() != 1 {
+		t.Errorf("stats = %v, want an unhealthy webhook publisher with 1 order spooled", stats)
+	}
+	if stats.GetLastError() == "" || stats.GetLastErrorTime() == nil {
+		t.Errorf("stats = %v, want the failed webhook publish", stats)
+	}
+	if stats.GetDeadLetterError() != "broker unreachable" || stats.GetSchemaVersion() != 1 {
+		t.Errorf("stats = %v, want the dead-letter error and schema version 1", stats)
+	}
+}
+
+func TestPauseAndResumePublishing(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+
+	stats, err := s.PausePublishing(ctx, &adminpb.PausePublishingRequest{})
+	if err != nil || !stats.GetPaused() {
+		t.Fatalf("PausePublishing() = %v, %v, want paused", stats, err)
+	}
+	if _, err := s.ReplayOutbox(ctx, &adminpb.ReplayOutboxRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ReplayOutbox() while paused = %v, want %s", err, codes.FailedPrecondition)
+	}
+
+	stats, err = s.ResumePublishing(ctx, &adminpb.ResumePublishingRequest{})
+	if err != nil || stats.GetPaused() {
+		t.Fatalf("ResumePublishing() = %v, %v, want resumed", stats, err)
+	}
+	if _, err := s.ReplayOutbox(ctx, &adminpb.ReplayOutboxRequest{}); err != nil {
+		t.Errorf("ReplayOutbox() = %v", err)
+	}
+}
+
+func TestPausePublishingWithoutSpool(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{OrderEvents: config.OrderEvents{Publisher: adapters.PublisherNoOp, Fallback: adapters.PublisherNone}}
+	p, err := wiring.NewPorts(cfg, logger, wiring.Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	defer p.Close(context.Background())
+	version := schemaVersion(1)
+	s := NewServer(p, &version, logger)
+
+	if _, err := s.PausePublishing(context.Background(), &adminpb.PausePublishingRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("PausePublishing() = %v, want %s", err, codes.FailedPrecondition)
+	}
+}
+
+func TestSetSchemaVersion(t *testing.T) {
+	s, _ := newTestServer(t)
+	ctx := context.Background()
+
+	resp, err := s.SetSchemaVersion(ctx, &adminpb.SetSchemaVersionRequest{SchemaVersion: 3})
+	if err != nil || resp.GetPreviousSchemaVersion() != 1 || resp.GetSchemaVersion() != 3 {
+		t.Errorf("SetSchemaVersion(3) = %v, %v, want 1 changed to 3", resp, err)
+	}
+	for _, version := range []int32{0, adapters.LatestSchemaVersion + 1} {
+		if _, err := s.SetSchemaVersion(ctx, &adminpb.SetSchemaVersionRequest{SchemaVersion: version}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("SetSchemaVersion(%d) = %v, want %s", version, err, codes.InvalidArgument)
+		}
+	}
+	if stats, _ := s.GetPublisherStats(ctx, &adminpb.GetPublisherStatsRequest{}); stats.GetSchemaVersion() != 3 {
+		t.Errorf("schema version = %d after the invalid changes, want 3", stats.GetSchemaVersion())
+	}
+}
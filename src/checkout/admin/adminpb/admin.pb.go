@@ -0,0 +1,577 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.4
+// source: adminpb/admin.proto
+
+package adminpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetPublisherStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPublisherStatsRequest) Reset() {
+	*x = GetPublisherStatsRequest{}
+	mi := &file_adminpb_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublisherStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublisherStatsRequest) ProtoMessage() {}
+
+func (x *GetPublisherStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublisherStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetPublisherStatsRequest) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type PausePublishingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PausePublishingRequest) Reset() {
+	*x = PausePublishingRequest{}
+	mi := &file_adminpb_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PausePublishingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PausePublishingRequest) ProtoMessage() {}
+
+func (x *PausePublishingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PausePublishingRequest.ProtoReflect.Descriptor instead.
+func (*PausePublishingRequest) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{1}
+}
+
+type ResumePublishingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumePublishingRequest) Reset() {
+	*x = ResumePublishingRequest{}
+	mi := &file_adminpb_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumePublishingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumePublishingRequest) ProtoMessage() {}
+
+func (x *ResumePublishingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumePublishingRequest.ProtoReflect.Descriptor instead.
+func (*ResumePublishingRequest) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{2}
+}
+
+type PublisherStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ORDER_EVENT_PUBLISHER kind, spool when order events are only spooled
+	Publisher string `protobuf:"bytes,1,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	Paused    bool   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	// Whether order events go to the publisher rather than its fallback
+	Healthy bool `protobuf:"varint,3,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Kafka messages queued but not acknowledged yet
+	InFlight int64 `protobuf:"varint,4,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	// Batches committed to the outbox and not published yet
+	OutboxPending int64 `protobuf:"varint,5,opt,name=outbox_pending,json=outboxPending,proto3" json:"outbox_pending,omitempty"`
+	// Order events in the spool
+	SpoolDepth int64 `protobuf:"varint,6,opt,name=spool_depth,json=spoolDepth,proto3" json:"spool_depth,omitempty"`
+	// Messages on the dead-letter topic, when dead_letter_error is empty
+	DeadLetterSize  int64  `protobuf:"varint,7,opt,name=dead_letter_size,json=deadLetterSize,proto3" json:"dead_letter_size,omitempty"`
+	DeadLetterError string `protobuf:"bytes,8,opt,name=dead_letter_error,json=deadLetterError,proto3" json:"dead_letter_error,omitempty"`
+	// The last failed publish of the current publisher, unset if none failed
+	// since it was built at startup or reloaded
+	LastError     string                 `protobuf:"bytes,9,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastErrorCode string                 `protobuf:"bytes,10,opt,name=last_error_code,json=lastErrorCode,proto3" json:"last_error_code,omitempty"`
+	LastErrorTime *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=last_error_time,json=lastErrorTime,proto3" json:"last_error_time,omitempty"`
+	SchemaVersion int32                  `protobuf:"varint,12,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// The region order events go to with KAFKA_SECONDARY_ADDR
+	Region        string `protobuf:"bytes,13,opt,name=region,proto3" json:"region,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublisherStats) Reset() {
+	*x = PublisherStats{}
+	mi := &file_adminpb_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublisherStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublisherStats) ProtoMessage() {}
+
+func (x *PublisherStats) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublisherStats.ProtoReflect.Descriptor instead.
+func (*PublisherStats) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PublisherStats) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *PublisherStats) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *PublisherStats) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *PublisherStats) GetInFlight() int64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *PublisherStats) GetOutboxPending() int64 {
+	if x != nil {
+		return x.OutboxPending
+	}
+	return 0
+}
+
+func (x *PublisherStats) GetSpoolDepth() int64 {
+	if x != nil {
+		return x.SpoolDepth
+	}
+	return 0
+}
+
+func (x *PublisherStats) GetDeadLetterSize() int64 {
+	if x != nil {
+		return x.DeadLetterSize
+	}
+	return 0
+}
+
+func (x *PublisherStats) GetDeadLetterError() string {
+	if x != nil {
+		return x.DeadLetterError
+	}
+	return ""
+}
+
+func (x *PublisherStats) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *PublisherStats) GetLastErrorCode() string {
+	if x != nil {
+		return x.LastErrorCode
+	}
+	return ""
+}
+
+func (x *PublisherStats) GetLastErrorTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastErrorTime
+	}
+	return nil
+}
+
+func (x *PublisherStats) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *PublisherStats) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+type ReplayOutboxRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayOutboxRequest) Reset() {
+	*x = ReplayOutboxRequest{}
+	mi := &file_adminpb_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayOutboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayOutboxRequest) ProtoMessage() {}
+
+func (x *ReplayOutboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayOutboxRequest.ProtoReflect.Descriptor instead.
+func (*ReplayOutboxRequest) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{4}
+}
+
+type ReplayOutboxResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Batches in the outbox when the replay was triggered
+	OutboxPending int64 `protobuf:"varint,1,opt,name=outbox_pending,json=outboxPending,proto3" json:"outbox_pending,omitempty"`
+	// Order events replayed from the spool
+	SpoolReplayed int64 `protobuf:"varint,2,opt,name=spool_replayed,json=spoolReplayed,proto3" json:"spool_replayed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayOutboxResponse) Reset() {
+	*x = ReplayOutboxResponse{}
+	mi := &file_adminpb_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayOutboxResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayOutboxResponse) ProtoMessage() {}
+
+func (x *ReplayOutboxResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayOutboxResponse.ProtoReflect.Descriptor instead.
+func (*ReplayOutboxResponse) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReplayOutboxResponse) GetOutboxPending() int64 {
+	if x != nil {
+		return x.OutboxPending
+	}
+	return 0
+}
+
+func (x *ReplayOutboxResponse) GetSpoolReplayed() int64 {
+	if x != nil {
+		return x.SpoolReplayed
+	}
+	return 0
+}
+
+type SetSchemaVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion int32                  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSchemaVersionRequest) Reset() {
+	*x = SetSchemaVersionRequest{}
+	mi := &file_adminpb_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSchemaVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSchemaVersionRequest) ProtoMessage() {}
+
+func (x *SetSchemaVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSchemaVersionRequest.ProtoReflect.Descriptor instead.
+func (*SetSchemaVersionRequest) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetSchemaVersionRequest) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+type SetSchemaVersionResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	PreviousSchemaVersion int32                  `protobuf:"varint,1,opt,name=previous_schema_version,json=previousSchemaVersion,proto3" json:"previous_schema_version,omitempty"`
+	SchemaVersion         int32                  `protobuf:"varint,2,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SetSchemaVersionResponse) Reset() {
+	*x = SetSchemaVersionResponse{}
+	mi := &file_adminpb_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSchemaVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSchemaVersionResponse) ProtoMessage() {}
+
+func (x *SetSchemaVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_adminpb_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSchemaVersionResponse.ProtoReflect.Descriptor instead.
+func (*SetSchemaVersionResponse) Descriptor() ([]byte, []int) {
+	return file_adminpb_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetSchemaVersionResponse) GetPreviousSchemaVersion() int32 {
+	if x != nil {
+		return x.PreviousSchemaVersion
+	}
+	return 0
+}
+
+func (x *SetSchemaVersionResponse) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+var File_adminpb_admin_proto protoreflect.FileDescriptor
+
+const file_adminpb_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x13adminpb/admin.proto\x12\x0eoteldemo.admin\x1a\x1fgoogle/protobuf/timestamp.proto\"\x1a\n" +
+	"\x18GetPublisherStatsRequest\"\x18\n" +
+	"\x16PausePublishingRequest\"\x19\n" +
+	"\x17ResumePublishingRequest\"\xe5\x03\n" +
+	"\x0ePublisherStats\x12\x1c\n" +
+	"\tpublisher\x18\x01 \x01(\tR\tpublisher\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\x12\x18\n" +
+	"\ahealthy\x18\x03 \x01(\bR\ahealthy\x12\x1b\n" +
+	"\tin_flight\x18\x04 \x01(\x03R\binFlight\x12%\n" +
+	"\x0eoutbox_pending\x18\x05 \x01(\x03R\routboxPending\x12\x1f\n" +
+	"\vspool_depth\x18\x06 \x01(\x03R\n" +
+	"spoolDepth\x12(\n" +
+	"\x10dead_letter_size\x18\a \x01(\x03R\x0edeadLetterSize\x12*\n" +
+	"\x11dead_letter_error\x18\b \x01(\tR\x0fdeadLetterError\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\t \x01(\tR\tlastError\x12&\n" +
+	"\x0flast_error_code\x18\n" +
+	" \x01(\tR\rlastErrorCode\x12B\n" +
+	"\x0flast_error_time\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\rlastErrorTime\x12%\n" +
+	"\x0eschema_version\x18\f \x01(\x05R\rschemaVersion\x12\x16\n" +
+	"\x06region\x18\r \x01(\tR\x06region\"\x15\n" +
+	"\x13ReplayOutboxRequest\"d\n" +
+	"\x14ReplayOutboxResponse\x12%\n" +
+	"\x0eoutbox_pending\x18\x01 \x01(\x03R\routboxPending\x12%\n" +
+	"\x0espool_replayed\x18\x02 \x01(\x03R\rspoolReplayed\"@\n" +
+	"\x17SetSchemaVersionRequest\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\x05R\rschemaVersion\"y\n" +
+	"\x18SetSchemaVersionResponse\x126\n" +
+	"\x17previous_schema_version\x18\x01 \x01(\x05R\x15previousSchemaVersion\x12%\n" +
+	"\x0eschema_version\x18\x02 \x01(\x05R\rschemaVersion2\xe9\x03\n" +
+	"\x0ePublisherAdmin\x12]\n" +
+	"\x11GetPublisherStats\x12(.oteldemo.admin.GetPublisherStatsRequest\x1a\x1e.oteldemo.admin.PublisherStats\x12Y\n" +
+	"\x0fPausePublishing\x12&.oteldemo.admin.PausePublishingRequest\x1a\x1e.oteldemo.admin.PublisherStats\x12[\n" +
+	"\x10ResumePublishing\x12'.oteldemo.admin.ResumePublishingRequest\x1a\x1e.oteldemo.admin.PublisherStats\x12Y\n" +
+	"\fReplayOutbox\x12#.oteldemo.admin.ReplayOutboxRequest\x1a$.oteldemo.admin.ReplayOutboxResponse\x12e\n" +
+	"\x10SetSchemaVersion\x12'.oteldemo.admin.SetSchemaVersionRequest\x1a(.oteldemo.admin.SetSchemaVersionResponseBIZGgithub.com/open-telemetry/opentelemetry-demo/src/checkout/admin/adminpbb\x06proto3"
+
+var (
+	file_adminpb_admin_proto_rawDescOnce sync.Once
+	file_adminpb_admin_proto_rawDescData []byte
+)
+
+func file_adminpb_admin_proto_rawDescGZIP() []byte {
+	file_adminpb_admin_proto_rawDescOnce.Do(func() {
+		file_adminpb_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_adminpb_admin_proto_rawDesc), len(file_adminpb_admin_proto_rawDesc)))
+	})
+	return file_adminpb_admin_proto_rawDescData
+}
+
+var file_adminpb_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_adminpb_admin_proto_goTypes = []any{
+	(*GetPublisherStatsRequest)(nil), // 0: oteldemo.admin.GetPublisherStatsRequest
+	(*PausePublishingRequest)(nil),   // 1: oteldemo.admin.PausePublishingRequest
+	(*ResumePublishingRequest)(nil),  // 2: oteldemo.admin.ResumePublishingRequest
+	(*PublisherStats)(nil),           // 3: oteldemo.admin.PublisherStats
+	(*ReplayOutboxRequest)(nil),      // 4: oteldemo.admin.ReplayOutboxRequest
+	(*ReplayOutboxResponse)(nil),     // 5: oteldemo.admin.ReplayOutboxResponse
+	(*SetSchemaVersionRequest)(nil),  // 6: oteldemo.admin.SetSchemaVersionRequest
+	(*SetSchemaVersionResponse)(nil), // 7: oteldemo.admin.SetSchemaVersionResponse
+	(*timestamppb.Timestamp)(nil),    // 8: google.protobuf.Timestamp
+}
+var file_adminpb_admin_proto_depIdxs = []int32{
+	8, // 0: oteldemo.admin.PublisherStats.last_error_time:type_name -> google.protobuf.Timestamp
+	0, // 1: oteldemo.admin.PublisherAdmin.GetPublisherStats:input_type -> oteldemo.admin.GetPublisherStatsRequest
+	1, // 2: oteldemo.admin.PublisherAdmin.PausePublishing:input_type -> oteldemo.admin.PausePublishingRequest
+	2, // 3: oteldemo.admin.PublisherAdmin.ResumePublishing:input_type -> oteldemo.admin.ResumePublishingRequest
+	4, // 4: oteldemo.admin.PublisherAdmin.ReplayOutbox:input_type -> oteldemo.admin.ReplayOutboxRequest
+	6, // 5: oteldemo.admin.PublisherAdmin.SetSchemaVersion:input_type -> oteldemo.admin.SetSchemaVersionRequest
+	3, // 6: oteldemo.admin.PublisherAdmin.GetPublisherStats:output_type -> oteldemo.admin.PublisherStats
+	3, // 7: oteldemo.admin.PublisherAdmin.PausePublishing:output_type -> oteldemo.admin.PublisherStats
+	3, // 8: oteldemo.admin.PublisherAdmin.ResumePublishing:output_type -> oteldemo.admin.PublisherStats
+	5, // 9: oteldemo.admin.PublisherAdmin.ReplayOutbox:output_type -> oteldemo.admin.ReplayOutboxResponse
+	7, // 10: oteldemo.admin.PublisherAdmin.SetSchemaVersion:output_type -> oteldemo.admin.SetSchemaVersionResponse
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_adminpb_admin_proto_init() }
+func file_adminpb_admin_proto_init() {
+	if File_adminpb_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_adminpb_admin_proto_rawDesc), len(file_adminpb_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_adminpb_admin_proto_goTypes,
+		DependencyIndexes: file_adminpb_admin_proto_depIdxs,
+		MessageInfos:      file_adminpb_admin_proto_msgTypes,
+	}.Build()
+	File_adminpb_admin_proto = out.File
+	file_adminpb_admin_proto_goTypes = nil
+	file_adminpb_admin_proto_depIdxs = nil
+}
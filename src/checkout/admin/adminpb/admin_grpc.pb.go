@@ -0,0 +1,302 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.4
+// source: adminpb/admin.proto
+
+package adminpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PublisherAdmin_GetPublisherStats_FullMethodName = "/oteldemo.admin.PublisherAdmin/GetPublisherStats"
+	PublisherAdmin_PausePublishing_FullMethodName   = "/oteldemo.admin.PublisherAdmin/PausePublishing"
+	PublisherAdmin_ResumePublishing_FullMethodName  = "/oteldemo.admin.PublisherAdmin/ResumePublishing"
+	PublisherAdmin_ReplayOutbox_FullMethodName      = "/oteldemo.admin.PublisherAdmin/ReplayOutbox"
+	PublisherAdmin_SetSchemaVersion_FullMethodName  = "/oteldemo.admin.PublisherAdmin/SetSchemaVersion"
+)
+
+// PublisherAdminClient is the client API for PublisherAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PublisherAdmin operates the order event publisher of the checkout service
+// while it runs. It is served on CHECKOUT_ADMIN_ADDR only, never on the port
+// of the CheckoutService.
+type PublisherAdminClient interface {
+	// GetPublisherStats returns the state of the publisher.
+	GetPublisherStats(ctx context.Context, in *GetPublisherStatsRequest, opts ...grpc.CallOption) (*PublisherStats, error)
+	// PausePublishing holds order events until publishing resumes: the
+	// publisher spools them and the outbox keeps its batches.
+	PausePublishing(ctx context.Context, in *PausePublishingRequest, opts ...grpc.CallOption) (*PublisherStats, error)
+	// ResumePublishing publishes order events again, and the ones held while
+	// paused.
+	ResumePublishing(ctx context.Context, in *ResumePublishingRequest, opts ...grpc.CallOption) (*PublisherStats, error)
+	// ReplayOutbox publishes the batches waiting in the outbox now, instead
+	// of after their retry interval, and replays the spool to the publisher.
+	ReplayOutbox(ctx context.Context, in *ReplayOutboxRequest, opts ...grpc.CallOption) (*ReplayOutboxResponse, error)
+	// SetSchemaVersion changes the schema version of the order events placed
+	// from now on.
+	SetSchemaVersion(ctx context.Context, in *SetSchemaVersionRequest, opts ...grpc.CallOption) (*SetSchemaVersionResponse, error)
+}
+
+type publisherAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPublisherAdminClient(cc grpc.ClientConnInterface) PublisherAdminClient {
+	return &publisherAdminClient{cc}
+}
+
+func (c *publisherAdminClient) GetPublisherStats(ctx context.Context, in *GetPublisherStatsRequest, opts ...grpc.CallOption) (*PublisherStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublisherStats)
+	err := c.cc.Invoke(ctx, PublisherAdmin_GetPublisherStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *publisherAdminClient) PausePublishing(ctx context.Context, in *PausePublishingRequest, opts ...grpc.CallOption) (*PublisherStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublisherStats)
+	err := c.cc.Invoke(ctx, PublisherAdmin_PausePublishing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *publisherAdminClient) ResumePublishing(ctx context.Context, in *ResumePublishingRequest, opts ...grpc.CallOption) (*PublisherStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublisherStats)
+	err := c.cc.Invoke(ctx, PublisherAdmin_ResumePublishing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *publisherAdminClient) ReplayOutbox(ctx context.Context, in *ReplayOutboxRequest, opts ...grpc.CallOption) (*ReplayOutboxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayOutboxResponse)
+	err := c.cc.Invoke(ctx, PublisherAdmin_ReplayOutbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *publisherAdminClient) SetSchemaVersion(ctx context.Context, in *SetSchemaVersionRequest, opts ...grpc.CallOption) (*SetSchemaVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSchemaVersionResponse)
+	err := c.cc.Invoke(ctx, PublisherAdmin_SetSchemaVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PublisherAdminServer is the server API for PublisherAdmin service.
+// All implementations must embed UnimplementedPublisherAdminServer
+// for forward compatibility.
+//
+// PublisherAdmin operates the order event publisher of the checkout service
+// while it runs. It is served on CHECKOUT_ADMIN_ADDR only, never on the port
+// of the CheckoutService.
+type PublisherAdminServer interface {
+	// GetPublisherStats returns the state of the publisher.
+	GetPublisherStats(context.Context, *GetPublisherStatsRequest) (*PublisherStats, error)
+	// PausePublishing holds order events until publishing resumes: the
+	// publisher spools them and the outbox keeps its batches.
+	PausePublishing(context.Context, *PausePublishingRequest) (*PublisherStats, error)
+	// ResumePublishing publishes order events again, and the ones held while
+	// paused.
+	ResumePublishing(context.Context, *ResumePublishingRequest) (*PublisherStats, error)
+	// ReplayOutbox publishes the batches waiting in the outbox now, instead
+	// of after their retry interval, and replays the spool to the publisher.
+	ReplayOutbox(context.Context, *ReplayOutboxRequest) (*ReplayOutboxResponse, error)
+	// SetSchemaVersion changes the schema version of the order events placed
+	// from now on.
+	SetSchemaVersion(context.Context, *SetSchemaVersionRequest) (*SetSchemaVersionResponse, error)
+	mustEmbedUnimplementedPublisherAdminServer()
+}
+
+// UnimplementedPublisherAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPublisherAdminServer struct{}
+
+func (UnimplementedPublisherAdminServer) GetPublisherStats(context.Context, *GetPublisherStatsRequest) (*PublisherStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublisherStats not implemented")
+}
+func (UnimplementedPublisherAdminServer) PausePublishing(context.Context, *PausePublishingRequest) (*PublisherStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PausePublishing not implemented")
+}
+func (UnimplementedPublisherAdminServer) ResumePublishing(context.Context, *ResumePublishingRequest) (*PublisherStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumePublishing not implemented")
+}
+func (UnimplementedPublisherAdminServer) ReplayOutbox(context.Context, *ReplayOutboxRequest) (*ReplayOutboxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayOutbox not implemented")
+}
+func (UnimplementedPublisherAdminServer) SetSchemaVersion(context.Context, *SetSchemaVersionRequest) (*SetSchemaVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSchemaVersion not implemented")
+}
+func (UnimplementedPublisherAdminServer) mustEmbedUnimplementedPublisherAdminServer() {}
+func (UnimplementedPublisherAdminServer) testEmbeddedByValue()                        {}
+
+// UnsafePublisherAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PublisherAdminServer will
+// result in compilation errors.
+type UnsafePublisherAdminServer interface {
+	mustEmbedUnimplementedPublisherAdminServer()
+}
+
+func RegisterPublisherAdminServer(s grpc.ServiceRegistrar, srv PublisherAdminServer) {
+	// If the following call pancis, it indicates UnimplementedPublisherAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PublisherAdmin_ServiceDesc, srv)
+}
+
+func _PublisherAdmin_GetPublisherStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublisherStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublisherAdminServer).GetPublisherStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PublisherAdmin_GetPublisherStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublisherAdminServer).GetPublisherStats(ctx, req.(*GetPublisherStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PublisherAdmin_PausePublishing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PausePublishingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublisherAdminServer).PausePublishing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PublisherAdmin_PausePublishing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublisherAdminServer).PausePublishing(ctx, req.(*PausePublishingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PublisherAdmin_ResumePublishing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumePublishingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublisherAdminServer).ResumePublishing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PublisherAdmin_ResumePublishing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublisherAdminServer).ResumePublishing(ctx, req.(*ResumePublishingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PublisherAdmin_ReplayOutbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayOutboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublisherAdminServer).ReplayOutbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PublisherAdmin_ReplayOutbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublisherAdminServer).ReplayOutbox(ctx, req.(*ReplayOutboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PublisherAdmin_SetSchemaVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSchemaVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublisherAdminServer).SetSchemaVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PublisherAdmin_SetSchemaVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublisherAdminServer).SetSchemaVersion(ctx, req.(*SetSchemaVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PublisherAdmin_ServiceDesc is the grpc.ServiceDesc for PublisherAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PublisherAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "oteldemo.admin.PublisherAdmin",
+	HandlerType: (*PublisherAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPublisherStats",
+			Handler:    _PublisherAdmin_GetPublisherStats_Handler,
+		},
+		{
+			MethodName: "PausePublishing",
+			Handler:    _PublisherAdmin_PausePublishing_Handler,
+		},
+		{
+			MethodName: "ResumePublishing",
+			Handler:    _PublisherAdmin_ResumePublishing_Handler,
+		},
+		{
+			MethodName: "ReplayOutbox",
+			Handler:    _PublisherAdmin_ReplayOutbox_Handler,
+		},
+		{
+			MethodName: "SetSchemaVersion",
+			Handler:    _PublisherAdmin_SetSchemaVersion_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "adminpb/admin.proto",
+}
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package backfill republishes historical orders from an order repository
+// through an order event publisher, for onboarding consumers that need the
+// order history. Republished events carry the adapters.BackfillHeader header
+// set to true.
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// defaultPageSize is the number of orders read from the repository at once.
+const defaultPageSize = 100
+
+// UserLister is implemented by repositories that can list the users with
+// orders, which lets a job backfill every user.
+type UserLister interface {
+	Users(ctx context.Context) ([]string, error)
+}
+
+// Job republishes the orders of a repository.
+type Job struct {
+	Repository ports.OrderRepository
+	Publisher  ports.OrderEventPublisher
+	Logger     *slog.Logger
+	// PageSize is the number of orders read at once, 100 when unset
+	PageSize int
+}
+
+// Result is the outcome of a backfill.
+type Result struct {
+	Users     int `json:"users"`
+	Published int `json:"published"`
+	// Failed are the IDs of the orders that could not be republished
+	Failed []string `json:"failed,omitempty"`
+}
+
+// Run republishes the orders of users, oldest first for each user. Without
+// users, it backfills every user of a repository that implements UserLister.
+// An order that fails to publish is recorded in Result.Failed and the backfill
+// goes on; reading the repository failing or ctx ending stops it.
+func (j *Job) Run(ctx context.Context, users []string) (Result, error) {
+	ctx, span := otel.Tracer("checkout-backfill").Start(ctx, "backfill orders")
+	defer span.End()
+
+	var result Result
+	if len(users) == 0 {
+		lister, ok := j.Repository.(UserLister)
+		if !ok {
+			return result, errors.New("the order repository cannot list users, name them instead")
+		}
+		var err error
+		if users, err = lister.Users(ctx); err != nil {
+			return result, fmt.Errorf("failed to list users: %w", err)
+		}
+	}
+
+	ctx = adapters.WithMessageHeaders(ctx, map[string]string{adapters.BackfillHeader: "true"})
+	for _, userID := range users {
+		orders, err := j.history(ctx, userID)
+		if err != nil {
+			return result, err
+		}
+		result.Users++
+		for _, order := range orders {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if err := j.Publisher.PublishOrderCompleted(ctx, order); err != nil {
+				j.Logger.WarnContext(ctx, "Failed to backfill order",
+					slog.String("order_id", order.GetOrderId()),
+					slog.String("error", err.Error()),
+					errcode.Attr(err),
+				)
+				result.Failed = append(result.Failed, order.GetOrderId())
+				continue
+			}
+			result.Published++
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("app.backfill.users", result.Users),
+		attribute.Int("app.backfill.published", result.Published),
+		attribute.Int("app.backfill.failed", len(result.Failed)),
+	)
+	j.Logger.InfoContext(ctx, "Backfilled orders",
+		slog.Int("users", result.Users),
+		slog.Int("published", result.Published),
+		slog.Int("failed", len(result.Failed)),
+	)
+	return result, nil
+}
+
+// ServeHTTP runs the job on POST, for the users named by the user_id query
+// parameters or for every user, and reports its Result.
+func (j *Job) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	result, err := j.Run(r.Context(), r.URL.Query()["user_id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// history returns every order of userID, oldest first.
+func (j *Job) history(ctx context.Context, userID string) ([]*pb.OrderResult, error) {
+	pageSize := j.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	var orders []*pb.OrderResult
+	token := ""
+	for {
+		page, next, err := j.Repository.List(ctx, userID, pageSize, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the orders of %s: %w", userID, err)
+		}
+		orders = append(orders, page...)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	slices.Reverse(orders)
+	return orders, nil
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// recordingPublisher records the orders it publishes with their headers, and
+// fails the orders in fail.
+type recordingPublisher struct {
+	orders  []string
+	headers []map[string]string
+	fail    map[string]bool
+}
+
+func (r *recordingPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if r.fail[order.OrderId] {
+		return errors.New("broker unavailable")
+	}
+	r.orders = append(r.orders, order.OrderId)
+	r.headers = append(r.headers, adapters.MessageHeaders(ctx))
+	return nil
+}
+
+// listOnly hides the UserLister of a repository.
+type listOnly struct{ ports.OrderRepository }
+
+func testJob(t *testing.T, publisher ports.OrderEventPublisher) *Job {
+	t.Helper()
+	repo := adapters.NewInMemoryOrderRepository(10)
+	for _, o := range []struct{ user, order string }{
+		{"user-1", "order-1"}, {"user-2", "order-2"}, {"user-1", "order-3"}, {"user-1", "order-4"},
+	} {
+		if err := repo.Save(context.Background(), o.user, &pb.OrderResult{OrderId: o.order}); err != nil {
+			t.Fatalf("Save() = %v", err)
+		}
+	}
+	return &Job{
+		Repository: repo,
+		Publisher:  publisher,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		PageSize:   2,
+	}
+}
+
+func TestJobRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		users []string
+		fail  map[string]bool
+		want  Result
+		order []string
+	}{
+		{
+			name:  "every user",
+			want:  Result{Users: 2, Published: 4},
+			order: []string{"order-1", "order-3", "order-4", "order-2"},
+		},
+		{
+			name:  "named users",
+			users: []string{"user-2"},
+			want:  Result{Users: 1, Published: 1},
+			order: []string{"order-2"},
+		},
+		{
+			name:  "failed orders",
+			users: []string{"user-1"},
+			fail:  map[string]bool{"order-3": true},
+			want:  Result{Users: 1, Published: 2, Failed: []string{"order-3"}},
+			order: []string{"order-1", "order-4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{fail: tt.fail}
+			got, err := testJob(t, publisher).Run(context.Background(), tt.users)
+			if err != nil {
+				t.Fatalf("Run() = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(publisher.orders, tt.order) {
+				t.Errorf("published %v, want %v", publisher.orders, tt.order)
+			}
+			for i, headers := range publisher.headers {
+				if headers[adapters.BackfillHeader] != "true" {
+					t.Errorf("order %s headers = %v, want %s=true", publisher.orders[i], headers, adapters.BackfillHeader)
+				}
+			}
+		})
+	}
+}
+
+func TestJobRunWithoutUserLister(t *testing.T) {
+	job := testJob(t, &recordingPublisher{})
+	job.Repository = listOnly{job.Repository}
+
+	if _, err := job.Run(context.Background(), nil); err == nil {
+		t.Error("Run() without users on a repository that cannot list them = nil, want an error")
+	}
+	if got, err := job.Run(context.Background(), []string{"user-2"}); err != nil || got.Published != 1 {
+		t.Errorf("Run(user-2) = %+v, %v; want 1 order published", got, err)
+	}
+}
+
+func TestJobServeHTTP(t *testing.T) {
+	publisher := &recordingPublisher{}
+	srv := httptest.NewServer(testJob(t, publisher))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	resp, err = http.Post(srv.URL+"?user_id=user-1&user_id=user-2", "", nil)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer resp.Body.Close()
+	var got Result
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if want := (Result{Users: 2, Published: 4}); !reflect.DeepEqual(got, want) {
+		t.Errorf("POST = %+v, want %+v", got, want)
+	}
+}
@@ -27,7 +27,7 @@ func TestCheckoutServiceMessageProvider_Legacy(t *testing.T) {
 
 // TestOrderResultMessageGeneration_Legacy is a no-op placeholder for the original test.
 func TestOrderResultMessageGeneration_Legacy(t *testing.T) {
-	t.Skip("Legacy test - superseded by port-based contract tests in order_event_publisher_contract_test.go")
+	t.Skip("Legacy test - superseded by TestOrderResultMessageGeneration in order_result_message_test.go")
 }
 
 // TestOrderResultCreationFromActualBusinessLogic_Legacy is a no-op placeholder for the original test.
@@ -71,12 +71,12 @@ func createOrderResultFromBusinessLogic() *pb.OrderResult {
 
 func fixUnitsFieldsToIntegers(jsonObj map[string]interface{}) {
     // Original protobuf serialization fix
-    // Now renamed to fixProtobufSerializationIssues()
+    // Now lives in serialization.ToConsumerJSON()
 }
 
 func TestOrderResultMessageGeneration(t *testing.T) {
-    // Original message generation test
-    // Now covered by port-based testing approach
+    // Original message generation test, which checked that the JSON contained data
+    // Now a field-by-field comparison in order_result_message_test.go
 }
 
 func TestOrderResultCreationFromActualBusinessLogic(t *testing.T) {
@@ -93,7 +93,7 @@ ORIGINAL IMPORTS (for reference):
 	"github.com/pact-foundation/pact-go/v2/provider"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
-	pb "github.com/open-telemetry/opentelemetry-demo/src/checkout/genproto/oteldemo"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
 
 ORIGINAL IMPLEMENTATION CODE:
 [Several hundred lines of legacy test implementation code preserved for historical reference]
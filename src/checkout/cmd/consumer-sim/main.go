@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command consumer-sim plays the consumers of the order topics against a
+// running stack. It subscribes to the topics, decodes each order event into
+// the JSON consumers read, and checks it against the message interactions the
+// consumers recorded in pact files, with their matchers. It prints one line
+// per message and the violations of each consumer it breaks, and exits 1 on
+// interrupt if any message broke a contract.
+//
+// A consumer is satisfied by a message that satisfies one of its interactions
+// for the message's event type. Otherwise the violations of the interaction
+// it came closest to are printed.
+//
+// Usage:
+//
+//	KAFKA_ADDR=localhost:9092 go run ./cmd/consumer-sim -pacts 'pacts/*.json' -from-beginning
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+)
+
+func main() {
+	pacts := flag.String("pacts", "pacts/*.json", "comma-separated globs of the pact files to check against")
+	topics := flag.String("topics", kafka.Topic+","+kafka.EventsTopic, "comma-separated topics to subscribe to")
+	group := flag.String("group", "checkout-consumer-sim", "consumer group")
+	fromBeginning := flag.Bool("from-beginning", false, "read the topics from the oldest message rather than only new ones")
+	flag.Parse()
+
+	contracts, err := loadContracts(strings.Split(*pacts, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "consumer-sim: %v\n", err)
+		os.Exit(1)
+	}
+	if len(contracts) == 0 {
+		fmt.Fprintf(os.Stderr, "consumer-sim: no message interaction in %s\n", *pacts)
+		os.Exit(1)
+	}
+	var env struct{ Kafka config.Kafka }
+	if err := config.Parse(&env, os.LookupEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "consumer-sim: %v\n", err)
+		os.Exit(1)
+	}
+	if env.Kafka.Addr == "" {
+		fmt.Fprintln(os.Stderr, "consumer-sim: KAFKA_ADDR is not set")
+		os.Exit(2)
+	}
+	mode, err := adapters.ParseDecodeMode(env.Kafka.DecodeMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "consumer-sim: %v\n", err)
+		os.Exit(2)
+	}
+
+	cfg := sarama.NewConfig()
+	cfg.Version = kafka.ProtocolVersion
+	cfg.Consumer.Offsets.Initial = sarama.OffsetNewest
+	if *fromBeginning {
+		cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+	consumer, err := sarama.NewConsumerGroup([]string{env.Kafka.Addr}, *group, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "consumer-sim: %v\n", err)
+		os.Exit(1)
+	}
+
+	sim := &simulator{contracts: contracts, mode: mode, out: os.Stdout}
+	fmt.Printf("checking %s against %d message interactions\n", *topics, len(contracts))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for ctx.Err() == nil {
+		if err := consumer.Consume(ctx, strings.Split(*topics, ","), sim); err != nil && !errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			fmt.Fprintf(os.Stderr, "consumer-sim: %v\n", err)
+			break
+		}
+	}
+	if err := consumer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "consumer-sim: %v\n", err)
+	}
+
+	checked, violated := sim.summary()
+	fmt.Printf("checked %d messages, %d violated a contract\n", checked, violated)
+	if violated > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactmatch"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+)
+
+// violation is a way a message breaks a contract.
+type violation struct {
+	path    string
+	problem string
+}
+
+func (v violation) String() string {
+	return v.path + ": " + v.problem
+}
+
+// check returns the violations of c by a message with body, decoded with
+// json.Number numbers, and headers. As in pact, the body may have fields the
+// example lacks, a field without a matching rule must equal the example, and
+// a type matcher applies to the fields below it too. The contentType metadata
+// describes the body of the pact, not the message, and the event type selects
+// the contracts of a message, so neither is checked.
+func (c contract) check(body any, headers map[string]string) []violation {
+	var violations []violation
+	report := func(path []pactmatch.Segment, format string, args ...any) {
+		violations = append(violations, violation{pactmatch.FormatPath(path), fmt.Sprintf(format, args...)})
+	}
+	c.compare(nil, c.body, body, report)
+
+	keys := make([]string, 0, len(c.metadata))
+	for key := range c.metadata {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if key == "contentType" || key == adapters.EventTypeHeader {
+			continue
+		}
+		path := "metadata." + key
+		value, ok := headers[key]
+		if !ok {
+			violations = append(violations, violation{path, "missing"})
+			continue
+		}
+		expected := metadataString(c.metadata[key])
+		if r, ok := c.metadataRules[key]; ok {
+			if problem := r.Apply(expected, value); problem != "" {
+				violations = append(violations, violation{path, problem})
+			}
+		} else if value != expected {
+			violations = append(violations, violation{path, fmt.Sprintf("got %q, want %q", value, expected)})
+		}
+	}
+	return violations
+}
+
+// compare checks actual against the example expected at path.
+func (c contract) compare(path []pactmatch.Segment, expected, actual any, report func([]pactmatch.Segment, string, ...any)) {
+	r := pactmatch.RuleFor(c.bodyRules, path)
+	ok := r != nil
+	typed := ok && r.Typed()
+	if ok {
+		if problem := r.Apply(expected, actual); problem != "" {
+			report(path, "%s", problem)
+			return
+		}
+	}
+
+	switch exp := expected.(type) {
+	case map[string]any:
+		act, isObject := actual.(map[string]any)
+		if !isObject {
+			if !ok {
+				report(path, "got %s, want an object", pactmatch.Describe(actual))
+			}
+			return
+		}
+		keys := make([]string, 0, len(exp))
+		for key := range exp {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			child := append(slices.Clip(path), pactmatch.Segment(key))
+			value, present := act[key]
+			if !present {
+				report(child, "missing")
+				continue
+			}
+			c.compare(child, exp[key], value, report)
+		}
+	case []any:
+		act, isArray := actual.([]any)
+		if !isArray {
+			if !ok {
+				report(path, "got %s, want an array", pactmatch.Describe(actual))
+			}
+			return
+		}
+		if typed {
+			// Each element is matched against the first of the example
+			if len(exp) == 0 {
+				return
+			}
+			for i, value := range act {
+				c.compare(append(slices.Clip(path), pactmatch.Segment(i)), exp[0], value, report)
+			}
+			return
+		}
+		if len(act) != len(exp) {
+			report(path, "got %d elements, want %d", len(act), len(exp))
+			return
+		}
+		for i, value := range act {
+			c.compare(append(slices.Clip(path), pactmatch.Segment(i)), exp[i], value, report)
+		}
+	default:
+		if !ok && !pactmatch.Equal(expected, actual) {
+			report(path, "got %s, want %s", pactmatch.Describe(actual), pactmatch.Describe(expected))
+		}
+	}
+}
+
+// metadataString returns an example metadata value as a header value.
+func metadataString(v any) string {
+	if s, ok := pactmatch.ScalarString(v); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
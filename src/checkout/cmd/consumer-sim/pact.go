@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactmatch"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// contract is a message interaction of a pact file: what one consumer expects
+// of one kind of order event.
+type contract struct {
+	consumer    string
+	description string
+	// eventType is the event.type metadata of the interaction,
+	// ports.OrderCompletedEvent when it has none
+	eventType string
+	// body is the example body, decoded with json.Number numbers
+	body          any
+	metadata      map[string]any
+	bodyRules     []pactmatch.Rule
+	metadataRules map[string]pactmatch.Rule
+}
+
+// loadContracts reads the message interactions of the pact files matching
+// each of patterns. Interactions over HTTP or gRPC are skipped.
+func loadContracts(patterns []string) ([]contract, error) {
+	var contracts []contract
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no pact file matches %s", pattern)
+		}
+		for _, file := range files {
+			loaded, err := loadPact(file)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			contracts = append(contracts, loaded...)
+		}
+	}
+	return contracts, nil
+}
+
+// pactFile is the part of a V3 or V4 pact file the simulator reads.
+type pactFile struct {
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	// Messages are the interactions of a V3 message pact
+	Messages []interaction `json:"messages"`
+	// Interactions are the interactions of a V4 pact, of any kind
+	Interactions []interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Type          string                     `json:"type"`
+	Description   string                     `json:"description"`
+	Contents      json.RawMessage            `json:"contents"`
+	Metadata      map[string]json.RawMessage `json:"metadata"`
+	MatchingRules struct {
+		Body     map[string]pactmatch.Rule `json:"body"`
+		Metadata map[string]pactmatch.Rule `json:"metadata"`
+	} `json:"matchingRules"`
+}
+
+func loadPact(file string) ([]contract, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var pact pactFile
+	if err := json.Unmarshal(data, &pact); err != nil {
+		return nil, err
+	}
+	var contracts []contract
+	for _, i := range pact.Messages {
+		c, err := newContract(pact.Consumer.Name, i, i.Contents)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, c)
+	}
+	for _, i := range pact.Interactions {
+		if i.Type != "Asynchronous/Messages" {
+			continue
+		}
+		// V4 wraps the body with its content type
+		var contents struct {
+			Content     json.RawMessage `json:"content"`
+			ContentType string          `json:"contentType"`
+			Encoded     any             `json:"encoded"`
+		}
+		if err := json.Unmarshal(i.Contents, &contents); err != nil {
+			return nil, fmt.Errorf("%q: %w", i.Description, err)
+		}
+		if encoded, _ := contents.Encoded.(bool); encoded || !strings.Contains(contents.ContentType, "json") {
+			continue
+		}
+		c, err := newContract(pact.Consumer.Name, i, contents.Content)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, c)
+	}
+	return contracts, nil
+}
+
+func newContract(consumer string, i interaction, body json.RawMessage) (contract, error) {
+	c := contract{
+		consumer:      consumer,
+		description:   i.Description,
+		eventType:     string(ports.OrderCompletedEvent),
+		metadata:      map[string]any{},
+		metadataRules: map[string]pactmatch.Rule{},
+	}
+	var err error
+	if c.body, err = pactmatch.DecodeJSON(body); err != nil {
+		return c, fmt.Errorf("%q: %w", i.Description, err)
+	}
+	for key, raw := range i.Metadata {
+		if c.metadata[key], err = pactmatch.DecodeJSON(raw); err != nil {
+			return c, fmt.Errorf("%q: metadata %s: %w", i.Description, key, err)
+		}
+	}
+	if eventType, ok := c.metadata[adapters.EventTypeHeader].(string); ok {
+		c.eventType = eventType
+	}
+	for path, r := range i.MatchingRules.Body {
+		if err := checkSupported(r); err != nil {
+			return c, fmt.Errorf("%q: %s: %w", i.Description, path, err)
+		}
+		var err error
+		if r.Path, err = pactmatch.ParsePath(path); err != nil {
+			return c, fmt.Errorf("%q: %w", i.Description, err)
+		}
+		c.bodyRules = append(c.bodyRules, r)
+	}
+	for key, r := range i.MatchingRules.Metadata {
+		if err := checkSupported(r); err != nil {
+			return c, fmt.Errorf("%q: metadata %s: %w", i.Description, key, err)
+		}
+		c.metadataRules[key] = r
+	}
+	return c, nil
+}
+
+// checkSupported rejects a rule with a matcher the simulator does not
+// evaluate, rather than checking it partially.
+func checkSupported(r pactmatch.Rule) error {
+	for _, m := range r.Matchers {
+		if !m.Supported() {
+			return fmt.Errorf("unsupported matcher %q", m.Match)
+		}
+	}
+	return nil
+}
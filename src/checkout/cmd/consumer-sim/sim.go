@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactmatch"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// simulator checks each order event against the contracts of the consumers
+// of its event type and prints the outcome. It implements
+// sarama.ConsumerGroupHandler.
+type simulator struct {
+	contracts []contract
+	// mode is the decode mode of ORDER_EVENT_DECODE_MODE
+	mode adapters.DecodeMode
+	out  io.Writer
+
+	mu       sync.Mutex
+	checked  int
+	violated int
+}
+
+// Compile-time check that simulator implements sarama.ConsumerGroupHandler
+var _ sarama.ConsumerGroupHandler = (*simulator)(nil)
+
+// Setup is run at the beginning of a new consumer group session.
+func (s *simulator) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+// Cleanup is run at the end of a consumer group session.
+func (s *simulator) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim checks every message of a claim.
+func (s *simulator) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		s.print(msg, s.check(msg))
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// verdict is the outcome of a message for one consumer.
+type verdict struct {
+	consumer string
+	// closest is the interaction of the consumer the message came closest to
+	// satisfying, with its violations, none if the message satisfies it
+	closest    contract
+	violations []violation
+}
+
+// outcome is the outcome of a message for every consumer of its event type.
+type outcome struct {
+	orderID   string
+	eventType string
+	// err is set when the message could not be decoded
+	err      error
+	verdicts []verdict
+}
+
+// check checks msg against the contracts of its event type. A consumer is
+// satisfied when the message satisfies one of its interactions, since an
+// interaction describes one kind of order, such as one in EUR.
+func (s *simulator) check(msg *sarama.ConsumerMessage) outcome {
+	headers := map[string]string{}
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers[string(h.Key)] = string(h.Value)
+		}
+	}
+	o := outcome{eventType: headers[adapters.EventTypeHeader]}
+	if o.eventType == "" {
+		o.eventType = string(ports.OrderCompletedEvent)
+	}
+
+	body, err := consumerJSON(msg.Value, s.mode)
+	if err != nil {
+		o.err = err
+	} else if m, ok := body.(map[string]any); ok {
+		o.orderID, _ = m["orderId"].(string)
+	}
+
+	byConsumer := map[string]*verdict{}
+	var consumers []string
+	for _, c := range s.contracts {
+		if c.eventType != o.eventType {
+			continue
+		}
+		v, seen := byConsumer[c.consumer]
+		if !seen {
+			v = &verdict{consumer: c.consumer}
+			byConsumer[c.consumer] = v
+			consumers = append(consumers, c.consumer)
+		} else if len(v.violations) == 0 || o.err != nil {
+			continue
+		}
+		if o.err != nil {
+			v.closest, v.violations = c, []violation{{"$", o.err.Error()}}
+			continue
+		}
+		violations := c.check(body, headers)
+		if !seen || len(violations) < len(v.violations) {
+			v.closest, v.violations = c, violations
+		}
+	}
+	slices.Sort(consumers)
+	for _, consumer := range consumers {
+		o.verdicts = append(o.verdicts, *byConsumer[consumer])
+	}
+	return o
+}
+
+// consumerJSON decodes an OrderResult message in mode into the JSON consumers
+// read, with json.Number numbers.
+func consumerJSON(value []byte, mode adapters.DecodeMode) (any, error) {
+	order, err := adapters.DecodeOrderResult(value, mode)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := serialization.ToConsumerJSON(order)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return pactmatch.DecodeJSON(data)
+}
+
+// print writes the outcome of msg: one line, followed by the violations of
+// each consumer the message fails.
+func (s *simulator) print(msg *sarama.ConsumerMessage, o outcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checked++
+
+	prefix := fmt.Sprintf("%s/%d@%d %s %s", msg.Topic, msg.Partition, msg.Offset, o.orderID, o.eventType)
+	if len(o.verdicts) == 0 {
+		fmt.Fprintf(s.out, "%s: no contract\n", prefix)
+		return
+	}
+	var satisfied, failed []string
+	for _, v := range o.verdicts {
+		if len(v.violations) == 0 {
+			satisfied = append(satisfied, v.consumer)
+		} else {
+			failed = append(failed, v.consumer)
+		}
+	}
+	if len(failed) == 0 {
+		fmt.Fprintf(s.out, "%s: ok %v\n", prefix, satisfied)
+		return
+	}
+	s.violated++
+	fmt.Fprintf(s.out, "%s: VIOLATION %v\n", prefix, failed)
+	for _, v := range o.verdicts {
+		if len(v.violations) == 0 {
+			continue
+		}
+		fmt.Fprintf(s.out, "    %s %q\n", v.consumer, v.closest.description)
+		for _, violation := range v.violations {
+			fmt.Fprintf(s.out, "        %s\n", violation)
+		}
+	}
+}
+
+// summary returns the number of messages checked and of those that violated
+// a contract.
+func (s *simulator) summary() (checked, violated int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checked, s.violated
+}
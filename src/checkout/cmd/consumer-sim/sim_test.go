@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func newMessage(t *testing.T, order *pb.OrderResult, headers map[string]string) *sarama.ConsumerMessage {
+	t.Helper()
+	value, err := proto.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &sarama.ConsumerMessage{Topic: "orders", Value: value}
+	for key, v := range headers {
+		msg.Headers = append(msg.Headers, &sarama.RecordHeader{Key: []byte(key), Value: []byte(v)})
+	}
+	return msg
+}
+
+func TestSimulator(t *testing.T) {
+	contracts, err := loadContracts([]string{"testdata/*.json"})
+	if err != nil {
+		t.Fatalf("loadContracts() = %v", err)
+	}
+	if len(contracts) != 3 {
+		t.Fatalf("loaded %d contracts, want the 3 message interactions", len(contracts))
+	}
+	order := testdata.NewOrder().WithID("order-1").WithItem("SKU-1", 3, testdata.USD(5)).Build()
+
+	tests := []struct {
+		name    string
+		order   *pb.OrderResult
+		headers map[string]string
+		// want is the violations of each consumer, "" for none
+		want map[string]string
+	}{
+		{
+			name:  "order result",
+			order: order,
+			want:  map[string]string{"payments-consumer": ""},
+		},
+		{
+			name:  "lowercase currency",
+			order: testdata.NewOrder().WithID("order-1").WithShipping(testdata.Money("usd", 8, 0)).WithItem("SKU-1", 3, testdata.USD(5)).Build(),
+			want:  map[string]string{"payments-consumer": `$.shippingCost.currencyCode: got string "usd", want a match of ^[A-Z]{3}$`},
+		},
+		{
+			name:    "schema version 2",
+			order:   &pb.OrderResult{OrderId: "order-1"},
+			headers: map[string]string{"event.type": "OrderResult", "order.schema.version": "2"},
+			want:    map[string]string{"payments-consumer": ""},
+		},
+		{
+			name:  "no items",
+			order: testdata.NewOrder().WithID("order-1").Build(),
+			// Versions 1 and 2 both have one violation, the first is reported
+			want: map[string]string{"payments-consumer": "$.items: got 0 elements, want at least 1"},
+		},
+		{
+			name:    "loyalty points",
+			order:   order,
+			headers: map[string]string{"event.type": "LoyaltyPointsEarned", "customer_id": "user-1", "points": "15"},
+			want:    map[string]string{"loyalty-consumer": ""},
+		},
+		{
+			name:    "loyalty points of another customer",
+			order:   order,
+			headers: map[string]string{"event.type": "LoyaltyPointsEarned", "customer_id": "user-2", "points": "many"},
+			want: map[string]string{"loyalty-consumer": `metadata.customer_id: got "user-2", want "user-1"; ` +
+				`metadata.points: got string "many", want a match of ^[0-9]+$`},
+		},
+		{
+			name:    "no consumer",
+			order:   order,
+			headers: map[string]string{"event.type": "PaymentCaptured"},
+			want:    map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := &simulator{contracts: contracts}
+			o := sim.check(newMessage(t, tt.order, tt.headers))
+			got := map[string]string{}
+			for _, v := range o.verdicts {
+				var violations []string
+				for _, violation := range v.violations {
+					violations = append(violations, violation.String())
+				}
+				got[v.consumer] = strings.Join(violations, "; ")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("checked against %v, want %v", got, tt.want)
+			}
+			for consumer, want := range tt.want {
+				if got[consumer] != want {
+					t.Errorf("violations of %s = %q, want %q", consumer, got[consumer], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatorPrint(t *testing.T) {
+	contracts, err := loadContracts([]string{"testdata/payments-consumer-checkout-provider.json"})
+	if err != nil {
+		t.Fatalf("loadContracts() = %v", err)
+	}
+	var out bytes.Buffer
+	sim := &simulator{contracts: contracts, out: &out}
+	order := testdata.NewOrder().WithID("order-1").WithItem("SKU-1", 3, testdata.USD(5)).Build()
+	for _, msg := range []*sarama.ConsumerMessage{
+		newMessage(t, order, nil),
+		newMessage(t, testdata.NewOrder().WithID("order-2").Build(), nil),
+		{Topic: "orders", Offset: 2, Value: []byte("not protobuf")},
+	} {
+		sim.print(msg, sim.check(msg))
+	}
+
+	want := `orders/0@0 order-1 OrderResult: ok [payments-consumer]
+orders/0@0 order-2 OrderResult: VIOLATION [payments-consumer]
+    payments-consumer "an order-result message in schema version 1"
+        $.items: got 0 elements, want at least 1
+orders/0@2  OrderResult: VIOLATION [payments-consumer]
+    payments-consumer "an order-result message in schema version 1"
+        $: failed to unmarshal order result:`
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("printed\n%s\nwant it to start with\n%s", out.String(), want)
+	}
+	if checked, violated := sim.summary(); checked != 3 || violated != 2 {
+		t.Errorf("summary() = %d, %d; want 3, 2", checked, violated)
+	}
+}
+
+func TestLoadContractsRejectsUnsupportedMatchers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pact.json")
+	pact := `{"consumer": {"name": "c"}, "messages": [{"description": "d", "contents": {"at": "2025-01-01"},
+		"matchingRules": {"body": {"$.at": {"matchers": [{"match": "date", "format": "yyyy-MM-dd"}]}}}}]}`
+	if err := os.WriteFile(path, []byte(pact), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadContracts([]string{path}); err == nil || !strings.Contains(err.Error(), `unsupported matcher "date"`) {
+		t.Errorf("loadContracts() = %v, want an unsupported matcher error", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// broker is a client of the HTTP API of a Pact Broker.
+type broker struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+func newBroker(env brokerEnv) *broker {
+	return &broker{
+		baseURL:  strings.TrimSuffix(env.URL, "/"),
+		username: env.Username,
+		password: env.Password,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// pactFile is the part of a pact file naming its pacticipants.
+type pactFile struct {
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	Provider struct {
+		Name string `json:"name"`
+	} `json:"provider"`
+}
+
+// publishPact publishes the pact file data as the pact of its consumer at
+// version, and records version as on branch unless branch is empty.
+func (b *broker) publishPact(ctx context.Context, data []byte, version, branch string) (pactFile, error) {
+	var p pactFile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("invalid pact file: %w", err)
+	}
+	if p.Consumer.Name == "" || p.Provider.Name == "" {
+		return p, errors.New("invalid pact file: no consumer or provider name")
+	}
+
+	path := "/pacts/provider/" + url.PathEscape(p.Provider.Name) +
+		"/consumer/" + url.PathEscape(p.Consumer.Name) +
+		"/version/" + url.PathEscape(version)
+	if err := b.do(ctx, http.MethodPut, path, data, nil); err != nil {
+		return p, err
+	}
+	if branch == "" {
+		return p, nil
+	}
+	path = "/pacticipants/" + url.PathEscape(p.Consumer.Name) +
+		"/branches/" + url.PathEscape(branch) +
+		"/versions/" + url.PathEscape(version)
+	return p, b.do(ctx, http.MethodPut, path, []byte("{}"), nil)
+}
+
+// fetchPact returns the pact between consumer and provider published at
+// version.
+func (b *broker) fetchPact(ctx context.Context, provider, consumer, version string) ([]byte, error) {
+	path := "/pacts/provider/" + url.PathEscape(provider) +
+		"/consumer/" + url.PathEscape(consumer) +
+		"/version/" + url.PathEscape(version)
+	var pact json.RawMessage
+	if err := b.do(ctx, http.MethodGet, path, nil, &pact); err != nil {
+		return nil, err
+	}
+	return pact, nil
+}
+
+// deployability is the answer of the broker to can-i-deploy.
+type deployability struct {
+	Deployable bool   `json:"deployable"`
+	Reason     string `json:"reason"`
+}
+
+// canIDeploy asks whether version of pacticipant is compatible with the
+// versions tagged to, or deployed to environment.
+func (b *broker) canIDeploy(ctx context.Context, pacticipant, version, to, environment string) (deployability, error) {
+	query := url.Values{"pacticipant": {pacticipant}, "version": {version}}
+	if environment != "" {
+		query.Set("environment", environment)
+	} else {
+		query.Set("to", to)
+	}
+	var answer struct {
+		Summary *deployability `json:"summary"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/can-i-deploy?"+query.Encode(), nil, &answer); err != nil {
+		return deployability{}, err
+	}
+	if answer.Summary == nil {
+		return deployability{}, errors.New("the broker answered can-i-deploy without a summary")
+	}
+	return *answer.Summary, nil
+}
+
+// do sends a request with the JSON body to path, and decodes the JSON
+// response into out unless it is nil.
+func (b *broker) do(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/hal+json, application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.username != "" {
+		req.SetBasicAuth(b.username, b.password)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPublishPact(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		user, password, _ := r.BasicAuth()
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath()+" "+user+":"+password+" "+string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	pact := `{"consumer": {"name": "payments-consumer"}, "provider": {"name": "checkout-provider"}}`
+	b := newBroker(brokerEnv{URL: server.URL + "/", Username: "ci", Password: "secret"})
+	if _, err := b.publishPact(context.Background(), []byte(pact), "abc123", "feature/x"); err != nil {
+		t.Fatalf("publishPact() = %v", err)
+	}
+
+	want := []string{
+		"PUT /pacts/provider/checkout-provider/consumer/payments-consumer/version/abc123 ci:secret " + pact,
+		"PUT /pacticipants/payments-consumer/branches/feature%2Fx/versions/abc123 ci:secret {}",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPublishPactRejectsInvalidFiles(t *testing.T) {
+	b := newBroker(brokerEnv{URL: "http://broker.invalid"})
+	for _, pact := range []string{`not json`, `{"consumer": {"name": "c"}}`} {
+		if _, err := b.publishPact(context.Background(), []byte(pact), "v1", ""); err == nil || !strings.Contains(err.Error(), "invalid pact file") {
+			t.Errorf("publishPact(%s) = %v, want an invalid pact file error", pact, err)
+		}
+	}
+}
+
+func TestCanIDeploy(t *testing.T) {
+	tests := []struct {
+		name        string
+		to          string
+		environment string
+		status      int
+		response    string
+		wantQuery   string
+		want        deployability
+		wantErr     string
+	}{
+		{
+			name:      "deployable to a tag",
+			to:        "production",
+			status:    http.StatusOK,
+			response:  `{"summary": {"deployable": true, "reason": "All required verification results are published and successful"}}`,
+			wantQuery: "pacticipant=checkout-provider&to=production&version=abc123",
+			want:      deployability{Deployable: true, Reason: "All required verification results are published and successful"},
+		},
+		{
+			name:        "not deployable to an environment",
+			environment: "staging",
+			status:      http.StatusOK,
+			response:    `{"summary": {"deployable": false, "reason": "There is no verified pact"}}`,
+			wantQuery:   "environment=staging&pacticipant=checkout-provider&version=abc123",
+			want:        deployability{Reason: "There is no verified pact"},
+		},
+		{
+			name:      "unknown version",
+			to:        "production",
+			status:    http.StatusBadRequest,
+			response:  `{"errors": ["unknown version"]}`,
+			wantQuery: "pacticipant=checkout-provider&to=production&version=abc123",
+			wantErr:   "400 Bad Request",
+		},
+		{
+			name:      "no summary",
+			to:        "production",
+			status:    http.StatusOK,
+			response:  `{}`,
+			wantQuery: "pacticipant=checkout-provider&to=production&version=abc123",
+			wantErr:   "without a summary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/can-i-deploy" || r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("request = %s, want /can-i-deploy?%s", r.URL, tt.wantQuery)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			got, err := newBroker(brokerEnv{URL: server.URL}).canIDeploy(context.Background(), providerName, "abc123", tt.to, tt.environment)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("canIDeploy() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("canIDeploy() = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestPatterns(t *testing.T) {
+	consumers := []string{"TestOrderQueryConsumerContract", "TestPaymentsConsumerContract"}
+	providers := []string{"TestOrderQueryProviderContract", "TestPaymentsProviderContract", "TestOrderEventPublisherContract"}
+	others := []string{"TestContractPipeline", "TestPlaceOrderIsIdempotent", "TestOrderEventPublisherContractX"}
+
+	for pattern, names := range map[string][]string{consumerTests: consumers, providerTests: providers} {
+		re := regexp.MustCompile(pattern)
+		for _, name := range append(append([]string(nil), consumers...), append(providers, others...)...) {
+			want := false
+			for _, n := range names {
+				want = want || n == name
+			}
+			if got := re.MatchString(name); got != want {
+				t.Errorf("%s matches %s = %v, want %v", pattern, name, got, want)
+			}
+		}
+	}
+}
+
+func TestGoTestArgs(t *testing.T) {
+	got := strings.Join(goTestArgs(consumerTests, true), " ")
+	want := "test -count=1 -run " + consumerTests + " -v " + checkoutPackage
+	if got != want {
+		t.Errorf("goTestArgs() = %s, want %s", got, want)
+	}
+}
+
+func TestFetchPact(t *testing.T) {
+	pact := `{"consumer": {"name": "payments-consumer"}, "provider": {"name": "checkout-provider"}, "messages": []}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pacts/provider/checkout-provider/consumer/payments-consumer/version/abc123" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, pact)
+	}))
+	defer server.Close()
+
+	b := newBroker(brokerEnv{URL: server.URL})
+	got, err := b.fetchPact(context.Background(), providerName, "payments-consumer", "abc123")
+	if err != nil || string(got) != pact {
+		t.Errorf("fetchPact() = %s, %v; want the published pact", got, err)
+	}
+	if _, err := b.fetchPact(context.Background(), providerName, "payments-consumer", "unknown"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("fetchPact() of an unknown version = %v, want a 404 error", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// checkoutPackage is the package of the contract tests. go test runs them in
+// its directory, so that they find pacts/ wherever contractctl is started.
+const checkoutPackage = "github.com/open-telemetry/opentelemetry-demo/src/checkout"
+
+// goTestArgs returns the arguments of the go test run of the tests run
+// matches. The results are never cached, since they depend on pact files and
+// a broker go test does not know about.
+func goTestArgs(run string, verbose bool) []string {
+	args := []string{"test", "-count=1", "-run", run}
+	if verbose {
+		args = append(args, "-v")
+	}
+	return append(args, checkoutPackage)
+}
+
+// goTest runs the checkout tests run matches, with env added to the
+// environment, and streams their output.
+func goTest(ctx context.Context, run string, verbose bool, env []string) error {
+	args := goTestArgs(run, verbose)
+	fmt.Fprintf(os.Stderr, "%sgo %s\n", strings.Join(append(env, ""), " "), strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go test: %w", err)
+	}
+	return nil
+}
+
+// packageDir returns the directory of checkoutPackage.
+func packageDir(ctx context.Context) (string, error) {
+	return output(ctx, "go", "list", "-f", "{{.Dir}}", checkoutPackage)
+}
+
+// orGit returns value, or the output of git with args if value is empty.
+func orGit(ctx context.Context, value string, args ...string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	return output(ctx, "git", args...)
+}
+
+func output(ctx context.Context, name string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
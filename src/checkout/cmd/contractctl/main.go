@@ -0,0 +1,320 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command contractctl runs the contract testing workflow of the checkout
+// service, so that nobody has to remember the test names, flags and
+// environment variables it takes:
+//
+//	contractctl generate          record the pacts of the consumer tests into pacts/
+//	contractctl verify            verify the provider against the local pacts
+//	contractctl verify -broker    verify it against the broker and publish the results
+//	contractctl publish           publish pacts/*.json to the broker
+//	contractctl can-i-deploy      ask the broker whether the provider can be deployed
+//	contractctl diff              classify the changes between two versions of a pact
+//
+// generate and verify run go test on the checkout package, wherever in the
+// module contractctl is started. The broker is read from PACT_BROKER_URL,
+// PACT_BROKER_USERNAME and PACT_BROKER_PASSWORD, and versions default to the
+// current git commit and branch, as in CI.
+//
+// Usage:
+//
+//	go run ./cmd/contractctl generate && go run ./cmd/contractctl verify
+//	PACT_BROKER_URL=https://broker.example.com go run ./cmd/contractctl can-i-deploy -to production
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactdiff"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+)
+
+const (
+	// providerName is the pacticipant name of the checkout service
+	providerName = "checkout-provider"
+	// consumerTests and providerTests select the two halves of the contract
+	// tests of the checkout package
+	consumerTests = `^Test\w+ConsumerContract$`
+	providerTests = `^(Test\w+ProviderContract|TestOrderEventPublisherContract)$`
+)
+
+// errUsage is returned for invalid arguments, after the usage was printed.
+var errUsage = errors.New("usage")
+
+// brokerEnv is the environment the broker commands read, the same variables
+// the provider tests use.
+type brokerEnv struct {
+	URL      string `env:"PACT_BROKER_URL"`
+	Username string `env:"PACT_BROKER_USERNAME"`
+	Password string `env:"PACT_BROKER_PASSWORD"`
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	commands := map[string]func(context.Context, []string) error{
+		"generate":     generate,
+		"verify":       verify,
+		"publish":      publish,
+		"can-i-deploy": canIDeploy,
+		"diff":         diff,
+	}
+	command, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "contractctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := command(ctx, flag.Args()[1:])
+	stop()
+	switch {
+	case errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "contractctl %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: contractctl <command> [flags]
+
+commands:
+  generate      record the pacts of the consumer tests into pacts/
+  verify        verify the provider against the local pacts, or the broker with -broker
+  publish       publish pact files to the broker
+  can-i-deploy  ask the broker whether a version can be deployed
+  diff          classify the changes between two versions of a pact as breaking or compatible
+
+Run contractctl <command> -h for the flags of a command.
+`)
+}
+
+// newFlagSet returns the flag set of command, which prints its errors and
+// usage rather than exiting.
+func newFlagSet(command string) *flag.FlagSet {
+	fs := flag.NewFlagSet("contractctl "+command, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "%s: unexpected arguments %v\n", fs.Name(), fs.Args())
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func generate(ctx context.Context, args []string) error {
+	fs := newFlagSet("generate")
+	run := fs.String("run", consumerTests, "regular expression selecting the consumer tests")
+	verbose := fs.Bool("v", false, "print the output of every test")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	// The consumer tests record their pacts whatever CONTRACT_TESTS says
+	return goTest(ctx, *run, *verbose, []string{"CONTRACT_TESTS=local"})
+}
+
+func verify(ctx context.Context, args []string) error {
+	fs := newFlagSet("verify")
+	run := fs.String("run", providerTests, "regular expression selecting the provider tests")
+	verbose := fs.Bool("v", false, "print the output of every test")
+	useBroker := fs.Bool("broker", false, "verify the pacts of PACT_BROKER_URL and publish the results, rather than the local pacts")
+	version := fs.String("version", "", "provider version the results are published for (default the git commit)")
+	branch := fs.String("branch", "", "provider branch the results are published for (default the git branch)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if !*useBroker {
+		return goTest(ctx, *run, *verbose, []string{"CONTRACT_TESTS=local"})
+	}
+	env, err := loadBrokerEnv()
+	if err != nil {
+		return err
+	}
+	if *version, err = orGit(ctx, *version, "rev-parse", "HEAD"); err != nil {
+		return err
+	}
+	if *branch, err = orGit(ctx, *branch, "rev-parse", "--abbrev-ref", "HEAD"); err != nil {
+		return err
+	}
+	return goTest(ctx, *run, *verbose, []string{
+		"CONTRACT_TESTS=broker",
+		"PACT_BROKER_URL=" + env.URL,
+		"GIT_COMMIT=" + *version,
+		"GIT_BRANCH=" + *branch,
+	})
+}
+
+func publish(ctx context.Context, args []string) error {
+	fs := newFlagSet("publish")
+	pacts := fs.String("pacts", "", "comma-separated globs of the pact files to publish (default pacts/*.json of the checkout package)")
+	version := fs.String("version", "", "consumer version the pacts are published for (default the git commit)")
+	branch := fs.String("branch", "", "consumer branch of the version (default the git branch)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	env, err := loadBrokerEnv()
+	if err != nil {
+		return err
+	}
+	if *version, err = orGit(ctx, *version, "rev-parse", "HEAD"); err != nil {
+		return err
+	}
+	if *branch, err = orGit(ctx, *branch, "rev-parse", "--abbrev-ref", "HEAD"); err != nil {
+		return err
+	}
+	if *pacts == "" {
+		dir, err := packageDir(ctx)
+		if err != nil {
+			return err
+		}
+		*pacts = filepath.Join(dir, "pacts", "*.json")
+	}
+
+	var files []string
+	for _, glob := range strings.Split(*pacts, ",") {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no pact file matches %s", *pacts)
+	}
+
+	b := newBroker(env)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		p, err := b.publishPact(ctx, data, *version, *branch)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		fmt.Printf("published %s %s -> %s\n", p.Consumer.Name, *version, p.Provider.Name)
+	}
+	return nil
+}
+
+func canIDeploy(ctx context.Context, args []string) error {
+	fs := newFlagSet("can-i-deploy")
+	pacticipant := fs.String("pacticipant", providerName, "pacticipant to deploy")
+	version := fs.String("version", "", "version to deploy (default the git commit)")
+	to := fs.String("to", "", "tag of the versions it must be compatible with, such as production")
+	environment := fs.String("environment", "", "environment to deploy to, instead of -to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if (*to == "") == (*environment == "") {
+		fmt.Fprintln(os.Stderr, "contractctl can-i-deploy: exactly one of -to and -environment is required")
+		fs.Usage()
+		return errUsage
+	}
+
+	env, err := loadBrokerEnv()
+	if err != nil {
+		return err
+	}
+	if *version, err = orGit(ctx, *version, "rev-parse", "HEAD"); err != nil {
+		return err
+	}
+	answer, err := newBroker(env).canIDeploy(ctx, *pacticipant, *version, *to, *environment)
+	if err != nil {
+		return err
+	}
+	fmt.Println(answer.Reason)
+	if !answer.Deployable {
+		return fmt.Errorf("%s %s cannot be deployed", *pacticipant, *version)
+	}
+	fmt.Printf("%s %s can be deployed\n", *pacticipant, *version)
+	return nil
+}
+
+func diff(ctx context.Context, args []string) error {
+	fs := newFlagSet("diff")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: contractctl diff old.json new.json\n       contractctl diff -consumer name old-version new-version")
+		fs.PrintDefaults()
+	}
+	consumer := fs.String("consumer", "", "compare two versions of the pact of this consumer in the broker, rather than two files")
+	provider := fs.String("provider", providerName, "provider of the pact in the broker")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var before, after []byte
+	var err error
+	if *consumer == "" {
+		if before, err = os.ReadFile(fs.Arg(0)); err != nil {
+			return err
+		}
+		if after, err = os.ReadFile(fs.Arg(1)); err != nil {
+			return err
+		}
+	} else {
+		env, err := loadBrokerEnv()
+		if err != nil {
+			return err
+		}
+		b := newBroker(env)
+		if before, err = b.fetchPact(ctx, *provider, *consumer, fs.Arg(0)); err != nil {
+			return err
+		}
+		if after, err = b.fetchPact(ctx, *provider, *consumer, fs.Arg(1)); err != nil {
+			return err
+		}
+	}
+
+	report, err := pactdiff.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(os.Stdout); err != nil {
+		return err
+	}
+	if report.Breaking() {
+		return errors.New("the new pact has breaking changes for the provider")
+	}
+	return nil
+}
+
+func loadBrokerEnv() (brokerEnv, error) {
+	var env brokerEnv
+	if err := config.Parse(&env, os.LookupEnv); err != nil {
+		return env, err
+	}
+	if env.URL == "" {
+		return env, errors.New("PACT_BROKER_URL is not set")
+	}
+	return env, nil
+}
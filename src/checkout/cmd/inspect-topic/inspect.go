@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// Formats of a message.
+const (
+	formatProtobuf = "protobuf"
+	// formatBinaryCloudEvent is a CloudEvent in the binary content mode of
+	// the Kafka binding: the attributes are ce_ headers and the value is the
+	// data
+	formatBinaryCloudEvent = "cloudevent"
+	// formatStructuredCloudEvent is a CloudEvent in the structured content
+	// mode: the value is the JSON event, with the data in data or data_base64
+	formatStructuredCloudEvent = "cloudevent+json"
+)
+
+// structuredContentType is the content type of a structured CloudEvent.
+const structuredContentType = "application/cloudevents+json"
+
+// inspection is what inspect found in a message.
+type inspection struct {
+	format string
+	// eventType is the event.type header or the type of a CloudEvent,
+	// empty when the message has neither
+	eventType string
+	order     *pb.OrderResult
+	// consumerJSON is the order as consumers read it
+	consumerJSON []byte
+	// err is set when the message could not be decoded
+	err error
+	// violations is set when the order fails the validation of the contract
+	violations error
+}
+
+// invalid reports whether the message should be flagged.
+func (i inspection) invalid() bool {
+	return i.err != nil || i.violations != nil
+}
+
+// inspect decodes msg as an OrderResult in mode, from protobuf or a
+// CloudEvent, and validates it as the publishers do before publishing it: the
+// field rules, the fields consumers require, and the types of the consumer
+// JSON.
+func inspect(msg *sarama.ConsumerMessage, mode adapters.DecodeMode) inspection {
+	headers := map[string]string{}
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers[strings.ToLower(string(h.Key))] = string(h.Value)
+		}
+	}
+	i := inspection{format: formatProtobuf, eventType: headers[adapters.EventTypeHeader]}
+	contentType := headers[adapters.ContentTypeHeader]
+
+	data := msg.Value
+	switch {
+	case headers["ce_specversion"] != "":
+		i.format, i.eventType = formatBinaryCloudEvent, headers["ce_type"]
+	case strings.HasPrefix(contentType, structuredContentType):
+		i.format = formatStructuredCloudEvent
+		var event struct {
+			Type            string          `json:"type"`
+			DataContentType string          `json:"datacontenttype"`
+			Data            json.RawMessage `json:"data"`
+			DataBase64      string          `json:"data_base64"`
+		}
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			i.err = fmt.Errorf("invalid CloudEvent: %w", err)
+			return i
+		}
+		i.eventType, contentType, data = event.Type, event.DataContentType, event.Data
+		if event.DataBase64 != "" {
+			var err error
+			if data, err = base64.StdEncoding.DecodeString(event.DataBase64); err != nil {
+				i.err = fmt.Errorf("invalid CloudEvent data_base64: %w", err)
+				return i
+			}
+			if contentType == "" {
+				contentType = "application/protobuf"
+			}
+		}
+	}
+
+	i.order, i.err = decode(data, contentType, mode)
+	if i.err != nil {
+		return i
+	}
+	obj, err := serialization.ToConsumerJSON(i.order)
+	if err != nil {
+		i.err = err
+		return i
+	}
+	if i.consumerJSON, err = json.MarshalIndent(obj, "", "  "); err != nil {
+		i.err = err
+		return i
+	}
+	i.violations = errors.Join(
+		validation.ValidateOrderResult(i.order),
+		validation.ValidateRequiredFields(i.order),
+		serialization.CheckConsumerTypes(i.order.ProtoReflect().Descriptor(), obj),
+	)
+	return i
+}
+
+// decode decodes an OrderResult of contentType: protobuf in mode unless it
+// names JSON, which is decoded as protobuf JSON.
+func decode(data []byte, contentType string, mode adapters.DecodeMode) (*pb.OrderResult, error) {
+	if strings.Contains(contentType, "json") {
+		order := &pb.OrderResult{}
+		if err := protojson.Unmarshal(data, order); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal order result from JSON: %w", err)
+		}
+		return order, nil
+	}
+	return adapters.DecodeOrderResult(data, mode)
+}
+
+// report writes one line for msg, then its consumer JSON unless quiet,
+// then why it was flagged.
+func report(w io.Writer, msg *sarama.ConsumerMessage, i inspection, quiet bool) {
+	status := "ok"
+	if i.invalid() {
+		status = "INVALID"
+	}
+	fmt.Fprintf(w, "%s/%d@%d %s %s %s %s\n", msg.Topic, msg.Partition, msg.Offset, orDash(i.order.GetOrderId()), orDash(i.eventType), i.format, status)
+	if !quiet && i.consumerJSON != nil {
+		fmt.Fprintf(w, "%s\n", i.consumerJSON)
+	}
+	if i.err != nil {
+		fmt.Fprintf(w, "    %v\n", i.err)
+	}
+	if i.violations != nil {
+		for _, line := range strings.Split(i.violations.Error(), "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func header(key, value string) *sarama.RecordHeader {
+	return &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)}
+}
+
+func TestInspect(t *testing.T) {
+	order := testdata.NewOrder().WithID("order-1").Build()
+	protoValue, err := proto.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonValue, err := protojson.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid, err := proto.Marshal(testdata.NewOrder().WithID("").WithTrackingID("").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Field 111 is not part of OrderResult
+	withFutureField := append(slices.Clip(protoValue), 0xf8, 0x06, 0x01)
+
+	tests := []struct {
+		name          string
+		msg           *sarama.ConsumerMessage
+		mode          adapters.DecodeMode
+		wantFormat    string
+		wantEventType string
+		// wantErr is part of the decoding error, or of the violations
+		wantErr string
+	}{
+		{
+			name:          "protobuf",
+			msg:           &sarama.ConsumerMessage{Value: protoValue, Headers: []*sarama.RecordHeader{header(adapters.EventTypeHeader, "OrderResult")}},
+			wantFormat:    formatProtobuf,
+			wantEventType: "OrderResult",
+		},
+		{
+			name: "binary CloudEvent with JSON data",
+			msg: &sarama.ConsumerMessage{Value: jsonValue, Headers: []*sarama.RecordHeader{
+				header("ce_specversion", "1.0"),
+				header("ce_type", "oteldemo.OrderResult"),
+				header("Content-Type", "application/json"),
+			}},
+			wantFormat:    formatBinaryCloudEvent,
+			wantEventType: "oteldemo.OrderResult",
+		},
+		{
+			name: "structured CloudEvent with JSON data",
+			msg: &sarama.ConsumerMessage{
+				Value:   []byte(`{"specversion": "1.0", "type": "oteldemo.OrderResult", "datacontenttype": "application/json", "data": ` + string(jsonValue) + `}`),
+				Headers: []*sarama.RecordHeader{header("content-type", "application/cloudevents+json; charset=utf-8")},
+			},
+			wantFormat:    formatStructuredCloudEvent,
+			wantEventType: "oteldemo.OrderResult",
+		},
+		{
+			name: "structured CloudEvent with protobuf data",
+			msg: &sarama.ConsumerMessage{
+				Value:   []byte(`{"specversion": "1.0", "type": "oteldemo.OrderResult", "data_base64": "` + base64.StdEncoding.EncodeToString(protoValue) + `"}`),
+				Headers: []*sarama.RecordHeader{header("content-type", "application/cloudevents+json")},
+			},
+			wantFormat:    formatStructuredCloudEvent,
+			wantEventType: "oteldemo.OrderResult",
+		},
+		{
+			name:       "invalid order",
+			msg:        &sarama.ConsumerMessage{Value: invalid},
+			wantFormat: formatProtobuf,
+			wantErr:    "shipping_tracking_id",
+		},
+		{
+			name:       "lenient unknown field",
+			msg:        &sarama.ConsumerMessage{Value: withFutureField},
+			wantFormat: formatProtobuf,
+		},
+		{
+			name:       "strict unknown field",
+			msg:        &sarama.ConsumerMessage{Value: withFutureField},
+			mode:       adapters.DecodeStrict,
+			wantFormat: formatProtobuf,
+			wantErr:    "unknown fields",
+		},
+		{
+			name:       "not an order",
+			msg:        &sarama.ConsumerMessage{Value: []byte{0xff, 0xff}},
+			wantFormat: formatProtobuf,
+			wantErr:    "unmarshal",
+		},
+		{
+			name:       "malformed CloudEvent",
+			msg:        &sarama.ConsumerMessage{Value: []byte(`{`), Headers: []*sarama.RecordHeader{header("content-type", "application/cloudevents+json")}},
+			wantFormat: formatStructuredCloudEvent,
+			wantErr:    "invalid CloudEvent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := inspect(tt.msg, tt.mode)
+			if i.format != tt.wantFormat || i.eventType != tt.wantEventType {
+				t.Errorf("inspect() format, event type = %s, %q; want %s, %q", i.format, i.eventType, tt.wantFormat, tt.wantEventType)
+			}
+			if tt.wantErr == "" {
+				if i.invalid() {
+					t.Fatalf("inspect() = %v, %v; want a valid order", i.err, i.violations)
+				}
+				if !proto.Equal(i.order, order) {
+					t.Errorf("inspect() order = %v, want %v", i.order, order)
+				}
+				if !bytes.Contains(i.consumerJSON, []byte(`"orderId": "order-1"`)) {
+					t.Errorf("consumer JSON = %s, want the indented orderId", i.consumerJSON)
+				}
+				return
+			}
+			var got string
+			if i.err != nil {
+				got = i.err.Error()
+			} else if i.violations != nil {
+				got = i.violations.Error()
+			}
+			if !i.invalid() || !strings.Contains(got, tt.wantErr) {
+				t.Errorf("inspect() = %q, want it flagged with %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTail(t *testing.T) {
+	valid, err := proto.Marshal(testdata.NewOrder().WithID("order-1").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumer := mocks.NewConsumer(t, nil)
+	consumer.SetTopicMetadata(map[string][]int32{"orders": {0}})
+	pc := consumer.ExpectConsumePartition("orders", 0, sarama.OffsetOldest)
+	pc.YieldMessage(&sarama.ConsumerMessage{Topic: "orders", Offset: 0, Value: valid})
+	pc.YieldMessage(&sarama.ConsumerMessage{Topic: "orders", Offset: 1, Value: []byte{0xff, 0xff}})
+	pc.YieldMessage(&sarama.ConsumerMessage{Topic: "orders", Offset: 2, Value: valid})
+
+	var out bytes.Buffer
+	c, err := tail(context.Background(), consumer, "orders", sarama.OffsetOldest, 3, adapters.DecodeLenient, true, &out)
+	if err != nil {
+		t.Fatalf("tail() = %v", err)
+	}
+	if c != (counts{read: 3, invalid: 1}) {
+		t.Errorf("tail() = %+v, want 3 read and 1 invalid", c)
+	}
+	if got := strings.SplitN(out.String(), "\n", 2)[0]; got != "orders/0@1 - - protobuf INVALID" {
+		t.Errorf("tail() first line = %q, want only the invalid message", got)
+	}
+	if strings.Contains(out.String(), "orders/0@0") || strings.Contains(out.String(), "orders/0@2") {
+		t.Errorf("tail() with invalidOnly reported valid messages:\n%s", out.String())
+	}
+	if err := consumer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command inspect-topic tails a Kafka topic of order events, decodes each
+// OrderResult, pretty-prints it as the JSON consumers read, and flags the
+// messages that fail the validation the checkout applies before publishing:
+// the field rules, the fields consumers require and the types of the
+// consumer JSON.
+//
+// Messages may be plain protobuf, as the checkout publishes them, or
+// CloudEvents in the binary or structured content mode of the Kafka binding,
+// with protobuf or JSON data. inspect-topic only reads CloudEvents; nothing
+// in the checkout writes them yet.
+//
+// It reads the topic without a consumer group, so it never moves the offsets
+// of the consumers. It exits 1 when a message was flagged.
+//
+// Usage:
+//
+//	KAFKA_ADDR=localhost:9092 go run ./cmd/inspect-topic
+//	KAFKA_ADDR=localhost:9092 go run ./cmd/inspect-topic -from-beginning -n 100 -invalid-only
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+)
+
+func main() {
+	topic := flag.String("topic", kafka.Topic, "topic to tail")
+	fromBeginning := flag.Bool("from-beginning", false, "read the topic from its oldest message rather than only new messages")
+	limit := flag.Int("n", 0, "stop after this many messages (default until interrupted)")
+	invalidOnly := flag.Bool("invalid-only", false, "only print the messages that fail validation")
+	flag.Parse()
+	if *limit < 0 {
+		fmt.Fprintf(os.Stderr, "inspect-topic: -n %d: expected a positive count\n", *limit)
+		os.Exit(2)
+	}
+
+	var env config.Kafka
+	if err := config.Parse(&env, os.LookupEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "inspect-topic: %v\n", err)
+		os.Exit(1)
+	}
+	if env.Addr == "" {
+		fmt.Fprintln(os.Stderr, "inspect-topic: KAFKA_ADDR is not set")
+		os.Exit(1)
+	}
+	mode, err := adapters.ParseDecodeMode(env.DecodeMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "inspect-topic: %v\n", err)
+		os.Exit(2)
+	}
+
+	cfg := sarama.NewConfig()
+	cfg.Version = kafka.ProtocolVersion
+	consumer, err := sarama.NewConsumer([]string{env.Addr}, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "inspect-topic: %v\n", err)
+		os.Exit(1)
+	}
+
+	offset := sarama.OffsetNewest
+	if *fromBeginning {
+		offset = sarama.OffsetOldest
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	c, err := tail(ctx, consumer, *topic, offset, *limit, mode, *invalidOnly, os.Stdout)
+	stop()
+	consumer.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "inspect-topic: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("read %d messages, %d invalid\n", c.read, c.invalid)
+	if c.invalid > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+)
+
+// counts is what tail saw.
+type counts struct {
+	read    int
+	invalid int
+}
+
+// tail consumes every partition of topic from offset, sarama.OffsetNewest or
+// sarama.OffsetOldest, and reports each message to w until ctx is done or
+// limit messages were read, limit 0 meaning no limit. Messages are decoded in
+// mode. With invalidOnly, only the flagged messages are reported. No consumer group is joined, so tailing
+// a topic never moves the offsets of the service's consumers.
+func tail(ctx context.Context, consumer sarama.Consumer, topic string, offset int64, limit int, mode adapters.DecodeMode, invalidOnly bool, w io.Writer) (counts, error) {
+	var c counts
+	partitions, err := consumer.Partitions(topic)
+	if err != nil {
+		return c, fmt.Errorf("failed to list the partitions of %s: %w", topic, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	messages := make(chan *sarama.ConsumerMessage)
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		pc, err := consumer.ConsumePartition(topic, partition, offset)
+		if err != nil {
+			cancel()
+			wg.Wait()
+			return c, fmt.Errorf("failed to consume %s/%d: %w", topic, partition, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer pc.AsyncClose()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg, ok := <-pc.Messages():
+					if !ok {
+						return
+					}
+					select {
+					case messages <- msg:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(messages)
+	}()
+
+	for msg := range messages {
+		i := inspect(msg, mode)
+		c.read++
+		if i.invalid() {
+			c.invalid++
+		}
+		if !invalidOnly || i.invalid() {
+			report(w, msg, i, false)
+		}
+		if limit > 0 && c.read >= limit {
+			cancel()
+			break
+		}
+	}
+	return c, nil
+}
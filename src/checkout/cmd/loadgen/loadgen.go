@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// options configure a load run.
+type options struct {
+	// rate is the number of orders started per second
+	rate int
+	// duration is how long orders are started for
+	duration time.Duration
+	// items is the number of items of each order
+	items int
+	// concurrency bounds the publishes in flight; an order due while all of
+	// them are busy is skipped and counted as missed
+	concurrency int
+}
+
+func (o options) validate() error {
+	switch {
+	case o.rate <= 0 || o.rate > 1_000_000:
+		return errors.New("-rate must be between 1 and 1000000")
+	case o.duration <= 0:
+		return errors.New("-duration must be positive")
+	case o.items < 0:
+		return errors.New("-items must not be negative")
+	case o.concurrency <= 0:
+		return errors.New("-concurrency must be positive")
+	}
+	return nil
+}
+
+// report is the outcome of a load run.
+type report struct {
+	opts options
+	// payloadBytes is the size of the protobuf encoding of each order
+	payloadBytes int
+	// elapsed runs from the first order until the last publish returned
+	elapsed time.Duration
+	sent    int
+	failed  int
+	missed  int
+	// latencies are the durations of the successful publishes, sorted
+	latencies []time.Duration
+	// firstErr is the error of the first failed publish
+	firstErr error
+}
+
+// run publishes an order of opts.items items every 1/opts.rate seconds
+// through publisher for opts.duration, or until ctx is done, and waits for
+// the publishes in flight.
+func run(ctx context.Context, publisher ports.OrderEventPublisher, opts options) report {
+	r := report{opts: opts, payloadBytes: proto.Size(newOrder(0, opts.items))}
+	var mu sync.Mutex
+	record := func(latency time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			r.failed++
+			if r.firstErr == nil {
+				r.firstErr = err
+			}
+			return
+		}
+		r.sent++
+		r.latencies = append(r.latencies, latency)
+	}
+
+	slots := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(time.Second / time.Duration(opts.rate))
+	defer ticker.Stop()
+	start := time.Now()
+	deadline := time.After(opts.duration)
+loop:
+	for seq := 0; ; seq++ {
+		select {
+		case slots <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				order := newOrder(seq, opts.items)
+				begin := time.Now()
+				err := publisher.PublishOrderCompleted(ctx, order)
+				record(time.Since(begin), err)
+			}()
+		default:
+			r.missed++
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	wg.Wait()
+	r.elapsed = time.Since(start)
+	slices.Sort(r.latencies)
+	return r
+}
+
+// newOrder returns the seq-th synthetic order, with items items. It passes
+// the validation of the publisher decorators.
+func newOrder(seq, items int) *pb.OrderResult {
+	order := &pb.OrderResult{
+		OrderId:            fmt.Sprintf("loadgen-%08d", seq),
+		ShippingTrackingId: fmt.Sprintf("LOADGEN-TRACK-%08d", seq),
+		ShippingCost:       &pb.Money{CurrencyCode: "USD", Units: 8, Nanos: 990000000},
+		ShippingAddress: &pb.Address{
+			StreetAddress: "1600 Amphitheatre Parkway",
+			City:          "Mountain View",
+			State:         "CA",
+			Country:       "US",
+			ZipCode:       "94043",
+		},
+	}
+	for i := range items {
+		order.Items = append(order.Items, &pb.OrderItem{
+			Item: &pb.CartItem{ProductId: fmt.Sprintf("LOADGEN-%04d", i), Quantity: 1},
+			Cost: &pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 990000000},
+		})
+	}
+	return order
+}
+
+// percentile returns the p-th percentile of the sorted latencies, 0 without
+// any.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := int(float64(len(latencies)-1) * p / 100)
+	return latencies[i]
+}
+
+func (r report) print(w io.Writer) {
+	fmt.Fprintf(w, "target rate    %d orders/s for %s\n", r.opts.rate, r.opts.duration)
+	fmt.Fprintf(w, "payload        %d items, %d bytes\n", r.opts.items, r.payloadBytes)
+	fmt.Fprintf(w, "sent           %d (%.1f orders/s)\n", r.sent, float64(r.sent)/r.elapsed.Seconds())
+	fmt.Fprintf(w, "failed         %d\n", r.failed)
+	fmt.Fprintf(w, "missed         %d (all %d publishes in flight)\n", r.missed, r.opts.concurrency)
+	fmt.Fprintf(w, "latency        p50 %s  p90 %s  p99 %s  max %s\n",
+		percentile(r.latencies, 50), percentile(r.latencies, 90), percentile(r.latencies, 99), percentile(r.latencies, 100))
+	if r.firstErr != nil {
+		fmt.Fprintf(w, "first error    %v\n", r.firstErr)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports/mocks"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+func TestRun(t *testing.T) {
+	errBroker := errors.New("broker unavailable")
+	publisher := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+	var calls int
+	publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, order *pb.OrderResult) error {
+			if err := errors.Join(validation.ValidateOrderResult(order), validation.ValidateRequiredFields(order)); err != nil {
+				t.Errorf("published an invalid order: %v", err)
+			}
+			if calls++; calls%2 == 0 {
+				return errBroker
+			}
+			return nil
+		}).
+		AnyTimes()
+
+	r := run(context.Background(), publisher, options{rate: 100, duration: 200 * time.Millisecond, items: 3, concurrency: 1})
+
+	// About 20 orders are due; a mock publisher is never too slow for them
+	if total := r.sent + r.failed; total < 10 || total > 25 || r.missed != 0 {
+		t.Errorf("sent %d, failed %d and missed %d orders, want about 20 published", r.sent, r.failed, r.missed)
+	}
+	if r.failed != r.sent && r.failed != r.sent-1 || r.firstErr != errBroker {
+		t.Errorf("failed %d of %d orders with %v, want every other one failed with %v", r.failed, r.sent+r.failed, r.firstErr, errBroker)
+	}
+	if len(r.latencies) != r.sent {
+		t.Errorf("recorded %d latencies for %d orders sent", len(r.latencies), r.sent)
+	}
+
+	var out strings.Builder
+	r.print(&out)
+	if !strings.Contains(out.String(), "payload        3 items") || !strings.Contains(out.String(), "first error    broker unavailable") {
+		t.Errorf("report =\n%s", out.String())
+	}
+}
+
+func TestRunMissesOrdersWhilePublishesAreInFlight(t *testing.T) {
+	release := make(chan struct{})
+	publisher := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+	publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, *pb.OrderResult) error {
+			<-release
+			return nil
+		}).
+		Times(2)
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+
+	r := run(context.Background(), publisher, options{rate: 100, duration: 50 * time.Millisecond, items: 1, concurrency: 2})
+	if r.sent != 2 || r.missed == 0 {
+		t.Errorf("sent %d and missed %d orders, want 2 sent and the others missed", r.sent, r.missed)
+	}
+}
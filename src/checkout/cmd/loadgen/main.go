@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command loadgen publishes synthetic orders at a fixed rate through the order
+// event publisher the checkout service would use, decorators included, and
+// reports the throughput and publish latency reached, for capacity planning of
+// the order topic.
+//
+// The publisher is configured from the same environment as the service, such
+// as KAFKA_ADDR or ORDER_EVENT_WEBHOOK_URL. Failed publishes are counted
+// rather than sent to a fallback.
+//
+// Usage:
+//
+//	KAFKA_ADDR=localhost:9092 go run ./cmd/loadgen -publisher kafka -rate 200 -items 10 -duration 30s
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func main() {
+	publisher := flag.String("publisher", "", "order event publisher: kafka, webhook, spool or noop (default ORDER_EVENT_PUBLISHER)")
+	var opts options
+	flag.IntVar(&opts.rate, "rate", 100, "orders to publish per second")
+	flag.DurationVar(&opts.duration, "duration", 10*time.Second, "how long to publish for")
+	flag.IntVar(&opts.items, "items", 5, "items per order, which sets the payload size")
+	flag.IntVar(&opts.concurrency, "concurrency", 64, "maximum publishes in flight")
+	flag.Parse()
+	if err := opts.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(*publisher)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	chain, err := adapters.NewOrderEventPublisherFromConfig(cfg.OrderEvents, cfg.Kafka, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(1)
+	}
+	pub := wiring.Decorate(chain, wiring.PublisherDecorators(cfg, logger))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	r := run(ctx, pub, opts)
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if l, ok := pub.(ports.Lifecycle); ok {
+		if err := l.Close(closeCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		}
+	}
+
+	fmt.Printf("publisher      %s\n", cfg.OrderEvents.Publisher)
+	r.print(os.Stdout)
+	if r.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadConfig reads the settings of the order event publisher from the
+// environment, as the service does, with publisher in place of
+// ORDER_EVENT_PUBLISHER when set. The services the checkout calls need not be
+// configured.
+func loadConfig(publisher string) (*config.Config, error) {
+	cfg := &config.Config{}
+	env := struct {
+		Debug       bool `env:"CHECKOUT_DEBUG"`
+		Kafka       config.Kafka
+		OrderEvents config.OrderEvents
+	}{}
+	if err := config.Parse(&env, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	cfg.Debug, cfg.Kafka, cfg.OrderEvents = env.Debug, env.Kafka, env.OrderEvents
+
+	if publisher != "" {
+		cfg.OrderEvents.Publisher = publisher
+	}
+	if cfg.OrderEvents.Publisher == "" {
+		cfg.OrderEvents.Publisher = adapters.PublisherNoOp
+		if cfg.Kafka.Addr != "" {
+			cfg.OrderEvents.Publisher = adapters.PublisherKafka
+		}
+	}
+	if cfg.OrderEvents.SpoolPath == "" {
+		cfg.OrderEvents.SpoolPath = filepath.Join(os.TempDir(), "checkout-loadgen.spool")
+	}
+	// Measure the chosen publisher alone
+	cfg.OrderEvents.Fallback = adapters.PublisherNone
+	return cfg, nil
+}
@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command replay republishes order events kept aside by the checkout service:
+// the orders of the spool or of the files of the file publisher, or the
+// OrderResult messages of the dead-letter topic. It publishes them through the order event publisher the service
+// would use, decorators included, or another one chosen with -publisher.
+//
+// Events can be selected by order ID and, for the dead-letter topic, by the
+// time they were dead-lettered. The sources are read but never changed: the
+// service still replays its spool itself, and consumers deduplicate by order
+// ID. The outbox lives in the memory of the service and cannot be replayed
+// from outside it.
+//
+// Usage:
+//
+//	go run ./cmd/replay -from spool -order-id order-1,order-2 -dry-run
+//	ORDER_EVENT_FILE_FORMAT=protobuf go run ./cmd/replay -from file -file /var/log/checkout/orders.pb
+//	KAFKA_ADDR=localhost:9092 go run ./cmd/replay -from dlq -since 2025-01-01T10:00:00Z -until 2025-01-01T11:00:00Z
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func main() {
+	from := flag.String("from", "spool", "source of the events: spool, file or dlq")
+	spool := flag.String("spool", "", "spool file to read (default ORDER_EVENT_SPOOL_PATH)")
+	file := flag.String("file", "", "file of the file publisher to read, its rotated files included, in ORDER_EVENT_FILE_FORMAT (default ORDER_EVENT_FILE_PATH)")
+	topic := flag.String("topic", kafka.DeadLetterTopic, "dead-letter topic to read")
+	publisher := flag.String("publisher", "", "order event publisher to replay to: kafka, webhook, spool, file or noop (default ORDER_EVENT_PUBLISHER)")
+	orderIDs := flag.String("order-id", "", "comma-separated order IDs to replay (default all)")
+	since := flag.String("since", "", "only replay events written at or after this RFC 3339 time")
+	until := flag.String("until", "", "only replay events written before this RFC 3339 time")
+	dryRun := flag.Bool("dry-run", false, "print the events that would be replayed without publishing them")
+	flag.Parse()
+
+	f, err := parseFilter(*orderIDs, *since, *until)
+	if err == nil && *from != "spool" && *from != "file" && *from != "dlq" {
+		err = fmt.Errorf("-from %q: expected spool, file or dlq", *from)
+	}
+	if err == nil && *from != "dlq" && f.timed() {
+		err = fmt.Errorf("-since and -until need -from dlq: orders of the spool and of files carry no time")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(*publisher)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(1)
+	}
+	if *spool == "" {
+		*spool = cfg.OrderEvents.SpoolPath
+	}
+	if *from == "spool" && cfg.OrderEvents.Publisher == adapters.PublisherSpool && filepath.Clean(*spool) == filepath.Clean(cfg.OrderEvents.SpoolPath) {
+		fmt.Fprintln(os.Stderr, "replay: refusing to replay a spool into itself")
+		os.Exit(2)
+	}
+	if *file == "" {
+		*file = cfg.OrderEvents.File.Path
+	}
+	if *from == "file" && *file == "" {
+		fmt.Fprintln(os.Stderr, "replay: -from file needs -file or ORDER_EVENT_FILE_PATH")
+		os.Exit(2)
+	}
+	if *from == "file" && cfg.OrderEvents.Publisher == adapters.PublisherFile && filepath.Clean(*file) == filepath.Clean(cfg.OrderEvents.File.Path) {
+		fmt.Fprintln(os.Stderr, "replay: refusing to replay a file into itself")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var events []event
+	var unreadable int
+	switch *from {
+	case "spool":
+		events, unreadable, err = readSpool(*spool)
+	case "file":
+		events, unreadable, err = readFile(*file, cfg.OrderEvents.File.Format)
+	default:
+		events, unreadable, err = readTopic(ctx, cfg.Kafka, *topic)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	var pub ports.OrderEventPublisher
+	if !*dryRun {
+		chain, err := adapters.NewOrderEventPublisherFromConfig(cfg.OrderEvents, cfg.Kafka, logger)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+			os.Exit(1)
+		}
+		pub = wiring.Decorate(chain, wiring.PublisherDecorators(cfg, logger))
+	}
+
+	r := replay(ctx, events, f, pub, *dryRun, os.Stdout)
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if l, ok := pub.(ports.Lifecycle); ok {
+		if err := l.Close(closeCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		}
+	}
+
+	fmt.Printf("read %d events (%d unreadable), selected %d, replayed %d, failed %d\n",
+		len(events)+unreadable, unreadable, r.selected, r.replayed, r.failed)
+	if r.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// parseFilter parses the selection flags.
+func parseFilter(orderIDs, since, until string) (filter, error) {
+	var f filter
+	if orderIDs != "" {
+		f.orderIDs = map[string]bool{}
+		for _, id := range strings.Split(orderIDs, ",") {
+			f.orderIDs[strings.TrimSpace(id)] = true
+		}
+	}
+	var err error
+	if since != "" {
+		if f.since, err = time.Parse(time.RFC3339, since); err != nil {
+			return f, fmt.Errorf("-since: %w", err)
+		}
+	}
+	if until != "" {
+		if f.until, err = time.Parse(time.RFC3339, until); err != nil {
+			return f, fmt.Errorf("-until: %w", err)
+		}
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && !f.since.Before(f.until) {
+		return f, fmt.Errorf("-since %s is not before -until %s", since, until)
+	}
+	return f, nil
+}
+
+// readTopic reads the dead-letter topic of the broker at KAFKA_ADDR, decoding
+// its messages in ORDER_EVENT_DECODE_MODE.
+func readTopic(ctx context.Context, k config.Kafka, topic string) ([]event, int, error) {
+	if k.Addr == "" {
+		return nil, 0, fmt.Errorf("KAFKA_ADDR is not set")
+	}
+	mode, err := adapters.ParseDecodeMode(k.DecodeMode)
+	if err != nil {
+		return nil, 0, err
+	}
+	cfg := sarama.NewConfig()
+	cfg.Version = kafka.ProtocolVersion
+	client, err := sarama.NewClient([]string{k.Addr}, cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer consumer.Close()
+	return readDeadLetters(ctx, consumer, client, topic, mode)
+}
+
+// loadConfig reads the settings of the order event publisher from the
+// environment, as the service does, with publisher in place of
+// ORDER_EVENT_PUBLISHER when set. The services the checkout calls need not be
+// configured.
+func loadConfig(publisher string) (*config.Config, error) {
+	cfg := &config.Config{}
+	env := struct {
+		Debug       bool `env:"CHECKOUT_DEBUG"`
+		Kafka       config.Kafka
+		OrderEvents config.OrderEvents
+	}{}
+	if err := config.Parse(&env, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	cfg.Debug, cfg.Kafka, cfg.OrderEvents = env.Debug, env.Kafka, env.OrderEvents
+
+	if publisher != "" {
+		cfg.OrderEvents.Publisher = publisher
+	}
+	if cfg.OrderEvents.Publisher == "" {
+		cfg.OrderEvents.Publisher = adapters.PublisherNoOp
+		if cfg.Kafka.Addr != "" {
+			cfg.OrderEvents.Publisher = adapters.PublisherKafka
+		}
+	}
+	if cfg.OrderEvents.SpoolPath == "" {
+		cfg.OrderEvents.SpoolPath = filepath.Join(os.TempDir(), "checkout-order-events.spool")
+	}
+	// A failed replay is reported rather than spooled again
+	cfg.OrderEvents.Fallback = adapters.PublisherNone
+	return cfg, nil
+}
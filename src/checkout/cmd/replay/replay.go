@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// filter selects the events to replay. Its zero value selects every event.
+type filter struct {
+	orderIDs map[string]bool
+	// since and until bound the time of an event, each unless zero
+	since, until time.Time
+}
+
+func (f filter) match(e event) bool {
+	if len(f.orderIDs) > 0 && !f.orderIDs[e.order.GetOrderId()] {
+		return false
+	}
+	if !f.since.IsZero() && e.at.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !e.at.Before(f.until) {
+		return false
+	}
+	return true
+}
+
+// timed reports whether f filters by time.
+func (f filter) timed() bool {
+	return !f.since.IsZero() || !f.until.IsZero()
+}
+
+// result counts the events of a replay.
+type result struct {
+	selected, replayed, failed int
+}
+
+// replay publishes the events f selects, in order, and prints one line per
+// event. A failed event does not stop the replay. With dryRun, the events are
+// printed but not published.
+func replay(ctx context.Context, events []event, f filter, pub ports.OrderEventPublisher, dryRun bool, out io.Writer) result {
+	var r result
+	for _, e := range events {
+		if !f.match(e) {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		r.selected++
+		line := e.origin + " " + e.order.GetOrderId()
+		if !e.at.IsZero() {
+			line += " " + e.at.UTC().Format(time.RFC3339)
+		}
+		if dryRun {
+			fmt.Fprintf(out, "%s: would replay\n", line)
+			continue
+		}
+		publishCtx := ctx
+		if len(e.headers) > 0 {
+			publishCtx = adapters.WithMessageHeaders(ctx, e.headers)
+		}
+		if err := pub.PublishOrderCompleted(publishCtx, e.order); err != nil {
+			r.failed++
+			fmt.Fprintf(out, "%s: FAILED %v\n", line, err)
+			continue
+		}
+		r.replayed++
+		fmt.Fprintf(out, "%s: replayed\n", line)
+	}
+	return r
+}
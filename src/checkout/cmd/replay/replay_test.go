@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	portmocks "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports/mocks"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestReplay(t *testing.T) {
+	at := func(minute int) time.Time { return testdata.Epoch.Add(time.Duration(minute) * time.Minute) }
+	events := []event{
+		{order: testdata.NewOrder().WithID("order-1").Build(), origin: "orders/0@1", at: at(0)},
+		{order: testdata.NewOrder().WithID("order-2").Build(), origin: "orders/0@2", at: at(10)},
+		{order: testdata.NewOrder().WithID("order-3").Build(), origin: "orders/0@3", at: at(20)},
+	}
+
+	tests := []struct {
+		name   string
+		filter filter
+		dryRun bool
+		fail   string
+		want   string
+	}{
+		{
+			name: "all",
+			want: "orders/0@1 order-1 2025-01-01T00:00:00Z: replayed\n" +
+				"orders/0@2 order-2 2025-01-01T00:10:00Z: replayed\n" +
+				"orders/0@3 order-3 2025-01-01T00:20:00Z: replayed\n",
+		},
+		{
+			name:   "by order ID",
+			filter: filter{orderIDs: map[string]bool{"order-1": true, "order-3": true}},
+			want: "orders/0@1 order-1 2025-01-01T00:00:00Z: replayed\n" +
+				"orders/0@3 order-3 2025-01-01T00:20:00Z: replayed\n",
+		},
+		{
+			name:   "by time range",
+			filter: filter{since: at(10), until: at(20)},
+			want:   "orders/0@2 order-2 2025-01-01T00:10:00Z: replayed\n",
+		},
+		{
+			name:   "dry run",
+			filter: filter{since: at(5)},
+			dryRun: true,
+			want: "orders/0@2 order-2 2025-01-01T00:10:00Z: would replay\n" +
+				"orders/0@3 order-3 2025-01-01T00:20:00Z: would replay\n",
+		},
+		{
+			name: "failure",
+			fail: "order-2",
+			want: "orders/0@1 order-1 2025-01-01T00:00:00Z: replayed\n" +
+				"orders/0@2 order-2 2025-01-01T00:10:00Z: FAILED broker unavailable\n" +
+				"orders/0@3 order-3 2025-01-01T00:20:00Z: replayed\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := portmocks.NewMockOrderEventPublisher(gomock.NewController(t))
+			publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, order *pb.OrderResult) error {
+					if order.GetOrderId() == tt.fail {
+						return errors.New("broker unavailable")
+					}
+					return nil
+				}).
+				AnyTimes()
+
+			var out bytes.Buffer
+			r := replay(context.Background(), events, tt.filter, publisher, tt.dryRun, &out)
+			if out.String() != tt.want {
+				t.Errorf("printed\n%s\nwant\n%s", out.String(), tt.want)
+			}
+			selected := strings.Count(tt.want, "\n")
+			failed := strings.Count(tt.want, "FAILED")
+			replayed := selected - failed
+			if tt.dryRun {
+				replayed = 0
+			}
+			if want := (result{selected, replayed, failed}); r != want {
+				t.Errorf("replay() = %+v, want %+v", r, want)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter("order-1, order-2", "2025-01-01T10:00:00Z", "")
+	if err != nil || len(f.orderIDs) != 2 || !f.orderIDs["order-2"] || f.since.Hour() != 10 || !f.until.IsZero() {
+		t.Errorf("parseFilter() = %+v, %v", f, err)
+	}
+	for _, args := range [][3]string{
+		{"", "yesterday", ""},
+		{"", "", "2025-01-01"},
+		{"", "2025-01-01T11:00:00Z", "2025-01-01T10:00:00Z"},
+	} {
+		if _, err := parseFilter(args[0], args[1], args[2]); err == nil {
+			t.Errorf("parseFilter(%q) = nil error, want an error", args)
+		}
+	}
+}
+
+// fixedOffsets reports a partition holding the offsets from oldest to
+// newest-1.
+type fixedOffsets struct{ oldest, newest int64 }
+
+func (o fixedOffsets) GetOffset(_ string, _ int32, time int64) (int64, error) {
+	if time == sarama.OffsetOldest {
+		return o.oldest, nil
+	}
+	return o.newest, nil
+}
+
+func TestReadDeadLetters(t *testing.T) {
+	value, err := proto.Marshal(testdata.NewOrder().WithID("order-1").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := func(key, value string) *sarama.RecordHeader {
+		return &sarama.RecordHeader{Key: []byte(key), Value: []byte(value)}
+	}
+
+	consumer := mocks.NewConsumer(t, nil)
+	consumer.SetTopicMetadata(map[string][]int32{"orders-dlq": {0}})
+	pc := consumer.ExpectConsumePartition("orders-dlq", 0, 5)
+	pc.YieldMessage(&sarama.ConsumerMessage{Value: value, Timestamp: testdata.Epoch, Headers: []*sarama.RecordHeader{
+		header(adapters.DeadLetterTopicHeader, "orders"),
+		header(adapters.DeadLetterPartitionHeader, "2"),
+		header(adapters.DeadLetterOffsetHeader, "41"),
+	}})
+	pc.YieldMessage(&sarama.ConsumerMessage{Value: []byte("not protobuf")})
+	pc.YieldMessage(&sarama.ConsumerMessage{Value: value, Headers: []*sarama.RecordHeader{header(adapters.EventTypeHeader, "OutOfStock")}})
+
+	events, unreadable, err := readDeadLetters(context.Background(), consumer, fixedOffsets{oldest: 5, newest: 8}, "orders-dlq", adapters.DecodeLenient)
+	if err != nil {
+		t.Fatalf("readDeadLetters() = %v", err)
+	}
+	if unreadable != 1 {
+		t.Errorf("unreadable = %d, want 1", unreadable)
+	}
+	if len(events) != 1 {
+		t.Fatalf("read %d events, want the OrderResult one", len(events))
+	}
+	if e := events[0]; e.order.GetOrderId() != "order-1" || e.origin != "orders/2@41" || !e.at.Equal(testdata.Epoch) {
+		t.Errorf("event = %s %s %v, want order-1 from orders/2@41 at %v", e.order.GetOrderId(), e.origin, e.at, testdata.Epoch)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// event is an order event read from a source.
+type event struct {
+	order *pb.OrderResult
+	// headers are the message headers the event was published with, if the
+	// source keeps them
+	headers map[string]string
+	// origin tells where the event comes from, such as the original
+	// topic, partition and offset of a dead-lettered message
+	origin string
+	// at is when the event was written, zero if the source does not say
+	at time.Time
+}
+
+// readSpool returns the events of the spool file at path.
+func readSpool(path string) ([]event, int, error) {
+	spooled, unreadable, err := adapters.ReadSpoolEvents(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	events := make([]event, len(spooled))
+	for i, e := range spooled {
+		events[i] = event{order: e.Order, headers: e.Headers, origin: fmt.Sprintf("spool#%d", i+1)}
+	}
+	return events, unreadable, nil
+}
+
+// readFile returns the events of the files a file publisher wrote to path in
+// format, the rotated ones first.
+func readFile(path, format string) ([]event, int, error) {
+	orders, unreadable, err := adapters.ReadOrderEventFiles(path, format)
+	if err != nil {
+		return nil, 0, err
+	}
+	events := make([]event, len(orders))
+	for i, order := range orders {
+		events[i] = event{order: order, origin: fmt.Sprintf("file#%d", i+1)}
+	}
+	return events, unreadable, nil
+}
+
+// offsetGetter returns the offsets of a partition, as sarama.Client does.
+type offsetGetter interface {
+	GetOffset(topic string, partition int32, time int64) (int64, error)
+}
+
+// readDeadLetters returns the OrderResult events of the dead-letter topic,
+// decoded in mode, from the oldest message of each partition to the newest
+// when it was called. It does not commit offsets, so the topic can be read again. The
+// messages of other event types are not counted as unreadable, since the
+// order event publisher has no way to republish them.
+func readDeadLetters(ctx context.Context, consumer sarama.Consumer, offsets offsetGetter, topic string, mode adapters.DecodeMode) ([]event, int, error) {
+	partitions, err := consumer.Partitions(topic)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list the partitions of %s: %w", topic, err)
+	}
+
+	var events []event
+	unreadable := 0
+	for _, partition := range partitions {
+		newest, err := offsets.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get the newest offset of %s/%d: %w", topic, partition, err)
+		}
+		oldest, err := offsets.GetOffset(topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get the oldest offset of %s/%d: %w", topic, partition, err)
+		}
+		if oldest >= newest {
+			continue
+		}
+
+		pc, err := consumer.ConsumePartition(topic, partition, oldest)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to consume %s/%d: %w", topic, partition, err)
+		}
+		for done := false; !done; {
+			select {
+			case <-ctx.Done():
+				pc.AsyncClose()
+				return nil, 0, ctx.Err()
+			case msg := <-pc.Messages():
+				e, ok := deadLetterEvent(msg, mode)
+				switch {
+				case ok:
+					events = append(events, e)
+				case e.origin != "":
+					unreadable++
+				}
+				done = msg.Offset >= newest-1
+			}
+		}
+		if err := pc.Close(); err != nil {
+			return nil, 0, fmt.Errorf("failed to close %s/%d: %w", topic, partition, err)
+		}
+	}
+	return events, unreadable, nil
+}
+
+// deadLetterEvent decodes a dead-lettered message in mode. It returns false with an
+// origin for a message that cannot be decoded, and false without one for an
+// event of another type than OrderResult.
+func deadLetterEvent(msg *sarama.ConsumerMessage, mode adapters.DecodeMode) (event, bool) {
+	headers := map[string]string{}
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers[string(h.Key)] = string(h.Value)
+		}
+	}
+	if eventType, ok := headers[adapters.EventTypeHeader]; ok && eventType != string(ports.OrderCompletedEvent) {
+		return event{}, false
+	}
+
+	e := event{origin: fmt.Sprintf("%s/%d@%d", msg.Topic, msg.Partition, msg.Offset), at: msg.Timestamp}
+	if topic, ok := headers[adapters.DeadLetterTopicHeader]; ok {
+		partition, _ := strconv.Atoi(headers[adapters.DeadLetterPartitionHeader])
+		offset, _ := strconv.ParseInt(headers[adapters.DeadLetterOffsetHeader], 10, 64)
+		e.origin = fmt.Sprintf("%s/%d@%d", topic, partition, offset)
+	}
+	order, err := adapters.DecodeOrderResult(msg.Value, mode)
+	if err != nil {
+		return e, false
+	}
+	e.order = order
+	return e, true
+}
@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command schemagen writes the consumer-facing schema artifacts of the order
+// event (.proto, JSON Schema and markdown tables), the OpenAPI document of the
+// HTTP facade and the GraphQL schema, derived from the compiled descriptors, so
+// that published contracts never drift from the code.
+//
+// Usage:
+//
+//	go run ./cmd/schemagen -out schemas
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/schema"
+)
+
+func main() {
+	out := flag.String("out", "schemas", "directory to write schema artifacts to")
+	flag.Parse()
+
+	artifacts, err := schema.Artifacts(schema.OrderResultDescriptor())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "schemagen: %v\n", err)
+		os.Exit(1)
+	}
+	if artifacts[schema.OpenAPIFile], err = schema.OpenAPI(); err != nil {
+		fmt.Fprintf(os.Stderr, "schemagen: %v\n", err)
+		os.Exit(1)
+	}
+	artifacts[schema.GraphQLFile] = []byte(schema.GraphQLSchema())
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "schemagen: %v\n", err)
+		os.Exit(1)
+	}
+
+	names := make([]string, 0, len(artifacts))
+	for name := range artifacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(*out, name)
+		if err := os.WriteFile(path, artifacts[name], 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "schemagen: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("wrote", path)
+	}
+}
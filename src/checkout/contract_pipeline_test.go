@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/contracttest"
+)
+
+// contractPair is a consumer contract test and the provider test verifying
+// the pact file it records.
+type contractPair struct {
+	pactFile string
+	consumer func(*testing.T)
+	provider func(*testing.T)
+}
+
+// contractPairs lists the contracts recorded in this repository.
+// TestOrderEventPublisherContract is not among them, as its consumer lives in
+// the accounting service.
+var contractPairs = []contractPair{
+	{orderQueryPactFile, TestOrderQueryConsumerContract, TestOrderQueryProviderContract},
+	{webClientPactFile, TestWebClientConsumerContract, TestWebClientProviderContract},
+	{inventoryPactFile, TestInventoryConsumerContract, TestInventoryProviderContract},
+	{loyaltyPactFile, TestLoyaltyConsumerContract, TestLoyaltyProviderContract},
+	{paymentsPactFile, TestPaymentsConsumerContract, TestPaymentsProviderContract},
+	{accountingPactFile, TestAccountingConsumerContract, TestAccountingProviderContract},
+	{promotionsPactFile, TestPromotionsConsumerContract, TestPromotionsProviderContract},
+}
+
+// TestContractPipeline runs the whole contract loop in one step: for each
+// contract it deletes the pact file, records it again with the consumer test,
+// and verifies the provider against the file just written, never the broker.
+// A contract whose consumer fails is not verified.
+func TestContractPipeline(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	t.Setenv("CONTRACT_TESTS", string(testmode.ContractLocal))
+	for _, pair := range contractPairs {
+		name := strings.TrimSuffix(filepath.Base(pair.pactFile), ".json")
+		t.Run(name, func(t *testing.T) {
+			if err := os.Remove(pair.pactFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("failed to remove the previous contract: %v", err)
+			}
+			if !t.Run("consumer", pair.consumer) {
+				t.FailNow()
+			}
+			if _, err := os.Stat(pair.pactFile); err != nil {
+				t.Fatalf("consumer recorded no contract: %v", err)
+			}
+			t.Run("provider", pair.provider)
+		})
+	}
+}
+
+// pactSource points req at the pacts CONTRACT_TESTS selects: those of the
+// broker, publishing the results there, or pactFile, skipping t when
+// consumerTest has not recorded it yet. It skips t when the contract tests
+// are off.
+func pactSource(t *testing.T, req *provider.VerifyRequest, pactFile, consumerTest string) {
+	t.Helper()
+	modes := testmode.Require(t, testmode.Contract)
+	var source contracttest.Source
+	if modes.Contract == testmode.ContractBroker {
+		source = contracttest.Source{
+			BrokerURL:       modes.Broker.URL,
+			BrokerUsername:  modes.Broker.Username,
+			BrokerPassword:  modes.Broker.Password,
+			ProviderVersion: modes.Broker.ProviderVersion,
+			ProviderBranch:  modes.Broker.ProviderBranch,
+		}
+	}
+	source.Configure(t, req, "checkout-provider", pactFile, consumerTest)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/message"
+	messagev3 "github.com/pact-foundation/pact-go/v2/message/v3"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Pact message contract for orders placed in another currency than the
+// catalog's. Item and shipping costs are converted from USD at checkout, at
+// the same cached rate for the whole order, and rounded down to the cent. The
+// costs are exact values rather than type matchers, so that the conversion
+// semantics are part of the contract.
+const (
+	accountingConsumer = "accounting-consumer"
+	accountingPactFile = "pacts/accounting-consumer-checkout-provider.json"
+	convertedOrder     = "an order-result message converted to the user's currency"
+)
+
+// convertedOrderResult is the part of an OrderResult the accounting consumer
+// reads.
+type convertedOrderResult struct {
+	OrderID      string          `json:"orderId"`
+	ShippingCost convertedAmount `json:"shippingCost"`
+	Items        []struct {
+		Cost convertedAmount `json:"cost"`
+	} `json:"items"`
+}
+
+// convertedAmount is a cost in the user's currency.
+type convertedAmount struct {
+	CurrencyCode string `json:"currencyCode"`
+	Units        int64  `json:"units"`
+	Nanos        int32  `json:"nanos"`
+}
+
+// TestAccountingConsumerContract records an order placed in EUR.
+func TestAccountingConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+		Consumer: accountingConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(accountingPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.EUROrder.Given()).
+		ExpectsToReceive(convertedOrder).
+		WithMetadata(map[string]string{
+			"contentType":            "application/json",
+			adapters.EventTypeHeader: string(ports.OrderCompletedEvent),
+		}).
+		WithJSONContent(matchers.StructMatcher{
+			"orderId": matchers.Like("order-12345-contract-test"),
+			// USD 8 at 0.9
+			"shippingCost": map[string]any{"currencyCode": "EUR", "units": 7, "nanos": 200000000},
+			// USD 19.99 at 0.9 is EUR 17.991, rounded down to the cent
+			"items": []any{map[string]any{
+				"item": map[string]any{"productId": "OLJCESPC7Z", "quantity": 2},
+				"cost": map[string]any{"currencyCode": "EUR", "units": 17, "nanos": 990000000},
+			}},
+		}).
+		AsType(&convertedOrderResult{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			order := m.Content.(*convertedOrderResult)
+			if order.ShippingCost.CurrencyCode != "EUR" || len(order.Items) == 0 {
+				return fmt.Errorf("order %+v is not in EUR", order)
+			}
+			for _, item := range order.Items {
+				if item.Cost.CurrencyCode != order.ShippingCost.CurrencyCode || item.Cost.Nanos%10_000_000 != 0 {
+					return fmt.Errorf("item cost %+v is not in whole cents of %s", item.Cost, order.ShippingCost.CurrencyCode)
+				}
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAccountingProviderContract places an order in EUR and verifies the
+// OrderResult it emits against the recorded accounting contract.
+func TestAccountingProviderContract(t *testing.T) {
+	messageHandlers := message.Handlers{
+		convertedOrder: func(states []models.ProviderState) (message.Body, message.Metadata, error) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			req := testPlaceOrderRequest()
+			req.UserCurrency = "EUR"
+			if _, err := svc.PlaceOrder(context.Background(), req); err != nil {
+				return nil, nil, err
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				return nil, nil, err
+			}
+			if len(batches.batches) != 1 {
+				return nil, nil, fmt.Errorf("published %v, want one batch", batches.batches)
+			}
+			for _, event := range batches.batches[0] {
+				if event.Type != ports.OrderCompletedEvent {
+					continue
+				}
+				body, err := serialization.ToConsumerJSON(event.Order)
+				if err != nil {
+					return nil, nil, err
+				}
+				return body, message.Metadata{
+					"contentType":            "application/json",
+					adapters.EventTypeHeader: string(event.Type),
+				}, nil
+			}
+			return nil, nil, fmt.Errorf("published %v, want an OrderCompleted event", batches.batches)
+		},
+	}
+	// fakeCurrencyClient converts USD to EUR at 0.9
+	stateHandlers := providerstate.Handlers(providerstate.EUROrder.Bind(nil))
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		StateHandlers:   stateHandlers,
+		MessageHandlers: messageHandlers,
+	}
+	pactSource(t, &verifyRequest, accountingPactFile, "TestAccountingConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package debugserver
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+)
+
+// NewHandler returns the handler for the optional debug listener. It serves
+// pprof profiles under /debug/pprof/, expvar variables under /debug/vars and
+// the JSON encoding of state() under /debug/publisher. When logLevel is not nil
+// it is served under /debug/loglevel to change the log level at runtime, and
+// when backfill is not nil under /debug/backfill to republish order history.
+//
+// The handler exposes process internals and must never be reachable from
+// outside the cluster.
+func NewHandler(state func() any, logLevel, backfill http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/publisher", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(state()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	if logLevel != nil {
+		mux.Handle("/debug/loglevel", logLevel)
+	}
+	if backfill != nil {
+		mux.Handle("/debug/backfill", backfill)
+	}
+	return mux
+}
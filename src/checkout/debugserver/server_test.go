@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package debugserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(NewHandler(func() any {
+		return map[string]int{"in_flight": 3}
+	}, handler, handler))
+	defer srv.Close()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/vars", "/debug/publisher", "/debug/loglevel", "/debug/backfill"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s = %d, want %d", path, resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/debug/publisher")
+	if err != nil {
+		t.Fatalf("GET /debug/publisher: %v", err)
+	}
+	defer resp.Body.Close()
+	var got map[string]int
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode /debug/publisher: %v", err)
+	}
+	if got["in_flight"] != 3 {
+		t.Errorf("/debug/publisher = %v, want in_flight=3", got)
+	}
+}
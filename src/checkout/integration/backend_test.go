@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+)
+
+// brokerKafka is a real broker.
+type brokerKafka struct {
+	addr string
+}
+
+func (k brokerKafka) producer(t *testing.T, logger *slog.Logger) sarama.AsyncProducer {
+	producer, err := kafka.CreateKafkaProducer([]string{k.addr}, logger, func(c *sarama.Config) {
+		// Wait for the broker, so that an acknowledged order is on the topic
+		c.Producer.RequiredAcks = sarama.WaitForLocal
+	})
+	if err != nil {
+		t.Fatalf("CreateKafkaProducer() = %v", err)
+	}
+	return producer
+}
+
+func (k brokerKafka) consumeOrder(t *testing.T, orderID string) receivedOrder {
+	return consumeOrder(t, k.addr, orderID)
+}
+
+// fakeKafka stands in for the broker in process: its producer acknowledges
+// the one message the round trip publishes, and consuming hands the
+// acknowledged messages to the subscriber as a consumer group would, so
+// that everything but the network is exercised.
+type fakeKafka struct {
+	kafka *kafkatest.Producer
+}
+
+func newFakeKafka(t *testing.T) *fakeKafka {
+	return &fakeKafka{kafka: kafkatest.NewProducer(t)}
+}
+
+func (k *fakeKafka) producer(*testing.T, *slog.Logger) sarama.AsyncProducer {
+	k.kafka.ExpectSuccess()
+	return k.kafka
+}
+
+func (k *fakeKafka) consumeOrder(t *testing.T, orderID string) receivedOrder {
+	t.Helper()
+	handler := &recordingHandler{orderID: orderID, received: make(chan receivedOrder, 1)}
+	subscriber := adapters.NewKafkaOrderEventSubscriber(handler, adapters.DecodeStrict, slog.New(slog.DiscardHandler))
+	for offset, published := range k.kafka.Messages() {
+		msg := kafkatest.Consumed(t, published, int64(offset))
+		handler.headers = kafkatest.Headers(published)
+		if err := subscriber.HandleMessage(context.Background(), msg); err != nil {
+			t.Fatalf("HandleMessage() = %v", err)
+		}
+		select {
+		case received := <-handler.received:
+			return received
+		default:
+		}
+	}
+	t.Fatalf("order %s was not published", orderID)
+	return receivedOrder{}
+}
@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package integration holds the end-to-end tests of the checkout adapters
+// against real infrastructure. The tests are built with the integration tag:
+//
+//	go test -tags integration ./integration
+//
+// Kafka runs in a throwaway container started with the testcontainers Kafka
+// module, or is taken from KAFKA_INTEGRATION_ADDR when set. Tests skip when neither is
+// available, or fail with INTEGRATION=kafka. INTEGRATION=fake runs them
+// against an in-process fake of Kafka instead.
+package integration
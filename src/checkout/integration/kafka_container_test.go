@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	tckafka "github.com/testcontainers/testcontainers-go/modules/kafka"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+)
+
+// kafkaImage is the single-node KRaft broker the suite runs against, one of
+// the Confluent images the testcontainers Kafka module configures.
+const kafkaImage = "confluentinc/confluent-local:7.5.0"
+
+// kafkaBackend is the Kafka a test publishes orders to and consumes them
+// from.
+type kafkaBackend interface {
+	// producer returns a producer writing to the backend
+	producer(t *testing.T, logger *slog.Logger) sarama.AsyncProducer
+	// consumeOrder consumes the orders topic until the subscriber hands
+	// orderID to its handler
+	consumeOrder(t *testing.T, orderID string) receivedOrder
+}
+
+// startKafka returns the Kafka INTEGRATION selects: the in-process fake, or
+// a real broker from startBroker.
+func startKafka(t *testing.T) kafkaBackend {
+	t.Helper()
+	modes := testmode.Require(t, testmode.Integration)
+	if modes.Integration == testmode.IntegrationFake {
+		return newFakeKafka(t)
+	}
+	return brokerKafka{addr: startBroker(t, modes.Integration == testmode.IntegrationKafka)}
+}
+
+// startBroker returns the address of a Kafka broker for the test. It uses
+// KAFKA_INTEGRATION_ADDR when set, and otherwise runs kafkaImage with the
+// testcontainers Kafka module, which advertises the port docker mapped and
+// removes the container when the test ends. When no broker can be started,
+// the test fails if required and skips otherwise.
+func startBroker(t *testing.T, required bool) string {
+	t.Helper()
+	unavailable := t.Skipf
+	if required {
+		unavailable = t.Fatalf
+	}
+	if addr := os.Getenv("KAFKA_INTEGRATION_ADDR"); addr != "" {
+		waitForKafka(t, addr)
+		return addr
+	}
+
+	ctx := context.Background()
+	container, err := tckafka.Run(ctx, kafkaImage)
+	if container != nil {
+		t.Cleanup(func() {
+			if err := container.Terminate(context.Background()); err != nil {
+				t.Logf("failed to remove the %s container: %v", kafkaImage, err)
+			}
+		})
+	}
+	if err != nil {
+		unavailable("failed to start %s, set KAFKA_INTEGRATION_ADDR or INTEGRATION=fake to run without docker: %v", kafkaImage, err)
+	}
+	brokers, err := container.Brokers(ctx)
+	if err != nil {
+		t.Fatalf("failed to get the address of %s: %v", kafkaImage, err)
+	}
+	// Run returns once the broker logged that it is running
+	return brokers[0]
+}
+
+// waitForKafka waits up to a minute for the broker at addr to serve metadata,
+// since the broker of KAFKA_INTEGRATION_ADDR may still be starting.
+func waitForKafka(t *testing.T, addr string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cfg := sarama.NewConfig()
+	cfg.Version = kafka.ProtocolVersion
+	for {
+		client, err := sarama.NewClient([]string{addr}, cfg)
+		if err == nil {
+			client.Close()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("Kafka at %s is not ready: %v", addr, err)
+		case <-time.After(time.Second):
+		}
+	}
+}
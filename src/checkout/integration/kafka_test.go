@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+// TestKafkaOrderEventRoundTrip publishes an order through
+// KafkaOrderEventPublisher to Kafka, consumes it with
+// KafkaOrderEventSubscriber, and checks the payload, the headers and that the
+// trace and baggage of the publisher reach the handler.
+func TestKafkaOrderEventRoundTrip(t *testing.T) {
+	k := startKafka(t)
+	spans := installTracing(t)
+	logger := slog.New(slog.DiscardHandler)
+
+	publisher := adapters.NewKafkaOrderEventPublisher(k.producer(t, logger), logger)
+	t.Cleanup(func() { publisher.Close(context.Background()) })
+
+	order := testdata.NewOrder().
+		WithID("order-"+uuid.NewString()).
+		WithItem("OLJCESPC7Z", 2, testdata.Money("USD", 19, 990000000)).
+		WithShipping(testdata.USD(8)).
+		Build()
+	member, _ := baggage.NewMember(adapters.BaggageSyntheticRequest, "true")
+	bag, _ := baggage.New(member)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	ctx = adapters.WithMessageHeaders(ctx, map[string]string{adapters.EventTypeHeader: "OrderCompleted"})
+	ctx, parent := otel.Tracer("integration").Start(ctx, "PlaceOrder")
+
+	publishCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := publisher.PublishOrderCompleted(publishCtx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	parent.End()
+
+	received := k.consumeOrder(t, order.GetOrderId())
+	if !proto.Equal(received.order, order) {
+		t.Errorf("consumed %v, want %v", received.order, order)
+	}
+	if got := received.headers[adapters.EventTypeHeader]; got != "OrderCompleted" {
+		t.Errorf("header %s = %q, want OrderCompleted", adapters.EventTypeHeader, got)
+	}
+	if received.headers["traceparent"] == "" || received.headers["baggage"] == "" {
+		t.Errorf("headers %v carry no trace context or baggage", received.headers)
+	}
+	if got := received.span.TraceID(); got != parent.SpanContext().TraceID() {
+		t.Errorf("handler ran in trace %s, want the publisher's trace %s", got, parent.SpanContext().TraceID())
+	}
+	if got := received.baggage.Member(adapters.BaggageSyntheticRequest).Value(); got != "true" {
+		t.Errorf("handler baggage %s = %q, want true", adapters.BaggageSyntheticRequest, got)
+	}
+
+	var deliver *tracetest.SpanStub
+	for _, span := range spans.GetSpans() {
+		if span.Name == kafka.Topic+" deliver" && span.SpanContext.TraceID() == parent.SpanContext().TraceID() {
+			deliver = &span
+		}
+	}
+	if deliver == nil {
+		t.Fatalf("no %s deliver span in the publisher's trace", kafka.Topic)
+	}
+	if len(deliver.Links) != 1 || deliver.Links[0].SpanContext.SpanID() != deliver.Parent.SpanID() {
+		t.Errorf("deliver span links %v, want a link to its producer parent %s", deliver.Links, deliver.Parent.SpanID())
+	}
+}
+
+// receivedOrder is an order as the subscriber handed it to the handler.
+type receivedOrder struct {
+	order   *pb.OrderResult
+	headers map[string]string
+	span    trace.SpanContext
+	baggage baggage.Baggage
+}
+
+// consumeOrder reads the orders topic from the start in a new consumer group
+// until the subscriber hands orderID to its handler.
+func consumeOrder(t *testing.T, addr, orderID string) receivedOrder {
+	t.Helper()
+	cfg := sarama.NewConfig()
+	cfg.Version = kafka.ProtocolVersion
+	cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	group, err := sarama.NewConsumerGroup([]string{addr}, "integration-"+uuid.NewString(), cfg)
+	if err != nil {
+		t.Fatalf("NewConsumerGroup() = %v", err)
+	}
+	defer group.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	handler := &recordingHandler{orderID: orderID, received: make(chan receivedOrder, 1)}
+	consumer := &headerRecordingConsumer{
+		KafkaOrderEventSubscriber: adapters.NewKafkaOrderEventSubscriber(handler, adapters.DecodeStrict, slog.New(slog.DiscardHandler)),
+		handler:                   handler,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		for ctx.Err() == nil {
+			if err := group.Consume(ctx, []string{kafka.Topic}, consumer); err != nil && !errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case received := <-handler.received:
+		return received
+	case err := <-errs:
+		t.Fatalf("Consume() = %v", err)
+	case <-ctx.Done():
+		t.Fatalf("order %s was not consumed: %v", orderID, ctx.Err())
+	}
+	return receivedOrder{}
+}
+
+// recordingHandler implements the OrderEventHandler port, and records the
+// order it waits for with the trace context it was handled in.
+type recordingHandler struct {
+	orderID  string
+	headers  map[string]string
+	received chan receivedOrder
+}
+
+func (h *recordingHandler) HandleOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if order.GetOrderId() != h.orderID {
+		return nil
+	}
+	h.received <- receivedOrder{
+		order:   order,
+		headers: h.headers,
+		span:    trace.SpanContextFromContext(ctx),
+		baggage: baggage.FromContext(ctx),
+	}
+	return nil
+}
+
+// headerRecordingConsumer consumes with the subscriber adapter, recording the
+// headers of each message before the adapter handles it.
+type headerRecordingConsumer struct {
+	*adapters.KafkaOrderEventSubscriber
+	handler *recordingHandler
+}
+
+func (c *headerRecordingConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		headers := make(map[string]string, len(msg.Headers))
+		for _, h := range msg.Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+		c.handler.headers = headers
+		if err := c.HandleMessage(session.Context(), msg); err != nil {
+			return err
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// installTracing records spans in memory and propagates trace context and
+// baggage, as the service does.
+func installTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package k8sdetector
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// Environment variables the pod spec sets from the downward API, for example:
+//
+//	env:
+//	  - name: K8S_POD_NAME
+//	    valueFrom:
+//	      fieldRef:
+//	        fieldPath: metadata.name
+const (
+	PodNameEnv       = "K8S_POD_NAME"
+	PodUIDEnv        = "K8S_POD_UID"
+	NamespaceNameEnv = "K8S_NAMESPACE_NAME"
+	NodeNameEnv      = "K8S_NODE_NAME"
+)
+
+// namespaceFile is where Kubernetes mounts the namespace of the pod's service
+// account token.
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Detector detects the Kubernetes pod, namespace and node the process runs in,
+// so that telemetry can be attributed to a specific pod during incident
+// analysis.
+type Detector struct {
+	getenv        func(string) string
+	hostname      func() (string, error)
+	namespaceFile string
+}
+
+// Compile-time check that Detector implements sdkresource.Detector
+var _ sdkresource.Detector = Detector{}
+
+// New creates a Detector that reads the process environment.
+func New() Detector {
+	return Detector{getenv: os.Getenv, hostname: os.Hostname, namespaceFile: namespaceFile}
+}
+
+// Detect returns the Kubernetes attributes set through the downward API. When
+// they are not set but the process runs in a cluster, the pod name falls back
+// to the hostname and the namespace to the service account's namespace. Outside
+// a cluster it returns an empty resource.
+func (d Detector) Detect(context.Context) (*sdkresource.Resource, error) {
+	var attrs []attribute.KeyValue
+	inCluster := d.getenv("KUBERNETES_SERVICE_HOST") != ""
+
+	podName := d.getenv(PodNameEnv)
+	if podName == "" && inCluster {
+		podName, _ = d.hostname()
+	}
+	if podName != "" {
+		attrs = append(attrs, semconv.K8SPodName(podName))
+	}
+	if uid := d.getenv(PodUIDEnv); uid != "" {
+		attrs = append(attrs, semconv.K8SPodUID(uid))
+	}
+
+	namespace := d.getenv(NamespaceNameEnv)
+	if namespace == "" && inCluster {
+		if b, err := os.ReadFile(d.namespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(b))
+		}
+	}
+	if namespace != "" {
+		attrs = append(attrs, semconv.K8SNamespaceName(namespace))
+	}
+	if node := d.getenv(NodeNameEnv); node != "" {
+		attrs = append(attrs, semconv.K8SNodeName(node))
+	}
+
+	if len(attrs) == 0 {
+		return sdkresource.Empty(), nil
+	}
+	return sdkresource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package k8sdetector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	namespaceFile := filepath.Join(t.TempDir(), "namespace")
+	if err := os.WriteFile(namespaceFile, []byte("shop\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "outside a cluster",
+			env:  map[string]string{},
+			want: map[string]string{},
+		},
+		{
+			name: "downward API",
+			env: map[string]string{
+				PodNameEnv:       "checkout-7d9f",
+				PodUIDEnv:        "0b1c",
+				NamespaceNameEnv: "otel-demo",
+				NodeNameEnv:      "node-1",
+			},
+			want: map[string]string{
+				"k8s.pod.name":       "checkout-7d9f",
+				"k8s.pod.uid":        "0b1c",
+				"k8s.namespace.name": "otel-demo",
+				"k8s.node.name":      "node-1",
+			},
+		},
+		{
+			name: "in cluster without downward API",
+			env:  map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"},
+			want: map[string]string{
+				"k8s.pod.name":       "checkout-host",
+				"k8s.namespace.name": "shop",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Detector{
+				getenv:        func(key string) string { return tt.env[key] },
+				hostname:      func() (string, error) { return "checkout-host", nil },
+				namespaceFile: namespaceFile,
+			}
+			res, err := d.Detect(context.Background())
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			got := map[string]string{}
+			for _, kv := range res.Attributes() {
+				got[string(kv.Key)] = kv.Value.AsString()
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect() = %v, want %v", got, tt.want)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("Detect()[%s] = %q, want %q", key, got[key], want)
+				}
+			}
+		})
+	}
+}
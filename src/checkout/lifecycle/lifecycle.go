@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Manager shuts the application down in stages when it is asked to stop.
+// Stages run in the reverse order they were added, like deferred calls, so
+// that components stop accepting work before the ones they depend on are
+// closed. Every stage shares one shutdown deadline.
+type Manager struct {
+	logger  *slog.Logger
+	timeout time.Duration
+
+	mu     sync.Mutex
+	stages []stage
+}
+
+type stage struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// New creates a Manager that gives the whole shutdown timeout to finish.
+func New(logger *slog.Logger, timeout time.Duration) *Manager {
+	return &Manager{logger: logger, timeout: timeout}
+}
+
+// OnShutdown adds a stage that runs stop on shutdown.
+func (m *Manager) OnShutdown(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stages = append(m.stages, stage{name: name, stop: stop})
+}
+
+// Shutdown runs every stage within the shutdown timeout, even after one
+// fails, and returns their errors joined.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	stages := m.stages
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(stages) - 1; i >= 0; i-- {
+		s := stages[i]
+		start := time.Now()
+		if err := s.stop(ctx); err != nil {
+			m.logger.Error(fmt.Sprintf("shutdown of %s failed: %v", s.name, err))
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		m.logger.Info(fmt.Sprintf("shut down %s", s.name), slog.Duration("duration", time.Since(start)))
+	}
+	return errors.Join(errs...)
+}
+
+// WaitForSignal blocks until the process receives one of signals or ctx is
+// done, then shuts the application down.
+func (m *Manager) WaitForSignal(ctx context.Context, signals ...os.Signal) error {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+	return m.wait(ctx, ch)
+}
+
+func (m *Manager) wait(ctx context.Context, ch <-chan os.Signal) error {
+	select {
+	case sig := <-ch:
+		m.logger.Info(fmt.Sprintf("shutting down on %s", sig), slog.Duration("timeout", m.timeout))
+	case <-ctx.Done():
+		m.logger.Info("shutting down", slog.Duration("timeout", m.timeout))
+	}
+	return m.Shutdown(context.WithoutCancel(ctx))
+}
+
+// GracefulStop returns a stage that stops srv from accepting new RPCs and
+// waits for the pending ones to finish. When the shutdown deadline passes
+// first, the remaining RPCs are cancelled.
+func GracefulStop(srv *grpc.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			srv.Stop()
+			return fmt.Errorf("RPCs still running at the shutdown deadline were cancelled: %w", ctx.Err())
+		}
+	}
+}
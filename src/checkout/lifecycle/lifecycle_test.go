@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestManagerShutdown(t *testing.T) {
+	m := New(discardLogger(), time.Second)
+	var order []string
+	stage := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("stage %s has no deadline", name)
+			}
+			order = append(order, name)
+			return err
+		}
+	}
+	m.OnShutdown("telemetry", stage("telemetry", nil))
+	m.OnShutdown("publisher", stage("publisher", errors.New("flush failed")))
+	m.OnShutdown("server", stage("server", nil))
+
+	err := m.Shutdown(context.Background())
+	if want := []string{"server", "publisher", "telemetry"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("stages ran in order %v, want %v", order, want)
+	}
+	if err == nil || err.Error() != "publisher: flush failed" {
+		t.Errorf("Shutdown() = %v, want the publisher error", err)
+	}
+}
+
+func TestManagerWaitsForSignal(t *testing.T) {
+	m := New(discardLogger(), time.Second)
+	stopped := make(chan struct{})
+	m.OnShutdown("server", func(context.Context) error {
+		close(stopped)
+		return nil
+	})
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan error)
+	go func() { done <- m.wait(context.Background(), ch) }()
+	select {
+	case <-stopped:
+		t.Fatal("shut down before a signal")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	ch <- syscall.SIGTERM
+	if err := <-done; err != nil {
+		t.Errorf("wait() = %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Error("stage did not run on SIGTERM")
+	}
+}
+
+func TestGracefulStop(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	served := make(chan error)
+	go func() { served <- srv.Serve(lis) }()
+
+	if err := GracefulStop(srv)(context.Background()); err != nil {
+		t.Errorf("GracefulStop() = %v", err)
+	}
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Error("Serve() still running after a graceful stop")
+	}
+}
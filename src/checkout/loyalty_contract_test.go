@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/message"
+	messagev3 "github.com/pact-foundation/pact-go/v2/message/v3"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Pact message contract for the LoyaltyPointsEarned event on the order-events
+// topic. The consumer test records what the loyalty team relies on into
+// pacts/loyalty-consumer-checkout-provider.json, and the provider test
+// verifies the event PlaceOrder emits once an order completed.
+const (
+	loyaltyConsumer      = "loyalty-consumer"
+	loyaltyPactFile      = "pacts/loyalty-consumer-checkout-provider.json"
+	loyaltyPointsMessage = "a loyalty-points-earned event"
+)
+
+// loyaltyPointsEvent is the part of a LoyaltyPointsEarned event body the
+// loyalty consumer reads. The customer and the points are in the metadata.
+type loyaltyPointsEvent struct {
+	OrderID string `json:"orderId"`
+}
+
+// TestLoyaltyConsumerContract records the LoyaltyPointsEarned message.
+func TestLoyaltyConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+		Consumer: loyaltyConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(loyaltyPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.LoyaltyOrder.Given()).
+		ExpectsToReceive(loyaltyPointsMessage).
+		WithMetadata(map[string]string{
+			"contentType":            "application/json",
+			adapters.EventTypeHeader: string(ports.LoyaltyPointsEarnedEvent),
+			"customer_id":            "user-1",
+			"points":                 "39",
+		}).
+		WithJSONContent(matchers.StructMatcher{
+			"orderId": matchers.Like("order-12345-contract-test"),
+		}).
+		AsType(&loyaltyPointsEvent{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			event := m.Content.(*loyaltyPointsEvent)
+			if event.OrderID == "" {
+				return fmt.Errorf("LoyaltyPointsEarned event %+v names no order", event)
+			}
+			if m.Metadata["customer_id"] == "" || m.Metadata["points"] == "" {
+				return fmt.Errorf("LoyaltyPointsEarned metadata %v names no customer or points", m.Metadata)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLoyaltyProviderContract places an order and verifies the
+// LoyaltyPointsEarned event it emits against the recorded loyalty contract.
+func TestLoyaltyProviderContract(t *testing.T) {
+	messageHandlers := message.Handlers{
+		loyaltyPointsMessage: func(states []models.ProviderState) (message.Body, message.Metadata, error) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			if _, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest()); err != nil {
+				return nil, nil, err
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				return nil, nil, err
+			}
+			if len(batches.batches) != 1 {
+				return nil, nil, fmt.Errorf("published %v, want one batch", batches.batches)
+			}
+			for _, event := range batches.batches[0] {
+				if event.Type != ports.LoyaltyPointsEarnedEvent {
+					continue
+				}
+				body, err := serialization.ToConsumerJSON(event.Order)
+				if err != nil {
+					return nil, nil, err
+				}
+				metadata := message.Metadata{
+					"contentType":            "application/json",
+					adapters.EventTypeHeader: string(event.Type),
+				}
+				for key, value := range event.Attributes {
+					metadata[key] = value
+				}
+				return body, metadata, nil
+			}
+			return nil, nil, fmt.Errorf("published %v, want a LoyaltyPointsEarned event", batches.batches)
+		},
+	}
+	stateHandlers := providerstate.Handlers(providerstate.LoyaltyOrder.Bind(nil))
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		StateHandlers:   stateHandlers,
+		MessageHandlers: messageHandlers,
+	}
+	pactSource(t, &verifyRequest, loyaltyPactFile, "TestLoyaltyConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
@@ -3,21 +3,27 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
-	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"runtime/debug"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
 
@@ -25,19 +31,24 @@ import (
 	otelhooks "github.com/open-feature/go-sdk-contrib/hooks/open-telemetry/pkg"
 	flagd "github.com/open-feature/go-sdk-contrib/providers/flagd/pkg"
 	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/contrib/propagators/autoprop"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/propagation"
 
+	"go.opentelemetry.io/otel/sdk/instrumentation"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
 	sdkresource "go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
@@ -46,20 +57,38 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
-	"github.com/open-telemetry/opentelemetry-demo/src/checkout/adapters"
-	pb "github.com/open-telemetry/opentelemetry-demo/src/checkout/genproto/oteldemo"
-	"github.com/open-telemetry/opentelemetry-demo/src/checkout/kafka"
-	"github.com/open-telemetry/opentelemetry-demo/src/checkout/money"
-	"github.com/open-telemetry/opentelemetry-demo/src/checkout/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/admin"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/admin/adminpb"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/backfill"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/debugserver"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/k8sdetector"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/lifecycle"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/readiness"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/registry"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/reload"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/saga"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/sampling"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/schema"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/slo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/loglevel"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
 )
 
-//go:generate go install google.golang.org/protobuf/cmd/protoc-gen-go
-//go:generate go install google.golang.org/grpc/cmd/protoc-gen-go-grpc
-//go:generate protoc --go_out=./ --go-grpc_out=./ --proto_path=../../pb ../../pb/demo.proto
+//go:generate go run ./cmd/schemagen -out schemas
 
 var logger *slog.Logger
+var logLevels *loglevel.Controller
 var tracer trace.Tracer
 var resource *sdkresource.Resource
 var initResourcesOnce sync.Once
@@ -72,6 +101,10 @@ func initResource() *sdkresource.Resource {
 			sdkresource.WithProcess(),
 			sdkresource.WithContainer(),
 			sdkresource.WithHost(),
+			// Attribute order event telemetry to the pod that produced it
+			sdkresource.WithDetectors(k8sdetector.New()),
+			// Let OTEL_RESOURCE_ATTRIBUTES override detected attributes
+			sdkresource.WithFromEnv(),
 		)
 		resource, _ = sdkresource.Merge(
 			sdkresource.Default(),
@@ -81,38 +114,151 @@ func initResource() *sdkresource.Resource {
 	return resource
 }
 
-func initTracerProvider() *sdktrace.TracerProvider {
+func initTracerProvider(cfg config.Telemetry) *sdktrace.TracerProvider {
 	ctx := context.Background()
 
 	exporter, err := otlptracegrpc.New(ctx)
 	if err != nil {
 		logger.Error(fmt.Sprintf("new otlp trace grpc exporter failed: %v", err))
 	}
-	tp := sdktrace.NewTracerProvider(
+	opts := []sdktrace.TracerProviderOption{
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(initResource()),
-	)
+	}
+	// Publisher spans may be sampled separately from the rest of the service
+	if cfg.PublisherTracesSampler != "" {
+		// The logger is not initialized yet, so invalid configuration is fatal
+		service, err := sampling.ParseSampler(cfg.TracesSampler, cfg.TracesSamplerArg)
+		if err != nil {
+			panic(fmt.Sprintf("invalid OTEL_TRACES_SAMPLER: %v", err))
+		}
+		publisher, err := sampling.ParseSampler(cfg.PublisherTracesSampler, cfg.PublisherTracesSamplerArg)
+		if err != nil {
+			panic(fmt.Sprintf("invalid PUBLISHER_TRACES_SAMPLER: %v", err))
+		}
+		opts = append(opts, sdktrace.WithSampler(sampling.NewPublisherSampler(publisher, service)))
+	}
+	tp := sdktrace.NewTracerProvider(opts...)
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetTextMapPropagator(initPropagator(cfg.Propagators))
 	return tp
 }
 
-func initMeterProvider() *sdkmetric.MeterProvider {
-	ctx := context.Background()
-
-	exporter, err := otlpmetricgrpc.New(ctx)
+// initPropagator builds the propagator from the OTEL_PROPAGATORS names,
+// defaulting to W3C trace context and baggage. Adding b3, b3multi or jaeger
+// lets legacy consumers that cannot read W3C headers continue the trace of an
+// order event.
+func initPropagator(names []string) propagation.TextMapPropagator {
+	if len(names) == 0 {
+		return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	propagator, err := autoprop.TextMapPropagator(names...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("new otlp metric grpc exporter failed: %v", err))
+		panic(fmt.Sprintf("invalid OTEL_PROPAGATORS %q: %v", strings.Join(names, ","), err))
 	}
+	return propagator
+}
 
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+func initMeterProvider(cfg config.Telemetry) *sdkmetric.MeterProvider {
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(initMetricReader(cfg)),
 		sdkmetric.WithResource(initResource()),
-	)
+	}
+	// Drop any publisher metric attribute outside the bounded set, so that a
+	// high-cardinality attribute cannot reach the backend
+	for _, scope := range []string{"checkout-kafka-adapter", "checkout-pubsub-adapter"} {
+		opts = append(opts, sdkmetric.WithView(sdkmetric.NewView(
+			sdkmetric.Instrument{Scope: instrumentation.Scope{Name: scope}},
+			sdkmetric.Stream{AttributeFilter: attribute.NewAllowKeysFilter(adapters.MetricAttributeKeys()...)},
+		)))
+	}
+	// Attach exemplars from sampled traces, so that slow publishes on the
+	// messaging.publish.duration histogram link to the offending trace.
+	// OTEL_METRICS_EXEMPLAR_FILTER still takes precedence when set.
+	if cfg.MetricsExemplarFilter == "" {
+		opts = append(opts, sdkmetric.WithExemplarFilter(exemplar.TraceBasedFilter))
+	}
+	mp := sdkmetric.NewMeterProvider(opts...)
 	otel.SetMeterProvider(mp)
 	return mp
 }
 
+// initRuntimeMetrics records Go runtime metrics (goroutines, heap, GC) with mp,
+// so that load tests can correlate drops in publish throughput with GC pauses.
+func initRuntimeMetrics(mp metric.MeterProvider) error {
+	err := runtime.Start(runtime.WithMeterProvider(mp), runtime.WithMinimumReadMemStatsInterval(time.Second))
+	if err != nil {
+		return err
+	}
+
+	// The runtime instrumentation reports the heap and the GC goal, but not
+	// how long collections stopped the world
+	meter := mp.Meter("checkout-runtime")
+	gcCount, err := meter.Int64ObservableCounter(
+		"go.gc.count",
+		metric.WithUnit("{gc_cycle}"),
+		metric.WithDescription("Completed garbage collection cycles."),
+	)
+	if err != nil {
+		return err
+	}
+	gcPause, err := meter.Float64ObservableCounter(
+		"go.gc.pause.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Cumulative time the world was stopped for garbage collection."),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		var stats debug.GCStats
+		debug.ReadGCStats(&stats)
+		o.ObserveInt64(gcCount, stats.NumGC)
+		o.ObserveFloat64(gcPause, stats.PauseTotal.Seconds())
+		return nil
+	}, gcCount, gcPause)
+	return err
+}
+
+// initMetricReader selects how metrics leave the process from
+// OTEL_METRICS_EXPORTER: "otlp" (the default) pushes them to the collector and
+// "prometheus" serves them on a scrape endpoint, for clusters without a
+// collector.
+func initMetricReader(cfg config.Telemetry) sdkmetric.Reader {
+	switch cfg.MetricsExporter {
+	case "", "otlp":
+		exporter, err := otlpmetricgrpc.New(context.Background())
+		if err != nil {
+			logger.Error(fmt.Sprintf("new otlp metric grpc exporter failed: %v", err))
+		}
+		return sdkmetric.NewPeriodicReader(exporter)
+
+	case "prometheus":
+		exporter, err := otelprom.New()
+		if err != nil {
+			panic(fmt.Sprintf("new prometheus exporter failed: %v", err))
+		}
+		// Listen on all interfaces by default so that the scraper can reach the pod
+		addr := net.JoinHostPort(cfg.PrometheusHost, cfg.PrometheusPort)
+		// Listen before the logger exists, so that a taken port fails loudly
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			panic(fmt.Sprintf("prometheus listener failed: %v", err))
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		go func() {
+			if err := http.Serve(lis, mux); err != nil {
+				logger.Error(fmt.Sprintf("prometheus listener failed: %v", err))
+			}
+		}()
+		return exporter
+
+	default:
+		panic(fmt.Sprintf("unsupported OTEL_METRICS_EXPORTER %q, expected \"otlp\" or \"prometheus\"", cfg.MetricsExporter))
+	}
+}
+
 func initLoggerProvider() *sdklog.LoggerProvider {
 	ctx := context.Background()
 
@@ -141,6 +287,23 @@ type checkout struct {
 
 	// Hexagonal Architecture: Core depends on ports, not implementations
 	orderEventPublisher ports.OrderEventPublisher
+	orderEventOutbox    ports.OrderEventOutbox
+	idempotencyStore    ports.IdempotencyStore
+	orderCompensator    ports.OrderCompensator
+	pendingOrders       ports.PendingOrderStore
+	orderRepository     ports.OrderRepository
+	shippingProviders   ports.ShippingProviderRegistry
+	inventory           ports.InventoryReserver
+	promotions          ports.PromotionEngine
+	payments            ports.PaymentService
+	currency            ports.CurrencyConverter
+	orderSchemaVersion  atomic.Int64
+	insurancePercent    float64
+	emailService        ports.EmailService
+	confirmations       ports.OrderConfirmationRenderer
+	asyncOrders         chan asyncOrder
+	stopWorkers         context.CancelFunc
+	workers             sync.WaitGroup
 
 	// External service clients (adapters for outbound calls)
 	shippingSvcClient       pb.ShippingServiceClient
@@ -151,18 +314,35 @@ type checkout struct {
 	paymentSvcClient        pb.PaymentServiceClient
 }
 
+// Compile-time check that checkout implements the primary port
+var _ ports.CheckoutUseCase = (*checkout)(nil)
+
+// SchemaVersion returns the schema version of the order events placed now.
+func (cs *checkout) SchemaVersion() int {
+	return int(cs.orderSchemaVersion.Load())
+}
+
+// SetSchemaVersion changes the schema version of the order events placed
+// from now on, and returns the previous one.
+func (cs *checkout) SetSchemaVersion(version int) int {
+	return int(cs.orderSchemaVersion.Swap(int64(version)))
+}
+
 func main() {
-	var port string
-	mustMapEnv(&port, "CHECKOUT_PORT")
+	// Report every invalid variable at once, before anything starts
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
 
-	tp := initTracerProvider()
+	tp := initTracerProvider(cfg.Telemetry)
 	defer func() {
 		if err := tp.Shutdown(context.Background()); err != nil {
 			logger.Error(fmt.Sprintf("Error shutting down tracer provider: %v", err))
 		}
 	}()
 
-	mp := initMeterProvider()
+	mp := initMeterProvider(cfg.Telemetry)
 	defer func() {
 		if err := mp.Shutdown(context.Background()); err != nil {
 			logger.Error(fmt.Sprintf("Error shutting down meter provider: %v", err))
@@ -178,13 +358,44 @@ func main() {
 
 	// this *must* be called after the logger provider is initialized
 	// otherwise the Sarama producer in kafka/producer.go will not be
-	// able to log properly
-	logger = otelslog.NewLogger("checkout")
+	// able to log properly. The level can be changed at runtime with SIGHUP,
+	// which toggles debug logging, or on the debug listener's /debug/loglevel
+	// endpoint
+	logLevel, err := loglevel.Parse(cfg.LogLevel)
+	if err != nil {
+		panic(err)
+	}
+	logLevels = loglevel.NewController(logLevel)
+	logger = slog.New(logLevels.Handler(otelslog.NewHandler("checkout")))
 	slog.SetDefault(logger)
+	go logLevels.WatchSignals(context.Background(), logger, syscall.SIGHUP)
+
+	// On SIGTERM, the components added to app are stopped in reverse order
+	// before the OTel providers are shut down
+	app := lifecycle.New(logger, cfg.ShutdownTimeout)
+
+	// Optionally take the order event publisher settings from a file or an
+	// endpoint, and apply their changes while running, see the reload package
+	var driven *wiring.Ports
+	var publisherSettings *reload.Watcher
+	if source := reload.NewSource(cfg.PublisherReload); source != nil {
+		publisherSettings = reload.NewWatcher(source, os.LookupEnv, func(ctx context.Context, c *config.Config) error {
+			ctx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+			defer cancel()
+			return driven.ReloadPublisher(ctx, c)
+		}, logger)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		loaded, err := publisherSettings.Load(ctx)
+		cancel()
+		if err != nil {
+			logger.Warn(fmt.Sprintf("order event publisher settings not loaded from %s, starting with the environment: %v", source, err))
+		} else {
+			cfg = loaded
+		}
+	}
 
-	err := runtime.Start(runtime.WithMinimumReadMemStatsInterval(time.Second))
-	if err != nil {
-		logger.Error((err.Error()))
+	if err := initRuntimeMetrics(mp); err != nil {
+		logger.Error(fmt.Sprintf("Error starting runtime metrics: %v", err))
 	}
 
 	provider, err := flagd.NewProvider()
@@ -199,57 +410,155 @@ func main() {
 
 	svc := new(checkout)
 
-	mustMapEnv(&svc.shippingSvcAddr, "SHIPPING_ADDR")
+	// Readiness aggregates the health of every port the service depends on
+	checker := readiness.NewChecker(2 * time.Second)
+
+	svc.shippingSvcAddr = cfg.Services.Shipping
 	c := mustCreateClient(svc.shippingSvcAddr)
+	checker.Add("shipping", readiness.GRPCConnCheck(c))
 	svc.shippingSvcClient = pb.NewShippingServiceClient(c)
 	defer c.Close()
 
-	mustMapEnv(&svc.productCatalogSvcAddr, "PRODUCT_CATALOG_ADDR")
+	svc.productCatalogSvcAddr = cfg.Services.ProductCatalog
 	c = mustCreateClient(svc.productCatalogSvcAddr)
+	checker.Add("product_catalog", readiness.GRPCConnCheck(c))
 	svc.productCatalogSvcClient = pb.NewProductCatalogServiceClient(c)
 	defer c.Close()
 
-	mustMapEnv(&svc.cartSvcAddr, "CART_ADDR")
+	svc.cartSvcAddr = cfg.Services.Cart
 	c = mustCreateClient(svc.cartSvcAddr)
+	checker.Add("cart", readiness.GRPCConnCheck(c))
 	svc.cartSvcClient = pb.NewCartServiceClient(c)
 	defer c.Close()
 
-	mustMapEnv(&svc.currencySvcAddr, "CURRENCY_ADDR")
+	svc.currencySvcAddr = cfg.Services.Currency
 	c = mustCreateClient(svc.currencySvcAddr)
+	checker.Add("currency", readiness.GRPCConnCheck(c))
 	svc.currencySvcClient = pb.NewCurrencyServiceClient(c)
 	defer c.Close()
 
-	mustMapEnv(&svc.emailSvcAddr, "EMAIL_ADDR")
+	svc.emailSvcAddr = cfg.Services.Email
 	c = mustCreateClient(svc.emailSvcAddr)
+	checker.Add("email", readiness.GRPCConnCheck(c))
 	svc.emailSvcClient = pb.NewEmailServiceClient(c)
 	defer c.Close()
 
-	mustMapEnv(&svc.paymentSvcAddr, "PAYMENT_ADDR")
+	svc.paymentSvcAddr = cfg.Services.Payment
 	c = mustCreateClient(svc.paymentSvcAddr)
+	checker.Add("payment", readiness.GRPCConnCheck(c))
 	svc.paymentSvcClient = pb.NewPaymentServiceClient(c)
 	defer c.Close()
 
-	svc.kafkaBrokerSvcAddr = os.Getenv("KAFKA_ADDR")
+	// Unlike readiness, the startup probe fails until the downstream ports
+	// accept connections
+	startup := readiness.NewChecker(2 * time.Second)
+	for name, addr := range map[string]string{
+		"shipping":        svc.shippingSvcAddr,
+		"product_catalog": svc.productCatalogSvcAddr,
+		"cart":            svc.cartSvcAddr,
+		"currency":        svc.currencySvcAddr,
+		"email":           svc.emailSvcAddr,
+		"payment":         svc.paymentSvcAddr,
+	} {
+		startup.Add(name, readiness.TCPCheck(addr))
+	}
 
-	// Initialize order event publisher (hexagonal architecture port)
+	svc.kafkaBrokerSvcAddr = cfg.Kafka.Addr
 	if svc.kafkaBrokerSvcAddr != "" {
-		kafkaProducer, err := kafka.CreateKafkaProducer([]string{svc.kafkaBrokerSvcAddr}, logger)
-		if err != nil {
-			logger.Error(err.Error())
-			// Use a no-op implementation if Kafka is unavailable
-			svc.orderEventPublisher = &adapters.NoOpOrderEventPublisher{}
-		} else {
-			// Use Kafka adapter implementation
-			svc.orderEventPublisher = adapters.NewKafkaOrderEventPublisher(kafkaProducer, logger)
+		pingKafka := func(ctx context.Context) error {
+			return kafka.Ping(ctx, []string{svc.kafkaBrokerSvcAddr})
 		}
-	} else {
-		// Use no-op implementation when Kafka is not configured
-		svc.orderEventPublisher = &adapters.NoOpOrderEventPublisher{}
+		checker.Add("kafka", pingKafka)
+		startup.Add("kafka", pingKafka)
+	}
+	if cfg.SchemaRegistry.URL != "" {
+		if client, err := registry.NewClient(registry.Kind(cfg.SchemaRegistry.Kind), cfg.SchemaRegistry.URL, nil); err == nil {
+			checker.Add("schema_registry", client.Ping)
+			startup.Add("schema_registry", client.Ping)
+		}
+	}
+
+	// Wait for dependencies that are still starting instead of crash-looping
+	degraded := waitForDependencies(startup, cfg.Startup)
+
+	// Track the publish SLO, which fails readiness while breached
+	publishSLO := initPublishSLO(cfg.PublishSLO)
+	if publishSLO != nil {
+		checker.Add("publish_slo", publishSLO.Check)
+	}
+
+	// Refuse to publish with a schema that would break consumers
+	var portOpts wiring.Options
+	if err := initOrderEventSchema(context.Background(), cfg.SchemaRegistry, cfg.Kafka.Topic); err != nil {
+		unavailable := errcode.Of(err) == errcode.SchemaRegistryUnavailable
+		if cfg.SchemaRegistry.OnIncompatible != "spool" && !(degraded && unavailable) {
+			panic(fmt.Sprintf("order event schema check failed: %v", err))
+		}
+		logger.Error(fmt.Sprintf("order event schema check failed, spooling order events to %s: %v", cfg.OrderEvents.SpoolPath, err), errcode.Attr(err))
+		portOpts.SpoolOnly = true
+	}
+	if publishSLO != nil {
+		portOpts.KafkaOptions = append(portOpts.KafkaOptions, adapters.WithPublishObserver(publishSLO))
+	}
+
+	portOpts.PaymentClient = svc.paymentSvcClient
+	portOpts.CurrencyClient = svc.currencySvcClient
+
+	// Build the adapters behind the driven ports, see the wiring package
+	driven, err = wiring.NewPorts(cfg, logger, portOpts)
+	if err != nil {
+		panic(fmt.Sprintf("invalid order event publisher config: %v", err))
+	}
+	svc.orderEventPublisher = driven.OrderEventPublisher
+	svc.idempotencyStore = driven.IdempotencyStore
+	svc.orderCompensator = driven.OrderCompensator
+	svc.orderRepository = driven.OrderRepository
+	svc.shippingProviders = driven.ShippingProviders
+	svc.inventory = driven.Inventory
+	svc.promotions = driven.Promotions
+	svc.payments = driven.Payments
+	svc.currency = driven.Currency
+	svc.orderSchemaVersion.Store(int64(cfg.OrderEvents.SchemaVersion))
+	svc.insurancePercent = cfg.PlaceOrder.ShippingInsurancePercent
+	svc.emailService = driven.EmailService
+	svc.confirmations = driven.ConfirmationRenderer
+	if driven.Outbox != nil {
+		svc.orderEventOutbox = driven.Outbox
+	}
+
+	// Drain in-flight publishes and flush the spool once nothing publishes
+	app.OnShutdown("order event publisher", driven.Close)
+
+	// Swap the publisher chain when its settings change, unless order events
+	// are only spooled
+	if publisherSettings != nil && !portOpts.SpoolOnly {
+		ctx, stop := context.WithCancel(context.Background())
+		go publisherSettings.Run(ctx, cfg.PublisherReload.Interval)
+		app.OnShutdown("publisher settings reload", func(context.Context) error {
+			stop()
+			return nil
+		})
+	}
+
+	// Optionally accept orders asynchronously and complete them in the background
+	if driven.PendingOrders != nil {
+		svc.startOrderWorkers(context.Background(), driven.PendingOrders, cfg.PlaceOrder.AsyncWorkers)
+		app.OnShutdown("async order workers", svc.stopOrderWorkers)
+	}
+
+	// Optional debug listener for troubleshooting publish latency in load tests
+	if cfg.DebugAddr != "" {
+		app.OnShutdown("debug listener", startDebugServer(cfg.DebugAddr, cfg.SchemaRegistry.URL, svc, driven.Transport, publishSLO).Shutdown)
+	}
+
+	// Optional admin listener to operate the order event publisher while it runs
+	if cfg.AdminAddr != "" {
+		app.OnShutdown("admin listener", startAdminServer(cfg.AdminAddr, cfg.Kafka.Addr, svc, driven))
 	}
 
 	logger.Info(fmt.Sprintf("service config: %+v", svc))
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Port))
 	if err != nil {
 		logger.Error(err.Error())
 	}
@@ -261,17 +570,199 @@ func main() {
 
 	healthcheck := health.NewServer()
 	healthpb.RegisterHealthServer(srv, healthcheck)
-	logger.Info(fmt.Sprintf("starting to listen on tcp: %q", lis.Addr().String()))
-	err = srv.Serve(lis)
-	logger.Error(err.Error())
+	go checker.Watch(context.Background(), healthcheck, 10*time.Second, "", "oteldemo.CheckoutService")
+
+	// Optional HTTP readiness probe for orchestrators without gRPC probes
+	if cfg.ReadinessAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/readyz", checker)
+		app.OnShutdown("readiness listener", serveHTTP("readiness", cfg.ReadinessAddr, mux).Shutdown)
+	}
+
+	// Optional REST and GraphQL facades for web clients that cannot speak gRPC
+	if cfg.HTTPAddr != "" {
+		rest := adapters.NewHTTPCheckoutHandler(svc, logger)
+		mux := http.NewServeMux()
+		mux.Handle("/orders", rest)
+		mux.Handle("/orders/", rest)
+		mux.Handle("/graphql", adapters.NewGraphQLCheckoutHandler(svc, logger))
+		handler := otelhttp.NewHandler(mux, "checkout-http")
+		app.OnShutdown("HTTP listener", serveHTTP("HTTP", cfg.HTTPAddr, handler).Shutdown)
+	}
+
+	// Stop accepting RPCs first, reporting NOT_SERVING so that load balancers
+	// route new calls elsewhere, and let the calls in progress finish
+	app.OnShutdown("gRPC server", func(ctx context.Context) error {
+		healthcheck.Shutdown()
+		return lifecycle.GracefulStop(srv)(ctx)
+	})
+	go func() {
+		logger.Info(fmt.Sprintf("starting to listen on tcp: %q", lis.Addr().String()))
+		if err := srv.Serve(lis); err != nil {
+			logger.Error(err.Error())
+		}
+	}()
+
+	if err := app.WaitForSignal(context.Background(), syscall.SIGTERM, os.Interrupt); err != nil {
+		logger.Error(fmt.Sprintf("shutdown incomplete: %v", err))
+	}
 }
 
-func mustMapEnv(target *string, envKey string) {
-	v := os.Getenv(envKey)
-	if v == "" {
-		panic(fmt.Sprintf("environment variable %q not set", envKey))
+// serveHTTP serves handler on addr in the background until the returned
+// server is shut down.
+func serveHTTP(name, addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		logger.Info(fmt.Sprintf("starting %s listener on tcp: %q", name, addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(fmt.Sprintf("%s listener failed: %v", name, err))
+		}
+	}()
+	return srv
+}
+
+// waitForDependencies probes the dependencies of startup with exponential
+// backoff for up to cfg.ProbeTimeout, 0 disabling probing. If some are still
+// down, it panics unless cfg.Degraded is set, and otherwise reports that the
+// service starts degraded: order events are spooled until Kafka is reachable,
+// or for good if the schema registry is not.
+func waitForDependencies(startup *readiness.Checker, cfg config.Startup) bool {
+	timeout := cfg.ProbeTimeout
+	if timeout <= 0 {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	report := startup.WaitReady(ctx, readiness.DefaultBackoff, logger)
+	if report.Ready {
+		return false
+	}
+	if !cfg.Degraded {
+		panic(fmt.Sprintf("dependencies not ready after %s: %v", timeout, report.Down()))
+	}
+	logger.Warn("starting in degraded mode", slog.Any("down", report.Down()))
+	return true
+}
+
+// initOrderEventSchema registers the order event schema with the schema
+// registry, if cfg has one, after verifying that it is compatible with the
+// versions consumers already rely on.
+func initOrderEventSchema(ctx context.Context, cfg config.SchemaRegistry, topic string) error {
+	if cfg.URL == "" {
+		return nil
+	}
+
+	level, err := registry.ParseCompatibility(cfg.Compatibility)
+	if err != nil {
+		return err
+	}
+	client, err := registry.NewClient(registry.Kind(cfg.Kind), cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	definition, err := schema.OrderResultProto()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	subject := topic + "-value"
+	id, err := registry.EnsureSchema(ctx, client, subject, registry.Schema{
+		Type:       registry.SchemaTypeProtobuf,
+		Definition: definition,
+	}, level)
+	if errors.Is(err, registry.ErrIncompatibleSchema) {
+		return errcode.Wrap(errcode.SchemaIncompatible, err)
+	}
+	if err != nil {
+		return errcode.Wrap(errcode.SchemaRegistryUnavailable, err)
+	}
+	logger.Info(fmt.Sprintf("order event schema registered: subject=%q id=%d compatibility=%s", subject, id, level))
+	return nil
+}
+
+// initPublishSLO creates a publish SLO tracker from cfg. It returns nil when
+// neither objective is set.
+func initPublishSLO(cfg config.PublishSLO) *slo.Tracker {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return slo.NewTracker(slo.Objective{
+		SuccessRate: cfg.SuccessRate,
+		Latency:     cfg.Latency,
+		Percentile:  cfg.Percentile,
+		Window:      cfg.Window,
+	})
+}
+
+// publisherDebugState is the publisher configuration and queue state served on
+// the debug listener.
+type publisherDebugState struct {
+	KafkaAddr         string                   `json:"kafka_addr"`
+	Publisher         string                   `json:"publisher"`
+	SchemaRegistryURL string                   `json:"schema_registry_url,omitempty"`
+	Kafka             *adapters.PublisherStats `json:"kafka,omitempty"`
+	Region            string                   `json:"region,omitempty"`
+	SLO               *slo.Status              `json:"slo,omitempty"`
+}
+
+// startAdminServer serves the PublisherAdmin service on addr in the
+// background, and returns the function stopping it. The size of the
+// dead-letter topic is read from the brokers at kafkaAddr, if set.
+func startAdminServer(addr, kafkaAddr string, svc *checkout, driven *wiring.Ports) func(context.Context) error {
+	var opts []admin.Option
+	if kafkaAddr != "" {
+		opts = append(opts, admin.WithDeadLetters(func() (int64, error) {
+			return kafka.TopicSize([]string{kafkaAddr}, kafka.DeadLetterTopic)
+		}))
+	}
+	srv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	adminpb.RegisterPublisherAdminServer(srv, admin.NewServer(driven, svc, logger, opts...))
+	go func() {
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			logger.Error(fmt.Sprintf("admin listener failed: %v", err))
+			return
+		}
+		logger.Info(fmt.Sprintf("starting admin listener on tcp: %q", addr))
+		if err := srv.Serve(lis); err != nil {
+			logger.Error(fmt.Sprintf("admin listener failed: %v", err))
+		}
+	}()
+	return lifecycle.GracefulStop(srv)
+}
+
+// startDebugServer serves pprof, expvar and the publisher state on addr. The
+// publisher state is also published as the order_event_publisher expvar. The
+// Kafka stats are those of the current publisher chain, from transport.
+func startDebugServer(addr, registryURL string, svc *checkout, transport func() *adapters.PublisherChain, publishSLO *slo.Tracker) *http.Server {
+	state := func() any {
+		s := publisherDebugState{
+			KafkaAddr:         svc.kafkaBrokerSvcAddr,
+			Publisher:         fmt.Sprintf("%T", svc.orderEventPublisher),
+			SchemaRegistryURL: registryURL,
+		}
+		if chain := transport(); chain != nil && chain.Kafka != nil {
+			stats := chain.Kafka.Stats()
+			s.Kafka = &stats
+		}
+		if chain := transport(); chain != nil && chain.Regions != nil {
+			s.Region = chain.Regions.ActiveRegion()
+		}
+		if publishSLO != nil {
+			status := publishSLO.Status()
+			s.SLO = &status
+		}
+		return s
 	}
-	*target = v
+	expvar.Publish("order_event_publisher", expvar.Func(state))
+
+	// The order repository lives in this process, so history is backfilled here
+	job := &backfill.Job{Repository: svc.orderRepository, Publisher: svc.orderEventPublisher, Logger: logger}
+	return serveHTTP("debug", addr, debugserver.NewHandler(state, logLevels, job))
 }
 
 func (cs *checkout) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
@@ -282,7 +773,302 @@ func (cs *checkout) Watch(req *healthpb.HealthCheckRequest, ws healthpb.Health_W
 	return status.Errorf(codes.Unimplemented, "health check via Watch not implemented")
 }
 
+// idempotencyKeyHeader is the gRPC metadata key carrying the client-supplied
+// request ID of a PlaceOrder call.
+const idempotencyKeyHeader = "idempotency-key"
+
+// idempotencyKey scopes requestID to userID so that one user cannot replay
+// another's order. The user ID is length-prefixed, as either ID may contain
+// any separator.
+func idempotencyKey(userID, requestID string) string {
+	return strconv.Itoa(len(userID)) + ":" + userID + "/" + requestID
+}
+
+// PlaceOrder places the order once per client-supplied request ID. A retried
+// call carrying the same idempotency-key returns the original order instead of
+// charging the card and publishing the order event again.
 func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	requestIDs := metadata.ValueFromIncomingContext(ctx, idempotencyKeyHeader)
+	if cs.idempotencyStore == nil || len(requestIDs) == 0 || requestIDs[0] == "" {
+		return cs.placeOrder(ctx, req)
+	}
+	key := idempotencyKey(req.UserId, requestIDs[0])
+
+	span := trace.SpanFromContext(ctx)
+	order, err := cs.idempotencyStore.Reserve(ctx, key)
+	switch {
+	case errors.Is(err, ports.ErrRequestInProgress):
+		return nil, status.Errorf(codes.Aborted, "%s", err.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Unavailable, "idempotency store failure: %+v", err)
+	case order != nil:
+		span.SetAttributes(
+			attribute.Bool("app.order.idempotent_replay", true),
+			attribute.String("app.order.id", order.OrderId),
+		)
+		logger.InfoContext(ctx, "returning the original order for a retried request", slog.String("app.order.id", order.OrderId))
+		return &pb.PlaceOrderResponse{Order: order}, nil
+	}
+
+	resp, err := cs.placeOrder(ctx, req)
+	if err != nil {
+		if releaseErr := cs.idempotencyStore.Release(ctx, key); releaseErr != nil {
+			logger.WarnContext(ctx, fmt.Sprintf("failed to release idempotency key: %+v", releaseErr))
+		}
+		return nil, err
+	}
+	if err := cs.idempotencyStore.Complete(ctx, key, resp.Order); err != nil {
+		logger.WarnContext(ctx, fmt.Sprintf("failed to record order for idempotency key: %+v", err))
+	}
+	return resp, nil
+}
+
+// Page sizes of ListOrders.
+const (
+	defaultOrdersPageSize = 10
+	maxOrdersPageSize     = 100
+)
+
+// GetOrder returns an order the user placed.
+func (cs *checkout) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("app.user.id", req.UserId),
+		attribute.String("app.order.id", req.OrderId),
+	)
+	if req.UserId == "" || req.OrderId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id and order_id are required")
+	}
+	order, err := cs.orderRepository.Get(ctx, req.UserId, req.OrderId)
+	switch {
+	case errors.Is(err, ports.ErrOrderNotFound):
+		return nil, status.Errorf(codes.NotFound, "order %s not found", req.OrderId)
+	case err != nil:
+		return nil, status.Errorf(codes.Unavailable, "order repository failure: %+v", err)
+	}
+	return &pb.GetOrderResponse{Order: order}, nil
+}
+
+// ListOrders returns a page of the orders the user placed, newest first.
+func (cs *checkout) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("app.user.id", req.UserId))
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	pageSize := int(req.PageSize)
+	switch {
+	case pageSize < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultOrdersPageSize
+	case pageSize > maxOrdersPageSize:
+		pageSize = maxOrdersPageSize
+	}
+
+	orders, next, err := cs.orderRepository.List(ctx, req.UserId, pageSize, req.PageToken)
+	switch {
+	case errors.Is(err, ports.ErrInvalidPageToken):
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case err != nil:
+		return nil, status.Errorf(codes.Unavailable, "order repository failure: %+v", err)
+	}
+	span.SetAttributes(attribute.Int("app.orders.count", len(orders)))
+	return &pb.ListOrdersResponse{Orders: orders, NextPageToken: next}, nil
+}
+
+// placeOrderModeHeader is the gRPC metadata key selecting how PlaceOrder
+// runs. With the value "async" it returns as soon as the order is accepted.
+const placeOrderModeHeader = "place-order-mode"
+
+// asyncOrderQueueSize bounds the orders waiting for a background worker.
+const asyncOrderQueueSize = 100
+
+// shippingMethodHeader is the gRPC metadata key selecting the shipping method
+// of an order, for example "express". Without it the order ships with the
+// default method.
+const shippingMethodHeader = "shipping-method"
+
+// giftCardHeader is the gRPC metadata key with the code of a gift card paying
+// for part of an order, before the card pays for the rest.
+const giftCardHeader = "gift-card"
+
+// shippingInsuranceHeader is the gRPC metadata key asking for shipping
+// insurance when "true".
+const shippingInsuranceHeader = "shipping-insurance"
+
+// orderOptions are the choices of the client for an order that are not part
+// of PlaceOrderRequest, read from the gRPC metadata.
+type orderOptions struct {
+	shippingMethod    string
+	giftCard          string
+	shippingInsurance bool
+}
+
+// orderOptionsFrom reads the order options from the gRPC metadata of ctx.
+func orderOptionsFrom(ctx context.Context) orderOptions {
+	var opts orderOptions
+	if methods := metadata.ValueFromIncomingContext(ctx, shippingMethodHeader); len(methods) > 0 {
+		opts.shippingMethod = methods[0]
+	}
+	if codes := metadata.ValueFromIncomingContext(ctx, giftCardHeader); len(codes) > 0 {
+		opts.giftCard = codes[0]
+	}
+	if insured := metadata.ValueFromIncomingContext(ctx, shippingInsuranceHeader); len(insured) > 0 {
+		opts.shippingInsurance = insured[0] == "true"
+	}
+	return opts
+}
+
+// placeOrder assigns the order its ID and completes it, or with async mode
+// requested and enabled, hands it to the background workers.
+func (cs *checkout) placeOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	orderID, err := uuid.NewUUID()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate order uuid")
+	}
+	opts := orderOptionsFrom(ctx)
+	if modes := metadata.ValueFromIncomingContext(ctx, placeOrderModeHeader); cs.pendingOrders != nil && len(modes) > 0 && modes[0] == "async" {
+		return cs.placeOrderAsync(ctx, orderID.String(), opts, req)
+	}
+	return cs.processOrder(ctx, orderID.String(), opts, req)
+}
+
+// placeOrderAsync validates the request, saves it as a pending order and
+// queues it for the background workers. The response carries only the order ID;
+// the completed OrderResult is published as the order event.
+func (cs *checkout) placeOrderAsync(ctx context.Context, orderID string, opts orderOptions, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("app.order.id", orderID),
+		attribute.Bool("app.order.async", true),
+	)
+	if err := validation.ValidatePlaceOrderRequest(req); err != nil {
+		cs.orderFailed(ctx, ports.FailedOrder{OrderID: orderID, UserID: req.UserId, Step: validateStep}, err)
+		return nil, err
+	}
+	if _, err := cs.shippingProviders.Provider(opts.shippingMethod); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	order := ports.PendingOrder{
+		OrderID:           orderID,
+		Request:           req,
+		ShippingMethod:    opts.shippingMethod,
+		GiftCard:          opts.giftCard,
+		ShippingInsurance: opts.shippingInsurance,
+	}
+	if err := cs.pendingOrders.Save(ctx, order); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to save pending order: %+v", err)
+	}
+
+	select {
+	case cs.asyncOrders <- asyncOrder{PendingOrder: order, link: trace.LinkFromContext(ctx)}:
+	default:
+		if err := cs.pendingOrders.Fail(ctx, orderID, "order queue full"); err != nil {
+			logger.WarnContext(ctx, fmt.Sprintf("failed to record rejected order %s: %+v", orderID, err))
+		}
+		return nil, status.Errorf(codes.ResourceExhausted, "too many pending orders")
+	}
+	logger.InfoContext(ctx, "order accepted", slog.String("app.order.id", orderID))
+	return &pb.PlaceOrderResponse{Order: &pb.OrderResult{OrderId: orderID}}, nil
+}
+
+// asyncOrder is a pending order queued for the background workers. link
+// points at the PlaceOrder call that accepted it.
+type asyncOrder struct {
+	ports.PendingOrder
+	link trace.Link
+}
+
+// startOrderWorkers enables asynchronous PlaceOrder. It queues the orders
+// store still holds as pending, then starts workers that complete queued
+// orders until ctx is done or stopOrderWorkers is called.
+func (cs *checkout) startOrderWorkers(ctx context.Context, store ports.PendingOrderStore, workers int) {
+	cs.pendingOrders = store
+	cs.asyncOrders = make(chan asyncOrder, asyncOrderQueueSize)
+	ctx, cs.stopWorkers = context.WithCancel(ctx)
+
+	pending, err := store.ListPending(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("failed to list pending orders: %+v", err))
+	}
+	go func() {
+		for _, order := range pending {
+			select {
+			case cs.asyncOrders <- asyncOrder{PendingOrder: order}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for range workers {
+		cs.workers.Add(1)
+		go func() {
+			defer cs.workers.Done()
+			for ctx.Err() == nil {
+				select {
+				case order := <-cs.asyncOrders:
+					// An order in progress completes even if the workers are stopped
+					cs.completeAsyncOrder(context.WithoutCancel(ctx), order)
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+}
+
+// stopOrderWorkers stops the workers once they have completed the orders in
+// progress, or ctx is done. Queued orders stay pending in the store, so that a
+// durable store resumes them on the next start.
+func (cs *checkout) stopOrderWorkers(ctx context.Context) error {
+	if cs.stopWorkers == nil {
+		return nil
+	}
+	cs.stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		cs.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("orders still in progress: %w", ctx.Err())
+	}
+}
+
+// completeAsyncOrder runs the order workflow for a pending order and records
+// its outcome.
+func (cs *checkout) completeAsyncOrder(ctx context.Context, order asyncOrder) {
+	ctx, span := tracer.Start(ctx, "PlaceOrder async",
+		trace.WithLinks(order.link),
+		trace.WithAttributes(attribute.String("app.order.id", order.OrderID)),
+	)
+	defer span.End()
+
+	opts := orderOptions{
+		shippingMethod:    order.ShippingMethod,
+		giftCard:          order.GiftCard,
+		shippingInsurance: order.ShippingInsurance,
+	}
+	resp, err := cs.processOrder(ctx, order.OrderID, opts, order.Request)
+	if err != nil {
+		span.SetStatus(otelcodes.Error, err.Error())
+		logger.ErrorContext(ctx, fmt.Sprintf("asynchronous order %s failed: %+v", order.OrderID, err))
+		if err := cs.pendingOrders.Fail(ctx, order.OrderID, err.Error()); err != nil {
+			logger.WarnContext(ctx, fmt.Sprintf("failed to record failed order %s: %+v", order.OrderID, err))
+		}
+		return
+	}
+	if err := cs.pendingOrders.Complete(ctx, order.OrderID, resp.Order); err != nil {
+		logger.WarnContext(ctx, fmt.Sprintf("failed to record completed order %s: %+v", order.OrderID, err))
+	}
+}
+
+// processOrder runs the order workflow: it charges the gift card and the card,
+// ships the order and publishes the completed order.
+func (cs *checkout) processOrder(ctx context.Context, orderID string, opts orderOptions, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
 		attribute.String("app.user.id", req.UserId),
@@ -302,17 +1088,44 @@ func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (
 		}
 	}()
 
-	orderID, err := uuid.NewUUID()
+	shipping, err := cs.shippingProviders.Provider(opts.shippingMethod)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to generate order uuid")
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	if opts.shippingMethod != "" {
+		span.SetAttributes(attribute.String("app.shipping.method", opts.shippingMethod))
 	}
 
-	prep, err := cs.prepareOrderItemsAndShippingQuoteFromCart(ctx, req.UserId, req.UserCurrency, req.Address)
+	// Orders that could never be fulfilled or published are rejected with
+	// the violations before the card is charged
+	if err = validation.ValidatePlaceOrderRequest(req); err != nil {
+		cs.orderFailed(ctx, ports.FailedOrder{OrderID: orderID, UserID: req.UserId, Step: validateStep}, err)
+		return nil, err
+	}
+
+	prep, err := cs.prepareOrderItemsAndShippingQuoteFromCart(ctx, req.UserId, req.UserCurrency, req.Address, shipping)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "%s", err.Error())
 	}
 	span.AddEvent("prepared")
 
+	// Promotions adjust the item costs before the order is charged, and its
+	// events carry the discount lines as headers
+	if ctx, err = cs.applyPromotions(ctx, req.UserId, &prep); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to apply promotions: %+v", err)
+	}
+
+	if err = validation.ValidatePreparedOrder(req.UserCurrency, prep.orderItems, prep.shippingCostLocalized); err != nil {
+		cs.orderFailed(ctx, ports.FailedOrder{OrderID: orderID, UserID: req.UserId, Step: validateStep}, err)
+		return nil, err
+	}
+
+	// The shipping cost includes the insurance the client asked for
+	fees, err := cs.shippingFees(ctx, shipping, &prep, opts.shippingInsurance)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to break down shipping fees: %+v", err)
+	}
+
 	total := &pb.Money{CurrencyCode: req.UserCurrency,
 		Units: 0,
 		Nanos: 0}
@@ -322,30 +1135,150 @@ func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (
 		total = money.Must(money.Sum(total, multPrice))
 	}
 
-	txID, err := cs.chargeCard(ctx, total, req.CreditCard)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to charge card: %+v", err)
+	// With an outbox, the events of the order are recorded as they happen and
+	// published together once it completed, so that a failed order emits none
+	var events ports.UnitOfWork
+	placed := &pb.OrderResult{
+		OrderId:         orderID,
+		ShippingCost:    prep.shippingCostLocalized,
+		ShippingAddress: req.Address,
+		Items:           prep.orderItems,
+	}
+	if cs.orderEventOutbox != nil {
+		events = cs.orderEventOutbox.Begin(orderID)
+		events.Record(ports.OrderEvent{Type: ports.OrderPlacedEvent, Order: placed})
 	}
 
-	span.AddEvent("charged",
-		trace.WithAttributes(attribute.String("app.payment.transaction.id", txID)))
-	logger.LogAttrs(
-		ctx,
-		slog.LevelInfo, "payment went through",
-		slog.String("transaction_id", txID),
+	// Reserving stock, charging and shipping run as a saga: if shipping fails
+	// after the card was charged, the charge is refunded, the stock released
+	// and the order reported as failed. A gift card pays first, and the card
+	// is charged what is left due.
+	var txID, shippingTrackingID string
+	var payments []ports.Payment
+	due := total
+	var steps []saga.Step
+	if cs.inventory != nil {
+		steps = append(steps, saga.Step{
+			Name: reserveStep,
+			Action: func(ctx context.Context) error {
+				return cs.inventory.Reserve(ctx, orderID, prep.cartItems)
+			},
+			Compensate: func(ctx context.Context) error {
+				return cs.inventory.Release(ctx, orderID)
+			},
+		})
+	}
+	if opts.giftCard != "" {
+		var gift ports.Payment
+		steps = append(steps, saga.Step{
+			Name: giftCardStep,
+			Action: func(ctx context.Context) error {
+				var err error
+				gift, err = cs.charge(ctx, total, ports.Tender{Type: ports.GiftCardTender, GiftCardCode: opts.giftCard})
+				if err != nil || money.IsZero(gift.Amount) {
+					return err
+				}
+				payments = append(payments, gift)
+				due = money.Must(money.Sum(total, money.Negate(gift.Amount)))
+				recordPayment(ctx, events, placed, gift)
+				return nil
+			},
+			Compensate: func(ctx context.Context) error {
+				if money.IsZero(gift.Amount) {
+					return nil
+				}
+				failedStep := "charge"
+				if txID != "" || !money.IsPositive(due) {
+					failedStep = "ship"
+				}
+				return cs.orderCompensator.RefundPayment(ctx, ports.FailedOrder{
+					OrderID:       orderID,
+					UserID:        req.UserId,
+					TransactionID: gift.TransactionID,
+					Amount:        gift.Amount,
+					Tender:        ports.GiftCardTender,
+					Step:          failedStep,
+				})
+			},
+		})
+	}
+	steps = append(steps,
+		saga.Step{
+			Name: "charge",
+			Action: func(ctx context.Context) error {
+				if !money.IsPositive(due) {
+					return nil
+				}
+				card, err := cs.charge(ctx, due, ports.Tender{Type: ports.CardTender, Card: req.CreditCard})
+				if err != nil {
+					return err
+				}
+				txID = card.TransactionID
+				payments = append(payments, card)
+				recordPayment(ctx, events, placed, card)
+				return nil
+			},
+			Compensate: func(ctx context.Context) error {
+				if txID == "" {
+					return nil
+				}
+				return cs.orderCompensator.RefundPayment(ctx, ports.FailedOrder{
+					OrderID:       orderID,
+					UserID:        req.UserId,
+					TransactionID: txID,
+					Amount:        due,
+					Step:          "ship",
+				})
+			},
+		},
+		saga.Step{
+			Name: "ship",
+			Action: func(ctx context.Context) error {
+				var err error
+				shippingTrackingID, err = shipping.Ship(ctx, req.Address, prep.cartItems)
+				return err
+			},
+		},
 	)
-
-	shippingTrackingID, err := cs.shipOrder(ctx, req.Address, prep.cartItems)
+	err = saga.New(steps...).Run(ctx)
 	if err != nil {
+		failed := ports.FailedOrder{
+			OrderID:       orderID,
+			UserID:        req.UserId,
+			TransactionID: txID,
+			Amount:        total,
+		}
+		var outOfStock *ports.OutOfStockError
+		if errors.As(err, &outOfStock) {
+			failed.OutOfStock = outOfStock.ProductIDs
+			cs.publishOutOfStock(ctx, placed, outOfStock.ProductIDs)
+		}
+		cs.orderFailed(ctx, failed, err)
+		switch saga.FailedStep(err) {
+		case reserveStep:
+			if outOfStock != nil {
+				return nil, status.Errorf(codes.FailedPrecondition, "%s", outOfStock.Error())
+			}
+			return nil, status.Errorf(codes.Unavailable, "failed to reserve stock: %+v", err)
+		case giftCardStep:
+			return nil, status.Errorf(codes.InvalidArgument, "failed to charge gift card: %+v", err)
+		case "charge":
+			return nil, status.Errorf(codes.Internal, "failed to charge card: %+v", err)
+		}
 		return nil, status.Errorf(codes.Unavailable, "shipping error: %+v", err)
 	}
+	if cs.inventory != nil {
+		if err := cs.inventory.Confirm(ctx, orderID); err != nil {
+			logger.WarnContext(ctx, fmt.Sprintf("failed to confirm the stock reservation of order %s: %+v", orderID, err))
+		}
+	}
 	shippingTrackingAttribute := attribute.String("app.shipping.tracking.id", shippingTrackingID)
 	span.AddEvent("shipped", trace.WithAttributes(shippingTrackingAttribute))
 
 	_ = cs.emptyUserCart(ctx, req.UserId)
 
 	orderResult := &pb.OrderResult{
-		OrderId:            orderID.String(),
+		OrderId:            orderID,
 		ShippingTrackingId: shippingTrackingID,
 		ShippingCost:       prep.shippingCostLocalized,
 		ShippingAddress:    req.Address,
@@ -356,7 +1289,7 @@ func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (
 	totalPriceFloat, _ := strconv.ParseFloat(fmt.Sprintf("%d.%02d", total.GetUnits(), total.GetNanos()/1000000000), 64)
 
 	span.SetAttributes(
-		attribute.String("app.order.id", orderID.String()),
+		attribute.String("app.order.id", orderID),
 		attribute.Float64("app.shipping.amount", shippingCostFloat),
 		attribute.Float64("app.order.amount", totalPriceFloat),
 		attribute.Int("app.order.items.count", len(prep.orderItems)),
@@ -365,7 +1298,7 @@ func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (
 	logger.LogAttrs(
 		ctx,
 		slog.LevelInfo, "order placed",
-		slog.String("app.order.id", orderID.String()),
+		slog.String("app.order.id", orderID),
 		slog.Float64("app.shipping.amount", shippingCostFloat),
 		slog.Float64("app.order.amount", totalPriceFloat),
 		slog.Int("app.order.items.count", len(prep.orderItems)),
@@ -373,18 +1306,39 @@ func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (
 	)
 
 	if err := cs.sendOrderConfirmation(ctx, req.Email, orderResult); err != nil {
-		logger.Warn(fmt.Sprintf("failed to send order confirmation to %q: %+v", req.Email, err))
+		logger.WarnContext(ctx, fmt.Sprintf("failed to send order confirmation to %q: %+v", req.Email, err))
 	} else {
-		logger.Info(fmt.Sprintf("order confirmation email sent to %q", req.Email))
+		logger.InfoContext(ctx, fmt.Sprintf("order confirmation email sent to %q", req.Email))
+	}
+
+	if cs.orderRepository != nil {
+		if err := cs.orderRepository.Save(ctx, req.UserId, orderResult); err != nil {
+			logger.WarnContext(ctx, fmt.Sprintf("failed to save order %s: %+v", orderID, err))
+		}
+	}
+
+	// From schema version 2 on, the order events list the payments of the
+	// order, and from version 3 on its fees
+	extensions := adapters.OrderExtensions{Payments: payments, Fees: fees}
+	if headers, err := extensions.Headers(cs.SchemaVersion()); err != nil {
+		logger.WarnContext(ctx, fmt.Sprintf("failed to add the payments and fees of order %s to its events: %+v", orderID, err))
+	} else if headers != nil {
+		ctx = adapters.WithMessageHeaders(ctx, headers)
 	}
 
 	// Publish order completion event using the port (hexagonal architecture)
 	// The core business logic doesn't know HOW the event is published (Kafka, etc.)
 	// It only knows WHAT it needs to do (publish the order completion)
-	logger.Info("publishing order completion event")
-	if err := cs.orderEventPublisher.PublishOrderCompleted(ctx, orderResult); err != nil {
+	logger.InfoContext(ctx, "publishing order completion event", slog.String("order_id", orderResult.OrderId))
+	var followUps []ports.OrderEvent
+	if points := loyaltyPoints(orderResult.GetItems()); points > 0 {
+		span.SetAttributes(attribute.Int64("app.loyalty.points", points))
+		followUps = append(followUps, loyaltyPointsEarned(req.UserId, orderID, points))
+	}
+	if err := cs.publishOrderEvents(ctx, events, orderResult, followUps...); err != nil {
 		// In a production system, you might want to implement retry logic or dead letter queues
-		logger.Error(fmt.Sprintf("failed to publish order completion event: %+v", err))
+		logger.ErrorContext(ctx, fmt.Sprintf("failed to publish order completion event: %+v", err), errcode.Attr(err))
+		span.AddEvent("order event publish failed", trace.WithAttributes(errcode.Key.String(string(errcode.Of(err)))))
 		// Don't fail the entire order for a publishing error
 	}
 
@@ -392,13 +1346,206 @@ func (cs *checkout) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (
 	return resp, nil
 }
 
+// publishOrderEvents commits the events of the order with its OrderResult
+// and the followUps to it, or publishes the OrderResult alone without an
+// outbox.
+func (cs *checkout) publishOrderEvents(ctx context.Context, events ports.UnitOfWork, order *pb.OrderResult, followUps ...ports.OrderEvent) error {
+	if events == nil {
+		return cs.orderEventPublisher.PublishOrderCompleted(ctx, order)
+	}
+	events.Record(ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: order})
+	for _, event := range followUps {
+		events.Record(event)
+	}
+	return events.Commit(ctx)
+}
+
+// loyaltyPoints returns the points earned by an order: one per whole unit of
+// currency spent on its items, shipping excluded.
+func loyaltyPoints(items []*pb.OrderItem) int64 {
+	return max(itemsNanos(items)/1_000_000_000, 0)
+}
+
+// itemsNanos returns the cost of items in nanos of their currency.
+func itemsNanos(items []*pb.OrderItem) int64 {
+	var nanos int64
+	for _, item := range items {
+		cost := item.GetCost()
+		nanos += (cost.GetUnits()*1_000_000_000 + int64(cost.GetNanos())) * int64(item.GetItem().GetQuantity())
+	}
+	return nanos
+}
+
+// loyaltyPointsEarned returns the LoyaltyPointsEarned event of the order with
+// orderID.
+func loyaltyPointsEarned(customerID, orderID string, points int64) ports.OrderEvent {
+	return ports.OrderEvent{
+		Type:  ports.LoyaltyPointsEarnedEvent,
+		Order: &pb.OrderResult{OrderId: orderID},
+		Attributes: map[string]string{
+			"customer_id": customerID,
+			"points":      strconv.FormatInt(points, 10),
+		},
+	}
+}
+
+// shippingFees breaks the shipping cost of prep down into fees and, for an
+// insured order, adds the insurance of its items to the shipping cost.
+func (cs *checkout) shippingFees(ctx context.Context, shipping ports.ShippingProvider, prep *orderPrep, insured bool) (ports.ShippingFees, error) {
+	currency := prep.shippingCostLocalized.GetCurrencyCode()
+	fees := ports.ShippingFees{
+		Base:       prep.shippingCostLocalized,
+		Insurance:  &pb.Money{CurrencyCode: currency},
+		Surcharges: &pb.Money{CurrencyCode: currency},
+	}
+	if surcharging, ok := shipping.(ports.SurchargingShippingProvider); ok {
+		surcharge, err := cs.convertCurrency(ctx, surcharging.Surcharge(), currency)
+		if err != nil {
+			return fees, err
+		}
+		if fees.Base, err = money.Sum(fees.Base, money.Negate(surcharge)); err != nil {
+			return fees, err
+		}
+		fees.Surcharges = surcharge
+	}
+	if insured {
+		fees.Insurance = shippingInsurance(prep.orderItems, cs.insurancePercent, currency)
+		cost, err := money.Sum(prep.shippingCostLocalized, fees.Insurance)
+		if err != nil {
+			return fees, err
+		}
+		prep.shippingCostLocalized = cost
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("app.shipping.insured", true))
+	}
+	return fees, nil
+}
+
+// shippingInsurance returns percent of the cost of items, rounded down to the
+// cent.
+func shippingInsurance(items []*pb.OrderItem, percent float64, currency string) *pb.Money {
+	insurance := int64(float64(itemsNanos(items)) * percent / 100)
+	insurance -= insurance % 10_000_000
+	return &pb.Money{CurrencyCode: currency, Units: insurance / 1_000_000_000, Nanos: int32(insurance % 1_000_000_000)}
+}
+
+// applyPromotions replaces the items of prep with the ones adjusted by the
+// promotion engine, and returns a context whose order events carry the
+// discount lines.
+func (cs *checkout) applyPromotions(ctx context.Context, userID string, prep *orderPrep) (context.Context, error) {
+	if cs.promotions == nil {
+		return ctx, nil
+	}
+	promotion, err := cs.promotions.Apply(ctx, userID, prep.orderItems)
+	if err != nil {
+		return ctx, err
+	}
+	prep.orderItems = promotion.Items
+	if len(promotion.Discounts) == 0 {
+		return ctx, nil
+	}
+	headers, err := adapters.DiscountHeaders(promotion.Discounts)
+	if err != nil {
+		return ctx, err
+	}
+	discountCodes := make([]string, 0, len(promotion.Discounts))
+	for _, d := range promotion.Discounts {
+		discountCodes = append(discountCodes, d.Code)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.StringSlice("app.order.discounts", discountCodes))
+	return adapters.WithMessageHeaders(ctx, headers), nil
+}
+
+// FailedOrder steps of orders rejected by validation, of orders whose stock
+// could not be reserved, and of orders whose gift card could not be charged.
+const (
+	validateStep = "validate"
+	reserveStep  = "reserve"
+	giftCardStep = "gift-card"
+)
+
+// publishOutOfStock emits OutOfStock for the items of order short of stock.
+// Like the other events of an order besides its OrderResult, it is only
+// published through an outbox.
+func (cs *checkout) publishOutOfStock(ctx context.Context, order *pb.OrderResult, productIDs []string) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.StringSlice("app.order.out_of_stock", productIDs))
+	if cs.orderEventOutbox == nil {
+		return
+	}
+	affected := &pb.OrderResult{OrderId: order.GetOrderId()}
+	for _, item := range order.GetItems() {
+		if slices.Contains(productIDs, item.GetItem().GetProductId()) {
+			affected.Items = append(affected.Items, item)
+		}
+	}
+	events := cs.orderEventOutbox.Begin(order.GetOrderId())
+	events.Record(ports.OrderEvent{
+		Type:       ports.OutOfStockEvent,
+		Order:      affected,
+		Attributes: map[string]string{"product_ids": strings.Join(productIDs, ",")},
+	})
+	if err := events.Commit(ctx); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("failed to publish out of stock event: %+v", err), errcode.Attr(err))
+	}
+}
+
+// orderFailedEvent returns the OrderFailed event of order.
+func orderFailedEvent(order ports.FailedOrder) ports.OrderEvent {
+	return ports.OrderEvent{
+		Type:  ports.OrderFailedEvent,
+		Order: &pb.OrderResult{OrderId: order.OrderID},
+		Attributes: map[string]string{
+			"user_id":     order.UserID,
+			"failed_step": order.Step,
+			"reason":      order.Reason,
+			"compensated": strconv.FormatBool(order.Compensated),
+		},
+	}
+}
+
+// orderFailed records the outcome of a failed order saga on the span, hands
+// it to the compensator and, like OutOfStock, emits OrderFailed through the
+// outbox.
+func (cs *checkout) orderFailed(ctx context.Context, order ports.FailedOrder, err error) {
+	span := trace.SpanFromContext(ctx)
+	var sagaErr *saga.Error
+	if errors.As(err, &sagaErr) {
+		order.Step = sagaErr.Step
+		order.Compensated = sagaErr.Compensated()
+		for _, c := range sagaErr.Compensations {
+			attrs := []attribute.KeyValue{attribute.String("app.order.compensated_step", c.Step)}
+			if c.Err != nil {
+				logger.ErrorContext(ctx, fmt.Sprintf("failed to compensate %s: %+v", c.Step, c.Err))
+				attrs = append(attrs, semconv.ExceptionMessageKey.String(c.Err.Error()))
+			}
+			span.AddEvent("compensated", trace.WithAttributes(attrs...))
+		}
+	}
+	order.Reason = err.Error()
+	span.AddEvent("order failed", trace.WithAttributes(
+		attribute.String("app.order.failed_step", order.Step),
+		attribute.Bool("app.order.compensated", order.Compensated),
+	))
+	if err := cs.orderCompensator.PublishOrderFailed(ctx, order); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("failed to publish order failed event: %+v", err))
+	}
+	if cs.orderEventOutbox == nil {
+		return
+	}
+	events := cs.orderEventOutbox.Begin(order.OrderID)
+	events.Record(orderFailedEvent(order))
+	if err := events.Commit(ctx); err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("failed to publish order failed event: %+v", err), errcode.Attr(err))
+	}
+}
+
 type orderPrep struct {
 	orderItems            []*pb.OrderItem
 	cartItems             []*pb.CartItem
 	shippingCostLocalized *pb.Money
 }
 
-func (cs *checkout) prepareOrderItemsAndShippingQuoteFromCart(ctx context.Context, userID, userCurrency string, address *pb.Address) (orderPrep, error) {
+func (cs *checkout) prepareOrderItemsAndShippingQuoteFromCart(ctx context.Context, userID, userCurrency string, address *pb.Address, shipping ports.ShippingProvider) (orderPrep, error) {
 
 	ctx, span := tracer.Start(ctx, "prepareOrderItemsAndShippingQuoteFromCart")
 	defer span.End()
@@ -412,11 +1559,11 @@ func (cs *checkout) prepareOrderItemsAndShippingQuoteFromCart(ctx context.Contex
 	if err != nil {
 		return out, fmt.Errorf("failed to prepare order: %+v", err)
 	}
-	shippingUSD, err := cs.quoteShipping(ctx, address, cartItems)
+	shippingQuote, err := shipping.Quote(ctx, address, cartItems)
 	if err != nil {
 		return out, fmt.Errorf("shipping quote failure: %+v", err)
 	}
-	shippingPrice, err := cs.convertCurrency(ctx, shippingUSD, userCurrency)
+	shippingPrice, err := cs.convertCurrency(ctx, shippingQuote, userCurrency)
 	if err != nil {
 		return out, fmt.Errorf("failed to convert shipping cost to currency: %+v", err)
 	}
@@ -451,43 +1598,6 @@ func mustCreateClient(svcAddr string) *grpc.ClientConn {
 	return c
 }
 
-func (cs *checkout) quoteShipping(ctx context.Context, address *pb.Address, items []*pb.CartItem) (*pb.Money, error) {
-	quotePayload, err := json.Marshal(map[string]interface{}{
-		"address": address,
-		"items":   items,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal ship order request: %+v", err)
-	}
-
-	resp, err := otelhttp.Post(ctx, cs.shippingSvcAddr+"/get-quote", "application/json", bytes.NewBuffer(quotePayload))
-	if err != nil {
-		return nil, fmt.Errorf("failed POST to shipping service: %+v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed POST to email service: expected 200, got %d", resp.StatusCode)
-	}
-
-	shippingQuoteBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read shipping quote response: %+v", err)
-	}
-
-	var quoteResp struct {
-		CostUsd *pb.Money `json:"cost_usd"`
-	}
-	if err := json.Unmarshal(shippingQuoteBytes, &quoteResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal shipping quote: %+v", err)
-	}
-	if quoteResp.CostUsd == nil {
-		return nil, fmt.Errorf("shipping quote missing cost_usd field")
-	}
-
-	return quoteResp.CostUsd, nil
-}
-
 func (cs *checkout) getUserCart(ctx context.Context, userID string) ([]*pb.CartItem, error) {
 	cart, err := cs.cartSvcClient.GetCart(ctx, &pb.GetCartRequest{UserId: userID})
 	if err != nil {
@@ -522,90 +1632,57 @@ func (cs *checkout) prepOrderItems(ctx context.Context, items []*pb.CartItem, us
 	return out, nil
 }
 
+// convertCurrency converts from through the currency converter port, at the
+// cached rate of its currency.
 func (cs *checkout) convertCurrency(ctx context.Context, from *pb.Money, toCurrency string) (*pb.Money, error) {
-	result, err := cs.currencySvcClient.Convert(ctx, &pb.CurrencyConversionRequest{
-		From:   from,
-		ToCode: toCurrency})
+	result, err := cs.currency.Convert(ctx, from, toCurrency)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert currency: %+v", err)
 	}
 	return result, err
 }
 
-func (cs *checkout) chargeCard(ctx context.Context, amount *pb.Money, paymentInfo *pb.CreditCardInfo) (string, error) {
-	paymentService := cs.paymentSvcClient
-	if cs.isFeatureFlagEnabled(ctx, "paymentUnreachable") {
+// charge takes at most amount from tender through the payment service port.
+func (cs *checkout) charge(ctx context.Context, amount *pb.Money, tender ports.Tender) (ports.Payment, error) {
+	payments := cs.payments
+	if tender.Type == ports.CardTender && cs.isFeatureFlagEnabled(ctx, "paymentUnreachable") {
 		badAddress := "badAddress:50051"
 		c := mustCreateClient(badAddress)
-		paymentService = pb.NewPaymentServiceClient(c)
-	}
-
-	paymentResp, err := paymentService.Charge(ctx, &pb.ChargeRequest{
-		Amount:     amount,
-		CreditCard: paymentInfo})
-	if err != nil {
-		return "", fmt.Errorf("could not charge the card: %+v", err)
+		payments = adapters.NewGRPCPaymentService(pb.NewPaymentServiceClient(c))
 	}
-	return paymentResp.GetTransactionId(), nil
+	return payments.Charge(ctx, amount, tender)
 }
 
-func (cs *checkout) sendOrderConfirmation(ctx context.Context, email string, order *pb.OrderResult) error {
-	emailPayload, err := json.Marshal(map[string]interface{}{
-		"email": email,
-		"order": order,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to marshal order to JSON: %+v", err)
-	}
-
-	resp, err := otelhttp.Post(ctx, cs.emailSvcAddr+"/send_order_confirmation", "application/json", bytes.NewBuffer(emailPayload))
-	if err != nil {
-		return fmt.Errorf("failed POST to email service: %+v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed POST to email service: expected 200, got %d", resp.StatusCode)
+// recordPayment traces and logs payment, and records it as PaymentCaptured
+// when the events of the order are recorded.
+func recordPayment(ctx context.Context, events ports.UnitOfWork, order *pb.OrderResult, payment ports.Payment) {
+	trace.SpanFromContext(ctx).AddEvent("charged", trace.WithAttributes(
+		attribute.String("app.payment.transaction.id", payment.TransactionID),
+		attribute.String("app.payment.tender", string(payment.Type)),
+	))
+	logger.LogAttrs(
+		ctx,
+		slog.LevelInfo, "payment went through",
+		slog.String("transaction_id", payment.TransactionID),
+		slog.String("tender", string(payment.Type)),
+	)
+	if events != nil {
+		events.Record(ports.OrderEvent{
+			Type:       ports.PaymentCapturedEvent,
+			Order:      order,
+			Attributes: map[string]string{"transaction_id": payment.TransactionID, "tender": string(payment.Type)},
+		})
 	}
-
-	return err
 }
 
-func (cs *checkout) shipOrder(ctx context.Context, address *pb.Address, items []*pb.CartItem) (string, error) {
-	shipPayload, err := json.Marshal(map[string]interface{}{
-		"address": address,
-		"items":   items,
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal ship order request: %+v", err)
-	}
-
-	resp, err := otelhttp.Post(ctx, cs.shippingSvcAddr+"/ship-order", "application/json", bytes.NewBuffer(shipPayload))
-	if err != nil {
-		return "", fmt.Errorf("failed POST to shipping service: %+v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed POST to email service: expected 200, got %d", resp.StatusCode)
-	}
-
-	trackingRespBytes, err := io.ReadAll(resp.Body)
+// sendOrderConfirmation renders the confirmation email of order and sends it
+// through the email service port.
+func (cs *checkout) sendOrderConfirmation(ctx context.Context, email string, order *pb.OrderResult) error {
+	confirmation, err := cs.confirmations.RenderOrderConfirmation(order)
 	if err != nil {
-		return "", fmt.Errorf("failed to read ship order response: %+v", err)
+		return err
 	}
-
-	var shipResp struct {
-		TrackingID string `json:"tracking_id"`
-	}
-	if err := json.Unmarshal(trackingRespBytes, &shipResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal ship order response: %+v", err)
-	}
-	if shipResp.TrackingID == "" {
-		return "", fmt.Errorf("ship order response missing tracking_id field")
-	}
-
-	return shipResp.TrackingID, nil
+	return cs.emailService.SendOrderConfirmation(ctx, email, order, confirmation)
 }
 
 // func (cs *checkout) sendToPostProcessor(ctx context.Context, result *pb.OrderResult) {
@@ -2,8 +2,9 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
@@ -11,12 +12,30 @@ import (
 	"github.com/pact-foundation/pact-go/v2/message"
 	"github.com/pact-foundation/pact-go/v2/models"
 	"github.com/pact-foundation/pact-go/v2/provider"
-	"google.golang.org/protobuf/encoding/protojson"
-
-	pb "github.com/open-telemetry/opentelemetry-demo/src/checkout/genproto/oteldemo"
-	"github.com/open-telemetry/opentelemetry-demo/src/checkout/ports"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/contracttest"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
 )
 
+// accountingServicePactFile is the pact the accounting service records for
+// the order events it consumes.
+const accountingServicePactFile = "../accounting/tests/pacts/accounting-consumer-checkout-provider.json"
+
 // TestOrderEventPublisherContract verifies that our OrderEventPublisher port
 // satisfies the message contracts defined by consumers. This test exercises
 // the hexagonal architecture pattern by testing the port abstraction rather
@@ -29,58 +48,73 @@ import (
 // 4. Easy to mock and test different scenarios
 //
 // Pact Source Configuration:
-// - If PACT_BROKER_URL environment variable is set, fetches contracts from the broker
-// - Otherwise, falls back to local pact files for offline development
+// - CONTRACT_TESTS=broker fetches contracts from PACT_BROKER_URL, the default when it is set
+// - CONTRACT_TESTS=local uses the local pact files for offline development
 // - Supports broker authentication via PACT_BROKER_USERNAME and PACT_BROKER_PASSWORD
 // - Publishes verification results back to broker when using broker mode
 func TestOrderEventPublisherContract(t *testing.T) {
-	// Create a message capture mock that records what gets published through the port
-	var capturedOrder *pb.OrderResult
-	captureMock := &MessageCaptureMock{
-		onPublish: func(order *pb.OrderResult) {
-			capturedOrder = order
-		},
-	}
-
-	// Create a checkout service with the capture mock
+	// Capture the spans the adapters emit, so that telemetry is verified as
+	// part of the contract
+	spanExporter := installInMemoryTracing(t)
+
+	// Hand every captured order to the real Kafka adapter over a mock
+	// producer, so that the message headers and producer spans are the
+	// production ones
+	producer := kafkatest.NewProducer(t)
+	kafkaPublisher := adapters.NewKafkaOrderEventPublisher(producer, slog.New(slog.DiscardHandler))
+
+	// Create a checkout service with an in-memory publisher that captures
+	// what gets published through the port
+	captured := adapters.NewInMemoryOrderEventPublisher()
 	checkoutService := &checkout{
-		orderEventPublisher: captureMock,
+		orderEventPublisher: captured,
 	}
 
 	// Create message handlers that exercise the port interface
 	messageHandlers := message.Handlers{
 		"order-result message": func(states []models.ProviderState) (message.Body, message.Metadata, error) {
 			// Create an OrderResult using business logic patterns
-			orderResult := createOrderResultFromBusinessLogicPatterns()
+			orderResult := createOrderResultFromBusinessLogicPatterns(t)
+			spanExporter.Reset()
+			producer.ExpectSuccess()
 
 			// ✅ THIS IS THE KEY: Exercise the actual port interface!
 			// This calls through the checkout service's orderEventPublisher,
 			// testing the same business logic flow as the real PlaceOrder method
-			err := checkoutService.orderEventPublisher.PublishOrderCompleted(context.Background(), orderResult)
+			// Contract verification is synthetic traffic, flagged through baggage
+			// exactly as the load generator does it
+			ctx := syntheticContext()
+			err := checkoutService.orderEventPublisher.PublishOrderCompleted(ctx, orderResult)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to publish order through port: %w", err)
 			}
 
 			// Verify the order was captured through the port interface
-			if capturedOrder == nil {
-				return nil, nil, fmt.Errorf("order was not captured by mock publisher")
+			event, ok := captured.Last()
+			if !ok {
+				return nil, nil, fmt.Errorf("order was not captured by the in-memory publisher")
+			}
+			if err := kafkaPublisher.PublishOrderCompleted(ctx, event.Order); err != nil {
+				return nil, nil, fmt.Errorf("failed to publish captured order to Kafka: %w", err)
 			}
 
-			// Convert the captured order to the format that consumers expect (JSON)
-			jsonObj, err := convertOrderResultToConsumerFormat(capturedOrder)
-			if err != nil {
-				return nil, nil, fmt.Errorf("failed to convert captured OrderResult to consumer format: %w", err)
+			// The producer spans are part of the contract too
+			if err := assertProducerTelemetry(spanExporter.GetSpans()); err != nil {
+				return nil, nil, fmt.Errorf("publishing produced unexpected telemetry: %w", err)
 			}
 
-			return jsonObj, message.Metadata{
-				"contentType": "application/json",
-			}, nil
+			// Convert the captured order to the format that consumers expect
+			// (JSON), and surface the propagation headers (baggage,
+			// traceparent) the Kafka adapter attached to the message, so
+			// consumers can rely on them
+			messages := producer.Messages()
+			return contracttest.Message(event.Order, messages[len(messages)-1])
 		},
 	}
 
 	// Provider states represent the business conditions when messages are published
-	stateHandlers := models.StateHandlers{
-		"An order has been successfully processed": func(setup bool, s models.ProviderState) (models.ProviderStateResponse, error) {
+	stateHandlers := providerstate.Handlers(
+		providerstate.OrderProcessed.Bind(func(setup bool, _ providerstate.Params) error {
 			if setup {
 				t.Log("Provider State Setup: Order processing completed successfully")
 				// In a real system, this might involve:
@@ -91,11 +125,9 @@ func TestOrderEventPublisherContract(t *testing.T) {
 				t.Log("Provider State Teardown: Cleaning up order processing state")
 				// Cleanup operations
 			}
-			return models.ProviderStateResponse{
-				"orderProcessingComplete": setup,
-			}, nil
-		},
-	}
+			return nil
+		}),
+	)
 
 	// Verify that our port implementation satisfies the consumer contracts
 	verifier := provider.NewVerifier()
@@ -106,43 +138,8 @@ func TestOrderEventPublisherContract(t *testing.T) {
 		MessageHandlers: messageHandlers,
 	}
 
-	// Configure pact source: broker if available, local files as fallback
-	if brokerURL := os.Getenv("PACT_BROKER_URL"); brokerURL != "" {
-		t.Logf("🌐 Using Pact Broker for contract verification: %s", brokerURL)
-		// Configure broker-based verification
-		verifyRequest.BrokerURL = brokerURL
-		verifyRequest.BrokerUsername = os.Getenv("PACT_BROKER_USERNAME")
-		verifyRequest.BrokerPassword = os.Getenv("PACT_BROKER_PASSWORD")
-		verifyRequest.ConsumerVersionSelectors = []provider.Selector{
-			&provider.ConsumerVersionSelector{
-				Tag: "main",
-			},
-			&provider.ConsumerVersionSelector{
-				Latest: true,
-			},
-		}
-		verifyRequest.Provider = "checkout-provider"
-
-		// Use Git commit and branch if available
-		if gitCommit := os.Getenv("GIT_COMMIT"); gitCommit != "" {
-			verifyRequest.ProviderVersion = gitCommit
-			t.Logf("📝 Provider version: %s", gitCommit)
-		}
-		if gitBranch := os.Getenv("GIT_BRANCH"); gitBranch != "" {
-			verifyRequest.ProviderBranch = gitBranch
-			t.Logf("🌿 Provider branch: %s", gitBranch)
-		}
-
-		// Enable publishing verification results back to broker
-		verifyRequest.PublishVerificationResults = true
-		t.Log("📤 Will publish verification results to broker")
-	} else {
-		t.Log("📁 Using local pact files for contract verification")
-		// Fallback to local files
-		verifyRequest.PactFiles = []string{
-			filepath.ToSlash("../accounting/tests/pacts/accounting-consumer-checkout-provider.json"),
-		}
-	}
+	// Configure pact source: the broker or the local files, as CONTRACT_TESTS selects
+	pactSource(t, &verifyRequest, accountingServicePactFile, "the consumer tests of the accounting service")
 
 	err := verifier.VerifyProvider(t, verifyRequest)
 
@@ -156,270 +153,196 @@ func TestOrderEventPublisherContract(t *testing.T) {
 // createOrderResultFromBusinessLogicPatterns creates an OrderResult using the same
 // business logic patterns as the actual PlaceOrder workflow. This ensures our
 // contract tests exercise realistic business scenarios.
-func createOrderResultFromBusinessLogicPatterns() *pb.OrderResult {
-	// Simulate the PlaceOrder business logic flow:
-	// 1. Order ID generation (uuid.NewUUID() pattern)
-	// 2. Cost calculation (prepareOrderItemsAndShippingQuoteFromCart pattern)
-	// 3. Address handling (from PlaceOrderRequest.Address)
-	// 4. Item processing (prepOrderItems pattern)
-	// 5. Shipping tracking (shipOrder pattern)
-
-	// Business logic: Generate unique order identifier
-	orderID := "order-12345-contract-test"
-
-	// Business logic: Calculate shipping costs with realistic values
-	shippingCost := &pb.Money{
-		CurrencyCode: "USD",
-		Units:        8, // $8.00
-		Nanos:        0, // + $0.50 = $8.50 total
-	}
-
-	// Business logic: Process shipping address (from user input)
-	shippingAddress := &pb.Address{
-		StreetAddress: "456 Contract St",
-		City:          "Test City",
-		State:         "CA",
-		Country:       "USA",
-		ZipCode:       "90210",
-	}
-
-	// Business logic: Process cart items and calculate costs
-	orderItems := []*pb.OrderItem{
-		{
-			Item: &pb.CartItem{
-				ProductId: "CONTRACT-PRODUCT-001",
-				Quantity:  2,
-			},
-			Cost: &pb.Money{
-				CurrencyCode: "USD",
-				Units:        15,
-				Nanos:        0, // $15.99 per item
-			},
-		},
-		{
-			Item: &pb.CartItem{
-				ProductId: "CONTRACT-PRODUCT-002",
-				Quantity:  1,
-			},
-			Cost: &pb.Money{
-				CurrencyCode: "USD",
-				Units:        25,
-				Nanos:        0, // $25.00
-			},
-		},
-	}
-
-	// Business logic: Generate shipping tracking ID
-	shippingTrackingID := "TRACK-CONTRACT-789"
-
-	// Create OrderResult following the exact PlaceOrder pattern
-	return &pb.OrderResult{
-		OrderId:            orderID,
-		ShippingTrackingId: shippingTrackingID,
-		ShippingCost:       shippingCost,
-		ShippingAddress:    shippingAddress,
-		Items:              orderItems,
-	}
-}
-
-// convertOrderResultToConsumerFormat converts a protobuf OrderResult to the JSON
-// format that consumers expect. This includes handling protobuf-specific serialization
-// quirks like int64 fields being serialized as strings.
-func convertOrderResultToConsumerFormat(orderResult *pb.OrderResult) (map[string]interface{}, error) {
-	// Use protobuf JSON marshaling with options that match consumer expectations
-	marshaler := protojson.MarshalOptions{
-		EmitUnpopulated: true,  // Include zero values like nanos:0
-		UseProtoNames:   false, // Use JSON names (camelCase)
-	}
-
-	jsonBytes, err := marshaler.Marshal(orderResult)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal OrderResult to JSON: %w", err)
-	}
-
-	// Parse JSON into a map for Pact processing
-	var jsonObj map[string]interface{}
-	if err := json.Unmarshal(jsonBytes, &jsonObj); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON into map: %w", err)
-	}
-
-	// Fix protobuf int64 serialization issue: units fields come as strings but consumers expect integers
-	fixProtobufSerializationIssues(jsonObj)
-
-	return jsonObj, nil
-}
-
-// fixProtobufSerializationIssues converts protobuf int64 "units" fields from strings to integers
-// to match consumer expectations. This is necessary because protobuf serializes int64
-// as strings in JSON to prevent precision loss, but our consumers expect integers.
-func fixProtobufSerializationIssues(jsonObj map[string]interface{}) {
-	// Fix shipping cost units field
-	if shippingCost, ok := jsonObj["shippingCost"].(map[string]interface{}); ok {
-		if unitsStr, ok := shippingCost["units"].(string); ok {
-			if units := parseIntFromString(unitsStr); units != nil {
-				shippingCost["units"] = *units
-			}
-		}
-	}
-
-	// Fix order items cost units fields
-	if items, ok := jsonObj["items"].([]interface{}); ok {
-		for _, item := range items {
-			if itemObj, ok := item.(map[string]interface{}); ok {
-				if cost, ok := itemObj["cost"].(map[string]interface{}); ok {
-					if unitsStr, ok := cost["units"].(string); ok {
-						if units := parseIntFromString(unitsStr); units != nil {
-							cost["units"] = *units
-						}
-					}
-				}
-			}
-		}
-	}
-}
-
-// parseIntFromString safely converts a string to an integer, returning nil if conversion fails
-func parseIntFromString(s string) *int {
-	if val, err := json.Number(s).Int64(); err == nil {
-		intVal := int(val)
-		return &intVal
-	}
-	return nil
+func createOrderResultFromBusinessLogicPatterns(t testing.TB) *pb.OrderResult {
+	// The happy_path scenario is the PlaceOrder business logic flow: an order
+	// ID, the items of the cart with their costs, the shipping quote to the
+	// address of the request, and the tracking ID of the shipment. Consumer
+	// tests take their example values from the same file
+	return testdata.Scenario(t, "happy_path")
 }
 
 // TestPortAbstractionWithMockPublisher demonstrates how the port abstraction
 // enables easy testing with mock implementations. This shows the flexibility
 // of the hexagonal architecture approach.
 func TestPortAbstractionWithMockPublisher(t *testing.T) {
-	// Create a mock implementation of the OrderEventPublisher port
-	mockPublisher := &MockOrderEventPublisher{
-		publishedOrders: make([]*pb.OrderResult, 0),
-	}
+	// Test that the business logic uses the port correctly
+	orderResult := createOrderResultFromBusinessLogicPatterns(t)
+
+	// Create an in-memory implementation of the OrderEventPublisher port,
+	// which captures what it receives
+	publisher := adapters.NewInMemoryOrderEventPublisher()
 
-	// Create a checkout service with the mock publisher
+	// Create a checkout service with the in-memory publisher
 	checkoutService := &checkout{
-		orderEventPublisher: mockPublisher,
+		orderEventPublisher: publisher,
 	}
 
-	// Test that the business logic uses the port correctly
-	orderResult := createOrderResultFromBusinessLogicPatterns()
-
 	// In a real test, you would call checkoutService.PlaceOrder() here
 	// For this demonstration, we'll directly test the publisher
 	err := checkoutService.orderEventPublisher.PublishOrderCompleted(context.Background(), orderResult)
 	if err != nil {
 		t.Fatalf("Failed to publish order: %v", err)
 	}
-
-	// Verify the mock received the order
-	if len(mockPublisher.publishedOrders) != 1 {
-		t.Fatalf("Expected 1 published order, got %d", len(mockPublisher.publishedOrders))
+	if orders := publisher.Orders(); len(orders) != 1 || !proto.Equal(orders[0], orderResult) {
+		t.Fatalf("Publisher received %v, want the order exactly once", orders)
 	}
 
-	published := mockPublisher.publishedOrders[0]
-	if published.OrderId != orderResult.OrderId {
-		t.Errorf("Expected order ID %s, got %s", orderResult.OrderId, published.OrderId)
-	}
-
-	t.Log("✅ Port abstraction test passed! Mock publisher received the order correctly.")
-}
-
-// MockOrderEventPublisher is a test implementation of the OrderEventPublisher port.
-// This demonstrates how the hexagonal architecture enables easy testing.
-type MockOrderEventPublisher struct {
-	publishedOrders []*pb.OrderResult
-	shouldFail      bool
+	t.Log("✅ Port abstraction test passed! In-memory publisher received the order correctly.")
 }
 
-// Compile-time check that MockOrderEventPublisher implements OrderEventPublisher
-var _ ports.OrderEventPublisher = (*MockOrderEventPublisher)(nil)
-
-// PublishOrderCompleted implements the OrderEventPublisher interface for testing
-func (m *MockOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
-	if m.shouldFail {
-		return fmt.Errorf("mock publisher configured to fail")
+// TestOrderEventPublisherRejectsInvalidOrders covers the failure side of the
+// order-result contract: orders that would violate consumer expectations must be
+// rejected with a typed validation error and never reach the transport.
+func TestOrderEventPublisherRejectsInvalidOrders(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(o *pb.OrderResult)
+		wantField string
+	}{
+		{"empty order id", func(o *pb.OrderResult) { o.OrderId = "" }, "order_id"},
+		{"invalid currency code", func(o *pb.OrderResult) { o.ShippingCost.CurrencyCode = "dollars" }, "shipping_cost.currency_code"},
+		{"negative item cost", func(o *pb.OrderResult) { o.Items[1].Cost.Units = -25 }, "items[1].cost"},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := adapters.NewInMemoryOrderEventPublisher()
+			checkoutService := &checkout{
+				orderEventPublisher: adapters.NewValidatingOrderEventPublisher(transport, slog.Default()),
+			}
 
-	m.publishedOrders = append(m.publishedOrders, order)
-	return nil
-}
+			orderResult := createOrderResultFromBusinessLogicPatterns(t)
+			tt.mutate(orderResult)
 
-// GetPublishedOrders returns the orders that were published (for test verification)
-func (m *MockOrderEventPublisher) GetPublishedOrders() []*pb.OrderResult {
-	return m.publishedOrders
+			err := checkoutService.orderEventPublisher.PublishOrderCompleted(context.Background(), orderResult)
+			var verr *validation.Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *validation.Error, got %v", err)
+			}
+			if verr.Violations[0].Field != tt.wantField {
+				t.Errorf("Expected violation on %s, got %s", tt.wantField, verr.Violations[0].Field)
+			}
+			if transport.Len() != 0 {
+				t.Errorf("Invalid order reached the transport: %v", transport.Orders())
+			}
+		})
+	}
 }
 
-// SetShouldFail configures the mock to fail on the next publish (for error testing)
-func (m *MockOrderEventPublisher) SetShouldFail(shouldFail bool) {
-	m.shouldFail = shouldFail
+// installInMemoryTracing installs a tracer provider that records every span in
+// memory, and W3C propagation, for the duration of the test.
+func installInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
 }
 
-// MessageCaptureMock is a specialized mock for contract testing that captures
-// published messages for verification. This enables the contract test to exercise
-// the actual port interface while capturing the result for Pact verification.
-type MessageCaptureMock struct {
-	onPublish func(*pb.OrderResult)
-}
+// assertProducerTelemetry checks that publishing an order produced a producer
+// span with the messaging semantic conventions consumers and dashboards rely
+// on, and an acknowledgment span linked to it.
+func assertProducerTelemetry(spans tracetest.SpanStubs) error {
+	var publish, ack *tracetest.SpanStub
+	for i := range spans {
+		switch spans[i].Name {
+		case kafka.Topic + " publish":
+			publish = &spans[i]
+		case kafka.Topic + " ack":
+			ack = &spans[i]
+		}
+	}
+	if publish == nil || ack == nil {
+		return fmt.Errorf("want %q and %q spans, got %d spans", kafka.Topic+" publish", kafka.Topic+" ack", len(spans))
+	}
+	if publish.SpanKind != trace.SpanKindProducer {
+		return fmt.Errorf("publish span kind = %v, want %v", publish.SpanKind, trace.SpanKindProducer)
+	}
 
-// Compile-time check that MessageCaptureMock implements OrderEventPublisher
-var _ ports.OrderEventPublisher = (*MessageCaptureMock)(nil)
+	want := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingDestinationName(kafka.Topic),
+		semconv.MessagingOperationPublish,
+		attribute.Bool("app.synthetic_request", true),
+	}
+	got := attribute.NewSet(publish.Attributes...)
+	for _, kv := range want {
+		if v, ok := got.Value(kv.Key); !ok || v != kv.Value {
+			return fmt.Errorf("publish span attribute %s = %q, want %q", kv.Key, v.Emit(), kv.Value.Emit())
+		}
+	}
 
-// PublishOrderCompleted implements the OrderEventPublisher interface and captures
-// the published order for contract test verification
-func (m *MessageCaptureMock) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
-	if m.onPublish != nil {
-		m.onPublish(order)
+	if len(ack.Links) != 1 || ack.Links[0].SpanContext.SpanID() != publish.SpanContext.SpanID() {
+		return fmt.Errorf("ack span links = %v, want a link to the publish span", ack.Links)
 	}
 	return nil
 }
 
-// TestPactSourceConfiguration verifies that the contract test correctly chooses
-// between broker and local file modes based on environment variables.
+// syntheticContext returns a context carrying the baggage that marks a request
+// as synthetic, as the load generator and contract tests do.
+func syntheticContext() context.Context {
+	synthetic, _ := baggage.NewMember(adapters.BaggageSyntheticRequest, "true")
+	session, _ := baggage.NewMember(adapters.BaggageSessionID, "contract-test")
+	bag, _ := baggage.New(synthetic, session)
+	return baggage.ContextWithBaggage(context.Background(), bag)
+}
+
+// TestPactSourceConfiguration verifies that the contract tests choose between
+// broker and local file modes as CONTRACT_TESTS selects.
 func TestPactSourceConfiguration(t *testing.T) {
-	// Save original environment
-	originalBrokerURL := os.Getenv("PACT_BROKER_URL")
-	defer os.Setenv("PACT_BROKER_URL", originalBrokerURL)
+	pactFile := filepath.Join(t.TempDir(), "consumer-checkout-provider.json")
+	if err := os.WriteFile(pactFile, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
-	// Test local file mode (no broker URL)
-	os.Unsetenv("PACT_BROKER_URL")
 	t.Run("LocalFileMode", func(t *testing.T) {
+		t.Setenv("CONTRACT_TESTS", "local")
+		t.Setenv("PACT_BROKER_URL", "https://test-broker.example.com")
 		verifyRequest := provider.VerifyRequest{}
+		pactSource(t, &verifyRequest, pactFile, "TestConsumerContract")
 
-		if brokerURL := os.Getenv("PACT_BROKER_URL"); brokerURL != "" {
-			t.Fatal("Expected empty broker URL for local file test")
-		} else {
-			verifyRequest.PactFiles = []string{
-				filepath.ToSlash("../accounting/tests/pacts/accounting-consumer-checkout-provider.json"),
-			}
-		}
-
-		if len(verifyRequest.PactFiles) == 0 {
-			t.Fatal("Expected PactFiles to be set in local file mode")
+		if len(verifyRequest.PactFiles) != 1 || verifyRequest.PactFiles[0] != filepath.ToSlash(pactFile) {
+			t.Fatalf("PactFiles = %v, want %s in local file mode", verifyRequest.PactFiles, pactFile)
 		}
 		if verifyRequest.BrokerURL != "" {
 			t.Fatal("Expected BrokerURL to be empty in local file mode")
 		}
 	})
 
-	// Test broker mode
-	os.Setenv("PACT_BROKER_URL", "https://test-broker.example.com")
 	t.Run("BrokerMode", func(t *testing.T) {
+		t.Setenv("CONTRACT_TESTS", "")
+		t.Setenv("PACT_BROKER_URL", "https://test-broker.example.com")
+		t.Setenv("GIT_COMMIT", "abc123")
 		verifyRequest := provider.VerifyRequest{}
+		pactSource(t, &verifyRequest, pactFile, "TestConsumerContract")
 
-		if brokerURL := os.Getenv("PACT_BROKER_URL"); brokerURL != "" {
-			verifyRequest.BrokerURL = brokerURL
-			verifyRequest.Provider = "checkout-provider"
-			verifyRequest.PublishVerificationResults = true
-		}
-
-		if verifyRequest.BrokerURL == "" {
+		if verifyRequest.BrokerURL != "https://test-broker.example.com" {
 			t.Fatal("Expected BrokerURL to be set in broker mode")
 		}
-		if verifyRequest.Provider != "checkout-provider" {
-			t.Fatal("Expected Provider to be set in broker mode")
+		if len(verifyRequest.PactFiles) != 0 {
+			t.Fatal("Expected PactFiles to be empty in broker mode")
+		}
+		if verifyRequest.Provider != "checkout-provider" || verifyRequest.ProviderVersion != "abc123" {
+			t.Fatal("Expected Provider and ProviderVersion to be set in broker mode")
 		}
 		if !verifyRequest.PublishVerificationResults {
 			t.Fatal("Expected PublishVerificationResults to be true in broker mode")
 		}
 	})
+
+	t.Run("Off", func(t *testing.T) {
+		t.Setenv("CONTRACT_TESTS", "off")
+		ran := false
+		t.Run("provider", func(t *testing.T) {
+			pactSource(t, &provider.VerifyRequest{}, pactFile, "TestConsumerContract")
+			ran = true
+		})
+		if ran {
+			t.Fatal("Expected the provider test to be skipped with CONTRACT_TESTS=off")
+		}
+	})
 }
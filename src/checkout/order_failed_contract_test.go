@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/message"
+	messagev3 "github.com/pact-foundation/pact-go/v2/message/v3"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Pact message contract for the OrderFailed event on the order-events topic.
+// The consumer test records what the fulfillment team relies on into
+// pacts/fulfillment-consumer-checkout-provider.json, and the provider test
+// verifies the event PlaceOrder emits when shipping fails after the card was
+// charged.
+const (
+	fulfillmentConsumer = "fulfillment-consumer"
+	fulfillmentPactFile = "pacts/fulfillment-consumer-checkout-provider.json"
+	orderFailedMessage  = "an order-failed event"
+)
+
+// orderFailedEventBody is the part of an OrderFailed event the fulfillment
+// consumer reads. Where and why the order failed are in the metadata.
+type orderFailedEventBody struct {
+	OrderID string `json:"orderId"`
+}
+
+// TestFulfillmentConsumerContract records the OrderFailed message.
+func TestFulfillmentConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+		Consumer: fulfillmentConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(fulfillmentPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.ShippingFails.Given()).
+		ExpectsToReceive(orderFailedMessage).
+		WithMetadata(map[string]string{
+			"contentType":            "application/json",
+			adapters.EventTypeHeader: string(ports.OrderFailedEvent),
+			"user_id":                "user-1",
+			"failed_step":            "ship",
+			"compensated":            "true",
+		}).
+		WithJSONContent(matchers.StructMatcher{
+			"orderId": matchers.Like("order-12345-contract-test"),
+		}).
+		AsType(&orderFailedEventBody{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			event := m.Content.(*orderFailedEventBody)
+			if event.OrderID == "" {
+				return fmt.Errorf("OrderFailed event %+v names no order", event)
+			}
+			if m.Metadata["failed_step"] == "" || m.Metadata["compensated"] == "" {
+				return fmt.Errorf("OrderFailed metadata %v names no failed step", m.Metadata)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFulfillmentProviderContract places an order whose shipment fails and
+// verifies the OrderFailed event it emits against the recorded fulfillment
+// contract.
+func TestFulfillmentProviderContract(t *testing.T) {
+	messageHandlers := message.Handlers{
+		orderFailedMessage: func(states []models.ProviderState) (message.Body, message.Metadata, error) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			svc.shippingProviders = newTestShippingProviders(newTestHTTPServices(t, true))
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			if _, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest()); err == nil {
+				return nil, nil, fmt.Errorf("PlaceOrder() with shipping down succeeded")
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				return nil, nil, err
+			}
+			if len(batches.batches) != 1 || len(batches.batches[0]) != 1 {
+				return nil, nil, fmt.Errorf("published %v, want one OrderFailed event", batches.batches)
+			}
+			event := batches.batches[0][0]
+
+			body, err := serialization.ToConsumerJSON(event.Order)
+			if err != nil {
+				return nil, nil, err
+			}
+			metadata := message.Metadata{
+				"contentType":            "application/json",
+				adapters.EventTypeHeader: string(event.Type),
+			}
+			for key, value := range event.Attributes {
+				metadata[key] = value
+			}
+			return body, metadata, nil
+		},
+	}
+	stateHandlers := providerstate.Handlers(providerstate.ShippingFails.Bind(nil))
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		StateHandlers:   stateHandlers,
+		MessageHandlers: messageHandlers,
+	}
+	pactSource(t, &verifyRequest, fulfillmentPactFile, "TestFulfillmentConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/consumer"
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+// Pact HTTP contract for the REST facade. The consumer test records what a web
+// client of POST /orders and GET /orders/{orderId} relies on into
+// pacts/web-client-checkout-provider.json, and the provider test verifies the
+// HTTP adapter in front of a checkout with fake downstream services.
+const (
+	webClientConsumer = "web-client"
+	webClientPactFile = "pacts/web-client-checkout-provider.json"
+)
+
+func newWebClientPact(t *testing.T) *consumer.V3HTTPMockProvider {
+	t.Helper()
+	p, err := consumer.NewV3Pact(consumer.MockHTTPProviderConfig{
+		Consumer: webClientConsumer,
+		Provider: "checkout-provider",
+		Host:     "127.0.0.1",
+		PactDir:  pactdir.For(t, filepath.Dir(webClientPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+	return p
+}
+
+// webClientOrder is the part of order a web client renders.
+func webClientOrder(order *pb.OrderResult) matchers.StructMatcher {
+	item := order.GetItems()[0]
+	return matchers.StructMatcher{
+		"orderId":            matchers.Like(order.GetOrderId()),
+		"shippingTrackingId": matchers.Like(order.GetShippingTrackingId()),
+		"shippingCost": matchers.StructMatcher{
+			"currencyCode": matchers.Regex(order.GetShippingCost().GetCurrencyCode(), "^[A-Z]{3}$"),
+			"units":        matchers.Integer(int(order.GetShippingCost().GetUnits())),
+		},
+		"items": matchers.EachLike(matchers.StructMatcher{
+			"item": matchers.StructMatcher{
+				"productId": matchers.Like(item.GetItem().GetProductId()),
+				"quantity":  matchers.Integer(int(item.GetItem().GetQuantity())),
+			},
+		}, 1),
+	}
+}
+
+// TestWebClientConsumerContract records the REST interactions of a web client.
+// The orders in the responses are the happy_path scenario, which the provider
+// states seed.
+func TestWebClientConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	placed := testdata.Scenario(t, "happy_path")
+
+	t.Run("POST /orders", func(t *testing.T) {
+		p := newWebClientPact(t)
+		err := p.AddInteraction().
+			GivenWithParameter(providerstate.DependenciesAvailable.Given()).
+			UponReceiving("a request to place an order").
+			WithRequest(http.MethodPost, "/orders", func(b *consumer.V3RequestBuilder) {
+				b.Header("Content-Type", matchers.S("application/json"))
+				b.Header("Idempotency-Key", matchers.Like("checkout-attempt-1"))
+				b.JSONBody(matchers.StructMatcher{
+					"userId":       matchers.Like("user-1"),
+					"userCurrency": matchers.Regex("USD", "^[A-Z]{3}$"),
+					"email":        matchers.Like("someone@example.com"),
+					"address": matchers.StructMatcher{
+						"streetAddress": matchers.Like("1600 Amphitheatre Parkway"),
+						"city":          matchers.Like("Mountain View"),
+						"country":       matchers.Like("US"),
+						"zipCode":       matchers.Like("94043"),
+					},
+					"creditCard": matchers.StructMatcher{
+						"creditCardNumber":          matchers.Like("4432-8015-6152-0454"),
+						"creditCardCvv":             matchers.Integer(672),
+						"creditCardExpirationYear":  matchers.Integer(2039),
+						"creditCardExpirationMonth": matchers.Integer(1),
+					},
+				})
+			}).
+			WillRespondWith(http.StatusCreated, func(b *consumer.V3ResponseBuilder) {
+				b.Header("Content-Type", matchers.S("application/json"))
+				b.Header("Location", matchers.Regex("/orders/"+placed.GetOrderId(), "^/orders/.+$"))
+				b.JSONBody(webClientOrder(placed))
+			}).
+			ExecuteTest(t, func(config consumer.MockServerConfig) error {
+				body := `{
+					"userId": "user-1",
+					"userCurrency": "USD",
+					"email": "someone@example.com",
+					"address": {"streetAddress": "1600 Amphitheatre Parkway", "city": "Mountain View", "country": "US", "zipCode": "94043"},
+					"creditCard": {"creditCardNumber": "4432-8015-6152-0454", "creditCardCvv": 672, "creditCardExpirationYear": 2039, "creditCardExpirationMonth": 1}
+				}`
+				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s:%d/orders", config.Host, config.Port), strings.NewReader(body))
+				if err != nil {
+					return err
+				}
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Idempotency-Key", "checkout-attempt-1")
+				return expectStatus(req, http.StatusCreated)
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("GET /orders/{orderId}", func(t *testing.T) {
+		p := newWebClientPact(t)
+		err := p.AddInteraction().
+			GivenWithParameter(providerstate.OrderPlaced.Given()).
+			UponReceiving("a request for a placed order").
+			WithRequest(http.MethodGet, "/orders/"+placed.GetOrderId(), func(b *consumer.V3RequestBuilder) {
+				b.Query("userId", matchers.S("user-1"))
+			}).
+			WillRespondWith(http.StatusOK, func(b *consumer.V3ResponseBuilder) {
+				b.Header("Content-Type", matchers.S("application/json"))
+				b.JSONBody(webClientOrder(placed))
+			}).
+			ExecuteTest(t, func(config consumer.MockServerConfig) error {
+				req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s:%d/orders/%s?userId=user-1", config.Host, config.Port, placed.GetOrderId()), nil)
+				if err != nil {
+					return err
+				}
+				return expectStatus(req, http.StatusOK)
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("GET /orders/{orderId} not found", func(t *testing.T) {
+		p := newWebClientPact(t)
+		err := p.AddInteraction().
+			GivenWithParameter(providerstate.NoOrders.Given()).
+			UponReceiving("a request for an unknown order").
+			WithRequest(http.MethodGet, "/orders/unknown-order", func(b *consumer.V3RequestBuilder) {
+				b.Query("userId", matchers.S("user-1"))
+			}).
+			WillRespondWith(http.StatusNotFound, func(b *consumer.V3ResponseBuilder) {
+				b.Header("Content-Type", matchers.S("application/json"))
+				b.JSONBody(matchers.StructMatcher{
+					"code":    matchers.S("NOT_FOUND"),
+					"message": matchers.Like("order unknown-order not found"),
+				})
+			}).
+			ExecuteTest(t, func(config consumer.MockServerConfig) error {
+				req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s:%d/orders/unknown-order?userId=user-1", config.Host, config.Port), nil)
+				if err != nil {
+					return err
+				}
+				return expectStatus(req, http.StatusNotFound)
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// expectStatus sends req and checks the response status.
+func expectStatus(req *http.Request, want int) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s = %d, want %d: %s", req.Method, req.URL.Path, resp.StatusCode, want, body)
+	}
+	return nil
+}
+
+// TestWebClientProviderContract verifies the HTTP adapter against the recorded
+// web client contract. Provider states seed the order repository GET reads
+// from; POST places a real order against fake downstream services.
+func TestWebClientProviderContract(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	var repo *adapters.InMemoryOrderRepository
+	resetOrders := func() {
+		repo = adapters.NewInMemoryOrderRepository(100)
+		svc.orderRepository = repo
+	}
+	resetOrders()
+
+	srv := httptest.NewServer(adapters.NewHTTPCheckoutHandler(svc, logger))
+	defer srv.Close()
+
+	reset := func(bool, providerstate.Params) error {
+		resetOrders()
+		return nil
+	}
+	stateHandlers := providerstate.Handlers(
+		providerstate.DependenciesAvailable.Bind(reset),
+		providerstate.OrderPlaced.Bind(func(setup bool, params providerstate.Params) error {
+			resetOrders()
+			if setup {
+				return repo.Save(t.Context(), params.String("userId"), createOrderResultFromBusinessLogicPatterns(t))
+			}
+			return nil
+		}),
+		providerstate.NoOrders.Bind(reset),
+	)
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		ProviderBaseURL: srv.URL,
+		StateHandlers:   stateHandlers,
+	}
+	pactSource(t, &verifyRequest, webClientPactFile, "TestWebClientConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
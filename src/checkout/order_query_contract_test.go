@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	message "github.com/pact-foundation/pact-go/v2/message/v4"
+	"github.com/pact-foundation/pact-go/v2/provider"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+// Pact gRPC contract for the GetOrder and ListOrders query RPCs. The consumer
+// test records what a client of the order queries relies on into
+// pacts/order-query-client-checkout-provider.json, and the provider test
+// verifies the real gRPC server against it. Both need the pact protobuf plugin
+// (pact-plugin-cli install protobuf).
+const (
+	orderQueryConsumer = "order-query-client"
+	orderQueryPactFile = "pacts/order-query-client-checkout-provider.json"
+	protobufPlugin     = "0.5.4"
+)
+
+// orderQueryInteraction returns the plugin configuration of an interaction
+// with method of the CheckoutService.
+func orderQueryInteraction(t *testing.T, method, body string) string {
+	t.Helper()
+	proto, err := filepath.Abs("../../pb/demo.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `{
+		"pact:proto": "` + filepath.ToSlash(proto) + `",
+		"pact:proto-service": "CheckoutService/` + method + `",
+		"pact:content-type": "application/protobuf",
+		` + body + `
+	}`
+}
+
+// dialOrderQueries connects a CheckoutService client to the pact mock server.
+func dialOrderQueries(t *testing.T, transport message.TransportConfig) pb.CheckoutServiceClient {
+	t.Helper()
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", transport.Port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect to the mock server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewCheckoutServiceClient(conn)
+}
+
+func newOrderQueryPact(t *testing.T) *message.SynchronousPact {
+	t.Helper()
+	p, err := message.NewSynchronousPact(message.Config{
+		Consumer: orderQueryConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(orderQueryPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+	return p
+}
+
+// TestOrderQueryConsumerContract records the order query interactions. The
+// placed order is the happy_path scenario, which the provider states seed.
+func TestOrderQueryConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	plugin := message.PluginConfig{Plugin: "protobuf", Version: protobufPlugin}
+	placed := testdata.Scenario(t, "happy_path")
+
+	t.Run("GetOrder", func(t *testing.T) {
+		p := newOrderQueryPact(t)
+		err := p.AddSynchronousMessage("GetOrder returns a placed order").
+			GivenWithParameter(providerstate.OrderPlaced.Given()).
+			UsingPlugin(plugin).
+			WithContents(orderQueryInteraction(t, "GetOrder", fmt.Sprintf(`
+				"request": {
+					"user_id": "matching(type, 'user-1')",
+					"order_id": "matching(type, '%[1]s')"
+				},
+				"response": {
+					"order": {
+						"order_id": "notEmpty('%[1]s')",
+						"shipping_tracking_id": "notEmpty('%[2]s')",
+						"shipping_cost": {
+							"currency_code": "matching(regex, '^[A-Z]{3}$', '%[3]s')",
+							"units": "matching(integer, %[4]d)"
+						}
+					}
+				}`, placed.GetOrderId(), placed.GetShippingTrackingId(), placed.GetShippingCost().GetCurrencyCode(), placed.GetShippingCost().GetUnits())), "application/protobuf").
+			StartTransport("grpc", "127.0.0.1", nil).
+			ExecuteTest(t, func(transport message.TransportConfig, m message.SynchronousMessage) error {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				resp, err := dialOrderQueries(t, transport).GetOrder(ctx, &pb.GetOrderRequest{UserId: "user-1", OrderId: placed.GetOrderId()})
+				if err != nil {
+					return err
+				}
+				if resp.Order.GetOrderId() != placed.GetOrderId() {
+					return fmt.Errorf("GetOrder() = %v, want %s", resp.Order, placed.GetOrderId())
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("GetOrder not found", func(t *testing.T) {
+		p := newOrderQueryPact(t)
+		err := p.AddSynchronousMessage("GetOrder of an unknown order").
+			GivenWithParameter(providerstate.NoOrders.Given()).
+			UsingPlugin(plugin).
+			WithContents(orderQueryInteraction(t, "GetOrder", `
+				"request": {
+					"user_id": "matching(type, 'user-1')",
+					"order_id": "matching(type, 'unknown-order')"
+				},
+				"responseMetadata": {
+					"grpc-status": "NOT_FOUND"
+				}`), "application/protobuf").
+			StartTransport("grpc", "127.0.0.1", nil).
+			ExecuteTest(t, func(transport message.TransportConfig, m message.SynchronousMessage) error {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_, err := dialOrderQueries(t, transport).GetOrder(ctx, &pb.GetOrderRequest{UserId: "user-1", OrderId: "unknown-order"})
+				if status.Code(err) != codes.NotFound {
+					return fmt.Errorf("GetOrder() = %v, want %v", err, codes.NotFound)
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ListOrders", func(t *testing.T) {
+		p := newOrderQueryPact(t)
+		err := p.AddSynchronousMessage("ListOrders returns the user's orders").
+			GivenWithParameter(providerstate.OrderPlaced.Given()).
+			UsingPlugin(plugin).
+			WithContents(orderQueryInteraction(t, "ListOrders", fmt.Sprintf(`
+				"request": {
+					"user_id": "matching(type, 'user-1')",
+					"page_size": "matching(integer, 10)"
+				},
+				"response": {
+					"orders": {
+						"pact:match": "eachValue(matching($'OrderResult'))",
+						"OrderResult": {
+							"order_id": "notEmpty('%s')",
+							"shipping_tracking_id": "notEmpty('%s')"
+						}
+					}
+				}`, placed.GetOrderId(), placed.GetShippingTrackingId())), "application/protobuf").
+			StartTransport("grpc", "127.0.0.1", nil).
+			ExecuteTest(t, func(transport message.TransportConfig, m message.SynchronousMessage) error {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				resp, err := dialOrderQueries(t, transport).ListOrders(ctx, &pb.ListOrdersRequest{UserId: "user-1", PageSize: 10})
+				if err != nil {
+					return err
+				}
+				if len(resp.Orders) == 0 {
+					return fmt.Errorf("ListOrders() returned no orders")
+				}
+				return nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestOrderQueryProviderContract verifies the GetOrder and ListOrders RPCs of
+// a real gRPC server against the recorded contract. Provider states seed the
+// order repository the RPCs read from.
+func TestOrderQueryProviderContract(t *testing.T) {
+	var repo *adapters.InMemoryOrderRepository
+	svc := &checkout{}
+	resetOrders := func() {
+		repo = adapters.NewInMemoryOrderRepository(100)
+		svc.orderRepository = repo
+	}
+	resetOrders()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	pb.RegisterCheckoutServiceServer(srv, svc)
+	go srv.Serve(lis)
+	defer srv.Stop()
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	stateHandlers := providerstate.Handlers(
+		providerstate.OrderPlaced.Bind(func(setup bool, params providerstate.Params) error {
+			resetOrders()
+			if setup {
+				return repo.Save(context.Background(), params.String("userId"), createOrderResultFromBusinessLogicPatterns(t))
+			}
+			return nil
+		}),
+		providerstate.NoOrders.Bind(func(bool, providerstate.Params) error {
+			resetOrders()
+			return nil
+		}),
+	)
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		ProviderBaseURL: fmt.Sprintf("http://127.0.0.1:%d", port),
+		Transports:      []provider.Transport{{Protocol: "grpc", Port: uint16(port)}},
+		StateHandlers:   stateHandlers,
+	}
+	pactSource(t, &verifyRequest, orderQueryPactFile, "TestOrderQueryConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
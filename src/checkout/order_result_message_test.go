@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+// TestOrderResultMessageGeneration publishes the happy_path order through the
+// Kafka adapter and compares the consumer JSON of the message produced with
+// the expected message field by field, types included, so that any field
+// added, dropped or retyped fails with its path.
+func TestOrderResultMessageGeneration(t *testing.T) {
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	publisher := adapters.NewKafkaOrderEventPublisher(producer, slog.New(slog.DiscardHandler))
+	if err := publisher.PublishOrderCompleted(context.Background(), testdata.Scenario(t, "happy_path")); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	value, err := producer.Messages()[0].Value.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := &pb.OrderResult{}
+	if err := proto.Unmarshal(value, published); err != nil {
+		t.Fatalf("proto.Unmarshal() = %v", err)
+	}
+	got, err := serialization.ToConsumerJSON(published)
+	if err != nil {
+		t.Fatalf("ToConsumerJSON() = %v", err)
+	}
+
+	money := func(units int64) map[string]any {
+		return map[string]any{"currencyCode": "USD", "units": units, "nanos": 0}
+	}
+	testdata.MatchJSON(t, map[string]any{
+		"orderId":            "order-12345-contract-test",
+		"shippingTrackingId": "TRACK-CONTRACT-789",
+		"shippingCost":       money(8),
+		"shippingAddress": map[string]any{
+			"streetAddress": "456 Contract St",
+			"city":          "Test City",
+			"state":         "CA",
+			"country":       "USA",
+			"zipCode":       "90210",
+		},
+		"items": []any{
+			map[string]any{
+				"item": map[string]any{"productId": "CONTRACT-PRODUCT-001", "quantity": 2},
+				"cost": money(15),
+			},
+			map[string]any{
+				"item": map[string]any{"productId": "CONTRACT-PRODUCT-002", "quantity": 1},
+				"cost": money(25),
+			},
+		},
+	}, got)
+}
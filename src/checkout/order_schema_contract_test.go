@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/message"
+	messagev3 "github.com/pact-foundation/pact-go/v2/message/v3"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Pact message contract for the versions of the order event. Each schema
+// version is its own interaction, so that a consumer moving to version 2 keeps
+// verifying version 1 until ORDER_EVENT_SCHEMA_VERSION changes everywhere.
+// Version 2 keeps the OrderResult body and adds the order.schema.version and
+// order.payments headers, and version 3 adds the order.fees header.
+const (
+	paymentsConsumer       = "payments-consumer"
+	paymentsPactFile       = "pacts/payments-consumer-checkout-provider.json"
+	orderSchemaV1          = "an order-result message in schema version 1"
+	orderSchemaV2          = "an order-result message in schema version 2 with payments"
+	orderSchemaV3          = "an order-result message in schema version 3 with fees"
+	paymentsV2CardOnly     = `[{"type":"card","amount":{"currencyCode":"USD","units":47,"nanos":980000000},"transactionId":"tx-1"}]`
+	paymentsV3InsuredOrder = `[{"type":"card","amount":{"currencyCode":"USD","units":58,"nanos":370000000},"transactionId":"tx-1"}]`
+	feesV3InsuredOrder     = `{"base":{"currencyCode":"USD","units":8,"nanos":0},"insurance":{"currencyCode":"USD","units":0,"nanos":390000000},"surcharges":{"currencyCode":"USD","units":10,"nanos":0}}`
+	orderSchemaVersion2    = "2"
+	orderSchemaVersion3    = "3"
+)
+
+// versionedOrder is the part of an OrderResult the payments consumer reads in
+// every schema version.
+type versionedOrder struct {
+	OrderID string `json:"orderId"`
+}
+
+// paymentLineV2 is a line of the order.payments header of schema version 2.
+type paymentLineV2 struct {
+	Type   string `json:"type"`
+	Amount struct {
+		CurrencyCode string `json:"currencyCode"`
+		Units        int64  `json:"units"`
+		Nanos        int32  `json:"nanos"`
+	} `json:"amount"`
+	TransactionID string `json:"transactionId"`
+}
+
+// feesV3 is the order.fees header of schema version 3.
+type feesV3 struct {
+	Base       feeV3 `json:"base"`
+	Insurance  feeV3 `json:"insurance"`
+	Surcharges feeV3 `json:"surcharges"`
+}
+
+// feeV3 is a fee of the order.fees header of schema version 3.
+type feeV3 struct {
+	CurrencyCode string `json:"currencyCode"`
+	Units        int64  `json:"units"`
+	Nanos        int32  `json:"nanos"`
+}
+
+// TestPaymentsConsumerContract records every version of the order-result
+// message.
+func TestPaymentsConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+		Consumer: paymentsConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(paymentsPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+	body := matchers.StructMatcher{
+		"orderId": matchers.Like("order-12345-contract-test"),
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.OrderPaidByCard.Given()).
+		ExpectsToReceive(orderSchemaV1).
+		WithMetadata(map[string]string{
+			"contentType":            "application/json",
+			adapters.EventTypeHeader: string(ports.OrderCompletedEvent),
+		}).
+		WithJSONContent(body).
+		AsType(&versionedOrder{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			if order := m.Content.(*versionedOrder); order.OrderID == "" {
+				return fmt.Errorf("order %+v has no ID", order)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.OrderPaidByCard.Given()).
+		ExpectsToReceive(orderSchemaV2).
+		WithMetadata(map[string]string{
+			"contentType":                "application/json",
+			adapters.EventTypeHeader:     string(ports.OrderCompletedEvent),
+			adapters.SchemaVersionHeader: orderSchemaVersion2,
+			adapters.PaymentsHeader:      paymentsV2CardOnly,
+		}).
+		WithJSONContent(body).
+		AsType(&versionedOrder{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			if version := m.Metadata[adapters.SchemaVersionHeader]; version != orderSchemaVersion2 {
+				return fmt.Errorf("schema version %v, want 2", version)
+			}
+			header, _ := m.Metadata[adapters.PaymentsHeader].(string)
+			var payments []paymentLineV2
+			if err := json.Unmarshal([]byte(header), &payments); err != nil {
+				return fmt.Errorf("failed to parse payments %q: %w", header, err)
+			}
+			if len(payments) == 0 || payments[0].Type == "" || payments[0].TransactionID == "" {
+				return fmt.Errorf("payments %+v have no tender or transaction", payments)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.InsuredExpressOrder.Given()).
+		ExpectsToReceive(orderSchemaV3).
+		WithMetadata(map[string]string{
+			"contentType":                "application/json",
+			adapters.EventTypeHeader:     string(ports.OrderCompletedEvent),
+			adapters.SchemaVersionHeader: orderSchemaVersion3,
+			adapters.PaymentsHeader:      paymentsV3InsuredOrder,
+			adapters.FeesHeader:          feesV3InsuredOrder,
+		}).
+		WithJSONContent(body).
+		AsType(&versionedOrder{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			if version := m.Metadata[adapters.SchemaVersionHeader]; version != orderSchemaVersion3 {
+				return fmt.Errorf("schema version %v, want 3", version)
+			}
+			header, _ := m.Metadata[adapters.FeesHeader].(string)
+			var fees feesV3
+			if err := json.Unmarshal([]byte(header), &fees); err != nil {
+				return fmt.Errorf("failed to parse fees %q: %w", header, err)
+			}
+			if fees.Base.CurrencyCode == "" || fees.Insurance.CurrencyCode == "" || fees.Surcharges.CurrencyCode == "" {
+				return fmt.Errorf("fees %+v are missing base, insurance or surcharges", fees)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPaymentsProviderContract places an order paid by card in each schema
+// version and verifies the OrderResult it emits against the recorded payments
+// contract. md holds the order options of the interaction.
+func TestPaymentsProviderContract(t *testing.T) {
+	orderResultIn := func(version int, md metadata.MD) message.Handler {
+		return func(states []models.ProviderState) (message.Body, message.Metadata, error) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			svc.SetSchemaVersion(version)
+			svc.insurancePercent = 1
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+			if _, err := svc.PlaceOrder(ctx, testPlaceOrderRequest()); err != nil {
+				return nil, nil, err
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				return nil, nil, err
+			}
+			if len(batches.batches) != 1 {
+				return nil, nil, fmt.Errorf("published %v, want one batch", batches.batches)
+			}
+			for _, event := range batches.batches[0] {
+				if event.Type != ports.OrderCompletedEvent {
+					continue
+				}
+				body, err := serialization.ToConsumerJSON(event.Order)
+				if err != nil {
+					return nil, nil, err
+				}
+				meta := message.Metadata{
+					"contentType":            "application/json",
+					adapters.EventTypeHeader: string(event.Type),
+				}
+				for key, value := range batches.headers[0] {
+					meta[key] = value
+				}
+				return body, meta, nil
+			}
+			return nil, nil, fmt.Errorf("published %v, want an OrderCompleted event", batches.batches)
+		}
+	}
+	messageHandlers := message.Handlers{
+		orderSchemaV1: orderResultIn(1, nil),
+		orderSchemaV2: orderResultIn(2, nil),
+		orderSchemaV3: orderResultIn(3, metadata.Pairs(
+			shippingMethodHeader, adapters.ShippingMethodExpress,
+			shippingInsuranceHeader, "true",
+		)),
+	}
+	stateHandlers := providerstate.Handlers(
+		providerstate.OrderPaidByCard.Bind(nil),
+		providerstate.InsuredExpressOrder.Bind(nil),
+	)
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		StateHandlers:   stateHandlers,
+		MessageHandlers: messageHandlers,
+	}
+	pactSource(t, &verifyRequest, paymentsPactFile, "TestPaymentsConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/message"
+	messagev3 "github.com/pact-foundation/pact-go/v2/message/v3"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Pact message contract for the OutOfStock event on the order-events topic.
+// The consumer test records what the inventory team relies on into
+// pacts/inventory-consumer-checkout-provider.json, and the provider test
+// verifies the event PlaceOrder emits when a product cannot be reserved.
+const (
+	inventoryConsumer = "inventory-consumer"
+	inventoryPactFile = "pacts/inventory-consumer-checkout-provider.json"
+	outOfStockMessage = "an out-of-stock event"
+)
+
+// outOfStockEvent is the part of an OutOfStock event the inventory consumer
+// reads.
+type outOfStockEvent struct {
+	OrderID string `json:"orderId"`
+	Items   []struct {
+		Item struct {
+			ProductID string `json:"productId"`
+			Quantity  int    `json:"quantity"`
+		} `json:"item"`
+	} `json:"items"`
+}
+
+// TestInventoryConsumerContract records the OutOfStock message.
+func TestInventoryConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+		Consumer: inventoryConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(inventoryPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.OutOfStock.Given()).
+		ExpectsToReceive(outOfStockMessage).
+		WithMetadata(map[string]string{
+			"contentType":            "application/json",
+			adapters.EventTypeHeader: string(ports.OutOfStockEvent),
+		}).
+		WithJSONContent(matchers.StructMatcher{
+			"orderId": matchers.Like("order-12345-contract-test"),
+			"items": matchers.EachLike(matchers.StructMatcher{
+				"item": matchers.StructMatcher{
+					"productId": matchers.Like("OLJCESPC7Z"),
+					"quantity":  matchers.Integer(2),
+				},
+			}, 1),
+		}).
+		AsType(&outOfStockEvent{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			event := m.Content.(*outOfStockEvent)
+			if event.OrderID == "" || len(event.Items) == 0 || event.Items[0].Item.ProductID == "" {
+				return fmt.Errorf("OutOfStock event %+v names no order or product", event)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestInventoryProviderContract places an order whose product cannot be
+// reserved and verifies the OutOfStock event it emits against the recorded
+// inventory contract.
+func TestInventoryProviderContract(t *testing.T) {
+	messageHandlers := message.Handlers{
+		outOfStockMessage: func(states []models.ProviderState) (message.Body, message.Metadata, error) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			// The fake cart holds 2 of OLJCESPC7Z
+			svc.inventory = adapters.NewInMemoryInventoryReserver(map[string]int64{"OLJCESPC7Z": 1})
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			if _, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest()); err == nil {
+				return nil, nil, fmt.Errorf("PlaceOrder() of a product out of stock succeeded")
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				return nil, nil, err
+			}
+			// The OutOfStock event is followed by OrderFailed
+			if len(batches.batches) == 0 || batches.batches[0][0].Type != ports.OutOfStockEvent {
+				return nil, nil, fmt.Errorf("published %v, want an OutOfStock event first", batches.batches)
+			}
+			event := batches.batches[0][0]
+
+			body, err := serialization.ToConsumerJSON(event.Order)
+			if err != nil {
+				return nil, nil, err
+			}
+			metadata := message.Metadata{
+				"contentType":            "application/json",
+				adapters.EventTypeHeader: string(event.Type),
+			}
+			for key, value := range event.Attributes {
+				metadata[key] = value
+			}
+			return body, metadata, nil
+		},
+	}
+	stateHandlers := providerstate.Handlers(providerstate.OutOfStock.Bind(nil))
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		StateHandlers:   stateHandlers,
+		MessageHandlers: messageHandlers,
+	}
+	pactSource(t, &verifyRequest, inventoryPactFile, "TestInventoryConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
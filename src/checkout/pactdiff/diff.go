@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactdiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactmatch"
+)
+
+// differ collects the changes of the interactions of two pacts.
+type differ struct {
+	interaction string
+	changes     []Change
+}
+
+func (d *differ) report(path string, breaking bool, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Interaction: d.interaction,
+		Path:        path,
+		Breaking:    breaking,
+		Detail:      fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) compareInteractions(before, after *interaction) {
+	d.compareRequests(before.request, after.request)
+
+	byName := map[string]part{}
+	for _, p := range before.expected {
+		byName[p.name] = p
+	}
+	for _, p := range after.expected {
+		old, ok := byName[p.name]
+		delete(byName, p.name)
+		switch {
+		case p.keyed && ok:
+			// A missing map is an empty one, so that each key is reported
+			d.compareKeyed(p.name, old, p)
+		case p.value == nil:
+			if ok && old.value != nil {
+				d.report(p.name, false, "removed, the consumer no longer expects it")
+			}
+		case !ok || old.value == nil:
+			d.report(p.name, true, "added, the consumer now expects it")
+		case p.name == "response.status":
+			if !pactmatch.Equal(old.value, p.value) {
+				d.report(p.name, true, "the consumer now expects %s instead of %s", pactmatch.Describe(p.value), pactmatch.Describe(old.value))
+			}
+		default:
+			d.compareBody(p.name, nil, old.value, p.value, old.rules, p.rules)
+		}
+	}
+	for _, name := range sortedKeys(byName) {
+		if byName[name].value != nil {
+			d.report(name, false, "removed, the consumer no longer expects it")
+		}
+	}
+}
+
+// compareRequests reports a change of what the consumer sends. Any change is
+// breaking, since the provider must handle the new request.
+func (d *differ) compareRequests(before, after any) {
+	b, _ := before.(map[string]any)
+	a, _ := after.(map[string]any)
+	var fields []string
+	for _, field := range sortedKeys(b, a) {
+		if !pactmatch.Equal(b[field], a[field]) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > 0 {
+		d.report("request", true, "%s changed, the provider must handle the new request", strings.Join(fields, ", "))
+	}
+}
+
+// compareKeyed compares headers or metadata, which the consumer may receive
+// more of than it expects.
+func (d *differ) compareKeyed(name string, before, after part) {
+	b, _ := before.value.(map[string]any)
+	a, _ := after.value.(map[string]any)
+	for _, key := range sortedKeys(b, a) {
+		path := name + " " + key
+		oldValue, inOld := b[key]
+		newValue, inNew := a[key]
+		switch {
+		case !inOld:
+			d.report(path, true, "added, the consumer now expects %s", pactmatch.Describe(newValue))
+		case !inNew:
+			d.report(path, false, "removed, the consumer no longer expects it")
+		default:
+			d.compareValue(path, oldValue, newValue, keyRule(before.rules, key), keyRule(after.rules, key))
+		}
+	}
+}
+
+// compareBody compares the example bodies before and after at path, with the
+// rules that apply to them. As in pact, a body may have fields its example
+// lacks, but arrays must have the length of the example unless matched by
+// type.
+func (d *differ) compareBody(name string, path []pactmatch.Segment, before, after any, beforeRules, afterRules []pactmatch.Rule) {
+	location := name + " " + pactmatch.FormatPath(path)
+	oldRule, newRule := pactmatch.RuleFor(beforeRules, path), pactmatch.RuleFor(afterRules, path)
+	ruleChanged := d.compareRule(location, oldRule, newRule)
+
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(b, a) {
+			child := append(slices.Clip(path), pactmatch.Segment(key))
+			oldValue, inOld := b[key]
+			newValue, inNew := a[key]
+			switch {
+			case !inOld:
+				d.report(name+" "+pactmatch.FormatPath(child), true, "added, the consumer now expects %s", pactmatch.Describe(newValue))
+			case !inNew:
+				d.report(name+" "+pactmatch.FormatPath(child), false, "removed, the consumer no longer reads it")
+			default:
+				d.compareBody(name, child, oldValue, newValue, beforeRules, afterRules)
+			}
+		}
+		return
+	case []any:
+		a, ok := after.([]any)
+		if !ok {
+			break
+		}
+		if newRule != nil && newRule.Typed() {
+			// Every element is matched against the first of the example
+			if len(b) > 0 && len(a) > 0 {
+				d.compareBody(name, append(slices.Clip(path), pactmatch.Segment(0)), b[0], a[0], beforeRules, afterRules)
+			}
+			return
+		}
+		if len(a) != len(b) {
+			d.report(location, true, "the consumer now expects %d elements instead of %d", len(a), len(b))
+		}
+		for i := range min(len(a), len(b)) {
+			d.compareBody(name, append(slices.Clip(path), pactmatch.Segment(i)), b[i], a[i], beforeRules, afterRules)
+		}
+		return
+	}
+	d.compareExample(location, before, after, newRule, ruleChanged)
+}
+
+// compareValue compares a header or metadata value.
+func (d *differ) compareValue(path string, before, after any, oldRule, newRule *pactmatch.Rule) {
+	ruleChanged := d.compareRule(path, oldRule, newRule)
+	d.compareExample(path, before, after, newRule, ruleChanged)
+}
+
+// compareRule reports a change of the rule of a path, and whether there was
+// one.
+func (d *differ) compareRule(path string, before, after *pactmatch.Rule) bool {
+	switch compareRules(before, after) {
+	case looser:
+		d.report(path, false, "now matched by %s instead of %s", describeRule(after), describeRule(before))
+	case stricter, changed:
+		d.report(path, true, "now matched by %s instead of %s", describeRule(after), describeRule(before))
+	default:
+		return false
+	}
+	return true
+}
+
+// compareExample compares the example values of a path, which matter when
+// the path is matched by equality or the kind of value changed.
+func (d *differ) compareExample(path string, before, after any, newRule *pactmatch.Rule, ruleChanged bool) {
+	switch {
+	case pactmatch.Equal(before, after):
+	case pactmatch.Kind(before) != pactmatch.Kind(after):
+		d.report(path, true, "the consumer now expects %s instead of %s", pactmatch.Describe(after), pactmatch.Describe(before))
+	case newRule == nil:
+		d.report(path, true, "the consumer now expects %s instead of %s", pactmatch.Describe(after), pactmatch.Describe(before))
+	case !ruleChanged:
+		d.report(path, false, "example changed from %s to %s", pactmatch.Describe(before), pactmatch.Describe(after))
+	}
+}
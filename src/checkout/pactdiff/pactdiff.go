@@ -0,0 +1,307 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pactdiff compares two versions of the pact of a consumer and
+// classifies each change as breaking or compatible for the provider, so that
+// a change of a consumer contract can be reviewed before it fails the
+// verification of the provider:
+//
+//	report, err := pactdiff.Diff(oldPact, newPact)
+//	if report.Breaking() { ... }
+//
+// A change is breaking when the new pact asks more of the provider: a new
+// interaction or provider state, a field or header it did not expect, a value
+// that must now be equal, a stricter matcher, or a different request. It is
+// compatible when it asks less: a removed interaction or field, or a looser
+// matcher. Matcher changes that are neither clearly stricter nor looser, such
+// as a new regular expression, are breaking.
+//
+// HTTP and message interactions of pact specification 2 to 4 are compared.
+package pactdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactmatch"
+)
+
+// Change is a difference between two versions of an interaction.
+type Change struct {
+	// Interaction is the description of the interaction, with its provider
+	// states
+	Interaction string
+	// Path locates the change in the interaction, such as
+	// "response.body $.items[0].cost" or "metadata customer_id", and is empty
+	// for a change of the whole interaction
+	Path     string
+	Breaking bool
+	Detail   string
+}
+
+func (c Change) String() string {
+	severity := "compatible"
+	if c.Breaking {
+		severity = "BREAKING"
+	}
+	if c.Path == "" {
+		return fmt.Sprintf("%s %s: %s", severity, c.Interaction, c.Detail)
+	}
+	return fmt.Sprintf("%s %s: %s: %s", severity, c.Interaction, c.Path, c.Detail)
+}
+
+// Report is the changes between two versions of a pact, ordered by
+// interaction and path.
+type Report struct {
+	Consumer string
+	Provider string
+	Changes  []Change
+}
+
+// Breaking reports whether any change is breaking.
+func (r Report) Breaking() bool {
+	return slices.ContainsFunc(r.Changes, func(c Change) bool { return c.Breaking })
+}
+
+// Write writes one line per change, or that there is none.
+func (r Report) Write(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "%s -> %s: no change\n", r.Consumer, r.Provider)
+		return err
+	}
+	for _, c := range r.Changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff compares the pact files oldPact and newPact, which must be between
+// the same consumer and provider.
+func Diff(oldPact, newPact []byte) (Report, error) {
+	before, err := parsePact(oldPact)
+	if err != nil {
+		return Report{}, fmt.Errorf("old pact: %w", err)
+	}
+	after, err := parsePact(newPact)
+	if err != nil {
+		return Report{}, fmt.Errorf("new pact: %w", err)
+	}
+	if before.consumer != after.consumer || before.provider != after.provider {
+		return Report{}, fmt.Errorf("the pacts are between different pacticipants: %s -> %s and %s -> %s",
+			before.consumer, before.provider, after.consumer, after.provider)
+	}
+
+	d := &differ{}
+	for _, key := range sortedKeys(before.interactions, after.interactions) {
+		o, inOld := before.interactions[key]
+		n, inNew := after.interactions[key]
+		d.interaction = key
+		switch {
+		case !inOld:
+			d.report("", true, "added, the provider must now satisfy it")
+		case !inNew:
+			d.report("", false, "removed")
+		default:
+			d.compareInteractions(o, n)
+		}
+	}
+	return Report{Consumer: after.consumer, Provider: after.provider, Changes: d.changes}, nil
+}
+
+// pact is a parsed pact file.
+type pact struct {
+	consumer, provider string
+	// interactions are keyed by description and provider states, since an
+	// interaction given other states is another interaction for the provider
+	interactions map[string]*interaction
+}
+
+// interaction is what one interaction sends to the provider and expects of
+// it.
+type interaction struct {
+	// request is what the consumer sends, with its matching rules, and nil
+	// for an asynchronous message
+	request any
+	// expected are the parts of the response or message the consumer
+	// expects, in order
+	expected []part
+}
+
+// part is a part of a response or message: its body, its status, or its
+// headers or metadata.
+type part struct {
+	name string
+	// keyed parts are maps of headers or metadata, whose rules are by key
+	keyed bool
+	value any
+	rules []pactmatch.Rule
+}
+
+func parsePact(data []byte) (*pact, error) {
+	var file struct {
+		Consumer     struct{ Name string } `json:"consumer"`
+		Provider     struct{ Name string } `json:"provider"`
+		Interactions []json.RawMessage     `json:"interactions"`
+		Messages     []json.RawMessage     `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Consumer.Name == "" || file.Provider.Name == "" {
+		return nil, fmt.Errorf("no consumer or provider name")
+	}
+	p := &pact{consumer: file.Consumer.Name, provider: file.Provider.Name, interactions: map[string]*interaction{}}
+	for _, raw := range append(file.Interactions, file.Messages...) {
+		v, err := pactmatch.DecodeJSON(raw)
+		if err != nil {
+			return nil, err
+		}
+		obj, _ := v.(map[string]any)
+		key, i, err := parseInteraction(obj)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		p.interactions[key] = i
+	}
+	return p, nil
+}
+
+// parseInteraction returns the key and contents of an HTTP or message
+// interaction.
+func parseInteraction(obj map[string]any) (string, *interaction, error) {
+	key := interactionKey(obj)
+	i := &interaction{}
+	var err error
+	if request, ok := obj["request"].(map[string]any); ok {
+		i.request = map[string]any{
+			"method":        request["method"],
+			"path":          request["path"],
+			"query":         request["query"],
+			"headers":       lowerKeys(request["headers"]),
+			"body":          content(request["body"]),
+			"contents":      content(request["contents"]),
+			"metadata":      request["metadata"],
+			"matchingRules": request["matchingRules"],
+		}
+	}
+	switch response := obj["response"].(type) {
+	case map[string]any:
+		// HTTP
+		i.expected, err = parseParts("response", response, "body", "headers")
+		if status, ok := response["status"]; ok {
+			i.expected = append([]part{{name: "response.status", value: status}}, i.expected...)
+		}
+	case []any:
+		// Synchronous messages, with one or more possible responses
+		for n, r := range response {
+			name := "response"
+			if len(response) > 1 {
+				name = fmt.Sprintf("response[%d]", n)
+			}
+			r, _ := r.(map[string]any)
+			parts, partErr := parseParts(name, r, "contents", "metadata")
+			if partErr != nil {
+				err = partErr
+			}
+			i.expected = append(i.expected, parts...)
+		}
+	default:
+		// Asynchronous message
+		i.expected, err = parseParts("", obj, "contents", "metadata")
+	}
+	return key, i, err
+}
+
+// parseParts returns the body and keyed parts of obj, prefixed by name,
+// with the matching rules of obj.
+func parseParts(name string, obj map[string]any, bodyKey, keyedKey string) ([]part, error) {
+	rules, err := parseRules(obj["matchingRules"])
+	if err != nil {
+		return nil, err
+	}
+	prefix := name
+	if prefix != "" {
+		prefix += "."
+	}
+	keyedRules := rules["metadata"]
+	if keyedKey == "headers" {
+		keyedRules = rules["header"]
+	}
+	keyed := obj[keyedKey]
+	if keyedKey == "headers" {
+		keyed = lowerKeys(keyed)
+	}
+	return []part{
+		{name: prefix + "body", value: content(obj[bodyKey]), rules: rules["body"]},
+		{name: prefix + keyedKey, keyed: true, value: keyed, rules: keyedRules},
+	}, nil
+}
+
+// interactionKey is the description of an interaction with its provider
+// states.
+func interactionKey(obj map[string]any) string {
+	key, _ := obj["description"].(string)
+	var states []string
+	if state, ok := obj["providerState"].(string); ok && state != "" {
+		states = append(states, state)
+	}
+	list, _ := obj["providerStates"].([]any)
+	for _, s := range list {
+		s, _ := s.(map[string]any)
+		state, _ := s["name"].(string)
+		if params, ok := s["params"]; ok {
+			data, _ := json.Marshal(params)
+			state += " " + string(data)
+		}
+		states = append(states, state)
+	}
+	if len(states) > 0 {
+		key += " [given " + strings.Join(states, ", ") + "]"
+	}
+	return key
+}
+
+// content returns the body of a V4 body, which wraps it with its content
+// type, or body itself.
+func content(body any) any {
+	if obj, ok := body.(map[string]any); ok {
+		if c, ok := obj["content"]; ok {
+			if _, typed := obj["contentType"]; typed {
+				return c
+			}
+		}
+	}
+	return body
+}
+
+// lowerKeys returns headers with lowercase names, since header names are
+// case-insensitive.
+func lowerKeys(headers any) any {
+	obj, ok := headers.(map[string]any)
+	if !ok {
+		return headers
+	}
+	lower := make(map[string]any, len(obj))
+	for k, v := range obj {
+		lower[strings.ToLower(k)] = v
+	}
+	return lower
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactdiff
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// messagePact returns a V3 message pact with one interaction of the given
+// contents, metadata and body rules.
+func messagePact(description, contents, metadata, bodyRules string) []byte {
+	return []byte(`{
+		"consumer": {"name": "payments-consumer"},
+		"provider": {"name": "checkout-provider"},
+		"messages": [{
+			"description": "` + description + `",
+			"providerStates": [{"name": "an order was placed", "params": {"orderId": "order-1"}}],
+			"contents": ` + contents + `,
+			"metadata": ` + metadata + `,
+			"matchingRules": {"body": ` + bodyRules + `}
+		}],
+		"metadata": {"pactSpecification": {"version": "3.0.0"}}
+	}`)
+}
+
+const (
+	orderContents = `{"orderId": "order-1", "items": [{"productId": "SKU-1", "quantity": 2}], "currencyCode": "USD"}`
+	orderMetadata = `{"contentType": "application/json", "order.schema.version": "1"}`
+	orderRules    = `{
+		"$.orderId": {"matchers": [{"match": "type"}]},
+		"$.items": {"matchers": [{"match": "type", "min": 1}]},
+		"$.currencyCode": {"matchers": [{"match": "regex", "regex": "^[A-Z]{3}$"}]}
+	}`
+)
+
+func TestDiff(t *testing.T) {
+	before := messagePact("an order result", orderContents, orderMetadata, orderRules)
+
+	tests := []struct {
+		name string
+		new  []byte
+		// want is the changes, one per line
+		want         string
+		wantBreaking bool
+	}{
+		{
+			name: "no change",
+			new:  before,
+		},
+		{
+			name: "example changed under a matcher",
+			new: messagePact("an order result",
+				`{"orderId": "order-2", "items": [{"productId": "SKU-1", "quantity": 2}, {"productId": "SKU-2", "quantity": 1}], "currencyCode": "EUR"}`,
+				orderMetadata, orderRules),
+			want: `compatible an order result [given an order was placed {"orderId":"order-1"}]: body $.currencyCode: example changed from string "USD" to string "EUR"
+compatible an order result [given an order was placed {"orderId":"order-1"}]: body $.orderId: example changed from string "order-1" to string "order-2"`,
+		},
+		{
+			name: "field added and removed",
+			new: messagePact("an order result",
+				`{"orderId": "order-1", "items": [{"productId": "SKU-1", "quantity": 2}], "shippingCost": {"units": 8}}`,
+				orderMetadata, orderRules),
+			want: `compatible an order result [given an order was placed {"orderId":"order-1"}]: body $.currencyCode: removed, the consumer no longer reads it
+BREAKING an order result [given an order was placed {"orderId":"order-1"}]: body $.shippingCost: added, the consumer now expects an object`,
+			wantBreaking: true,
+		},
+		{
+			name: "value without matcher changed",
+			new: messagePact("an order result",
+				`{"orderId": "order-1", "items": [{"productId": "SKU-1", "quantity": 3}], "currencyCode": "USD"}`,
+				orderMetadata, orderRules),
+			// The items are matched by type, so the quantity is only an example
+			want: `compatible an order result [given an order was placed {"orderId":"order-1"}]: body $.items[0].quantity: example changed from number 2 to number 3`,
+		},
+		{
+			name: "matchers loosened and tightened",
+			new: messagePact("an order result", orderContents, orderMetadata, `{
+				"$.orderId": {"matchers": [{"match": "regex", "regex": "^order-[0-9]+$"}]},
+				"$.items": {"matchers": [{"match": "type"}]},
+				"$.currencyCode": {"matchers": [{"match": "type"}]}
+			}`),
+			want: `compatible an order result [given an order was placed {"orderId":"order-1"}]: body $.currencyCode: now matched by type instead of regex ^[A-Z]{3}$
+compatible an order result [given an order was placed {"orderId":"order-1"}]: body $.items: now matched by type instead of type (min 1)
+BREAKING an order result [given an order was placed {"orderId":"order-1"}]: body $.orderId: now matched by regex ^order-[0-9]+$ instead of type`,
+			wantBreaking: true,
+		},
+		{
+			name: "matcher removed",
+			new: messagePact("an order result", orderContents, orderMetadata, `{
+				"$.items": {"matchers": [{"match": "type", "min": 2}]},
+				"$.currencyCode": {"matchers": [{"match": "regex", "regex": "^[A-Z]{3}$"}]}
+			}`),
+			want: `BREAKING an order result [given an order was placed {"orderId":"order-1"}]: body $.items: now matched by type (min 2) instead of type (min 1)
+BREAKING an order result [given an order was placed {"orderId":"order-1"}]: body $.orderId: now matched by equality to the example instead of type`,
+			wantBreaking: true,
+		},
+		{
+			name: "metadata",
+			new:  messagePact("an order result", orderContents, `{"contentType": "application/json", "order.schema.version": "2", "event.type": "OrderResult"}`, orderRules),
+			want: `BREAKING an order result [given an order was placed {"orderId":"order-1"}]: metadata event.type: added, the consumer now expects string "OrderResult"
+BREAKING an order result [given an order was placed {"orderId":"order-1"}]: metadata order.schema.version: the consumer now expects string "2" instead of string "1"`,
+			wantBreaking: true,
+		},
+		{
+			name: "interaction replaced",
+			new:  messagePact("an order result in EUR", orderContents, orderMetadata, orderRules),
+			want: `compatible an order result [given an order was placed {"orderId":"order-1"}]: removed
+BREAKING an order result in EUR [given an order was placed {"orderId":"order-1"}]: added, the provider must now satisfy it`,
+			wantBreaking: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Diff(before, tt.new)
+			if err != nil {
+				t.Fatalf("Diff() = %v", err)
+			}
+			var got []string
+			for _, c := range report.Changes {
+				got = append(got, c.String())
+			}
+			if strings.Join(got, "\n") != tt.want {
+				t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), tt.want)
+			}
+			if report.Breaking() != tt.wantBreaking {
+				t.Errorf("Breaking() = %v, want %v", report.Breaking(), tt.wantBreaking)
+			}
+		})
+	}
+}
+
+func TestDiffHTTP(t *testing.T) {
+	// Pact specification 2, as the frontend records it
+	pact := func(path string, status int, body string) []byte {
+		return []byte(`{
+			"consumer": {"name": "Frontend"},
+			"provider": {"name": "checkout-provider"},
+			"interactions": [{
+				"description": "a request for an order",
+				"providerState": "an order exists",
+				"request": {"method": "GET", "path": "` + path + `", "headers": {"Accept": "application/json"}},
+				"response": {
+					"status": ` + strconv.Itoa(status) + `,
+					"headers": {"Content-Type": "application/json"},
+					"body": ` + body + `,
+					"matchingRules": {"$.body.total.units": {"match": "type"}, "$.headers.Content-Type": {"match": "regex", "regex": "application/json.*"}}
+				}
+			}],
+			"metadata": {"pactSpecification": {"version": "2.0.0"}}
+		}`)
+	}
+	before := pact("/orders/order-1", 200, `{"orderId": "order-1", "total": {"units": 10}}`)
+	after := pact("/v2/orders/order-1", 400, `{"orderId": "order-1", "total": {"units": 12}, "lines": [1, 2]}`)
+
+	report, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() = %v", err)
+	}
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `BREAKING a request for an order [given an order exists]: request: path changed, the provider must handle the new request
+BREAKING a request for an order [given an order exists]: response.status: the consumer now expects number 400 instead of number 200
+BREAKING a request for an order [given an order exists]: response.body $.lines: added, the consumer now expects an array
+compatible a request for an order [given an order exists]: response.body $.total.units: example changed from number 10 to number 12
+`
+	if out.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDiffRejectsOtherPacticipants(t *testing.T) {
+	before := messagePact("an order result", orderContents, orderMetadata, orderRules)
+	after := bytes.Replace(before, []byte("payments-consumer"), []byte("loyalty-consumer"), 1)
+	if _, err := Diff(before, after); err == nil || !strings.Contains(err.Error(), "different pacticipants") {
+		t.Errorf("Diff() = %v, want a different pacticipants error", err)
+	}
+	if _, err := Diff(before, []byte(`{"consumer": {}}`)); err == nil {
+		t.Error("Diff() of a pact without names = nil error, want an error")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/pactmatch"
+)
+
+// describeRule renders the matchers of r, or "equality to the example"
+// without any.
+func describeRule(r *pactmatch.Rule) string {
+	if r == nil || len(r.Matchers) == 0 {
+		return "equality to the example"
+	}
+	names := make([]string, len(r.Matchers))
+	for i, m := range r.Matchers {
+		names[i] = m.String()
+	}
+	return strings.Join(names, " "+strings.ToLower(r.Combine)+" ")
+}
+
+// parseRules parses the matchingRules of an interaction, a request or a
+// response by category: body, header, metadata, query or path. Pact
+// specification 2 keys every rule by a JSONPath, such as $.body.items or
+// $.headers.Accept, with a single matcher.
+func parseRules(v any) (map[string][]pactmatch.Rule, error) {
+	obj, _ := v.(map[string]any)
+	rules := map[string][]pactmatch.Rule{}
+	for category, value := range obj {
+		if strings.HasPrefix(category, "$") {
+			category, path := splitV2Path(category)
+			r, err := newRule(map[string]any{"matchers": []any{value}})
+			if err != nil {
+				return nil, err
+			}
+			if err := locate(&r, category, path); err != nil {
+				return nil, err
+			}
+			rules[category] = append(rules[category], r)
+			continue
+		}
+		if category == "headers" {
+			category = "header"
+		}
+		byPath, _ := value.(map[string]any)
+		for path, value := range byPath {
+			spec, _ := value.(map[string]any)
+			r, err := newRule(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", category, path, err)
+			}
+			if err := locate(&r, category, path); err != nil {
+				return nil, err
+			}
+			rules[category] = append(rules[category], r)
+		}
+	}
+	return rules, nil
+}
+
+// splitV2Path splits a V2 rule path, such as $.body.items[*], into its
+// category and its path within it, $.items[*].
+func splitV2Path(path string) (string, string) {
+	rest := strings.TrimPrefix(path, "$.")
+	category, rest, _ := strings.Cut(rest, ".")
+	if i := strings.IndexByte(category, '['); i >= 0 {
+		category, rest = category[:i], category[i:]
+	} else if rest != "" {
+		rest = "." + rest
+	}
+	switch category {
+	case "headers":
+		return "header", strings.TrimPrefix(rest, ".")
+	case "body":
+		return "body", "$" + rest
+	}
+	return category, strings.TrimPrefix(rest, ".")
+}
+
+// locate sets the path or key of r, a rule of category at path.
+func locate(r *pactmatch.Rule, category, path string) error {
+	if category == "body" {
+		var err error
+		r.Path, err = pactmatch.ParsePath(path)
+		return err
+	}
+	// Keys may be given as $.name or $['name']
+	key := strings.TrimPrefix(path, "$.")
+	if strings.HasPrefix(key, "$['") && strings.HasSuffix(key, "']") {
+		key = key[3 : len(key)-2]
+	}
+	if category == "header" {
+		key = strings.ToLower(key)
+	}
+	r.Key = key
+	return nil
+}
+
+func newRule(spec map[string]any) (pactmatch.Rule, error) {
+	r := pactmatch.Rule{Combine: "AND"}
+	if combine, ok := spec["combine"].(string); ok && combine != "" {
+		r.Combine = strings.ToUpper(combine)
+	}
+	list, _ := spec["matchers"].([]any)
+	for _, raw := range list {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return r, err
+		}
+		var m pactmatch.Matcher
+		if err := json.Unmarshal(data, &m); err != nil {
+			return r, fmt.Errorf("invalid matcher %s: %w", data, err)
+		}
+		r.Matchers = append(r.Matchers, m)
+	}
+	return r, nil
+}
+
+// relation is how a rule changed.
+type relation int
+
+const (
+	same relation = iota
+	// looser accepts everything the old rule did
+	looser
+	// stricter rejects something the old rule accepted
+	stricter
+	// changed may reject something the old rule accepted
+	changed
+)
+
+// compareRules returns how the rule of a path changed from before to after,
+// nil for none, which is equality to the example.
+func compareRules(before, after *pactmatch.Rule) relation {
+	switch {
+	case before == nil && after == nil:
+		return same
+	case before == nil:
+		return looser
+	case after == nil:
+		return stricter
+	}
+	if before.Combine == after.Combine && slices.EqualFunc(before.Matchers, after.Matchers, func(a, b pactmatch.Matcher) bool { return a.Raw == b.Raw }) {
+		return same
+	}
+	if len(before.Matchers) != 1 || len(after.Matchers) != 1 {
+		return changed
+	}
+	b, a := before.Matchers[0], after.Matchers[0]
+	if !a.Typed() {
+		return changed
+	}
+	if !b.Typed() {
+		if a.Min == nil && a.Max == nil {
+			// A type matcher accepts any value of the type of the example,
+			// which every matcher of the kind of the example accepts too
+			return looser
+		}
+		return changed
+	}
+	if looserBound(b.Min, a.Min, func(x, y int) bool { return x <= y }) && looserBound(b.Max, a.Max, func(x, y int) bool { return x >= y }) {
+		return looser
+	}
+	return stricter
+}
+
+// looserBound reports whether the bound after is no tighter than before, nil
+// being no bound, where loose(x, y) reports whether x is looser than y.
+func looserBound(before, after *int, loose func(x, y int) bool) bool {
+	switch {
+	case after == nil:
+		return true
+	case before == nil:
+		return false
+	}
+	return loose(*after, *before)
+}
+
+// keyRule returns the rule of the header or metadata key.
+func keyRule(rules []pactmatch.Rule, key string) *pactmatch.Rule {
+	for i := range rules {
+		if rules[i].Key == key {
+			return &rules[i]
+		}
+	}
+	return nil
+}
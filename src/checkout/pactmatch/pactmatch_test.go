@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactmatch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	for path, want := range map[string]string{
+		"$.items[*].cost.units": "$.items[-1].cost.units",
+		"$.items[0]":            "$.items[0]",
+		"$['shipping cost'].*":  "$.shipping cost.*",
+	} {
+		segments, err := ParsePath(path)
+		if err != nil || FormatPath(segments) != want {
+			t.Errorf("ParsePath(%q) = %s, %v; want %s", path, FormatPath(segments), err, want)
+		}
+	}
+	for _, path := range []string{"items", "$..items", "$.items[", "$.items[x]"} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("ParsePath(%q) = nil error, want an error", path)
+		}
+	}
+}
+
+func TestMatcherUnmarshal(t *testing.T) {
+	var m Matcher
+	if err := json.Unmarshal([]byte(`{"min": 1}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Match != "type" || m.Raw != `{"min": 1}` {
+		t.Errorf("early min matcher = %+v, want a type match keeping its raw JSON", m)
+	}
+	if err := json.Unmarshal([]byte(`{"match": "regex", "regex": "("}`), &m); err == nil {
+		t.Error("Unmarshal() of an invalid regex = nil error, want an error")
+	}
+}
+
+func TestRuleFor(t *testing.T) {
+	rule := func(path string, matchers ...Matcher) Rule {
+		segments, err := ParsePath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Rule{Path: segments, Combine: "AND", Matchers: matchers}
+	}
+	one := 1
+	rules := []Rule{
+		rule("$.items", Matcher{Match: "type", Min: &one}),
+		rule("$.items[*].currencyCode", Matcher{Match: "regex", Regex: "^[A-Z]{3}$"}),
+		rule("$.items[0].currencyCode", Matcher{Match: "equality"}),
+		rule("$.orderId", Matcher{Match: "regex", Regex: "^order-"}),
+	}
+	path := func(segments ...Segment) []Segment { return segments }
+
+	for _, tt := range []struct {
+		path []Segment
+		want string
+	}{
+		{path("items"), "type (min 1)"},
+		// The min of an array does not cascade to its elements
+		{path("items", 1, "units"), "type"},
+		{path("items", 1, "currencyCode"), "regex ^[A-Z]{3}$"},
+		{path("items", 0, "currencyCode"), "equality"},
+		{path("orderId"), "regex ^order-"},
+	} {
+		r := RuleFor(rules, tt.path)
+		if r == nil || r.Matchers[0].String() != tt.want {
+			t.Errorf("RuleFor(%s) = %v, want %s", FormatPath(tt.path), r, tt.want)
+		}
+	}
+	// Only type matchers cascade
+	if r := RuleFor(rules, path("orderId", "suffix")); r != nil {
+		t.Errorf("RuleFor($.orderId.suffix) = %v, want none", r)
+	}
+}
+
+func TestApply(t *testing.T) {
+	number := func(s string) any { return json.Number(s) }
+	for _, tt := range []struct {
+		matcher          Matcher
+		expected, actual any
+		want             string
+	}{
+		{Matcher{Match: "type"}, "a", "b", ""},
+		{Matcher{Match: "type"}, "a", number("1"), "got number 1, want a string"},
+		{Matcher{Match: "regex", Regex: "^[A-Z]{3}$"}, "USD", "usd", `got string "usd", want a match of ^[A-Z]{3}$`},
+		{Matcher{Match: "integer"}, number("1"), number("1.5"), "got number 1.5, want an integer"},
+		{Matcher{Match: "equality"}, number("2"), number("2.0"), ""},
+		{Matcher{Match: "notEmpty"}, []any{"a"}, []any{}, "got an array, want a non-empty array"},
+	} {
+		if got := tt.matcher.Apply(tt.expected, tt.actual); got != tt.want {
+			t.Errorf("%s.Apply(%v, %v) = %q, want %q", tt.matcher, tt.expected, tt.actual, got, tt.want)
+		}
+	}
+
+	or := Rule{Combine: "OR", Matchers: []Matcher{{Match: "null"}, {Match: "integer"}}}
+	if got := or.Apply(number("1"), nil); got != "" {
+		t.Errorf("OR rule on null = %q, want no problem", got)
+	}
+	if got := or.Apply(number("1"), "x"); got != `got string "x", want null, or got string "x", want an integer` {
+		t.Errorf("OR rule on a string = %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pactmatch implements the parts of pact matching that the pact tools
+// of the checkout share: the JSONPaths of matching rules, the matchers, which
+// rule applies at a path of a body, and the comparison and description of
+// JSON values decoded with json.Number numbers. cmd/consumer-sim checks
+// messages against them, and pactdiff compares two versions of them.
+package pactmatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Segment is a step of a JSONPath: a field name, "*" for any field, an array
+// index, or AnyIndex.
+type Segment any
+
+// AnyIndex is the [*] segment.
+const AnyIndex = -1
+
+// ParsePath parses the JSONPath of a matching rule, such as
+// $.items[*].cost.units or $['shipping cost'].
+func ParsePath(path string) ([]Segment, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+	var segments []Segment
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			segments = append(segments, rest[1:end])
+			rest = rest[end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			inner := rest[1:end]
+			switch {
+			case inner == "*":
+				segments = append(segments, AnyIndex)
+			case len(inner) >= 2 && inner[0] == '\'' && inner[len(inner)-1] == '\'':
+				segments = append(segments, inner[1:len(inner)-1])
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid path %q", path)
+				}
+				segments = append(segments, i)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// PathMatches reports whether path, a path of a body, is matched by pattern,
+// a path of a rule of the same length.
+func PathMatches(pattern, path []Segment) bool {
+	for i, s := range pattern {
+		switch s {
+		case "*":
+			if _, ok := path[i].(string); !ok {
+				return false
+			}
+		case AnyIndex:
+			if _, ok := path[i].(int); !ok {
+				return false
+			}
+		default:
+			if s != path[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CountWildcards returns the number of "*" and AnyIndex segments of path.
+func CountWildcards(path []Segment) int {
+	n := 0
+	for _, s := range path {
+		if s == "*" || s == AnyIndex {
+			n++
+		}
+	}
+	return n
+}
+
+// FormatPath renders path the way the rules of pact files spell it.
+func FormatPath(path []Segment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range path {
+		switch s := s.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+		case string:
+			b.WriteString("." + s)
+		}
+	}
+	return b.String()
+}
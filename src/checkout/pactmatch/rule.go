@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Rule is the matching rule of a body path or of a header or metadata key.
+type Rule struct {
+	// Path is the parsed JSONPath of a body rule
+	Path []Segment `json:"-"`
+	// Key is the header or metadata key of a keyed rule
+	Key string `json:"-"`
+	// Combine is AND or OR, how the matchers combine
+	Combine  string    `json:"combine"`
+	Matchers []Matcher `json:"matchers"`
+}
+
+// Matcher is a single pact matcher, such as {"match": "regex", "regex": "^[A-Z]{3}$"}.
+type Matcher struct {
+	Match string          `json:"match"`
+	Min   *int            `json:"min,omitempty"`
+	Max   *int            `json:"max,omitempty"`
+	Regex string          `json:"regex,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+	// Raw is the whole matcher, to tell apart matchers with other settings
+	Raw string `json:"-"`
+
+	regex *regexp.Regexp
+}
+
+// supportedMatchers are the matchers Apply evaluates.
+var supportedMatchers = map[string]bool{
+	"type": true, "min": true, "max": true, "regex": true, "integer": true, "decimal": true,
+	"number": true, "boolean": true, "equality": true, "include": true, "null": true, "notEmpty": true,
+}
+
+// UnmarshalJSON decodes a matcher and compiles its regex. Early pacts give
+// min and max without a match, which is a type match.
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	type plain Matcher
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*m = Matcher(p)
+	if m.Match == "" && (m.Min != nil || m.Max != nil) {
+		m.Match = "type"
+	}
+	m.Raw = string(data)
+	if m.Match == "regex" {
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return err
+		}
+		m.regex = re
+	}
+	return nil
+}
+
+func (m Matcher) String() string {
+	switch {
+	case m.Match == "regex":
+		return "regex " + m.Regex
+	case m.Min != nil && m.Max != nil:
+		return fmt.Sprintf("%s (min %d, max %d)", m.Match, *m.Min, *m.Max)
+	case m.Min != nil:
+		return fmt.Sprintf("%s (min %d)", m.Match, *m.Min)
+	case m.Max != nil:
+		return fmt.Sprintf("%s (max %d)", m.Match, *m.Max)
+	}
+	return m.Match
+}
+
+// Supported reports whether Apply evaluates m.
+func (m Matcher) Supported() bool {
+	return supportedMatchers[m.Match]
+}
+
+// Typed reports whether m matches by type.
+func (m Matcher) Typed() bool {
+	return m.Match == "type" || m.Match == "min" || m.Match == "max"
+}
+
+// Or reports whether a value must pass any of the matchers of r, rather
+// than all of them.
+func (r Rule) Or() bool {
+	return strings.EqualFold(r.Combine, "OR")
+}
+
+// Typed reports whether r matches by type, so that arrays are matched
+// element by element against the first element of the example.
+func (r Rule) Typed() bool {
+	return slices.ContainsFunc(r.Matchers, Matcher.Typed)
+}
+
+// RuleFor returns the body rule of rules that applies at path, nil for none:
+// the most specific rule of path itself, else the type matchers of the
+// closest rule of a parent, as pact cascades them to the fields below.
+func RuleFor(rules []Rule, path []Segment) *Rule {
+	var best *Rule
+	bestLen, bestWildcards := -1, 0
+	for _, r := range rules {
+		if len(r.Path) > len(path) || !PathMatches(r.Path, path[:len(r.Path)]) {
+			continue
+		}
+		candidate := r
+		if len(r.Path) < len(path) {
+			if !r.Typed() {
+				continue
+			}
+			// The bounds of an array do not apply to its elements
+			candidate = Rule{Path: r.Path, Combine: "AND", Matchers: []Matcher{{Match: "type", Raw: `{"match":"type"}`}}}
+		}
+		wildcards := CountWildcards(r.Path)
+		if len(r.Path) > bestLen || len(r.Path) == bestLen && wildcards < bestWildcards {
+			best, bestLen, bestWildcards = &candidate, len(r.Path), wildcards
+		}
+	}
+	return best
+}
+
+// Apply returns why actual fails the matchers of r, or "".
+func (r Rule) Apply(expected, actual any) string {
+	var problems []string
+	for _, m := range r.Matchers {
+		problem := m.Apply(expected, actual)
+		if problem == "" && r.Or() {
+			return ""
+		}
+		if problem != "" {
+			problems = append(problems, problem)
+			if !r.Or() {
+				break
+			}
+		}
+	}
+	return strings.Join(problems, ", or ")
+}
+
+// Apply returns why actual fails m, or "", where expected is the example.
+func (m Matcher) Apply(expected, actual any) string {
+	switch m.Match {
+	case "type", "min", "max":
+		if Kind(actual) != Kind(expected) {
+			return fmt.Sprintf("got %s, want %s", Describe(actual), article(Kind(expected)))
+		}
+		if n, ok := length(actual); ok {
+			if m.Min != nil && n < *m.Min {
+				return fmt.Sprintf("got %d elements, want at least %d", n, *m.Min)
+			}
+			if m.Max != nil && n > *m.Max {
+				return fmt.Sprintf("got %d elements, want at most %d", n, *m.Max)
+			}
+		}
+	case "regex":
+		re := m.regex
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(m.Regex); err != nil {
+				return fmt.Sprintf("invalid regex %s: %v", m.Regex, err)
+			}
+		}
+		s, ok := ScalarString(actual)
+		if !ok || !re.MatchString(s) {
+			return fmt.Sprintf("got %s, want a match of %s", Describe(actual), m.Regex)
+		}
+	case "integer":
+		if n, ok := actual.(json.Number); !ok || strings.ContainsAny(n.String(), ".eE") {
+			return fmt.Sprintf("got %s, want an integer", Describe(actual))
+		}
+	case "decimal":
+		if n, ok := actual.(json.Number); !ok || !strings.Contains(n.String(), ".") {
+			return fmt.Sprintf("got %s, want a decimal", Describe(actual))
+		}
+	case "number":
+		if _, ok := actual.(json.Number); !ok {
+			return fmt.Sprintf("got %s, want a number", Describe(actual))
+		}
+	case "boolean":
+		if _, ok := actual.(bool); !ok {
+			return fmt.Sprintf("got %s, want a boolean", Describe(actual))
+		}
+	case "equality":
+		if !Equal(expected, actual) {
+			return fmt.Sprintf("got %s, want %s", Describe(actual), Describe(expected))
+		}
+	case "include":
+		var want string
+		if err := json.Unmarshal(m.Value, &want); err != nil {
+			want = string(m.Value)
+		}
+		if s, ok := ScalarString(actual); !ok || !strings.Contains(s, want) {
+			return fmt.Sprintf("got %s, want it to include %q", Describe(actual), want)
+		}
+	case "null":
+		if actual != nil {
+			return fmt.Sprintf("got %s, want null", Describe(actual))
+		}
+	case "notEmpty":
+		if n, ok := length(actual); actual == nil || ok && n == 0 || actual == "" || Kind(actual) != Kind(expected) {
+			return fmt.Sprintf("got %s, want a non-empty %s", Describe(actual), Kind(expected))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactmatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DecodeJSON decodes data keeping numbers as json.Number, so that integers
+// and decimals can be told apart. Empty data decodes to nil.
+func DecodeJSON(data []byte) (any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// Equal compares two JSON values, numbers by value.
+func Equal(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := strconv.ParseFloat(an.String(), 64)
+		bf, berr := strconv.ParseFloat(bn.String(), 64)
+		return aerr == nil && berr == nil && af == bf
+	}
+	aj, _ := json.Marshal(a)
+	bj, _ := json.Marshal(b)
+	return string(aj) == string(bj)
+}
+
+// Kind returns the JSON type of v, such as "number" or "object".
+func Kind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Describe renders a value in a message, with its JSON type, such as
+// number 2 or an object.
+func Describe(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case []any, map[string]any:
+		return article(Kind(v))
+	case string:
+		return fmt.Sprintf("string %q", v)
+	}
+	if s, ok := ScalarString(v); ok {
+		return Kind(v) + " " + s
+	}
+	return fmt.Sprintf("%s %v", Kind(v), v)
+}
+
+// ScalarString returns a string, number or boolean as text.
+func ScalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func article(kind string) string {
+	if strings.IndexByte("aeiou", kind[0]) >= 0 {
+		return "an " + kind
+	}
+	return "a " + kind
+}
+
+// length returns the number of elements of an array or an object.
+func length(v any) (int, bool) {
+	switch v := v.(type) {
+	case []any:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	}
+	return 0, false
+}
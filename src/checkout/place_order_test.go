@@ -0,0 +1,913 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports/mocks"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// Fakes for the downstream gRPC services PlaceOrder calls. Embedding the
+// client interface leaves every other method unimplemented.
+type fakeCartClient struct{ pb.CartServiceClient }
+
+func (fakeCartClient) GetCart(context.Context, *pb.GetCartRequest, ...grpc.CallOption) (*pb.Cart, error) {
+	return &pb.Cart{Items: []*pb.CartItem{{ProductId: "OLJCESPC7Z", Quantity: 2}}}, nil
+}
+
+func (fakeCartClient) EmptyCart(context.Context, *pb.EmptyCartRequest, ...grpc.CallOption) (*pb.Empty, error) {
+	return &pb.Empty{}, nil
+}
+
+// emptyCartClient serves a cart without items.
+type emptyCartClient struct{ fakeCartClient }
+
+func (emptyCartClient) GetCart(context.Context, *pb.GetCartRequest, ...grpc.CallOption) (*pb.Cart, error) {
+	return &pb.Cart{}, nil
+}
+
+type fakeProductCatalogClient struct{ pb.ProductCatalogServiceClient }
+
+func (fakeProductCatalogClient) GetProduct(_ context.Context, req *pb.GetProductRequest, _ ...grpc.CallOption) (*pb.Product, error) {
+	return &pb.Product{Id: req.Id, PriceUsd: &pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 990000000}}, nil
+}
+
+// fakeCurrencyClient converts USD to EUR at 0.9 and other currencies one to
+// one.
+type fakeCurrencyClient struct{ pb.CurrencyServiceClient }
+
+func (fakeCurrencyClient) Convert(_ context.Context, req *pb.CurrencyConversionRequest, _ ...grpc.CallOption) (*pb.Money, error) {
+	if req.From.CurrencyCode == "USD" && req.ToCode == "EUR" {
+		nanos := (req.From.Units*1_000_000_000 + int64(req.From.Nanos)) * 9 / 10
+		return &pb.Money{CurrencyCode: req.ToCode, Units: nanos / 1_000_000_000, Nanos: int32(nanos % 1_000_000_000)}, nil
+	}
+	return &pb.Money{CurrencyCode: req.ToCode, Units: req.From.Units, Nanos: req.From.Nanos}, nil
+}
+
+type fakePaymentClient struct {
+	pb.PaymentServiceClient
+	charges atomic.Int32
+	err     error
+}
+
+func (f *fakePaymentClient) Charge(context.Context, *pb.ChargeRequest, ...grpc.CallOption) (*pb.ChargeResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.charges.Add(1)
+	return &pb.ChargeResponse{TransactionId: "tx-1"}, nil
+}
+
+// fakeOrderCompensator records refunds and failed orders.
+type fakeOrderCompensator struct {
+	mu        sync.Mutex
+	refunds   []ports.FailedOrder
+	failed    []ports.FailedOrder
+	refundErr error
+}
+
+func (f *fakeOrderCompensator) RefundPayment(_ context.Context, order ports.FailedOrder) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refunds = append(f.refunds, order)
+	return f.refundErr
+}
+
+func (f *fakeOrderCompensator) PublishOrderFailed(_ context.Context, order ports.FailedOrder) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed = append(f.failed, order)
+	return nil
+}
+
+// newMockPublisher returns an order event publisher mock without
+// expectations, which fails the test on any publish until the test sets some.
+func newMockPublisher(t *testing.T) *mocks.MockOrderEventPublisher {
+	return mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+}
+
+// newAcceptingPublisher returns an order event publisher mock that accepts any
+// number of orders, for tests that do not check what is published.
+func newAcceptingPublisher(t *testing.T) *mocks.MockOrderEventPublisher {
+	publisher := newMockPublisher(t)
+	publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any()).AnyTimes()
+	return publisher
+}
+
+// newTestCheckout returns a checkout whose downstream services are fakes. The
+// shipping and email services are served over HTTP.
+func newTestCheckout(t *testing.T, publisher ports.OrderEventPublisher, payment *fakePaymentClient) *checkout {
+	t.Helper()
+	logger = slog.New(slog.DiscardHandler)
+	tracer = otel.Tracer("checkout-test")
+
+	addr := newTestHTTPServices(t, false)
+	return &checkout{
+		emailService:            adapters.NewHTTPEmailService(addr, nil),
+		confirmations:           adapters.NewTemplateOrderConfirmationRenderer(),
+		orderEventPublisher:     publisher,
+		idempotencyStore:        adapters.NewInMemoryIdempotencyStore(time.Hour),
+		orderCompensator:        &fakeOrderCompensator{},
+		orderRepository:         adapters.NewInMemoryOrderRepository(10),
+		shippingProviders:       newTestShippingProviders(addr),
+		inventory:               adapters.NewInMemoryInventoryReserver(nil),
+		cartSvcClient:           fakeCartClient{},
+		productCatalogSvcClient: fakeProductCatalogClient{},
+		paymentSvcClient:        payment,
+		payments:                adapters.NewGRPCPaymentService(payment),
+		currency:                adapters.NewCachingCurrencyConverter(fakeCurrencyClient{}, time.Minute, time.Hour, logger),
+	}
+}
+
+// newTestHTTPServices serves the shipping and email endpoints and returns their
+// address. With failShipping, quotes succeed but shipping an order fails.
+func newTestHTTPServices(t *testing.T, failShipping bool) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/get-quote", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"cost_usd": &pb.Money{CurrencyCode: "USD", Units: 8}})
+	})
+	mux.HandleFunc("/ship-order", func(w http.ResponseWriter, r *http.Request) {
+		if failShipping {
+			http.Error(w, "shipping unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"tracking_id": "TRACK-1"})
+	})
+	mux.HandleFunc("/send_order_confirmation", func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// newTestShippingProviders ships through the shipping service at addr with
+// the standard and express methods.
+func newTestShippingProviders(addr string) *adapters.InMemoryShippingProviderRegistry {
+	return wiring.ShippingProviders(config.Services{Shipping: addr}, config.Carrier{})
+}
+
+func testPlaceOrderRequest() *pb.PlaceOrderRequest {
+	return &pb.PlaceOrderRequest{
+		UserId:       "user-1",
+		UserCurrency: "USD",
+		Email:        "someone@example.com",
+		Address:      &pb.Address{StreetAddress: "1600 Amphitheatre Parkway", City: "Mountain View", Country: "US", ZipCode: "94043"},
+		CreditCard:   &pb.CreditCardInfo{CreditCardNumber: "4432-8015-6152-0454", CreditCardExpirationYear: 2039, CreditCardExpirationMonth: 1, CreditCardCvv: 672},
+	}
+}
+
+func withIdempotencyKey(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotencyKeyHeader, key))
+}
+
+func TestPlaceOrderIsIdempotent(t *testing.T) {
+	publisher := newMockPublisher(t)
+	payment := &fakePaymentClient{}
+	svc := newTestCheckout(t, publisher, payment)
+
+	// Message contract: exactly one order event for the order
+	publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any())
+
+	first, err := svc.PlaceOrder(withIdempotencyKey("req-1"), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	retried, err := svc.PlaceOrder(withIdempotencyKey("req-1"), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("retried PlaceOrder() = %v", err)
+	}
+
+	// gRPC contract: the retry returns the original order
+	if !proto.Equal(retried.Order, first.Order) {
+		t.Errorf("retried PlaceOrder() = %v, want the original order %v", retried.Order, first.Order)
+	}
+	if got := payment.charges.Load(); got != 1 {
+		t.Errorf("card charged %d times, want 1", got)
+	}
+
+	// A different request ID is a new order
+	publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any())
+	other, err := svc.PlaceOrder(withIdempotencyKey("req-2"), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() with a new key = %v", err)
+	}
+	if other.Order.OrderId == first.Order.OrderId {
+		t.Error("PlaceOrder() with a new key returned the original order")
+	}
+}
+
+func TestIdempotencyKeyIsUnambiguous(t *testing.T) {
+	// Without the length prefix both would be "a/b/c"
+	if a, b := idempotencyKey("a/b", "c"), idempotencyKey("a", "b/c"); a == b {
+		t.Errorf("idempotencyKey() = %q for two different users", a)
+	}
+}
+
+func TestPlaceOrderWithoutIdempotencyKey(t *testing.T) {
+	payment := &fakePaymentClient{}
+	svc := newTestCheckout(t, newAcceptingPublisher(t), payment)
+
+	for range 2 {
+		if _, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest()); err != nil {
+			t.Fatalf("PlaceOrder() = %v", err)
+		}
+	}
+	if got := payment.charges.Load(); got != 2 {
+		t.Errorf("card charged %d times, want 2 without an idempotency key", got)
+	}
+}
+
+func TestPlaceOrderCompensatesFailures(t *testing.T) {
+	errRefund := errors.New("refund rejected")
+	tests := []struct {
+		name         string
+		chargeErr    error
+		failShipping bool
+		refundErr    error
+		wantCode     codes.Code
+		wantRefunds  int
+		wantStep     string
+		wantUndone   bool
+	}{
+		{
+			name:       "payment fails",
+			chargeErr:  errors.New("card declined"),
+			wantCode:   codes.Internal,
+			wantStep:   "charge",
+			wantUndone: true,
+		},
+		{
+			name:         "shipping fails after payment",
+			failShipping: true,
+			wantCode:     codes.Unavailable,
+			wantRefunds:  1,
+			wantStep:     "ship",
+			wantUndone:   true,
+		},
+		{
+			name:         "refund fails",
+			failShipping: true,
+			refundErr:    errRefund,
+			wantCode:     codes.Unavailable,
+			wantRefunds:  1,
+			wantStep:     "ship",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No order completed event is expected for a failed order
+			svc := newTestCheckout(t, newMockPublisher(t), &fakePaymentClient{err: tt.chargeErr})
+			svc.shippingProviders = newTestShippingProviders(newTestHTTPServices(t, tt.failShipping))
+			compensator := &fakeOrderCompensator{refundErr: tt.refundErr}
+			svc.orderCompensator = compensator
+
+			_, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest())
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("PlaceOrder() code = %v, want %v (err %v)", got, tt.wantCode, err)
+			}
+
+			if len(compensator.refunds) != tt.wantRefunds {
+				t.Fatalf("refunded %d times, want %d", len(compensator.refunds), tt.wantRefunds)
+			}
+			if tt.wantRefunds > 0 && compensator.refunds[0].TransactionID != "tx-1" {
+				t.Errorf("refunded transaction %q, want tx-1", compensator.refunds[0].TransactionID)
+			}
+			if len(compensator.failed) != 1 {
+				t.Fatalf("published %d OrderFailed events, want 1", len(compensator.failed))
+			}
+			failed := compensator.failed[0]
+			if failed.Step != tt.wantStep || failed.Compensated != tt.wantUndone {
+				t.Errorf("OrderFailed = step %q compensated %v, want step %q compensated %v",
+					failed.Step, failed.Compensated, tt.wantStep, tt.wantUndone)
+			}
+		})
+	}
+}
+
+// recordingBatchPublisher records the batches relayed by an order event outbox.
+type recordingBatchPublisher struct {
+	mu      sync.Mutex
+	batches [][]ports.OrderEvent
+	headers []map[string]string
+}
+
+func (r *recordingBatchPublisher) PublishOrderEvents(ctx context.Context, events []ports.OrderEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, events)
+	r.headers = append(r.headers, adapters.MessageHeaders(ctx))
+	return nil
+}
+
+func TestPlaceOrderEmitsOrderEventsAtomically(t *testing.T) {
+	tests := []struct {
+		name         string
+		failShipping bool
+		wantTypes    []ports.OrderEventType
+	}{
+		{
+			name:      "order completes",
+			wantTypes: []ports.OrderEventType{ports.OrderPlacedEvent, ports.PaymentCapturedEvent, ports.OrderCompletedEvent, ports.LoyaltyPointsEarnedEvent},
+		},
+		{
+			// The events recorded before the failure are discarded
+			name:         "shipping fails after payment",
+			failShipping: true,
+			wantTypes:    []ports.OrderEventType{ports.OrderFailedEvent},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Orders go through the outbox, never straight to the publisher
+			svc := newTestCheckout(t, newMockPublisher(t), &fakePaymentClient{})
+			svc.shippingProviders = newTestShippingProviders(newTestHTTPServices(t, tt.failShipping))
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			resp, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest())
+			if (err != nil) != tt.failShipping {
+				t.Fatalf("PlaceOrder() = %v", err)
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+
+			if len(batches.batches) != 1 {
+				t.Fatalf("published %d batches, want 1", len(batches.batches))
+			}
+			var types []ports.OrderEventType
+			for _, event := range batches.batches[0] {
+				types = append(types, event.Type)
+				if event.Order.GetOrderId() == "" || (resp != nil && event.Order.GetOrderId() != resp.Order.OrderId) {
+					t.Errorf("%s event for order %q, want %q", event.Type, event.Order.GetOrderId(), resp.GetOrder().GetOrderId())
+				}
+			}
+			if !slices.Equal(types, tt.wantTypes) {
+				t.Errorf("published %v, want %v", types, tt.wantTypes)
+			}
+			if tt.failShipping {
+				failed := batches.batches[0][0].Attributes
+				if failed["failed_step"] != "ship" || failed["compensated"] != "true" || failed["user_id"] != "user-1" {
+					t.Errorf("OrderFailed attributes = %v, want step ship, compensated, for user-1", failed)
+				}
+				return
+			}
+			if got := batches.batches[0][1].Attributes["transaction_id"]; got != "tx-1" {
+				t.Errorf("PaymentCaptured transaction_id = %q, want tx-1", got)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderReservesInventory(t *testing.T) {
+	tests := []struct {
+		name         string
+		stock        int64
+		failShipping bool
+		wantCode     codes.Code
+		wantStock    int64
+		wantCharges  int32
+	}{
+		{name: "order completes", stock: 5, wantStock: 3, wantCharges: 1},
+		{name: "shipping fails after payment", stock: 5, failShipping: true, wantCode: codes.Unavailable, wantStock: 5, wantCharges: 1},
+		{name: "out of stock", stock: 1, wantCode: codes.FailedPrecondition, wantStock: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &fakePaymentClient{}
+			svc := newTestCheckout(t, newAcceptingPublisher(t), payment)
+			svc.shippingProviders = newTestShippingProviders(newTestHTTPServices(t, tt.failShipping))
+			inventory := adapters.NewInMemoryInventoryReserver(map[string]int64{"OLJCESPC7Z": tt.stock})
+			svc.inventory = inventory
+			compensator := &fakeOrderCompensator{}
+			svc.orderCompensator = compensator
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			_, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest())
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("PlaceOrder() code = %v, want %v (err %v)", got, tt.wantCode, err)
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			if got, _ := inventory.Stock("OLJCESPC7Z"); got != tt.wantStock {
+				t.Errorf("stock = %d, want %d", got, tt.wantStock)
+			}
+			if got := payment.charges.Load(); got != tt.wantCharges {
+				t.Errorf("charged %d times, want %d", got, tt.wantCharges)
+			}
+			if tt.wantCode != codes.FailedPrecondition {
+				return
+			}
+
+			if len(compensator.failed) != 1 || compensator.failed[0].Step != reserveStep ||
+				!slices.Equal(compensator.failed[0].OutOfStock, []string{"OLJCESPC7Z"}) {
+				t.Fatalf("OrderFailed = %+v, want step %s with OLJCESPC7Z out of stock", compensator.failed, reserveStep)
+			}
+			// OutOfStock comes first, then OrderFailed
+			if len(batches.batches) != 2 || len(batches.batches[0]) != 1 || batches.batches[1][0].Type != ports.OrderFailedEvent {
+				t.Fatalf("published %v, want one OutOfStock event and then OrderFailed", batches.batches)
+			}
+			event := batches.batches[0][0]
+			if event.Type != ports.OutOfStockEvent || event.Attributes["product_ids"] != "OLJCESPC7Z" {
+				t.Errorf("published %s with %v, want OutOfStock with product_ids=OLJCESPC7Z", event.Type, event.Attributes)
+			}
+			if len(event.Order.GetItems()) != 1 || event.Order.GetItems()[0].GetItem().GetProductId() != "OLJCESPC7Z" {
+				t.Errorf("OutOfStock items = %v, want the OLJCESPC7Z item", event.Order.GetItems())
+			}
+		})
+	}
+}
+
+func TestPlaceOrderShippingMethods(t *testing.T) {
+	tests := []struct {
+		method   string
+		wantCode codes.Code
+		wantCost int64
+	}{
+		{method: "", wantCode: codes.OK, wantCost: 8},
+		{method: adapters.ShippingMethodStandard, wantCode: codes.OK, wantCost: 8},
+		{method: adapters.ShippingMethodExpress, wantCode: codes.OK, wantCost: 18},
+		{method: "teleport", wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			ctx := context.Background()
+			if tt.method != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(shippingMethodHeader, tt.method))
+			}
+
+			resp, err := svc.PlaceOrder(ctx, testPlaceOrderRequest())
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("PlaceOrder() code = %v, want %v (err %v)", got, tt.wantCode, err)
+			}
+			if err == nil && resp.Order.ShippingCost.GetUnits() != tt.wantCost {
+				t.Errorf("shipping cost = %v, want %d USD", resp.Order.ShippingCost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderRejectsInvalidOrders(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(svc *checkout, req *pb.PlaceOrderRequest)
+		wantField string
+	}{
+		{
+			name:      "incomplete address",
+			mutate:    func(svc *checkout, req *pb.PlaceOrderRequest) { req.Address.City = "" },
+			wantField: "address.city",
+		},
+		{
+			name:      "empty cart",
+			mutate:    func(svc *checkout, req *pb.PlaceOrderRequest) { svc.cartSvcClient = emptyCartClient{} },
+			wantField: "items",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &fakePaymentClient{}
+			svc := newTestCheckout(t, newMockPublisher(t), payment)
+			compensator := svc.orderCompensator.(*fakeOrderCompensator)
+			req := testPlaceOrderRequest()
+			tt.mutate(svc, req)
+
+			_, err := svc.PlaceOrder(context.Background(), req)
+			st := status.Convert(err)
+			if st.Code() != codes.InvalidArgument {
+				t.Fatalf("PlaceOrder() = %v, want %v", err, codes.InvalidArgument)
+			}
+			if violations := validation.ViolationsOf(st); len(violations) != 1 || violations[0].Field != tt.wantField {
+				t.Errorf("violations = %+v, want %s", violations, tt.wantField)
+			}
+			if got := payment.charges.Load(); got != 0 {
+				t.Errorf("charged %d times, want 0", got)
+			}
+			if len(compensator.failed) != 1 || compensator.failed[0].Step != validateStep || compensator.failed[0].Reason == "" {
+				t.Errorf("failed orders = %+v, want one failed at %s", compensator.failed, validateStep)
+			}
+		})
+	}
+}
+
+func withAsyncMode() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(placeOrderModeHeader, "async"))
+}
+
+// waitForOrder waits until the pending order with id is no longer pending.
+func waitForOrder(t *testing.T, store ports.PendingOrderStore, id string) ports.PendingOrder {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		order, err := store.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", id, err)
+		}
+		if order.Status != ports.OrderPending {
+			return order
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("order %s still pending", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPlaceOrderAsync(t *testing.T) {
+	// The store orders the publish before the completion waitForOrder sees
+	var published *pb.OrderResult
+	publisher := newMockPublisher(t)
+	publisher.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, order *pb.OrderResult) { published = order })
+	svc := newTestCheckout(t, publisher, &fakePaymentClient{})
+	store := adapters.NewInMemoryPendingOrderStore(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc.startOrderWorkers(ctx, store, 2)
+
+	resp, err := svc.PlaceOrder(withAsyncMode(), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	orderID := resp.Order.OrderId
+	if orderID == "" || resp.Order.ShippingTrackingId != "" {
+		t.Fatalf("PlaceOrder() = %v, want only the order ID", resp.Order)
+	}
+
+	order := waitForOrder(t, store, orderID)
+	if order.Status != ports.OrderCompleted || order.Result.ShippingTrackingId != "TRACK-1" {
+		t.Fatalf("order = %+v, want it completed and shipped", order)
+	}
+	// The final OrderResult is published under the ID returned to the client
+	if !proto.Equal(published, order.Result) || published.GetOrderId() != orderID {
+		t.Errorf("published %v, want the completed order %s", published, orderID)
+	}
+}
+
+func TestPlaceOrderAsyncRejectsInvalidRequests(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	store := adapters.NewInMemoryPendingOrderStore(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc.startOrderWorkers(ctx, store, 1)
+
+	req := testPlaceOrderRequest()
+	req.CreditCard = nil
+	if _, err := svc.PlaceOrder(withAsyncMode(), req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("PlaceOrder() = %v, want %v", err, codes.InvalidArgument)
+	}
+	if pending, _ := store.ListPending(context.Background()); len(pending) != 0 {
+		t.Errorf("saved %d pending orders for an invalid request, want 0", len(pending))
+	}
+}
+
+func TestStopOrderWorkers(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	store := adapters.NewInMemoryPendingOrderStore(time.Hour)
+	svc.startOrderWorkers(context.Background(), store, 2)
+
+	resp, err := svc.PlaceOrder(withAsyncMode(), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	waitForOrder(t, store, resp.Order.OrderId)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := svc.stopOrderWorkers(ctx); err != nil {
+		t.Fatalf("stopOrderWorkers() = %v", err)
+	}
+
+	// Orders accepted after the workers stopped stay pending for the next start
+	resp, err = svc.PlaceOrder(withAsyncMode(), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if order, _ := store.Get(context.Background(), resp.Order.OrderId); order.Status != ports.OrderPending {
+		t.Errorf("order status = %v after the workers stopped, want pending", order.Status)
+	}
+}
+
+func TestPlaceOrderAsyncRecordsFailures(t *testing.T) {
+	// No order event is expected for a failed order
+	svc := newTestCheckout(t, newMockPublisher(t), &fakePaymentClient{})
+	svc.shippingProviders = newTestShippingProviders(newTestHTTPServices(t, true))
+	store := adapters.NewInMemoryPendingOrderStore(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc.startOrderWorkers(ctx, store, 1)
+
+	resp, err := svc.PlaceOrder(withAsyncMode(), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v, want the order accepted", err)
+	}
+	if order := waitForOrder(t, store, resp.Order.OrderId); order.Status != ports.OrderFailed || order.Reason == "" {
+		t.Errorf("order = %+v, want it failed with a reason", order)
+	}
+}
+
+func TestGetAndListOrders(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	ctx := context.Background()
+
+	var placed []*pb.OrderResult
+	for range 3 {
+		resp, err := svc.PlaceOrder(ctx, testPlaceOrderRequest())
+		if err != nil {
+			t.Fatalf("PlaceOrder() = %v", err)
+		}
+		placed = append(placed, resp.Order)
+	}
+
+	got, err := svc.GetOrder(ctx, &pb.GetOrderRequest{UserId: "user-1", OrderId: placed[0].OrderId})
+	if err != nil || !proto.Equal(got.Order, placed[0]) {
+		t.Fatalf("GetOrder() = %v, %v; want the placed order", got, err)
+	}
+	if _, err := svc.GetOrder(ctx, &pb.GetOrderRequest{UserId: "user-2", OrderId: placed[0].OrderId}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetOrder() of another user's order = %v, want %v", err, codes.NotFound)
+	}
+	if _, err := svc.GetOrder(ctx, &pb.GetOrderRequest{UserId: "user-1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetOrder() without an order ID = %v, want %v", err, codes.InvalidArgument)
+	}
+
+	first, err := svc.ListOrders(ctx, &pb.ListOrdersRequest{UserId: "user-1", PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListOrders() = %v", err)
+	}
+	second, err := svc.ListOrders(ctx, &pb.ListOrdersRequest{UserId: "user-1", PageSize: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatalf("ListOrders() of the second page = %v", err)
+	}
+	listed := append(first.Orders, second.Orders...)
+	if len(listed) != 3 || second.NextPageToken != "" {
+		t.Fatalf("ListOrders() returned %d orders, want all 3 on two pages", len(listed))
+	}
+	for i, order := range listed {
+		if want := placed[len(placed)-1-i]; !proto.Equal(order, want) {
+			t.Errorf("ListOrders()[%d] = %s, want %s (newest first)", i, order.OrderId, want.OrderId)
+		}
+	}
+	if _, err := svc.ListOrders(ctx, &pb.ListOrdersRequest{UserId: "user-1", PageToken: "bogus"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListOrders() with a bogus token = %v, want %v", err, codes.InvalidArgument)
+	}
+}
+
+func TestPlaceOrderAppliesPromotions(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	svc.promotions = adapters.NewPercentOffPromotionEngine(map[string]int64{"OLJCESPC7Z": 10})
+	batches := &recordingBatchPublisher{}
+	outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+	svc.orderEventOutbox = outbox
+
+	resp, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	if err := outbox.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	// 10% off 19.99 is 17.991, rounded down to 17.99
+	want := &pb.Money{CurrencyCode: "USD", Units: 17, Nanos: 990_000_000}
+	if got := resp.GetOrder().GetItems()[0].GetCost(); !proto.Equal(got, want) {
+		t.Errorf("item cost = %v, want %v", got, want)
+	}
+	if len(batches.headers) != 1 {
+		t.Fatalf("published %d batches, want 1", len(batches.headers))
+	}
+	headers := batches.headers[0]
+	if headers[adapters.DiscountsVersionHeader] != adapters.DiscountsVersion {
+		t.Errorf("%s = %q, want %q", adapters.DiscountsVersionHeader, headers[adapters.DiscountsVersionHeader], adapters.DiscountsVersion)
+	}
+	wantDiscounts := `[{"code":"PERCENT_OFF","productId":"OLJCESPC7Z","amount":{"currencyCode":"USD","units":4,"nanos":0}}]`
+	if got := headers[adapters.DiscountsHeader]; got != wantDiscounts {
+		t.Errorf("%s = %s, want %s", adapters.DiscountsHeader, got, wantDiscounts)
+	}
+	for _, event := range batches.batches[0] {
+		if event.Type == ports.LoyaltyPointsEarnedEvent {
+			continue
+		}
+		if !proto.Equal(event.Order.GetItems()[0].GetCost(), want) {
+			t.Errorf("%s item cost = %v, want %v", event.Type, event.Order.GetItems()[0].GetCost(), want)
+		}
+	}
+}
+
+func TestPlaceOrderEarnsLoyaltyPoints(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	batches := &recordingBatchPublisher{}
+	outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+	svc.orderEventOutbox = outbox
+
+	resp, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	if err := outbox.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	if len(batches.batches) != 1 {
+		t.Fatalf("published %d batches, want 1", len(batches.batches))
+	}
+	batch := batches.batches[0]
+	event := batch[len(batch)-1]
+	if event.Type != ports.LoyaltyPointsEarnedEvent || event.Order.GetOrderId() != resp.GetOrder().GetOrderId() {
+		t.Fatalf("last event = %s for order %q, want LoyaltyPointsEarned for %q", event.Type, event.Order.GetOrderId(), resp.GetOrder().GetOrderId())
+	}
+	// 2 of OLJCESPC7Z at 19.99 is 39.98
+	want := map[string]string{"customer_id": "user-1", "points": "39"}
+	if !maps.Equal(event.Attributes, want) {
+		t.Errorf("attributes = %v, want %v", event.Attributes, want)
+	}
+}
+
+func TestPlaceOrderSplitsTenders(t *testing.T) {
+	tests := []struct {
+		name         string
+		balance      int64
+		wantTenders  []ports.TenderType
+		wantCharges  int32
+		failShipping bool
+		wantBalance  int64
+	}{
+		{name: "gift card pays part", balance: 10, wantTenders: []ports.TenderType{ports.GiftCardTender, ports.CardTender}, wantCharges: 1},
+		{name: "gift card pays everything", balance: 1000, wantTenders: []ports.TenderType{ports.GiftCardTender}, wantBalance: -1},
+		{name: "shipping fails after payment", balance: 10, wantCharges: 1, failShipping: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &fakePaymentClient{}
+			svc := newTestCheckout(t, newAcceptingPublisher(t), payment)
+			svc.shippingProviders = newTestShippingProviders(newTestHTTPServices(t, tt.failShipping))
+			giftCards := adapters.NewInMemoryGiftCardPaymentService(map[string]*pb.Money{
+				"GIFT-1": {CurrencyCode: "USD", Units: tt.balance},
+			}, adapters.NewGRPCPaymentService(payment))
+			svc.payments = giftCards
+			svc.SetSchemaVersion(2)
+			compensator := &fakeOrderCompensator{}
+			svc.orderCompensator = compensator
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(giftCardHeader, "GIFT-1"))
+			_, err := svc.PlaceOrder(ctx, testPlaceOrderRequest())
+			if (err != nil) != tt.failShipping {
+				t.Fatalf("PlaceOrder() = %v", err)
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			if got := payment.charges.Load(); got != tt.wantCharges {
+				t.Errorf("charged the card %d times, want %d", got, tt.wantCharges)
+			}
+			if balance, _ := giftCards.Balance("GIFT-1"); tt.wantBalance >= 0 && balance.GetUnits() != tt.wantBalance {
+				t.Errorf("gift card balance = %v, want %d", balance, tt.wantBalance)
+			}
+			if tt.failShipping {
+				var tenders []ports.TenderType
+				for _, refund := range compensator.refunds {
+					tenders = append(tenders, refund.Tender)
+				}
+				if !slices.Equal(tenders, []ports.TenderType{"", ports.GiftCardTender}) {
+					t.Errorf("refunded %v, want the card then the gift card", compensator.refunds)
+				}
+				return
+			}
+
+			if len(batches.headers) != 1 {
+				t.Fatalf("published %d batches, want 1", len(batches.headers))
+			}
+			headers := batches.headers[0]
+			if headers[adapters.SchemaVersionHeader] != "2" {
+				t.Errorf("%s = %q, want 2", adapters.SchemaVersionHeader, headers[adapters.SchemaVersionHeader])
+			}
+			var payments []struct {
+				Type   string `json:"type"`
+				Amount struct {
+					Units int64 `json:"units"`
+				} `json:"amount"`
+				TransactionID string `json:"transactionId"`
+			}
+			if err := json.Unmarshal([]byte(headers[adapters.PaymentsHeader]), &payments); err != nil {
+				t.Fatalf("%s = %q: %v", adapters.PaymentsHeader, headers[adapters.PaymentsHeader], err)
+			}
+			var tenders []ports.TenderType
+			for _, p := range payments {
+				tenders = append(tenders, ports.TenderType(p.Type))
+			}
+			if !slices.Equal(tenders, tt.wantTenders) {
+				t.Errorf("payments = %+v, want tenders %v", payments, tt.wantTenders)
+			}
+			if len(payments) == 2 && payments[0].Amount.Units != tt.balance {
+				t.Errorf("gift card paid %d, want its balance %d", payments[0].Amount.Units, tt.balance)
+			}
+		})
+	}
+}
+
+func TestPlaceOrderSchemaVersion1HasNoPayments(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	svc.SetSchemaVersion(1)
+	batches := &recordingBatchPublisher{}
+	outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+	svc.orderEventOutbox = outbox
+
+	if _, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest()); err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	if err := outbox.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if len(batches.headers) != 1 || len(batches.headers[0]) != 0 {
+		t.Errorf("published headers %v, want none in schema version 1", batches.headers)
+	}
+}
+
+func TestPlaceOrderBreaksDownShippingFees(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	svc.SetSchemaVersion(3)
+	svc.insurancePercent = 1
+	batches := &recordingBatchPublisher{}
+	outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+	svc.orderEventOutbox = outbox
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		shippingMethodHeader, adapters.ShippingMethodExpress,
+		shippingInsuranceHeader, "true",
+	))
+	resp, err := svc.PlaceOrder(ctx, testPlaceOrderRequest())
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+	if err := outbox.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	// A quote of 8, the express surcharge of 10 and 1% of 39.98 rounded down
+	want := &pb.Money{CurrencyCode: "USD", Units: 18, Nanos: 390_000_000}
+	if got := resp.GetOrder().GetShippingCost(); !proto.Equal(got, want) {
+		t.Errorf("shipping cost = %v, want %v", got, want)
+	}
+	if len(batches.headers) != 1 {
+		t.Fatalf("published %d batches, want 1", len(batches.headers))
+	}
+	wantFees := `{"base":{"currencyCode":"USD","units":8,"nanos":0},"insurance":{"currencyCode":"USD","units":0,"nanos":390000000},"surcharges":{"currencyCode":"USD","units":10,"nanos":0}}`
+	if got := batches.headers[0][adapters.FeesHeader]; got != wantFees {
+		t.Errorf("%s = %s, want %s", adapters.FeesHeader, got, wantFees)
+	}
+}
+
+func TestPlaceOrderConvertsToUserCurrency(t *testing.T) {
+	svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+	req := testPlaceOrderRequest()
+	req.UserCurrency = "EUR"
+
+	resp, err := svc.PlaceOrder(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PlaceOrder() = %v", err)
+	}
+
+	// USD 19.99 at 0.9 is EUR 17.991, rounded down to the cent
+	wantCost := &pb.Money{CurrencyCode: "EUR", Units: 17, Nanos: 990_000_000}
+	for _, item := range resp.GetOrder().GetItems() {
+		if !proto.Equal(item.GetCost(), wantCost) {
+			t.Errorf("cost of %s = %v, want %v", item.GetItem().GetProductId(), item.GetCost(), wantCost)
+		}
+	}
+	wantShipping := &pb.Money{CurrencyCode: "EUR", Units: 7, Nanos: 200_000_000}
+	if got := resp.GetOrder().GetShippingCost(); !proto.Equal(got, wantShipping) {
+		t.Errorf("shipping cost = %v, want %v", got, wantShipping)
+	}
+}
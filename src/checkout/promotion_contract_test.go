@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/matchers"
+	"github.com/pact-foundation/pact-go/v2/message"
+	messagev3 "github.com/pact-foundation/pact-go/v2/message/v3"
+	"github.com/pact-foundation/pact-go/v2/models"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/testmode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/pactdir"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/providerstate"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Pact message contract for discounted orders. OrderResult has no field for
+// discounts, so the adjusted item costs travel in the order and the discount
+// lines in the order.discounts header, whose shape is versioned by the
+// order.discounts.version header. Each version of the shape gets its own
+// interaction, so that consumers of an older version keep verifying.
+const (
+	promotionsConsumer = "promotions-consumer"
+	promotionsPactFile = "pacts/promotions-consumer-checkout-provider.json"
+	discountedOrderV1  = "a discounted order-result message with discounts v1"
+)
+
+// discountedOrder is the part of a discounted OrderResult the promotions
+// consumer reads.
+type discountedOrder struct {
+	OrderID string `json:"orderId"`
+	Items   []struct {
+		Item struct {
+			ProductID string `json:"productId"`
+		} `json:"item"`
+		Cost struct {
+			CurrencyCode string `json:"currencyCode"`
+			Units        int64  `json:"units"`
+			Nanos        int32  `json:"nanos"`
+		} `json:"cost"`
+	} `json:"items"`
+}
+
+// discountLineV1 is version 1 of a line of the order.discounts header.
+type discountLineV1 struct {
+	Code      string `json:"code"`
+	ProductID string `json:"productId"`
+	Amount    struct {
+		CurrencyCode string `json:"currencyCode"`
+		Units        int64  `json:"units"`
+		Nanos        int32  `json:"nanos"`
+	} `json:"amount"`
+}
+
+// TestPromotionsConsumerContract records version 1 of the discounted order
+// message.
+func TestPromotionsConsumerContract(t *testing.T) {
+	testmode.Require(t, testmode.Contract)
+	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+		Consumer: promotionsConsumer,
+		Provider: "checkout-provider",
+		PactDir:  pactdir.For(t, filepath.Dir(promotionsPactFile)),
+	})
+	if err != nil {
+		t.Fatalf("failed to create pact: %v", err)
+	}
+
+	err = p.AddAsynchronousMessage().
+		GivenWithParameter(providerstate.DiscountedOrder.Given()).
+		ExpectsToReceive(discountedOrderV1).
+		WithMetadata(map[string]string{
+			"contentType":                   "application/json",
+			adapters.EventTypeHeader:        string(ports.OrderCompletedEvent),
+			adapters.DiscountsVersionHeader: "1",
+			adapters.DiscountsHeader:        `[{"code":"PERCENT_OFF","productId":"OLJCESPC7Z","amount":{"currencyCode":"USD","units":4,"nanos":0}}]`,
+		}).
+		WithJSONContent(matchers.StructMatcher{
+			"orderId": matchers.Like("order-12345-contract-test"),
+			"items": matchers.EachLike(matchers.StructMatcher{
+				"item": matchers.StructMatcher{
+					"productId": matchers.Like("OLJCESPC7Z"),
+				},
+				"cost": matchers.StructMatcher{
+					"currencyCode": matchers.Like("USD"),
+					"units":        matchers.Integer(17),
+					"nanos":        matchers.Integer(990000000),
+				},
+			}, 1),
+		}).
+		AsType(&discountedOrder{}).
+		ConsumedBy(func(m messagev3.MessageContents) error {
+			order := m.Content.(*discountedOrder)
+			if order.OrderID == "" || len(order.Items) == 0 {
+				return fmt.Errorf("discounted order %+v names no order or item", order)
+			}
+			if version := m.Metadata[adapters.DiscountsVersionHeader]; version != "1" {
+				return fmt.Errorf("discounts version %v, want 1", version)
+			}
+			header, _ := m.Metadata[adapters.DiscountsHeader].(string)
+			var lines []discountLineV1
+			if err := json.Unmarshal([]byte(header), &lines); err != nil {
+				return fmt.Errorf("failed to parse discounts %q: %w", header, err)
+			}
+			if len(lines) == 0 || lines[0].Code == "" || lines[0].Amount.CurrencyCode == "" {
+				return fmt.Errorf("discounts %+v have no code or amount", lines)
+			}
+			return nil
+		}).
+		Verify(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPromotionsProviderContract places an order with a promotion and verifies
+// the OrderResult it emits against the recorded promotions contract.
+func TestPromotionsProviderContract(t *testing.T) {
+	messageHandlers := message.Handlers{
+		discountedOrderV1: func(states []models.ProviderState) (message.Body, message.Metadata, error) {
+			svc := newTestCheckout(t, newAcceptingPublisher(t), &fakePaymentClient{})
+			svc.promotions = adapters.NewPercentOffPromotionEngine(map[string]int64{"OLJCESPC7Z": 10})
+			batches := &recordingBatchPublisher{}
+			outbox := adapters.NewInMemoryOrderEventOutbox(batches, logger)
+			svc.orderEventOutbox = outbox
+
+			if _, err := svc.PlaceOrder(context.Background(), testPlaceOrderRequest()); err != nil {
+				return nil, nil, err
+			}
+			if err := outbox.Close(context.Background()); err != nil {
+				return nil, nil, err
+			}
+			if len(batches.batches) != 1 {
+				return nil, nil, fmt.Errorf("published %v, want one batch", batches.batches)
+			}
+			for _, event := range batches.batches[0] {
+				if event.Type != ports.OrderCompletedEvent {
+					continue
+				}
+				body, err := serialization.ToConsumerJSON(event.Order)
+				if err != nil {
+					return nil, nil, err
+				}
+				metadata := message.Metadata{
+					"contentType":            "application/json",
+					adapters.EventTypeHeader: string(event.Type),
+				}
+				for key, value := range batches.headers[0] {
+					metadata[key] = value
+				}
+				return body, metadata, nil
+			}
+			return nil, nil, fmt.Errorf("published %v, want an OrderCompleted event", batches.batches)
+		},
+	}
+	stateHandlers := providerstate.Handlers(providerstate.DiscountedOrder.Bind(nil))
+
+	verifyRequest := provider.VerifyRequest{
+		Provider:        "checkout-provider",
+		StateHandlers:   stateHandlers,
+		MessageHandlers: messageHandlers,
+	}
+	pactSource(t, &verifyRequest, promotionsPactFile, "TestPromotionsConsumerContract")
+
+	if err := provider.NewVerifier().VerifyProvider(t, verifyRequest); err != nil {
+		t.Fatalf("Contract verification failed: %v", err)
+	}
+}
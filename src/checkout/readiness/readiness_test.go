@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestChecker(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks map[string]Check
+		want   Report
+	}{
+		{
+			name:   "all ready",
+			checks: map[string]Check{"kafka": ok, "cart": ok},
+			want:   Report{Ready: true, Checks: map[string]string{"kafka": "ok", "cart": "ok"}},
+		},
+		{
+			name: "kafka down",
+			checks: map[string]Check{
+				"kafka": func(context.Context) error { return errors.New("no kafka broker reachable") },
+				"cart":  ok,
+			},
+			want: Report{Ready: false, Checks: map[string]string{"kafka": "no kafka broker reachable", "cart": "ok"}},
+		},
+		{
+			name: "check times out",
+			checks: map[string]Check{"schema_registry": func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}},
+			want: Report{Ready: false, Checks: map[string]string{"schema_registry": context.DeadlineExceeded.Error()}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(10 * time.Millisecond)
+			for name, check := range tt.checks {
+				checker.Add(name, check)
+			}
+
+			srv := httptest.NewServer(checker)
+			defer srv.Close()
+			resp, err := http.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var got Report
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("decode report: %v", err)
+			}
+			if got.Ready != tt.want.Ready || len(got.Checks) != len(tt.want.Checks) {
+				t.Fatalf("report = %+v, want %+v", got, tt.want)
+			}
+			for name, want := range tt.want.Checks {
+				if got.Checks[name] != want {
+					t.Errorf("check %s = %q, want %q", name, got.Checks[name], want)
+				}
+			}
+			wantStatus := http.StatusOK
+			if !tt.want.Ready {
+				wantStatus = http.StatusServiceUnavailable
+			}
+			if resp.StatusCode != wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckerWatch(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("kafka", func(context.Context) error { return errors.New("down") })
+	server := health.NewServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.Watch(ctx, server, time.Hour, "", "oteldemo.CheckoutService")
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "oteldemo.CheckoutService"})
+		if err == nil && resp.Status == healthpb.HealthCheckResponse_NOT_SERVING {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Check() = %v, %v; want NOT_SERVING", resp, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package readiness
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sort"
+	"time"
+)
+
+// Backoff is the exponential delay between two startup probes.
+type Backoff struct {
+	// Initial is the delay after the first failed probe
+	Initial time.Duration
+	// Max caps the delay
+	Max time.Duration
+	// Multiplier grows the delay after each failed probe
+	Multiplier float64
+}
+
+// DefaultBackoff waits 500ms after the first failed probe, doubling up to 10s.
+var DefaultBackoff = Backoff{Initial: 500 * time.Millisecond, Max: 10 * time.Second, Multiplier: 2}
+
+// next returns the delay that follows delay.
+func (b Backoff) next(delay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * b.Multiplier)
+	if delay > b.Max || delay <= 0 {
+		return b.Max
+	}
+	return delay
+}
+
+// WaitReady runs the checks until all of them pass or ctx is done, waiting
+// according to backoff between attempts, and returns the last report. Each
+// failed attempt is logged with the dependencies that are still down, so that
+// a slow start can be told apart from a misconfiguration.
+func (c *Checker) WaitReady(ctx context.Context, backoff Backoff, logger *slog.Logger) Report {
+	start := time.Now()
+	delay := backoff.Initial
+	for attempt := 1; ; attempt++ {
+		report := c.Check(ctx)
+		if report.Ready {
+			logger.InfoContext(ctx, "dependencies ready",
+				slog.Int("attempt", attempt),
+				slog.Duration("elapsed", time.Since(start)),
+			)
+			return report
+		}
+
+		down := report.Down()
+		attrs := []any{
+			slog.Int("attempt", attempt),
+			slog.Any("down", down),
+			slog.Duration("retry_in", delay),
+		}
+		for _, name := range down {
+			attrs = append(attrs, slog.String("check."+name, report.Checks[name]))
+		}
+		logger.WarnContext(ctx, "waiting for dependencies", attrs...)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return report
+		case <-timer.C:
+		}
+		delay = backoff.next(delay)
+	}
+}
+
+// Down returns the names of the failed checks in alphabetical order.
+func (r Report) Down() []string {
+	var down []string
+	for name, result := range r.Checks {
+		if result != "ok" {
+			down = append(down, name)
+		}
+	}
+	sort.Strings(down)
+	return down
+}
+
+// TCPCheck reports whether a TCP connection to addr can be opened. Unlike
+// GRPCConnCheck, it fails while the target is still starting, which makes it
+// suited to probing dependencies on boot.
+func TCPCheck(addr string) Check {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
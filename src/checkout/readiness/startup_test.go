@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package readiness
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testBackoff = Backoff{Initial: time.Millisecond, Max: 4 * time.Millisecond, Multiplier: 2}
+
+func TestWaitReady(t *testing.T) {
+	var attempts atomic.Int32
+	checker := NewChecker(time.Second)
+	checker.Add("cart", ok)
+	checker.Add("kafka", func(context.Context) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("no kafka broker reachable")
+		}
+		return nil
+	})
+
+	report := checker.WaitReady(context.Background(), testBackoff, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if !report.Ready {
+		t.Fatalf("WaitReady() = %+v, want ready", report)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("kafka checked %d times, want 3", got)
+	}
+}
+
+func TestWaitReadyGivesUp(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Add("cart", ok)
+	checker.Add("schema_registry", func(context.Context) error { return errors.New("connection refused") })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report := checker.WaitReady(ctx, testBackoff, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if report.Ready {
+		t.Fatal("WaitReady() is ready, want not ready")
+	}
+	if got, want := report.Down(), []string{"schema_registry"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Down() = %v, want %v", got, want)
+	}
+}
+
+func TestBackoffNext(t *testing.T) {
+	tests := []struct {
+		delay time.Duration
+		want  time.Duration
+	}{
+		{delay: time.Millisecond, want: 2 * time.Millisecond},
+		{delay: 2 * time.Millisecond, want: 4 * time.Millisecond},
+		{delay: 4 * time.Millisecond, want: 4 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := testBackoff.next(tt.delay); got != tt.want {
+			t.Errorf("next(%v) = %v, want %v", tt.delay, got, tt.want)
+		}
+	}
+}
+
+func TestTCPCheck(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+
+	if err := TCPCheck(addr)(context.Background()); err != nil {
+		t.Errorf("TCPCheck() with a listener = %v, want nil", err)
+	}
+	lis.Close()
+	if err := TCPCheck(addr)(context.Background()); err == nil {
+		t.Error("TCPCheck() without a listener = nil, want an error")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const apicurioDefaultGroup = "default"
+
+// ApicurioClient implements Client against the Apicurio Registry v2 core API.
+// Subjects map to artifact IDs within a single artifact group.
+type ApicurioClient struct {
+	baseURL    string
+	group      string
+	httpClient *http.Client
+}
+
+// Compile-time check that ApicurioClient implements Client
+var _ Client = (*ApicurioClient)(nil)
+
+// NewApicurioClient creates an Apicurio Registry client. An empty group selects
+// Apicurio's "default" group.
+func NewApicurioClient(baseURL, group string, httpClient *http.Client) *ApicurioClient {
+	if group == "" {
+		group = apicurioDefaultGroup
+	}
+	return &ApicurioClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		group:      group,
+		httpClient: defaultHTTPClient(httpClient),
+	}
+}
+
+func (a *ApicurioClient) artifactsURL() string {
+	return fmt.Sprintf("%s/apis/registry/v2/groups/%s/artifacts", a.baseURL, url.PathEscape(a.group))
+}
+
+func (a *ApicurioClient) artifactURL(subject string) string {
+	return a.artifactsURL() + "/" + url.PathEscape(subject)
+}
+
+func apicurioContentType(t SchemaType) string {
+	if t == SchemaTypeProtobuf {
+		return "application/x-protobuf"
+	}
+	return "application/json"
+}
+
+// Register implements Client.
+func (a *ApicurioClient) Register(ctx context.Context, subject string, schema Schema) (int64, error) {
+	header := http.Header{
+		"Content-Type":            {apicurioContentType(schema.Type)},
+		"X-Registry-ArtifactId":   {subject},
+		"X-Registry-ArtifactType": {string(schema.Type)},
+	}
+
+	var resp struct {
+		GlobalID int64 `json:"globalId"`
+	}
+	endpoint := a.artifactsURL() + "?ifExists=RETURN_OR_UPDATE"
+	if _, err := doJSON(ctx, a.httpClient, http.MethodPost, endpoint, header, []byte(schema.Definition), &resp); err != nil {
+		return 0, err
+	}
+	return resp.GlobalID, nil
+}
+
+// CheckCompatibility implements Client using the artifact "test update" endpoint,
+// which applies the artifact's rules without storing a new version.
+func (a *ApicurioClient) CheckCompatibility(ctx context.Context, subject string, schema Schema) (bool, error) {
+	header := http.Header{"Content-Type": {apicurioContentType(schema.Type)}}
+
+	status, err := doJSON(ctx, a.httpClient, http.MethodPut, a.artifactURL(subject)+"/test", header, []byte(schema.Definition), nil)
+	switch status {
+	case http.StatusNotFound:
+		return true, nil
+	case http.StatusConflict:
+		// Apicurio reports rule violations as 409 RuleViolationException
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetCompatibility implements Client. Apicurio attaches rules to artifacts, so
+// the subject must be registered first; ErrSubjectNotFound is returned otherwise.
+func (a *ApicurioClient) SetCompatibility(ctx context.Context, subject string, level Compatibility) error {
+	body, err := json.Marshal(map[string]string{"type": "COMPATIBILITY", "config": string(level)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal compatibility rule: %w", err)
+	}
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	status, err := doJSON(ctx, a.httpClient, http.MethodPost, a.artifactURL(subject)+"/rules", header, body, nil)
+	switch status {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrSubjectNotFound, subject)
+	case http.StatusConflict:
+		// The rule already exists, update it in place
+		_, err = doJSON(ctx, a.httpClient, http.MethodPut, a.artifactURL(subject)+"/rules/COMPATIBILITY", header, body, nil)
+	}
+	return err
+}
+
+// Ping implements Client.
+func (a *ApicurioClient) Ping(ctx context.Context) error {
+	_, err := doJSON(ctx, a.httpClient, http.MethodGet, a.baseURL+"/apis/registry/v2/system/info", nil, nil, nil)
+	return err
+}
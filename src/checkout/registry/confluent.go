@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const confluentContentType = "application/vnd.schemaregistry.v1+json"
+
+// ConfluentClient implements Client against the Confluent Schema Registry REST API.
+type ConfluentClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Compile-time check that ConfluentClient implements Client
+var _ Client = (*ConfluentClient)(nil)
+
+// NewConfluentClient creates a Confluent Schema Registry client.
+func NewConfluentClient(baseURL string, httpClient *http.Client) *ConfluentClient {
+	return &ConfluentClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: defaultHTTPClient(httpClient),
+	}
+}
+
+type confluentSchemaRequest struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+func (c *ConfluentClient) header() http.Header {
+	return http.Header{"Content-Type": {confluentContentType}, "Accept": {confluentContentType}}
+}
+
+// Register implements Client.
+func (c *ConfluentClient) Register(ctx context.Context, subject string, schema Schema) (int64, error) {
+	body, err := json.Marshal(confluentSchemaRequest{Schema: schema.Definition, SchemaType: schema.Type})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	var resp struct {
+		ID int64 `json:"id"`
+	}
+	endpoint := fmt.Sprintf("%s/subjects/%s/versions", c.baseURL, url.PathEscape(subject))
+	if _, err := doJSON(ctx, c.httpClient, http.MethodPost, endpoint, c.header(), body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// CheckCompatibility implements Client.
+func (c *ConfluentClient) CheckCompatibility(ctx context.Context, subject string, schema Schema) (bool, error) {
+	body, err := json.Marshal(confluentSchemaRequest{Schema: schema.Definition, SchemaType: schema.Type})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	var resp struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	endpoint := fmt.Sprintf("%s/compatibility/subjects/%s/versions/latest", c.baseURL, url.PathEscape(subject))
+	status, err := doJSON(ctx, c.httpClient, http.MethodPost, endpoint, c.header(), body, &resp)
+	if status == http.StatusNotFound {
+		// Nothing registered yet, so there is nothing to be incompatible with
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return resp.IsCompatible, nil
+}
+
+// SetCompatibility implements Client.
+func (c *ConfluentClient) SetCompatibility(ctx context.Context, subject string, level Compatibility) error {
+	body, err := json.Marshal(map[string]Compatibility{"compatibility": level})
+	if err != nil {
+		return fmt.Errorf("failed to marshal compatibility config: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/config/%s", c.baseURL, url.PathEscape(subject))
+	_, err = doJSON(ctx, c.httpClient, http.MethodPut, endpoint, c.header(), body, nil)
+	return err
+}
+
+// Ping implements Client.
+func (c *ConfluentClient) Ping(ctx context.Context) error {
+	_, err := doJSON(ctx, c.httpClient, http.MethodGet, c.baseURL+"/subjects", c.header(), nil, nil)
+	return err
+}
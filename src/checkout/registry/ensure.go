@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrIncompatibleSchema is returned by EnsureSchema when the schema would break
+// consumers of the subject under its compatibility level.
+var ErrIncompatibleSchema = errors.New("schema is incompatible with the registered subject")
+
+// EnsureSchema enforces level on subject, verifies that schema is compatible
+// with what is registered under subject at that level, and registers it. It
+// returns the registry ID of the schema, or ErrIncompatibleSchema if it would
+// break consumers.
+func EnsureSchema(ctx context.Context, client Client, subject string, schema Schema, level Compatibility) (int64, error) {
+	// The check runs under the level of the subject, so level is set first.
+	// Apicurio only has rules for registered subjects; a new one has nothing
+	// to be incompatible with and gets level once registered
+	err := client.SetCompatibility(ctx, subject, level)
+	unregistered := errors.Is(err, ErrSubjectNotFound)
+	if err != nil && !unregistered {
+		return 0, fmt.Errorf("failed to set compatibility for %q: %w", subject, err)
+	}
+
+	compatible, err := client.CheckCompatibility(ctx, subject, schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check schema compatibility for %q: %w", subject, err)
+	}
+	if !compatible {
+		return 0, fmt.Errorf("%w: %s (%s)", ErrIncompatibleSchema, subject, level)
+	}
+
+	id, err := client.Register(ctx, subject, schema)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema for %q: %w", subject, err)
+	}
+	if unregistered {
+		if err := client.SetCompatibility(ctx, subject, level); err != nil {
+			return id, fmt.Errorf("failed to set compatibility for %q: %w", subject, err)
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// SchemaType identifies the schema language of a Schema definition.
+type SchemaType string
+
+const (
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+	SchemaTypeJSON     SchemaType = "JSON"
+)
+
+// Compatibility is a registry compatibility level. The names are shared by
+// Confluent and Apicurio.
+type Compatibility string
+
+const (
+	CompatibilityNone               Compatibility = "NONE"
+	CompatibilityBackward           Compatibility = "BACKWARD"
+	CompatibilityBackwardTransitive Compatibility = "BACKWARD_TRANSITIVE"
+	CompatibilityForward            Compatibility = "FORWARD"
+	CompatibilityForwardTransitive  Compatibility = "FORWARD_TRANSITIVE"
+	CompatibilityFull               Compatibility = "FULL"
+	CompatibilityFullTransitive     Compatibility = "FULL_TRANSITIVE"
+)
+
+// ParseCompatibility parses a compatibility level name. An empty string
+// selects BACKWARD, the default of both registries.
+func ParseCompatibility(s string) (Compatibility, error) {
+	c := Compatibility(strings.ToUpper(s))
+	switch c {
+	case "":
+		return CompatibilityBackward, nil
+	case CompatibilityNone, CompatibilityBackward, CompatibilityBackwardTransitive,
+		CompatibilityForward, CompatibilityForwardTransitive, CompatibilityFull, CompatibilityFullTransitive:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown compatibility level %q", s)
+	}
+}
+
+// Kind names a registry implementation.
+type Kind string
+
+const (
+	KindConfluent Kind = "confluent"
+	KindApicurio  Kind = "apicurio"
+)
+
+// ErrSubjectNotFound is returned when an operation requires a subject that
+// has not been registered yet.
+var ErrSubjectNotFound = errors.New("subject not found in schema registry")
+
+// Schema is a schema definition together with its language.
+type Schema struct {
+	Type       SchemaType
+	Definition string
+}
+
+// Client abstracts a schema registry so that schema registration and
+// compatibility checks are not tied to a single vendor.
+type Client interface {
+	// Register registers schema under subject and returns the registry ID of
+	// the stored version. Registering an identical schema again is a no-op that
+	// returns the existing ID.
+	Register(ctx context.Context, subject string, schema Schema) (int64, error)
+
+	// CheckCompatibility reports whether schema is compatible with the versions
+	// registered under subject, according to the subject's compatibility level.
+	// A subject with no registered versions is always compatible.
+	CheckCompatibility(ctx context.Context, subject string, schema Schema) (bool, error)
+
+	// SetCompatibility sets the compatibility level enforced for subject.
+	SetCompatibility(ctx context.Context, subject string, level Compatibility) error
+
+	// Ping reports whether the registry is reachable and answering requests.
+	Ping(ctx context.Context) error
+}
+
+// NewClient creates a registry client of the given kind. If httpClient is nil
+// a client with OpenTelemetry instrumentation is used.
+func NewClient(kind Kind, baseURL string, httpClient *http.Client) (Client, error) {
+	switch Kind(strings.ToLower(string(kind))) {
+	case KindConfluent, "":
+		return NewConfluentClient(baseURL, httpClient), nil
+	case KindApicurio:
+		return NewApicurioClient(baseURL, "", httpClient), nil
+	default:
+		return nil, fmt.Errorf("unknown schema registry kind %q", kind)
+	}
+}
+
+func defaultHTTPClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}
+
+// doJSON sends a request and decodes a JSON response into out, if out is not
+// nil. It returns the response status code alongside any error.
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, body []byte, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create registry request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed %s to schema registry: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read schema registry response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, fmt.Errorf("schema registry %s %s: expected 2xx, got %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to unmarshal schema registry response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
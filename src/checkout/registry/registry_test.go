@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry stores schemas per subject and treats a new schema as
+// compatible if it only appends to the latest registered definition, or if
+// the compatibility level of the subject is NONE.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	subjects map[string][]string
+	levels   map[string]string
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{subjects: map[string][]string{}, levels: map[string]string{}}
+}
+
+func (f *fakeRegistry) register(subject, schema string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	versions := f.subjects[subject]
+	if len(versions) == 0 || versions[len(versions)-1] != schema {
+		f.subjects[subject] = append(versions, schema)
+	}
+	return int64(len(f.subjects[subject]))
+}
+
+// compatible returns (compatible, found).
+func (f *fakeRegistry) compatible(subject, schema string) (bool, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	versions := f.subjects[subject]
+	if len(versions) == 0 {
+		return false, false
+	}
+	if f.levels[subject] == string(CompatibilityNone) {
+		return true, true
+	}
+	return strings.HasPrefix(schema, versions[len(versions)-1]), true
+}
+
+func (f *fakeRegistry) confluentHandler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /subjects/{subject}/versions", func(w http.ResponseWriter, r *http.Request) {
+		var req confluentSchemaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad register body: %v", err)
+		}
+		if req.SchemaType != SchemaTypeProtobuf {
+			t.Errorf("schemaType = %q, want PROTOBUF", req.SchemaType)
+		}
+		json.NewEncoder(w).Encode(map[string]int64{"id": f.register(r.PathValue("subject"), req.Schema)})
+	})
+	mux.HandleFunc("POST /compatibility/subjects/{subject}/versions/latest", func(w http.ResponseWriter, r *http.Request) {
+		var req confluentSchemaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		ok, found := f.compatible(r.PathValue("subject"), req.Schema)
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40401,"message":"Subject not found."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]bool{"is_compatible": ok})
+	})
+	mux.HandleFunc("GET /subjects", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("PUT /config/{subject}", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.levels[r.PathValue("subject")] = req["compatibility"]
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(req)
+	})
+	return mux
+}
+
+func (f *fakeRegistry) apicurioHandler(t *testing.T) http.Handler {
+	const prefix = "/apis/registry/v2/groups/default/artifacts"
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis/registry/v2/system/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"Apicurio Registry (fake)"}`))
+	})
+	mux.HandleFunc("POST "+prefix, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("ifExists"); got != "RETURN_OR_UPDATE" {
+			t.Errorf("ifExists = %q", got)
+		}
+		if got := r.Header.Get("X-Registry-ArtifactType"); got != "PROTOBUF" {
+			t.Errorf("X-Registry-ArtifactType = %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		id := f.register(r.Header.Get("X-Registry-ArtifactId"), string(body))
+		json.NewEncoder(w).Encode(map[string]int64{"globalId": id})
+	})
+	mux.HandleFunc("PUT "+prefix+"/{id}/test", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ok, found := f.compatible(r.PathValue("id"), string(body))
+		switch {
+		case !found:
+			w.WriteHeader(http.StatusNotFound)
+		case !ok:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_code":409,"message":"Incompatible artifact"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("POST "+prefix+"/{id}/rules", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if _, found := f.compatible(id, ""); !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, exists := f.levels[id]; exists {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.levels[id] = req["config"]
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT "+prefix+"/{id}/rules/COMPATIBILITY", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.levels[r.PathValue("id")] = req["config"]
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(req)
+	})
+	return mux
+}
+
+// TestClients runs the same registration lifecycle against every implementation
+// so that callers can rely on identical semantics regardless of vendor.
+func TestClients(t *testing.T) {
+	tests := []struct {
+		kind    Kind
+		handler func(f *fakeRegistry, t *testing.T) http.Handler
+	}{
+		{KindConfluent, (*fakeRegistry).confluentHandler},
+		{KindApicurio, (*fakeRegistry).apicurioHandler},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			fake := newFakeRegistry()
+			srv := httptest.NewServer(tt.handler(fake, t))
+			defer srv.Close()
+
+			client, err := NewClient(tt.kind, srv.URL, srv.Client())
+			if err != nil {
+				t.Fatalf("NewClient() = %v", err)
+			}
+			ctx := context.Background()
+			if err := client.Ping(ctx); err != nil {
+				t.Fatalf("Ping() = %v", err)
+			}
+			v1 := Schema{Type: SchemaTypeProtobuf, Definition: "message OrderResult { string order_id = 1; }"}
+			v2 := Schema{Type: SchemaTypeProtobuf, Definition: v1.Definition + " // v2"}
+			broken := Schema{Type: SchemaTypeProtobuf, Definition: "message OrderResult { int64 order_id = 1; }"}
+
+			if ok, err := client.CheckCompatibility(ctx, "orders-value", v1); err != nil || !ok {
+				t.Fatalf("CheckCompatibility(unregistered) = %v, %v; want true, nil", ok, err)
+			}
+			if _, err := client.Register(ctx, "orders-value", v1); err != nil {
+				t.Fatalf("Register() = %v", err)
+			}
+			if err := client.SetCompatibility(ctx, "orders-value", CompatibilityBackward); err != nil {
+				t.Fatalf("SetCompatibility() = %v", err)
+			}
+			if err := client.SetCompatibility(ctx, "orders-value", CompatibilityFull); err != nil {
+				t.Fatalf("SetCompatibility(update) = %v", err)
+			}
+			if fake.levels["orders-value"] != string(CompatibilityFull) {
+				t.Errorf("compatibility = %q, want FULL", fake.levels["orders-value"])
+			}
+			if ok, err := client.CheckCompatibility(ctx, "orders-value", v2); err != nil || !ok {
+				t.Errorf("CheckCompatibility(v2) = %v, %v; want true, nil", ok, err)
+			}
+			if ok, err := client.CheckCompatibility(ctx, "orders-value", broken); err != nil || ok {
+				t.Errorf("CheckCompatibility(broken) = %v, %v; want false, nil", ok, err)
+			}
+			id, err := client.Register(ctx, "orders-value", v2)
+			if err != nil || id != 2 {
+				t.Errorf("Register(v2) = %d, %v; want 2, nil", id, err)
+			}
+		})
+	}
+}
+
+func TestApicurioSetCompatibilityRequiresArtifact(t *testing.T) {
+	srv := httptest.NewServer(newFakeRegistry().apicurioHandler(t))
+	defer srv.Close()
+
+	err := NewApicurioClient(srv.URL, "", srv.Client()).SetCompatibility(context.Background(), "missing", CompatibilityBackward)
+	if !errors.Is(err, ErrSubjectNotFound) {
+		t.Errorf("SetCompatibility() = %v, want ErrSubjectNotFound", err)
+	}
+}
+
+func TestParseCompatibility(t *testing.T) {
+	if c, err := ParseCompatibility(""); err != nil || c != CompatibilityBackward {
+		t.Errorf("ParseCompatibility(\"\") = %q, %v", c, err)
+	}
+	if c, err := ParseCompatibility("full_transitive"); err != nil || c != CompatibilityFullTransitive {
+		t.Errorf("ParseCompatibility(full_transitive) = %q, %v", c, err)
+	}
+	if _, err := ParseCompatibility("sideways"); err == nil {
+		t.Error("ParseCompatibility(sideways) succeeded, want error")
+	}
+}
+
+func TestNewClientUnknownKind(t *testing.T) {
+	if _, err := NewClient("glue", "http://localhost", nil); err == nil {
+		t.Error("NewClient(glue) succeeded, want error")
+	}
+}
+
+func TestEnsureSchema(t *testing.T) {
+	fake := newFakeRegistry()
+	srv := httptest.NewServer(fake.confluentHandler(t))
+	defer srv.Close()
+	client := NewConfluentClient(srv.URL, srv.Client())
+	ctx := context.Background()
+
+	v1 := Schema{Type: SchemaTypeProtobuf, Definition: "message OrderResult {}"}
+	if _, err := EnsureSchema(ctx, client, "orders-value", v1, CompatibilityBackward); err != nil {
+		t.Fatalf("EnsureSchema(v1) = %v", err)
+	}
+	if fake.levels["orders-value"] != string(CompatibilityBackward) {
+		t.Errorf("compatibility = %q, want BACKWARD", fake.levels["orders-value"])
+	}
+
+	broken := Schema{Type: SchemaTypeProtobuf, Definition: "message Renamed {}"}
+	if _, err := EnsureSchema(ctx, client, "orders-value", broken, CompatibilityBackward); !errors.Is(err, ErrIncompatibleSchema) {
+		t.Errorf("EnsureSchema(broken) = %v, want ErrIncompatibleSchema", err)
+	}
+	if len(fake.subjects["orders-value"]) != 1 {
+		t.Error("incompatible schema must not be registered")
+	}
+}
+
+func TestEnsureSchemaEnforcesLevelBeforeCheck(t *testing.T) {
+	for _, tt := range []struct {
+		kind    Kind
+		handler func(f *fakeRegistry, t *testing.T) http.Handler
+	}{
+		{KindConfluent, (*fakeRegistry).confluentHandler},
+		{KindApicurio, (*fakeRegistry).apicurioHandler},
+	} {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			fake := newFakeRegistry()
+			srv := httptest.NewServer(tt.handler(fake, t))
+			defer srv.Close()
+			client, err := NewClient(tt.kind, srv.URL, srv.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+
+			// The subject accepts anything until the configured level applies
+			v1 := Schema{Type: SchemaTypeProtobuf, Definition: "message OrderResult {}"}
+			if _, err := EnsureSchema(ctx, client, "orders-value", v1, CompatibilityNone); err != nil {
+				t.Fatalf("EnsureSchema(v1, NONE) = %v", err)
+			}
+			if fake.levels["orders-value"] != string(CompatibilityNone) {
+				t.Fatalf("compatibility = %q, want NONE", fake.levels["orders-value"])
+			}
+
+			broken := Schema{Type: SchemaTypeProtobuf, Definition: "message Renamed {}"}
+			if _, err := EnsureSchema(ctx, client, "orders-value", broken, CompatibilityBackward); !errors.Is(err, ErrIncompatibleSchema) {
+				t.Errorf("EnsureSchema(broken, BACKWARD) = %v, want ErrIncompatibleSchema", err)
+			}
+			if len(fake.subjects["orders-value"]) != 1 {
+				t.Error("incompatible schema must not be registered")
+			}
+			if fake.levels["orders-value"] != string(CompatibilityBackward) {
+				t.Errorf("compatibility = %q, want BACKWARD", fake.levels["orders-value"])
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package reload applies changes to the order event publisher settings while
+// the checkout service runs. A Watcher polls a file or an HTTP endpoint
+// holding the variables of config.OrderEvents and config.Kafka as KEY=value
+// lines, overlays them on the environment, and hands the resulting
+// configuration to the service, which swaps its publisher chain:
+//
+//	ORDER_EVENT_PUBLISHER=webhook
+//	ORDER_EVENT_WEBHOOK_URL=http://consumer/orders
+//
+// A source that cannot be read or holds invalid settings is reported and the
+// running publisher is kept.
+package reload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+)
+
+// ApplyFunc applies cfg to the running service.
+type ApplyFunc func(ctx context.Context, cfg *config.Config) error
+
+// Watcher applies the settings of a source when they change.
+type Watcher struct {
+	source Source
+	lookup config.LookupFunc
+	apply  ApplyFunc
+	logger *slog.Logger
+	// keys are the variables a source may set
+	keys map[string]bool
+
+	// last holds the variables of the source last applied, or that failed
+	// to apply, so that a bad source is only reported once
+	last map[string]string
+}
+
+// NewWatcher watches source, whose variables override those returned by
+// lookup, and passes the configuration to apply when they change.
+func NewWatcher(source Source, lookup config.LookupFunc, apply ApplyFunc, logger *slog.Logger) *Watcher {
+	keys := map[string]bool{}
+	for _, key := range append(config.Keys(config.OrderEvents{}), config.Keys(config.Kafka{})...) {
+		keys[key] = true
+	}
+	return &Watcher{source: source, lookup: lookup, apply: apply, logger: logger, keys: keys}
+}
+
+// Load reads the source and returns the configuration it sets over the
+// environment, for the service to start with.
+func (w *Watcher) Load(ctx context.Context) (*config.Config, error) {
+	vars, err := w.source.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := w.config(vars)
+	if err != nil {
+		return nil, err
+	}
+	w.last = vars
+	return cfg, nil
+}
+
+// Poll reads the source and applies its settings if they changed since the
+// last Load or Poll. It returns whether they changed.
+func (w *Watcher) Poll(ctx context.Context) (bool, error) {
+	vars, err := w.source.Read(ctx)
+	if err != nil {
+		return false, err
+	}
+	if maps.Equal(vars, w.last) {
+		return false, nil
+	}
+	changed := changedKeys(w.last, vars)
+	w.last = vars
+	cfg, err := w.config(vars)
+	if err != nil {
+		return true, err
+	}
+	if err := w.apply(ctx, cfg); err != nil {
+		return true, fmt.Errorf("failed to apply %s: %w", strings.Join(changed, ", "), err)
+	}
+	w.logger.Info(fmt.Sprintf("order event publisher settings reloaded from %s: %s", w.source, strings.Join(changed, ", ")))
+	return true, nil
+}
+
+// Run polls the source every interval until ctx is done, logging the
+// settings that cannot be applied.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Poll(ctx); err != nil {
+				w.logger.Error(fmt.Sprintf("order event publisher settings not reloaded from %s, keeping the running publisher: %v", w.source, err))
+			}
+		}
+	}
+}
+
+// config loads the configuration with vars over the environment, rejecting
+// the variables that are not publisher settings.
+func (w *Watcher) config(vars map[string]string) (*config.Config, error) {
+	var unknown []string
+	for key := range vars {
+		if !w.keys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s cannot be reloaded, only the variables of the order event publisher and Kafka can", strings.Join(unknown, ", "))
+	}
+	return config.LoadFrom(func(key string) (string, bool) {
+		if v, ok := vars[key]; ok {
+			return v, true
+		}
+		return w.lookup(key)
+	})
+}
+
+// changedKeys returns the keys set, changed or removed between before and
+// after, in alphabetical order.
+func changedKeys(before, after map[string]string) []string {
+	var keys []string
+	for key, v := range after {
+		if old, ok := before[key]; !ok || old != v {
+			keys = append(keys, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
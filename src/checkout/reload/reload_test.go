@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package reload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+)
+
+// environment sets the variables the configuration requires.
+func environment(key string) (string, bool) {
+	switch key {
+	case "CHECKOUT_PORT":
+		return "5050", true
+	case "SHIPPING_ADDR", "PRODUCT_CATALOG_ADDR", "CART_ADDR", "CURRENCY_ADDR", "EMAIL_ADDR", "PAYMENT_ADDR":
+		return "service:8080", true
+	case "ORDER_EVENT_PUBLISHER":
+		return "noop", true
+	}
+	return "", false
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	got, err := ParseVariables(strings.NewReader(`
+# switch to the webhook
+ORDER_EVENT_PUBLISHER = webhook
+ORDER_EVENT_WEBHOOK_URL="http://consumer/orders?a=b"
+KAFKA_ADDR=
+`))
+	if err != nil {
+		t.Fatalf("ParseVariables() = %v", err)
+	}
+	want := map[string]string{
+		"ORDER_EVENT_PUBLISHER":   "webhook",
+		"ORDER_EVENT_WEBHOOK_URL": "http://consumer/orders?a=b",
+		"KAFKA_ADDR":              "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseVariables() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseVariables(strings.NewReader("ORDER_EVENT_PUBLISHER\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("ParseVariables(no value) = %v, want an error on line 1", err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "publisher.env")
+	writeFile(t, path, "ORDER_EVENT_PUBLISHER=spool\n")
+	var applied []*config.Config
+	applyErr := error(nil)
+	w := NewWatcher(FileSource(path), environment, func(ctx context.Context, cfg *config.Config) error {
+		applied = append(applied, cfg)
+		return applyErr
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cfg, err := w.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if cfg.OrderEvents.Publisher != "spool" || cfg.Services.Cart != "service:8080" {
+		t.Errorf("Load() = %+v, want the spool publisher over the environment", cfg)
+	}
+	if changed, err := w.Poll(context.Background()); changed || err != nil {
+		t.Errorf("Poll() of the loaded settings = %v, %v; want false, nil", changed, err)
+	}
+
+	writeFile(t, path, "ORDER_EVENT_PUBLISHER=webhook\nORDER_EVENT_WEBHOOK_URL=http://consumer/orders\n")
+	if changed, err := w.Poll(context.Background()); !changed || err != nil {
+		t.Fatalf("Poll() = %v, %v; want true, nil", changed, err)
+	}
+	if len(applied) != 1 || applied[0].OrderEvents.Publisher != "webhook" || !reflect.DeepEqual(applied[0].OrderEvents.Webhook.URLs, []string{"http://consumer/orders"}) {
+		t.Errorf("applied %+v, want the webhook publisher", applied)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "invalid setting", content: "ORDER_EVENT_PUBLISHER=webhook\n", wantErr: "ORDER_EVENT_WEBHOOK_URL"},
+		{name: "not a publisher setting", content: "LOG_LEVEL=debug\nCART_ADDR=cart:7070\n", wantErr: "CART_ADDR, LOG_LEVEL cannot be reloaded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, path, tt.content)
+			if _, err := w.Poll(context.Background()); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Poll() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if changed, err := w.Poll(context.Background()); changed || err != nil {
+				t.Errorf("Poll() again = %v, %v; want the bad settings reported once", changed, err)
+			}
+		})
+	}
+
+	applyErr = errors.New("restart to change it")
+	writeFile(t, path, "ORDER_EVENT_PUBLISHER=spool\nORDER_EVENT_OUTBOX=true\n")
+	if _, err := w.Poll(context.Background()); !errors.Is(err, applyErr) || !strings.Contains(err.Error(), "ORDER_EVENT_OUTBOX, ORDER_EVENT_PUBLISHER") {
+		t.Errorf("Poll() = %v, want %v naming the changed settings", err, applyErr)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		io.WriteString(w, "ORDER_EVENT_PUBLISHER=spool\n")
+	}))
+	defer srv.Close()
+	source := HTTPSource{URL: srv.URL}
+
+	vars, err := source.Read(context.Background())
+	if err != nil || vars["ORDER_EVENT_PUBLISHER"] != "spool" {
+		t.Errorf("Read() = %v, %v; want the spool publisher", vars, err)
+	}
+	status = http.StatusNotFound
+	if _, err := source.Read(context.Background()); err == nil {
+		t.Error("Read() of a missing resource = nil, want an error")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package reload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+)
+
+// Source holds publisher settings as KEY=value lines.
+type Source interface {
+	// Read returns the variables of the source.
+	Read(ctx context.Context) (map[string]string, error)
+	String() string
+}
+
+// FileSource reads the settings from the file at its path, such as a mounted
+// ConfigMap.
+type FileSource string
+
+func (f FileSource) Read(ctx context.Context) (map[string]string, error) {
+	b, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, err
+	}
+	return ParseVariables(bytes.NewReader(b))
+}
+
+func (f FileSource) String() string {
+	return string(f)
+}
+
+// HTTPSource GETs the settings from URL, such as a configuration service.
+type HTTPSource struct {
+	URL string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+func (h HTTPSource) Read(ctx context.Context) (map[string]string, error) {
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", h.URL, resp.Status)
+	}
+	return ParseVariables(resp.Body)
+}
+
+func (h HTTPSource) String() string {
+	return h.URL
+}
+
+// ParseVariables parses KEY=value lines. Blank lines and lines starting with #
+// are skipped, and quotes around a value are removed.
+func ParseVariables(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value, got %q", n, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// NewSource returns the source cfg sets, nil if none is.
+func NewSource(cfg config.PublisherReload) Source {
+	switch {
+	case cfg.File != "":
+		return FileSource(cfg.File)
+	case cfg.URL != "":
+		return HTTPSource{URL: cfg.URL}
+	}
+	return nil
+}
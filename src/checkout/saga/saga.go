@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Step is one action of a saga. Compensate undoes Action and is nil for
+// steps that have nothing to undo.
+type Step struct {
+	Name       string
+	Action     func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+}
+
+// Error reports a saga that failed at Step. Compensations lists the outcome of
+// every compensation that ran, in the order they ran.
+type Error struct {
+	Step          string
+	Err           error
+	Compensations []Compensation
+}
+
+// Compensation is the outcome of compensating a completed step. Err is nil when
+// the step was undone.
+type Compensation struct {
+	Step string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Step, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Compensated reports whether every completed step was undone.
+func (e *Error) Compensated() bool {
+	for _, c := range e.Compensations {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Coordinator runs steps in order. When a step fails it compensates the steps
+// that completed before it, most recent first, and returns an *Error.
+type Coordinator struct {
+	steps []Step
+}
+
+// New creates a Coordinator for steps.
+func New(steps ...Step) *Coordinator {
+	return &Coordinator{steps: steps}
+}
+
+// Run runs the saga. Compensations run with a context that is not cancelled
+// with ctx, so that a caller giving up does not leave a step half undone.
+func (c *Coordinator) Run(ctx context.Context) error {
+	for i, step := range c.steps {
+		err := step.Action(ctx)
+		if err == nil {
+			continue
+		}
+		sagaErr := &Error{Step: step.Name, Err: err}
+		compensateCtx := context.WithoutCancel(ctx)
+		for j := i - 1; j >= 0; j-- {
+			if c.steps[j].Compensate == nil {
+				continue
+			}
+			sagaErr.Compensations = append(sagaErr.Compensations, Compensation{
+				Step: c.steps[j].Name,
+				Err:  c.steps[j].Compensate(compensateCtx),
+			})
+		}
+		return sagaErr
+	}
+	return nil
+}
+
+// FailedStep returns the step err failed at, or "" if err is not an *Error.
+func FailedStep(err error) string {
+	var sagaErr *Error
+	if errors.As(err, &sagaErr) {
+		return sagaErr.Step
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package saga
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCoordinatorRun(t *testing.T) {
+	errFailed := errors.New("step failed")
+	tests := []struct {
+		name          string
+		failAt        string
+		failUndo      string
+		wantCalls     []string
+		wantCompensed bool
+	}{
+		{
+			name:      "success",
+			wantCalls: []string{"charge", "ship", "notify"},
+		},
+		{
+			name:          "first step fails",
+			failAt:        "charge",
+			wantCalls:     []string{"charge"},
+			wantCompensed: true,
+		},
+		{
+			name:          "later step fails",
+			failAt:        "notify",
+			wantCalls:     []string{"charge", "ship", "notify", "undo ship", "undo charge"},
+			wantCompensed: true,
+		},
+		{
+			name:      "compensation fails",
+			failAt:    "notify",
+			failUndo:  "ship",
+			wantCalls: []string{"charge", "ship", "notify", "undo ship", "undo charge"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			step := func(name string) Step {
+				return Step{
+					Name: name,
+					Action: func(context.Context) error {
+						calls = append(calls, name)
+						if name == tt.failAt {
+							return errFailed
+						}
+						return nil
+					},
+					Compensate: func(context.Context) error {
+						calls = append(calls, "undo "+name)
+						if name == tt.failUndo {
+							return errFailed
+						}
+						return nil
+					},
+				}
+			}
+
+			err := New(step("charge"), step("ship"), step("notify")).Run(context.Background())
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.failAt == "" {
+				if err != nil {
+					t.Fatalf("Run() = %v, want nil", err)
+				}
+				return
+			}
+			var sagaErr *Error
+			if !errors.As(err, &sagaErr) || !errors.Is(err, errFailed) {
+				t.Fatalf("Run() = %v, want *Error wrapping the step error", err)
+			}
+			if FailedStep(err) != tt.failAt {
+				t.Errorf("FailedStep() = %q, want %q", FailedStep(err), tt.failAt)
+			}
+			if sagaErr.Compensated() != tt.wantCompensed {
+				t.Errorf("Compensated() = %v, want %v", sagaErr.Compensated(), tt.wantCompensed)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package sampling
+
+import (
+	"fmt"
+	"strconv"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+)
+
+// ParseSampler builds a sampler from the names and argument format of
+// OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG. An empty name selects the
+// SDK default, parentbased_always_on. In addition to the standard names it
+// accepts error_biased, which samples spans by trace ID ratio but always keeps
+// failed publish acknowledgments.
+func ParseSampler(name, arg string) (sdktrace.Sampler, error) {
+	ratio := 1.0
+	if arg != "" {
+		var err error
+		if ratio, err = strconv.ParseFloat(arg, 64); err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid sampler ratio %q, expected a number between 0 and 1", arg)
+		}
+	}
+
+	switch name {
+	case "", "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio)), nil
+	case "always_on":
+		return sdktrace.AlwaysSample(), nil
+	case "always_off":
+		return sdktrace.NeverSample(), nil
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(ratio), nil
+	case "error_biased":
+		return errorBiasedSampler{base: sdktrace.TraceIDRatioBased(ratio)}, nil
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", name)
+	}
+}
+
+// NewPublisherSampler returns a sampler that applies publisher to the spans of
+// the order event publisher (producer spans and acknowledgment spans) and
+// service to every other span. High-volume checkouts produce many identical
+// publish spans, which can then be sampled more aggressively than the rest of
+// the service.
+func NewPublisherSampler(publisher, service sdktrace.Sampler) sdktrace.Sampler {
+	return publisherSampler{publisher: publisher, service: service}
+}
+
+type publisherSampler struct {
+	publisher sdktrace.Sampler
+	service   sdktrace.Sampler
+}
+
+func (s publisherSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if isPublisherSpan(p) {
+		return s.publisher.ShouldSample(p)
+	}
+	return s.service.ShouldSample(p)
+}
+
+func (s publisherSampler) Description() string {
+	return fmt.Sprintf("PublisherSampler{publisher:%s,service:%s}", s.publisher.Description(), s.service.Description())
+}
+
+func isPublisherSpan(p sdktrace.SamplingParameters) bool {
+	if p.Kind == trace.SpanKindProducer {
+		return true
+	}
+	for _, kv := range p.Attributes {
+		if kv.Key == adapters.ProducerSuccessKey {
+			return true
+		}
+	}
+	return false
+}
+
+// errorBiasedSampler keeps every failed acknowledgment and defers to base for
+// all other spans.
+type errorBiasedSampler struct {
+	base sdktrace.Sampler
+}
+
+func (s errorBiasedSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	for _, kv := range p.Attributes {
+		if kv.Key == adapters.ProducerSuccessKey && !kv.Value.AsBool() {
+			return sdktrace.SamplingResult{
+				Decision:   sdktrace.RecordAndSample,
+				Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+			}
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+func (s errorBiasedSampler) Description() string {
+	return fmt.Sprintf("ErrorBiased{%s}", s.base.Description())
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package sampling
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+)
+
+func TestParseSampler(t *testing.T) {
+	tests := []struct {
+		name, arg string
+		want      string
+		wantErr   bool
+	}{
+		{"", "", "ParentBased{root:AlwaysOnSampler,remoteParentSampled:AlwaysOnSampler,remoteParentNotSampled:AlwaysOffSampler,localParentSampled:AlwaysOnSampler,localParentNotSampled:AlwaysOffSampler}", false},
+		{"always_off", "", "AlwaysOffSampler", false},
+		{"traceidratio", "0.25", "TraceIDRatioBased{0.25}", false},
+		{"error_biased", "0.1", "ErrorBiased{TraceIDRatioBased{0.1}}", false},
+		{"traceidratio", "1.5", "", true},
+		{"sometimes", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.arg, func(t *testing.T) {
+			got, err := ParseSampler(tt.name, tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSampler() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Description() != tt.want {
+				t.Errorf("ParseSampler() = %s, want %s", got.Description(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPublisherSampler(t *testing.T) {
+	publisher, err := ParseSampler("error_biased", "0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithSampler(NewPublisherSampler(publisher, sdktrace.AlwaysSample())),
+	)
+	tracer := tp.Tracer("test")
+
+	// The spans of a successful and a failed publish, as the Kafka adapter
+	// starts them
+	ctx, span := tracer.Start(context.Background(), "PlaceOrder")
+	for _, success := range []bool{true, false} {
+		_, publish := tracer.Start(ctx, "orders publish", trace.WithSpanKind(trace.SpanKindProducer))
+		publish.End()
+		_, ack := tracer.Start(ctx, "orders ack", trace.WithAttributes(adapters.ProducerSuccessKey.Bool(success)))
+		ack.End()
+	}
+	span.End()
+
+	got := map[string]int{}
+	for _, s := range recorder.Ended() {
+		got[s.Name()]++
+	}
+	want := map[string]int{"PlaceOrder": 1, "orders ack": 1}
+	if !maps.Equal(got, want) {
+		t.Errorf("recorded spans = %v, want %v (service span and the failed ack only)", got, want)
+	}
+}
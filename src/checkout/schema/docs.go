@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// defsRef prefixes references to the messages of a JSON Schema document.
+const defsRef = "#/$defs/"
+
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// JSONSchema renders a JSON Schema (draft 2020-12) describing the consumer JSON
+// format of root, as produced by serialization.ToConsumerJSON: camelCase field
+// names, every field present, and integers (including int64) as JSON numbers.
+func JSONSchema(root protoreflect.MessageDescriptor) ([]byte, error) {
+	defs := map[string]*jsonSchema{}
+	for _, md := range Dependencies(root) {
+		defs[string(md.Name())] = messageSchema(md, defsRef)
+	}
+
+	doc := &jsonSchema{
+		Schema: jsonSchemaDialect,
+		ID:     fmt.Sprintf("%s.schema.json", SnakeCase(string(root.Name()))),
+		Title:  string(root.FullName()),
+		Ref:    defsRef + string(root.Name()),
+		Defs:   defs,
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON schema: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// messageSchema describes md, referencing other messages as refPrefix followed
+// by their name.
+func messageSchema(md protoreflect.MessageDescriptor, refPrefix string) *jsonSchema {
+	// Consumers must tolerate fields added by newer producers
+	additional := true
+	s := &jsonSchema{
+		Type:                 "object",
+		Description:          fmt.Sprintf("Consumer JSON form of %s.", md.FullName()),
+		Properties:           map[string]*jsonSchema{},
+		AdditionalProperties: &additional,
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		prop := valueSchema(fd, refPrefix)
+		if fd.IsList() {
+			prop = &jsonSchema{Type: "array", Items: prop}
+		}
+		prop.Description = fmt.Sprintf("Proto field %s (%d).", fd.Name(), fd.Number())
+		s.Properties[fd.JSONName()] = prop
+		s.Required = append(s.Required, fd.JSONName())
+	}
+	return s
+}
+
+func valueSchema(fd protoreflect.FieldDescriptor, refPrefix string) *jsonSchema {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return &jsonSchema{Ref: refPrefix + string(fd.Message().Name())}
+	case protoreflect.BoolKind:
+		return &jsonSchema{Type: "boolean"}
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind:
+		return &jsonSchema{Type: "string"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return &jsonSchema{Type: "number"}
+	default:
+		return &jsonSchema{Type: "integer"}
+	}
+}
+
+// Markdown renders consumer-facing field tables for root and every message it
+// references.
+func Markdown(root protoreflect.MessageDescriptor) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", root.Name())
+	b.WriteString("<!-- Code generated by cmd/schemagen. DO NOT EDIT. -->\n\n")
+	fmt.Fprintf(&b, "Consumer JSON format of `%s`. Every field is always present; zero values are emitted explicitly.\n", root.FullName())
+
+	for _, md := range Dependencies(root) {
+		fmt.Fprintf(&b, "\n## %s\n\n", md.Name())
+		b.WriteString("| JSON field | Type | Proto field | Number |\n")
+		b.WriteString("|------------|------|-------------|--------|\n")
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			label := ""
+			if fd.IsList() {
+				label = "repeated "
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | `%s%s %s` | %d |\n", fd.JSONName(), markdownType(fd), label, fieldType(fd), fd.Name(), fd.Number())
+		}
+	}
+	return b.String()
+}
+
+func markdownType(fd protoreflect.FieldDescriptor) string {
+	var t string
+	if s := valueSchema(fd, defsRef); s.Ref != "" {
+		t = fmt.Sprintf("[%s](#%s)", fd.Message().Name(), strings.ToLower(string(fd.Message().Name())))
+	} else {
+		t = s.Type
+	}
+	if fd.IsList() {
+		return "array of " + t
+	}
+	return t
+}
+
+// Artifacts returns every consumer-facing schema artifact for root, keyed by
+// file name: the .proto definition, the JSON Schema and the markdown tables.
+func Artifacts(root protoreflect.MessageDescriptor) (map[string][]byte, error) {
+	base := SnakeCase(string(root.Name()))
+	jsonSchema, err := JSONSchema(root)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := ProtoDefinition(root)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		base + ".proto":       []byte(proto),
+		base + ".schema.json": jsonSchema,
+		base + ".md":          []byte(Markdown(root)),
+	}, nil
+}
+
+// SnakeCase converts a message name such as OrderResult to order_result.
+func SnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
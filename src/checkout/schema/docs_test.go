@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestArtifactsUpToDate fails when the committed schema artifacts no longer
+// match the compiled descriptors. Run `go generate` to refresh them.
+func TestArtifactsUpToDate(t *testing.T) {
+	artifacts, err := Artifacts(OrderResultDescriptor())
+	if err != nil {
+		t.Fatalf("Artifacts() = %v", err)
+	}
+	if artifacts[OpenAPIFile], err = OpenAPI(); err != nil {
+		t.Fatalf("OpenAPI() = %v", err)
+	}
+	artifacts[GraphQLFile] = []byte(GraphQLSchema())
+	for name, want := range artifacts {
+		got, err := os.ReadFile(filepath.Join("..", "schemas", name))
+		if err != nil {
+			t.Errorf("missing artifact %s: %v (run go generate)", name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("schemas/%s is out of date (run go generate)", name)
+		}
+	}
+}
+
+func TestJSONSchemaDescribesConsumerFormat(t *testing.T) {
+	data, err := JSONSchema(OrderResultDescriptor())
+	if err != nil {
+		t.Fatalf("JSONSchema() = %v", err)
+	}
+	var doc struct {
+		Ref  string `json:"$ref"`
+		Defs map[string]struct {
+			Properties map[string]struct {
+				Type string `json:"type"`
+				Ref  string `json:"$ref"`
+			} `json:"properties"`
+			Required []string `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("JSONSchema() is not valid JSON: %v", err)
+	}
+
+	if doc.Ref != "#/$defs/OrderResult" {
+		t.Errorf("$ref = %q", doc.Ref)
+	}
+	money := doc.Defs["Money"]
+	if money.Properties["units"].Type != "integer" {
+		t.Errorf("Money.units type = %q, want integer", money.Properties["units"].Type)
+	}
+	if money.Properties["currencyCode"].Type != "string" {
+		t.Errorf("Money.currencyCode type = %q, want string", money.Properties["currencyCode"].Type)
+	}
+	if got := doc.Defs["OrderResult"].Properties["shippingCost"].Ref; got != "#/$defs/Money" {
+		t.Errorf("OrderResult.shippingCost $ref = %q", got)
+	}
+	if len(doc.Defs["OrderResult"].Required) != 5 {
+		t.Errorf("OrderResult required = %v, want all 5 fields", doc.Defs["OrderResult"].Required)
+	}
+}
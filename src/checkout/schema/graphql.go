@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// GraphQLFile is the file name of the GraphQL schema among the artifacts.
+const GraphQLFile = "checkout.graphql"
+
+// GraphQLInputSuffix is appended to a message name to name its input type.
+const GraphQLInputSuffix = "Input"
+
+// graphQLRoots is the part of the schema that is not derived from messages.
+const graphQLRoots = `"""
+64-bit integer, serialized as a JSON number like in the consumer JSON format.
+"""
+scalar Int64
+
+type Query {
+  "Returns an order the user placed (CheckoutService.GetOrder)."
+  order(userId: String!, orderId: String!): OrderResult
+  "Returns a page of the orders the user placed, newest first (CheckoutService.ListOrders)."
+  orders(userId: String!, pageSize: Int, pageToken: String): ListOrdersResponse!
+}
+
+type Mutation {
+  """
+  Places an order (CheckoutService.PlaceOrder). A retried call with the same
+  idempotencyKey returns the original order. With async, the order only carries
+  its ID and is completed in the background.
+  """
+  placeOrder(input: PlaceOrderRequestInput!, idempotencyKey: String, async: Boolean): OrderResult!
+}
+`
+
+// GraphQLSchema renders the GraphQL schema of the checkout service in SDL.
+// Object types are the messages returned by the queries and mutations, with
+// the field names of the consumer JSON format. Input types are the messages
+// they take, named with GraphQLInputSuffix.
+func GraphQLSchema() string {
+	var b strings.Builder
+	b.WriteString("# Code generated by cmd/schemagen. DO NOT EDIT.\n\n")
+	b.WriteString(graphQLRoots)
+
+	seen := map[protoreflect.FullName]bool{}
+	for _, root := range []protoreflect.MessageDescriptor{
+		OrderResultDescriptor(),
+		(&pb.ListOrdersResponse{}).ProtoReflect().Descriptor(),
+	} {
+		for _, md := range Dependencies(root) {
+			if !seen[md.FullName()] {
+				seen[md.FullName()] = true
+				writeGraphQLType(&b, md, false)
+			}
+		}
+	}
+	for _, md := range Dependencies((&pb.PlaceOrderRequest{}).ProtoReflect().Descriptor()) {
+		writeGraphQLType(&b, md, true)
+	}
+	return b.String()
+}
+
+func writeGraphQLType(b *strings.Builder, md protoreflect.MessageDescriptor, input bool) {
+	keyword, name := "type", string(md.Name())
+	if input {
+		keyword, name = "input", name+GraphQLInputSuffix
+	}
+	fmt.Fprintf(b, "\n\"Proto message %s.\"\n%s %s {\n", md.FullName(), keyword, name)
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fmt.Fprintf(b, "  %s: %s\n", fd.JSONName(), GraphQLType(fd, input))
+	}
+	b.WriteString("}\n")
+}
+
+// GraphQLType returns the GraphQL type of fd. Scalars of object types are
+// non-null because the consumer JSON format always sets them, while input
+// fields are optional like in proto3.
+func GraphQLType(fd protoreflect.FieldDescriptor, input bool) string {
+	var t string
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		t = string(fd.Message().Name())
+		if input {
+			t += GraphQLInputSuffix
+		}
+	case protoreflect.BoolKind:
+		t = "Boolean"
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind:
+		t = "String"
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		t = "Float"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		t = "Int"
+	default:
+		// GraphQL Int is 32-bit signed
+		t = "Int64"
+	}
+	isMessage := fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+	if fd.IsList() {
+		t = "[" + t + "!]"
+		if !input {
+			t += "!"
+		}
+	} else if !input && !isMessage {
+		t += "!"
+	}
+	return t
+}
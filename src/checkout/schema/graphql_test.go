@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+)
+
+func TestGraphQLSchema(t *testing.T) {
+	sdl := GraphQLSchema()
+
+	for _, want := range []string{
+		"type OrderResult {\n  orderId: String!\n",
+		"  items: [OrderItem!]!\n",
+		"  shippingCost: Money\n",
+		// GraphQL Int is 32-bit
+		"  units: Int64!\n",
+		"  nanos: Int!\n",
+		"type ListOrdersResponse {\n  orders: [OrderResult!]!\n",
+		// Input fields are optional like in proto3
+		"input PlaceOrderRequestInput {\n  userId: String\n",
+		"  creditCard: CreditCardInfoInput\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("GraphQLSchema() does not contain %q", want)
+		}
+	}
+	// Messages shared by several roots are declared once
+	if n := strings.Count(sdl, "type Money {"); n != 1 {
+		t.Errorf("GraphQLSchema() declares Money %d times, want 1", n)
+	}
+}
+
+// TestGraphQLSchemaMatchesGateway checks that the gateway executes the schema
+// of the SDL: the same types, with the same fields and arguments.
+func TestGraphQLSchemaMatchesGateway(t *testing.T) {
+	doc, err := parser.Parse(parser.ParseParams{Source: GraphQLSchema()})
+	if err != nil {
+		t.Fatalf("GraphQLSchema() does not parse: %v", err)
+	}
+	gateway, err := adapters.GraphQLCheckoutSchema()
+	if err != nil {
+		t.Fatalf("GraphQLCheckoutSchema() = %v", err)
+	}
+
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.ObjectDefinition:
+			object, ok := gateway.Type(def.Name.Value).(*graphql.Object)
+			if !ok {
+				t.Errorf("gateway has no object type %s", def.Name.Value)
+				continue
+			}
+			fields := object.Fields()
+			if len(fields) != len(def.Fields) {
+				t.Errorf("gateway type %s has %d fields, want %d", def.Name.Value, len(fields), len(def.Fields))
+			}
+			for _, f := range def.Fields {
+				field, ok := fields[f.Name.Value]
+				if !ok {
+					t.Errorf("gateway type %s has no field %s", def.Name.Value, f.Name.Value)
+					continue
+				}
+				if got, want := field.Type.String(), graphQLTypeString(f.Type); got != want {
+					t.Errorf("gateway field %s.%s is %s, want %s", def.Name.Value, f.Name.Value, got, want)
+				}
+				if len(field.Args) != len(f.Arguments) {
+					t.Errorf("gateway field %s.%s has %d arguments, want %d", def.Name.Value, f.Name.Value, len(field.Args), len(f.Arguments))
+				}
+				for _, arg := range f.Arguments {
+					if !hasArgument(field.Args, arg.Name.Value, graphQLTypeString(arg.Type)) {
+						t.Errorf("gateway field %s.%s has no argument %s: %s", def.Name.Value, f.Name.Value, arg.Name.Value, graphQLTypeString(arg.Type))
+					}
+				}
+			}
+		case *ast.InputObjectDefinition:
+			input, ok := gateway.Type(def.Name.Value).(*graphql.InputObject)
+			if !ok {
+				t.Errorf("gateway has no input type %s", def.Name.Value)
+				continue
+			}
+			fields := input.Fields()
+			if len(fields) != len(def.Fields) {
+				t.Errorf("gateway input %s has %d fields, want %d", def.Name.Value, len(fields), len(def.Fields))
+			}
+			for _, f := range def.Fields {
+				field, ok := fields[f.Name.Value]
+				if !ok {
+					t.Errorf("gateway input %s has no field %s", def.Name.Value, f.Name.Value)
+				} else if got, want := field.Type.String(), graphQLTypeString(f.Type); got != want {
+					t.Errorf("gateway input field %s.%s is %s, want %s", def.Name.Value, f.Name.Value, got, want)
+				}
+			}
+		case *ast.ScalarDefinition:
+			if _, ok := gateway.Type(def.Name.Value).(*graphql.Scalar); !ok {
+				t.Errorf("gateway has no scalar %s", def.Name.Value)
+			}
+		}
+	}
+}
+
+// graphQLTypeString returns a type of the SDL as graphql-go prints types.
+func graphQLTypeString(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return graphQLTypeString(t.Type) + "!"
+	case *ast.List:
+		return "[" + graphQLTypeString(t.Type) + "]"
+	case *ast.Named:
+		return t.Name.Value
+	}
+	return ""
+}
+
+func hasArgument(args []*graphql.Argument, name, typ string) bool {
+	for _, arg := range args {
+		if arg.Name() == name && arg.Type.String() == typ {
+			return true
+		}
+	}
+	return false
+}
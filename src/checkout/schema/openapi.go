@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// componentsRef prefixes references to the schemas of an OpenAPI document.
+const componentsRef = "#/components/schemas/"
+
+// OpenAPIFile is the file name of the OpenAPI document among the artifacts.
+const OpenAPIFile = "openapi.json"
+
+type openAPIDocument struct {
+	OpenAPI           string                          `json:"openapi"`
+	JSONSchemaDialect string                          `json:"jsonSchemaDialect"`
+	Info              openAPIInfo                     `json:"info"`
+	Paths             map[string]map[string]operation `json:"paths"`
+	Components        openAPIComponents               `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*jsonSchema `json:"schemas"`
+}
+
+type operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]response `json:"responses"`
+}
+
+type parameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required"`
+	Schema      *jsonSchema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Headers     map[string]header    `json:"headers,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type header struct {
+	Description string      `json:"description"`
+	Schema      *jsonSchema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *jsonSchema `json:"schema"`
+}
+
+// jsonContent is a JSON body of the named component schema.
+func jsonContent(name string) map[string]mediaType {
+	return map[string]mediaType{"application/json": {Schema: &jsonSchema{Ref: componentsRef + name}}}
+}
+
+// errorResponse is a response carrying an Error body.
+func errorResponse(description string) response {
+	return response{Description: description, Content: jsonContent("Error")}
+}
+
+// OpenAPI renders the OpenAPI 3.1 document of the HTTP facade of the checkout
+// service. Message schemas are the consumer JSON format described by
+// JSONSchema, derived from the compiled descriptors.
+func OpenAPI() ([]byte, error) {
+	schemas := map[string]*jsonSchema{
+		"Error": {
+			Type:        "object",
+			Description: "Error returned by every operation, carrying the gRPC status of the failed call.",
+			Properties: map[string]*jsonSchema{
+				"code":    {Type: "string", Description: "gRPC status code name, e.g. NOT_FOUND."},
+				"message": {Type: "string", Description: "Human readable description of the error."},
+				"violations": {
+					Type:        "array",
+					Description: "Fields of an INVALID_ARGUMENT request or order that failed validation.",
+					Items: &jsonSchema{
+						Type: "object",
+						Properties: map[string]*jsonSchema{
+							"field":   {Type: "string", Description: "Proto field path, e.g. address.city."},
+							"rule":    {Type: "string", Description: "Identifier of the failed constraint, e.g. required."},
+							"message": {Type: "string", Description: "Human readable description of the violation."},
+						},
+						Required: []string{"field", "rule", "message"},
+					},
+				},
+			},
+			Required: []string{"code", "message"},
+		},
+	}
+	for _, root := range []protoreflect.MessageDescriptor{
+		(&pb.PlaceOrderRequest{}).ProtoReflect().Descriptor(),
+		OrderResultDescriptor(),
+	} {
+		for _, md := range Dependencies(root) {
+			schemas[string(md.Name())] = messageSchema(md, componentsRef)
+		}
+	}
+
+	doc := openAPIDocument{
+		OpenAPI:           "3.1.0",
+		JSONSchemaDialect: jsonSchemaDialect,
+		Info: openAPIInfo{
+			Title:       "Checkout",
+			Description: "HTTP facade of oteldemo.CheckoutService for clients that cannot use gRPC.",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]map[string]operation{
+			"/orders": {
+				"post": {
+					OperationID: "placeOrder",
+					Summary:     "Places an order (CheckoutService.PlaceOrder).",
+					Parameters: []parameter{
+						{
+							Name:        "Idempotency-Key",
+							In:          "header",
+							Description: "Client-supplied request ID. A retried request with the same key returns the original order.",
+							Schema:      &jsonSchema{Type: "string"},
+						},
+						{
+							Name:        "Place-Order-Mode",
+							In:          "header",
+							Description: "async to return as soon as the order is accepted. The response then carries only the order ID.",
+							Schema:      &jsonSchema{Type: "string"},
+						},
+						{
+							Name:        "Shipping-Method",
+							In:          "header",
+							Description: "Shipping provider of the order, e.g. standard or express. Defaults to standard.",
+							Schema:      &jsonSchema{Type: "string"},
+						},
+						{
+							Name:        "Shipping-Insurance",
+							In:          "header",
+							Description: "true to insure the shipment. Insurance costs a percentage of the items and is added to the shipping cost.",
+							Schema:      &jsonSchema{Type: "string"},
+						},
+						{
+							Name:        "Gift-Card",
+							In:          "header",
+							Description: "Code of a gift card paying for the order before the card. The card is charged what the gift card does not cover.",
+							Schema:      &jsonSchema{Type: "string"},
+						},
+					},
+					RequestBody: &requestBody{Required: true, Content: jsonContent("PlaceOrderRequest")},
+					Responses: map[string]response{
+						"201": {
+							Description: "The order was placed.",
+							Headers: map[string]header{
+								"Location": {Description: "Path of the order.", Schema: &jsonSchema{Type: "string"}},
+							},
+							Content: jsonContent("OrderResult"),
+						},
+						"400": errorResponse("The request is malformed or invalid."),
+						"409": errorResponse("A request with the same idempotency key is in progress."),
+						"429": errorResponse("Too many orders are pending."),
+						"500": errorResponse("The order could not be placed."),
+						"503": errorResponse("A downstream service is unavailable."),
+					},
+				},
+			},
+			"/orders/{orderId}": {
+				"get": {
+					OperationID: "getOrder",
+					Summary:     "Returns an order the user placed (CheckoutService.GetOrder).",
+					Parameters: []parameter{
+						{Name: "orderId", In: "path", Description: "ID of the order.", Required: true, Schema: &jsonSchema{Type: "string"}},
+						{Name: "userId", In: "query", Description: "ID of the user who placed the order.", Required: true, Schema: &jsonSchema{Type: "string"}},
+					},
+					Responses: map[string]response{
+						"200": {Description: "The order.", Content: jsonContent("OrderResult")},
+						"400": errorResponse("The user ID is missing."),
+						"404": errorResponse("The user placed no order with this ID."),
+						"503": errorResponse("The order repository is unavailable."),
+					},
+				},
+			},
+		},
+		Components: openAPIComponents{Schemas: schemas},
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenAPI document: %w", err)
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	data, err := OpenAPI()
+	if err != nil {
+		t.Fatalf("OpenAPI() = %v", err)
+	}
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Ref string `json:"$ref"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]json.RawMessage `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("OpenAPI() is not valid JSON: %v", err)
+	}
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q, want 3.1.0", doc.OpenAPI)
+	}
+	post := doc.Paths["/orders"]["post"]
+	if got := post.RequestBody.Content["application/json"].Schema.Ref; got != "#/components/schemas/PlaceOrderRequest" {
+		t.Errorf("POST /orders request body $ref = %q", got)
+	}
+	if _, ok := post.Responses["201"]; !ok {
+		t.Error("POST /orders has no 201 response")
+	}
+	if _, ok := doc.Paths["/orders/{orderId}"]["get"].Responses["404"]; !ok {
+		t.Error("GET /orders/{orderId} has no 404 response")
+	}
+	// Every message referenced by the request and the order is a component,
+	// and references resolve within the document
+	for _, name := range []string{"PlaceOrderRequest", "CreditCardInfo", "OrderResult", "OrderItem", "Money", "Address", "Error"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("missing component schema %s", name)
+		}
+	}
+	if strings.Contains(string(data), "#/$defs/") {
+		t.Error("OpenAPI() references JSON Schema $defs instead of components")
+	}
+}
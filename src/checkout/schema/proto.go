@@ -0,0 +1,237 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// OrderResultDescriptor is the descriptor of the message published on the
+// order topic.
+func OrderResultDescriptor() protoreflect.MessageDescriptor {
+	return (&pb.OrderResult{}).ProtoReflect().Descriptor()
+}
+
+// OrderResultProto renders a self-contained .proto definition of OrderResult
+// and every message it references, suitable for registering with a schema
+// registry. It is derived from the compiled descriptors so it always matches
+// the code that serializes the messages.
+func OrderResultProto() (string, error) {
+	return ProtoDefinition(OrderResultDescriptor())
+}
+
+// ProtoDefinition renders root and the messages and enums it transitively
+// references as a proto3 file. The types of the file of root are emitted in
+// the order they are declared in it, nested ones inside their parent, so that
+// the output is deterministic; the types of other files, such as
+// google.protobuf.Timestamp, are imported. It fails for the descriptors it
+// cannot render: files other than proto3, and oneofs.
+func ProtoDefinition(root protoreflect.MessageDescriptor) (string, error) {
+	file := root.ParentFile()
+	if file.Syntax() != protoreflect.Proto3 {
+		return "", fmt.Errorf("%s: cannot render a %s file", file.Path(), file.Syntax())
+	}
+	deps := dependencies(root)
+
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n")
+	if file.Package() != "" {
+		fmt.Fprintf(&b, "\npackage %s;\n", file.Package())
+	}
+	if len(deps.imports) > 0 {
+		b.WriteString("\n")
+		for _, path := range deps.imports {
+			fmt.Fprintf(&b, "import %q;\n", path)
+		}
+	}
+	r := protoRenderer{b: &b, deps: deps, pkg: file.Package()}
+	enums := file.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		if ed := enums.Get(i); deps.types[ed.FullName()] {
+			b.WriteString("\n")
+			r.enum(ed, "")
+		}
+	}
+	messages := file.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		if md := messages.Get(i); deps.types[md.FullName()] {
+			b.WriteString("\n")
+			if err := r.message(md, ""); err != nil {
+				return "", err
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// Dependencies returns root and every message of its file it transitively
+// references, in source declaration order, nested messages after their
+// parent. The entries of map fields are not messages of the file.
+func Dependencies(root protoreflect.MessageDescriptor) []protoreflect.MessageDescriptor {
+	deps := dependencies(root)
+	var out []protoreflect.MessageDescriptor
+	var walk func(messages protoreflect.MessageDescriptors)
+	walk = func(messages protoreflect.MessageDescriptors) {
+		for i := 0; i < messages.Len(); i++ {
+			if md := messages.Get(i); deps.types[md.FullName()] {
+				out = append(out, md)
+				walk(md.Messages())
+			}
+		}
+	}
+	walk(root.ParentFile().Messages())
+	return out
+}
+
+// protoDependencies are the types a message transitively references.
+type protoDependencies struct {
+	// types are the messages and enums of the file of the root, with the
+	// messages that enclose them
+	types map[protoreflect.FullName]bool
+	// imports are the paths of the other files, sorted
+	imports []string
+}
+
+func dependencies(root protoreflect.MessageDescriptor) protoDependencies {
+	file := root.ParentFile().Path()
+	deps := protoDependencies{types: map[protoreflect.FullName]bool{}}
+	var visit func(d protoreflect.Descriptor)
+	visit = func(d protoreflect.Descriptor) {
+		if deps.types[d.FullName()] {
+			return
+		}
+		if path := d.ParentFile().Path(); path != file {
+			if !slices.Contains(deps.imports, path) {
+				deps.imports = append(deps.imports, path)
+			}
+			return
+		}
+		deps.types[d.FullName()] = true
+		if parent, ok := d.Parent().(protoreflect.MessageDescriptor); ok {
+			visit(parent)
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return
+		}
+		fields := md.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fd := fields.Get(i)
+			if fd.IsMap() {
+				// Map entries are rendered as map fields
+				fd = fd.MapValue()
+			}
+			if m := fd.Message(); m != nil {
+				visit(m)
+			} else if e := fd.Enum(); e != nil {
+				visit(e)
+			}
+		}
+	}
+	visit(root)
+	slices.Sort(deps.imports)
+	return deps
+}
+
+// protoRenderer writes the declarations of the dependencies of a message.
+type protoRenderer struct {
+	b    *strings.Builder
+	deps protoDependencies
+	// pkg is the package of the file, which the names of its types omit
+	pkg protoreflect.FullName
+}
+
+func (r protoRenderer) message(md protoreflect.MessageDescriptor, indent string) error {
+	fmt.Fprintf(r.b, "%smessage %s {\n", indent, md.Name())
+	enums := md.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		if ed := enums.Get(i); r.deps.types[ed.FullName()] {
+			r.enum(ed, indent+"  ")
+		}
+	}
+	messages := md.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		if nested := messages.Get(i); r.deps.types[nested.FullName()] {
+			if err := r.message(nested, indent+"  "); err != nil {
+				return err
+			}
+		}
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+			return fmt.Errorf("%s: cannot render oneof %s", md.FullName(), od.Name())
+		}
+		label := ""
+		switch {
+		case fd.IsList():
+			label = "repeated "
+		case fd.HasOptionalKeyword():
+			label = "optional "
+		}
+		fmt.Fprintf(r.b, "%s  %s%s %s = %d;\n", indent, label, r.protoType(fd), fd.Name(), fd.Number())
+	}
+	fmt.Fprintf(r.b, "%s}\n", indent)
+	return nil
+}
+
+func (r protoRenderer) enum(ed protoreflect.EnumDescriptor, indent string) {
+	fmt.Fprintf(r.b, "%senum %s {\n", indent, ed.Name())
+	values := ed.Values()
+	numbers := map[protoreflect.EnumNumber]bool{}
+	for i := 0; i < values.Len(); i++ {
+		n := values.Get(i).Number()
+		if numbers[n] {
+			fmt.Fprintf(r.b, "%s  option allow_alias = true;\n", indent)
+			break
+		}
+		numbers[n] = true
+	}
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		fmt.Fprintf(r.b, "%s  %s = %d;\n", indent, v.Name(), v.Number())
+	}
+	fmt.Fprintf(r.b, "%s}\n", indent)
+}
+
+func (r protoRenderer) protoType(fd protoreflect.FieldDescriptor) string {
+	if fd.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", r.protoType(fd.MapKey()), r.protoType(fd.MapValue()))
+	}
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return r.typeName(fd.Message())
+	case protoreflect.EnumKind:
+		return r.typeName(fd.Enum())
+	default:
+		return fd.Kind().String()
+	}
+}
+
+// typeName returns the name of a message or enum as the file refers to it:
+// relative to its package for its own types, such as Field.Kind, and fully
+// qualified for imported ones, such as .google.protobuf.Any.
+func (r protoRenderer) typeName(d protoreflect.Descriptor) string {
+	if r.deps.types[d.FullName()] {
+		return strings.TrimPrefix(string(d.FullName()), string(r.pkg)+".")
+	}
+	return "." + string(d.FullName())
+}
+
+func fieldType(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(fd.Message().Name())
+	case protoreflect.EnumKind:
+		return string(fd.Enum().Name())
+	default:
+		return fd.Kind().String()
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+func TestOrderResultProto(t *testing.T) {
+	got, err := OrderResultProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"syntax = \"proto3\";",
+		"package oteldemo;",
+		"message CartItem {\n  string product_id = 1;\n  int32 quantity = 2;\n}",
+		"message OrderResult {",
+		"  Money shipping_cost = 3;",
+		"  repeated OrderItem items = 5;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("OrderResultProto() missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "message PlaceOrderRequest") {
+		t.Error("OrderResultProto() includes messages OrderResult does not reference")
+	}
+}
+
+func TestProtoDefinitionEnumsAndImports(t *testing.T) {
+	// google.protobuf.Type references a top-level enum, the enums nested in
+	// Field, and messages of two other files
+	got, err := ProtoDefinition((&typepb.Type{}).ProtoReflect().Descriptor())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"import \"google/protobuf/any.proto\";\nimport \"google/protobuf/source_context.proto\";\n",
+		"enum Syntax {\n  SYNTAX_PROTO2 = 0;\n  SYNTAX_PROTO3 = 1;\n",
+		"  Syntax syntax = 6;",
+		"message Field {\n  enum Kind {\n    TYPE_UNKNOWN = 0;\n",
+		"  enum Cardinality {\n",
+		"  Field.Kind kind = 1;",
+		"  .google.protobuf.SourceContext source_context = 5;",
+		"  .google.protobuf.Any value = 2;",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ProtoDefinition(Type) missing %q\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"message Any {", "message Enum {"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("ProtoDefinition(Type) includes %q\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestProtoDefinitionRejectsOneofs(t *testing.T) {
+	_, err := ProtoDefinition((&structpb.Value{}).ProtoReflect().Descriptor())
+	if err == nil || !strings.Contains(err.Error(), "oneof kind") {
+		t.Errorf("ProtoDefinition(Value) = %v, want a oneof error", err)
+	}
+}
+
+func TestDependenciesOrder(t *testing.T) {
+	var names []string
+	for _, md := range Dependencies(OrderResultDescriptor()) {
+		names = append(names, string(md.Name()))
+	}
+	want := "CartItem,Address,Money,OrderItem,OrderResult"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("Dependencies() = %s, want %s", got, want)
+	}
+}
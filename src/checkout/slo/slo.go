@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package slo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram. Percentiles
+// are estimated as the upper bound of the bucket they fall in, like
+// histogram_quantile does for the messaging.publish.duration metric.
+var latencyBuckets = [...]time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond,
+	50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// slots is the number of sub-windows the rolling window is split into. Older
+// observations expire one slot at a time.
+const slots = 10
+
+// Objective is the publish service level objective.
+type Objective struct {
+	// Window is the rolling window observations are evaluated over. It
+	// defaults to DefaultWindow.
+	Window time.Duration
+	// SuccessRate is the minimum fraction of successful publishes, from 0 to 1.
+	// Zero disables the success rate objective.
+	SuccessRate float64
+	// Percentile, from 0 to 1, of publishes that must be acknowledged within
+	// Latency. A zero Latency disables the latency objective.
+	Percentile float64
+	Latency    time.Duration
+}
+
+// Status summarizes the publishes observed in the current window.
+type Status struct {
+	Total       uint64        `json:"total"`
+	Failed      uint64        `json:"failed"`
+	SuccessRate float64       `json:"success_rate"`
+	P50         time.Duration `json:"p50"`
+	P95         time.Duration `json:"p95"`
+	P99         time.Duration `json:"p99"`
+}
+
+type slot struct {
+	start  time.Time
+	total  uint64
+	failed uint64
+	// buckets counts observations per latency bucket, the last one
+	// holding those beyond the largest bound
+	buckets [len(latencyBuckets) + 1]uint64
+}
+
+// Tracker computes a rolling publish success rate and latency percentiles and
+// evaluates them against an Objective.
+type Tracker struct {
+	objective Objective
+	now       func() time.Time
+
+	mu    sync.Mutex
+	slots [slots]slot
+}
+
+// DefaultWindow is the rolling window of an Objective that does not set one.
+const DefaultWindow = 5 * time.Minute
+
+// NewTracker creates a Tracker for objective.
+func NewTracker(objective Objective) *Tracker {
+	if objective.Window <= 0 {
+		objective.Window = DefaultWindow
+	}
+	return &Tracker{objective: objective, now: time.Now}
+}
+
+// ObservePublish records the outcome of one publish. A nil err is a success.
+func (t *Tracker) ObservePublish(duration time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.current()
+	s.total++
+	if err != nil {
+		s.failed++
+	}
+	s.buckets[sort.Search(len(latencyBuckets), func(i int) bool { return duration <= latencyBuckets[i] })]++
+}
+
+// current returns the slot for now, resetting it if it holds observations
+// from an earlier window. It must be called with t.mu held.
+func (t *Tracker) current() *slot {
+	width := t.objective.Window / slots
+	start := t.now().Truncate(width)
+	s := &t.slots[int(start.UnixNano()/int64(width))%slots]
+	if !s.start.Equal(start) {
+		*s = slot{start: start}
+	}
+	return s
+}
+
+// Status returns the success rate and latency percentiles of the current
+// window. The success rate is 1 when nothing was published.
+func (t *Tracker) Status() Status {
+	total, failed, buckets := t.window()
+	status := Status{Total: total, Failed: failed, SuccessRate: 1}
+	if total == 0 {
+		return status
+	}
+	status.SuccessRate = float64(total-failed) / float64(total)
+	status.P50 = percentile(buckets, total, 0.50)
+	status.P95 = percentile(buckets, total, 0.95)
+	status.P99 = percentile(buckets, total, 0.99)
+	return status
+}
+
+// WithinSLO reports whether the current window meets the objective.
+func (t *Tracker) WithinSLO() bool {
+	return t.Check(context.Background()) == nil
+}
+
+// Check returns an error describing the breach when the current window does
+// not meet the objective, so that it can be registered as a readiness check.
+func (t *Tracker) Check(context.Context) error {
+	total, failed, buckets := t.window()
+	if total == 0 {
+		return nil
+	}
+	if rate := float64(total-failed) / float64(total); rate < t.objective.SuccessRate {
+		return fmt.Errorf("publish success rate %.4f is below the objective of %.4f", rate, t.objective.SuccessRate)
+	}
+	if t.objective.Latency > 0 {
+		if latency := percentile(buckets, total, t.objective.Percentile); latency > t.objective.Latency {
+			return fmt.Errorf("p%g publish latency %s exceeds the objective of %s", t.objective.Percentile*100, latency, t.objective.Latency)
+		}
+	}
+	return nil
+}
+
+// window aggregates the slots of the current window.
+func (t *Tracker) window() (total, failed uint64, buckets []uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buckets = make([]uint64, len(latencyBuckets)+1)
+	cutoff := t.now().Add(-t.objective.Window)
+	for _, s := range t.slots {
+		if !s.start.After(cutoff) {
+			continue
+		}
+		total += s.total
+		failed += s.failed
+		for i, n := range s.buckets {
+			buckets[i] += n
+		}
+	}
+	return total, failed, buckets
+}
+
+// percentile returns the upper bound of the bucket holding the q-th quantile
+// of total observations. Observations beyond the last bound report it.
+func percentile(buckets []uint64, total uint64, q float64) time.Duration {
+	rank := max(uint64(math.Ceil(q*float64(total))), 1)
+	var seen uint64
+	for i, n := range buckets {
+		seen += n
+		if seen >= rank {
+			if i >= len(latencyBuckets) {
+				break
+			}
+			return latencyBuckets[i]
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1]
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package slo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func newTestTracker(objective Objective) (*Tracker, *testdata.Clock) {
+	clock := testdata.NewClock()
+	t := NewTracker(objective)
+	t.now = clock.Now
+	return t, clock
+}
+
+func TestTrackerStatus(t *testing.T) {
+	tracker, _ := newTestTracker(Objective{Window: time.Minute})
+	for i := range 100 {
+		var err error
+		if i < 2 {
+			err = errors.New("broker unavailable")
+		}
+		duration := 3 * time.Millisecond
+		if i >= 96 {
+			duration = 2 * time.Second
+		}
+		tracker.ObservePublish(duration, err)
+	}
+
+	want := Status{
+		Total:       100,
+		Failed:      2,
+		SuccessRate: 0.98,
+		P50:         5 * time.Millisecond,
+		P95:         5 * time.Millisecond,
+		P99:         2500 * time.Millisecond,
+	}
+	if got := tracker.Status(); got != want {
+		t.Errorf("Status() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTrackerWithinSLO(t *testing.T) {
+	objective := Objective{Window: time.Minute, SuccessRate: 0.99, Percentile: 0.95, Latency: 100 * time.Millisecond}
+	tests := []struct {
+		name     string
+		failures int
+		slow     int
+		want     bool
+	}{
+		{name: "no publishes", want: true},
+		{name: "healthy", failures: 1, slow: 5, want: true},
+		{name: "too many failures", failures: 2, want: false},
+		{name: "too slow", slow: 6, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, _ := newTestTracker(objective)
+			if tt.name != "no publishes" {
+				for i := range 100 {
+					var err error
+					if i < tt.failures {
+						err = errors.New("broker unavailable")
+					}
+					duration := 10 * time.Millisecond
+					if i >= 100-tt.slow {
+						duration = time.Second
+					}
+					tracker.ObservePublish(duration, err)
+				}
+			}
+			if got := tracker.WithinSLO(); got != tt.want {
+				t.Errorf("WithinSLO() = %v, want %v (Check() = %v)", got, tt.want, tracker.Check(context.Background()))
+			}
+		})
+	}
+}
+
+func TestTrackerWindowExpires(t *testing.T) {
+	tracker, clock := newTestTracker(Objective{Window: time.Minute, SuccessRate: 0.99})
+	tracker.ObservePublish(time.Millisecond, errors.New("broker unavailable"))
+	if tracker.WithinSLO() {
+		t.Fatal("WithinSLO() = true after a failed publish, want false")
+	}
+
+	clock.Advance(30 * time.Second)
+	tracker.ObservePublish(time.Millisecond, nil)
+	if got := tracker.Status().Total; got != 2 {
+		t.Errorf("Status().Total = %d within the window, want 2", got)
+	}
+
+	clock.Advance(45 * time.Second)
+	if got := tracker.Status(); got.Total != 1 || got.Failed != 0 {
+		t.Errorf("Status() = %+v after the failure expired, want 1 successful publish", got)
+	}
+	if !tracker.WithinSLO() {
+		t.Error("WithinSLO() = false after the failure expired, want true")
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package soak holds the long-running tests of the order event pipeline. They
+// publish continuously while a ChaosOrderEventPublisher injects broker
+// failures and outages, and check that no event is lost and that memory stays
+// bounded. The tests are built with the soak tag:
+//
+//	SOAK_DURATION=10m go test -tags soak -timeout 0 ./soak
+//
+// Each test publishes for SOAK_DURATION, 30s by default.
+package soak
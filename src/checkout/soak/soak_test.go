@@ -0,0 +1,295 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build soak
+
+package soak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkout/wiring"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+const (
+	// rate is the number of orders published per second
+	rate = 500
+	// failureRate is the share of publishes failed at random
+	failureRate = 0.01
+	// outagePeriod and outage schedule the broker outages: the broker is
+	// down for outage at the start of every outagePeriod
+	outagePeriod = time.Second
+	outage       = 300 * time.Millisecond
+)
+
+// TestSoakFallbackToSpool publishes through the fallback publisher with a
+// spool, as configured with ORDER_EVENT_FALLBACK=spool. Orders published
+// during an outage are spooled and replayed once the broker is back.
+func TestSoakFallbackToSpool(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	b := &broker{}
+	down := startOutages(t)
+	primary := adapters.NewChaosOrderEventPublisher(b, failureRate, adapters.WithOutage(down.Load))
+	spool := adapters.NewSpoolOrderEventPublisher(filepath.Join(t.TempDir(), "orders.spool"), logger)
+	fallback := adapters.NewFallbackOrderEventPublisher(primary, spool, logger, adapters.WithHealthCheck(func(context.Context) error {
+		if down.Load() {
+			return errors.New("broker down")
+		}
+		return nil
+	}, 50*time.Millisecond))
+	replayer := adapters.NewSpoolReplayer(spool, primary, logger,
+		adapters.WithReplayInterval(100*time.Millisecond),
+		adapters.WithReplayCondition(fallback.Healthy),
+	)
+	publisher := wiring.Decorate(fallback, wiring.PublisherDecorators(&config.Config{}, logger))
+
+	n := soak(t, publisher.PublishOrderCompleted)
+
+	// Once the broker has recovered, what is left in the spool is replayed
+	down.Store(false)
+	primary.SetFailureRate(0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := replayer.Close(ctx); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if _, err := spool.Replay(ctx, primary.PublishOrderCompleted); err != nil {
+		t.Fatalf("Replay() = %v", err)
+	}
+	if depth, err := spool.Depth(); depth != 0 || err != nil {
+		t.Errorf("Depth() = %d, %v after the last replay, want an empty spool", depth, err)
+	}
+	checkDelivered(t, b, n, primary)
+}
+
+// TestSoakOutbox publishes through the order event outbox, as configured with
+// ORDER_EVENT_OUTBOX. Batches that fail during an outage are retried by the
+// relay until the broker is back.
+func TestSoakOutbox(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	b := &broker{}
+	down := startOutages(t)
+	primary := adapters.NewChaosOrderEventPublisher(b, failureRate, adapters.WithOutage(down.Load))
+	publisher := wiring.Decorate(primary, wiring.PublisherDecorators(&config.Config{}, logger))
+	outbox := adapters.NewInMemoryOrderEventOutbox(adapters.NewOrderCompletedBatchPublisher(publisher), logger,
+		adapters.WithRelayRetryInterval(20*time.Millisecond))
+
+	n := soak(t, func(ctx context.Context, order *pb.OrderResult) error {
+		events := outbox.Begin(order.GetOrderId())
+		events.Record(ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: order})
+		return events.Commit(ctx)
+	})
+
+	// Once the broker has recovered, the relay publishes the batches left
+	down.Store(false)
+	primary.SetFailureRate(0)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	checkDelivered(t, b, n, primary)
+}
+
+// soakDuration is how long each test publishes, from SOAK_DURATION.
+func soakDuration(t *testing.T) time.Duration {
+	t.Helper()
+	s := os.Getenv("SOAK_DURATION")
+	if s == "" {
+		return 30 * time.Second
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		t.Fatalf("invalid SOAK_DURATION: %v", err)
+	}
+	return d
+}
+
+// soak publishes orders soak-0, soak-1, ... at rate with publish for the soak
+// duration and returns their number. It fails t if a publish returned an
+// error, since the fallback and the outbox must accept every order, or if
+// the live heap kept growing.
+func soak(t *testing.T, publish func(context.Context, *pb.OrderResult) error) int {
+	t.Helper()
+	duration := soakDuration(t)
+	heap := sampleHeap(duration / 20)
+
+	orders := make(chan int)
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seq := range orders {
+				order := testdata.NewOrder().WithID(fmt.Sprintf("soak-%d", seq)).Build()
+				if err := publish(context.Background(), order); err != nil && failed.Add(1) == 1 {
+					t.Errorf("publishing %s = %v", order.GetOrderId(), err)
+				}
+			}
+		}()
+	}
+	ticker := time.NewTicker(time.Second / rate)
+	defer ticker.Stop()
+	deadline := time.After(duration)
+	n := 0
+loop:
+	for ; ; n++ {
+		select {
+		case <-ticker.C:
+			orders <- n
+		case <-deadline:
+			break loop
+		}
+	}
+	close(orders)
+	wg.Wait()
+
+	if failed.Load() > 0 {
+		t.Errorf("%d of %d publishes failed, want none", failed.Load(), n)
+	}
+	checkHeapBounded(t, heap())
+	return n
+}
+
+// checkDelivered fails t unless the broker received each of the n orders
+// while primary injected failures.
+func checkDelivered(t *testing.T, b *broker, n int, primary *adapters.ChaosOrderEventPublisher) {
+	t.Helper()
+	if primary.Injected() == 0 {
+		t.Fatal("no failure was injected")
+	}
+	if missing := b.missing(n); len(missing) > 0 {
+		t.Errorf("%d of %d orders never reached the broker, such as soak-%d", len(missing), n, missing[0])
+	}
+	t.Logf("published %d orders through %d injected failures", n, primary.Injected())
+}
+
+// startOutages takes the broker down for outage at the start of every
+// outagePeriod until the test ends, and returns whether it is down.
+func startOutages(t *testing.T) *atomic.Bool {
+	var down atomic.Bool
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(outagePeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				down.Store(true)
+				select {
+				case <-time.After(outage):
+				case <-stop:
+					return
+				}
+				down.Store(false)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+	return &down
+}
+
+// broker stands for the order topic. It records which orders arrived, one
+// bit per order, so that its own memory does not hide a leak.
+type broker struct {
+	mu   sync.Mutex
+	seen []uint64
+}
+
+func (b *broker) PublishOrderCompleted(_ context.Context, order *pb.OrderResult) error {
+	seq, err := strconv.Atoi(strings.TrimPrefix(order.GetOrderId(), "soak-"))
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for len(b.seen) <= seq/64 {
+		b.seen = append(b.seen, 0)
+	}
+	b.seen[seq/64] |= 1 << (seq % 64)
+	return nil
+}
+
+// missing returns the orders below n that never arrived.
+func (b *broker) missing(n int) []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var missing []int
+	for seq := range n {
+		if seq/64 >= len(b.seen) || b.seen[seq/64]&(1<<(seq%64)) == 0 {
+			missing = append(missing, seq)
+		}
+	}
+	return missing
+}
+
+// sampleHeap records the live heap, after a collection, every interval until
+// the returned function is called, which returns the samples.
+func sampleHeap(interval time.Duration) func() []uint64 {
+	var samples []uint64
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				runtime.GC()
+				var stats runtime.MemStats
+				runtime.ReadMemStats(&stats)
+				samples = append(samples, stats.HeapAlloc)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() []uint64 {
+		close(stop)
+		<-done
+		return samples
+	}
+}
+
+// checkHeapBounded fails t if the live heap kept growing: its peak over the
+// second half of the run must stay within twice the peak of the first half,
+// with 8 MiB of slack for the noise of a small heap.
+func checkHeapBounded(t *testing.T, samples []uint64) {
+	t.Helper()
+	if len(samples) < 4 {
+		t.Logf("only %d heap samples, not checking the heap", len(samples))
+		return
+	}
+	first, second := slices.Max(samples[:len(samples)/2]), slices.Max(samples[len(samples)/2:])
+	if second > 2*first+8<<20 {
+		t.Errorf("live heap grew from a peak of %d bytes to %d", first, second)
+	}
+	t.Logf("live heap peaked at %d bytes, then %d", first, second)
+}
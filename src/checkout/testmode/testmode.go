@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package testmode selects the test suites of the checkout service from the
+// environment, so that which tests run, and against what, is decided in one
+// place rather than by skips spread across the tests:
+//
+//	CONTRACT_TESTS=broker  verify the providers against the pacts of PACT_BROKER_URL
+//	CONTRACT_TESTS=local   verify them against the pact files of the repository
+//	CONTRACT_TESTS=off     skip the contract tests
+//	INTEGRATION=kafka      run the integration suite against a real broker
+//	INTEGRATION=fake       run it against an in-process fake of Kafka
+//
+// CONTRACT_TESTS defaults to broker when PACT_BROKER_URL is set and to local
+// otherwise. Without INTEGRATION the integration suite uses a real broker
+// when one is available and skips otherwise. The integration and soak suites
+// are only built with their build tags, listed in Suites.
+package testmode
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+)
+
+// ContractMode selects the pacts the contract tests verify.
+type ContractMode string
+
+const (
+	ContractBroker ContractMode = "broker"
+	ContractLocal  ContractMode = "local"
+	ContractOff    ContractMode = "off"
+)
+
+// IntegrationMode selects the Kafka the integration suite runs against.
+type IntegrationMode string
+
+const (
+	// IntegrationAuto runs against a real broker when one is available and
+	// skips otherwise
+	IntegrationAuto IntegrationMode = ""
+	// IntegrationKafka runs against a real broker and fails when none can be
+	// started
+	IntegrationKafka IntegrationMode = "kafka"
+	IntegrationFake  IntegrationMode = "fake"
+)
+
+// Modes is the selection read from the environment.
+type Modes struct {
+	Contract    ContractMode    `env:"CONTRACT_TESTS" oneof:"broker local off"`
+	Integration IntegrationMode `env:"INTEGRATION" oneof:"kafka fake"`
+	Broker      Broker
+}
+
+// Broker is the Pact Broker the broker contract mode verifies against.
+type Broker struct {
+	URL      string `env:"PACT_BROKER_URL"`
+	Username string `env:"PACT_BROKER_USERNAME"`
+	Password string `env:"PACT_BROKER_PASSWORD"`
+	// ProviderVersion and ProviderBranch are recorded with the verification
+	// results
+	ProviderVersion string `env:"GIT_COMMIT"`
+	ProviderBranch  string `env:"GIT_BRANCH"`
+}
+
+// Load reads the modes from the variables returned by lookup.
+func Load(lookup config.LookupFunc) (Modes, error) {
+	var m Modes
+	if err := config.Parse(&m, lookup); err != nil {
+		return m, err
+	}
+	switch {
+	case m.Contract == "" && m.Broker.URL != "":
+		m.Contract = ContractBroker
+	case m.Contract == "":
+		m.Contract = ContractLocal
+	case m.Contract == ContractBroker && m.Broker.URL == "":
+		return m, errors.New("CONTRACT_TESTS=broker requires PACT_BROKER_URL")
+	}
+	return m, nil
+}
+
+// Suite is a kind of test of the checkout service.
+type Suite struct {
+	Name string
+	// Tag is the build tag the suite is built with, empty if it always is
+	Tag string
+	// Command runs the suite from the module root
+	Command string
+	// skip returns why the suite does not run in the modes, or the empty
+	// string
+	skip func(Modes) string
+}
+
+var (
+	Unit = Suite{
+		Name:    "unit",
+		Command: "go test ./...",
+	}
+	Contract = Suite{
+		Name:    "contract",
+		Command: "go run ./cmd/contractctl generate && go run ./cmd/contractctl verify",
+		skip: func(m Modes) string {
+			if m.Contract == ContractOff {
+				return "contract tests are off: CONTRACT_TESTS=off"
+			}
+			return ""
+		},
+	}
+	Integration = Suite{
+		Name:    "integration",
+		Tag:     "integration",
+		Command: "go test -tags integration ./integration",
+	}
+	Soak = Suite{
+		Name:    "soak",
+		Tag:     "soak",
+		Command: "SOAK_DURATION=10m go test -tags soak -timeout 0 ./soak",
+	}
+)
+
+// Suites returns every suite, in the order CI runs them.
+func Suites() []Suite {
+	return []Suite{Unit, Contract, Integration, Soak}
+}
+
+// Require returns the modes for a test of suite. It fails t when the
+// environment is invalid, and skips it when the modes leave suite out.
+func Require(t testing.TB, suite Suite) Modes {
+	t.Helper()
+	m, err := Load(os.LookupEnv)
+	if err != nil {
+		t.Fatalf("testmode: %v", err)
+	}
+	if suite.skip != nil {
+		if reason := suite.skip(m); reason != "" {
+			t.Skip(reason)
+		}
+	}
+	return m
+}
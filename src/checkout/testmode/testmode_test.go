@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testmode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Modes
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: Modes{Contract: ContractLocal},
+		},
+		{
+			name: "broker by default when one is configured",
+			env:  map[string]string{"PACT_BROKER_URL": "https://broker.example.com"},
+			want: Modes{Contract: ContractBroker, Broker: Broker{URL: "https://broker.example.com"}},
+		},
+		{
+			name: "local despite a broker",
+			env:  map[string]string{"CONTRACT_TESTS": "local", "PACT_BROKER_URL": "https://broker.example.com"},
+			want: Modes{Contract: ContractLocal, Broker: Broker{URL: "https://broker.example.com"}},
+		},
+		{
+			name: "off and fake",
+			env:  map[string]string{"CONTRACT_TESTS": "OFF", "INTEGRATION": "fake"},
+			want: Modes{Contract: ContractOff, Integration: IntegrationFake},
+		},
+		{
+			name:    "broker without a URL",
+			env:     map[string]string{"CONTRACT_TESTS": "broker"},
+			wantErr: "requires PACT_BROKER_URL",
+		},
+		{
+			name:    "unknown modes",
+			env:     map[string]string{"CONTRACT_TESTS": "remote", "INTEGRATION": "docker"},
+			wantErr: "CONTRACT_TESTS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(func(key string) (string, bool) {
+				v, ok := tt.env[key]
+				return v, ok
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Load() = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	t.Setenv("PACT_BROKER_URL", "")
+	t.Setenv("INTEGRATION", "")
+
+	t.Setenv("CONTRACT_TESTS", "off")
+	ran := t.Run("contract tests off", func(t *testing.T) {
+		Require(t, Contract)
+		t.Error("Require() did not skip a contract test with CONTRACT_TESTS=off")
+	})
+	if !ran {
+		t.Error("Require() failed the test rather than skipping it")
+	}
+
+	t.Setenv("CONTRACT_TESTS", "local")
+	t.Run("contract tests local", func(t *testing.T) {
+		if m := Require(t, Contract); m.Contract != ContractLocal {
+			t.Errorf("Require() = %+v, want local contract tests", m)
+		}
+	})
+}
+
+func TestSuites(t *testing.T) {
+	names := map[string]bool{}
+	for _, s := range Suites() {
+		if names[s.Name] {
+			t.Errorf("suite %s is listed twice", s.Name)
+		}
+		names[s.Name] = true
+		if s.Tag != "" && !strings.Contains(s.Command, "-tags "+s.Tag) {
+			t.Errorf("suite %s command %q does not set its build tag %s", s.Name, s.Command, s.Tag)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package wiring
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+// largeOrderItems is the number of items of the large order, well above what
+// the storefront sends.
+const largeOrderItems = 200
+
+func largeOrder() *pb.OrderResult {
+	order := testdata.NewOrder().WithShipping(testdata.USD(5))
+	for i := range largeOrderItems {
+		order.WithItem(fmt.Sprintf("SKU-%d", i), 2, testdata.USD(3))
+	}
+	return order.Build()
+}
+
+// BenchmarkPublishOrderCompleted publishes orders through the Kafka adapter
+// over an in-process producer: bare, with the decorators of
+// PublisherDecorators, and with those of CHECKOUT_DEBUG. It measures the
+// serialization, validation and header injection of the publish path without
+// a broker.
+func BenchmarkPublishOrderCompleted(b *testing.B) {
+	orders := []struct {
+		name  string
+		order *pb.OrderResult
+	}{
+		{"small", testOrder()},
+		{"large", largeOrder()},
+	}
+	chains := []struct {
+		name       string
+		decorators []Decorator
+	}{
+		{"transport", nil},
+		{"decorated", PublisherDecorators(&config.Config{}, slog.New(slog.DiscardHandler))},
+		{"debug", PublisherDecorators(&config.Config{Debug: true}, slog.New(slog.DiscardHandler))},
+	}
+	for _, o := range orders {
+		for _, c := range chains {
+			b.Run(o.name+"/"+c.name, func(b *testing.B) {
+				publisher := newBenchmarkPublisher(b, c.decorators)
+				ctx := context.Background()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for range b.N {
+					if err := publisher.PublishOrderCompleted(ctx, o.order); err != nil {
+						b.Fatalf("PublishOrderCompleted() = %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// newBenchmarkPublisher returns the Kafka adapter wrapped with decorators over
+// a producer acknowledging b.N messages. The expectations do not record the
+// messages, which would be measured with the publish.
+func newBenchmarkPublisher(b *testing.B, decorators []Decorator) ports.OrderEventPublisher {
+	producer := kafkatest.NewProducer(b)
+	for range b.N {
+		producer.ExpectInputAndSucceed()
+	}
+	transport := adapters.NewKafkaOrderEventPublisher(producer, slog.New(slog.DiscardHandler))
+	return Decorate(transport, decorators)
+}
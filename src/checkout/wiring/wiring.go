@@ -0,0 +1,505 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wiring is the composition root of the checkout service. It builds
+// the adapters behind the driven ports from the configuration, and declares
+// in one place the order in which decorators wrap the order event transport.
+package wiring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Decorator wraps an order event publisher with one concern.
+type Decorator struct {
+	Name string
+	Wrap func(next ports.OrderEventPublisher) ports.OrderEventPublisher
+}
+
+// Decorator names, as returned by PublisherDecorators.
+const (
+	DecoratorValidating = "validating"
+	DecoratorRoundTrip  = "round_trip"
+	DecoratorBuffer     = "buffer"
+	DecoratorRetry      = "retry"
+)
+
+// PublisherDecorators returns the decorators of the order event publisher,
+// outermost first:
+//
+//	validating → round_trip (with CHECKOUT_DEBUG) → buffer (with PUBLISH_BUFFER_SIZE)
+//	  → retry (with PUBLISH_RETRY_MAX_ATTEMPTS) → transport
+//
+// Validation comes first so that a malformed order is never serialized, and
+// retries come last so that only the transport is retried, from the workers
+// of the buffer when there is one.
+func PublisherDecorators(cfg *config.Config, logger *slog.Logger) []Decorator {
+	decorators := []Decorator{{
+		Name: DecoratorValidating,
+		Wrap: func(next ports.OrderEventPublisher) ports.OrderEventPublisher {
+			return adapters.NewValidatingOrderEventPublisher(next, logger)
+		},
+	}}
+	if cfg.Debug {
+		decorators = append(decorators, Decorator{
+			Name: DecoratorRoundTrip,
+			Wrap: func(next ports.OrderEventPublisher) ports.OrderEventPublisher {
+				return adapters.NewRoundTripCheckingOrderEventPublisher(next, logger)
+			},
+		})
+	}
+	if cfg.PublishBuffer.Enabled() {
+		decorators = append(decorators, Decorator{
+			Name: DecoratorBuffer,
+			Wrap: func(next ports.OrderEventPublisher) ports.OrderEventPublisher {
+				return adapters.NewBufferingOrderEventPublisher(next, logger, bufferOptions(cfg, next, logger)...)
+			},
+		})
+	}
+	if cfg.PublishRetry.Enabled() {
+		policy := adapters.RetryPolicy{
+			MaxAttempts: cfg.PublishRetry.MaxAttempts,
+			Backoff:     cfg.PublishRetry.Backoff,
+			MaxBackoff:  cfg.PublishRetry.MaxBackoff,
+			Jitter:      cfg.PublishRetry.Jitter,
+		}
+		decorators = append(decorators, Decorator{
+			Name: DecoratorRetry,
+			Wrap: func(next ports.OrderEventPublisher) ports.OrderEventPublisher {
+				return adapters.NewRetryingOrderEventPublisher(next, policy, logger)
+			},
+		})
+	}
+	return decorators
+}
+
+// bufferOptions configures the buffer in front of next. With the spill
+// overflow policy, the events that do not fit go to a spool next to the
+// spool fallback, which is replayed to next like the fallback.
+func bufferOptions(cfg *config.Config, next ports.OrderEventPublisher, logger *slog.Logger) []adapters.BufferingPublisherOption {
+	opts := []adapters.BufferingPublisherOption{
+		adapters.WithBufferSize(cfg.PublishBuffer.Size),
+		adapters.WithBufferWorkers(cfg.PublishBuffer.Workers),
+		adapters.WithOverflowPolicy(cfg.PublishBuffer.Overflow),
+	}
+	if cfg.PublishBuffer.Overflow != adapters.OverflowSpill {
+		return opts
+	}
+	spool := adapters.NewSpoolOrderEventPublisher(cfg.OrderEvents.SpoolPath+".overflow", logger)
+	spill := &adapters.PublisherChain{OrderEventPublisher: spool, Spool: spool}
+	if cfg.OrderEvents.SpoolReplayInterval > 0 {
+		spill.Replayer = adapters.NewSpoolReplayer(spool, next, logger,
+			adapters.WithReplayInterval(cfg.OrderEvents.SpoolReplayInterval),
+			adapters.WithBacklogStore(adapters.BacklogStoreOverflow),
+		)
+	}
+	return append(opts, adapters.WithSpillover(spill))
+}
+
+// Decorate wraps transport with decorators, the first one outermost.
+func Decorate(transport ports.OrderEventPublisher, decorators []Decorator) ports.OrderEventPublisher {
+	publisher := transport
+	for i := len(decorators) - 1; i >= 0; i-- {
+		publisher = decorators[i].Wrap(publisher)
+	}
+	return publisher
+}
+
+// Options are the dependencies of NewPorts that do not come from the
+// configuration.
+type Options struct {
+	// KafkaOptions are applied to the Kafka publisher
+	KafkaOptions []adapters.KafkaPublisherOption
+	// SpoolOnly replaces the configured transport with the spool, for when
+	// order events must not be published, such as after a failed schema check
+	SpoolOnly bool
+	// PaymentClient charges cards on behalf of the PaymentService port
+	PaymentClient pb.PaymentServiceClient
+	// CurrencyClient serves the exchange rates of the CurrencyConverter port
+	CurrencyClient pb.CurrencyServiceClient
+	// TransactionalProducer creates the producer the outbox publishes its
+	// batches with in Kafka transactions, kafka.CreateTransactionalProducer
+	// if nil
+	TransactionalProducer func(brokers []string, logger *slog.Logger, transactionalID string, opts ...kafka.ProducerOption) (sarama.SyncProducer, error)
+}
+
+// OrderEventOutbox is an outbox of order events whose batches can be
+// counted, held and replayed.
+type OrderEventOutbox interface {
+	ports.OrderEventOutbox
+	// Pending returns the number of batches waiting to be published
+	Pending() int
+	// Pause keeps the batches in the outbox until Resume
+	Pause()
+	Resume()
+	Paused() bool
+	// Replay publishes the batches now and returns the number waiting
+	Replay() int
+}
+
+// Compile-time check that InMemoryOrderEventOutbox implements OrderEventOutbox
+var _ OrderEventOutbox = (*adapters.InMemoryOrderEventOutbox)(nil)
+
+// Compile-time check that sqlOrderEventOutbox implements OrderEventOutbox
+var _ OrderEventOutbox = sqlOrderEventOutbox{}
+
+// sqlOrderEventOutbox is the outbox table of ORDER_EVENT_OUTBOX_DSN, whose
+// batches are published by its relay.
+type sqlOrderEventOutbox struct {
+	table *adapters.OutboxOrderEventPublisher
+	relay *adapters.OutboxRelay
+}
+
+func (o sqlOrderEventOutbox) Begin(orderID string) ports.UnitOfWork { return o.table.Begin(orderID) }
+func (o sqlOrderEventOutbox) Pending() int                          { return o.relay.Pending() }
+func (o sqlOrderEventOutbox) Pause()                                { o.relay.Pause() }
+func (o sqlOrderEventOutbox) Resume()                               { o.relay.Resume() }
+func (o sqlOrderEventOutbox) Paused() bool                          { return o.relay.Paused() }
+func (o sqlOrderEventOutbox) Replay() int                           { return o.relay.Replay() }
+
+// Ports are the adapters behind the driven ports of the checkout service.
+type Ports struct {
+	// OrderEventPublisher is the decorated order event publisher
+	OrderEventPublisher ports.OrderEventPublisher
+
+	IdempotencyStore  ports.IdempotencyStore
+	OrderCompensator  ports.OrderCompensator
+	OrderRepository   ports.OrderRepository
+	ShippingProviders ports.ShippingProviderRegistry
+	// Inventory holds stock while an order is charged and shipped
+	Inventory ports.InventoryReserver
+	// Payments charges the card and gift cards of an order
+	Payments ports.PaymentService
+	// Promotions adjusts item costs before an order is charged
+	Promotions ports.PromotionEngine
+	// Currency converts costs to the currency of the user
+	Currency     ports.CurrencyConverter
+	EmailService ports.EmailService
+	// ConfirmationRenderer renders the order confirmation emails
+	ConfirmationRenderer ports.OrderConfirmationRenderer
+	// PendingOrders is nil unless asynchronous orders are enabled
+	PendingOrders ports.PendingOrderStore
+	// Outbox is nil unless ORDER_EVENT_OUTBOX is set. It is the outbox table
+	// of ORDER_EVENT_OUTBOX_DSN if set, and in memory otherwise
+	Outbox OrderEventOutbox
+	// Relay publishes the batches of the outbox table, nil unless
+	// ORDER_EVENT_OUTBOX_DSN is set
+	Relay *adapters.OutboxRelay
+	// batchPublisher is the publisher the outbox relays to
+	batchPublisher ports.OrderEventBatchPublisher
+	// outboxTable is the outbox table the relay reads
+	outboxTable *adapters.OutboxOrderEventPublisher
+	// relayChain is the publisher chain of
+	// ORDER_EVENT_OUTBOX_RELAY_PUBLISHER, nil unless ORDER_EVENT_PUBLISHER
+	// is outbox
+	relayChain *adapters.PublisherChain
+
+	// transport holds the publisher chain under the decorators, nil with
+	// Options.SpoolOnly
+	transport             *adapters.SwappableOrderEventPublisher
+	logger                *slog.Logger
+	kafkaOptions          []adapters.KafkaPublisherOption
+	transactionalProducer func(brokers []string, logger *slog.Logger, transactionalID string, opts ...kafka.ProducerOption) (sarama.SyncProducer, error)
+
+	// reloadMu serializes reloads and guards the settings of the chain and
+	// whether publishing is paused
+	reloadMu sync.Mutex
+	settings config.Config
+	paused   bool
+}
+
+// Compile-time check that Ports implements Lifecycle
+var _ ports.Lifecycle = (*Ports)(nil)
+
+// NewPorts builds the adapters for cfg.
+func NewPorts(cfg *config.Config, logger *slog.Logger, opts Options) (*Ports, error) {
+	p := &Ports{
+		IdempotencyStore: adapters.NewInMemoryIdempotencyStore(cfg.PlaceOrder.IdempotencyTTL),
+		// Refunds and OrderFailed are recorded as logs until the payment service
+		// has a refund RPC and the order schema an OrderFailed message
+		OrderCompensator:  adapters.NewLoggingOrderCompensator(logger),
+		OrderRepository:   adapters.NewInMemoryOrderRepository(100),
+		ShippingProviders: ShippingProviders(cfg.Services, cfg.Carrier),
+		// Stock levels are configured until an inventory service exists
+		Inventory:  adapters.NewInMemoryInventoryReserver(cfg.PlaceOrder.Stock()),
+		Promotions: adapters.NewPercentOffPromotionEngine(cfg.PlaceOrder.PercentOff()),
+		Currency:   adapters.NewCachingCurrencyConverter(opts.CurrencyClient, cfg.CurrencyRates.TTL, cfg.CurrencyRates.MaxStaleness, logger),
+		// Gift card balances are configured until a gift card service exists
+		Payments:              adapters.NewInMemoryGiftCardPaymentService(GiftCardBalances(cfg.PlaceOrder), adapters.NewGRPCPaymentService(opts.PaymentClient)),
+		EmailService:          adapters.NewHTTPEmailService(cfg.Services.Email, nil),
+		ConfirmationRenderer:  adapters.NewTemplateOrderConfirmationRenderer(),
+		logger:                logger,
+		kafkaOptions:          opts.KafkaOptions,
+		transactionalProducer: opts.TransactionalProducer,
+		settings:              *cfg,
+	}
+	if p.transactionalProducer == nil {
+		p.transactionalProducer = kafka.CreateTransactionalProducer
+	}
+	if cfg.PlaceOrder.AsyncWorkers > 0 {
+		p.PendingOrders = adapters.NewInMemoryPendingOrderStore(24 * time.Hour)
+	}
+
+	var transport ports.OrderEventPublisher
+	if opts.SpoolOnly {
+		transport = adapters.NewSpoolOrderEventPublisher(cfg.OrderEvents.SpoolPath, logger)
+	} else {
+		chain, err := adapters.NewOrderEventPublisherFromConfig(cfg.OrderEvents, cfg.Kafka, logger, opts.KafkaOptions...)
+		if err != nil {
+			return nil, err
+		}
+		p.transport = adapters.NewSwappableOrderEventPublisher(chain)
+		transport = p.transport
+	}
+	p.OrderEventPublisher = Decorate(transport, PublisherDecorators(cfg, logger))
+
+	if cfg.OrderEvents.OutboxDSN != "" {
+		if err := p.startRelay(cfg, opts.SpoolOnly); err != nil {
+			return nil, errors.Join(err, p.Close(context.Background()))
+		}
+		if cfg.OrderEvents.Outbox {
+			p.Outbox = sqlOrderEventOutbox{table: p.outboxTable, relay: p.Relay}
+		}
+	} else if cfg.OrderEvents.Outbox {
+		p.batchPublisher = p.newBatchPublisher(cfg, p.OrderEventPublisher, opts.SpoolOnly)
+		p.Outbox = adapters.NewInMemoryOrderEventOutbox(p.batchPublisher, logger)
+	}
+	return p, nil
+}
+
+// startRelay starts relaying the outbox table of ORDER_EVENT_OUTBOX_DSN. With
+// ORDER_EVENT_PUBLISHER=outbox, whose publisher writes to that table, the
+// relay publishes through a chain of ORDER_EVENT_OUTBOX_RELAY_PUBLISHER
+// without a fallback, so that the batches stay in the table while it is
+// down; otherwise it publishes through the order event publisher.
+func (p *Ports) startRelay(cfg *config.Config, spoolOnly bool) error {
+	table, err := adapters.OpenOutboxOrderEventPublisher(cfg.OrderEvents)
+	if err != nil {
+		return err
+	}
+	p.outboxTable = table
+	if cfg.OrderEvents.Publisher == adapters.PublisherOutbox && !spoolOnly {
+		relayCfg := *cfg
+		relayCfg.OrderEvents.Publisher = cfg.OrderEvents.OutboxRelayPublisher
+		relayCfg.OrderEvents.Fallback = adapters.PublisherNone
+		p.relayChain, err = adapters.NewOrderEventPublisherFromConfig(relayCfg.OrderEvents, relayCfg.Kafka, p.logger, p.kafkaOptions...)
+		if err != nil {
+			return fmt.Errorf("failed to create the publisher of the outbox relay: %w", err)
+		}
+		p.batchPublisher = p.newBatchPublisher(&relayCfg, p.relayChain, false)
+	} else {
+		p.batchPublisher = p.newBatchPublisher(cfg, p.OrderEventPublisher, spoolOnly)
+	}
+	p.Relay = adapters.NewOutboxRelay(table, p.batchPublisher, p.logger,
+		adapters.WithOutboxPollInterval(cfg.OrderEvents.OutboxPollInterval),
+	)
+	return nil
+}
+
+// Transport returns the publisher chain under the decorators, nil with
+// Options.SpoolOnly. It changes when the publisher is reloaded.
+func (p *Ports) Transport() *adapters.PublisherChain {
+	if p.transport == nil {
+		return nil
+	}
+	chain, _ := p.transport.Current().(*adapters.PublisherChain)
+	return chain
+}
+
+// ReloadPublisher replaces the publisher chain with one built from the order
+// event and Kafka settings of cfg, keeping the decorators and the outbox. The
+// orders in flight on the replaced chain are published within ctx, and it is
+// then closed. Settings outside the chain, the outbox and its table, the
+// schema version and the transactional ID, need a restart, and so does any
+// change while order events are only spooled, the outbox publishes in Kafka
+// transactions or the publisher is the outbox table, whose relay publishes
+// with the settings it started with.
+func (p *Ports) ReloadPublisher(ctx context.Context, cfg *config.Config) error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	current := p.settings
+	if reflect.DeepEqual(cfg.OrderEvents, current.OrderEvents) && reflect.DeepEqual(cfg.Kafka, current.Kafka) {
+		return nil
+	}
+	switch {
+	case p.transport == nil:
+		return errors.New("order events are only spooled after the failed schema check, restart to publish them")
+	case cfg.OrderEvents.Outbox != current.OrderEvents.Outbox:
+		return errors.New("ORDER_EVENT_OUTBOX cannot be reloaded, restart to change it")
+	case cfg.OrderEvents.OutboxDSN != current.OrderEvents.OutboxDSN ||
+		cfg.OrderEvents.OutboxTable != current.OrderEvents.OutboxTable ||
+		cfg.OrderEvents.OutboxPollInterval != current.OrderEvents.OutboxPollInterval ||
+		cfg.OrderEvents.OutboxRelayPublisher != current.OrderEvents.OutboxRelayPublisher:
+		return errors.New("the ORDER_EVENT_OUTBOX_* settings of the outbox table cannot be reloaded, restart to change them")
+	case cfg.OrderEvents.Publisher == adapters.PublisherOutbox || current.OrderEvents.Publisher == adapters.PublisherOutbox:
+		return errors.New("ORDER_EVENT_PUBLISHER=outbox cannot be reloaded, restart to change the publisher")
+	case cfg.OrderEvents.SchemaVersion != current.OrderEvents.SchemaVersion:
+		return errors.New("ORDER_EVENT_SCHEMA_VERSION cannot be reloaded, restart to change it")
+	case cfg.Kafka.TransactionalID != current.Kafka.TransactionalID:
+		return errors.New("KAFKA_TRANSACTIONAL_ID cannot be reloaded, restart to change it")
+	case cfg.Kafka.ProducerMode != current.Kafka.ProducerMode &&
+		(cfg.Kafka.ProducerMode == adapters.KafkaProducerTransactional || current.Kafka.ProducerMode == adapters.KafkaProducerTransactional):
+		// The producers of both chains would share a transactional ID while
+		// the replaced one drains, and the new one would fence it off
+		return errors.New("KAFKA_PRODUCER_MODE=transactional cannot be reloaded, restart to change it")
+	}
+	if _, ok := p.batchPublisher.(*adapters.KafkaOrderEventBatchPublisher); ok {
+		return errors.New("the outbox publishes in Kafka transactions, restart to change the publisher")
+	}
+
+	chain, err := adapters.NewOrderEventPublisherFromConfig(cfg.OrderEvents, cfg.Kafka, p.logger, p.kafkaOptions...)
+	if err != nil {
+		return err
+	}
+	if p.paused {
+		if err := chain.Pause(); err != nil {
+			return errors.Join(fmt.Errorf("publishing is paused: %w", err), chain.Close(ctx))
+		}
+	}
+	p.settings.OrderEvents, p.settings.Kafka = cfg.OrderEvents, cfg.Kafka
+	if err := p.transport.Swap(ctx, chain); err != nil {
+		return fmt.Errorf("failed to drain the replaced publisher chain: %w", err)
+	}
+	return nil
+}
+
+// Settings returns the configuration of the ports, with the publisher
+// settings last reloaded.
+func (p *Ports) Settings() config.Config {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	return p.settings
+}
+
+// PausePublishing holds order events until ResumePublishing: the publisher
+// chain spools them and the outbox keeps its batches. Publishing stays
+// paused when the publisher is reloaded. It fails unless the chain has a
+// spool fallback.
+func (p *Ports) PausePublishing() error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	chain := p.Transport()
+	if chain == nil {
+		return errors.New("order events are only spooled after the failed schema check")
+	}
+	if err := chain.Pause(); err != nil {
+		return err
+	}
+	if p.Outbox != nil {
+		p.Outbox.Pause()
+	}
+	if p.Relay != nil {
+		p.Relay.Pause()
+	}
+	p.paused = true
+	return nil
+}
+
+// ResumePublishing publishes order events again. The ones spooled while
+// paused are replayed with the spool, and the outbox publishes its batches.
+func (p *Ports) ResumePublishing() {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	if chain := p.Transport(); chain != nil {
+		chain.Resume()
+	}
+	if p.Outbox != nil {
+		p.Outbox.Resume()
+	}
+	if p.Relay != nil {
+		p.Relay.Resume()
+	}
+	p.paused = false
+}
+
+// PublishingPaused reports whether publishing is paused.
+func (p *Ports) PublishingPaused() bool {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	return p.paused
+}
+
+// newBatchPublisher publishes the batches of the outbox in Kafka transactions
+// when Kafka is the publisher, with the OrderResult on KAFKA_TOPIC, to the
+// topics of KAFKA_REGION. Other
+// transports only carry the OrderResult of each batch, which is published
+// through publisher.
+func (p *Ports) newBatchPublisher(cfg *config.Config, publisher ports.OrderEventPublisher, spoolOnly bool) ports.OrderEventBatchPublisher {
+	if cfg.OrderEvents.Publisher != adapters.PublisherKafka || spoolOnly {
+		return adapters.NewOrderCompletedBatchPublisher(publisher)
+	}
+	producer, err := p.transactionalProducer([]string{cfg.Kafka.Addr}, p.logger, cfg.Kafka.TransactionalID)
+	if err != nil {
+		p.logger.Warn(fmt.Sprintf("kafka unreachable, publishing only the OrderResult of each order: %v", err))
+		return adapters.NewOrderCompletedBatchPublisher(publisher)
+	}
+	return adapters.NewKafkaOrderEventBatchPublisher(producer, p.logger,
+		adapters.WithBatchTopics(cfg.Kafka.Topic, kafka.EventsTopic),
+		adapters.WithBatchRegion(cfg.Kafka.Region),
+	)
+}
+
+// Close drains the outbox in memory and stops the relay of the outbox table,
+// then closes the publishers and the table. The batches of the table are
+// relayed after the next start.
+func (p *Ports) Close(ctx context.Context) error {
+	var errs []error
+	if l, ok := p.Outbox.(ports.Lifecycle); ok {
+		errs = append(errs, l.Close(ctx))
+	}
+	if p.Relay != nil {
+		errs = append(errs, p.Relay.Close(ctx))
+	}
+	for _, publisher := range []any{p.batchPublisher, p.OrderEventPublisher} {
+		if l, ok := publisher.(ports.Lifecycle); ok {
+			errs = append(errs, l.Close(ctx))
+		}
+	}
+	if p.relayChain != nil {
+		errs = append(errs, p.relayChain.Close(ctx))
+	}
+	if p.outboxTable != nil {
+		errs = append(errs, p.outboxTable.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// ExpressShippingSurcharge is added to the standard quote, which is in USD,
+// for express shipping.
+var ExpressShippingSurcharge = &pb.Money{CurrencyCode: "USD", Units: 10}
+
+// ShippingProviders ships with the shipping service by default, or per order
+// with express handling or, when configured, a third-party carrier.
+func ShippingProviders(services config.Services, carrier config.Carrier) *adapters.InMemoryShippingProviderRegistry {
+	providers := adapters.NewInMemoryShippingProviderRegistry(adapters.ShippingMethodStandard)
+	standard := adapters.NewHTTPShippingProvider(services.Shipping)
+	providers.Register(adapters.ShippingMethodStandard, standard)
+	providers.Register(adapters.ShippingMethodExpress, adapters.NewExpressShippingProvider(standard, ExpressShippingSurcharge))
+	if carrier.URL != "" {
+		providers.Register(adapters.ShippingMethodCarrier, adapters.NewCarrierAPIShippingProvider(carrier.URL, carrier.APIKey, nil))
+	}
+	return providers
+}
+
+// GiftCardBalances returns the configured gift card balances as money.
+func GiftCardBalances(placeOrder config.PlaceOrder) map[string]*pb.Money {
+	balances := make(map[string]*pb.Money)
+	for code, balance := range placeOrder.GiftCardBalances() {
+		balances[code] = &pb.Money{CurrencyCode: balance.CurrencyCode, Units: balance.Units}
+	}
+	return balances
+}
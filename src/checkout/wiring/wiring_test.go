@@ -0,0 +1,441 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package wiring
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/adapters"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func testOrder() *pb.OrderResult {
+	return testdata.NewOrder().
+		WithItem("SKU-1", 2, testdata.USD(3)).
+		WithShipping(testdata.USD(5)).
+		Build()
+}
+
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		OrderEvents: config.OrderEvents{
+			Publisher: adapters.PublisherNoOp,
+			Fallback:  adapters.PublisherNone,
+			SpoolPath: filepath.Join(t.TempDir(), "orders.spool"),
+		},
+	}
+}
+
+// tracingPublisher records the name of every publisher an event goes through.
+type tracingPublisher struct {
+	name  string
+	next  ports.OrderEventPublisher
+	calls *[]string
+}
+
+func (p *tracingPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	*p.calls = append(*p.calls, p.name)
+	if p.next == nil {
+		return nil
+	}
+	return p.next.PublishOrderCompleted(ctx, order)
+}
+
+func TestDecorateWrapsFirstDecoratorOutermost(t *testing.T) {
+	var calls []string
+	decorator := func(name string) Decorator {
+		return Decorator{Name: name, Wrap: func(next ports.OrderEventPublisher) ports.OrderEventPublisher {
+			return &tracingPublisher{name: name, next: next, calls: &calls}
+		}}
+	}
+	transport := &tracingPublisher{name: "kafka", calls: &calls}
+
+	pub := Decorate(transport, []Decorator{decorator("retry"), decorator("circuit_breaker"), decorator("metrics")})
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if want := []string{"retry", "circuit_breaker", "metrics", "kafka"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("event went through %v, want %v", calls, want)
+	}
+}
+
+func TestPublisherDecorators(t *testing.T) {
+	tests := []struct {
+		debug    bool
+		attempts int
+		buffer   int
+		want     []string
+	}{
+		{debug: false, want: []string{DecoratorValidating}},
+		{debug: true, want: []string{DecoratorValidating, DecoratorRoundTrip}},
+		{debug: false, attempts: 1, want: []string{DecoratorValidating}},
+		{debug: true, attempts: 3, want: []string{DecoratorValidating, DecoratorRoundTrip, DecoratorRetry}},
+		{debug: true, attempts: 3, buffer: 100, want: []string{DecoratorValidating, DecoratorRoundTrip, DecoratorBuffer, DecoratorRetry}},
+	}
+	for _, tt := range tests {
+		var names []string
+		cfg := &config.Config{
+			Debug:         tt.debug,
+			PublishRetry:  config.PublishRetry{MaxAttempts: tt.attempts},
+			PublishBuffer: config.PublishBuffer{Size: tt.buffer},
+		}
+		for _, d := range PublisherDecorators(cfg, discardLogger()) {
+			names = append(names, d.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("PublisherDecorators(debug=%v, attempts=%d, buffer=%d) = %v, want %v", tt.debug, tt.attempts, tt.buffer, names, tt.want)
+		}
+	}
+}
+
+func TestNewPorts(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PlaceOrder.AsyncWorkers = 2
+
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	if _, ok := p.OrderEventPublisher.(*adapters.ValidatingOrderEventPublisher); !ok {
+		t.Errorf("OrderEventPublisher = %T, want the validating decorator outermost", p.OrderEventPublisher)
+	}
+	if p.Transport() == nil || p.PendingOrders == nil {
+		t.Errorf("Transport = %v and PendingOrders = %v, want both set", p.Transport(), p.PendingOrders)
+	}
+	if err := p.OrderEventPublisher.PublishOrderCompleted(context.Background(), &pb.OrderResult{}); errcode.Of(err) != errcode.ValidationFailed {
+		t.Errorf("PublishOrderCompleted(invalid order) = %v, want %s", err, errcode.ValidationFailed)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestNewPortsBuffer(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.OrderEvents.SpoolReplayInterval = time.Hour
+	cfg.PublishBuffer = config.PublishBuffer{Size: 10, Workers: 2, Overflow: adapters.OverflowSpill}
+
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	if err := p.OrderEventPublisher.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	// Close drains the buffer and stops the replayer of its overflow spool
+	if err := p.Close(context.Background()); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestNewPortsSpoolOnly(t *testing.T) {
+	cfg := testConfig(t)
+
+	p, err := NewPorts(cfg, discardLogger(), Options{SpoolOnly: true})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	if p.Transport() != nil {
+		t.Errorf("Transport = %v, want nil with SpoolOnly", p.Transport())
+	}
+	if err := p.OrderEventPublisher.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if _, err := os.Stat(cfg.OrderEvents.SpoolPath); err != nil {
+		t.Errorf("order was not spooled: %v", err)
+	}
+}
+
+func TestNewPortsOutbox(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.OrderEvents.Publisher = adapters.PublisherSpool
+	cfg.OrderEvents.Outbox = true
+
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	if p.Outbox == nil {
+		t.Fatal("Outbox = nil with ORDER_EVENT_OUTBOX")
+	}
+	uow := p.Outbox.Begin("order-1")
+	uow.Record(ports.OrderEvent{Type: ports.OrderPlacedEvent, Order: &pb.OrderResult{OrderId: "order-1"}})
+	uow.Record(ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: testOrder()})
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+
+	// Close drains the outbox before the spool it relays to
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if _, err := os.Stat(cfg.OrderEvents.SpoolPath); err != nil {
+		t.Errorf("OrderResult was not spooled: %v", err)
+	}
+}
+
+func TestNewPortsOutboxKafkaTopic(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Kafka.Topic = "checkout.orders"
+
+	want := []string{kafka.EventsTopic, "checkout.orders"}
+	if topics := outboxTopics(t, cfg, "checkout.orders"); !slices.Equal(topics, want) {
+		t.Errorf("outbox published to %v, want %v", topics, want)
+	}
+}
+
+func TestNewPortsOutboxKafkaRegion(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Kafka.Topic = kafka.Topic
+	cfg.Kafka.Region = "us-east-1"
+
+	want := []string{"us-east-1." + kafka.EventsTopic, "us-east-1." + kafka.Topic}
+	if topics := outboxTopics(t, cfg, "us-east-1."+kafka.Topic); !slices.Equal(topics, want) {
+		t.Errorf("outbox published to %v, want %v", topics, want)
+	}
+}
+
+// outboxTopics commits the events of an order to the outbox of the kafka
+// publisher of cfg, whose OrderResult goes to topic, and returns the topics
+// the outbox published them to.
+func outboxTopics(t *testing.T, cfg *config.Config, topic string) []string {
+	t.Helper()
+	cfg.OrderEvents.Publisher = adapters.PublisherKafka
+	cfg.OrderEvents.Outbox = true
+	cfg.Kafka.Addr = kafkatest.NewBroker(t, topic).Addr()
+	cfg.Kafka.TransactionalID = "checkout-test"
+
+	producer := kafkatest.NewTransactionalProducer(t, cfg.Kafka.TransactionalID)
+	var topics []string
+	for range 2 {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			topics = append(topics, msg.Topic)
+			return nil
+		})
+	}
+	p, err := NewPorts(cfg, discardLogger(), Options{
+		TransactionalProducer: func([]string, *slog.Logger, string, ...kafka.ProducerOption) (sarama.SyncProducer, error) {
+			return producer, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	uow := p.Outbox.Begin("order-1")
+	uow.Record(ports.OrderEvent{Type: ports.OrderPlacedEvent, Order: &pb.OrderResult{OrderId: "order-1"}})
+	uow.Record(ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: testOrder()})
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+
+	// Close drains the outbox
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	return topics
+}
+
+func TestNewPortsOutboxTable(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.OrderEvents.Publisher = adapters.PublisherSpool
+	cfg.OrderEvents.Outbox = true
+	// The relay does not poll the unreachable database during the test
+	cfg.OrderEvents.OutboxDSN = "postgres://127.0.0.1:1/orders?connect_timeout=1"
+	cfg.OrderEvents.OutboxPollInterval = time.Hour
+
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	defer p.Close(context.Background())
+	if _, ok := p.Outbox.(sqlOrderEventOutbox); !ok || p.Relay == nil {
+		t.Fatalf("Outbox = %T, want the outbox table with its relay", p.Outbox)
+	}
+	if err := p.PausePublishing(); err == nil || p.Relay.Paused() {
+		t.Errorf("PausePublishing() without a spool fallback = %v and paused the relay: %v", err, p.Relay.Paused())
+	}
+
+	moved := *cfg
+	moved.OrderEvents.OutboxTable = "checkout.order_event_outbox"
+	if err := p.ReloadPublisher(context.Background(), &moved); err == nil {
+		t.Error("ReloadPublisher() reloaded ORDER_EVENT_OUTBOX_TABLE, want an error")
+	}
+}
+
+func TestNewPortsOutboxPublisher(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.OrderEvents.Publisher = adapters.PublisherOutbox
+	cfg.OrderEvents.Fallback = adapters.PublisherSpool
+	cfg.OrderEvents.OutboxDSN = "postgres://127.0.0.1:1/orders?connect_timeout=1"
+	cfg.OrderEvents.OutboxPollInterval = time.Hour
+	cfg.OrderEvents.OutboxRelayPublisher = adapters.PublisherMemory
+
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	defer p.Close(context.Background())
+	if p.Outbox != nil || p.Relay == nil {
+		t.Fatalf("Outbox = %v and Relay = %v, want only the relay without ORDER_EVENT_OUTBOX", p.Outbox, p.Relay)
+	}
+	if _, ok := p.relayChain.OrderEventPublisher.(*adapters.InMemoryOrderEventPublisher); !ok {
+		t.Errorf("relay publishes through %T, want ORDER_EVENT_OUTBOX_RELAY_PUBLISHER", p.relayChain.OrderEventPublisher)
+	}
+
+	reloaded := *cfg
+	reloaded.OrderEvents.FallbackRecheckInterval = time.Minute
+	if err := p.ReloadPublisher(context.Background(), &reloaded); err == nil {
+		t.Error("ReloadPublisher() with ORDER_EVENT_PUBLISHER=outbox = nil, want an error")
+	}
+}
+
+func TestReloadPublisher(t *testing.T) {
+	cfg := testConfig(t)
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	defer p.Close(context.Background())
+
+	unchanged := *cfg
+	chain := p.Transport()
+	if err := p.ReloadPublisher(context.Background(), &unchanged); err != nil || p.Transport() != chain {
+		t.Errorf("ReloadPublisher(unchanged) = %v and replaced the chain: %v, want nil and false", err, p.Transport() != chain)
+	}
+
+	spooled := *cfg
+	spooled.OrderEvents.Publisher = adapters.PublisherSpool
+	if err := p.ReloadPublisher(context.Background(), &spooled); err != nil {
+		t.Fatalf("ReloadPublisher() = %v", err)
+	}
+	if err := p.OrderEventPublisher.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if _, err := os.Stat(cfg.OrderEvents.SpoolPath); err != nil {
+		t.Errorf("order was not spooled after the reload: %v", err)
+	}
+
+	outbox := spooled
+	outbox.OrderEvents.Outbox = true
+	if err := p.ReloadPublisher(context.Background(), &outbox); err == nil {
+		t.Error("ReloadPublisher() reloaded ORDER_EVENT_OUTBOX, want an error")
+	}
+	transactional := spooled
+	transactional.Kafka.ProducerMode = adapters.KafkaProducerTransactional
+	if err := p.ReloadPublisher(context.Background(), &transactional); err == nil {
+		t.Error("ReloadPublisher() switched to KAFKA_PRODUCER_MODE=transactional, want an error")
+	}
+	invalid := spooled
+	invalid.OrderEvents.Publisher = "carrier-pigeon"
+	if err := p.ReloadPublisher(context.Background(), &invalid); err == nil {
+		t.Error("ReloadPublisher() accepted an unknown publisher")
+	}
+	if _, ok := p.Transport().OrderEventPublisher.(*adapters.SpoolOrderEventPublisher); !ok {
+		t.Errorf("Transport() = %T after failed reloads, want the spool kept", p.Transport().OrderEventPublisher)
+	}
+}
+
+func TestReloadPublisherSpoolOnly(t *testing.T) {
+	cfg := testConfig(t)
+	p, err := NewPorts(cfg, discardLogger(), Options{SpoolOnly: true})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	reloaded := *cfg
+	reloaded.OrderEvents.Publisher = adapters.PublisherKafka
+	if err := p.ReloadPublisher(context.Background(), &reloaded); err == nil {
+		t.Error("ReloadPublisher() with SpoolOnly = nil, want an error")
+	}
+}
+
+func TestPausePublishing(t *testing.T) {
+	cfg := testConfig(t)
+	p, err := NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	if err := p.PausePublishing(); err == nil || p.PublishingPaused() {
+		t.Errorf("PausePublishing() without a spool fallback = %v, want an error", err)
+	}
+	p.Close(context.Background())
+
+	cfg.OrderEvents.Publisher = adapters.PublisherWebhook
+	cfg.OrderEvents.Webhook.URLs = []string{"http://127.0.0.1:1"}
+	cfg.OrderEvents.Fallback = adapters.PublisherSpool
+	cfg.OrderEvents.Outbox = true
+	p, err = NewPorts(cfg, discardLogger(), Options{})
+	if err != nil {
+		t.Fatalf("NewPorts() = %v", err)
+	}
+	defer p.Close(context.Background())
+	if err := p.PausePublishing(); err != nil {
+		t.Fatalf("PausePublishing() = %v", err)
+	}
+	if !p.PublishingPaused() || !p.Transport().Paused() || !p.Outbox.Paused() {
+		t.Fatal("PausePublishing() did not pause the chain and the outbox")
+	}
+
+	// A reloaded chain stays paused, and one that cannot pause is rejected
+	reloaded := *cfg
+	reloaded.OrderEvents.FallbackRecheckInterval = time.Minute
+	if err := p.ReloadPublisher(context.Background(), &reloaded); err != nil {
+		t.Fatalf("ReloadPublisher() = %v", err)
+	}
+	if !p.Transport().Paused() {
+		t.Error("reloaded chain is not paused")
+	}
+	unspooled := reloaded
+	unspooled.OrderEvents.Fallback = adapters.PublisherNone
+	if err := p.ReloadPublisher(context.Background(), &unspooled); err == nil {
+		t.Error("ReloadPublisher() without a spool fallback while paused = nil, want an error")
+	}
+	if p.Settings().OrderEvents.Fallback != adapters.PublisherSpool {
+		t.Errorf("Settings() has fallback %q after the failed reload, want %q", p.Settings().OrderEvents.Fallback, adapters.PublisherSpool)
+	}
+
+	p.ResumePublishing()
+	if p.PublishingPaused() || p.Transport().Paused() || p.Outbox.Paused() {
+		t.Error("ResumePublishing() did not resume the chain and the outbox")
+	}
+}
+
+func TestShippingProviders(t *testing.T) {
+	tests := []struct {
+		carrier config.Carrier
+		want    []string
+	}{
+		{want: []string{adapters.ShippingMethodStandard, adapters.ShippingMethodExpress}},
+		{
+			carrier: config.Carrier{URL: "http://carrier"},
+			want:    []string{adapters.ShippingMethodStandard, adapters.ShippingMethodExpress, adapters.ShippingMethodCarrier},
+		},
+	}
+	for _, tt := range tests {
+		providers := ShippingProviders(config.Services{Shipping: "shipping:50050"}, tt.carrier)
+		for _, method := range tt.want {
+			if _, err := providers.Provider(method); err != nil {
+				t.Errorf("Provider(%q) with carrier %+v = %v", method, tt.carrier, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// BacklogStoreKey tells apart the stores of the messaging.publish.backlog gauge.
+const BacklogStoreKey = attribute.Key("checkout.backlog.store")
+
+// Stores of the messaging.publish.backlog gauge.
+const (
+	BacklogStoreSpool       = "spool"
+	BacklogStoreOutbox      = "outbox"
+	BacklogStoreOutboxTable = "outbox_table"
+	BacklogStoreBuffer      = "buffer"
+	BacklogStoreOverflow    = "overflow"
+)
+
+// observeBacklog reports depth as the messaging.publish.backlog gauge of
+// store, until the returned registration is unregistered. It returns nil if
+// the gauge cannot be created.
+func observeBacklog(store string, depth func() (int, error), logger *slog.Logger) metric.Registration {
+	meter := otel.Meter("checkout-order-events")
+	gauge, err := meter.Int64ObservableGauge(
+		"messaging.publish.backlog",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Order events persisted and waiting to be published."),
+	)
+	if err != nil {
+		logger.Warn("Failed to create publish backlog gauge", slog.String("error", err.Error()))
+		return nil
+	}
+	attrs := metric.WithAttributes(BacklogStoreKey.String(store))
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		n, err := depth()
+		if err != nil {
+			return err
+		}
+		o.ObserveInt64(gauge, int64(n), attrs)
+		return nil
+	}, gauge)
+	if err != nil {
+		logger.Warn("Failed to observe publish backlog", slog.String("error", err.Error()))
+		return nil
+	}
+	return registration
+}
@@ -0,0 +1,261 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Defaults of a BufferingOrderEventPublisher.
+const (
+	defaultBufferSize    = 1000
+	defaultBufferWorkers = 4
+)
+
+// Overflow policies of a BufferingOrderEventPublisher, for when its queue is
+// full.
+const (
+	// OverflowBlock waits for room in the queue until the publish's context
+	// ends.
+	OverflowBlock = "block"
+	// OverflowDropOldest drops the oldest queued event to make room.
+	OverflowDropOldest = "drop_oldest"
+	// OverflowSpill publishes the event to the spillover publisher, such as
+	// a spool, instead of queueing it.
+	OverflowSpill = "spill"
+)
+
+// bufferedEvent is an order event waiting in the queue, with the context of
+// its publish.
+type bufferedEvent struct {
+	ctx   context.Context
+	order *pb.OrderResult
+}
+
+// BufferingOrderEventPublisher is a decorator that queues order events in
+// memory and publishes them to the wrapped publisher from a pool of workers,
+// so that a publish returns as soon as its event is queued, however slow the
+// broker is. The trace context and message headers of a publish go with its
+// event, whose publish is no longer cancelled with the caller's context.
+//
+// When the queue is full, the overflow policy decides between waiting for
+// room, dropping the oldest event and spilling the event to another
+// publisher. Events that fail to publish from the queue, or are dropped, are
+// logged and lost unless the wrapped publisher has a fallback, so the queue
+// trades the broker's acknowledgment for latency. It reports its depth on
+// the messaging.publish.backlog gauge.
+type BufferingOrderEventPublisher struct {
+	next     ports.OrderEventPublisher
+	logger   *slog.Logger
+	size     int
+	workers  int
+	overflow string
+	spill    ports.OrderEventPublisher
+
+	queue   chan bufferedEvent
+	wg      sync.WaitGroup
+	backlog metric.Registration
+	dropped atomic.Int64
+
+	// mu guards closed, which senders read while the queue is open
+	mu        sync.RWMutex
+	closed    bool
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// Compile-time check that BufferingOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*BufferingOrderEventPublisher)(nil)
+
+// Compile-time check that BufferingOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*BufferingOrderEventPublisher)(nil)
+
+// BufferingPublisherOption configures optional behavior of a
+// BufferingOrderEventPublisher.
+type BufferingPublisherOption func(*BufferingOrderEventPublisher)
+
+// WithBufferSize sets how many events the queue holds. The default is 1000.
+func WithBufferSize(size int) BufferingPublisherOption {
+	return func(b *BufferingOrderEventPublisher) {
+		b.size = size
+	}
+}
+
+// WithBufferWorkers sets how many events are published at once. The default
+// is 4.
+func WithBufferWorkers(workers int) BufferingPublisherOption {
+	return func(b *BufferingOrderEventPublisher) {
+		b.workers = workers
+	}
+}
+
+// WithOverflowPolicy sets what happens to an event when the queue is full:
+// OverflowBlock, the default, OverflowDropOldest or OverflowSpill.
+func WithOverflowPolicy(policy string) BufferingPublisherOption {
+	return func(b *BufferingOrderEventPublisher) {
+		b.overflow = policy
+	}
+}
+
+// WithSpillover sets the publisher that takes the events that do not fit in
+// the queue with OverflowSpill. It is closed with the buffering publisher.
+func WithSpillover(spill ports.OrderEventPublisher) BufferingPublisherOption {
+	return func(b *BufferingOrderEventPublisher) {
+		b.spill = spill
+	}
+}
+
+// NewBufferingOrderEventPublisher wraps next with a queue and starts its
+// workers.
+func NewBufferingOrderEventPublisher(next ports.OrderEventPublisher, logger *slog.Logger, opts ...BufferingPublisherOption) *BufferingOrderEventPublisher {
+	b := &BufferingOrderEventPublisher{
+		next:     next,
+		logger:   logger,
+		size:     defaultBufferSize,
+		workers:  defaultBufferWorkers,
+		overflow: OverflowBlock,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.queue = make(chan bufferedEvent, b.size)
+	b.backlog = observeBacklog(BacklogStoreBuffer, func() (int, error) { return len(b.queue), nil }, logger)
+
+	for range b.workers {
+		b.wg.Add(1)
+		go b.work()
+	}
+	return b
+}
+
+// PublishOrderCompleted queues the order, applying the overflow policy when
+// the queue is full.
+func (b *BufferingOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return errcode.Errorf(errcode.PublisherClosed, "order event buffer is closed")
+	}
+
+	event := bufferedEvent{
+		ctx:   context.WithoutCancel(ctx),
+		order: proto.Clone(order).(*pb.OrderResult),
+	}
+	select {
+	case b.queue <- event:
+		return nil
+	default:
+	}
+
+	switch b.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case b.queue <- event:
+				return nil
+			default:
+			}
+			select {
+			case oldest := <-b.queue:
+				b.drop(ctx, oldest)
+			default:
+			}
+		}
+	case OverflowSpill:
+		if b.spill == nil {
+			return errcode.Errorf(errcode.BufferFull, "order event buffer is full and has no spillover publisher")
+		}
+		return b.spill.PublishOrderCompleted(ctx, order)
+	default:
+		select {
+		case b.queue <- event:
+			return nil
+		case <-b.stop:
+			return errcode.Errorf(errcode.PublisherClosed, "order event buffer is closed")
+		case <-ctx.Done():
+			return errcode.Errorf(errcode.BufferFull, "order event buffer stayed full: %w", ctx.Err())
+		}
+	}
+}
+
+// drop logs an event dropped from the queue to make room.
+func (b *BufferingOrderEventPublisher) drop(ctx context.Context, event bufferedEvent) {
+	b.dropped.Add(1)
+	b.logger.ErrorContext(ctx, "Dropping the oldest buffered order event, the buffer is full",
+		slog.String("order_id", event.order.GetOrderId()),
+		slog.Int("buffer_size", b.size),
+	)
+}
+
+// Dropped returns the number of events dropped with OverflowDropOldest.
+func (b *BufferingOrderEventPublisher) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Len returns the number of queued events.
+func (b *BufferingOrderEventPublisher) Len() int {
+	return len(b.queue)
+}
+
+// work publishes queued events until the queue is closed and empty.
+func (b *BufferingOrderEventPublisher) work() {
+	defer b.wg.Done()
+	for event := range b.queue {
+		if err := b.next.PublishOrderCompleted(event.ctx, event.order); err != nil {
+			b.logger.ErrorContext(event.ctx, "Failed to publish buffered order event",
+				slog.String("order_id", event.order.GetOrderId()),
+				slog.String("error", err.Error()),
+				errcode.Attr(err),
+			)
+		}
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// published, or until ctx is done, and then closes the wrapped and
+// spillover publishers. The events still queued when ctx is done are logged
+// as lost.
+func (b *BufferingOrderEventPublisher) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		// Release the publishes waiting for room before taking the lock
+		close(b.stop)
+		b.mu.Lock()
+		b.closed = true
+		close(b.queue)
+		b.mu.Unlock()
+		if b.backlog != nil {
+			b.backlog.Unregister()
+		}
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		if n := len(b.queue); n > 0 {
+			b.logger.ErrorContext(ctx, "Buffered order events not published before shutdown, they are lost", slog.Int("count", n))
+			err = fmt.Errorf("%d buffered order events not published before shutdown: %w", n, ctx.Err())
+		} else {
+			err = ctx.Err()
+		}
+	}
+	return errors.Join(err, closeIfLifecycle(ctx, b.next), closeIfLifecycle(ctx, b.spill))
+}
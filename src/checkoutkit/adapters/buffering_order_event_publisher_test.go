@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+// newFullBuffer returns a buffering publisher of one worker and one queued
+// event, whose worker holds the first event and whose queue holds the second
+// until next is released.
+func newFullBuffer(t *testing.T, next *blockingPublisher, opts ...BufferingPublisherOption) *BufferingOrderEventPublisher {
+	t.Helper()
+	opts = append([]BufferingPublisherOption{WithBufferSize(1), WithBufferWorkers(1)}, opts...)
+	pub := NewBufferingOrderEventPublisher(next, discardLogger(), opts...)
+	for i := range 2 {
+		if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+		if i == 0 {
+			<-next.started
+		}
+	}
+	return pub
+}
+
+func TestBufferingOrderEventPublisher(t *testing.T) {
+	next := newBlockingPublisher()
+	// Both publishes return while the broker holds the first one
+	pub := newFullBuffer(t, next)
+
+	close(next.release)
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if orders, closed := next.state(); orders != 2 || !closed {
+		t.Errorf("next published %d orders and closed = %v, want 2 orders drained and closed", orders, closed)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want %s", err, errcode.PublisherClosed)
+	}
+}
+
+func TestBufferingOrderEventPublisherOverflow(t *testing.T) {
+	tests := []struct {
+		policy      string
+		wantCode    errcode.Code
+		wantOrders  int
+		wantDropped int64
+		wantSpilled int
+	}{
+		{policy: OverflowBlock, wantCode: errcode.BufferFull, wantOrders: 2},
+		{policy: OverflowDropOldest, wantOrders: 2, wantDropped: 1},
+		{policy: OverflowSpill, wantOrders: 2, wantSpilled: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			next := newBlockingPublisher()
+			spill := NewInMemoryOrderEventPublisher()
+			pub := newFullBuffer(t, next, WithOverflowPolicy(tt.policy), WithSpillover(spill))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			err := pub.PublishOrderCompleted(ctx, testOrder())
+			if (err == nil) != (tt.wantCode == "") || (err != nil && errcode.Of(err) != tt.wantCode) {
+				t.Errorf("PublishOrderCompleted() with a full buffer = %v, want code %q", err, tt.wantCode)
+			}
+
+			close(next.release)
+			if err := pub.Close(context.Background()); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			if orders, _ := next.state(); orders != tt.wantOrders {
+				t.Errorf("next published %d orders, want %d", orders, tt.wantOrders)
+			}
+			if pub.Dropped() != tt.wantDropped || spill.Len() != tt.wantSpilled {
+				t.Errorf("dropped %d and spilled %d orders, want %d and %d", pub.Dropped(), spill.Len(), tt.wantDropped, tt.wantSpilled)
+			}
+		})
+	}
+}
+
+func TestBufferingOrderEventPublisherCloseTimesOut(t *testing.T) {
+	next := newBlockingPublisher()
+	pub := newFullBuffer(t, next)
+	defer close(next.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pub.Close(ctx); err == nil {
+		t.Error("Close() = nil with an event still queued, want an error")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// CachingCurrencyConverter implements the CurrencyConverter port with the
+// exchange rates of the demo currency service. The rate of a currency pair is
+// the price of one unit, fetched once per TTL and kept in process memory, so
+// that an order converts all its costs at the same rate.
+type CachingCurrencyConverter struct {
+	client   pb.CurrencyServiceClient
+	ttl      time.Duration
+	maxStale time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	rates map[currencyPair]exchangeRate
+}
+
+// currencyPair is the currencies of an exchange rate.
+type currencyPair struct {
+	from, to string
+}
+
+// exchangeRate is the price in nanos of one unit of a currency, and when it
+// was fetched.
+type exchangeRate struct {
+	nanos   int64
+	fetched time.Time
+}
+
+// Compile-time check that CachingCurrencyConverter implements CurrencyConverter
+var _ ports.CurrencyConverter = (*CachingCurrencyConverter)(nil)
+
+// NewCachingCurrencyConverter creates a converter fetching rates through
+// client. A rate older than ttl is fetched again, and while the currency
+// service fails, rates up to maxStale old are still used.
+func NewCachingCurrencyConverter(client pb.CurrencyServiceClient, ttl, maxStale time.Duration, logger *slog.Logger) *CachingCurrencyConverter {
+	return &CachingCurrencyConverter{
+		client:   client,
+		ttl:      ttl,
+		maxStale: maxStale,
+		logger:   logger,
+		now:      time.Now,
+		rates:    make(map[currencyPair]exchangeRate),
+	}
+}
+
+// Convert multiplies amount by the rate of its currency in currency and rounds
+// the result down to the cent.
+func (c *CachingCurrencyConverter) Convert(ctx context.Context, amount *pb.Money, currency string) (*pb.Money, error) {
+	if amount.GetCurrencyCode() == currency {
+		return amount, nil
+	}
+	rate, err := c.rate(ctx, currencyPair{from: amount.GetCurrencyCode(), to: currency})
+	if err != nil {
+		return nil, err
+	}
+	converted := new(big.Int).Mul(big.NewInt(toNanos(amount)), big.NewInt(rate))
+	converted.Quo(converted, big.NewInt(1_000_000_000))
+	if !converted.IsInt64() {
+		return nil, fmt.Errorf("%s %d.%09d overflows in %s", amount.GetCurrencyCode(), amount.GetUnits(), amount.GetNanos(), currency)
+	}
+	nanos := converted.Int64()
+	nanos -= nanos % nanosPerCent
+	return fromNanos(currency, nanos), nil
+}
+
+// rate returns the cached rate of pair, or fetches it when it is older than
+// the TTL. A failed fetch falls back to a cached rate up to maxStale old.
+func (c *CachingCurrencyConverter) rate(ctx context.Context, pair currencyPair) (int64, error) {
+	c.mu.Lock()
+	cached, ok := c.rates[pair]
+	c.mu.Unlock()
+	age := c.now().Sub(cached.fetched)
+	if ok && age < c.ttl {
+		return cached.nanos, nil
+	}
+
+	unit, err := c.client.Convert(ctx, &pb.CurrencyConversionRequest{
+		From:   &pb.Money{CurrencyCode: pair.from, Units: 1},
+		ToCode: pair.to,
+	})
+	if err == nil && unit.GetCurrencyCode() != pair.to {
+		err = fmt.Errorf("the currency service returned %s instead of %s", unit.GetCurrencyCode(), pair.to)
+	}
+	if err != nil {
+		if ok && age < c.maxStale {
+			c.logger.Warn(fmt.Sprintf("using a %s old rate from %s to %s, the currency service failed: %v", age.Round(time.Second), pair.from, pair.to, err))
+			return cached.nanos, nil
+		}
+		return 0, fmt.Errorf("failed to fetch the rate from %s to %s: %+v", pair.from, pair.to, err)
+	}
+
+	c.mu.Lock()
+	c.rates[pair] = exchangeRate{nanos: toNanos(unit), fetched: c.now()}
+	c.mu.Unlock()
+	return toNanos(unit), nil
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+// rateCurrencyClient converts at a rate in nanos and counts its calls.
+type rateCurrencyClient struct {
+	pb.CurrencyServiceClient
+	rate  int64
+	err   error
+	calls int
+}
+
+func (c *rateCurrencyClient) Convert(_ context.Context, req *pb.CurrencyConversionRequest, _ ...grpc.CallOption) (*pb.Money, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return fromNanos(req.ToCode, toNanos(req.From)*c.rate/1_000_000_000), nil
+}
+
+func TestCachingCurrencyConverter(t *testing.T) {
+	ctx := context.Background()
+	client := &rateCurrencyClient{rate: 900_000_000}
+	c := NewCachingCurrencyConverter(client, time.Minute, time.Hour, slog.New(slog.DiscardHandler))
+
+	tests := []struct {
+		amount *pb.Money
+		want   *pb.Money
+	}{
+		{&pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 990000000}, &pb.Money{CurrencyCode: "EUR", Units: 17, Nanos: 990000000}},
+		{&pb.Money{CurrencyCode: "USD", Units: 8}, &pb.Money{CurrencyCode: "EUR", Units: 7, Nanos: 200000000}},
+		{&pb.Money{CurrencyCode: "EUR", Units: 1, Nanos: 999999999}, &pb.Money{CurrencyCode: "EUR", Units: 1, Nanos: 999999999}},
+	}
+	for _, tt := range tests {
+		got, err := c.Convert(ctx, tt.amount, "EUR")
+		if err != nil || !proto.Equal(got, tt.want) {
+			t.Errorf("Convert(%v) = %v, %v; want %v", tt.amount, got, err, tt.want)
+		}
+	}
+	if client.calls != 1 {
+		t.Errorf("fetched the rate %d times, want once", client.calls)
+	}
+}
+
+func TestCachingCurrencyConverterStaleness(t *testing.T) {
+	ctx := context.Background()
+	clock := testdata.NewClock()
+	client := &rateCurrencyClient{rate: 900_000_000}
+	c := NewCachingCurrencyConverter(client, time.Minute, time.Hour, slog.New(slog.DiscardHandler))
+	c.now = clock.Now
+	amount := &pb.Money{CurrencyCode: "USD", Units: 10}
+
+	c.Convert(ctx, amount, "EUR")
+	client.rate = 800_000_000
+	clock.Advance(time.Minute)
+	if got, _ := c.Convert(ctx, amount, "EUR"); got.GetUnits() != 8 || client.calls != 2 {
+		t.Errorf("Convert() after the TTL = %v after %d fetches, want EUR 8 at the new rate", got, client.calls)
+	}
+
+	client.err = errors.New("currency service down")
+	clock.Advance(59 * time.Minute)
+	if got, err := c.Convert(ctx, amount, "EUR"); err != nil || got.GetUnits() != 8 {
+		t.Errorf("Convert() while the service fails = %v, %v; want EUR 8 at the stale rate", got, err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.Convert(ctx, amount, "EUR"); err == nil {
+		t.Error("Convert() with a rate older than the max staleness succeeded, want an error")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// CarrierAPIShippingProvider ships orders with a third-party carrier's REST
+// API:
+//
+//	POST /v1/rates      {destination, parcels} -> {currency, amount_cents}
+//	POST /v1/shipments  {destination, parcels} -> {tracking_number}
+//
+// Requests are authenticated with the API key as a bearer token.
+type CarrierAPIShippingProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// Compile-time check that CarrierAPIShippingProvider implements ShippingProvider
+var _ ports.ShippingProvider = (*CarrierAPIShippingProvider)(nil)
+
+// NewCarrierAPIShippingProvider creates a provider for the carrier API at
+// baseURL. A nil client uses an instrumented default client.
+func NewCarrierAPIShippingProvider(baseURL, apiKey string, client *http.Client) *CarrierAPIShippingProvider {
+	if client == nil {
+		client = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	}
+	return &CarrierAPIShippingProvider{baseURL: baseURL, apiKey: apiKey, client: client}
+}
+
+type carrierShipmentRequest struct {
+	Destination carrierAddress  `json:"destination"`
+	Parcels     []carrierParcel `json:"parcels"`
+}
+
+type carrierAddress struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	Country    string `json:"country"`
+	PostalCode string `json:"postal_code"`
+}
+
+type carrierParcel struct {
+	SKU      string `json:"sku"`
+	Quantity int32  `json:"quantity"`
+}
+
+func newCarrierShipmentRequest(address *pb.Address, items []*pb.CartItem) carrierShipmentRequest {
+	req := carrierShipmentRequest{
+		Destination: carrierAddress{
+			Street:     address.GetStreetAddress(),
+			City:       address.GetCity(),
+			State:      address.GetState(),
+			Country:    address.GetCountry(),
+			PostalCode: address.GetZipCode(),
+		},
+		Parcels: make([]carrierParcel, 0, len(items)),
+	}
+	for _, item := range items {
+		req.Parcels = append(req.Parcels, carrierParcel{SKU: item.GetProductId(), Quantity: item.GetQuantity()})
+	}
+	return req
+}
+
+// Quote asks the carrier for a rate.
+func (p *CarrierAPIShippingProvider) Quote(ctx context.Context, address *pb.Address, items []*pb.CartItem) (*pb.Money, error) {
+	var rate struct {
+		Currency    string `json:"currency"`
+		AmountCents *int64 `json:"amount_cents"`
+	}
+	if err := p.post(ctx, "/v1/rates", newCarrierShipmentRequest(address, items), &rate); err != nil {
+		return nil, err
+	}
+	if rate.Currency == "" || rate.AmountCents == nil {
+		return nil, fmt.Errorf("carrier rate missing currency or amount_cents field")
+	}
+	cents := *rate.AmountCents
+	return &pb.Money{CurrencyCode: rate.Currency, Units: cents / 100, Nanos: int32(cents%100) * 10_000_000}, nil
+}
+
+// Ship books a shipment with the carrier.
+func (p *CarrierAPIShippingProvider) Ship(ctx context.Context, address *pb.Address, items []*pb.CartItem) (string, error) {
+	var shipment struct {
+		TrackingNumber string `json:"tracking_number"`
+	}
+	if err := p.post(ctx, "/v1/shipments", newCarrierShipmentRequest(address, items), &shipment); err != nil {
+		return "", err
+	}
+	if shipment.TrackingNumber == "" {
+		return "", fmt.Errorf("carrier shipment missing tracking_number field")
+	}
+	return shipment.TrackingNumber, nil
+}
+
+func (p *CarrierAPIShippingProvider) post(ctx context.Context, path string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal carrier request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create carrier request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed POST to carrier API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read carrier response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed POST to carrier API %s: status %d: %s", path, resp.StatusCode, respBody)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal carrier response: %w", err)
+	}
+	return nil
+}
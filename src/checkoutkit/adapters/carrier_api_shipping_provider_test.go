@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// newFakeCarrierAPI serves the carrier API, recording the shipment requests.
+// It fails every request when down is set.
+func newFakeCarrierAPI(t *testing.T, down bool, requests *[]carrierShipmentRequest) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	handle := func(path string, status int, resp any) {
+		mux.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			if down {
+				http.Error(w, `{"error": "maintenance"}`, http.StatusBadGateway)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer key-1" {
+				http.Error(w, `{"error": "unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+			var req carrierShipmentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if requests != nil {
+				*requests = append(*requests, req)
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+	handle("/v1/rates", http.StatusOK, map[string]any{"currency": "USD", "amount_cents": 1250})
+	handle("/v1/shipments", http.StatusCreated, map[string]any{"shipment_id": "shp-1", "tracking_number": "1Z999"})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestCarrierAPIShippingProviderContract(t *testing.T) {
+	testShippingProviderContract(t, func(t *testing.T, down bool) ports.ShippingProvider {
+		return NewCarrierAPIShippingProvider(newFakeCarrierAPI(t, down, nil), "key-1", nil)
+	})
+}
+
+func TestCarrierAPIShippingProvider(t *testing.T) {
+	var requests []carrierShipmentRequest
+	provider := NewCarrierAPIShippingProvider(newFakeCarrierAPI(t, false, &requests), "key-1", nil)
+
+	quote, err := provider.Quote(t.Context(), testShippingAddress(), testCartItems())
+	if want := (&pb.Money{CurrencyCode: "USD", Units: 12, Nanos: 500000000}); err != nil || !money.AreEquals(quote, want) {
+		t.Errorf("Quote() = %v, %v; want %v", quote, err, want)
+	}
+	if trackingID, err := provider.Ship(t.Context(), testShippingAddress(), testCartItems()); err != nil || trackingID != "1Z999" {
+		t.Errorf("Ship() = %q, %v; want 1Z999", trackingID, err)
+	}
+
+	want := carrierShipmentRequest{
+		Destination: carrierAddress{Street: "1 Main St", City: "Anytown", State: "CA", Country: "USA", PostalCode: "94043"},
+		Parcels:     []carrierParcel{{SKU: "SKU-1", Quantity: 2}, {SKU: "SKU-2", Quantity: 1}},
+	}
+	if len(requests) != 2 || !reflect.DeepEqual(requests[0], want) || !reflect.DeepEqual(requests[1], want) {
+		t.Errorf("carrier received %+v, want %+v for the rate and the shipment", requests, want)
+	}
+
+	unauthorized := NewCarrierAPIShippingProvider(newFakeCarrierAPI(t, false, nil), "wrong-key", nil)
+	if _, err := unauthorized.Quote(t.Context(), testShippingAddress(), testCartItems()); err == nil {
+		t.Error("Quote() with a wrong API key succeeded, want an error")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// ErrInjectedFailure is the cause of the failures a ChaosOrderEventPublisher
+// injects.
+var ErrInjectedFailure = errors.New("injected broker failure")
+
+// ChaosOrderEventPublisher is a decorator that fails publishes on purpose, the
+// way a broker outage would, before they reach the wrapped publisher. It
+// rehearses the fallback, spool and outbox paths in soak tests. An injected
+// failure is a KAFKA_PRODUCE_FAILED error wrapping ErrInjectedFailure.
+type ChaosOrderEventPublisher struct {
+	next ports.OrderEventPublisher
+	// failureRate holds the float64 bits of the probability of a failure
+	failureRate atomic.Uint64
+	down        func() bool
+	injected    atomic.Int64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Compile-time check that ChaosOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*ChaosOrderEventPublisher)(nil)
+
+// Compile-time check that ChaosOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*ChaosOrderEventPublisher)(nil)
+
+// ChaosPublisherOption configures optional behavior of a
+// ChaosOrderEventPublisher.
+type ChaosPublisherOption func(*ChaosOrderEventPublisher)
+
+// WithOutage fails every publish while down returns true, on top of the
+// random failures.
+func WithOutage(down func() bool) ChaosPublisherOption {
+	return func(c *ChaosOrderEventPublisher) {
+		c.down = down
+	}
+}
+
+// WithChaosSeed seeds the random failures, so that a run can be repeated.
+func WithChaosSeed(seed int64) ChaosPublisherOption {
+	return func(c *ChaosOrderEventPublisher) {
+		c.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// NewChaosOrderEventPublisher wraps next so that each publish fails with
+// probability failureRate, between 0 and 1.
+func NewChaosOrderEventPublisher(next ports.OrderEventPublisher, failureRate float64, opts ...ChaosPublisherOption) *ChaosOrderEventPublisher {
+	c := &ChaosOrderEventPublisher{
+		next: next,
+		down: func() bool { return false },
+		rand: rand.New(rand.NewSource(rand.Int63())),
+	}
+	c.SetFailureRate(failureRate)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetFailureRate changes the probability of a failure, 0 to stop injecting
+// random failures.
+func (c *ChaosOrderEventPublisher) SetFailureRate(rate float64) {
+	c.failureRate.Store(math.Float64bits(min(max(rate, 0), 1)))
+}
+
+// Injected returns the number of failures injected so far.
+func (c *ChaosOrderEventPublisher) Injected() int64 {
+	return c.injected.Load()
+}
+
+// PublishOrderCompleted fails during an outage or at random, and publishes the
+// order through the wrapped publisher otherwise.
+func (c *ChaosOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if c.down() || c.roll() {
+		c.injected.Add(1)
+		return errcode.Wrap(errcode.KafkaProduceFailed, ErrInjectedFailure)
+	}
+	return c.next.PublishOrderCompleted(ctx, order)
+}
+
+// roll reports whether the next publish fails at random.
+func (c *ChaosOrderEventPublisher) roll() bool {
+	rate := math.Float64frombits(c.failureRate.Load())
+	if rate == 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Float64() < rate
+}
+
+// Close closes the wrapped publisher.
+func (c *ChaosOrderEventPublisher) Close(ctx context.Context) error {
+	return closeIfLifecycle(ctx, c.next)
+}
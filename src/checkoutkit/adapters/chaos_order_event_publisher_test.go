@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+func TestChaosOrderEventPublisher(t *testing.T) {
+	next := &recordingPublisher{}
+	down := false
+	pub := NewChaosOrderEventPublisher(next, 0.25, WithChaosSeed(1), WithOutage(func() bool { return down }))
+
+	var failed int
+	for range 400 {
+		err := pub.PublishOrderCompleted(context.Background(), testOrder())
+		if err == nil {
+			continue
+		}
+		failed++
+		if !errors.Is(err, ErrInjectedFailure) || errcode.Of(err) != errcode.KafkaProduceFailed {
+			t.Fatalf("PublishOrderCompleted() = %v, want an injected %s error", err, errcode.KafkaProduceFailed)
+		}
+	}
+	if failed < 60 || failed > 140 || len(next.orders) != 400-failed || pub.Injected() != int64(failed) {
+		t.Errorf("failed %d of 400 publishes, %d reached the wrapped publisher and %d were injected; want about 100 failed",
+			failed, len(next.orders), pub.Injected())
+	}
+
+	// An outage fails every publish, and the failure rate can be turned off
+	down = true
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); !errors.Is(err, ErrInjectedFailure) {
+		t.Errorf("PublishOrderCompleted() during an outage = %v, want %v", err, ErrInjectedFailure)
+	}
+	down = false
+	pub.SetFailureRate(0)
+	for range 100 {
+		if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+			t.Fatalf("PublishOrderCompleted() without chaos = %v", err)
+		}
+	}
+}
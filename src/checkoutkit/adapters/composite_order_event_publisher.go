@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Fan-out modes of a CompositeOrderEventPublisher.
+const (
+	// FanOutAll fails a publish unless every target accepted the order.
+	FanOutAll = "all"
+	// FanOutBestEffort fails a publish only if no target accepted the order.
+	FanOutBestEffort = "best_effort"
+)
+
+// CompositeTarget is one of the publishers of a CompositeOrderEventPublisher,
+// named after its kind for logs and errors.
+type CompositeTarget struct {
+	Name      string
+	Publisher ports.OrderEventPublisher
+}
+
+// CompositeOrderEventPublisher implements the OrderEventPublisher port by
+// publishing every order to several publishers at once, such as the old and
+// the new broker while migrating from one to the other.
+//
+// With FanOutAll, the default, a publish fails if any target fails, with the
+// errors of the failed targets joined; the targets that succeeded keep the
+// order, so a retry publishes it to them again and consumers deduplicate by
+// order ID. With FanOutBestEffort, the failures of some targets are logged
+// and recorded on the caller's span, and the publish only fails if every
+// target failed.
+type CompositeOrderEventPublisher struct {
+	targets []CompositeTarget
+	mode    string
+	logger  *slog.Logger
+}
+
+// Compile-time check that CompositeOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*CompositeOrderEventPublisher)(nil)
+
+// Compile-time check that CompositeOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*CompositeOrderEventPublisher)(nil)
+
+// CompositePublisherOption configures optional behavior of a
+// CompositeOrderEventPublisher.
+type CompositePublisherOption func(*CompositeOrderEventPublisher)
+
+// WithFanOutMode sets the fan-out mode, FanOutAll or FanOutBestEffort.
+func WithFanOutMode(mode string) CompositePublisherOption {
+	return func(c *CompositeOrderEventPublisher) {
+		c.mode = mode
+	}
+}
+
+// NewCompositeOrderEventPublisher creates a publisher to every target.
+func NewCompositeOrderEventPublisher(targets []CompositeTarget, logger *slog.Logger, opts ...CompositePublisherOption) *CompositeOrderEventPublisher {
+	c := &CompositeOrderEventPublisher{
+		targets: targets,
+		mode:    FanOutAll,
+		logger:  logger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PublishOrderCompleted publishes the order to every target concurrently and
+// returns once each of them returned.
+func (c *CompositeOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	errs := make([]error, len(c.targets))
+	var wg sync.WaitGroup
+	for i, target := range c.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := target.Publisher.PublishOrderCompleted(ctx, order); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", target.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if c.mode != FanOutBestEffort || len(failed) == len(c.targets) {
+		return errors.Join(failed...)
+	}
+	span := trace.SpanFromContext(ctx)
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		c.logger.WarnContext(ctx, "Failed to publish order event to one of the fan-out publishers",
+			slog.String("order_id", order.GetOrderId()),
+			slog.String(string(publisherKindKey), c.targets[i].Name),
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+		span.AddEvent("order event fan-out failed", trace.WithAttributes(
+			publisherKindKey.String(c.targets[i].Name),
+			errcode.Key.String(string(errcode.Of(err))),
+		))
+	}
+	return nil
+}
+
+// Close closes every target.
+func (c *CompositeOrderEventPublisher) Close(ctx context.Context) error {
+	errs := make([]error, 0, len(c.targets))
+	for _, target := range c.targets {
+		errs = append(errs, closeIfLifecycle(ctx, target.Publisher))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+// compositeOf returns a composite publisher to an in-memory publisher named
+// after each of names.
+func compositeOf(mode string, names ...string) (*CompositeOrderEventPublisher, map[string]*InMemoryOrderEventPublisher) {
+	pubs := map[string]*InMemoryOrderEventPublisher{}
+	var targets []CompositeTarget
+	for _, name := range names {
+		pubs[name] = NewInMemoryOrderEventPublisher()
+		targets = append(targets, CompositeTarget{Name: name, Publisher: pubs[name]})
+	}
+	return NewCompositeOrderEventPublisher(targets, discardLogger(), WithFanOutMode(mode)), pubs
+}
+
+func TestCompositeOrderEventPublisher(t *testing.T) {
+	composite, pubs := compositeOf(FanOutAll, "kafka", "nats")
+	if err := composite.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	for name, pub := range pubs {
+		if pub.Len() != 1 {
+			t.Errorf("%s received %d orders, want 1", name, pub.Len())
+		}
+	}
+}
+
+func TestCompositeOrderEventPublisherAllMustSucceed(t *testing.T) {
+	composite, pubs := compositeOf(FanOutAll, "kafka", "nats")
+	down := errcode.Errorf(errcode.NATSAckTimeout, "no ack")
+	pubs["nats"].FailAll(down)
+
+	err := composite.PublishOrderCompleted(context.Background(), testOrder())
+	if !errors.Is(err, down) || errcode.Of(err) != errcode.NATSAckTimeout {
+		t.Errorf("PublishOrderCompleted() = %v, want %v with its code", err, down)
+	}
+	if pubs["kafka"].Len() != 1 {
+		t.Errorf("kafka received %d orders, want the order published to it anyway", pubs["kafka"].Len())
+	}
+}
+
+func TestCompositeOrderEventPublisherBestEffort(t *testing.T) {
+	composite, pubs := compositeOf(FanOutBestEffort, "kafka", "nats")
+	down := errors.New("broker down")
+	pubs["nats"].FailAll(down)
+	if err := composite.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Errorf("PublishOrderCompleted() = %v with kafka up, want nil", err)
+	}
+
+	pubs["kafka"].FailAll(down)
+	if err := composite.PublishOrderCompleted(context.Background(), testOrder()); !errors.Is(err, down) {
+		t.Errorf("PublishOrderCompleted() = %v with every publisher down, want %v", err, down)
+	}
+}
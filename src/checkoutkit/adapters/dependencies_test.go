@@ -0,0 +1,27 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestLibraryPackagesDoNotImportOTelSDK keeps the packages of the module usable
+// as a library without the OpenTelemetry SDK. They only use the OTel API, whose
+// global providers are no-ops until an application installs the SDK.
+func TestLibraryPackagesDoNotImportOTelSDK(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+	out, err := exec.Command("go", "list", "-deps", "../...").Output()
+	if err != nil {
+		t.Fatalf("go list -deps: %v", err)
+	}
+	for _, pkg := range strings.Fields(string(out)) {
+		if strings.HasPrefix(pkg, "go.opentelemetry.io/otel/sdk") || strings.HasPrefix(pkg, "go.opentelemetry.io/otel/exporters") {
+			t.Errorf("library packages depend on %s, keep SDK code in the services (see the sampling package of the checkout)", pkg)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// DiscountsHeader is the message header listing the discount lines of an
+// order as JSON, since OrderResult has no field for them. Item costs in the
+// order are already discounted.
+const DiscountsHeader = "order.discounts"
+
+// DiscountsVersionHeader is the message header with the version of the
+// DiscountsHeader shape, so that consumers can tell shapes apart as it
+// changes.
+const DiscountsVersionHeader = "order.discounts.version"
+
+// DiscountsVersion is the version of the DiscountsHeader shape written by
+// DiscountHeaders.
+const DiscountsVersion = "1"
+
+// discountLine is the version 1 JSON shape of a ports.Discount.
+type discountLine struct {
+	Code      string     `json:"code"`
+	ProductID string     `json:"productId"`
+	Amount    moneyValue `json:"amount"`
+}
+
+// moneyValue is the JSON shape of money in message headers.
+type moneyValue struct {
+	CurrencyCode string `json:"currencyCode"`
+	Units        int64  `json:"units"`
+	Nanos        int32  `json:"nanos"`
+}
+
+func toMoneyValue(m *pb.Money) moneyValue {
+	return moneyValue{CurrencyCode: m.GetCurrencyCode(), Units: m.GetUnits(), Nanos: m.GetNanos()}
+}
+
+// DiscountHeaders returns the message headers of discounts, to set with
+// WithMessageHeaders, and no headers without discounts.
+func DiscountHeaders(discounts []ports.Discount) (map[string]string, error) {
+	if len(discounts) == 0 {
+		return nil, nil
+	}
+	lines := make([]discountLine, 0, len(discounts))
+	for _, d := range discounts {
+		lines = append(lines, discountLine{
+			Code:      d.Code,
+			ProductID: d.ProductID,
+			Amount:    toMoneyValue(d.Amount),
+		})
+	}
+	body, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		DiscountsHeader:        string(body),
+		DiscountsVersionHeader: DiscountsVersion,
+	}, nil
+}
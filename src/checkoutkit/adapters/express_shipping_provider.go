@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// ExpressShippingProvider ships orders through another provider with priority
+// handling, which costs a flat surcharge on top of its quote.
+type ExpressShippingProvider struct {
+	next      ports.ShippingProvider
+	surcharge *pb.Money
+}
+
+// Compile-time check that ExpressShippingProvider implements SurchargingShippingProvider
+var _ ports.SurchargingShippingProvider = (*ExpressShippingProvider)(nil)
+
+// NewExpressShippingProvider creates an express provider that ships through
+// next and adds surcharge to its quotes.
+func NewExpressShippingProvider(next ports.ShippingProvider, surcharge *pb.Money) *ExpressShippingProvider {
+	return &ExpressShippingProvider{next: next, surcharge: surcharge}
+}
+
+// Quote returns the quote of the wrapped provider plus the surcharge.
+func (p *ExpressShippingProvider) Quote(ctx context.Context, address *pb.Address, items []*pb.CartItem) (*pb.Money, error) {
+	quote, err := p.next.Quote(ctx, address, items)
+	if err != nil {
+		return nil, err
+	}
+	total, err := money.Sum(quote, p.surcharge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add express surcharge to quote: %w", err)
+	}
+	return total, nil
+}
+
+// Surcharge returns the surcharge added to the quotes.
+func (p *ExpressShippingProvider) Surcharge() *pb.Money {
+	return p.surcharge
+}
+
+// Ship ships through the wrapped provider.
+func (p *ExpressShippingProvider) Ship(ctx context.Context, address *pb.Address, items []*pb.CartItem) (string, error) {
+	return p.next.Ship(ctx, address, items)
+}
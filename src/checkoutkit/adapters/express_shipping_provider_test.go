@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestExpressShippingProviderContract(t *testing.T) {
+	testShippingProviderContract(t, func(t *testing.T, down bool) ports.ShippingProvider {
+		standard := NewHTTPShippingProvider(newFakeShippingService(t, down))
+		return NewExpressShippingProvider(standard, &pb.Money{CurrencyCode: "USD", Units: 10})
+	})
+}
+
+func TestExpressShippingProviderQuote(t *testing.T) {
+	standard := NewHTTPShippingProvider(newFakeShippingService(t, false))
+
+	tests := []struct {
+		name      string
+		surcharge *pb.Money
+		want      *pb.Money
+	}{
+		{
+			name:      "adds the surcharge",
+			surcharge: &pb.Money{CurrencyCode: "USD", Units: 10, Nanos: 500000000},
+			want:      &pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 490000000},
+		},
+		{
+			name:      "surcharge in another currency",
+			surcharge: &pb.Money{CurrencyCode: "EUR", Units: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := NewExpressShippingProvider(standard, tt.surcharge).Quote(t.Context(), testShippingAddress(), testCartItems())
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("Quote() = %v, want an error", quote)
+				}
+				return
+			}
+			if err != nil || !money.AreEquals(quote, tt.want) {
+				t.Errorf("Quote() = %v, %v; want %v", quote, err, tt.want)
+			}
+		})
+	}
+}
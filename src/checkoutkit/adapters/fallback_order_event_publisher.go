@@ -0,0 +1,262 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// defaultRecheckInterval is how long a failed primary publisher is bypassed
+// before it is tried again.
+const defaultRecheckInterval = 30 * time.Second
+
+// Attributes set on the caller's span by a FallbackOrderEventPublisher, which
+// record the publisher that took an order event and, for the fallback, why.
+const (
+	PublishPathKey    = attribute.Key("app.order_event.publish_path")
+	FallbackReasonKey = attribute.Key("app.order_event.fallback_reason")
+)
+
+// Values of PublishPathKey.
+const (
+	PublishPathPrimary  = "primary"
+	PublishPathFallback = "fallback"
+)
+
+// Values of FallbackReasonKey.
+const (
+	// FallbackReasonPrimaryFailed means the primary was tried and failed
+	FallbackReasonPrimaryFailed = "primary_failed"
+	// FallbackReasonPrimaryUnhealthy means the primary was skipped because
+	// it failed before and was not due, or healthy, to be tried again
+	FallbackReasonPrimaryUnhealthy = "primary_unhealthy"
+	// FallbackReasonPaused means the publisher was paused
+	FallbackReasonPaused = "paused"
+)
+
+// publisherKindKey names the kind of publisher, such as kafka, that an order
+// event went to or failed on.
+const publisherKindKey = attribute.Key("app.order_event.publisher")
+
+// FallbackOrderEventPublisher implements the OrderEventPublisher port by
+// publishing to a primary publisher and, when that fails, to a fallback such
+// as a SpoolOrderEventPublisher, so that events are kept while the primary
+// transport is down.
+//
+// A failed publish marks the primary unhealthy. Events then go straight to
+// the fallback until the recheck interval has passed, after which the next
+// event tries the primary again. With a health check, the primary is only
+// tried again once the check passes. An event whose acknowledgment timed out
+// may reach both publishers, so consumers deduplicate by order ID.
+//
+// While paused, events go to the fallback and the primary is not tried.
+//
+// The path each event took is recorded on the caller's span as
+// PublishPathKey, with FallbackReasonKey for the fallback, and with the kind
+// of the publisher when they were named WithPublisherKinds.
+type FallbackOrderEventPublisher struct {
+	primary      ports.OrderEventPublisher
+	fallback     ports.OrderEventPublisher
+	primaryKind  string
+	fallbackKind string
+	logger       *slog.Logger
+	check        func(context.Context) error
+	interval     time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	unhealthy bool
+	paused    bool
+	recheckAt time.Time
+	lastFail  PublishFailure
+}
+
+// Compile-time check that FallbackOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*FallbackOrderEventPublisher)(nil)
+
+// Compile-time check that FallbackOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*FallbackOrderEventPublisher)(nil)
+
+// FallbackPublisherOption configures a FallbackOrderEventPublisher.
+type FallbackPublisherOption func(*FallbackOrderEventPublisher)
+
+// WithHealthCheck only switches back to the primary once check passes. It is
+// run at most once per interval while the primary is unhealthy.
+func WithHealthCheck(check func(context.Context) error, interval time.Duration) FallbackPublisherOption {
+	return func(f *FallbackOrderEventPublisher) {
+		f.check = check
+		f.interval = interval
+	}
+}
+
+// WithPrimaryDown starts with the primary marked unhealthy, for a primary
+// whose transport was unreachable on startup. The first event probes it.
+func WithPrimaryDown() FallbackPublisherOption {
+	return func(f *FallbackOrderEventPublisher) {
+		f.unhealthy = true
+	}
+}
+
+// WithPublisherKinds names the kinds of the primary and fallback publishers,
+// such as kafka and spool, on the spans of the events.
+func WithPublisherKinds(primary, fallback string) FallbackPublisherOption {
+	return func(f *FallbackOrderEventPublisher) {
+		f.primaryKind = primary
+		f.fallbackKind = fallback
+	}
+}
+
+// NewFallbackOrderEventPublisher creates a publisher that falls back from
+// primary to fallback.
+func NewFallbackOrderEventPublisher(primary, fallback ports.OrderEventPublisher, logger *slog.Logger, opts ...FallbackPublisherOption) *FallbackOrderEventPublisher {
+	f := &FallbackOrderEventPublisher{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+		interval: defaultRecheckInterval,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// PublishOrderCompleted publishes the order to the primary publisher while it
+// is healthy and to the fallback otherwise.
+func (f *FallbackOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	span := trace.SpanFromContext(ctx)
+	reason := f.skipPrimary(ctx)
+	if reason == "" {
+		err := f.primary.PublishOrderCompleted(ctx, order)
+		if err == nil {
+			f.setHealthy(ctx)
+			span.SetAttributes(f.pathAttributes(PublishPathPrimary, f.primaryKind)...)
+			return nil
+		}
+		f.setUnhealthy(err)
+		f.logger.WarnContext(ctx, "primary order event publisher failed, using the fallback",
+			slog.String("order_id", order.GetOrderId()),
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+		span.AddEvent("order event publisher fallback",
+			trace.WithAttributes(errcode.Key.String(string(errcode.Of(err)))))
+		reason = FallbackReasonPrimaryFailed
+	}
+	span.SetAttributes(f.pathAttributes(PublishPathFallback, f.fallbackKind)...)
+	span.SetAttributes(FallbackReasonKey.String(reason))
+	return f.fallback.PublishOrderCompleted(ctx, order)
+}
+
+// pathAttributes returns the attributes of an event that took path to a
+// publisher of kind.
+func (f *FallbackOrderEventPublisher) pathAttributes(path, kind string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{PublishPathKey.String(path)}
+	if kind != "" {
+		attrs = append(attrs, publisherKindKey.String(kind))
+	}
+	return attrs
+}
+
+// Close closes the primary publisher and then the fallback, so that events
+// published while the primary drains still reach the fallback.
+func (f *FallbackOrderEventPublisher) Close(ctx context.Context) error {
+	return errors.Join(closeIfLifecycle(ctx, f.primary), closeIfLifecycle(ctx, f.fallback))
+}
+
+// Healthy reports whether events currently go to the primary publisher.
+func (f *FallbackOrderEventPublisher) Healthy() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.unhealthy && !f.paused
+}
+
+// Pause sends every event to the fallback until Resume.
+func (f *FallbackOrderEventPublisher) Pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+}
+
+// Resume tries the primary publisher again from the next event.
+func (f *FallbackOrderEventPublisher) Resume() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = false
+	f.recheckAt = time.Time{}
+}
+
+// Paused reports whether the publisher is paused.
+func (f *FallbackOrderEventPublisher) Paused() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paused
+}
+
+// LastFailure returns the last failed publish of the primary publisher, the
+// zero PublishFailure if none failed.
+func (f *FallbackOrderEventPublisher) LastFailure() PublishFailure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastFail
+}
+
+// skipPrimary returns why the primary should not be tried, or "" if it
+// should, running the health check once the recheck interval of an unhealthy
+// primary has passed.
+func (f *FallbackOrderEventPublisher) skipPrimary(ctx context.Context) string {
+	f.mu.Lock()
+	if f.paused {
+		f.mu.Unlock()
+		return FallbackReasonPaused
+	}
+	if !f.unhealthy {
+		f.mu.Unlock()
+		return ""
+	}
+	if f.now().Before(f.recheckAt) {
+		f.mu.Unlock()
+		return FallbackReasonPrimaryUnhealthy
+	}
+	// Let one event probe the primary while the others keep using the fallback
+	f.recheckAt = f.now().Add(f.interval)
+	f.mu.Unlock()
+
+	if f.check == nil {
+		return ""
+	}
+	if err := f.check(ctx); err != nil {
+		f.logger.DebugContext(ctx, "primary order event publisher still unhealthy", slog.String("error", err.Error()))
+		return FallbackReasonPrimaryUnhealthy
+	}
+	return ""
+}
+
+func (f *FallbackOrderEventPublisher) setUnhealthy(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unhealthy = true
+	f.recheckAt = f.now().Add(f.interval)
+	f.lastFail = PublishFailure{Err: err, At: f.now()}
+}
+
+func (f *FallbackOrderEventPublisher) setHealthy(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.unhealthy {
+		f.logger.InfoContext(ctx, "primary order event publisher recovered")
+	}
+	f.unhealthy = false
+}
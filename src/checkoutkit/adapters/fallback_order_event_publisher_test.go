@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestFallbackOrderEventPublisher(t *testing.T) {
+	primary := &recordingPublisher{}
+	fallback := &recordingPublisher{}
+	var checks int
+	checkErr := errors.New("broker unreachable")
+	pub := NewFallbackOrderEventPublisher(primary, fallback, discardLogger(), WithHealthCheck(func(context.Context) error {
+		checks++
+		return checkErr
+	}, time.Minute))
+	clock := testdata.NewClock()
+	pub.now = clock.Now
+	ctx := context.Background()
+
+	publish := func(wantPrimary, wantFallback int) {
+		t.Helper()
+		if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+		if len(primary.orders) != wantPrimary || len(fallback.orders) != wantFallback {
+			t.Fatalf("primary got %d orders and fallback %d, want %d and %d", len(primary.orders), len(fallback.orders), wantPrimary, wantFallback)
+		}
+	}
+
+	publish(1, 0)
+
+	// A failed publish is retried on the fallback, which is then used alone
+	primary.err = errors.New("kafka down")
+	publish(2, 1)
+	if pub.Healthy() {
+		t.Error("Healthy() = true after a failed publish, want false")
+	}
+	publish(2, 2)
+
+	// Once the interval has passed, the health check gates the primary
+	clock.Advance(time.Minute)
+	publish(2, 3)
+	if checks != 1 {
+		t.Errorf("ran %d health checks, want 1", checks)
+	}
+	publish(2, 4)
+
+	clock.Advance(time.Minute)
+	primary.err, checkErr = nil, nil
+	publish(3, 4)
+	if !pub.Healthy() || checks != 2 {
+		t.Errorf("Healthy() = %v after %d checks, want true after 2", pub.Healthy(), checks)
+	}
+}
+
+func TestFallbackOrderEventPublisherWithoutHealthCheck(t *testing.T) {
+	primary := &recordingPublisher{err: errors.New("webhook down")}
+	fallback := &recordingPublisher{err: errors.New("disk full")}
+	pub := NewFallbackOrderEventPublisher(primary, fallback, discardLogger())
+	clock := testdata.NewClock()
+	pub.now = clock.Now
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != fallback.err {
+		t.Errorf("PublishOrderCompleted() = %v, want the fallback error %v", err, fallback.err)
+	}
+
+	// Without a health check, the primary is tried again after the interval
+	clock.Advance(defaultRecheckInterval)
+	primary.err = nil
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil || len(primary.orders) != 2 {
+		t.Errorf("PublishOrderCompleted() = %v with %d primary publishes, want the primary retried", err, len(primary.orders))
+	}
+}
+
+func TestFallbackOrderEventPublisherCloseDrainsIntoFallback(t *testing.T) {
+	spool := NewSpoolOrderEventPublisher(filepath.Join(t.TempDir(), "orders.spool"), discardLogger())
+	primary := NewSpoolOrderEventPublisher(filepath.Join(t.TempDir(), "primary.spool"), discardLogger())
+	pub := NewValidatingOrderEventPublisher(NewFallbackOrderEventPublisher(primary, spool, discardLogger()), discardLogger())
+
+	// Events published while the primary is closed still reach the fallback
+	if err := primary.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v, want the order spooled", err)
+	}
+
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want a %s error", err, errcode.PublisherClosed)
+	}
+}
+
+func TestFallbackOrderEventPublisherPause(t *testing.T) {
+	primary := &recordingPublisher{}
+	fallback := &recordingPublisher{}
+	pub := NewFallbackOrderEventPublisher(primary, fallback, discardLogger())
+	clock := testdata.NewClock()
+	pub.now = clock.Now
+	ctx := context.Background()
+
+	// While paused, orders go to the fallback and the primary is not retried
+	pub.Pause()
+	clock.Advance(defaultRecheckInterval)
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(primary.orders) != 0 || len(fallback.orders) != 1 {
+		t.Fatalf("primary got %d orders and fallback %d while paused, want 0 and 1", len(primary.orders), len(fallback.orders))
+	}
+	if !pub.Paused() || pub.Healthy() {
+		t.Errorf("Paused() = %v and Healthy() = %v, want true and false", pub.Paused(), pub.Healthy())
+	}
+
+	pub.Resume()
+	primary.err = errors.New("kafka down")
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(primary.orders) != 1 {
+		t.Errorf("primary got %d orders after Resume, want 1", len(primary.orders))
+	}
+	if last := pub.LastFailure(); last.Err != primary.err || !last.At.Equal(clock.Now()) {
+		t.Errorf("LastFailure() = %v, want %v at %v", last, primary.err, clock.Now())
+	}
+}
+
+func TestFallbackOrderEventPublisherRecordsPath(t *testing.T) {
+	recorder := newTestTracing(t)
+	primary := &recordingPublisher{}
+	pub := NewFallbackOrderEventPublisher(primary, &recordingPublisher{}, discardLogger(), WithPublisherKinds(PublisherKafka, PublisherFile))
+
+	publish := func(name string) map[string]string {
+		t.Helper()
+		ctx, span := otel.Tracer("test").Start(context.Background(), name)
+		if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+		span.End()
+		attrs := map[string]string{}
+		for _, kv := range endedSpan(t, recorder, name).Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		return attrs
+	}
+	path := func(attrs map[string]string) string {
+		return attrs[string(PublishPathKey)] + "/" + attrs[string(publisherKindKey)] + "/" + attrs[string(FallbackReasonKey)]
+	}
+
+	if got := path(publish("healthy")); got != "primary/kafka/" {
+		t.Errorf("healthy publish took %s, want the primary", got)
+	}
+	primary.err = errors.New("kafka down")
+	if got := path(publish("failed")); got != "fallback/file/primary_failed" {
+		t.Errorf("failed publish took %s, want the fallback after the primary failed", got)
+	}
+	if got := path(publish("unhealthy")); got != "fallback/file/primary_unhealthy" {
+		t.Errorf("publish with the primary unhealthy took %s, want the fallback", got)
+	}
+	pub.Pause()
+	if got := path(publish("paused")); got != "fallback/file/paused" {
+		t.Errorf("paused publish took %s, want the fallback", got)
+	}
+}
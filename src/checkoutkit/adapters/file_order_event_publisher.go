@@ -0,0 +1,292 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Record formats of the file publisher.
+const (
+	// FileFormatJSON writes one protojson document per line, as the spool
+	// does, so that the file can be read with jq or ReadSpool.
+	FileFormatJSON = "json"
+	// FileFormatProtobuf writes each order in protobuf, prefixed with its
+	// size as a varint, as protodelim does.
+	FileFormatProtobuf = "protobuf"
+)
+
+// FileOrderEventPublisher implements the OrderEventPublisher port by
+// appending every order to a local file, for demos, debugging, and as a
+// source for cmd/replay. Unlike the spool, the file is not a fallback and is
+// never emptied: with rotation, once a record would grow it past the maximum
+// size, the file is renamed to path.1, the previous path.1 to path.2, and so
+// on up to the number of backups kept.
+type FileOrderEventPublisher struct {
+	path       string
+	format     string
+	maxBytes   int64
+	maxBackups int
+	logger     *slog.Logger
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// Compile-time check that FileOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*FileOrderEventPublisher)(nil)
+
+// Compile-time check that FileOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*FileOrderEventPublisher)(nil)
+
+// FilePublisherOption configures optional behavior of a
+// FileOrderEventPublisher.
+type FilePublisherOption func(*FileOrderEventPublisher)
+
+// WithFileFormat sets the format of the records, FileFormatJSON by default.
+func WithFileFormat(format string) FilePublisherOption {
+	return func(f *FileOrderEventPublisher) {
+		f.format = format
+	}
+}
+
+// WithFileRotation rotates the file once a record would grow it past
+// maxBytes, keeping maxBackups rotated files. With no backups the file is
+// truncated instead. By default the file is never rotated.
+func WithFileRotation(maxBytes int64, maxBackups int) FilePublisherOption {
+	return func(f *FileOrderEventPublisher) {
+		f.maxBytes = maxBytes
+		f.maxBackups = maxBackups
+	}
+}
+
+// NewFileOrderEventPublisher creates a publisher that appends orders to path.
+// The file is created on the first publish.
+func NewFileOrderEventPublisher(path string, logger *slog.Logger, opts ...FilePublisherOption) *FileOrderEventPublisher {
+	f := &FileOrderEventPublisher{
+		path:   path,
+		format: FileFormatJSON,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// PublishOrderCompleted appends the order to the file, rotating it first if
+// the record would grow it past the maximum size.
+func (f *FileOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	record, err := encodeFileRecord(f.format, order)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return errcode.Errorf(errcode.PublisherClosed, "order event file %s is closed", f.path)
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(record)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return errcode.Errorf(errcode.FileWriteFailed, "failed to rotate order event file: %w", err)
+		}
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return errcode.Errorf(errcode.FileWriteFailed, "failed to open order event file: %w", err)
+		}
+	}
+	n, err := f.file.Write(record)
+	f.size += int64(n)
+	if err != nil {
+		return errcode.Errorf(errcode.FileWriteFailed, "failed to write to order event file: %w", err)
+	}
+	f.logger.DebugContext(ctx, "Wrote order event to file",
+		slog.String("order_id", order.GetOrderId()),
+		slog.String("path", f.path),
+	)
+	return nil
+}
+
+// encodeFileRecord returns the record of order in format.
+func encodeFileRecord(format string, order *pb.OrderResult) ([]byte, error) {
+	switch format {
+	case FileFormatJSON:
+		line, err := protojson.Marshal(order)
+		if err != nil {
+			return nil, errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to JSON: %w", err)
+		}
+		return append(line, '\n'), nil
+	case FileFormatProtobuf:
+		var buf bytes.Buffer
+		if _, err := protodelim.MarshalTo(&buf, order); err != nil {
+			return nil, errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to protobuf: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errcode.Errorf(errcode.SerializationFailed, "unknown order event file format %q, expected json or protobuf", format)
+	}
+}
+
+// open opens the file for appending. f.mu must be held.
+func (f *FileOrderEventPublisher) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate closes the file and shifts it and its backups by one, dropping the
+// oldest. f.mu must be held.
+func (f *FileOrderEventPublisher) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	if f.maxBackups == 0 {
+		return os.Truncate(f.path, 0)
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	f.logger.Info("Rotated order event file", slog.String("path", f.path))
+	return nil
+}
+
+// Close flushes the file to stable storage and closes it. Later events fail
+// with PUBLISHER_CLOSED.
+func (f *FileOrderEventPublisher) Close(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Sync()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.file = nil
+	if err != nil {
+		return errcode.Errorf(errcode.FileWriteFailed, "failed to flush order event file: %w", err)
+	}
+	return nil
+}
+
+// ReadOrderEventFiles returns the orders written by a file publisher to path
+// in format, oldest first: those of the rotated files from the highest
+// number down, then those of path. In JSON, unreadable lines are skipped and
+// counted. In protobuf, an unreadable record, such as one cut short by a
+// crash, ends its file and counts as one.
+func ReadOrderEventFiles(path, format string) (orders []*pb.OrderResult, unreadable int, err error) {
+	if format != FileFormatJSON && format != FileFormatProtobuf {
+		return nil, 0, fmt.Errorf("unknown order event file format %q, expected json or protobuf", format)
+	}
+	files, err := rotatedFiles(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, file := range append(files, path) {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read order event file: %w", err)
+		}
+		read, bad := decodeFileRecords(format, data)
+		orders = append(orders, read...)
+		unreadable += bad
+	}
+	return orders, unreadable, nil
+}
+
+// rotatedFiles returns the rotated files of path, the oldest first.
+func rotatedFiles(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated order event files: %w", err)
+	}
+	numbers := map[string]int{}
+	var files []string
+	for _, match := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(match, path+"."))
+		if err != nil || n < 1 {
+			continue
+		}
+		numbers[match] = n
+		files = append(files, match)
+	}
+	sort.Slice(files, func(i, j int) bool { return numbers[files[i]] > numbers[files[j]] })
+	return files, nil
+}
+
+// decodeFileRecords returns the orders of the records in data.
+func decodeFileRecords(format string, data []byte) (orders []*pb.OrderResult, unreadable int) {
+	if format == FileFormatJSON {
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			order := &pb.OrderResult{}
+			if err := protojson.Unmarshal(line, order); err != nil {
+				unreadable++
+				continue
+			}
+			orders = append(orders, order)
+		}
+		return orders, unreadable
+	}
+	r := bufio.NewReader(bytes.NewReader(data))
+	for {
+		order := &pb.OrderResult{}
+		err := protodelim.UnmarshalFrom(r, order)
+		if errors.Is(err, io.EOF) {
+			return orders, unreadable
+		}
+		if err != nil {
+			return orders, unreadable + 1
+		}
+		orders = append(orders, order)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+// publishOrders publishes an order with each of ids.
+func publishOrders(t *testing.T, pub *FileOrderEventPublisher, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		order := testOrder()
+		order.OrderId = id
+		if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+			t.Fatalf("PublishOrderCompleted(%s) = %v", id, err)
+		}
+	}
+}
+
+func TestFileOrderEventPublisher(t *testing.T) {
+	for _, format := range []string{FileFormatJSON, FileFormatProtobuf} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "orders")
+			pub := NewFileOrderEventPublisher(path, discardLogger(), WithFileFormat(format))
+			publishOrders(t, pub, "order-1", "order-2")
+			if err := pub.Close(context.Background()); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+
+			orders, unreadable, err := ReadOrderEventFiles(path, format)
+			if err != nil || unreadable != 0 || len(orders) != 2 {
+				t.Fatalf("ReadOrderEventFiles() = %d orders, %d unreadable, %v, want 2 orders", len(orders), unreadable, err)
+			}
+			want := testOrder()
+			want.OrderId = "order-1"
+			if !proto.Equal(orders[0], want) || orders[1].GetOrderId() != "order-2" {
+				t.Errorf("ReadOrderEventFiles() = %v, want the orders as published", orders)
+			}
+
+			err = pub.PublishOrderCompleted(context.Background(), testOrder())
+			if errcode.Of(err) != errcode.PublisherClosed {
+				t.Errorf("PublishOrderCompleted() after Close = %v, want %s", err, errcode.PublisherClosed)
+			}
+		})
+	}
+}
+
+func TestFileOrderEventPublisherJSONIsReadableAsSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	pub := NewFileOrderEventPublisher(path, discardLogger())
+	defer pub.Close(context.Background())
+	publishOrders(t, pub, "order-1")
+
+	orders, _, err := ReadSpool(path)
+	if err != nil || len(orders) != 1 || orders[0].GetOrderId() != "order-1" {
+		t.Errorf("ReadSpool() = %v, %v, want order-1", orders, err)
+	}
+}
+
+func TestFileOrderEventPublisherRotation(t *testing.T) {
+	record, err := encodeFileRecord(FileFormatJSON, testOrder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	// Two orders fit in a file, and two rotated files are kept
+	pub := NewFileOrderEventPublisher(path, discardLogger(), WithFileRotation(int64(2*len(record)+1), 2))
+	defer pub.Close(context.Background())
+	publishOrders(t, pub, "a", "b", "c", "d", "e", "f", "g")
+
+	for file, want := range map[string]int{path: 1, path + ".1": 2, path + ".2": 2} {
+		orders, _, err := ReadSpool(file)
+		if err != nil || len(orders) != want {
+			t.Errorf("%s holds %d orders (%v), want %d", filepath.Base(file), len(orders), err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Stat(%s.3) = %v, want it dropped", filepath.Base(path), err)
+	}
+	orders, _, err := ReadOrderEventFiles(path, FileFormatJSON)
+	if got := fmt.Sprint(orderIDs(orders)); err != nil || got != "[c d e f g]" {
+		t.Errorf("ReadOrderEventFiles() = %s, %v, want the kept orders oldest first", got, err)
+	}
+}
+
+func TestFileOrderEventPublisherRotationWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	pub := NewFileOrderEventPublisher(path, discardLogger(), WithFileRotation(1, 0))
+	defer pub.Close(context.Background())
+	publishOrders(t, pub, "a", "b")
+
+	orders, _, err := ReadOrderEventFiles(path, FileFormatJSON)
+	if got := fmt.Sprint(orderIDs(orders)); err != nil || got != "[b]" {
+		t.Errorf("ReadOrderEventFiles() = %s, %v, want only the last order", got, err)
+	}
+}
+
+func TestReadOrderEventFilesUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "orders.jsonl")
+	if err := os.WriteFile(jsonPath, []byte("{\"orderId\":\"a\"}\nnot json\n{\"orderId\":\"b\"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	orders, unreadable, err := ReadOrderEventFiles(jsonPath, FileFormatJSON)
+	if got := fmt.Sprint(orderIDs(orders)); err != nil || got != "[a b]" || unreadable != 1 {
+		t.Errorf("ReadOrderEventFiles(json) = %s, %d unreadable, %v, want a and b with 1 unreadable", got, unreadable, err)
+	}
+
+	// A record cut short by a crash ends the file
+	protoPath := filepath.Join(dir, "orders.pb")
+	pub := NewFileOrderEventPublisher(protoPath, discardLogger(), WithFileFormat(FileFormatProtobuf))
+	publishOrders(t, pub, "a", "b")
+	pub.Close(context.Background())
+	info, err := os.Stat(protoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(protoPath, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+	orders, unreadable, err = ReadOrderEventFiles(protoPath, FileFormatProtobuf)
+	if got := fmt.Sprint(orderIDs(orders)); err != nil || got != "[a]" || unreadable != 1 {
+		t.Errorf("ReadOrderEventFiles(protobuf) = %s, %d unreadable, %v, want a with 1 unreadable", got, unreadable, err)
+	}
+
+	if _, _, err := ReadOrderEventFiles(jsonPath, "avro"); err == nil {
+		t.Error("ReadOrderEventFiles() in an unknown format = nil, want an error")
+	}
+}
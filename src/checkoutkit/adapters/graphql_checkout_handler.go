@@ -0,0 +1,209 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// GraphQLCheckoutHandler is the GraphQL gateway of the checkout service, for
+// frontends that consume checkout through GraphQL. It executes operations of
+// the schema in schemas/checkout.graphql with graphql-go, on the
+// CheckoutUseCase port:
+//
+//	query    order(userId, orderId)                    GetOrder
+//	query    orders(userId, pageSize, pageToken)       ListOrders
+//	mutation placeOrder(input, idempotencyKey, async)  PlaceOrder
+//
+// Requests follow GraphQL over HTTP: a POST with a JSON body of query,
+// operationName and variables, or a GET with the same query parameters for
+// queries. Documents that do not parse or validate against the schema, and
+// requests whose variables do not match it, are rejected with 400 before
+// anything runs. Object fields resolve to the fields of the proto messages
+// with the same JSON name. Errors of the port are reported with the gRPC
+// status code name in extensions.code and validation failures in
+// extensions.violations, except that an unknown order is null.
+type GraphQLCheckoutHandler struct {
+	svc    ports.CheckoutUseCase
+	logger *slog.Logger
+}
+
+// Compile-time check that GraphQLCheckoutHandler implements http.Handler
+var _ http.Handler = (*GraphQLCheckoutHandler)(nil)
+
+// NewGraphQLCheckoutHandler creates the GraphQL gateway of svc.
+func NewGraphQLCheckoutHandler(svc ports.CheckoutUseCase, logger *slog.Logger) *GraphQLCheckoutHandler {
+	return &GraphQLCheckoutHandler{svc: svc, logger: logger}
+}
+
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type graphQLResponse struct {
+	// Data is omitted for the errors of a request that did not execute
+	Data   any                        `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// ServeHTTP executes a GraphQL request.
+func (h *GraphQLCheckoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := decodeGraphQLJSON(strings.NewReader(v), &req.Variables); err != nil {
+				h.writeErrors(w, r, http.StatusBadRequest, fmt.Sprintf("invalid variables: %v", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := decodeGraphQLJSON(http.MaxBytesReader(w, r.Body, maxOrderRequestBytes), &req); err != nil {
+			h.writeErrors(w, r, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		h.writeErrors(w, r, http.StatusMethodNotAllowed, "GraphQL requests must use GET or POST")
+		return
+	}
+	if req.Query == "" {
+		h.writeErrors(w, r, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	schema, err := GraphQLCheckoutSchema()
+	if err != nil {
+		h.writeErrors(w, r, http.StatusInternalServerError, fmt.Sprintf("invalid GraphQL schema: %v", err))
+		return
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		h.writeJSON(w, r, http.StatusBadRequest, graphQLResponse{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+		h.writeJSON(w, r, http.StatusBadRequest, graphQLResponse{Errors: result.Errors})
+		return
+	}
+	if operationType(doc, req.OperationName) == ast.OperationTypeMutation && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		h.writeErrors(w, r, http.StatusMethodNotAllowed, "mutations must use POST")
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		Root:          h.svc,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       r.Context(),
+	})
+	if result.Data == nil && !executed(result.Errors) {
+		// The operation or its variables were rejected before executing
+		h.writeJSON(w, r, http.StatusBadRequest, graphQLResponse{Errors: result.Errors})
+		return
+	}
+	data := result.Data
+	if data == nil {
+		// An error of a non-null root field nulls the data, which is kept
+		data = json.RawMessage("null")
+	}
+	h.writeJSON(w, r, http.StatusOK, graphQLResponse{Data: data, Errors: result.Errors})
+}
+
+// decodeGraphQLJSON decodes JSON into v with integers as int64, so that the
+// Int64 scalar keeps their precision, and other numbers as float64, which
+// graphql-go coerces.
+func decodeGraphQLJSON(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *graphQLRequest:
+		v.Variables, _ = plainNumbers(v.Variables).(map[string]any)
+	case *map[string]any:
+		*v, _ = plainNumbers(*v).(map[string]any)
+	}
+	return nil
+}
+
+// plainNumbers replaces the json.Number values of v.
+func plainNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = plainNumbers(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = plainNumbers(value)
+		}
+	}
+	return v
+}
+
+// operationType returns the type of the operation of doc named name, or of
+// its only operation without a name, and "" if there is none.
+func operationType(doc *ast.Document, name string) string {
+	var ops []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			if name == "" || (op.Name != nil && op.Name.Value == name) {
+				ops = append(ops, op)
+			}
+		}
+	}
+	if len(ops) != 1 {
+		return ""
+	}
+	return ops[0].Operation
+}
+
+// executed reports whether errs are those of an executed operation, which
+// have the path of their field.
+func executed(errs []gqlerrors.FormattedError) bool {
+	for _, err := range errs {
+		if len(err.Path) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *GraphQLCheckoutHandler) writeErrors(w http.ResponseWriter, r *http.Request, code int, message string) {
+	h.writeJSON(w, r, code, graphQLResponse{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)}})
+}
+
+func (h *GraphQLCheckoutHandler) writeJSON(w http.ResponseWriter, r *http.Request, code int, body graphQLResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to write response", slog.String("error", err.Error()))
+	}
+}
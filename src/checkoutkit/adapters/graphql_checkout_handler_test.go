@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// graphQL posts query with variables to h and returns the status and body.
+func graphQL(t *testing.T, h http.Handler, query string, variables map[string]any) (int, string) {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestGraphQLCheckoutHandler(t *testing.T) {
+	h := NewGraphQLCheckoutHandler(&fakeCheckoutServer{}, discardLogger())
+
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]any
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "order",
+			query:      `{ order(userId: "user-1", orderId: "order-1") { orderId shippingCost { units currencyCode } items { item { productId } } } }`,
+			wantStatus: http.StatusOK,
+			want:       `{"data":{"order":{"items":[{"item":{"productId":"SKU-1"}}],"orderId":"order-1","shippingCost":{"currencyCode":"USD","units":5}}}}`,
+		},
+		{
+			name: "fragments and directives",
+			query: `query ($withCost: Boolean!) {
+				order(userId: "user-1", orderId: "order-1") { ...Order shippingCost @include(if: $withCost) { units } }
+			}
+			fragment Order on OrderResult { orderId }`,
+			variables:  map[string]any{"withCost": false},
+			wantStatus: http.StatusOK,
+			want:       `{"data":{"order":{"orderId":"order-1"}}}`,
+		},
+		{
+			name:       "unknown order is null",
+			query:      `query Order($id: String!) { order(userId: "user-1", orderId: $id) { orderId } }`,
+			variables:  map[string]any{"id": "order-2"},
+			wantStatus: http.StatusOK,
+			want:       `{"data":{"order":null}}`,
+		},
+		{
+			name:       "orders with aliases",
+			query:      `query { __typename page: orders(userId: "user-1", pageSize: 10) { next: nextPageToken orders { __typename orderId } } }`,
+			wantStatus: http.StatusOK,
+			want:       `{"data":{"__typename":"Query","page":{"next":"next","orders":[{"__typename":"OrderResult","orderId":"order-1"}]}}}`,
+		},
+		{
+			name: "place order",
+			query: `mutation Place($input: PlaceOrderRequestInput!) {
+				placeOrder(input: $input, idempotencyKey: "req-1") { orderId shippingTrackingId }
+			}`,
+			variables:  map[string]any{"input": map[string]any{"userId": "user-1", "address": map[string]any{"city": "Anytown"}}},
+			wantStatus: http.StatusOK,
+			want:       `{"data":{"placeOrder":{"orderId":"order-1","shippingTrackingId":"trk-1"}}}`,
+		},
+		{
+			name:       "port error",
+			query:      `mutation { placeOrder(input: {userCurrency: "USD"}) { orderId } }`,
+			wantStatus: http.StatusOK,
+			// placeOrder is non-null, so its error nulls the data
+			want: `{"data":null,"errors":[{"message":"invalid order result: user_id: must be set","locations":[{"line":1,"column":12}],"path":["placeOrder"],"extensions":{"code":"INVALID_ARGUMENT","violations":[{"field":"user_id","rule":"required","message":"must be set"}]}}]}`,
+		},
+		{
+			name:       "unknown input field",
+			query:      `mutation { placeOrder(input: {userId: "user-1", coupon: "FREE"}) { orderId } }`,
+			wantStatus: http.StatusBadRequest,
+			want:       `In field \"coupon\": Unknown field.`,
+		},
+		{
+			name:       "unknown field",
+			query:      `{ order(userId: "user-1", orderId: "order-1") { orderId total } }`,
+			wantStatus: http.StatusBadRequest,
+			want:       `{"errors":[{"message":"Cannot query field \"total\" on type \"OrderResult\".","locations":[{"line":1,"column":57}]}]}`,
+		},
+		{
+			name:       "missing selection",
+			query:      `{ order(userId: "user-1", orderId: "order-1") }`,
+			wantStatus: http.StatusBadRequest,
+			want:       `Field \"order\" of type \"OrderResult\" must have a sub selection.`,
+		},
+		{
+			name:       "missing argument",
+			query:      `{ order(userId: "user-1") { orderId } }`,
+			wantStatus: http.StatusBadRequest,
+			want:       `Field \"order\" argument \"orderId\" of type \"String!\" is required but not provided.`,
+		},
+		{
+			name:       "missing variable",
+			query:      `query ($id: String!) { order(userId: "user-1", orderId: $id) { orderId } }`,
+			wantStatus: http.StatusBadRequest,
+			want:       `Variable \"$id\" of required type \"String!\" was not provided.`,
+		},
+		{
+			name:       "syntax error",
+			query:      `{ order(userId: "user-1" { orderId } }`,
+			wantStatus: http.StatusBadRequest,
+			want:       `Syntax Error GraphQL (1:26) Expected Name, found {`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := graphQL(t, h, tt.query, tt.variables)
+			if code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", code, tt.wantStatus, body)
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("body = %s, want it to contain %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLCheckoutHandlerPlaceOrderMetadata(t *testing.T) {
+	svc := &fakeCheckoutServer{}
+	h := NewGraphQLCheckoutHandler(svc, discardLogger())
+
+	code, body := graphQL(t, h, `mutation { placeOrder(input: {userId: "user-1"}, idempotencyKey: "req-1", async: true) { orderId } }`, nil)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (%s)", code, http.StatusOK, body)
+	}
+	for key, want := range map[string]string{"idempotency-key": "req-1", "place-order-mode": "async"} {
+		if got := svc.md.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("PlaceOrder() metadata %s = %v, want %q", key, got, want)
+		}
+	}
+}
+
+func TestGraphQLCheckoutHandlerGet(t *testing.T) {
+	h := NewGraphQLCheckoutHandler(&fakeCheckoutServer{}, discardLogger())
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"query", `{ order(userId: "user-1", orderId: "order-1") { orderId } }`, http.StatusOK},
+		{"mutation", `mutation { placeOrder(input: {userId: "user-1"}) { orderId } }`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(tt.query), nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s = %d, want %d (%s)", tt.query, rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
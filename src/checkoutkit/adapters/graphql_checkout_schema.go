@@ -0,0 +1,321 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// graphQLInputSuffix is appended to a message name to name its input type.
+const graphQLInputSuffix = "Input"
+
+// GraphQLCheckoutSchema returns the executable schema of
+// schemas/checkout.graphql, built with graphql-go from the descriptors of
+// the messages like the SDL is. Its root fields expect the CheckoutUseCase
+// they call as the root value of the execution.
+var GraphQLCheckoutSchema = sync.OnceValues(func() (graphql.Schema, error) {
+	types := &graphQLTypes{
+		objects: map[protoreflect.FullName]*graphql.Object{},
+		inputs:  map[protoreflect.FullName]*graphql.InputObject{},
+	}
+	orderResult := (&pb.OrderResult{}).ProtoReflect().Descriptor()
+	listOrders := (&pb.ListOrdersResponse{}).ProtoReflect().Descriptor()
+	placeOrder := (&pb.PlaceOrderRequest{}).ProtoReflect().Descriptor()
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"order": &graphql.Field{
+				Description: "Returns an order the user placed (CheckoutService.GetOrder).",
+				Type:        types.object(orderResult),
+				Args: graphql.FieldConfigArgument{
+					"userId":  {Type: graphql.NewNonNull(graphql.String)},
+					"orderId": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: resolveOrder,
+			},
+			"orders": &graphql.Field{
+				Description: "Returns a page of the orders the user placed, newest first (CheckoutService.ListOrders).",
+				Type:        graphql.NewNonNull(types.object(listOrders)),
+				Args: graphql.FieldConfigArgument{
+					"userId":    {Type: graphql.NewNonNull(graphql.String)},
+					"pageSize":  {Type: graphql.Int},
+					"pageToken": {Type: graphql.String},
+				},
+				Resolve: resolveOrders,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"placeOrder": &graphql.Field{
+				Description: "Places an order (CheckoutService.PlaceOrder). A retried call with the same " +
+					"idempotencyKey returns the original order. With async, the order only carries " +
+					"its ID and is completed in the background.",
+				Type: graphql.NewNonNull(types.object(orderResult)),
+				Args: graphql.FieldConfigArgument{
+					"input":          {Type: graphql.NewNonNull(types.input(placeOrder))},
+					"idempotencyKey": {Type: graphql.String},
+					"async":          {Type: graphql.Boolean},
+				},
+				Resolve: resolvePlaceOrder,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+})
+
+// graphQLInt64 is the Int64 scalar, since GraphQL Int is 32-bit signed.
+var graphQLInt64 = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Int64",
+	Description: "64-bit integer, serialized as a JSON number like in the consumer JSON format.",
+	Serialize: func(value any) any {
+		return value
+	},
+	ParseValue: func(value any) any {
+		switch v := value.(type) {
+		case int:
+			return int64(v)
+		case int64:
+			return v
+		case float64:
+			if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+				return int64(v)
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) any {
+		if v, ok := value.(*ast.IntValue); ok {
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+// graphQLTypes are the object and input types of the messages, created on
+// first use so that recursive messages resolve to the same type.
+type graphQLTypes struct {
+	objects map[protoreflect.FullName]*graphql.Object
+	inputs  map[protoreflect.FullName]*graphql.InputObject
+}
+
+// object returns the object type of md, whose fields have the JSON names of
+// the message fields and resolve from a protoreflect.Message.
+func (t *graphQLTypes) object(md protoreflect.MessageDescriptor) *graphql.Object {
+	if o, ok := t.objects[md.FullName()]; ok {
+		return o
+	}
+	o := graphql.NewObject(graphql.ObjectConfig{
+		Name:        string(md.Name()),
+		Description: fmt.Sprintf("Proto message %s.", md.FullName()),
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := graphql.Fields{}
+			for i := 0; i < md.Fields().Len(); i++ {
+				fd := md.Fields().Get(i)
+				fields[fd.JSONName()] = &graphql.Field{Type: t.fieldType(fd, false), Resolve: resolveMessageField(fd)}
+			}
+			return fields
+		}),
+	})
+	t.objects[md.FullName()] = o
+	return o
+}
+
+// input returns the input type of md, named with graphQLInputSuffix.
+func (t *graphQLTypes) input(md protoreflect.MessageDescriptor) *graphql.InputObject {
+	if in, ok := t.inputs[md.FullName()]; ok {
+		return in
+	}
+	in := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        string(md.Name()) + graphQLInputSuffix,
+		Description: fmt.Sprintf("Proto message %s.", md.FullName()),
+		Fields: graphql.InputObjectConfigFieldMapThunk(func() graphql.InputObjectConfigFieldMap {
+			fields := graphql.InputObjectConfigFieldMap{}
+			for i := 0; i < md.Fields().Len(); i++ {
+				fd := md.Fields().Get(i)
+				fields[fd.JSONName()] = &graphql.InputObjectFieldConfig{Type: t.fieldType(fd, true)}
+			}
+			return fields
+		}),
+	})
+	t.inputs[md.FullName()] = in
+	return in
+}
+
+// fieldType returns the GraphQL type of fd, like schema.GraphQLType in the
+// SDL. Scalars of object types are non-null because the consumer JSON format
+// always sets them, while input fields are optional like in proto3.
+func (t *graphQLTypes) fieldType(fd protoreflect.FieldDescriptor, input bool) graphql.Type {
+	var typ graphql.Type
+	isMessage := fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if input {
+			typ = t.input(fd.Message())
+		} else {
+			typ = t.object(fd.Message())
+		}
+	case protoreflect.BoolKind:
+		typ = graphql.Boolean
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind:
+		typ = graphql.String
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		typ = graphql.Float
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		typ = graphql.Int
+	default:
+		typ = graphQLInt64
+	}
+	switch {
+	case fd.IsList() && input:
+		return graphql.NewList(graphql.NewNonNull(typ))
+	case fd.IsList():
+		return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(typ)))
+	case !input && !isMessage:
+		return graphql.NewNonNull(typ)
+	}
+	return typ
+}
+
+// resolveMessageField resolves fd on the protoreflect.Message of the parent
+// object.
+func resolveMessageField(fd protoreflect.FieldDescriptor) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		m, ok := p.Source.(protoreflect.Message)
+		if !ok {
+			return nil, fmt.Errorf("field %s resolved on %T", fd.JSONName(), p.Source)
+		}
+		v := m.Get(fd)
+		if !fd.IsList() {
+			if fd.Message() != nil && !m.Has(fd) {
+				return nil, nil
+			}
+			return graphQLValue(fd, v), nil
+		}
+		list := make([]any, v.List().Len())
+		for i := range list {
+			list[i] = graphQLValue(fd, v.List().Get(i))
+		}
+		return list, nil
+	}
+}
+
+// graphQLValue returns v as its GraphQL type serializes it.
+func graphQLValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return v.Message()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.BytesKind:
+		// Bytes are base64 like in proto JSON
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	default:
+		// Integers, including int64, are JSON numbers like in the consumer
+		// JSON format
+		return v.Interface()
+	}
+}
+
+// graphQLStatusError is an error of the CheckoutUseCase port, reported with
+// the gRPC status code name in extensions.code and validation failures in
+// extensions.violations.
+type graphQLStatusError struct {
+	st *status.Status
+}
+
+func (e graphQLStatusError) Error() string {
+	return e.st.Message()
+}
+
+func (e graphQLStatusError) Extensions() map[string]any {
+	extensions := map[string]any{"code": codeName(e.st.Code())}
+	if violations := fieldViolations(e.st); len(violations) > 0 {
+		extensions["violations"] = violations
+	}
+	return extensions
+}
+
+// portError converts an error of the CheckoutUseCase port for the response.
+func portError(err error) error {
+	return graphQLStatusError{st: status.Convert(err)}
+}
+
+func resolveOrder(p graphql.ResolveParams) (any, error) {
+	svc := p.Info.RootValue.(ports.CheckoutUseCase)
+	req := &pb.GetOrderRequest{}
+	req.UserId, _ = p.Args["userId"].(string)
+	req.OrderId, _ = p.Args["orderId"].(string)
+	resp, err := svc.GetOrder(p.Context, req)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, portError(err)
+	}
+	return resp.GetOrder().ProtoReflect(), nil
+}
+
+func resolveOrders(p graphql.ResolveParams) (any, error) {
+	svc := p.Info.RootValue.(ports.CheckoutUseCase)
+	req := &pb.ListOrdersRequest{}
+	req.UserId, _ = p.Args["userId"].(string)
+	if size, ok := p.Args["pageSize"].(int); ok {
+		req.PageSize = int32(size)
+	}
+	req.PageToken, _ = p.Args["pageToken"].(string)
+	resp, err := svc.ListOrders(p.Context, req)
+	if err != nil {
+		return nil, portError(err)
+	}
+	return resp.ProtoReflect(), nil
+}
+
+func resolvePlaceOrder(p graphql.ResolveParams) (any, error) {
+	svc := p.Info.RootValue.(ports.CheckoutUseCase)
+	// Input fields have the JSON names of the request fields
+	input, err := json.Marshal(p.Args["input"])
+	if err != nil {
+		return nil, portError(status.Errorf(codes.InvalidArgument, "invalid input: %v", err))
+	}
+	req := &pb.PlaceOrderRequest{}
+	if err := protojson.Unmarshal(input, req); err != nil {
+		return nil, portError(status.Errorf(codes.InvalidArgument, "invalid input: %v", err))
+	}
+
+	md := metadata.MD{}
+	if key, _ := p.Args["idempotencyKey"].(string); key != "" {
+		md.Set("idempotency-key", key)
+	}
+	if async, _ := p.Args["async"].(bool); async {
+		md.Set("place-order-mode", "async")
+	}
+	resp, err := svc.PlaceOrder(metadata.NewIncomingContext(p.Context, md), req)
+	if err != nil {
+		return nil, portError(err)
+	}
+	return resp.GetOrder().ProtoReflect(), nil
+}
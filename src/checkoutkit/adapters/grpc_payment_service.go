@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// GRPCPaymentService implements the PaymentService port with the card
+// payments of the demo payment service. It charges cards only.
+type GRPCPaymentService struct {
+	client pb.PaymentServiceClient
+}
+
+// Compile-time check that GRPCPaymentService implements PaymentService
+var _ ports.PaymentService = (*GRPCPaymentService)(nil)
+
+// NewGRPCPaymentService creates a payment service charging cards through
+// client.
+func NewGRPCPaymentService(client pb.PaymentServiceClient) *GRPCPaymentService {
+	return &GRPCPaymentService{client: client}
+}
+
+// Charge charges amount to the card of tender.
+func (s *GRPCPaymentService) Charge(ctx context.Context, amount *pb.Money, tender ports.Tender) (ports.Payment, error) {
+	if tender.Type != ports.CardTender {
+		return ports.Payment{}, fmt.Errorf("the payment service does not accept %s tenders", tender.Type)
+	}
+	resp, err := s.client.Charge(ctx, &pb.ChargeRequest{Amount: amount, CreditCard: tender.Card})
+	if err != nil {
+		return ports.Payment{}, fmt.Errorf("could not charge the card: %+v", err)
+	}
+	return ports.Payment{Type: ports.CardTender, Amount: amount, TransactionID: resp.GetTransactionId()}, nil
+}
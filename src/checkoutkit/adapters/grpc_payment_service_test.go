@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// recordingPaymentClient records the charge requests it accepts.
+type recordingPaymentClient struct {
+	pb.PaymentServiceClient
+	charges []*pb.ChargeRequest
+}
+
+func (c *recordingPaymentClient) Charge(_ context.Context, req *pb.ChargeRequest, _ ...grpc.CallOption) (*pb.ChargeResponse, error) {
+	c.charges = append(c.charges, req)
+	return &pb.ChargeResponse{TransactionId: "tx-1"}, nil
+}
+
+func TestGRPCPaymentService(t *testing.T) {
+	client := &recordingPaymentClient{}
+	s := NewGRPCPaymentService(client)
+	amount := &pb.Money{CurrencyCode: "USD", Units: 11}
+	card := &pb.CreditCardInfo{CreditCardNumber: "4432-8015-6152-0454"}
+
+	payment, err := s.Charge(context.Background(), amount, ports.Tender{Type: ports.CardTender, Card: card})
+	if err != nil {
+		t.Fatalf("Charge() = %v", err)
+	}
+	if payment.Type != ports.CardTender || payment.TransactionID != "tx-1" || !proto.Equal(payment.Amount, amount) {
+		t.Errorf("Charge() = %+v, want the whole amount charged to the card as tx-1", payment)
+	}
+	if len(client.charges) != 1 || !proto.Equal(client.charges[0].CreditCard, card) {
+		t.Errorf("charged %v, want the card once", client.charges)
+	}
+
+	if _, err := s.Charge(context.Background(), amount, ports.Tender{Type: ports.GiftCardTender, GiftCardCode: "GIFT-1"}); err == nil {
+		t.Error("Charge() of a gift card succeeded, want an error")
+	}
+}
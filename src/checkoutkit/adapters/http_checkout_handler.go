@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// maxOrderRequestBytes bounds the size of a POST /orders body.
+const maxOrderRequestBytes = 1 << 20
+
+// forwardedHeaders are the HTTP headers passed to the checkout service as
+// gRPC metadata, so that idempotency keys, the async mode, the shipping method
+// and insurance, and gift cards work the same over HTTP.
+var forwardedHeaders = []string{"Idempotency-Key", "Place-Order-Mode", "Shipping-Method", "Shipping-Insurance", "Gift-Card"}
+
+// HTTPCheckoutHandler is the HTTP facade of the checkout service, for web
+// clients that cannot use gRPC. It serves the operations described in
+// schemas/openapi.json on the CheckoutUseCase port:
+//
+//	POST /orders            PlaceOrder
+//	GET  /orders/{orderId}  GetOrder (?userId= identifies the user)
+//
+// Requests are PlaceOrderRequest in proto JSON form. Orders are returned in
+// the consumer JSON format of the order event, and errors as {code, message}
+// with the HTTP status matching the gRPC status code.
+type HTTPCheckoutHandler struct {
+	svc    ports.CheckoutUseCase
+	logger *slog.Logger
+	mux    *http.ServeMux
+}
+
+// Compile-time check that HTTPCheckoutHandler implements http.Handler
+var _ http.Handler = (*HTTPCheckoutHandler)(nil)
+
+// NewHTTPCheckoutHandler creates the HTTP facade of svc.
+func NewHTTPCheckoutHandler(svc ports.CheckoutUseCase, logger *slog.Logger) *HTTPCheckoutHandler {
+	h := &HTTPCheckoutHandler{svc: svc, logger: logger, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /orders", h.placeOrder)
+	h.mux.HandleFunc("GET /orders/{orderId}", h.getOrder)
+	return h
+}
+
+// ServeHTTP routes the request to its operation.
+func (h *HTTPCheckoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *HTTPCheckoutHandler) placeOrder(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxOrderRequestBytes))
+	if err != nil {
+		h.writeError(w, r, status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err))
+		return
+	}
+	req := &pb.PlaceOrderRequest{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, req); err != nil {
+		h.writeError(w, r, status.Errorf(codes.InvalidArgument, "invalid PlaceOrderRequest: %v", err))
+		return
+	}
+
+	md := metadata.MD{}
+	for _, name := range forwardedHeaders {
+		if v := r.Header.Get(name); v != "" {
+			md.Set(strings.ToLower(name), v)
+		}
+	}
+	resp, err := h.svc.PlaceOrder(metadata.NewIncomingContext(r.Context(), md), req)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/orders/"+resp.GetOrder().GetOrderId())
+	h.writeOrder(w, r, http.StatusCreated, resp.GetOrder())
+}
+
+func (h *HTTPCheckoutHandler) getOrder(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.GetOrder(r.Context(), &pb.GetOrderRequest{
+		UserId:  r.URL.Query().Get("userId"),
+		OrderId: r.PathValue("orderId"),
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.writeOrder(w, r, http.StatusOK, resp.GetOrder())
+}
+
+func (h *HTTPCheckoutHandler) writeOrder(w http.ResponseWriter, r *http.Request, code int, order *pb.OrderResult) {
+	body, err := serialization.ToConsumerJSON(order)
+	if err != nil {
+		h.writeError(w, r, status.Errorf(codes.Internal, "failed to serialize order: %v", err))
+		return
+	}
+	h.writeJSON(w, r, code, body)
+}
+
+type httpError struct {
+	Code       string           `json:"code"`
+	Message    string           `json:"message"`
+	Violations []fieldViolation `json:"violations,omitempty"`
+}
+
+// fieldViolation is a validation.Violation in error responses.
+type fieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// fieldViolations returns the validation violations carried by st.
+func fieldViolations(st *status.Status) []fieldViolation {
+	var out []fieldViolation
+	for _, v := range validation.ViolationsOf(st) {
+		out = append(out, fieldViolation{Field: v.Field, Rule: v.Rule, Message: v.Message})
+	}
+	return out
+}
+
+// writeError writes err as an Error body with the HTTP status of its gRPC
+// status code. Validation failures list their violations.
+func (h *HTTPCheckoutHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+	h.writeJSON(w, r, httpStatus(st.Code()), httpError{Code: codeName(st.Code()), Message: st.Message(), Violations: fieldViolations(st)})
+}
+
+func (h *HTTPCheckoutHandler) writeJSON(w http.ResponseWriter, r *http.Request, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.WarnContext(r.Context(), "failed to write response", slog.String("error", err.Error()))
+	}
+}
+
+// codeName returns the canonical name of code, e.g. "NOT_FOUND".
+func codeName(code codes.Code) string {
+	// codes.Code.String returns the Go constant name, e.g. "NotFound"
+	var b strings.Builder
+	prevLower := false
+	for _, r := range code.String() {
+		isUpper := r >= 'A' && r <= 'Z'
+		if isUpper && prevLower {
+			b.WriteByte('_')
+		}
+		prevLower = !isUpper
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// httpStatus maps a gRPC status code to an HTTP status, following the mapping
+// of google.rpc.Code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
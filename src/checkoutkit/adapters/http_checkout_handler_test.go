@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// fakeCheckoutServer records the PlaceOrder request and its metadata and
+// knows a single order of user-1.
+type fakeCheckoutServer struct {
+	pb.UnimplementedCheckoutServiceServer
+	req *pb.PlaceOrderRequest
+	md  metadata.MD
+}
+
+func (f *fakeCheckoutServer) PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error) {
+	f.req = req
+	f.md, _ = metadata.FromIncomingContext(ctx)
+	if req.UserId == "" {
+		return nil, &validation.Error{Violations: []validation.Violation{{Field: "user_id", Rule: validation.RuleRequired, Message: "must be set"}}}
+	}
+	return &pb.PlaceOrderResponse{Order: testOrder()}, nil
+}
+
+func (f *fakeCheckoutServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
+	if req.UserId != "user-1" || req.OrderId != testOrder().OrderId {
+		return nil, status.Errorf(codes.NotFound, "order %s not found", req.OrderId)
+	}
+	return &pb.GetOrderResponse{Order: testOrder()}, nil
+}
+
+func (f *fakeCheckoutServer) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	if req.UserId != "user-1" || req.PageToken != "" {
+		return &pb.ListOrdersResponse{}, nil
+	}
+	return &pb.ListOrdersResponse{Orders: []*pb.OrderResult{testOrder()}, NextPageToken: "next"}, nil
+}
+
+func TestHTTPCheckoutHandler(t *testing.T) {
+	svc := &fakeCheckoutServer{}
+	srv := httptest.NewServer(NewHTTPCheckoutHandler(svc, discardLogger()))
+	defer srv.Close()
+	orderPath := "/orders/" + testOrder().OrderId
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "place order",
+			method:     http.MethodPost,
+			path:       "/orders",
+			body:       `{"userId": "user-1", "userCurrency": "USD", "address": {"city": "Anytown"}, "unknownField": true}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `"orderId":"` + testOrder().OrderId + `"`,
+		},
+		{
+			name:       "invalid order",
+			method:     http.MethodPost,
+			path:       "/orders",
+			body:       `{"userCurrency": "USD"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"INVALID_ARGUMENT","message":"invalid order result: user_id: must be set","violations":[{"field":"user_id","rule":"required","message":"must be set"}]}`,
+		},
+		{
+			name:       "malformed body",
+			method:     http.MethodPost,
+			path:       "/orders",
+			body:       `{"userId": 1`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"INVALID_ARGUMENT"`,
+		},
+		{
+			name:       "get order",
+			method:     http.MethodGet,
+			path:       orderPath + "?userId=user-1",
+			wantStatus: http.StatusOK,
+			wantBody:   `"shippingTrackingId":"trk-1"`,
+		},
+		{
+			name:       "get another user's order",
+			method:     http.MethodGet,
+			path:       orderPath + "?userId=user-2",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"NOT_FOUND"`,
+		},
+		{
+			name:       "unsupported method",
+			method:     http.MethodDelete,
+			path:       orderPath,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var body json.RawMessage
+			json.NewDecoder(resp.Body).Decode(&body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("%s %s = %d, want %d (%s)", tt.method, tt.path, resp.StatusCode, tt.wantStatus, body)
+			}
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("%s %s body = %s, want it to contain %s", tt.method, tt.path, body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHTTPCheckoutHandlerPlaceOrder(t *testing.T) {
+	svc := &fakeCheckoutServer{}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"userId": "user-1", "address": {"city": "Anytown"}}`))
+	req.Header.Set("Idempotency-Key", "req-1")
+	req.Header.Set("Place-Order-Mode", "async")
+	req.Header.Set("Shipping-Method", "express")
+	req.Header.Set("Shipping-Insurance", "true")
+	req.Header.Set("Gift-Card", "GIFT-1")
+	NewHTTPCheckoutHandler(svc, discardLogger()).ServeHTTP(rec, req)
+
+	if got, want := rec.Header().Get("Location"), "/orders/"+testOrder().OrderId; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+	if svc.req.GetAddress().GetCity() != "Anytown" {
+		t.Errorf("PlaceOrder() received %v, want the decoded request", svc.req)
+	}
+	for key, want := range map[string]string{"idempotency-key": "req-1", "place-order-mode": "async", "shipping-method": "express", "shipping-insurance": "true", "gift-card": "GIFT-1"} {
+		if got := svc.md.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("PlaceOrder() metadata %s = %v, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCodeName(t *testing.T) {
+	tests := []struct {
+		code       codes.Code
+		wantName   string
+		wantStatus int
+	}{
+		{codes.InvalidArgument, "INVALID_ARGUMENT", http.StatusBadRequest},
+		{codes.NotFound, "NOT_FOUND", http.StatusNotFound},
+		{codes.AlreadyExists, "ALREADY_EXISTS", http.StatusConflict},
+		{codes.ResourceExhausted, "RESOURCE_EXHAUSTED", http.StatusTooManyRequests},
+		{codes.Unavailable, "UNAVAILABLE", http.StatusServiceUnavailable},
+		{codes.Internal, "INTERNAL", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := codeName(tt.code); got != tt.wantName {
+			t.Errorf("codeName(%v) = %q, want %q", tt.code, got, tt.wantName)
+		}
+		if got := httpStatus(tt.code); got != tt.wantStatus {
+			t.Errorf("httpStatus(%v) = %d, want %d", tt.code, got, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// HTTPEmailService sends emails with the demo email service, which serves
+// POST /send_order_confirmation.
+type HTTPEmailService struct {
+	addr   string
+	client *http.Client
+}
+
+// Compile-time check that HTTPEmailService implements EmailService
+var _ ports.EmailService = (*HTTPEmailService)(nil)
+
+// NewHTTPEmailService creates an email service client for addr, for example
+// http://email:6060. A nil client uses one instrumented with otelhttp.
+func NewHTTPEmailService(addr string, client *http.Client) *HTTPEmailService {
+	if client == nil {
+		client = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	}
+	return &HTTPEmailService{addr: addr, client: client}
+}
+
+// SendOrderConfirmation POSTs the order with its rendered confirmation. The
+// order is still sent, so that an email service rendering its own template
+// keeps working.
+func (s *HTTPEmailService) SendOrderConfirmation(ctx context.Context, email string, order *pb.OrderResult, confirmation ports.OrderConfirmation) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"email":   email,
+		"order":   order,
+		"subject": confirmation.Subject,
+		"text":    confirmation.Text,
+		"html":    confirmation.HTML,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal order to JSON: %+v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.addr+"/send_order_confirmation", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create email request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed POST to email service: %+v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed POST to email service: expected 200, got %d", resp.StatusCode)
+	}
+	return nil
+}
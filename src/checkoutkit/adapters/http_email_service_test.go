@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestHTTPEmailService(t *testing.T) {
+	var got map[string]any
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/send_order_confirmation" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	svc := NewHTTPEmailService(srv.URL, nil)
+	confirmation := ports.OrderConfirmation{Subject: "Your order order-1 is confirmed", Text: "text", HTML: "<p>html</p>"}
+
+	if err := svc.SendOrderConfirmation(context.Background(), "jane@example.com", testOrder(), confirmation); err != nil {
+		t.Fatalf("SendOrderConfirmation() = %v", err)
+	}
+	for key, want := range map[string]string{"email": "jane@example.com", "subject": confirmation.Subject, "text": "text", "html": "<p>html</p>"} {
+		if got[key] != want {
+			t.Errorf("payload %s = %v, want %q", key, got[key], want)
+		}
+	}
+	if order, _ := got["order"].(map[string]any); order["order_id"] != "order-1" {
+		t.Errorf("payload order = %v, want order-1", got["order"])
+	}
+
+	status = http.StatusInternalServerError
+	if err := svc.SendOrderConfirmation(context.Background(), "jane@example.com", testOrder(), confirmation); err == nil {
+		t.Error("SendOrderConfirmation() on a 500 = nil, want an error")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// HTTPShippingProvider ships orders with the demo shipping service, which
+// serves POST /get-quote and POST /ship-order. It is the standard shipping
+// method.
+type HTTPShippingProvider struct {
+	addr string
+}
+
+// Compile-time check that HTTPShippingProvider implements ShippingProvider
+var _ ports.ShippingProvider = (*HTTPShippingProvider)(nil)
+
+// NewHTTPShippingProvider creates a provider for the shipping service at addr,
+// for example http://shipping:50051.
+func NewHTTPShippingProvider(addr string) *HTTPShippingProvider {
+	return &HTTPShippingProvider{addr: addr}
+}
+
+// Quote asks the shipping service for the cost of shipping items to address,
+// in USD.
+func (p *HTTPShippingProvider) Quote(ctx context.Context, address *pb.Address, items []*pb.CartItem) (*pb.Money, error) {
+	var quoteResp struct {
+		CostUsd *pb.Money `json:"cost_usd"`
+	}
+	if err := p.post(ctx, "/get-quote", address, items, &quoteResp); err != nil {
+		return nil, err
+	}
+	if quoteResp.CostUsd == nil {
+		return nil, fmt.Errorf("shipping quote missing cost_usd field")
+	}
+	return quoteResp.CostUsd, nil
+}
+
+// Ship asks the shipping service to ship items to address.
+func (p *HTTPShippingProvider) Ship(ctx context.Context, address *pb.Address, items []*pb.CartItem) (string, error) {
+	var shipResp struct {
+		TrackingID string `json:"tracking_id"`
+	}
+	if err := p.post(ctx, "/ship-order", address, items, &shipResp); err != nil {
+		return "", err
+	}
+	if shipResp.TrackingID == "" {
+		return "", fmt.Errorf("ship order response missing tracking_id field")
+	}
+	return shipResp.TrackingID, nil
+}
+
+func (p *HTTPShippingProvider) post(ctx context.Context, path string, address *pb.Address, items []*pb.CartItem, out any) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"address": address,
+		"items":   items,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %+v", path, err)
+	}
+
+	resp, err := otelhttp.Post(ctx, p.addr+path, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed POST to shipping service: %+v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed POST to shipping service: expected 200, got %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %+v", path, err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %+v", path, err)
+	}
+	return nil
+}
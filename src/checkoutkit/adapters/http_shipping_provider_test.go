@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// newFakeShippingService serves the shipping service endpoints. It fails
+// every request when down is set.
+func newFakeShippingService(t *testing.T, down bool) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	handle := func(path string, resp any) {
+		mux.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			if down {
+				http.Error(w, "shipping unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			var req struct {
+				Address *pb.Address    `json:"address"`
+				Items   []*pb.CartItem `json:"items"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Address.GetCity() == "" || len(req.Items) == 0 {
+				http.Error(w, "address and items are required", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+	handle("/get-quote", map[string]any{"cost_usd": &pb.Money{CurrencyCode: "USD", Units: 8, Nanos: 990000000}})
+	handle("/ship-order", map[string]string{"tracking_id": "TRACK-1"})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestHTTPShippingProviderContract(t *testing.T) {
+	testShippingProviderContract(t, func(t *testing.T, down bool) ports.ShippingProvider {
+		return NewHTTPShippingProvider(newFakeShippingService(t, down))
+	})
+}
+
+func TestHTTPShippingProviderRejectsIncompleteResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	provider := NewHTTPShippingProvider(srv.URL)
+
+	if quote, err := provider.Quote(t.Context(), testShippingAddress(), testCartItems()); err == nil {
+		t.Errorf("Quote() = %v, want an error for a missing cost_usd", quote)
+	}
+	if trackingID, err := provider.Ship(t.Context(), testShippingAddress(), testCartItems()); err == nil {
+		t.Errorf("Ship() = %q, want an error for a missing tracking_id", trackingID)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+// Headers a dead-lettered message carries on top of its own, so that it can
+// be triaged and replayed without the subscriber's logs.
+const (
+	// DeadLetterErrorCodeHeader is the errcode.Code of the failure
+	DeadLetterErrorCodeHeader = "dlq.error.code"
+	// DeadLetterErrorHeader is the message of the failure
+	DeadLetterErrorHeader = "dlq.error.message"
+	// DeadLetterTopicHeader, DeadLetterPartitionHeader and
+	// DeadLetterOffsetHeader locate the original message
+	DeadLetterTopicHeader     = "dlq.original.topic"
+	DeadLetterPartitionHeader = "dlq.original.partition"
+	DeadLetterOffsetHeader    = "dlq.original.offset"
+)
+
+// DeadLetterQueue receives the messages a subscriber could not process, with
+// the reason.
+type DeadLetterQueue interface {
+	DeadLetter(ctx context.Context, msg *sarama.ConsumerMessage, err error) error
+}
+
+// KafkaDeadLetterQueue implements DeadLetterQueue with a Kafka topic. The
+// message is copied unchanged, key, value and headers, and the failure is
+// added as headers.
+type KafkaDeadLetterQueue struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// Compile-time check that KafkaDeadLetterQueue implements DeadLetterQueue
+var _ DeadLetterQueue = (*KafkaDeadLetterQueue)(nil)
+
+// NewKafkaDeadLetterQueue creates a dead-letter queue writing to topic, such
+// as kafka.DeadLetterTopic, through producer.
+func NewKafkaDeadLetterQueue(producer sarama.SyncProducer, topic string) *KafkaDeadLetterQueue {
+	return &KafkaDeadLetterQueue{producer: producer, topic: topic}
+}
+
+// DeadLetter writes msg to the dead-letter topic and waits for the broker to
+// acknowledge it.
+func (q *KafkaDeadLetterQueue) DeadLetter(_ context.Context, msg *sarama.ConsumerMessage, err error) error {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+5)
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers = append(headers, *h)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(DeadLetterErrorCodeHeader), Value: []byte(errcode.Of(err))},
+		sarama.RecordHeader{Key: []byte(DeadLetterErrorHeader), Value: []byte(err.Error())},
+		sarama.RecordHeader{Key: []byte(DeadLetterTopicHeader), Value: []byte(msg.Topic)},
+		sarama.RecordHeader{Key: []byte(DeadLetterPartitionHeader), Value: []byte(strconv.Itoa(int(msg.Partition)))},
+		sarama.RecordHeader{Key: []byte(DeadLetterOffsetHeader), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+	dead := &sarama.ProducerMessage{
+		Topic:   q.topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	}
+	if msg.Key != nil {
+		dead.Key = sarama.ByteEncoder(msg.Key)
+	}
+	if _, _, err := q.producer.SendMessage(dead); err != nil {
+		return errcode.Errorf(errcode.KafkaProduceFailed, "failed to dead-letter message %s/%d@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+)
+
+func TestKafkaDeadLetterQueue(t *testing.T) {
+	var dead *sarama.ProducerMessage
+	producer := kafkatest.NewSyncProducer(t)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		dead = msg
+		return nil
+	})
+	msg := &sarama.ConsumerMessage{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    41,
+		Key:       []byte("order-1"),
+		Value:     []byte("not protobuf"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("00-abc")}},
+	}
+	cause := errcode.Wrap(errcode.DecodeFailed, errors.New("bad wire type"))
+	if err := NewKafkaDeadLetterQueue(producer, kafka.DeadLetterTopic).DeadLetter(context.Background(), msg, cause); err != nil {
+		t.Fatalf("DeadLetter() = %v", err)
+	}
+
+	if dead.Topic != kafka.DeadLetterTopic {
+		t.Errorf("topic = %q, want %q", dead.Topic, kafka.DeadLetterTopic)
+	}
+	if key, _ := dead.Key.Encode(); string(key) != "order-1" {
+		t.Errorf("key = %q, want the original key", key)
+	}
+	if value, _ := dead.Value.Encode(); string(value) != "not protobuf" {
+		t.Errorf("value = %q, want the original value", value)
+	}
+	want := map[string]string{
+		"traceparent":             "00-abc",
+		DeadLetterErrorCodeHeader: string(errcode.DecodeFailed),
+		DeadLetterErrorHeader:     cause.Error(),
+		DeadLetterTopicHeader:     "orders",
+		DeadLetterPartitionHeader: "2",
+		DeadLetterOffsetHeader:    "41",
+	}
+	got := kafkatest.Headers(dead)
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("header %s = %q, want %q", key, got[key], value)
+		}
+	}
+}
+
+func TestKafkaDeadLetterQueueProduceFailure(t *testing.T) {
+	producer := kafkatest.NewSyncProducer(t)
+	producer.ExpectSendMessageAndFail(sarama.ErrNotLeaderForPartition)
+
+	err := NewKafkaDeadLetterQueue(producer, kafka.DeadLetterTopic).DeadLetter(context.Background(), &sarama.ConsumerMessage{Topic: "orders"}, errors.New("boom"))
+	if errcode.Of(err) != errcode.KafkaProduceFailed || !errors.Is(err, sarama.ErrNotLeaderForPartition) {
+		t.Errorf("DeadLetter() = %v, want a %s error wrapping the broker error", err, errcode.KafkaProduceFailed)
+	}
+}
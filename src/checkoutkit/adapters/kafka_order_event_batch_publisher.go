@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// EventTypeHeader is the Kafka header naming the ports.OrderEventType of a
+// message.
+const EventTypeHeader = "event.type"
+
+// KafkaOrderEventBatchPublisher implements the OrderEventBatchPublisher port
+// with Kafka transactions. The OrderResult of a batch goes to kafka.Topic like
+// the ones of KafkaOrderEventPublisher, the other events to kafka.EventsTopic,
+// unless WithBatchTopics names others, prefixed with the region of
+// WithBatchRegion.
+// Every message is keyed by order ID and carries its EventTypeHeader, its
+// attributes as headers and the trace context. Consumers reading with
+// isolation level read_committed see either every event of an order or none.
+type KafkaOrderEventBatchPublisher struct {
+	producer    sarama.SyncProducer
+	logger      *slog.Logger
+	tracer      trace.Tracer
+	topic       string
+	eventsTopic string
+	region      string
+
+	// mu serializes transactions, since a producer has at most one open
+	mu     sync.Mutex
+	closed bool
+}
+
+// Compile-time check that KafkaOrderEventBatchPublisher implements OrderEventBatchPublisher
+var _ ports.OrderEventBatchPublisher = (*KafkaOrderEventBatchPublisher)(nil)
+
+// Compile-time check that KafkaOrderEventBatchPublisher implements Lifecycle
+var _ ports.Lifecycle = (*KafkaOrderEventBatchPublisher)(nil)
+
+// KafkaBatchPublisherOption configures optional behavior of a
+// KafkaOrderEventBatchPublisher.
+type KafkaBatchPublisherOption func(*KafkaOrderEventBatchPublisher)
+
+// WithBatchTopics publishes the OrderResult of a batch to topic instead of
+// kafka.Topic, and the other events to eventsTopic instead of
+// kafka.EventsTopic. An empty topic keeps the default.
+func WithBatchTopics(topic, eventsTopic string) KafkaBatchPublisherOption {
+	return func(k *KafkaOrderEventBatchPublisher) {
+		if topic != "" {
+			k.topic = topic
+		}
+		if eventsTopic != "" {
+			k.eventsTopic = eventsTopic
+		}
+	}
+}
+
+// WithBatchRegion publishes to the topics of region, such as
+// us-east-1.orders, like a KafkaOrderEventPublisher created WithRegion, and
+// adds region to the publisher's spans as cloud.region.
+func WithBatchRegion(region string) KafkaBatchPublisherOption {
+	return func(k *KafkaOrderEventBatchPublisher) {
+		k.region = region
+	}
+}
+
+// NewKafkaOrderEventBatchPublisher creates a batch publisher on a producer
+// created by kafka.CreateTransactionalProducer.
+func NewKafkaOrderEventBatchPublisher(producer sarama.SyncProducer, logger *slog.Logger, opts ...KafkaBatchPublisherOption) *KafkaOrderEventBatchPublisher {
+	k := &KafkaOrderEventBatchPublisher{
+		producer:    producer,
+		logger:      logger,
+		tracer:      otel.Tracer("checkout-kafka-adapter"),
+		topic:       kafka.Topic,
+		eventsTopic: kafka.EventsTopic,
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	k.topic = kafka.RegionalTopic(k.region, k.topic)
+	k.eventsTopic = kafka.RegionalTopic(k.region, k.eventsTopic)
+	return k
+}
+
+// PublishOrderEvents publishes events in one transaction, which is aborted if
+// any of them is not acknowledged.
+func (k *KafkaOrderEventBatchPublisher) PublishOrderEvents(ctx context.Context, events []ports.OrderEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	orderID := events[0].Order.GetOrderId()
+	ctx, span := k.tracer.Start(ctx, "order events publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.PeerService("kafka"),
+			semconv.MessagingSystemKafka,
+			semconv.MessagingBatchMessageCount(len(events)),
+			attribute.String("app.order.id", orderID),
+		),
+	)
+	defer span.End()
+	if k.region != "" {
+		span.SetAttributes(semconv.CloudRegion(k.region))
+	}
+
+	msgs := make([]*sarama.ProducerMessage, 0, len(events))
+	for _, event := range events {
+		msg, err := k.message(ctx, event)
+		if err != nil {
+			errcode.RecordSpan(span, err, "order event could not be serialized")
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return errcode.Errorf(errcode.PublisherClosed, "kafka batch publisher is closed")
+	}
+	if err := k.producer.BeginTxn(); err != nil {
+		err = errcode.Errorf(errcode.KafkaProduceFailed, "failed to begin kafka transaction: %w", err)
+		errcode.RecordSpan(span, err, "transaction could not begin")
+		return err
+	}
+	err := k.producer.SendMessages(msgs)
+	if err == nil {
+		err = k.producer.CommitTxn()
+	}
+	if err != nil {
+		if abortErr := k.producer.AbortTxn(); abortErr != nil {
+			k.logger.WarnContext(ctx, "Failed to abort Kafka transaction", slog.String("error", abortErr.Error()))
+		}
+		err = errcode.Errorf(errcode.KafkaTransactionAborted, "kafka transaction aborted: %w", err)
+		errcode.RecordSpan(span, err, "transaction aborted")
+		return err
+	}
+
+	k.logger.InfoContext(ctx, "Successfully published order events",
+		slog.String("order_id", orderID),
+		slog.Int(string(semconv.MessagingBatchMessageCountKey), len(events)),
+	)
+	return nil
+}
+
+// Close waits for the transaction in progress and closes the producer.
+func (k *KafkaOrderEventBatchPublisher) Close(ctx context.Context) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return nil
+	}
+	k.closed = true
+	return k.producer.Close()
+}
+
+// message encodes event as a Kafka message to its topic, with the trace
+// context of ctx.
+func (k *KafkaOrderEventBatchPublisher) message(ctx context.Context, event ports.OrderEvent) (*sarama.ProducerMessage, error) {
+	value, err := proto.Marshal(event.Order)
+	if err != nil {
+		return nil, errcode.Errorf(errcode.SerializationFailed, "failed to marshal %s event to protobuf: %w", event.Type, err)
+	}
+	topic := k.eventsTopic
+	if event.Type == ports.OrderCompletedEvent {
+		topic = k.topic
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(event.Order.GetOrderId()),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(EventTypeHeader), Value: []byte(event.Type)},
+		},
+	}
+	for key, value := range MessageHeaders(ctx) {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	for key, value := range event.Attributes {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	for key, value := range PropagationHeaders(ctx) {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return msg, nil
+}
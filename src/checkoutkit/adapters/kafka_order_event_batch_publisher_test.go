@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/mock/gomock"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports/mocks"
+)
+
+func TestKafkaOrderEventBatchPublisher(t *testing.T) {
+	producer := kafkatest.NewTransactionalProducer(t, "checkout-test")
+	var msgs []*sarama.ProducerMessage
+	for range 3 {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			msgs = append(msgs, msg)
+			return nil
+		})
+	}
+	publisher := NewKafkaOrderEventBatchPublisher(producer, discardLogger())
+
+	if err := publisher.PublishOrderEvents(context.Background(), testOrderEvents("order-1")); err != nil {
+		t.Fatalf("PublishOrderEvents() = %v", err)
+	}
+	if producer.TxnStatus() != sarama.ProducerTxnFlagReady {
+		t.Errorf("TxnStatus() = %v, want the transaction committed", producer.TxnStatus())
+	}
+
+	want := []struct {
+		topic     string
+		eventType ports.OrderEventType
+	}{
+		{kafka.EventsTopic, ports.OrderPlacedEvent},
+		{kafka.EventsTopic, ports.PaymentCapturedEvent},
+		{kafka.Topic, ports.OrderCompletedEvent},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("sent %d messages, want %d", len(msgs), len(want))
+	}
+	for i, w := range want {
+		msg := msgs[i]
+		if msg.Topic != w.topic || kafkatest.Header(msg, EventTypeHeader) != string(w.eventType) {
+			t.Errorf("message %d = topic %s type %q, want topic %s type %q", i, msg.Topic, kafkatest.Header(msg, EventTypeHeader), w.topic, w.eventType)
+		}
+		if key, _ := msg.Key.Encode(); string(key) != "order-1" {
+			t.Errorf("message %d key = %q, want order-1", i, key)
+		}
+	}
+	if got := kafkatest.Header(msgs[1], "transaction_id"); got != "tx-1" {
+		t.Errorf("PaymentCaptured transaction_id header = %q, want tx-1", got)
+	}
+
+	if err := publisher.Close(context.Background()); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+	if err := publisher.PublishOrderEvents(context.Background(), testOrderEvents("order-2")); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderEvents() after Close = %v, want %s", err, errcode.PublisherClosed)
+	}
+}
+
+func TestKafkaOrderEventBatchPublisherTopics(t *testing.T) {
+	producer := kafkatest.NewTransactionalProducer(t, "checkout-test")
+	var topics []string
+	for range 3 {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			topics = append(topics, msg.Topic)
+			return nil
+		})
+	}
+	publisher := NewKafkaOrderEventBatchPublisher(producer, discardLogger(), WithBatchTopics("checkout.orders", "checkout.order-events"))
+	defer publisher.Close(context.Background())
+
+	if err := publisher.PublishOrderEvents(context.Background(), testOrderEvents("order-1")); err != nil {
+		t.Fatalf("PublishOrderEvents() = %v", err)
+	}
+	want := []string{"checkout.order-events", "checkout.order-events", "checkout.orders"}
+	if !slices.Equal(topics, want) {
+		t.Errorf("published to %v, want %v", topics, want)
+	}
+}
+
+func TestKafkaOrderEventBatchPublisherRegion(t *testing.T) {
+	producer := kafkatest.NewTransactionalProducer(t, "checkout-test")
+	var topics []string
+	for range 3 {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			topics = append(topics, msg.Topic)
+			return nil
+		})
+	}
+	publisher := NewKafkaOrderEventBatchPublisher(producer, discardLogger(), WithBatchRegion("us-east-1"))
+	defer publisher.Close(context.Background())
+
+	if err := publisher.PublishOrderEvents(context.Background(), testOrderEvents("order-1")); err != nil {
+		t.Fatalf("PublishOrderEvents() = %v", err)
+	}
+	want := []string{"us-east-1.order-events", "us-east-1.order-events", "us-east-1.orders"}
+	if !slices.Equal(topics, want) {
+		t.Errorf("published to %v, want %v", topics, want)
+	}
+}
+
+func TestKafkaOrderEventBatchPublisherAbortsFailedBatches(t *testing.T) {
+	producer := kafkatest.NewTransactionalProducer(t, "checkout-test")
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndFail(errors.New("not enough replicas"))
+	producer.ExpectSendMessageAndSucceed()
+	publisher := NewKafkaOrderEventBatchPublisher(producer, discardLogger())
+	defer publisher.Close(context.Background())
+
+	err := publisher.PublishOrderEvents(context.Background(), testOrderEvents("order-1"))
+	if errcode.Of(err) != errcode.KafkaTransactionAborted {
+		t.Errorf("PublishOrderEvents() = %v, want %s", err, errcode.KafkaTransactionAborted)
+	}
+	if producer.TxnStatus() != sarama.ProducerTxnFlagReady {
+		t.Errorf("TxnStatus() = %v, want the transaction aborted", producer.TxnStatus())
+	}
+}
+
+func TestOrderCompletedBatchPublisher(t *testing.T) {
+	// Only the OrderResult, the event with a tracking ID, is published
+	next := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+	next.EXPECT().PublishOrderCompleted(gomock.Any(), gomock.Cond(func(order *pb.OrderResult) bool {
+		return order.GetShippingTrackingId() != ""
+	}))
+	publisher := NewOrderCompletedBatchPublisher(next)
+
+	if err := publisher.PublishOrderEvents(context.Background(), testOrderEvents("order-1")); err != nil {
+		t.Fatalf("PublishOrderEvents() = %v", err)
+	}
+}
@@ -0,0 +1,824 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/IBM/sarama"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// KafkaOrderEventPublisher implements the OrderEventPublisher port using Apache Kafka.
+// This adapter handles all Kafka-specific concerns including:
+// - Message serialization and routing
+// - Producer error handling and retries
+// - Distributed tracing context propagation
+// - Performance monitoring and metrics
+// - It implements the OrderEventPublisher port
+type KafkaOrderEventPublisher struct {
+	producer sarama.AsyncProducer
+	// syncProducer replaces producer for a publisher created with
+	// NewKafkaSyncOrderEventPublisher
+	syncProducer sarama.SyncProducer
+	// transactional sends each message of syncProducer in a transaction of
+	// its own, which txnMu serializes
+	transactional   bool
+	txnMu           sync.Mutex
+	logger          *slog.Logger
+	tracer          trace.Tracer
+	publishDuration metric.Float64Histogram
+	slowPublishes   metric.Int64Counter
+
+	brokers              []string
+	region               string
+	topic                string
+	messageKey           func(*pb.OrderResult) string
+	staticHeaders        map[string]string
+	publishTimeout       time.Duration
+	backgroundAck        bool
+	slowPublishThreshold time.Duration
+	alertNotifier        ports.AlertNotifier
+	semconvMode          SemconvMode
+	publishObserver      PublishObserver
+
+	inFlight     atomic.Int64
+	acknowledged atomic.Uint64
+	failed       atomic.Uint64
+	// pending holds the messages in flight, which Close logs if they are not
+	// acknowledged before its deadline
+	pendingMu sync.Mutex
+	pending   map[*pendingMessage]struct{}
+
+	// closeMu guards closed, so that no publish queues a message once Close
+	// has started closing the producer
+	closeMu   sync.RWMutex
+	closed    bool
+	publishes sync.WaitGroup
+	// dispatched is closed when the producer's acknowledgment channels are
+	// drained after AsyncClose, so Close knows every flushed message has its
+	// outcome recorded
+	dispatched chan struct{}
+}
+
+// PublisherStats is a point-in-time snapshot of a KafkaOrderEventPublisher or
+// PubSubOrderEventPublisher, exposed on the debug listener for troubleshooting
+// publish latency.
+type PublisherStats struct {
+	Topic  string `json:"topic"`
+	Region string `json:"region,omitempty"`
+	// InFlight is the number of messages queued but not yet acknowledged
+	InFlight     int64  `json:"in_flight"`
+	Acknowledged uint64 `json:"acknowledged"`
+	Failed       uint64 `json:"failed"`
+}
+
+// Compile-time check that KafkaOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*KafkaOrderEventPublisher)(nil)
+
+// Compile-time check that KafkaOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*KafkaOrderEventPublisher)(nil)
+
+// ProducerSuccessKey is set on ack spans when they start, which lets samplers
+// tell failed publishes apart before the span is recorded.
+const ProducerSuccessKey = attribute.Key("messaging.kafka.producer.success")
+
+// Lifecycle events recorded on the producer span, so that a single trace shows
+// what happened to a message without correlating logs.
+const (
+	PublishEventQueued = "message.queued"
+	PublishEventAcked  = "message.acked"
+	PublishEventFailed = "message.failed"
+	// Recorded by ProducerInterceptor when sarama dispatches the message and
+	// each time it retries sending it to the broker
+	PublishEventDispatched  = "message.dispatched"
+	PublishEventBrokerRetry = "message.broker_retry"
+)
+
+// producerAttemptsKey counts the times sarama dispatched a message, which is
+// one more than its broker-level retries.
+const producerAttemptsKey = attribute.Key("messaging.kafka.producer.attempts")
+
+// pendingMessage travels with a message through sarama's ProducerMessage.Metadata
+// so that each acknowledgment is matched to the publish call that queued it.
+type pendingMessage struct {
+	// ctx is the caller's context without its cancellation, used to parent
+	// the acknowledgment span and correlate logs after the caller returns
+	ctx     context.Context
+	orderID string
+	// publishSpan stays open until the acknowledgment arrives, so that it
+	// carries the message's whole lifecycle as events
+	publishSpan trace.Span
+	queuedAt    time.Time
+	result      chan error
+
+	// dispatches are the times ProducerInterceptor saw sarama dispatch the
+	// message. They are recorded on the producer span when it ends, so that
+	// its events stay in order.
+	mu         sync.Mutex
+	dispatches []time.Time
+}
+
+func (p *pendingMessage) dispatched() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dispatches
+}
+
+// KafkaPublisherOption configures optional behavior of a KafkaOrderEventPublisher.
+type KafkaPublisherOption func(*KafkaOrderEventPublisher)
+
+// WithBrokers records the broker addresses the producer connects to, so that
+// alerts and logs can name them.
+func WithBrokers(brokers ...string) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.brokers = brokers
+	}
+}
+
+// WithRegion publishes to the topic of region, such as us-east-1.orders, and
+// adds region to the publisher's spans and metrics as cloud.region.
+func WithRegion(region string) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.region = region
+	}
+}
+
+// WithTopic publishes to topic instead of kafka.Topic. With a region, the
+// topic is prefixed with it as well.
+func WithTopic(topic string) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.topic = topic
+	}
+}
+
+// WithMessageKey keys every message with key(order), so that the partitioner
+// sends the messages of a key to the same partition. Messages have no key by
+// default, and none when key returns "".
+func WithMessageKey(key func(*pb.OrderResult) string) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.messageKey = key
+	}
+}
+
+// OrderIDKey keys messages by order ID, for WithMessageKey, which keeps every
+// message of an order in order on one partition.
+func OrderIDKey(order *pb.OrderResult) string {
+	return order.GetOrderId()
+}
+
+// WithStaticHeaders adds headers to every message, for example to tell
+// consumers the environment it was published in.
+func WithStaticHeaders(headers map[string]string) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.staticHeaders = headers
+	}
+}
+
+// WithDetachedPublishTimeout waits up to timeout for the acknowledgment of a
+// queued message, even once the caller's context has ended, so that a
+// cancelled request does not abandon a message the producer already has. By
+// default the wait ends with the caller's context.
+func WithDetachedPublishTimeout(timeout time.Duration) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.publishTimeout = timeout
+	}
+}
+
+// WithBackgroundAck returns from a publish once its message is queued. The
+// acknowledgment is still awaited, logged and recorded on the ack span linked
+// to the producer span, but a failure no longer reaches the caller, or a
+// FallbackOrderEventPublisher around the publisher. It has no effect on a
+// publisher created with NewKafkaSyncOrderEventPublisher.
+func WithBackgroundAck() KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.backgroundAck = true
+	}
+}
+
+// WithSlowPublishThreshold flags every publish whose acknowledgment takes
+// longer than threshold with a warning log and the messaging.publish.slow
+// metric, to catch broker degradation early. A zero threshold disables it.
+func WithSlowPublishThreshold(threshold time.Duration) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.slowPublishThreshold = threshold
+	}
+}
+
+// WithAlertNotifier additionally reports slow publishes to notifier.
+func WithAlertNotifier(notifier ports.AlertNotifier) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.alertNotifier = notifier
+	}
+}
+
+// WithSemconvMode selects the messaging semantic conventions of the publisher's
+// spans and logs. The default is SemconvOld.
+func WithSemconvMode(mode SemconvMode) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.semconvMode = mode
+	}
+}
+
+// PublishObserver is notified of the outcome of every acknowledged publish,
+// with the same duration that is recorded on messaging.publish.duration.
+type PublishObserver interface {
+	ObservePublish(duration time.Duration, err error)
+}
+
+// WithPublishObserver reports every acknowledged publish to observer, for
+// example to evaluate a publish SLO.
+func WithPublishObserver(observer PublishObserver) KafkaPublisherOption {
+	return func(k *KafkaOrderEventPublisher) {
+		k.publishObserver = observer
+	}
+}
+
+// NewKafkaOrderEventPublisher creates a new Kafka-based order event publisher.
+// The publisher consumes the producer's Successes and Errors channels, so the
+// producer must be configured to return both.
+func NewKafkaOrderEventPublisher(producer sarama.AsyncProducer, logger *slog.Logger, opts ...KafkaPublisherOption) *KafkaOrderEventPublisher {
+	k := &KafkaOrderEventPublisher{
+		producer:   producer,
+		logger:     logger,
+		tracer:     otel.Tracer("checkout-kafka-adapter"),
+		topic:      kafka.Topic,
+		pending:    map[*pendingMessage]struct{}{},
+		dispatched: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	k.topic = kafka.RegionalTopic(k.region, k.topic)
+
+	// Recorded in the ack span's context so that exemplars link slow
+	// publishes to their trace
+	var err error
+	k.publishDuration, err = otel.Meter("checkout-kafka-adapter").Float64Histogram(
+		"messaging.publish.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time from queuing an order event to its broker acknowledgment."),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		logger.Warn("Failed to create publish duration histogram", slog.String("error", err.Error()))
+	}
+	k.slowPublishes, err = otel.Meter("checkout-kafka-adapter").Int64Counter(
+		"messaging.publish.slow",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Order events acknowledged later than the slow-publish threshold."),
+	)
+	if err != nil {
+		logger.Warn("Failed to create slow publish counter", slog.String("error", err.Error()))
+	}
+	if producer != nil {
+		go k.dispatchAcknowledgments()
+	}
+	return k
+}
+
+// NewKafkaSyncOrderEventPublisher creates a Kafka-based order event publisher
+// on a producer created by kafka.CreateSyncProducer. Each publish sends its
+// message and returns once the broker acknowledged it, even if ctx ends
+// first, so that an order is never answered while its event may still be
+// lost. The spans, metrics and options are those of the asynchronous
+// publisher.
+func NewKafkaSyncOrderEventPublisher(producer sarama.SyncProducer, logger *slog.Logger, opts ...KafkaPublisherOption) *KafkaOrderEventPublisher {
+	k := NewKafkaOrderEventPublisher(nil, logger, opts...)
+	k.syncProducer = producer
+	close(k.dispatched)
+	return k
+}
+
+// NewKafkaTransactionalOrderEventPublisher creates a synchronous Kafka-based
+// order event publisher on a producer created by
+// kafka.CreateTransactionalProducer, which sends each message in a
+// transaction of its own. The idempotent producer never writes a message
+// twice, and consumers reading with isolation level read_committed never see
+// the message of an aborted transaction, which fails the publish with
+// KAFKA_TRANSACTION_ABORTED. Transactions are serialized, since a producer has
+// at most one open.
+func NewKafkaTransactionalOrderEventPublisher(producer sarama.SyncProducer, logger *slog.Logger, opts ...KafkaPublisherOption) *KafkaOrderEventPublisher {
+	k := NewKafkaSyncOrderEventPublisher(producer, logger, opts...)
+	k.transactional = true
+	return k
+}
+
+// PublishOrderCompleted publishes an order completion event to Kafka.
+// This method implements the OrderEventPublisher interface.
+func (k *KafkaOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if k.producer == nil && k.syncProducer == nil {
+		k.logger.WarnContext(ctx, "Kafka producer not configured, skipping order event publication")
+		return nil
+	}
+	k.closeMu.RLock()
+	if k.closed {
+		k.closeMu.RUnlock()
+		return errcode.Errorf(errcode.PublisherClosed, "kafka publisher is closed")
+	}
+	k.publishes.Add(1)
+	k.closeMu.RUnlock()
+	defer k.publishes.Done()
+
+	// Serialize the order to protobuf
+	message, err := proto.Marshal(order)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to protobuf: %w", err)
+	}
+
+	// Create Kafka message
+	pending := &pendingMessage{
+		ctx:     context.WithoutCancel(ctx),
+		orderID: order.GetOrderId(),
+		result:  make(chan error, 1),
+	}
+	msg := &sarama.ProducerMessage{
+		Topic:    k.topic,
+		Value:    sarama.ByteEncoder(message),
+		Metadata: pending,
+	}
+	if k.messageKey != nil {
+		if key := k.messageKey(order); key != "" {
+			msg.Key = sarama.StringEncoder(key)
+		}
+	}
+	for key, value := range k.staticHeaders {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	for key, value := range MessageHeaders(ctx) {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	// Add tracing context to message
+	span := k.createProducerSpan(ctx, msg)
+	pending.publishSpan = span
+
+	// Send message asynchronously. The producer span is ended by the
+	// dispatcher goroutine once the acknowledgment arrives, which is also
+	// recorded on a linked span.
+	pending.queuedAt = time.Now()
+	k.track(pending)
+	if k.syncProducer != nil {
+		return k.sendSync(msg, pending)
+	}
+	select {
+	case k.producer.Input() <- msg:
+		span.AddEvent(PublishEventQueued, trace.WithTimestamp(pending.queuedAt))
+		if k.backgroundAck {
+			return nil
+		}
+		return k.waitForAcknowledgment(ctx, pending)
+	case <-ctx.Done():
+		k.untrack(pending)
+		err := errcode.Errorf(errcode.KafkaEnqueueTimeout, "failed to queue message: %w", ctx.Err())
+		errcode.RecordSpan(span, err, "Context cancelled before message could be queued")
+		span.End()
+		return err
+	}
+}
+
+// Close stops accepting order events and waits until every publish in
+// progress has its acknowledgment, or ctx is done. It then closes the
+// producer, which flushes the messages still buffered, and waits for their
+// acknowledgments to be recorded. The messages still unacknowledged when ctx
+// is done may be lost, and are logged with their order ID. Events published
+// after Close fail with PUBLISHER_CLOSED.
+func (k *KafkaOrderEventPublisher) Close(ctx context.Context) error {
+	if k.producer == nil && k.syncProducer == nil {
+		return nil
+	}
+	k.closeMu.Lock()
+	if k.closed {
+		k.closeMu.Unlock()
+		return nil
+	}
+	k.closed = true
+	k.closeMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		k.publishes.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		k.logger.WarnContext(ctx, "Closing the Kafka producer with publishes still waiting for acknowledgment",
+			slog.Int64("messaging.kafka.producer.in_flight", k.inFlight.Load()),
+		)
+	}
+
+	if k.syncProducer != nil {
+		if err := k.syncProducer.Close(); err != nil {
+			return errcode.Errorf(errcode.KafkaProduceFailed, "failed to close kafka producer: %w", err)
+		}
+		return nil
+	}
+	// AsyncClose leaves the acknowledgment channels to the dispatcher, which
+	// records the outcome of every flushed message
+	k.producer.AsyncClose()
+	select {
+	case <-k.dispatched:
+		return nil
+	case <-ctx.Done():
+		dropped := k.logDropped()
+		return errcode.Errorf(errcode.KafkaAckTimeout, "kafka producer did not flush %d order events before shutdown: %w", dropped, ctx.Err())
+	}
+}
+
+// track records pending as in flight until untrack.
+func (k *KafkaOrderEventPublisher) track(pending *pendingMessage) {
+	k.inFlight.Add(1)
+	k.pendingMu.Lock()
+	defer k.pendingMu.Unlock()
+	k.pending[pending] = struct{}{}
+}
+
+func (k *KafkaOrderEventPublisher) untrack(pending *pendingMessage) {
+	k.inFlight.Add(-1)
+	k.pendingMu.Lock()
+	defer k.pendingMu.Unlock()
+	delete(k.pending, pending)
+}
+
+// logDropped logs every message still in flight as possibly lost, so that
+// its order can be published again, and returns how many there are.
+func (k *KafkaOrderEventPublisher) logDropped() int {
+	k.pendingMu.Lock()
+	defer k.pendingMu.Unlock()
+	for pending := range k.pending {
+		k.logger.ErrorContext(pending.ctx, "Order event not acknowledged before shutdown, it may be lost",
+			slog.String("order_id", pending.orderID),
+			slog.String(string(semconv.MessagingDestinationNameKey), k.topic),
+			slog.Int64("messaging.kafka.producer.duration_ms", time.Since(pending.queuedAt).Milliseconds()),
+			errcode.Attr(errcode.Errorf(errcode.KafkaAckTimeout, "not acknowledged before shutdown")),
+		)
+	}
+	return len(k.pending)
+}
+
+// Stats returns a snapshot of the publisher's queue and acknowledgment counters.
+func (k *KafkaOrderEventPublisher) Stats() PublisherStats {
+	return PublisherStats{
+		Topic:        k.topic,
+		Region:       k.region,
+		InFlight:     k.inFlight.Load(),
+		Acknowledged: k.acknowledged.Load(),
+		Failed:       k.failed.Load(),
+	}
+}
+
+// sendSync sends msg on the sync producer and records its acknowledgment as
+// the dispatcher does for the asynchronous producer.
+func (k *KafkaOrderEventPublisher) sendSync(msg *sarama.ProducerMessage, pending *pendingMessage) error {
+	pending.publishSpan.AddEvent(PublishEventQueued, trace.WithTimestamp(pending.queuedAt))
+	if k.transactional {
+		return k.sendInTransaction(msg, pending)
+	}
+	_, _, err := k.syncProducer.SendMessage(msg)
+	k.acknowledge(msg, err)
+	<-pending.result
+	if err != nil {
+		return errcode.Errorf(errcode.KafkaProduceFailed, "kafka producer error: %w", err)
+	}
+	return nil
+}
+
+// sendInTransaction sends msg in a transaction, which is aborted if the
+// message is not acknowledged or the transaction cannot commit.
+func (k *KafkaOrderEventPublisher) sendInTransaction(msg *sarama.ProducerMessage, pending *pendingMessage) error {
+	k.txnMu.Lock()
+	defer k.txnMu.Unlock()
+	err := k.syncProducer.BeginTxn()
+	if err == nil {
+		_, _, err = k.syncProducer.SendMessage(msg)
+		if err == nil {
+			err = k.syncProducer.CommitTxn()
+		}
+		if err != nil {
+			if abortErr := k.syncProducer.AbortTxn(); abortErr != nil {
+				k.logger.WarnContext(pending.ctx, "Failed to abort Kafka transaction", slog.String("error", abortErr.Error()))
+			}
+		}
+	}
+	if err != nil {
+		err = errcode.Errorf(errcode.KafkaTransactionAborted, "kafka transaction aborted: %w", err)
+	}
+	k.acknowledge(msg, err)
+	<-pending.result
+	return err
+}
+
+// waitForAcknowledgment waits for the dispatcher to report the outcome of the
+// message, until ctx ends or, with a detached publish timeout, that passes.
+func (k *KafkaOrderEventPublisher) waitForAcknowledgment(ctx context.Context, pending *pendingMessage) error {
+	if k.publishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), k.publishTimeout)
+		defer cancel()
+	}
+	select {
+	case err := <-pending.result:
+		if err != nil {
+			return errcode.Errorf(errcode.KafkaProduceFailed, "kafka producer error: %w", err)
+		}
+		return nil
+
+	case <-ctx.Done():
+		err := errcode.Errorf(errcode.KafkaAckTimeout, "context cancelled while waiting for kafka acknowledgment: %w", ctx.Err())
+		k.logger.WarnContext(ctx, "Context cancelled while waiting for Kafka acknowledgment",
+			slog.Int64("messaging.kafka.producer.duration_ms", time.Since(pending.queuedAt).Milliseconds()),
+			errcode.Attr(err),
+		)
+		return err
+	}
+}
+
+// dispatchAcknowledgments drains the producer's Successes and Errors channels
+// until the producer is closed, routing each outcome to its pending publish.
+func (k *KafkaOrderEventPublisher) dispatchAcknowledgments() {
+	defer close(k.dispatched)
+	successes, errs := k.producer.Successes(), k.producer.Errors()
+	for successes != nil || errs != nil {
+		select {
+		case msg, ok := <-successes:
+			if !ok {
+				successes = nil
+				continue
+			}
+			k.acknowledge(msg, nil)
+		case perr, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			k.acknowledge(perr.Msg, perr.Err)
+		}
+	}
+}
+
+// acknowledge records the outcome of a message as a span linked to its producer
+// span and hands the outcome to the waiting publisher, if it is still waiting.
+func (k *KafkaOrderEventPublisher) acknowledge(msg *sarama.ProducerMessage, ackErr error) {
+	pending, ok := msg.Metadata.(*pendingMessage)
+	if !ok {
+		k.logger.Warn("Received Kafka acknowledgment for an unknown message",
+			slog.String(string(semconv.MessagingDestinationNameKey), msg.Topic),
+		)
+		return
+	}
+
+	k.untrack(pending)
+	if ackErr != nil {
+		k.failed.Add(1)
+	} else {
+		k.acknowledged.Add(1)
+	}
+
+	duration := time.Since(pending.queuedAt)
+	ackCtx, span := k.tracer.Start(
+		pending.ctx,
+		fmt.Sprintf("%s ack", msg.Topic),
+		trace.WithTimestamp(pending.queuedAt),
+		trace.WithLinks(trace.Link{SpanContext: pending.publishSpan.SpanContext()}),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(msg.Topic),
+			ProducerSuccessKey.Bool(ackErr == nil),
+			attribute.Int("messaging.kafka.producer.duration_ms", int(duration.Milliseconds())),
+		),
+		trace.WithAttributes(k.semconvMode.partition(msg.Partition)...),
+	)
+	span.SetAttributes(k.regionAttributes()...)
+	if attempts := len(pending.dispatched()); attempts > 0 {
+		span.SetAttributes(producerAttemptsKey.Int(attempts))
+	}
+
+	// Logs are emitted in the ack span's context so the OTel log bridge
+	// correlates them with it
+	logAttrs := append([]any{
+		slog.String(string(semconv.MessagingDestinationNameKey), msg.Topic),
+		slog.Int64("messaging.kafka.producer.duration_ms", duration.Milliseconds()),
+	}, slogAttrs(k.semconvMode.partition(msg.Partition))...)
+	var coded error
+	if ackErr != nil {
+		coded = errcode.Wrap(errcode.KafkaProduceFailed, ackErr)
+	}
+	metricAttrs := publishMetricAttributes(semconv.MessagingSystemKafka, msg.Topic, k.region, coded)
+	if ackErr != nil {
+		errcode.RecordSpan(span, coded, ackErr.Error())
+		k.logger.ErrorContext(ackCtx, "Failed to publish order event",
+			append(logAttrs, slog.String("error", ackErr.Error()), errcode.Attr(coded))...,
+		)
+	} else {
+		span.SetAttributes(k.semconvMode.offset(msg.Offset)...)
+		k.logger.InfoContext(ackCtx, "Successfully published order event",
+			append(logAttrs, slogAttrs(k.semconvMode.offset(msg.Offset))...)...,
+		)
+	}
+	k.publishDuration.Record(ackCtx, duration.Seconds(), metric.WithAttributes(metricAttrs...))
+	if k.publishObserver != nil {
+		k.publishObserver.ObservePublish(duration, coded)
+	}
+	if k.slowPublishThreshold > 0 && duration > k.slowPublishThreshold {
+		k.flagSlowPublish(ackCtx, msg, duration, metricAttrs)
+	}
+	span.End()
+	k.endPublishSpan(pending, msg, ackErr)
+
+	pending.result <- ackErr
+}
+
+// flagSlowPublish reports a publish that exceeded the slow-publish threshold.
+// The alert notifier runs on its own goroutine so that it cannot hold up the
+// acknowledgments of other messages.
+func (k *KafkaOrderEventPublisher) flagSlowPublish(ctx context.Context, msg *sarama.ProducerMessage, duration time.Duration, metricAttrs []attribute.KeyValue) {
+	brokers := strings.Join(k.brokers, ",")
+	payloadSize := 0
+	if msg.Value != nil {
+		payloadSize = msg.Value.Length()
+	}
+
+	k.slowPublishes.Add(ctx, 1, metric.WithAttributes(metricAttrs...))
+	k.logger.WarnContext(ctx, "Slow order event publish",
+		slog.String("messaging.kafka.brokers", brokers),
+		slog.String(string(semconv.MessagingDestinationNameKey), msg.Topic),
+		slog.Int(string(semconv.MessagingMessageBodySizeKey), payloadSize),
+		slog.Int64("messaging.kafka.producer.duration_ms", duration.Milliseconds()),
+		slog.Int64("threshold_ms", k.slowPublishThreshold.Milliseconds()),
+	)
+
+	if k.alertNotifier == nil {
+		return
+	}
+	alert := ports.Alert{
+		Name:    "slow_publish",
+		Message: fmt.Sprintf("order event publish to %s took %s, threshold is %s", msg.Topic, duration, k.slowPublishThreshold),
+		Attributes: map[string]string{
+			"broker":       brokers,
+			"topic":        msg.Topic,
+			"payload_size": strconv.Itoa(payloadSize),
+			"duration":     duration.String(),
+		},
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := k.alertNotifier.Notify(ctx, alert); err != nil {
+			k.logger.WarnContext(ctx, "Failed to send slow publish alert", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// endPublishSpan records the outcome of msg as the last lifecycle event of its
+// producer span and ends it.
+func (k *KafkaOrderEventPublisher) endPublishSpan(pending *pendingMessage, msg *sarama.ProducerMessage, ackErr error) {
+	span := pending.publishSpan
+	for i, at := range pending.dispatched() {
+		if i == 0 {
+			span.AddEvent(PublishEventDispatched, trace.WithTimestamp(at))
+		} else {
+			span.AddEvent(PublishEventBrokerRetry, trace.WithTimestamp(at), trace.WithAttributes(producerAttemptsKey.Int(i+1)))
+		}
+	}
+	if ackErr != nil {
+		code := string(errcode.Of(errcode.Wrap(errcode.KafkaProduceFailed, ackErr)))
+		span.AddEvent(PublishEventFailed, trace.WithAttributes(errcode.Key.String(code)))
+		span.SetStatus(otelcodes.Error, code+": "+ackErr.Error())
+	} else {
+		span.AddEvent(PublishEventAcked,
+			trace.WithAttributes(k.semconvMode.partition(msg.Partition)...),
+			trace.WithAttributes(k.semconvMode.offset(msg.Offset)...),
+		)
+	}
+	span.End()
+}
+
+// createProducerSpan creates a distributed tracing span for the Kafka producer operation.
+func (k *KafkaOrderEventPublisher) createProducerSpan(ctx context.Context, msg *sarama.ProducerMessage) trace.Span {
+	spanContext, span := k.tracer.Start(
+		ctx,
+		fmt.Sprintf("%s publish", msg.Topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.PeerService("kafka"),
+			semconv.NetworkTransportTCP,
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(msg.Topic),
+		),
+		trace.WithAttributes(k.semconvMode.publishOperation()...),
+		trace.WithAttributes(k.semconvMode.partition(msg.Partition)...),
+	)
+
+	span.SetAttributes(k.regionAttributes()...)
+
+	// Let consumers and telemetry pipelines filter synthetic traffic
+	span.SetAttributes(baggageAttributes(ctx)...)
+
+	// Inject tracing context and baggage into message headers
+	for key, value := range PropagationHeaders(spanContext) {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{
+			Key:   []byte(key),
+			Value: []byte(value),
+		})
+	}
+
+	return span
+}
+
+// regionAttributes returns the cloud.region of the publisher, none without
+// a region.
+func (k *KafkaOrderEventPublisher) regionAttributes() []attribute.KeyValue {
+	if k.region == "" {
+		return nil
+	}
+	return []attribute.KeyValue{semconv.CloudRegion(k.region)}
+}
+
+// ProducerInterceptor instruments the sarama producer underneath a
+// KafkaOrderEventPublisher. Install it with kafka.WithProducerInterceptors to
+// see where publish latency goes: the producer span then records when sarama
+// dispatched the message, separating time spent waiting for the producer's
+// input from time spent batching and waiting for the broker, and every
+// broker-level retry. The ack span records the number of attempts.
+type ProducerInterceptor struct{}
+
+// Compile-time check that ProducerInterceptor implements sarama.ProducerInterceptor
+var _ sarama.ProducerInterceptor = ProducerInterceptor{}
+
+// OnSend is called by sarama each time it dispatches msg, including retries.
+func (ProducerInterceptor) OnSend(msg *sarama.ProducerMessage) {
+	pending, ok := msg.Metadata.(*pendingMessage)
+	if !ok {
+		return
+	}
+	pending.mu.Lock()
+	defer pending.mu.Unlock()
+	pending.dispatches = append(pending.dispatches, time.Now())
+}
+
+// MapCarrier implements the TextMapCarrier interface for OpenTelemetry propagation.
+type MapCarrier struct {
+	m map[string]string
+}
+
+// Get returns the value of key. Propagation header names are case-insensitive
+// but Kafka header names are not, so a differently cased header (for example
+// Tracestate from a non-Go producer) is matched as well.
+func (c *MapCarrier) Get(key string) string {
+	if v, ok := c.m[key]; ok {
+		return v
+	}
+	for k, v := range c.m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func (c *MapCarrier) Set(key, value string) {
+	c.m[key] = value
+}
+
+func (c *MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.m))
+	for k := range c.m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// NoOpOrderEventPublisher is a no-operation implementation of OrderEventPublisher.
+// This adapter is used when Kafka is not configured or unavailable.
+// It implements the OrderEventPublisher port but doesn't actually publish messages.
+// Use StoreAndReplayOrderEventPublisher (ORDER_EVENT_NOOP_STORE_PATH) to keep the
+// skipped events for a later replay.
+type NoOpOrderEventPublisher struct{}
+
+// Compile-time check that NoOpOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*NoOpOrderEventPublisher)(nil)
+
+// PublishOrderCompleted implements the OrderEventPublisher interface but does nothing.
+// This allows the system to continue functioning even when the messaging infrastructure is unavailable.
+func (n *NoOpOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	return nil
+}
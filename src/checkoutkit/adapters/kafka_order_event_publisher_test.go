@@ -0,0 +1,742 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// newTestTracing installs an in-memory span recorder as the global tracer
+// provider for the duration of the test.
+func newTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return recorder
+}
+
+// newTestMetrics installs a manual metric reader as the global meter provider
+// for the duration of the test. Publishers must be created after calling it.
+func newTestMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	prev := otel.GetMeterProvider()
+	otel.SetMeterProvider(mp)
+	t.Cleanup(func() { otel.SetMeterProvider(prev) })
+	return reader
+}
+
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range recorder.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("no ended span named %q", name)
+	return nil
+}
+
+func eventNames(span sdktrace.ReadOnlySpan) []string {
+	var names []string
+	for _, ev := range span.Events() {
+		names = append(names, ev.Name)
+	}
+	return names
+}
+
+func TestKafkaOrderEventPublisherRecordsAckOnLinkedSpan(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	publish := endedSpan(t, recorder, "orders publish")
+	ack := endedSpan(t, recorder, "orders ack")
+
+	if len(ack.Links()) != 1 || ack.Links()[0].SpanContext.SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("ack span links = %v, want link to publish span", ack.Links())
+	}
+	if ack.StartTime().Before(publish.StartTime()) {
+		t.Error("ack span starts before the message was queued")
+	}
+	attrs := map[string]bool{}
+	for _, kv := range ack.Attributes() {
+		attrs[string(kv.Key)] = true
+	}
+	if !attrs["messaging.kafka.message.offset"] || !attrs["messaging.kafka.producer.success"] {
+		t.Errorf("ack span attributes = %v, want offset and success", ack.Attributes())
+	}
+	for _, kv := range publish.Attributes() {
+		if kv.Key == "messaging.kafka.message.offset" {
+			t.Error("offset must be recorded on the ack span, not the publish span")
+		}
+	}
+	if got, want := eventNames(publish), []string{PublishEventQueued, PublishEventAcked}; !slices.Equal(got, want) {
+		t.Errorf("publish span events = %v, want %v", got, want)
+	}
+	if got, want := pub.Stats(), (PublisherStats{Topic: "orders", Acknowledged: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestKafkaOrderEventPublisherWithRegion(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(), WithRegion("us-east-1"))
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if msgs := producer.Messages(); len(msgs) != 1 || msgs[0].Topic != "us-east-1.orders" {
+		t.Fatalf("published %v, want one message on us-east-1.orders", msgs)
+	}
+	publish := endedSpan(t, recorder, "us-east-1.orders publish")
+	if !slices.Contains(publish.Attributes(), semconv.CloudRegion("us-east-1")) {
+		t.Errorf("publish span attributes = %v, want cloud.region", publish.Attributes())
+	}
+	if got, want := pub.Stats(), (PublisherStats{Topic: "us-east-1.orders", Region: "us-east-1", Acknowledged: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestKafkaTransactionalOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewTransactionalProducer(t, "checkout-test-publisher")
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+	pub := NewKafkaTransactionalOrderEventPublisher(producer, discardLogger())
+	defer pub.Close(context.Background())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if producer.TxnStatus() != sarama.ProducerTxnFlagReady {
+		t.Errorf("TxnStatus() = %v, want the transaction committed", producer.TxnStatus())
+	}
+
+	err := pub.PublishOrderCompleted(context.Background(), testOrder())
+	if !errors.Is(err, sarama.ErrNotEnoughReplicas) || errcode.Of(err) != errcode.KafkaTransactionAborted {
+		t.Errorf("PublishOrderCompleted() = %v, want %v with code %s", err, sarama.ErrNotEnoughReplicas, errcode.KafkaTransactionAborted)
+	}
+	if producer.TxnStatus() != sarama.ProducerTxnFlagReady {
+		t.Errorf("TxnStatus() = %v, want the transaction aborted", producer.TxnStatus())
+	}
+	if got := pub.Stats(); got.Acknowledged != 1 || got.Failed != 1 {
+		t.Errorf("Stats() = %+v, want one order acknowledged and one failed", got)
+	}
+	var failed []string
+	for _, s := range recorder.Ended() {
+		if s.Name() == "orders publish" && s.Status().Code == otelcodes.Error {
+			failed = append(failed, s.Status().Description)
+		}
+	}
+	if len(failed) != 1 || !strings.HasPrefix(failed[0], string(errcode.KafkaTransactionAborted)) {
+		t.Errorf("failed publish span statuses = %v, want one %s", failed, errcode.KafkaTransactionAborted)
+	}
+}
+
+func TestKafkaOrderEventPublisherRouting(t *testing.T) {
+	newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(),
+		WithRegion("eu-west-1"),
+		WithTopic("staging-orders"),
+		WithMessageKey(OrderIDKey),
+		WithStaticHeaders(map[string]string{"environment": "staging"}),
+	)
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	msgs := producer.Messages()
+	if len(msgs) != 1 || msgs[0].Topic != "eu-west-1.staging-orders" {
+		t.Fatalf("published %v, want one message on eu-west-1.staging-orders", msgs)
+	}
+	if key, _ := msgs[0].Key.Encode(); string(key) != testOrder().GetOrderId() {
+		t.Errorf("message key = %q, want the order ID %q", key, testOrder().GetOrderId())
+	}
+	if got := kafkatest.Headers(msgs[0])["environment"]; got != "staging" {
+		t.Errorf("environment header = %q, want staging", got)
+	}
+}
+
+func TestKafkaOrderEventPublisherWithoutKey(t *testing.T) {
+	newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if msgs := producer.Messages(); len(msgs) != 1 || msgs[0].Key != nil {
+		t.Errorf("published %v, want one message without a key", msgs)
+	}
+}
+
+// recordingLogProcessor keeps every record emitted through the OTel log bridge.
+type recordingLogProcessor struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (p *recordingLogProcessor) OnEmit(ctx context.Context, r *sdklog.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = append(p.records, r.Clone())
+	return nil
+}
+
+func (p *recordingLogProcessor) Shutdown(context.Context) error   { return nil }
+func (p *recordingLogProcessor) ForceFlush(context.Context) error { return nil }
+
+func TestKafkaOrderEventPublisherCorrelatesLogsWithAckSpan(t *testing.T) {
+	recorder := newTestTracing(t)
+	processor := &recordingLogProcessor{}
+	logger := otelslog.NewLogger("test", otelslog.WithLoggerProvider(sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))))
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+
+	if err := NewKafkaOrderEventPublisher(producer, logger).PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	ack := endedSpan(t, recorder, "orders ack")
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	for _, r := range processor.records {
+		if r.Body().AsString() != "Successfully published order event" {
+			continue
+		}
+		if r.SpanID() != ack.SpanContext().SpanID() || r.TraceID() != ack.SpanContext().TraceID() {
+			t.Errorf("log record span = %v/%v, want ack span %v/%v", r.TraceID(), r.SpanID(), ack.SpanContext().TraceID(), ack.SpanContext().SpanID())
+		}
+		var offsetKind otellog.Kind
+		r.WalkAttributes(func(kv otellog.KeyValue) bool {
+			if kv.Key == "messaging.kafka.message.offset" {
+				offsetKind = kv.Value.Kind()
+			}
+			return true
+		})
+		if offsetKind != otellog.KindInt64 {
+			t.Errorf("offset attribute kind = %v, want %v", offsetKind, otellog.KindInt64)
+		}
+		return
+	}
+	t.Fatal("no success log record emitted")
+}
+
+func TestKafkaOrderEventPublisherRecordsLatencyExemplars(t *testing.T) {
+	recorder := newTestTracing(t)
+	reader := newTestMetrics(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "PlaceOrder")
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	span.End()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	ack := endedSpan(t, recorder, "orders ack")
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "messaging.publish.duration" {
+				continue
+			}
+			hist := m.Data.(metricdata.Histogram[float64])
+			if len(hist.DataPoints) != 1 || hist.DataPoints[0].Count != 1 {
+				t.Fatalf("data points = %+v, want one measurement", hist.DataPoints)
+			}
+			exemplars := hist.DataPoints[0].Exemplars
+			if len(exemplars) != 1 {
+				t.Fatalf("exemplars = %+v, want one", exemplars)
+			}
+			if got, want := trace.TraceID(exemplars[0].TraceID), ack.SpanContext().TraceID(); got != want {
+				t.Errorf("exemplar trace = %v, want %v", got, want)
+			}
+			if got, want := trace.SpanID(exemplars[0].SpanID), ack.SpanContext().SpanID(); got != want {
+				t.Errorf("exemplar span = %v, want ack span %v", got, want)
+			}
+			return
+		}
+	}
+	t.Fatal("messaging.publish.duration not recorded")
+}
+
+// alertRecorder is an AlertNotifier that hands alerts to the test.
+type alertRecorder chan ports.Alert
+
+func (r alertRecorder) Notify(ctx context.Context, alert ports.Alert) error {
+	r <- alert
+	return nil
+}
+
+func TestKafkaOrderEventPublisherFlagsSlowPublishes(t *testing.T) {
+	reader := newTestMetrics(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSlowAck(20 * time.Millisecond)
+	alerts := make(alertRecorder, 1)
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(),
+		WithBrokers("kafka:9092"),
+		WithSlowPublishThreshold(10*time.Millisecond),
+		WithAlertNotifier(alerts),
+	)
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	select {
+	case alert := <-alerts:
+		if alert.Name != "slow_publish" || alert.Attributes["broker"] != "kafka:9092" || alert.Attributes["topic"] != "orders" {
+			t.Errorf("alert = %+v, want slow_publish for kafka:9092/orders", alert)
+		}
+		if size, want := alert.Attributes["payload_size"], strconv.Itoa(len(marshalOrder(t, testOrder()))); size != want {
+			t.Errorf("alert payload_size = %s, want %s", size, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no slow publish alert sent")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == "messaging.publish.slow" {
+				if got := m.Data.(metricdata.Sum[int64]).DataPoints[0].Value; got != 1 {
+					t.Errorf("messaging.publish.slow = %d, want 1", got)
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("messaging.publish.slow not recorded")
+}
+
+func TestKafkaOrderEventPublisherReportsProducerErrors(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	brokerErr := sarama.ErrNotLeaderForPartition
+	producer.ExpectError(brokerErr)
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	err := pub.PublishOrderCompleted(context.Background(), testOrder())
+	if !errors.Is(err, brokerErr) {
+		t.Fatalf("PublishOrderCompleted() = %v, want %v", err, brokerErr)
+	}
+	if got := errcode.Of(err); got != errcode.KafkaProduceFailed {
+		t.Errorf("errcode.Of() = %v, want %v", got, errcode.KafkaProduceFailed)
+	}
+	if ack := endedSpan(t, recorder, "orders ack"); ack.Status().Code != otelcodes.Error {
+		t.Errorf("ack span status = %v, want Error", ack.Status())
+	}
+	publish := endedSpan(t, recorder, "orders publish")
+	if got, want := eventNames(publish), []string{PublishEventQueued, PublishEventFailed}; !slices.Equal(got, want) {
+		t.Errorf("publish span events = %v, want %v", got, want)
+	}
+	if publish.Status().Code != otelcodes.Error {
+		t.Errorf("publish span status = %v, want Error", publish.Status())
+	}
+}
+
+func TestProducerInterceptorRecordsDispatchAndRetries(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	// The mock producer does not run interceptors, so dispatch the message
+	// twice as sarama does when the first attempt is retried
+	producer.ExpectMessage(func(msg *sarama.ProducerMessage) error {
+		ProducerInterceptor{}.OnSend(msg)
+		ProducerInterceptor{}.OnSend(msg)
+		return nil
+	})
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	publish := endedSpan(t, recorder, "orders publish")
+	want := []string{PublishEventQueued, PublishEventDispatched, PublishEventBrokerRetry, PublishEventAcked}
+	if got := eventNames(publish); !slices.Equal(got, want) {
+		t.Errorf("publish span events = %v, want %v", got, want)
+	}
+	var attempts int64
+	for _, kv := range endedSpan(t, recorder, "orders ack").Attributes() {
+		if kv.Key == producerAttemptsKey {
+			attempts = kv.Value.AsInt64()
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("ack span %s = %d, want 2", producerAttemptsKey, attempts)
+	}
+}
+
+type observedPublish struct {
+	duration time.Duration
+	err      error
+}
+
+type publishRecorder []observedPublish
+
+func (r *publishRecorder) ObservePublish(duration time.Duration, err error) {
+	*r = append(*r, observedPublish{duration, err})
+}
+
+func TestKafkaOrderEventPublisherReportsToObserver(t *testing.T) {
+	var observed publishRecorder
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	producer.ExpectError(sarama.ErrNotLeaderForPartition)
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(), WithPublishObserver(&observed))
+
+	pub.PublishOrderCompleted(context.Background(), testOrder())
+	pub.PublishOrderCompleted(context.Background(), testOrder())
+
+	if len(observed) != 2 {
+		t.Fatalf("observed %d publishes, want 2", len(observed))
+	}
+	if observed[0].err != nil {
+		t.Errorf("first publish err = %v, want nil", observed[0].err)
+	}
+	if got := errcode.Of(observed[1].err); got != errcode.KafkaProduceFailed {
+		t.Errorf("second publish code = %v, want %v", got, errcode.KafkaProduceFailed)
+	}
+}
+
+func TestKafkaOrderEventPublisherPropagatesBaggage(t *testing.T) {
+	recorder := newTestTracing(t)
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+
+	var headers map[string]string
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectMessage(func(msg *sarama.ProducerMessage) error {
+		headers = kafkatest.Headers(msg)
+		return nil
+	})
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	synthetic, _ := baggage.NewMember(BaggageSyntheticRequest, "true")
+	session, _ := baggage.NewMember(BaggageSessionID, "session-1")
+	bag, _ := baggage.New(synthetic, session)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	if headers["traceparent"] == "" {
+		t.Error("traceparent header missing")
+	}
+	got, err := baggage.Parse(headers["baggage"])
+	if err != nil {
+		t.Fatalf("baggage header %q: %v", headers["baggage"], err)
+	}
+	if got.Member(BaggageSyntheticRequest).Value() != "true" || got.Member(BaggageSessionID).Value() != "session-1" {
+		t.Errorf("baggage header = %q, want synthetic_request and session.id", headers["baggage"])
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range endedSpan(t, recorder, "orders publish").Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if !attrs["app.synthetic_request"].AsBool() {
+		t.Error("publish span missing app.synthetic_request=true")
+	}
+}
+
+func TestKafkaOrderEventPublisherWithoutProducer(t *testing.T) {
+	pub := NewKafkaOrderEventPublisher(nil, discardLogger())
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Errorf("PublishOrderCompleted() = %v, want nil", err)
+	}
+}
+
+func TestKafkaOrderEventPublisherClose(t *testing.T) {
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.Close(context.Background()); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want a %s error", err, errcode.PublisherClosed)
+	}
+	if got := pub.Stats(); got.Acknowledged != 1 || got.InFlight != 0 {
+		t.Errorf("Stats() = %+v, want the published order acknowledged", got)
+	}
+}
+
+// TestKafkaOrderEventPublisherMatchesConcurrentAcks publishes hundreds of
+// orders at once over a producer failing every third message with an error of
+// its own, and checks that each publish returns the outcome of its own
+// message. Run it with -race.
+func TestKafkaOrderEventPublisherDetachedPublishTimeout(t *testing.T) {
+	newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSlowAck(20 * time.Millisecond)
+	producer.ExpectSlowAck(200 * time.Millisecond)
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(), WithDetachedPublishTimeout(100*time.Millisecond))
+
+	// The request ends while the message is queued
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Errorf("PublishOrderCompleted() = %v, want the acknowledgment awaited", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.KafkaAckTimeout {
+		t.Errorf("PublishOrderCompleted() = %v past the timeout, want a %s error", err, errcode.KafkaAckTimeout)
+	}
+}
+
+func TestKafkaOrderEventPublisherBackgroundAck(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectError(sarama.ErrNotLeaderForPartition)
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(), WithBackgroundAck())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v, want nil once queued", err)
+	}
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	publish := endedSpan(t, recorder, "orders publish")
+	ack := endedSpan(t, recorder, "orders ack")
+	if ack.Status().Code != otelcodes.Error {
+		t.Errorf("ack span status = %v, want the failure recorded", ack.Status())
+	}
+	if links := ack.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("ack span links = %v, want the producer span", links)
+	}
+	if got := pub.Stats(); got.Failed != 1 || got.InFlight != 0 {
+		t.Errorf("Stats() = %+v, want the order failed", got)
+	}
+}
+
+func TestKafkaOrderEventPublisherCloseLogsDroppedMessages(t *testing.T) {
+	processor := &recordingLogProcessor{}
+	logger := otelslog.NewLogger("test", otelslog.WithLoggerProvider(sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))))
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSlowAck(200 * time.Millisecond)
+	pub := NewKafkaOrderEventPublisher(producer, logger)
+
+	// The caller gives up on the acknowledgment, leaving the message in flight
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); errcode.Of(err) != errcode.KafkaAckTimeout {
+		t.Fatalf("PublishOrderCompleted() = %v, want a %s error", err, errcode.KafkaAckTimeout)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pub.Close(ctx); errcode.Of(err) != errcode.KafkaAckTimeout {
+		t.Fatalf("Close() = %v, want a %s error", err, errcode.KafkaAckTimeout)
+	}
+
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	for _, r := range processor.records {
+		if r.Body().AsString() != "Order event not acknowledged before shutdown, it may be lost" {
+			continue
+		}
+		var orderID string
+		r.WalkAttributes(func(kv otellog.KeyValue) bool {
+			if kv.Key == "order_id" {
+				orderID = kv.Value.AsString()
+			}
+			return true
+		})
+		if orderID != testOrder().GetOrderId() {
+			t.Errorf("dropped message logged with order_id %q, want %q", orderID, testOrder().GetOrderId())
+		}
+		return
+	}
+	t.Fatal("dropped message not logged")
+}
+
+func TestKafkaOrderEventPublisherMatchesConcurrentAcks(t *testing.T) {
+	const publishes = 300
+	producer := kafkatest.NewProducer(t)
+	// The producer answers messages in the order they arrive, so outcomes[i]
+	// is the outcome of producer.Messages()[i]
+	outcomes := make([]error, publishes)
+	for i := range outcomes {
+		switch {
+		case i%3 == 2:
+			outcomes[i] = fmt.Errorf("broker error %d", i)
+			producer.ExpectError(outcomes[i])
+		case i%50 == 0:
+			producer.ExpectSlowAck(time.Millisecond)
+		default:
+			producer.ExpectSuccess()
+		}
+	}
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger())
+
+	var mu sync.Mutex
+	results := make(map[string]error, publishes)
+	var wg sync.WaitGroup
+	for i := range publishes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order := testOrder()
+			order.OrderId = fmt.Sprintf("order-%d", i)
+			err := pub.PublishOrderCompleted(context.Background(), order)
+			mu.Lock()
+			defer mu.Unlock()
+			results[order.OrderId] = err
+		}()
+	}
+	// Read the stats while publishing, as the debug endpoint does
+	stop := make(chan struct{})
+	statsRead := make(chan struct{})
+	go func() {
+		defer close(statsRead)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				pub.Stats()
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-statsRead
+
+	messages := producer.Messages()
+	if len(messages) != publishes {
+		t.Fatalf("produced %d messages, want %d", len(messages), publishes)
+	}
+	for i, msg := range messages {
+		value, err := msg.Value.Encode()
+		if err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		var order pb.OrderResult
+		if err := proto.Unmarshal(value, &order); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		got, ok := results[order.GetOrderId()]
+		switch {
+		case !ok:
+			t.Errorf("message %d carries unknown order %q", i, order.GetOrderId())
+		case outcomes[i] == nil && got != nil:
+			t.Errorf("PublishOrderCompleted(%s) = %v, want nil", order.GetOrderId(), got)
+		case outcomes[i] != nil && !errors.Is(got, outcomes[i]):
+			t.Errorf("PublishOrderCompleted(%s) = %v, want %v", order.GetOrderId(), got, outcomes[i])
+		}
+	}
+	want := PublisherStats{Topic: "orders", Acknowledged: publishes - publishes/3, Failed: publishes / 3}
+	if got := pub.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestKafkaSyncOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	producer := kafkatest.NewSyncProducer(t)
+	producer.ExpectSendMessageAndSucceed()
+	pub := NewKafkaSyncOrderEventPublisher(producer, discardLogger())
+
+	// The send is not abandoned when the caller gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	publish := endedSpan(t, recorder, "orders publish")
+	if got, want := eventNames(publish), []string{PublishEventQueued, PublishEventAcked}; !slices.Equal(got, want) {
+		t.Errorf("publish span events = %v, want %v", got, want)
+	}
+	if ack := endedSpan(t, recorder, "orders ack"); ack.Status().Code == otelcodes.Error {
+		t.Errorf("ack span status = %v, want success", ack.Status())
+	}
+	if got, want := pub.Stats(), (PublisherStats{Topic: "orders", Acknowledged: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want a %s error", err, errcode.PublisherClosed)
+	}
+}
+
+func TestKafkaSyncOrderEventPublisherReportsProducerErrors(t *testing.T) {
+	producer := kafkatest.NewSyncProducer(t)
+	brokerErr := sarama.ErrNotEnoughReplicas
+	producer.ExpectSendMessageAndFail(brokerErr)
+	pub := NewKafkaSyncOrderEventPublisher(producer, discardLogger())
+
+	err := pub.PublishOrderCompleted(context.Background(), testOrder())
+	if !errors.Is(err, brokerErr) || errcode.Of(err) != errcode.KafkaProduceFailed {
+		t.Errorf("PublishOrderCompleted() = %v, want %v with code %s", err, brokerErr, errcode.KafkaProduceFailed)
+	}
+	if got := pub.Stats(); got.Failed != 1 || got.InFlight != 0 {
+		t.Errorf("Stats() = %+v, want the order failed", got)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// DecodeMode controls how tolerant the subscriber is of schema drift.
+type DecodeMode int
+
+const (
+	// DecodeLenient discards unknown fields and accepts partially populated
+	// messages. This is the default so that producers can add fields freely.
+	DecodeLenient DecodeMode = iota
+	// DecodeStrict rejects messages carrying fields the consumer does not know
+	// about and messages missing fields the consumer requires.
+	DecodeStrict
+)
+
+// ErrUnknownFields is returned in strict mode when a message carries fields
+// that are not part of the consumer's schema.
+var ErrUnknownFields = errors.New("message contains unknown fields")
+
+// ContentTypeHeader is the Kafka header naming the encoding of a message. A
+// message without it is taken to be protobuf, as the checkout publishes.
+const ContentTypeHeader = "content-type"
+
+// ErrUnsupportedContentType is returned for a message whose ContentTypeHeader
+// is not a protobuf content type.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// protobufContentTypes are the values of ContentTypeHeader the subscriber
+// decodes.
+var protobufContentTypes = map[string]bool{
+	"application/protobuf":   true,
+	"application/x-protobuf": true,
+}
+
+func (m DecodeMode) String() string {
+	if m == DecodeStrict {
+		return "strict"
+	}
+	return "lenient"
+}
+
+// ParseDecodeMode parses a decode mode name as used in deployment configuration.
+// An empty string selects the lenient default.
+func ParseDecodeMode(s string) (DecodeMode, error) {
+	switch strings.ToLower(s) {
+	case "", "lenient":
+		return DecodeLenient, nil
+	case "strict":
+		return DecodeStrict, nil
+	default:
+		return DecodeLenient, fmt.Errorf("unknown decode mode %q, expected \"strict\" or \"lenient\"", s)
+	}
+}
+
+// DecodeOrderResult decodes a protobuf-encoded OrderResult according to mode.
+func DecodeOrderResult(data []byte, mode DecodeMode) (*pb.OrderResult, error) {
+	order := &pb.OrderResult{}
+	opts := proto.UnmarshalOptions{DiscardUnknown: mode == DecodeLenient}
+	if err := opts.Unmarshal(data, order); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order result: %w", err)
+	}
+	if mode == DecodeLenient {
+		return order, nil
+	}
+
+	if path := findUnknownFields(order.ProtoReflect(), "order"); path != "" {
+		return nil, fmt.Errorf("%w at %s", ErrUnknownFields, path)
+	}
+	if err := validation.ValidateRequiredFields(order); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateOrderResult(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// findUnknownFields returns the path of the first message that carries unknown
+// fields, or an empty string if there are none.
+func findUnknownFields(m protoreflect.Message, path string) string {
+	if len(m.GetUnknown()) > 0 {
+		return path
+	}
+	var found string
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+			return true
+		}
+		fieldPath := path + "." + string(fd.Name())
+		if fd.IsList() {
+			list := v.List()
+			for i := 0; i < list.Len() && found == ""; i++ {
+				found = findUnknownFields(list.Get(i).Message(), fmt.Sprintf("%s[%d]", fieldPath, i))
+			}
+		} else if !fd.IsMap() {
+			found = findUnknownFields(v.Message(), fieldPath)
+		}
+		return found == ""
+	})
+	return found
+}
+
+// KafkaOrderEventSubscriber is the consumer-side Kafka adapter. It implements
+// sarama.ConsumerGroupHandler, decodes order events from the topic and drives
+// the OrderEventHandler port with them.
+type KafkaOrderEventSubscriber struct {
+	handler ports.OrderEventHandler
+	mode    DecodeMode
+	logger  *slog.Logger
+	tracer  trace.Tracer
+	dlq     DeadLetterQueue
+
+	semconvMode SemconvMode
+}
+
+// KafkaSubscriberOption configures optional behavior of a KafkaOrderEventSubscriber.
+type KafkaSubscriberOption func(*KafkaOrderEventSubscriber)
+
+// WithSubscriberSemconvMode selects the messaging semantic conventions of the
+// subscriber's spans. The default is SemconvOld.
+func WithSubscriberSemconvMode(mode SemconvMode) KafkaSubscriberOption {
+	return func(s *KafkaOrderEventSubscriber) {
+		s.semconvMode = mode
+	}
+}
+
+// WithDeadLetterQueue routes the messages that fail to decode or to be handled
+// to dlq, with the error, instead of only logging them.
+func WithDeadLetterQueue(dlq DeadLetterQueue) KafkaSubscriberOption {
+	return func(s *KafkaOrderEventSubscriber) {
+		s.dlq = dlq
+	}
+}
+
+// Compile-time check that KafkaOrderEventSubscriber implements sarama.ConsumerGroupHandler
+var _ sarama.ConsumerGroupHandler = (*KafkaOrderEventSubscriber)(nil)
+
+// NewKafkaOrderEventSubscriber creates a subscriber that decodes messages
+// using mode and passes them to handler.
+func NewKafkaOrderEventSubscriber(handler ports.OrderEventHandler, mode DecodeMode, logger *slog.Logger, opts ...KafkaSubscriberOption) *KafkaOrderEventSubscriber {
+	s := &KafkaOrderEventSubscriber{
+		handler: handler,
+		mode:    mode,
+		logger:  logger,
+		tracer:  otel.Tracer("checkout-kafka-adapter"),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Setup is run at the beginning of a new consumer group session.
+func (s *KafkaOrderEventSubscriber) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+// Cleanup is run at the end of a consumer group session.
+func (s *KafkaOrderEventSubscriber) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim processes every message of a claim. Messages that fail to decode
+// or handle are logged, sent to the dead-letter queue if there is one, and
+// marked so that a single bad message cannot block the partition.
+func (s *KafkaOrderEventSubscriber) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		if err := s.HandleMessage(session.Context(), msg); err != nil {
+			s.reject(session.Context(), msg, err)
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+// reject logs msg, which failed with err, and sends it to the dead-letter
+// queue.
+func (s *KafkaOrderEventSubscriber) reject(ctx context.Context, msg *sarama.ConsumerMessage, err error) {
+	attrs := []any{
+		slog.String("topic", msg.Topic),
+		slog.Int("partition", int(msg.Partition)),
+		slog.Int64("offset", msg.Offset),
+		slog.String("error", err.Error()),
+		errcode.Attr(err),
+	}
+	s.logger.ErrorContext(ctx, "Failed to handle order event", attrs...)
+	if s.dlq == nil {
+		return
+	}
+	if dlqErr := s.dlq.DeadLetter(ctx, msg, err); dlqErr != nil {
+		attrs[3] = slog.String("error", dlqErr.Error())
+		attrs[4] = errcode.Attr(dlqErr)
+		s.logger.ErrorContext(ctx, "Failed to dead-letter order event", attrs...)
+	}
+}
+
+// HandleMessage decodes a single Kafka message and hands it to the handler. The
+// trace context and baggage injected by the producer are extracted from the
+// message headers, and the handler runs inside a consumer span that continues
+// the producer's trace and links back to its publish span.
+func (s *KafkaOrderEventSubscriber) HandleMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	ctx, span := s.createConsumerSpan(ctx, msg)
+	defer span.End()
+
+	if contentType := header(msg, ContentTypeHeader); contentType != "" && !protobufContentTypes[contentType] {
+		err := errcode.Errorf(errcode.DecodeFailed, "%w %q", ErrUnsupportedContentType, contentType)
+		errcode.RecordSpan(span, err, "failed to decode order event")
+		return err
+	}
+	order, err := DecodeOrderResult(msg.Value, s.mode)
+	if err != nil {
+		err = errcode.Errorf(errcode.DecodeFailed, "failed to decode order event in %s mode: %w", s.mode, err)
+		errcode.RecordSpan(span, err, "failed to decode order event")
+		return err
+	}
+
+	if err := s.handle(ctx, order); err != nil {
+		err = errcode.Wrap(errcode.HandlerFailed, err)
+		errcode.RecordSpan(span, err, "order event handler failed")
+		return err
+	}
+	return nil
+}
+
+// handle hands order to the handler, and turns a panic of the handler into an
+// error so that the message is rejected rather than the consumer stopped.
+func (s *KafkaOrderEventSubscriber) handle(ctx context.Context, order *pb.OrderResult) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return s.handler.HandleOrderCompleted(ctx, order)
+}
+
+// header returns the value of the header key of msg, matched
+// case-insensitively.
+func header(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if h != nil && strings.EqualFold(string(h.Key), key) {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// createConsumerSpan starts a consumer span for msg. The span is parented to the
+// producer's span context when the message carries one and also links to it, so
+// the hand-off is visible both in the trace tree and in backends that only
+// render links.
+func (s *KafkaOrderEventSubscriber) createConsumerSpan(ctx context.Context, msg *sarama.ConsumerMessage) (context.Context, trace.Span) {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers[string(h.Key)] = string(h.Value)
+		}
+	}
+	ctx = ExtractPropagationHeaders(ctx, headers)
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.PeerService("kafka"),
+			semconv.NetworkTransportTCP,
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingMessageBodySize(len(msg.Value)),
+		),
+		trace.WithAttributes(s.semconvMode.deliverOperation()...),
+		trace.WithAttributes(s.semconvMode.partition(msg.Partition)...),
+		trace.WithAttributes(s.semconvMode.offset(msg.Offset)...),
+	}
+	if producer := trace.SpanContextFromContext(ctx); producer.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+
+	ctx, span := s.tracer.Start(ctx, fmt.Sprintf("%s deliver", msg.Topic), opts...)
+	span.SetAttributes(baggageAttributes(ctx)...)
+	return ctx, span
+}
@@ -0,0 +1,325 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// recordingHandler records every order the subscriber hands to it.
+type recordingHandler struct {
+	orders []*pb.OrderResult
+	ctx    context.Context
+}
+
+func (r *recordingHandler) HandleOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	r.orders = append(r.orders, order)
+	r.ctx = ctx
+	return nil
+}
+
+func marshalOrder(t *testing.T, order *pb.OrderResult) []byte {
+	t.Helper()
+	data, err := proto.Marshal(order)
+	if err != nil {
+		t.Fatalf("proto.Marshal() = %v", err)
+	}
+	return data
+}
+
+// withFutureField simulates a producer running a newer schema by appending a
+// field number the consumer's OrderResult does not define.
+func withFutureField(data []byte) []byte {
+	data = protowire.AppendTag(data, 99, protowire.BytesType)
+	return protowire.AppendString(data, "added-by-a-newer-producer")
+}
+
+func TestParseDecodeMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    DecodeMode
+		wantErr bool
+	}{
+		{"", DecodeLenient, false},
+		{"lenient", DecodeLenient, false},
+		{"STRICT", DecodeStrict, false},
+		{"paranoid", DecodeLenient, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecodeMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDecodeMode(%q) = %v, %v; want %v, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSubscriberSchemaDrift(t *testing.T) {
+	incomplete := testOrder()
+	incomplete.ShippingAddress = nil
+
+	tests := []struct {
+		name       string
+		payload    func(t *testing.T) []byte
+		mode       DecodeMode
+		wantErr    error
+		wantHandle bool
+	}{
+		{"lenient accepts current schema", func(t *testing.T) []byte { return marshalOrder(t, testOrder()) }, DecodeLenient, nil, true},
+		{"strict accepts current schema", func(t *testing.T) []byte { return marshalOrder(t, testOrder()) }, DecodeStrict, nil, true},
+		{"lenient ignores added field", func(t *testing.T) []byte { return withFutureField(marshalOrder(t, testOrder())) }, DecodeLenient, nil, true},
+		{"strict rejects added field", func(t *testing.T) []byte { return withFutureField(marshalOrder(t, testOrder())) }, DecodeStrict, ErrUnknownFields, false},
+		{"lenient accepts missing field", func(t *testing.T) []byte { return marshalOrder(t, incomplete) }, DecodeLenient, nil, true},
+		{"strict rejects missing field", func(t *testing.T) []byte { return marshalOrder(t, incomplete) }, DecodeStrict, &validation.Error{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordingHandler{}
+			sub := NewKafkaOrderEventSubscriber(handler, tt.mode, discardLogger())
+
+			err := sub.HandleMessage(context.Background(), &sarama.ConsumerMessage{Value: tt.payload(t)})
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("HandleMessage() = %v, want nil", err)
+				}
+			case *validation.Error:
+				if !errors.As(err, &want) {
+					t.Fatalf("HandleMessage() = %v, want *validation.Error", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("HandleMessage() = %v, want %v", err, want)
+				}
+			}
+			if got := len(handler.orders) == 1; got != tt.wantHandle {
+				t.Errorf("handler invoked = %v, want %v", got, tt.wantHandle)
+			}
+		})
+	}
+}
+
+func TestDecodeOrderResultFindsNestedUnknownFields(t *testing.T) {
+	order := testOrder()
+	order.Items[0].Cost.ProtoReflect().SetUnknown(withFutureField(nil))
+
+	_, err := DecodeOrderResult(marshalOrder(t, order), DecodeStrict)
+	if !errors.Is(err, ErrUnknownFields) {
+		t.Fatalf("DecodeOrderResult() = %v, want ErrUnknownFields", err)
+	}
+}
+
+func TestSubscriberContinuesProducerTrace(t *testing.T) {
+	recorder := newTestTracing(t)
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+
+	// Publish through the real producer adapter to capture its headers
+	var produced *sarama.ProducerMessage
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectMessage(func(msg *sarama.ProducerMessage) error {
+		produced = msg
+		return nil
+	})
+	synthetic, _ := baggage.NewMember(BaggageSyntheticRequest, "true")
+	bag, _ := baggage.New(synthetic)
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	if err := NewKafkaOrderEventPublisher(producer, discardLogger()).PublishOrderCompleted(ctx, testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	msg := &sarama.ConsumerMessage{Topic: produced.Topic, Value: marshalOrder(t, testOrder())}
+	for _, h := range produced.Headers {
+		msg.Headers = append(msg.Headers, &h)
+	}
+	handler := &recordingHandler{}
+	if err := NewKafkaOrderEventSubscriber(handler, DecodeLenient, discardLogger()).HandleMessage(context.Background(), msg); err != nil {
+		t.Fatalf("HandleMessage() = %v", err)
+	}
+
+	publish := endedSpan(t, recorder, "orders publish")
+	deliver := endedSpan(t, recorder, "orders deliver")
+	if deliver.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("deliver span kind = %v, want %v", deliver.SpanKind(), trace.SpanKindConsumer)
+	}
+	if deliver.Parent().SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("deliver span parent = %v, want publish span %v", deliver.Parent().SpanID(), publish.SpanContext().SpanID())
+	}
+	if len(deliver.Links()) != 1 || deliver.Links()[0].SpanContext.SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("deliver span links = %v, want link to publish span", deliver.Links())
+	}
+	if got := trace.SpanContextFromContext(handler.ctx).SpanID(); got != deliver.SpanContext().SpanID() {
+		t.Errorf("handler span = %v, want deliver span %v", got, deliver.SpanContext().SpanID())
+	}
+	if got := baggage.FromContext(handler.ctx).Member(BaggageSyntheticRequest).Value(); got != "true" {
+		t.Errorf("handler baggage %s = %q, want \"true\"", BaggageSyntheticRequest, got)
+	}
+}
+
+func TestSubscriberWithoutTraceContext(t *testing.T) {
+	recorder := newTestTracing(t)
+	sub := NewKafkaOrderEventSubscriber(&recordingHandler{}, DecodeLenient, discardLogger())
+	if err := sub.HandleMessage(context.Background(), &sarama.ConsumerMessage{Topic: "orders", Value: marshalOrder(t, testOrder())}); err != nil {
+		t.Fatalf("HandleMessage() = %v", err)
+	}
+	deliver := endedSpan(t, recorder, "orders deliver")
+	if deliver.Parent().IsValid() || len(deliver.Links()) != 0 {
+		t.Errorf("deliver span parent = %v, links = %v; want a root span without links", deliver.Parent(), deliver.Links())
+	}
+}
+
+// panickingHandler is a recordingHandler with a bug that panics on the
+// first order.
+type panickingHandler struct {
+	recordingHandler
+	panicked bool
+}
+
+func (p *panickingHandler) HandleOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if !p.panicked {
+		p.panicked = true
+		panic("assignment to entry in nil map")
+	}
+	return p.recordingHandler.HandleOrderCompleted(ctx, order)
+}
+
+// recordingDeadLetterQueue records every message dead-lettered to it.
+type recordingDeadLetterQueue struct {
+	msgs []*sarama.ConsumerMessage
+	errs []error
+}
+
+func (q *recordingDeadLetterQueue) DeadLetter(_ context.Context, msg *sarama.ConsumerMessage, err error) error {
+	q.msgs = append(q.msgs, msg)
+	q.errs = append(q.errs, err)
+	return nil
+}
+
+// claimSession is a consumer group session and claim serving a fixed list of
+// messages and recording the ones marked.
+type claimSession struct {
+	sarama.ConsumerGroupSession
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+	marked   []*sarama.ConsumerMessage
+}
+
+func newClaimSession(msgs ...*sarama.ConsumerMessage) *claimSession {
+	c := &claimSession{messages: make(chan *sarama.ConsumerMessage, len(msgs))}
+	for _, msg := range msgs {
+		c.messages <- msg
+	}
+	close(c.messages)
+	return c
+}
+
+func (c *claimSession) Context() context.Context                 { return context.Background() }
+func (c *claimSession) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+func (c *claimSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	c.marked = append(c.marked, msg)
+}
+
+func TestSubscriberDeadLettersMalformedMessages(t *testing.T) {
+	valid := marshalOrder(t, testOrder())
+	incomplete := testOrder()
+	incomplete.ShippingAddress = nil
+
+	tests := []struct {
+		name        string
+		msg         *sarama.ConsumerMessage
+		mode        DecodeMode
+		panics      bool
+		wantCode    errcode.Code
+		wantErr     error
+		wantHandled bool
+	}{
+		{
+			name:     "truncated protobuf",
+			msg:      &sarama.ConsumerMessage{Value: valid[:len(valid)-3]},
+			wantCode: errcode.DecodeFailed,
+		},
+		{
+			name: "wrong content type",
+			msg: &sarama.ConsumerMessage{
+				Value:   []byte(`{"orderId": "order-1"}`),
+				Headers: []*sarama.RecordHeader{{Key: []byte("Content-Type"), Value: []byte("application/json")}},
+			},
+			wantCode: errcode.DecodeFailed,
+			wantErr:  ErrUnsupportedContentType,
+		},
+		{
+			name:     "missing required fields",
+			msg:      &sarama.ConsumerMessage{Value: marshalOrder(t, incomplete)},
+			mode:     DecodeStrict,
+			wantCode: errcode.DecodeFailed,
+		},
+		{
+			name:     "panicking handler",
+			msg:      &sarama.ConsumerMessage{Value: valid},
+			panics:   true,
+			wantCode: errcode.HandlerFailed,
+		},
+		{
+			name: "protobuf content type",
+			msg: &sarama.ConsumerMessage{
+				Value:   valid,
+				Headers: []*sarama.RecordHeader{{Key: []byte(ContentTypeHeader), Value: []byte("application/x-protobuf")}},
+			},
+			wantHandled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &panickingHandler{panicked: !tt.panics}
+			dlq := &recordingDeadLetterQueue{}
+			sub := NewKafkaOrderEventSubscriber(handler, tt.mode, discardLogger(), WithDeadLetterQueue(dlq))
+			// A valid message follows to show the claim goes on
+			next := &sarama.ConsumerMessage{Offset: 1, Value: valid}
+			session := newClaimSession(tt.msg, next)
+
+			if err := sub.ConsumeClaim(session, session); err != nil {
+				t.Fatalf("ConsumeClaim() = %v", err)
+			}
+			if len(session.marked) != 2 {
+				t.Errorf("marked %d messages, want 2", len(session.marked))
+			}
+			if tt.wantCode == "" {
+				if len(dlq.msgs) != 0 {
+					t.Fatalf("dead-lettered %v, want none", dlq.errs)
+				}
+			} else {
+				if len(dlq.msgs) != 1 || dlq.msgs[0] != tt.msg {
+					t.Fatalf("dead-lettered %d messages, want the malformed one", len(dlq.msgs))
+				}
+				if got := errcode.Of(dlq.errs[0]); got != tt.wantCode {
+					t.Errorf("dead-letter error code = %s, want %s (%v)", got, tt.wantCode, dlq.errs[0])
+				}
+				if tt.wantErr != nil && !errors.Is(dlq.errs[0], tt.wantErr) {
+					t.Errorf("dead-letter error = %v, want %v", dlq.errs[0], tt.wantErr)
+				}
+			}
+			wantOrders := 1
+			if tt.wantHandled {
+				wantOrders = 2
+			}
+			if len(handler.orders) != wantOrders {
+				t.Errorf("handler invoked %d times, want %d", len(handler.orders), wantOrders)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// closeIfLifecycle closes v if it implements the Lifecycle port, which lets
+// decorators forward Close to whatever they wrap.
+func closeIfLifecycle(ctx context.Context, v any) error {
+	if l, ok := v.(ports.Lifecycle); ok {
+		return l.Close(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// LoggingOrderCompensator implements the OrderCompensator port by recording
+// refunds and failed orders as structured log records. The payment service
+// has no refund RPC, so the records are what operators act on: each "refund
+// required" record is a charge to refund by hand. Downstream systems learn of
+// a failed order from the OrderFailed event PlaceOrder emits through the order
+// event outbox.
+type LoggingOrderCompensator struct {
+	logger *slog.Logger
+}
+
+// Compile-time check that LoggingOrderCompensator implements OrderCompensator
+var _ ports.OrderCompensator = (*LoggingOrderCompensator)(nil)
+
+// NewLoggingOrderCompensator creates a compensator that logs to logger.
+func NewLoggingOrderCompensator(logger *slog.Logger) *LoggingOrderCompensator {
+	return &LoggingOrderCompensator{logger: logger}
+}
+
+// RefundPayment logs the charge to refund at error level.
+func (c *LoggingOrderCompensator) RefundPayment(ctx context.Context, order ports.FailedOrder) error {
+	tender := order.Tender
+	if tender == "" {
+		tender = ports.CardTender
+	}
+	c.logger.ErrorContext(ctx, "refund required",
+		slog.String("order_id", order.OrderID),
+		slog.String("user_id", order.UserID),
+		slog.String("tender", string(tender)),
+		slog.String("transaction_id", order.TransactionID),
+		slog.String("currency_code", order.Amount.GetCurrencyCode()),
+		slog.Int64("units", order.Amount.GetUnits()),
+		slog.Int("nanos", int(order.Amount.GetNanos())),
+		slog.String("failed_step", order.Step),
+	)
+	return nil
+}
+
+// PublishOrderFailed logs the failed order for operators, with the products
+// out of stock when it could not be reserved.
+func (c *LoggingOrderCompensator) PublishOrderFailed(ctx context.Context, order ports.FailedOrder) error {
+	attrs := []any{
+		slog.String("order_id", order.OrderID),
+		slog.String("user_id", order.UserID),
+		slog.String("failed_step", order.Step),
+		slog.String("reason", order.Reason),
+		slog.Bool("compensated", order.Compensated),
+	}
+	if len(order.OutOfStock) > 0 {
+		attrs = append(attrs, slog.Any("out_of_stock", order.OutOfStock))
+	}
+	c.logger.WarnContext(ctx, "order failed", attrs...)
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestLoggingOrderCompensator(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewLoggingOrderCompensator(slog.New(slog.NewTextHandler(&buf, nil)))
+	order := ports.FailedOrder{
+		OrderID:       "order-1",
+		UserID:        "user-1",
+		TransactionID: "tx-1",
+		Amount:        &pb.Money{CurrencyCode: "USD", Units: 47, Nanos: 980000000},
+		Step:          "ship",
+		Reason:        "shipping unavailable",
+		Compensated:   true,
+	}
+
+	if err := c.RefundPayment(context.Background(), order); err != nil {
+		t.Fatalf("RefundPayment() = %v", err)
+	}
+	if err := c.PublishOrderFailed(context.Background(), order); err != nil {
+		t.Fatalf("PublishOrderFailed() = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d records, want 2", len(lines))
+	}
+	for _, want := range []string{"level=ERROR", `msg="refund required"`, "tender=card", "transaction_id=tx-1", "currency_code=USD units=47 nanos=980000000"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("refund record %q does not contain %q", lines[0], want)
+		}
+	}
+	for _, want := range []string{"level=WARN", `msg="order failed"`, "failed_step=ship", "compensated=true"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("order failed record %q does not contain %q", lines[1], want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// InMemoryGiftCardPaymentService decorates a PaymentService with gift cards
+// whose balances are kept in process memory, since the demo has no gift card
+// service. Other tenders are charged by the decorated service.
+type InMemoryGiftCardPaymentService struct {
+	next     ports.PaymentService
+	mu       sync.Mutex
+	balances map[string]*pb.Money
+}
+
+// Compile-time check that InMemoryGiftCardPaymentService implements PaymentService
+var _ ports.PaymentService = (*InMemoryGiftCardPaymentService)(nil)
+
+// NewInMemoryGiftCardPaymentService creates a payment service accepting the
+// gift cards of balances, by code, in front of next.
+func NewInMemoryGiftCardPaymentService(balances map[string]*pb.Money, next ports.PaymentService) *InMemoryGiftCardPaymentService {
+	s := &InMemoryGiftCardPaymentService{next: next, balances: make(map[string]*pb.Money, len(balances))}
+	for code, balance := range balances {
+		s.balances[code] = balance
+	}
+	return s
+}
+
+// Balance returns the balance of the gift card with code, and false for
+// unknown gift cards.
+func (s *InMemoryGiftCardPaymentService) Balance(code string) (*pb.Money, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	balance, ok := s.balances[code]
+	return balance, ok
+}
+
+// Charge takes at most amount from the balance of a gift card tender, which
+// must be in the currency of amount, and passes other tenders on.
+func (s *InMemoryGiftCardPaymentService) Charge(ctx context.Context, amount *pb.Money, tender ports.Tender) (ports.Payment, error) {
+	if tender.Type != ports.GiftCardTender {
+		return s.next.Charge(ctx, amount, tender)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	balance, ok := s.balances[tender.GiftCardCode]
+	if !ok {
+		return ports.Payment{}, fmt.Errorf("unknown gift card %q", tender.GiftCardCode)
+	}
+	if !money.AreSameCurrency(balance, amount) {
+		return ports.Payment{}, fmt.Errorf("gift card %q is in %s, not %s", tender.GiftCardCode, balance.GetCurrencyCode(), amount.GetCurrencyCode())
+	}
+	taken := amount
+	rest, err := money.Sum(balance, money.Negate(amount))
+	if err != nil {
+		return ports.Payment{}, err
+	}
+	if money.IsNegative(rest) {
+		taken, rest = balance, &pb.Money{CurrencyCode: balance.GetCurrencyCode()}
+	}
+	s.balances[tender.GiftCardCode] = rest
+	return ports.Payment{Type: ports.GiftCardTender, Amount: taken, TransactionID: "gift-" + uuid.NewString()}, nil
+}
@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestInMemoryGiftCardPaymentService(t *testing.T) {
+	ctx := context.Background()
+	client := &recordingPaymentClient{}
+	s := NewInMemoryGiftCardPaymentService(map[string]*pb.Money{
+		"GIFT-1": {CurrencyCode: "USD", Units: 10},
+	}, NewGRPCPaymentService(client))
+	gift := ports.Tender{Type: ports.GiftCardTender, GiftCardCode: "GIFT-1"}
+
+	// The first charge fits in the balance, the second empties it
+	payment, err := s.Charge(ctx, &pb.Money{CurrencyCode: "USD", Units: 4, Nanos: 500_000_000}, gift)
+	if err != nil {
+		t.Fatalf("Charge(4.50) = %v", err)
+	}
+	if payment.Type != ports.GiftCardTender || payment.TransactionID == "" || payment.Amount.GetUnits() != 4 {
+		t.Errorf("Charge(4.50) = %+v, want 4.50 taken from the gift card", payment)
+	}
+	payment, err = s.Charge(ctx, &pb.Money{CurrencyCode: "USD", Units: 20}, gift)
+	if err != nil {
+		t.Fatalf("Charge(20) = %v", err)
+	}
+	want := &pb.Money{CurrencyCode: "USD", Units: 5, Nanos: 500_000_000}
+	if !proto.Equal(payment.Amount, want) {
+		t.Errorf("Charge(20) took %v, want the remaining balance %v", payment.Amount, want)
+	}
+	if balance, _ := s.Balance("GIFT-1"); balance.GetUnits() != 0 || balance.GetNanos() != 0 {
+		t.Errorf("balance = %v, want 0", balance)
+	}
+
+	if _, err := s.Charge(ctx, &pb.Money{CurrencyCode: "EUR", Units: 1}, gift); err == nil {
+		t.Error("Charge() in another currency succeeded, want an error")
+	}
+	if _, err := s.Charge(ctx, &pb.Money{CurrencyCode: "USD", Units: 1}, ports.Tender{Type: ports.GiftCardTender, GiftCardCode: "NOPE"}); err == nil {
+		t.Error("Charge() of an unknown gift card succeeded, want an error")
+	}
+
+	if _, err := s.Charge(ctx, &pb.Money{CurrencyCode: "USD", Units: 1}, ports.Tender{Type: ports.CardTender}); err != nil {
+		t.Fatalf("Charge() of a card = %v", err)
+	}
+	if len(client.charges) != 1 {
+		t.Errorf("passed %d charges on, want the card charge", len(client.charges))
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// InMemoryIdempotencyStore implements the IdempotencyStore port in process
+// memory. Outcomes are forgotten after a TTL and are not shared between
+// replicas, so retries must reach the same instance to be deduplicated.
+//
+// An expired key is dropped when it is looked up, and the keys nobody looks
+// up again are swept at most once per TTL, so a reservation does not scan
+// every entry.
+type InMemoryIdempotencyStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]idempotencyEntry
+	nextSweep time.Time
+}
+
+// idempotencyEntry is a reserved key. order is nil until the request completes.
+type idempotencyEntry struct {
+	order   *pb.OrderResult
+	expires time.Time
+}
+
+// Compile-time check that InMemoryIdempotencyStore implements IdempotencyStore
+var _ ports.IdempotencyStore = (*InMemoryIdempotencyStore)(nil)
+
+// NewInMemoryIdempotencyStore creates a store that remembers each key for ttl.
+// A reservation whose request never completes also expires after ttl.
+func NewInMemoryIdempotencyStore(ttl time.Duration) *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]idempotencyEntry),
+	}
+}
+
+// Reserve claims key, or returns the order recorded for it.
+func (s *InMemoryIdempotencyStore) Reserve(ctx context.Context, key string) (*pb.OrderResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if !now.Before(s.nextSweep) {
+		s.expire(now)
+		s.nextSweep = now.Add(s.ttl)
+	}
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		if entry.order == nil {
+			return nil, ports.ErrRequestInProgress
+		}
+		return proto.Clone(entry.order).(*pb.OrderResult), nil
+	}
+	s.entries[key] = idempotencyEntry{expires: now.Add(s.ttl)}
+	return nil, nil
+}
+
+// Complete records order for key.
+func (s *InMemoryIdempotencyStore) Complete(ctx context.Context, key string, order *pb.OrderResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = idempotencyEntry{
+		order:   proto.Clone(order).(*pb.OrderResult),
+		expires: s.now().Add(s.ttl),
+	}
+	return nil
+}
+
+// Release forgets key.
+func (s *InMemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// expire drops the entries that expired before now. It must be called with
+// s.mu held.
+func (s *InMemoryIdempotencyStore) expire(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestInMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	clock := testdata.NewClock()
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	store.now = clock.Now
+
+	if order, err := store.Reserve(ctx, "user-1/req-1"); order != nil || err != nil {
+		t.Fatalf("Reserve() of a new key = %v, %v; want nil, nil", order, err)
+	}
+	if _, err := store.Reserve(ctx, "user-1/req-1"); !errors.Is(err, ports.ErrRequestInProgress) {
+		t.Fatalf("Reserve() of a key in progress = %v, want %v", err, ports.ErrRequestInProgress)
+	}
+
+	order := testOrder()
+	if err := store.Complete(ctx, "user-1/req-1", order); err != nil {
+		t.Fatalf("Complete() = %v", err)
+	}
+	got, err := store.Reserve(ctx, "user-1/req-1")
+	if err != nil || !proto.Equal(got, order) {
+		t.Fatalf("Reserve() of a completed key = %v, %v; want the recorded order", got, err)
+	}
+	got.OrderId = "changed"
+	if again, _ := store.Reserve(ctx, "user-1/req-1"); again.OrderId != order.OrderId {
+		t.Error("Reserve() returned the stored order instead of a copy")
+	}
+
+	clock.Advance(time.Hour)
+	if order, err := store.Reserve(ctx, "user-1/req-1"); order != nil || err != nil {
+		t.Errorf("Reserve() of an expired key = %v, %v; want nil, nil", order, err)
+	}
+}
+
+func TestInMemoryIdempotencyStoreRelease(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryIdempotencyStore(time.Hour)
+
+	store.Reserve(ctx, "user-1/req-1")
+	if err := store.Release(ctx, "user-1/req-1"); err != nil {
+		t.Fatalf("Release() = %v", err)
+	}
+	if order, err := store.Reserve(ctx, "user-1/req-1"); order != nil || err != nil {
+		t.Errorf("Reserve() after Release() = %v, %v; want nil, nil", order, err)
+	}
+}
+
+func TestInMemoryIdempotencyStoreSweepsExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := testdata.NewClock()
+	store := NewInMemoryIdempotencyStore(time.Hour)
+	store.now = clock.Now
+
+	store.Reserve(ctx, "user-1/req-1")
+	clock.Advance(30 * time.Minute)
+	store.Reserve(ctx, "user-1/req-2")
+	if len(store.entries) != 2 {
+		t.Fatalf("store holds %d keys, want 2 before the first sweep is due", len(store.entries))
+	}
+
+	// req-1 expired and is swept, req-2 is kept until it expires
+	clock.Advance(45 * time.Minute)
+	store.Reserve(ctx, "user-1/req-3")
+	if _, ok := store.entries["user-1/req-1"]; ok || len(store.entries) != 2 {
+		t.Errorf("store holds %d keys after the sweep, want req-1 dropped", len(store.entries))
+	}
+}
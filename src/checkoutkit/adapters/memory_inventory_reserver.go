@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"sync"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// InMemoryInventoryReserver implements the InventoryReserver port with stock
+// levels kept in process memory. Products without a stock level are never out
+// of stock, since the demo has no inventory service to ask.
+type InMemoryInventoryReserver struct {
+	mu           sync.Mutex
+	stock        map[string]int64
+	reservations map[string]map[string]int64
+}
+
+// Compile-time check that InMemoryInventoryReserver implements InventoryReserver
+var _ ports.InventoryReserver = (*InMemoryInventoryReserver)(nil)
+
+// NewInMemoryInventoryReserver creates a reserver with stock levels by
+// product ID.
+func NewInMemoryInventoryReserver(stock map[string]int64) *InMemoryInventoryReserver {
+	r := &InMemoryInventoryReserver{
+		stock:        make(map[string]int64, len(stock)),
+		reservations: make(map[string]map[string]int64),
+	}
+	for productID, quantity := range stock {
+		r.stock[productID] = quantity
+	}
+	return r
+}
+
+// SetStock sets the stock level of productID.
+func (r *InMemoryInventoryReserver) SetStock(productID string, quantity int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stock[productID] = quantity
+}
+
+// Stock returns the stock level of productID, and false for products without
+// one.
+func (r *InMemoryInventoryReserver) Stock(productID string) (int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	quantity, ok := r.stock[productID]
+	return quantity, ok
+}
+
+// Reserve takes the quantities of items off the stock, or reports the
+// products short of them and takes nothing.
+func (r *InMemoryInventoryReserver) Reserve(ctx context.Context, orderID string, items []*pb.CartItem) error {
+	wanted := make(map[string]int64)
+	var order []string
+	for _, item := range items {
+		if _, ok := wanted[item.GetProductId()]; !ok {
+			order = append(order, item.GetProductId())
+		}
+		wanted[item.GetProductId()] += int64(item.GetQuantity())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var short []string
+	for _, productID := range order {
+		if stock, ok := r.stock[productID]; ok && stock < wanted[productID] {
+			short = append(short, productID)
+		}
+	}
+	if len(short) > 0 {
+		return &ports.OutOfStockError{ProductIDs: short}
+	}
+	for productID, quantity := range wanted {
+		if _, ok := r.stock[productID]; ok {
+			r.stock[productID] -= quantity
+		}
+	}
+	r.reservations[orderID] = wanted
+	return nil
+}
+
+// Release puts the stock reserved for orderID back.
+func (r *InMemoryInventoryReserver) Release(ctx context.Context, orderID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for productID, quantity := range r.reservations[orderID] {
+		if _, ok := r.stock[productID]; ok {
+			r.stock[productID] += quantity
+		}
+	}
+	delete(r.reservations, orderID)
+	return nil
+}
+
+// Confirm forgets the reservation of orderID, whose stock stays taken.
+func (r *InMemoryInventoryReserver) Confirm(ctx context.Context, orderID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reservations, orderID)
+	return nil
+}
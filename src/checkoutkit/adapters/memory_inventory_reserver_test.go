@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestInMemoryInventoryReserver(t *testing.T) {
+	ctx := context.Background()
+	r := NewInMemoryInventoryReserver(map[string]int64{"SKU-1": 3, "SKU-2": 1})
+	stock := func(productID string) int64 {
+		t.Helper()
+		quantity, _ := r.Stock(productID)
+		return quantity
+	}
+
+	// The quantities of a product are added up across cart items
+	if err := r.Reserve(ctx, "order-1", []*pb.CartItem{
+		{ProductId: "SKU-1", Quantity: 1}, {ProductId: "SKU-1", Quantity: 1}, {ProductId: "UNTRACKED", Quantity: 100},
+	}); err != nil {
+		t.Fatalf("Reserve(order-1) = %v", err)
+	}
+	if got := stock("SKU-1"); got != 1 {
+		t.Errorf("SKU-1 stock = %d after reserving 2 of 3, want 1", got)
+	}
+
+	err := r.Reserve(ctx, "order-2", []*pb.CartItem{
+		{ProductId: "SKU-1", Quantity: 2}, {ProductId: "SKU-2", Quantity: 1}, {ProductId: "SKU-3", Quantity: 1},
+	})
+	var outOfStock *ports.OutOfStockError
+	if !errors.As(err, &outOfStock) || !reflect.DeepEqual(outOfStock.ProductIDs, []string{"SKU-1"}) {
+		t.Fatalf("Reserve(order-2) = %v, want SKU-1 out of stock", err)
+	}
+	if got := stock("SKU-2"); got != 1 {
+		t.Errorf("SKU-2 stock = %d after a failed reservation, want 1 untouched", got)
+	}
+
+	if err := r.Release(ctx, "order-1"); err != nil {
+		t.Fatalf("Release(order-1) = %v", err)
+	}
+	if got := stock("SKU-1"); got != 3 {
+		t.Errorf("SKU-1 stock = %d after Release, want 3", got)
+	}
+
+	if err := r.Reserve(ctx, "order-3", []*pb.CartItem{{ProductId: "SKU-2", Quantity: 1}}); err != nil {
+		t.Fatalf("Reserve(order-3) = %v", err)
+	}
+	if err := r.Confirm(ctx, "order-3"); err != nil {
+		t.Fatalf("Confirm(order-3) = %v", err)
+	}
+	r.Release(ctx, "order-3")
+	if got := stock("SKU-2"); got != 0 {
+		t.Errorf("SKU-2 stock = %d after a confirmed sale, want 0", got)
+	}
+}
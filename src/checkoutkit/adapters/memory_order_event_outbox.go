@@ -0,0 +1,314 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// defaultRelayRetryInterval is how long the relay waits before publishing a
+// failed batch again.
+const defaultRelayRetryInterval = 5 * time.Second
+
+// InMemoryOrderEventOutbox implements the OrderEventOutbox port in process
+// memory. A relay goroutine publishes the committed batches through an
+// OrderEventBatchPublisher in commit order. The number of batches waiting is
+// reported on the messaging.publish.backlog gauge. A batch that fails to publish
+// stays at the head of the outbox and is retried, unless it can never be
+// published, such as one that fails serialization. Batches still in the
+// outbox when the process exits are lost. While paused, the outbox keeps its
+// batches.
+type InMemoryOrderEventOutbox struct {
+	publisher     ports.OrderEventBatchPublisher
+	logger        *slog.Logger
+	retryInterval time.Duration
+
+	mu      sync.Mutex
+	batches []outboxBatch
+	closed  bool
+	paused  bool
+	// retrying is set while the relay waits to publish a failed batch again
+	retrying bool
+
+	// wake is signalled when a batch is committed, the outbox is closed or
+	// resumed
+	wake chan struct{}
+	// retry is signalled by Replay to cut the wait after a failed batch short
+	retry chan struct{}
+	// stop ends the relay when Close gives up on the remaining batches
+	stop     chan struct{}
+	stopOnce sync.Once
+	// relayed is closed when the relay has returned
+	relayed chan struct{}
+
+	backlog metric.Registration
+}
+
+// outboxBatch is a committed unit of work. ctx is the committing context
+// without its cancellation, so that the publish joins the order's trace.
+type outboxBatch struct {
+	ctx     context.Context
+	orderID string
+	events  []ports.OrderEvent
+}
+
+// Compile-time check that InMemoryOrderEventOutbox implements OrderEventOutbox
+var _ ports.OrderEventOutbox = (*InMemoryOrderEventOutbox)(nil)
+
+// Compile-time check that InMemoryOrderEventOutbox implements Lifecycle
+var _ ports.Lifecycle = (*InMemoryOrderEventOutbox)(nil)
+
+// OutboxOption configures optional behavior of an InMemoryOrderEventOutbox.
+type OutboxOption func(*InMemoryOrderEventOutbox)
+
+// WithRelayRetryInterval sets how long the relay waits before publishing a
+// failed batch again. The default is 5s.
+func WithRelayRetryInterval(interval time.Duration) OutboxOption {
+	return func(o *InMemoryOrderEventOutbox) {
+		o.retryInterval = interval
+	}
+}
+
+// NewInMemoryOrderEventOutbox creates an outbox and starts relaying its
+// batches to publisher.
+func NewInMemoryOrderEventOutbox(publisher ports.OrderEventBatchPublisher, logger *slog.Logger, opts ...OutboxOption) *InMemoryOrderEventOutbox {
+	o := &InMemoryOrderEventOutbox{
+		publisher:     publisher,
+		logger:        logger,
+		retryInterval: defaultRelayRetryInterval,
+		wake:          make(chan struct{}, 1),
+		retry:         make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		relayed:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.backlog = observeBacklog(BacklogStoreOutbox, func() (int, error) { return o.Pending(), nil }, logger)
+	go o.relay()
+	return o
+}
+
+// Begin starts a unit of work for the order with orderID.
+func (o *InMemoryOrderEventOutbox) Begin(orderID string) ports.UnitOfWork {
+	return &outboxUnitOfWork{outbox: o, orderID: orderID}
+}
+
+// Pending returns the number of committed batches not published yet.
+func (o *InMemoryOrderEventOutbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.batches)
+}
+
+// Pause keeps the committed batches in the outbox until Resume. The batch
+// being published when Pause is called still is.
+func (o *InMemoryOrderEventOutbox) Pause() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.paused = true
+}
+
+// Resume publishes the batches again.
+func (o *InMemoryOrderEventOutbox) Resume() {
+	o.mu.Lock()
+	o.paused = false
+	o.mu.Unlock()
+	o.signal()
+}
+
+// Paused reports whether the outbox is paused.
+func (o *InMemoryOrderEventOutbox) Paused() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.paused
+}
+
+// Replay publishes the batch that failed now, instead of after the retry
+// interval, and returns the number of batches waiting.
+func (o *InMemoryOrderEventOutbox) Replay() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.retrying {
+		select {
+		case o.retry <- struct{}{}:
+		default:
+		}
+	}
+	return len(o.batches)
+}
+
+// Close stops accepting batches and waits until the relay has published the
+// committed ones, even while paused, or ctx is done. Units of work committed
+// after Close fail with PUBLISHER_CLOSED. Close does not close the batch publisher.
+func (o *InMemoryOrderEventOutbox) Close(ctx context.Context) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	o.mu.Unlock()
+	o.signal()
+
+	select {
+	case <-o.relayed:
+		return nil
+	case <-ctx.Done():
+		o.stopOnce.Do(func() { close(o.stop) })
+		return fmt.Errorf("order event outbox closed with %d batches unpublished: %w", o.Pending(), ctx.Err())
+	}
+}
+
+// signal wakes the relay up without blocking.
+func (o *InMemoryOrderEventOutbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// commit appends batch to the outbox.
+func (o *InMemoryOrderEventOutbox) commit(batch outboxBatch) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return errcode.Errorf(errcode.PublisherClosed, "order event outbox is closed")
+	}
+	o.batches = append(o.batches, batch)
+	o.mu.Unlock()
+	o.signal()
+	return nil
+}
+
+// relay publishes the batches in commit order until the outbox is closed and
+// empty, or stopped.
+func (o *InMemoryOrderEventOutbox) relay() {
+	defer close(o.relayed)
+	if o.backlog != nil {
+		defer o.backlog.Unregister()
+	}
+	for {
+		o.mu.Lock()
+		if len(o.batches) == 0 || (o.paused && !o.closed) {
+			closed := o.closed && len(o.batches) == 0
+			o.mu.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case <-o.wake:
+			case <-o.stop:
+				return
+			}
+			continue
+		}
+		batch := o.batches[0]
+		o.mu.Unlock()
+
+		err := o.publisher.PublishOrderEvents(batch.ctx, batch.events)
+		if err != nil && !permanentPublishError(err) {
+			o.logger.WarnContext(batch.ctx, "Failed to publish order events, retrying",
+				slog.String("order_id", batch.orderID),
+				slog.Duration("retry_in", o.retryInterval),
+				slog.String("error", err.Error()),
+				errcode.Attr(err),
+			)
+			o.setRetrying(true)
+			select {
+			case <-time.After(o.retryInterval):
+			case <-o.retry:
+			case <-o.stop:
+				return
+			}
+			o.setRetrying(false)
+			continue
+		}
+		if err != nil {
+			o.logger.ErrorContext(batch.ctx, "Dropping order events that cannot be published",
+				slog.String("order_id", batch.orderID),
+				slog.String("error", err.Error()),
+				errcode.Attr(err),
+			)
+		}
+
+		o.mu.Lock()
+		o.batches = o.batches[1:]
+		o.mu.Unlock()
+	}
+}
+
+func (o *InMemoryOrderEventOutbox) setRetrying(retrying bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retrying = retrying
+}
+
+// permanentPublishError reports whether publishing the same batch again
+// cannot succeed, so that it does not hold up the batches behind it.
+func permanentPublishError(err error) bool {
+	switch errcode.Of(err) {
+	case errcode.SerializationFailed, errcode.ValidationFailed:
+		return true
+	}
+	return false
+}
+
+// outboxUnitOfWork records the events of one order for an
+// InMemoryOrderEventOutbox.
+type outboxUnitOfWork struct {
+	outbox    *InMemoryOrderEventOutbox
+	orderID   string
+	events    []ports.OrderEvent
+	committed bool
+}
+
+func (u *outboxUnitOfWork) Record(event ports.OrderEvent) {
+	u.events = append(u.events, event)
+}
+
+// Commit validates the OrderResult of the unit of work, so that a batch is
+// rejected as a whole rather than published without it, and appends the
+// events to the outbox.
+func (u *outboxUnitOfWork) Commit(ctx context.Context) error {
+	if u.committed {
+		return errors.New("unit of work already committed")
+	}
+	u.committed = true
+	if len(u.events) == 0 {
+		return nil
+	}
+	if err := validateOrderEvents(u.events); err != nil {
+		return err
+	}
+	return u.outbox.commit(outboxBatch{
+		ctx:     context.WithoutCancel(ctx),
+		orderID: u.orderID,
+		events:  u.events,
+	})
+}
+
+// validateOrderEvents validates the OrderResult of a batch, so that the batch
+// is rejected as a whole rather than published without it.
+func validateOrderEvents(events []ports.OrderEvent) error {
+	for _, event := range events {
+		if event.Type != ports.OrderCompletedEvent {
+			continue
+		}
+		if err := validation.ValidateOrderResult(event.Order); err != nil {
+			return errcode.Wrap(errcode.ValidationFailed, err)
+		}
+	}
+	return nil
+}
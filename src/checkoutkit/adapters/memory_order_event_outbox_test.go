@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// recordingBatchPublisher records published batches and fails the first
+// failures publishes.
+type recordingBatchPublisher struct {
+	mu       sync.Mutex
+	batches  [][]ports.OrderEvent
+	failures int
+}
+
+func (r *recordingBatchPublisher) PublishOrderEvents(ctx context.Context, events []ports.OrderEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		return errcode.Errorf(errcode.KafkaTransactionAborted, "broker unavailable")
+	}
+	r.batches = append(r.batches, events)
+	return nil
+}
+
+func (r *recordingBatchPublisher) published() [][]ports.OrderEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batches
+}
+
+// testOrderEvents returns the events of a completed order.
+func testOrderEvents(orderID string) []ports.OrderEvent {
+	order := testOrder()
+	order.OrderId = orderID
+	return []ports.OrderEvent{
+		{Type: ports.OrderPlacedEvent, Order: &pb.OrderResult{OrderId: orderID}},
+		{Type: ports.PaymentCapturedEvent, Order: &pb.OrderResult{OrderId: orderID}, Attributes: map[string]string{"transaction_id": "tx-1"}},
+		{Type: ports.OrderCompletedEvent, Order: order},
+	}
+}
+
+func commitEvents(t *testing.T, outbox *InMemoryOrderEventOutbox, orderID string) {
+	t.Helper()
+	uow := outbox.Begin(orderID)
+	for _, event := range testOrderEvents(orderID) {
+		uow.Record(event)
+	}
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("Commit(%s) = %v", orderID, err)
+	}
+}
+
+func TestInMemoryOrderEventOutboxPublishesBatchesInOrder(t *testing.T) {
+	publisher := &recordingBatchPublisher{failures: 1}
+	outbox := NewInMemoryOrderEventOutbox(publisher, discardLogger(), WithRelayRetryInterval(time.Millisecond))
+
+	commitEvents(t, outbox, "order-1")
+	commitEvents(t, outbox, "order-2")
+	// Never committed, so none of its events is published
+	outbox.Begin("order-3").Record(testOrderEvents("order-3")[0])
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+
+	batches := publisher.published()
+	if len(batches) != 2 {
+		t.Fatalf("published %d batches, want 2", len(batches))
+	}
+	for i, want := range []string{"order-1", "order-2"} {
+		if got := batches[i]; len(got) != 3 || got[2].Order.GetOrderId() != want {
+			t.Errorf("batch %d = %v, want the 3 events of %s", i, got, want)
+		}
+	}
+	if outbox.Pending() != 0 {
+		t.Errorf("Pending() = %d after Close, want 0", outbox.Pending())
+	}
+}
+
+func TestInMemoryOrderEventOutboxRejectsInvalidBatches(t *testing.T) {
+	publisher := &recordingBatchPublisher{}
+	outbox := NewInMemoryOrderEventOutbox(publisher, discardLogger())
+	defer outbox.Close(context.Background())
+
+	uow := outbox.Begin("order-1")
+	uow.Record(ports.OrderEvent{Type: ports.OrderPlacedEvent, Order: &pb.OrderResult{OrderId: "order-1"}})
+	uow.Record(ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: &pb.OrderResult{OrderId: "order-1"}})
+	if err := uow.Commit(context.Background()); errcode.Of(err) != errcode.ValidationFailed {
+		t.Errorf("Commit() = %v, want %s", err, errcode.ValidationFailed)
+	}
+	if err := uow.Commit(context.Background()); err == nil {
+		t.Error("second Commit() = nil, want an error")
+	}
+	if outbox.Pending() != 0 {
+		t.Errorf("Pending() = %d, want the invalid batch discarded", outbox.Pending())
+	}
+}
+
+func TestInMemoryOrderEventOutboxClose(t *testing.T) {
+	publisher := &recordingBatchPublisher{failures: 1 << 30}
+	outbox := NewInMemoryOrderEventOutbox(publisher, discardLogger(), WithRelayRetryInterval(time.Millisecond))
+	commitEvents(t, outbox, "order-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := outbox.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() with an unpublishable batch = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := outbox.Begin("order-2").Commit(context.Background()); err != nil {
+		t.Errorf("Commit() of an empty unit of work = %v, want nil", err)
+	}
+	uow := outbox.Begin("order-2")
+	uow.Record(testOrderEvents("order-2")[2])
+	if err := uow.Commit(context.Background()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("Commit() after Close = %v, want %s", err, errcode.PublisherClosed)
+	}
+}
+
+func TestInMemoryOrderEventOutboxPause(t *testing.T) {
+	publisher := &recordingBatchPublisher{}
+	outbox := NewInMemoryOrderEventOutbox(publisher, discardLogger())
+	outbox.Pause()
+	commitEvents(t, outbox, "order-1")
+
+	time.Sleep(20 * time.Millisecond)
+	if got := publisher.published(); len(got) != 0 || !outbox.Paused() {
+		t.Fatalf("published %d batches while paused, want 0", len(got))
+	}
+
+	outbox.Resume()
+	deadline := time.Now().Add(time.Second)
+	for len(publisher.published()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := publisher.published(); len(got) != 1 {
+		t.Errorf("published %d batches after Resume, want 1", len(got))
+	}
+
+	// Close publishes the committed batches even while paused
+	outbox.Pause()
+	commitEvents(t, outbox, "order-2")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := outbox.Close(ctx); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if got := publisher.published(); len(got) != 2 {
+		t.Errorf("published %d batches after Close, want 2", len(got))
+	}
+}
+
+func TestInMemoryOrderEventOutboxReplay(t *testing.T) {
+	publisher := &recordingBatchPublisher{failures: 1}
+	outbox := NewInMemoryOrderEventOutbox(publisher, discardLogger(), WithRelayRetryInterval(time.Hour))
+	defer outbox.Close(context.Background())
+	commitEvents(t, outbox, "order-1")
+
+	// The failed batch is published again on Replay rather than in an hour
+	deadline := time.Now().Add(time.Second)
+	for len(publisher.published()) == 0 && time.Now().Before(deadline) {
+		if pending := outbox.Replay(); pending != 1 {
+			t.Fatalf("Replay() = %d, want 1 batch waiting", pending)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := publisher.published(); len(got) != 1 {
+		t.Errorf("published %d batches after Replay, want 1", len(got))
+	}
+}
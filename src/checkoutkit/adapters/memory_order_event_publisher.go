@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// PublishedOrderEvent is an order event captured by an
+// InMemoryOrderEventPublisher, with the message headers it was published
+// with.
+type PublishedOrderEvent struct {
+	Order   *pb.OrderResult
+	Headers map[string]string
+}
+
+// InMemoryOrderEventPublisher implements the OrderEventPublisher port in
+// process memory, for tests and local development. It captures a copy of
+// every order published with its message headers, hands it to the channels
+// subscribed to it, and fails publishes with the errors injected with
+// FailNext or FailAll. Failed publishes are not captured.
+type InMemoryOrderEventPublisher struct {
+	capacity int
+
+	mu          sync.Mutex
+	events      []PublishedOrderEvent
+	subscribers map[int]chan<- *pb.OrderResult
+	nextID      int
+	failures    []error
+	failAll     error
+}
+
+// Compile-time check that InMemoryOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*InMemoryOrderEventPublisher)(nil)
+
+// InMemoryPublisherOption configures optional behavior of an
+// InMemoryOrderEventPublisher.
+type InMemoryPublisherOption func(*InMemoryOrderEventPublisher)
+
+// WithInMemoryCapacity keeps only the last capacity events, so that a
+// long-running process does not grow without bound. By default every event is
+// kept.
+func WithInMemoryCapacity(capacity int) InMemoryPublisherOption {
+	return func(m *InMemoryOrderEventPublisher) {
+		m.capacity = capacity
+	}
+}
+
+// NewInMemoryOrderEventPublisher creates a publisher without events.
+func NewInMemoryOrderEventPublisher(opts ...InMemoryPublisherOption) *InMemoryOrderEventPublisher {
+	m := &InMemoryOrderEventPublisher{subscribers: make(map[int]chan<- *pb.OrderResult)}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// PublishOrderCompleted captures the order and sends it to every subscriber,
+// or returns the next injected failure. A send waits for the subscriber to
+// receive the order, or for ctx to end, in which case the order is captured
+// but the publish fails with the context's error.
+func (m *InMemoryOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	m.mu.Lock()
+	if len(m.failures) > 0 {
+		err := m.failures[0]
+		m.failures = m.failures[1:]
+		m.mu.Unlock()
+		return err
+	}
+	if m.failAll != nil {
+		err := m.failAll
+		m.mu.Unlock()
+		return err
+	}
+	order = proto.Clone(order).(*pb.OrderResult)
+	m.events = append(m.events, PublishedOrderEvent{Order: order, Headers: MessageHeaders(ctx)})
+	if m.capacity > 0 && len(m.events) > m.capacity {
+		m.events = m.events[len(m.events)-m.capacity:]
+	}
+	subscribers := make([]chan<- *pb.OrderResult, 0, len(m.subscribers))
+	for _, ch := range m.subscribers {
+		subscribers = append(subscribers, ch)
+	}
+	m.mu.Unlock()
+
+	for _, ch := range subscribers {
+		select {
+		case ch <- order:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe sends every order published from now on to ch, until the
+// returned function is called. Publishes wait for ch to receive each order,
+// so a subscriber that stops reading must unsubscribe or use a buffered
+// channel. The orders are shared between subscribers and must not be
+// modified.
+func (m *InMemoryOrderEventPublisher) Subscribe(ch chan<- *pb.OrderResult) (unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextID
+	m.nextID++
+	m.subscribers[id] = ch
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subscribers, id)
+	}
+}
+
+// Events returns the events captured, oldest first.
+func (m *InMemoryOrderEventPublisher) Events() []PublishedOrderEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := make([]PublishedOrderEvent, len(m.events))
+	copy(events, m.events)
+	return events
+}
+
+// Orders returns the orders captured, oldest first.
+func (m *InMemoryOrderEventPublisher) Orders() []*pb.OrderResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orders := make([]*pb.OrderResult, len(m.events))
+	for i, event := range m.events {
+		orders[i] = event.Order
+	}
+	return orders
+}
+
+// Last returns the last event captured, and false if there is none.
+func (m *InMemoryOrderEventPublisher) Last() (PublishedOrderEvent, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.events) == 0 {
+		return PublishedOrderEvent{}, false
+	}
+	return m.events[len(m.events)-1], true
+}
+
+// Len returns the number of events captured.
+func (m *InMemoryOrderEventPublisher) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.events)
+}
+
+// FailNext fails the next publishes with errs, one error each, before any
+// error set with FailAll.
+func (m *InMemoryOrderEventPublisher) FailNext(errs ...error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures = append(m.failures, errs...)
+}
+
+// FailAll fails every publish with err, until it is called with nil.
+func (m *InMemoryOrderEventPublisher) FailAll(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failAll = err
+}
+
+// Reset forgets the events captured and the failures injected. Subscribers
+// stay subscribed.
+func (m *InMemoryOrderEventPublisher) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = nil
+	m.failures = nil
+	m.failAll = nil
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+func TestInMemoryOrderEventPublisher(t *testing.T) {
+	pub := NewInMemoryOrderEventPublisher()
+	if _, ok := pub.Last(); ok {
+		t.Error("Last() found an event before any publish")
+	}
+
+	order := testOrder()
+	ctx := WithMessageHeaders(context.Background(), map[string]string{BackfillHeader: "true"})
+	if err := pub.PublishOrderCompleted(ctx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	order.OrderId = "changed-after-publish"
+
+	last, ok := pub.Last()
+	if !ok || last.Order.GetOrderId() != testOrder().GetOrderId() || last.Headers[BackfillHeader] != "true" {
+		t.Errorf("Last() = %+v, %v, want the order as published with its headers", last, ok)
+	}
+	if orders := pub.Orders(); len(orders) != 1 || !proto.Equal(orders[0], testOrder()) || pub.Len() != 1 {
+		t.Errorf("Orders() = %v, want the order", orders)
+	}
+
+	pub.Reset()
+	if pub.Len() != 0 || len(pub.Events()) != 0 {
+		t.Errorf("Events() = %v after Reset, want none", pub.Events())
+	}
+}
+
+func TestInMemoryOrderEventPublisherSubscribe(t *testing.T) {
+	pub := NewInMemoryOrderEventPublisher()
+	orders := make(chan *pb.OrderResult, 1)
+	unsubscribe := pub.Subscribe(orders)
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	select {
+	case got := <-orders:
+		if got.GetOrderId() != testOrder().GetOrderId() {
+			t.Errorf("subscriber received %s, want %s", got.GetOrderId(), testOrder().GetOrderId())
+		}
+	default:
+		t.Fatal("subscriber received no order")
+	}
+
+	unsubscribe()
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(orders) != 0 {
+		t.Error("subscriber received an order after unsubscribing")
+	}
+}
+
+func TestInMemoryOrderEventPublisherSubscriberNotReading(t *testing.T) {
+	pub := NewInMemoryOrderEventPublisher()
+	pub.Subscribe(make(chan *pb.OrderResult))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PublishOrderCompleted() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestInMemoryOrderEventPublisherInjectedFailures(t *testing.T) {
+	pub := NewInMemoryOrderEventPublisher()
+	first, second, down := errors.New("first"), errors.New("second"), errors.New("broker down")
+	pub.FailAll(down)
+	pub.FailNext(first, second)
+
+	for _, want := range []error{first, second, down, down} {
+		if err := pub.PublishOrderCompleted(context.Background(), testOrder()); !errors.Is(err, want) {
+			t.Errorf("PublishOrderCompleted() = %v, want %v", err, want)
+		}
+	}
+	pub.FailAll(nil)
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Errorf("PublishOrderCompleted() = %v after FailAll(nil)", err)
+	}
+	if pub.Len() != 1 {
+		t.Errorf("captured %d events, want only the one that succeeded", pub.Len())
+	}
+}
+
+func TestInMemoryOrderEventPublisherCapacity(t *testing.T) {
+	pub := NewInMemoryOrderEventPublisher(WithInMemoryCapacity(2))
+	for _, id := range []string{"a", "b", "c"} {
+		order := testOrder()
+		order.OrderId = id
+		if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+	}
+	orders := pub.Orders()
+	if len(orders) != 2 || orders[0].GetOrderId() != "b" || orders[1].GetOrderId() != "c" {
+		t.Errorf("Orders() = %v, want the last 2 orders", orders)
+	}
+}
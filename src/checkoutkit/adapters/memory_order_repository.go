@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// InMemoryOrderRepository implements the OrderRepository port in process
+// memory. It keeps the most recent orders of each user, up to a limit, and
+// loses them on restart.
+type InMemoryOrderRepository struct {
+	limit int
+
+	mu     sync.Mutex
+	orders map[string][]storedOrder
+}
+
+// storedOrder is an order with its position in the user's history. Page tokens
+// are sequence numbers, so they stay valid while newer orders are added.
+type storedOrder struct {
+	seq   int
+	order *pb.OrderResult
+}
+
+// Compile-time check that InMemoryOrderRepository implements OrderRepository
+var _ ports.OrderRepository = (*InMemoryOrderRepository)(nil)
+
+// NewInMemoryOrderRepository creates a repository keeping up to limit orders
+// per user.
+func NewInMemoryOrderRepository(limit int) *InMemoryOrderRepository {
+	return &InMemoryOrderRepository{
+		limit:  limit,
+		orders: make(map[string][]storedOrder),
+	}
+}
+
+// Save appends order to the user's history, dropping the oldest order once the
+// limit is reached.
+func (r *InMemoryOrderRepository) Save(ctx context.Context, userID string, order *pb.OrderResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := r.orders[userID]
+	seq := 1
+	if len(history) > 0 {
+		seq = history[len(history)-1].seq + 1
+	}
+	history = append(history, storedOrder{seq: seq, order: proto.Clone(order).(*pb.OrderResult)})
+	if len(history) > r.limit {
+		history = history[len(history)-r.limit:]
+	}
+	r.orders[userID] = history
+	return nil
+}
+
+// Get returns a copy of the order.
+func (r *InMemoryOrderRepository) Get(ctx context.Context, userID, orderID string) (*pb.OrderResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.orders[userID] {
+		if stored.order.GetOrderId() == orderID {
+			return proto.Clone(stored.order).(*pb.OrderResult), nil
+		}
+	}
+	return nil, ports.ErrOrderNotFound
+}
+
+// Users returns the users with orders, sorted.
+func (r *InMemoryOrderRepository) Users(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Sorted(maps.Keys(r.orders)), nil
+}
+
+// List returns copies of the user's orders, newest first. The page token is
+// the sequence number of the first order on the page.
+func (r *InMemoryOrderRepository) List(ctx context.Context, userID string, pageSize int, pageToken string) ([]*pb.OrderResult, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := r.orders[userID]
+	start := len(history) - 1
+	if pageToken != "" {
+		seq, err := strconv.Atoi(pageToken)
+		if err != nil || seq < 1 {
+			return nil, "", ports.ErrInvalidPageToken
+		}
+		for start >= 0 && history[start].seq > seq {
+			start--
+		}
+	}
+
+	var page []*pb.OrderResult
+	i := start
+	for ; i >= 0 && len(page) < pageSize; i-- {
+		page = append(page, proto.Clone(history[i].order).(*pb.OrderResult))
+	}
+	if i < 0 {
+		return page, "", nil
+	}
+	return page, strconv.Itoa(history[i].seq), nil
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func saveOrders(t *testing.T, repo *InMemoryOrderRepository, userID string, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		order := testOrder()
+		order.OrderId = id
+		if err := repo.Save(context.Background(), userID, order); err != nil {
+			t.Fatalf("Save(%s) = %v", id, err)
+		}
+	}
+}
+
+func orderIDs(orders []*pb.OrderResult) []string {
+	ids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.GetOrderId())
+	}
+	return ids
+}
+
+func TestInMemoryOrderRepositoryGet(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryOrderRepository(10)
+	saveOrders(t, repo, "user-1", "order-1")
+
+	got, err := repo.Get(ctx, "user-1", "order-1")
+	if err != nil || got.GetOrderId() != "order-1" {
+		t.Fatalf("Get() = %v, %v; want order-1", got, err)
+	}
+	got.OrderId = "changed"
+	if again, _ := repo.Get(ctx, "user-1", "order-1"); again.GetOrderId() != "order-1" {
+		t.Error("Get() returned the stored order instead of a copy")
+	}
+	// Orders are scoped to the user who placed them
+	if _, err := repo.Get(ctx, "user-2", "order-1"); !errors.Is(err, ports.ErrOrderNotFound) {
+		t.Errorf("Get() of another user's order = %v, want %v", err, ports.ErrOrderNotFound)
+	}
+}
+
+func TestInMemoryOrderRepositoryList(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryOrderRepository(4)
+	saveOrders(t, repo, "user-1", "order-1", "order-2", "order-3", "order-4", "order-5")
+
+	var pages [][]string
+	token := ""
+	for {
+		page, next, err := repo.List(ctx, "user-1", 3, token)
+		if err != nil {
+			t.Fatalf("List(%q) = %v", token, err)
+		}
+		pages = append(pages, orderIDs(page))
+		if next == "" {
+			break
+		}
+		token = next
+		// Orders placed between pages do not shift the next page
+		saveOrders(t, repo, "user-2", "other")
+	}
+
+	want := [][]string{{"order-5", "order-4", "order-3"}, {"order-2"}}
+	if fmt.Sprint(pages) != fmt.Sprint(want) {
+		t.Errorf("List() pages = %v, want %v (the oldest order dropped at the limit)", pages, want)
+	}
+	if page, next, _ := repo.List(ctx, "user-3", 3, ""); len(page) != 0 || next != "" {
+		t.Errorf("List() for a user without orders = %v, %q; want none", page, next)
+	}
+	if _, _, err := repo.List(ctx, "user-1", 3, "bogus"); !errors.Is(err, ports.ErrInvalidPageToken) {
+		t.Errorf("List() with a bogus token = %v, want %v", err, ports.ErrInvalidPageToken)
+	}
+	if !slices.Equal(orderIDs(mustList(t, repo, "user-2")), []string{"other"}) {
+		t.Error("List() mixed the orders of different users")
+	}
+}
+
+func mustList(t *testing.T, repo *InMemoryOrderRepository, userID string) []*pb.OrderResult {
+	t.Helper()
+	page, _, err := repo.List(context.Background(), userID, 10, "")
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	return page
+}
+
+func TestInMemoryOrderRepositoryUsers(t *testing.T) {
+	repo := NewInMemoryOrderRepository(10)
+	saveOrders(t, repo, "user-2", "order-1")
+	saveOrders(t, repo, "user-1", "order-2")
+
+	users, err := repo.Users(context.Background())
+	if want := []string{"user-1", "user-2"}; err != nil || !slices.Equal(users, want) {
+		t.Errorf("Users() = %v, %v; want %v", users, err, want)
+	}
+}
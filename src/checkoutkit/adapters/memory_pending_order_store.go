@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// InMemoryPendingOrderStore implements the PendingOrderStore port in process
+// memory. Orders do not survive a restart, so ListPending only helps callers
+// that share the store. Completed and failed orders are forgotten after a TTL.
+type InMemoryPendingOrderStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	orders map[string]pendingOrderEntry
+}
+
+// pendingOrderEntry is a stored order. expires is zero while it is pending.
+type pendingOrderEntry struct {
+	order   ports.PendingOrder
+	expires time.Time
+}
+
+// Compile-time check that InMemoryPendingOrderStore implements PendingOrderStore
+var _ ports.PendingOrderStore = (*InMemoryPendingOrderStore)(nil)
+
+// NewInMemoryPendingOrderStore creates a store that remembers finished orders
+// for ttl.
+func NewInMemoryPendingOrderStore(ttl time.Duration) *InMemoryPendingOrderStore {
+	return &InMemoryPendingOrderStore{
+		ttl:    ttl,
+		now:    time.Now,
+		orders: make(map[string]pendingOrderEntry),
+	}
+}
+
+// Save records order as pending.
+func (s *InMemoryPendingOrderStore) Save(ctx context.Context, order ports.PendingOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+	if _, ok := s.orders[order.OrderID]; ok {
+		return fmt.Errorf("order %s already exists", order.OrderID)
+	}
+	order.Status = ports.OrderPending
+	order.Request = proto.Clone(order.Request).(*pb.PlaceOrderRequest)
+	s.orders[order.OrderID] = pendingOrderEntry{order: order}
+	return nil
+}
+
+// Complete records result for the order.
+func (s *InMemoryPendingOrderStore) Complete(ctx context.Context, orderID string, result *pb.OrderResult) error {
+	return s.finish(orderID, func(order *ports.PendingOrder) {
+		order.Status = ports.OrderCompleted
+		order.Result = proto.Clone(result).(*pb.OrderResult)
+	})
+}
+
+// Fail records reason for the order.
+func (s *InMemoryPendingOrderStore) Fail(ctx context.Context, orderID string, reason string) error {
+	return s.finish(orderID, func(order *ports.PendingOrder) {
+		order.Status = ports.OrderFailed
+		order.Reason = reason
+	})
+}
+
+// Get returns a copy of the order.
+func (s *InMemoryPendingOrderStore) Get(ctx context.Context, orderID string) (ports.PendingOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+	entry, ok := s.orders[orderID]
+	if !ok {
+		return ports.PendingOrder{}, ports.ErrOrderNotFound
+	}
+	return copyPendingOrder(entry.order), nil
+}
+
+// ListPending returns copies of the pending orders.
+func (s *InMemoryPendingOrderStore) ListPending(ctx context.Context) ([]ports.PendingOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []ports.PendingOrder
+	for _, entry := range s.orders {
+		if entry.order.Status == ports.OrderPending {
+			pending = append(pending, copyPendingOrder(entry.order))
+		}
+	}
+	return pending, nil
+}
+
+// finish applies update to a pending order and starts its TTL.
+func (s *InMemoryPendingOrderStore) finish(orderID string, update func(*ports.PendingOrder)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+	entry, ok := s.orders[orderID]
+	if !ok {
+		return ports.ErrOrderNotFound
+	}
+	update(&entry.order)
+	entry.expires = now.Add(s.ttl)
+	s.orders[orderID] = entry
+	return nil
+}
+
+// expire drops the finished orders that expired before now. It must be called
+// with s.mu held.
+func (s *InMemoryPendingOrderStore) expire(now time.Time) {
+	for id, entry := range s.orders {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			delete(s.orders, id)
+		}
+	}
+}
+
+func copyPendingOrder(order ports.PendingOrder) ports.PendingOrder {
+	order.Request = proto.Clone(order.Request).(*pb.PlaceOrderRequest)
+	if order.Result != nil {
+		order.Result = proto.Clone(order.Result).(*pb.OrderResult)
+	}
+	return order
+}
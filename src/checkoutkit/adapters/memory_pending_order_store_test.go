@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestInMemoryPendingOrderStore(t *testing.T) {
+	ctx := context.Background()
+	clock := testdata.NewClock()
+	store := NewInMemoryPendingOrderStore(time.Hour)
+	store.now = clock.Now
+
+	req := &pb.PlaceOrderRequest{UserId: "user-1", UserCurrency: "USD"}
+	for _, id := range []string{"order-1", "order-2"} {
+		if err := store.Save(ctx, ports.PendingOrder{OrderID: id, Request: req}); err != nil {
+			t.Fatalf("Save(%s) = %v", id, err)
+		}
+	}
+	if err := store.Save(ctx, ports.PendingOrder{OrderID: "order-1", Request: req}); err == nil {
+		t.Error("Save() of an existing order = nil, want an error")
+	}
+	req.UserId = "changed"
+	if got, _ := store.Get(ctx, "order-1"); got.Status != ports.OrderPending || got.Request.UserId != "user-1" {
+		t.Errorf("Get() = %+v, want a pending copy of the saved request", got)
+	}
+
+	result := testOrder()
+	if err := store.Complete(ctx, "order-1", result); err != nil {
+		t.Fatalf("Complete() = %v", err)
+	}
+	if err := store.Fail(ctx, "order-2", "card declined"); err != nil {
+		t.Fatalf("Fail() = %v", err)
+	}
+	if got, _ := store.Get(ctx, "order-1"); got.Status != ports.OrderCompleted || !proto.Equal(got.Result, result) {
+		t.Errorf("Get() of a completed order = %+v, want its result", got)
+	}
+	if got, _ := store.Get(ctx, "order-2"); got.Status != ports.OrderFailed || got.Reason != "card declined" {
+		t.Errorf("Get() of a failed order = %+v, want its reason", got)
+	}
+	if pending, _ := store.ListPending(ctx); len(pending) != 0 {
+		t.Errorf("ListPending() = %v, want none", pending)
+	}
+	if err := store.Complete(ctx, "order-3", result); !errors.Is(err, ports.ErrOrderNotFound) {
+		t.Errorf("Complete() of an unknown order = %v, want %v", err, ports.ErrOrderNotFound)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err := store.Get(ctx, "order-1"); !errors.Is(err, ports.ErrOrderNotFound) {
+		t.Errorf("Get() of an expired order = %v, want %v", err, ports.ErrOrderNotFound)
+	}
+}
+
+func TestInMemoryPendingOrderStoreListPending(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryPendingOrderStore(time.Hour)
+	store.Save(ctx, ports.PendingOrder{OrderID: "order-1", Request: &pb.PlaceOrderRequest{}})
+
+	pending, err := store.ListPending(ctx)
+	if err != nil || len(pending) != 1 || pending[0].OrderID != "order-1" {
+		t.Fatalf("ListPending() = %v, %v; want order-1", pending, err)
+	}
+}
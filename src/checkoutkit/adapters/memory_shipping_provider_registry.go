@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Shipping methods of the providers main registers.
+const (
+	ShippingMethodStandard = "standard"
+	ShippingMethodExpress  = "express"
+	ShippingMethodCarrier  = "carrier"
+)
+
+// InMemoryShippingProviderRegistry maps shipping methods to the providers
+// registered for them. Providers are registered at startup, before the
+// registry is used.
+type InMemoryShippingProviderRegistry struct {
+	defaultMethod string
+	providers     map[string]ports.ShippingProvider
+}
+
+// Compile-time check that InMemoryShippingProviderRegistry implements ShippingProviderRegistry
+var _ ports.ShippingProviderRegistry = (*InMemoryShippingProviderRegistry)(nil)
+
+// NewInMemoryShippingProviderRegistry creates a registry whose default method
+// is defaultMethod. Register must be called with a provider for it.
+func NewInMemoryShippingProviderRegistry(defaultMethod string) *InMemoryShippingProviderRegistry {
+	return &InMemoryShippingProviderRegistry{defaultMethod: defaultMethod, providers: map[string]ports.ShippingProvider{}}
+}
+
+// Register makes provider ship the orders of method, replacing any provider
+// registered for it before.
+func (r *InMemoryShippingProviderRegistry) Register(method string, provider ports.ShippingProvider) {
+	r.providers[method] = provider
+}
+
+// Provider returns the provider of method, or of the default method when
+// method is empty.
+func (r *InMemoryShippingProviderRegistry) Provider(method string) (ports.ShippingProvider, error) {
+	if method == "" {
+		method = r.defaultMethod
+	}
+	provider, ok := r.providers[method]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of %v", ports.ErrUnknownShippingMethod, method, r.Methods())
+	}
+	return provider, nil
+}
+
+// Methods returns the registered shipping methods in alphabetical order.
+func (r *InMemoryShippingProviderRegistry) Methods() []string {
+	methods := make([]string, 0, len(r.providers))
+	for method := range r.providers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
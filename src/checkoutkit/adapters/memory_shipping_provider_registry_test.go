@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestInMemoryShippingProviderRegistry(t *testing.T) {
+	standard := NewHTTPShippingProvider("http://shipping")
+	express := NewExpressShippingProvider(standard, nil)
+	registry := NewInMemoryShippingProviderRegistry(ShippingMethodStandard)
+	registry.Register(ShippingMethodStandard, standard)
+	registry.Register(ShippingMethodExpress, express)
+
+	tests := []struct {
+		method  string
+		want    ports.ShippingProvider
+		wantErr error
+	}{
+		{method: "", want: standard},
+		{method: ShippingMethodStandard, want: standard},
+		{method: ShippingMethodExpress, want: express},
+		{method: ShippingMethodCarrier, wantErr: ports.ErrUnknownShippingMethod},
+	}
+	for _, tt := range tests {
+		got, err := registry.Provider(tt.method)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("Provider(%q) = %v, %v; want %v, %v", tt.method, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if got, want := registry.Methods(), []string{ShippingMethodExpress, ShippingMethodStandard}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Methods() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"maps"
+)
+
+// BackfillHeader marks order events republished from the order history, so
+// that consumers can tell them apart from new orders.
+const BackfillHeader = "backfill"
+
+// messageHeadersKey is the context key of WithMessageHeaders.
+type messageHeadersKey struct{}
+
+// WithMessageHeaders returns a context whose order events carry headers in
+// addition to the trace context. The Kafka publishers add them as record
+// headers and the webhook publisher as HTTP headers. The spool keeps them
+// with the order for its replay.
+func WithMessageHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := maps.Clone(MessageHeaders(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(headers))
+	}
+	maps.Copy(merged, headers)
+	return context.WithValue(ctx, messageHeadersKey{}, merged)
+}
+
+// MessageHeaders returns the headers set with WithMessageHeaders.
+func MessageHeaders(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(messageHeadersKey{}).(map[string]string)
+	return headers
+}
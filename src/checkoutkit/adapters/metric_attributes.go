@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+// Publisher metrics only carry attributes with a small, bounded set of values:
+// the messaging system, the topic, the region, the event type, the outcome and
+// the error code. Per-message values such as order IDs, offsets or user IDs belong on
+// spans and logs; on metrics each distinct value creates a new time series and
+// quickly overwhelms the metrics backend.
+const (
+	eventTypeKey = attribute.Key("app.event.type")
+	outcomeKey   = attribute.Key("app.publish.outcome")
+	// failoverKey tells failovers to the secondary region apart from
+	// failbacks to the primary one
+	failoverKey = attribute.Key("app.publish.failover")
+
+	eventTypeOrderCompleted = "order.completed"
+	outcomeSuccess          = "success"
+	outcomeFailure          = "failure"
+	failoverToSecondary     = "failover"
+	failoverToPrimary       = "failback"
+)
+
+// MetricAttributeKeys returns every attribute key publisher metrics may carry.
+// The service installs it as an allow-list view on the adapters' meters, so
+// that an attribute added by mistake is dropped instead of exploding the
+// number of series.
+func MetricAttributeKeys() []attribute.Key {
+	return []attribute.Key{
+		semconv.MessagingSystemKey,
+		semconv.MessagingDestinationNameKey,
+		semconv.CloudRegionKey,
+		eventTypeKey,
+		outcomeKey,
+		failoverKey,
+		errcode.Key,
+	}
+}
+
+// publishMetricAttributes returns the attributes of a publish measurement to
+// topic of the messaging system. region is empty for a publisher without one,
+// and err is the outcome of the publish, nil on success.
+func publishMetricAttributes(system attribute.KeyValue, topic, region string, err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		system,
+		semconv.MessagingDestinationName(topic),
+		eventTypeKey.String(eventTypeOrderCompleted),
+	}
+	if region != "" {
+		attrs = append(attrs, semconv.CloudRegion(region))
+	}
+	if err != nil {
+		return append(attrs, outcomeKey.String(outcomeFailure), errcode.Key.String(string(errcode.Of(err))))
+	}
+	return append(attrs, outcomeKey.String(outcomeSuccess))
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+)
+
+// TestPublisherMetricAttributesAreBounded fails when a publisher metric gains
+// an attribute outside MetricAttributeKeys or carries a per-message value.
+// Extend MetricAttributeKeys only with attributes whose values form a small,
+// fixed set.
+func TestPublisherMetricAttributesAreBounded(t *testing.T) {
+	reader := newTestMetrics(t)
+	producer := kafkatest.NewProducer(t)
+	producer.ExpectSuccess()
+	producer.ExpectError(sarama.ErrNotLeaderForPartition)
+	pub := NewKafkaOrderEventPublisher(producer, discardLogger(), WithSlowPublishThreshold(time.Nanosecond), WithRegion("us-east-1"))
+
+	order := testOrder()
+	_ = pub.PublishOrderCompleted(context.Background(), order)
+	_ = pub.PublishOrderCompleted(context.Background(), order)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	allowed := MetricAttributeKeys()
+	perMessage := []string{order.GetOrderId(), order.GetShippingTrackingId()}
+	checked := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			for _, set := range dataPointAttributes(m.Data) {
+				checked++
+				for _, kv := range set.ToSlice() {
+					if !slices.Contains(allowed, kv.Key) {
+						t.Errorf("metric %s has attribute %s outside MetricAttributeKeys", m.Name, kv.Key)
+					}
+					if slices.Contains(perMessage, kv.Value.Emit()) {
+						t.Errorf("metric %s attribute %s carries per-message value %q", m.Name, kv.Key, kv.Value.Emit())
+					}
+				}
+			}
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no publisher metrics recorded")
+	}
+}
+
+func dataPointAttributes(data metricdata.Aggregation) []attribute.Set {
+	var sets []attribute.Set
+	switch d := data.(type) {
+	case metricdata.Histogram[float64]:
+		for _, dp := range d.DataPoints {
+			sets = append(sets, dp.Attributes)
+		}
+	case metricdata.Sum[int64]:
+		for _, dp := range d.DataPoints {
+			sets = append(sets, dp.Attributes)
+		}
+	}
+	return sets
+}
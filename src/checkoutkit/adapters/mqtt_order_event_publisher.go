@@ -0,0 +1,209 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Defaults of the MQTT publisher.
+const (
+	defaultMQTTQoS            = 1
+	defaultMQTTPublishTimeout = 5 * time.Second
+)
+
+// mqttQoSKey is the attribute recording the QoS of an MQTT publish on its
+// producer span.
+const mqttQoSKey = attribute.Key("messaging.mqtt.qos")
+
+// MQTTClient is the part of the paho MQTT v5 client the MQTT publisher uses.
+// A client that can also be disconnected with Disconnect(ctx), as the
+// autopaho connection manager, is disconnected on Close.
+type MQTTClient interface {
+	Publish(ctx context.Context, p *paho.Publish) (*paho.PublishResponse, error)
+}
+
+// Compile-time checks that the paho client and connection manager implement
+// MQTTClient
+var (
+	_ MQTTClient = (*paho.Client)(nil)
+	_ MQTTClient = (*autopaho.ConnectionManager)(nil)
+)
+
+// MQTTOrderEventPublisher implements the OrderEventPublisher port on an MQTT
+// v5 broker, for IoT-style deployments whose consumers already speak MQTT.
+// Each order is published in protobuf, as on Kafka, to a topic with the
+// configured QoS. The trace context, baggage and message headers travel as
+// MQTT v5 user properties. With QoS 1 or 2, the publish returns once the
+// broker acknowledged it; with QoS 0, once it was written to the connection.
+type MQTTOrderEventPublisher struct {
+	client         MQTTClient
+	topic          string
+	qos            byte
+	publishTimeout time.Duration
+	logger         *slog.Logger
+	tracer         trace.Tracer
+
+	// closeMu guards closed, so that no publish starts once Close has
+	// started disconnecting
+	closeMu   sync.RWMutex
+	closed    bool
+	publishes sync.WaitGroup
+}
+
+// Compile-time check that MQTTOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*MQTTOrderEventPublisher)(nil)
+
+// Compile-time check that MQTTOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*MQTTOrderEventPublisher)(nil)
+
+// MQTTPublisherOption configures optional behavior of an
+// MQTTOrderEventPublisher.
+type MQTTPublisherOption func(*MQTTOrderEventPublisher)
+
+// WithMQTTQoS sets the QoS of the publishes: 0 at most once, 1 at least once
+// or 2 exactly once. The default is 1.
+func WithMQTTQoS(qos byte) MQTTPublisherOption {
+	return func(m *MQTTOrderEventPublisher) {
+		m.qos = qos
+	}
+}
+
+// WithMQTTPublishTimeout sets how long a publish waits for the broker to
+// acknowledge it, unless its context ends first. The default is 5s.
+func WithMQTTPublishTimeout(timeout time.Duration) MQTTPublisherOption {
+	return func(m *MQTTOrderEventPublisher) {
+		m.publishTimeout = timeout
+	}
+}
+
+// NewMQTTOrderEventPublisher creates a publisher of orders to topic.
+func NewMQTTOrderEventPublisher(client MQTTClient, topic string, logger *slog.Logger, opts ...MQTTPublisherOption) *MQTTOrderEventPublisher {
+	m := &MQTTOrderEventPublisher{
+		client:         client,
+		topic:          topic,
+		qos:            defaultMQTTQoS,
+		publishTimeout: defaultMQTTPublishTimeout,
+		logger:         logger,
+		tracer:         otel.Tracer("checkout-mqtt-adapter"),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// PublishOrderCompleted publishes the order to the topic and, with QoS 1 or
+// 2, waits for the broker's acknowledgment.
+func (m *MQTTOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	m.closeMu.RLock()
+	if m.closed {
+		m.closeMu.RUnlock()
+		return errcode.Errorf(errcode.PublisherClosed, "mqtt publisher is closed")
+	}
+	m.publishes.Add(1)
+	m.closeMu.RUnlock()
+	defer m.publishes.Done()
+
+	data, err := proto.Marshal(order)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to protobuf: %w", err)
+	}
+	msg := &paho.Publish{
+		QoS:     m.qos,
+		Topic:   m.topic,
+		Payload: data,
+		Properties: &paho.PublishProperties{
+			ContentType: "application/protobuf",
+		},
+	}
+	for key, value := range MessageHeaders(ctx) {
+		msg.Properties.User.Add(key, value)
+	}
+
+	spanCtx, span := m.tracer.Start(ctx, fmt.Sprintf("%s publish", m.topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.PeerService("mqtt"),
+			semconv.MessagingSystemKey.String("mqtt"),
+			semconv.MessagingDestinationName(m.topic),
+			semconv.MessagingOperationPublish,
+			mqttQoSKey.Int(int(m.qos)),
+		),
+	)
+	defer span.End()
+	span.SetAttributes(baggageAttributes(ctx)...)
+	for key, value := range PropagationHeaders(spanCtx) {
+		msg.Properties.User.Add(key, value)
+	}
+
+	publishCtx, cancel := context.WithTimeout(spanCtx, m.publishTimeout)
+	defer cancel()
+	resp, err := m.client.Publish(publishCtx, msg)
+	// A broker rejecting a QoS 2 publish answers a PUBREC with an error
+	// reason code, which the client does not report as an error
+	if err == nil && resp != nil && resp.ReasonCode >= 0x80 {
+		err = fmt.Errorf("broker answered reason code 0x%02x", resp.ReasonCode)
+	}
+	if err != nil {
+		code := errcode.MQTTPublishFailed
+		if errors.Is(err, context.DeadlineExceeded) {
+			code = errcode.MQTTAckTimeout
+		}
+		err = errcode.Errorf(code, "failed to publish order event to %s: %w", m.topic, err)
+		errcode.RecordSpan(span, err, "Broker did not acknowledge the message")
+		return err
+	}
+	span.AddEvent(PublishEventAcked)
+	m.logger.InfoContext(ctx, "Published order event to MQTT",
+		slog.String("order_id", order.GetOrderId()),
+		slog.String("topic", m.topic),
+		slog.Int("qos", int(m.qos)),
+	)
+	return nil
+}
+
+// Close stops accepting order events, waits until the publishes in progress
+// have their acknowledgment or ctx is done, and then disconnects the client
+// if it can be. Events published after Close fail with PUBLISHER_CLOSED.
+func (m *MQTTOrderEventPublisher) Close(ctx context.Context) error {
+	m.closeMu.Lock()
+	if m.closed {
+		m.closeMu.Unlock()
+		return nil
+	}
+	m.closed = true
+	m.closeMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.publishes.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		m.logger.WarnContext(ctx, "Disconnecting from the MQTT broker with publishes still waiting for acknowledgment")
+	}
+	if c, ok := m.client.(interface{ Disconnect(context.Context) error }); ok {
+		return c.Disconnect(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.golang/paho"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// fakeMQTTClient acknowledges the messages published to it with reasonCode,
+// fails them with err, or, with block, waits for the publish context to end.
+type fakeMQTTClient struct {
+	mu           sync.Mutex
+	msgs         []*paho.Publish
+	reasonCode   byte
+	err          error
+	block        bool
+	disconnected bool
+}
+
+func (f *fakeMQTTClient) Publish(ctx context.Context, p *paho.Publish) (*paho.PublishResponse, error) {
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.msgs = append(f.msgs, p)
+	return &paho.PublishResponse{ReasonCode: f.reasonCode}, nil
+}
+
+func (f *fakeMQTTClient) Disconnect(context.Context) error {
+	f.disconnected = true
+	return nil
+}
+
+func TestMQTTOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	client := &fakeMQTTClient{}
+	pub := NewMQTTOrderEventPublisher(client, "orders", discardLogger(), WithMQTTQoS(2))
+	member, _ := baggage.NewMember(BaggageSyntheticRequest, "true")
+	bag, _ := baggage.New(member)
+	ctx := WithMessageHeaders(baggage.ContextWithBaggage(context.Background(), bag), map[string]string{SchemaVersionHeader: "2"})
+
+	order := testOrder()
+	if err := pub.PublishOrderCompleted(ctx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(client.msgs) != 1 {
+		t.Fatalf("published %d messages, want 1", len(client.msgs))
+	}
+	msg := client.msgs[0]
+	var got pb.OrderResult
+	if err := proto.Unmarshal(msg.Payload, &got); err != nil || got.GetOrderId() != order.GetOrderId() {
+		t.Errorf("message = %v (%v), want order %s in protobuf", &got, err, order.GetOrderId())
+	}
+	if msg.Topic != "orders" || msg.QoS != 2 || msg.Properties.ContentType != "application/protobuf" {
+		t.Errorf("published to %q with QoS %d and content type %q, want orders with QoS 2 in protobuf", msg.Topic, msg.QoS, msg.Properties.ContentType)
+	}
+	for _, key := range []string{"traceparent", "baggage", SchemaVersionHeader} {
+		if msg.Properties.User.Get(key) == "" {
+			t.Errorf("user property %s missing from %v", key, msg.Properties.User)
+		}
+	}
+
+	span := endedSpan(t, recorder, "orders publish")
+	if span.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("span kind = %v, want producer", span.SpanKind())
+	}
+	if traceparent := msg.Properties.User.Get("traceparent"); traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("traceparent = %s, want the trace of the producer span %s", traceparent, span.SpanContext().TraceID())
+	}
+	if events := span.Events(); len(events) != 1 || events[0].Name != PublishEventAcked {
+		t.Errorf("span events = %v, want %s", events, PublishEventAcked)
+	}
+}
+
+func TestMQTTOrderEventPublisherFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *fakeMQTTClient
+		wantCode errcode.Code
+	}{
+		{name: "connection lost", client: &fakeMQTTClient{err: errors.New("connection lost")}, wantCode: errcode.MQTTPublishFailed},
+		{name: "not authorized", client: &fakeMQTTClient{reasonCode: 0x87}, wantCode: errcode.MQTTPublishFailed},
+		{name: "ack timeout", client: &fakeMQTTClient{block: true}, wantCode: errcode.MQTTAckTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := NewMQTTOrderEventPublisher(tt.client, "orders", discardLogger(), WithMQTTPublishTimeout(10*time.Millisecond))
+			err := pub.PublishOrderCompleted(context.Background(), testOrder())
+			if code := errcode.Of(err); code != tt.wantCode {
+				t.Errorf("PublishOrderCompleted() = %v with code %s, want %s", err, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMQTTOrderEventPublisherClose(t *testing.T) {
+	client := &fakeMQTTClient{}
+	pub := NewMQTTOrderEventPublisher(client, "orders", discardLogger())
+	if err := pub.Close(context.Background()); err != nil || !client.disconnected {
+		t.Fatalf("Close() = %v, disconnected %v, want the client disconnected", err, client.disconnected)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want %s", err, errcode.PublisherClosed)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// defaultNATSAckWait is how long a publish waits for the stream's
+// acknowledgment by default.
+const defaultNATSAckWait = 5 * time.Second
+
+// Attributes of the acknowledgment recorded on the producer span of a NATS
+// publish.
+const (
+	natsStreamSequenceKey = attribute.Key("messaging.nats.stream.sequence")
+	natsDuplicateKey      = attribute.Key("messaging.nats.duplicate")
+)
+
+// NATSOrderEventPublisher implements the OrderEventPublisher port on NATS
+// JetStream, for teams running NATS instead of Kafka. Each order is published
+// in protobuf, as on Kafka, to the subject of a stream, and the publish
+// returns once the stream acknowledged it or the ack wait passed. The trace
+// context, baggage and message headers travel as NATS headers. The order ID
+// is the Nats-Msg-Id, so that the stream drops a retried publish within its
+// duplicate window.
+type NATSOrderEventPublisher struct {
+	js      jetstream.JetStream
+	stream  string
+	subject string
+	ackWait time.Duration
+	logger  *slog.Logger
+	tracer  trace.Tracer
+
+	// closeMu guards closed, so that no publish starts once Close has
+	// started draining the connection
+	closeMu   sync.RWMutex
+	closed    bool
+	publishes sync.WaitGroup
+}
+
+// Compile-time check that NATSOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*NATSOrderEventPublisher)(nil)
+
+// Compile-time check that NATSOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*NATSOrderEventPublisher)(nil)
+
+// NATSPublisherOption configures optional behavior of a NATSOrderEventPublisher.
+type NATSPublisherOption func(*NATSOrderEventPublisher)
+
+// WithAckWait sets how long a publish waits for the stream to acknowledge
+// it, unless its context ends first. The default is 5s.
+func WithAckWait(wait time.Duration) NATSPublisherOption {
+	return func(n *NATSOrderEventPublisher) {
+		n.ackWait = wait
+	}
+}
+
+// NewNATSOrderEventPublisher creates a publisher of orders to subject, which
+// stream must bind.
+func NewNATSOrderEventPublisher(js jetstream.JetStream, stream, subject string, logger *slog.Logger, opts ...NATSPublisherOption) *NATSOrderEventPublisher {
+	n := &NATSOrderEventPublisher{
+		js:      js,
+		stream:  stream,
+		subject: subject,
+		ackWait: defaultNATSAckWait,
+		logger:  logger,
+		tracer:  otel.Tracer("checkout-nats-adapter"),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// EnsureNATSStream creates stream with subject as its only subject, unless a
+// stream of that name exists.
+func EnsureNATSStream(ctx context.Context, js jetstream.JetStream, stream, subject string) error {
+	_, err := js.Stream(ctx, stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: stream, Subjects: []string{subject}})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to ensure NATS stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+// PublishOrderCompleted publishes the order to the stream and waits for its
+// acknowledgment.
+func (n *NATSOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	n.closeMu.RLock()
+	if n.closed {
+		n.closeMu.RUnlock()
+		return errcode.Errorf(errcode.PublisherClosed, "nats publisher is closed")
+	}
+	n.publishes.Add(1)
+	n.closeMu.RUnlock()
+	defer n.publishes.Done()
+
+	data, err := proto.Marshal(order)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to protobuf: %w", err)
+	}
+	msg := nats.NewMsg(n.subject)
+	msg.Data = data
+	for key, value := range MessageHeaders(ctx) {
+		msg.Header.Set(key, value)
+	}
+
+	spanCtx, span := n.tracer.Start(ctx, fmt.Sprintf("%s publish", n.subject),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.PeerService("nats"),
+			semconv.NetworkTransportTCP,
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationName(n.subject),
+			semconv.MessagingOperationPublish,
+		),
+	)
+	defer span.End()
+	span.SetAttributes(baggageAttributes(ctx)...)
+	for key, value := range PropagationHeaders(spanCtx) {
+		msg.Header.Set(key, value)
+	}
+
+	ackCtx, cancel := context.WithTimeout(spanCtx, n.ackWait)
+	defer cancel()
+	ack, err := n.js.PublishMsg(ackCtx, msg,
+		jetstream.WithMsgID(order.GetOrderId()),
+		jetstream.WithExpectStream(n.stream),
+	)
+	if err != nil {
+		code := errcode.NATSPublishFailed
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout) {
+			code = errcode.NATSAckTimeout
+		}
+		err = errcode.Errorf(code, "failed to publish order event to %s: %w", n.subject, err)
+		errcode.RecordSpan(span, err, "Stream did not acknowledge the message")
+		return err
+	}
+	span.AddEvent(PublishEventAcked, trace.WithAttributes(
+		natsStreamSequenceKey.Int64(int64(ack.Sequence)),
+		natsDuplicateKey.Bool(ack.Duplicate),
+	))
+	n.logger.InfoContext(ctx, "Published order event to NATS",
+		slog.String("order_id", order.GetOrderId()),
+		slog.String("stream", ack.Stream),
+		slog.Uint64("sequence", ack.Sequence),
+	)
+	return nil
+}
+
+// Close stops accepting order events, waits until the publishes in progress
+// have their acknowledgment or ctx is done, and then drains the connection.
+// Events published after Close fail with PUBLISHER_CLOSED.
+func (n *NATSOrderEventPublisher) Close(ctx context.Context) error {
+	n.closeMu.Lock()
+	if n.closed {
+		n.closeMu.Unlock()
+		return nil
+	}
+	n.closed = true
+	n.closeMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		n.publishes.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		n.logger.WarnContext(ctx, "Closing the NATS connection with publishes still waiting for acknowledgment")
+	}
+	if conn := n.js.Conn(); conn != nil {
+		return conn.Drain()
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// fakeJetStream acknowledges the messages published to it, fails them with
+// err, or, with block, waits for the publish context to end.
+type fakeJetStream struct {
+	jetstream.JetStream
+
+	mu      sync.Mutex
+	msgs    []*nats.Msg
+	err     error
+	block   bool
+	streams map[string]bool
+	created []jetstream.StreamConfig
+}
+
+func (f *fakeJetStream) PublishMsg(ctx context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.msgs = append(f.msgs, msg)
+	return &jetstream.PubAck{Stream: "ORDERS", Sequence: uint64(len(f.msgs))}, nil
+}
+
+func (f *fakeJetStream) Stream(_ context.Context, name string) (jetstream.Stream, error) {
+	if !f.streams[name] {
+		return nil, jetstream.ErrStreamNotFound
+	}
+	return nil, nil
+}
+
+func (f *fakeJetStream) CreateStream(_ context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	f.created = append(f.created, cfg)
+	return nil, nil
+}
+
+func (f *fakeJetStream) Conn() *nats.Conn { return nil }
+
+func TestNATSOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	js := &fakeJetStream{}
+	pub := NewNATSOrderEventPublisher(js, "ORDERS", "orders", discardLogger())
+	member, _ := baggage.NewMember(BaggageSyntheticRequest, "true")
+	bag, _ := baggage.New(member)
+	ctx := WithMessageHeaders(baggage.ContextWithBaggage(context.Background(), bag), map[string]string{SchemaVersionHeader: "2"})
+
+	order := testOrder()
+	if err := pub.PublishOrderCompleted(ctx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(js.msgs) != 1 {
+		t.Fatalf("published %d messages, want 1", len(js.msgs))
+	}
+	msg := js.msgs[0]
+	var got pb.OrderResult
+	if err := proto.Unmarshal(msg.Data, &got); err != nil || got.GetOrderId() != order.GetOrderId() {
+		t.Errorf("message = %v (%v), want order %s in protobuf", &got, err, order.GetOrderId())
+	}
+	if msg.Subject != "orders" {
+		t.Errorf("subject = %q, want orders", msg.Subject)
+	}
+	for _, key := range []string{"traceparent", "baggage", SchemaVersionHeader} {
+		if msg.Header.Get(key) == "" {
+			t.Errorf("header %s missing from %v", key, msg.Header)
+		}
+	}
+
+	span := endedSpan(t, recorder, "orders publish")
+	if span.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("span kind = %v, want producer", span.SpanKind())
+	}
+	if traceparent := msg.Header.Get("traceparent"); traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("traceparent = %s, want the trace of the producer span %s", traceparent, span.SpanContext().TraceID())
+	}
+	if events := span.Events(); len(events) != 1 || events[0].Name != PublishEventAcked {
+		t.Errorf("span events = %v, want %s", events, PublishEventAcked)
+	}
+}
+
+func TestNATSOrderEventPublisherFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		js       *fakeJetStream
+		wantCode errcode.Code
+	}{
+		{name: "no stream", js: &fakeJetStream{err: jetstream.ErrNoStreamResponse}, wantCode: errcode.NATSPublishFailed},
+		{name: "ack wait", js: &fakeJetStream{block: true}, wantCode: errcode.NATSAckTimeout},
+		{name: "server timeout", js: &fakeJetStream{err: nats.ErrTimeout}, wantCode: errcode.NATSAckTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := NewNATSOrderEventPublisher(tt.js, "ORDERS", "orders", discardLogger(), WithAckWait(10*time.Millisecond))
+			err := pub.PublishOrderCompleted(context.Background(), testOrder())
+			if code := errcode.Of(err); code != tt.wantCode {
+				t.Errorf("PublishOrderCompleted() = %v with code %s, want %s", err, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestNATSOrderEventPublisherClose(t *testing.T) {
+	pub := NewNATSOrderEventPublisher(&fakeJetStream{}, "ORDERS", "orders", discardLogger())
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want %s", err, errcode.PublisherClosed)
+	}
+}
+
+func TestEnsureNATSStream(t *testing.T) {
+	js := &fakeJetStream{streams: map[string]bool{"EXISTING": true}}
+	if err := EnsureNATSStream(context.Background(), js, "EXISTING", "orders"); err != nil || len(js.created) != 0 {
+		t.Errorf("EnsureNATSStream(EXISTING) = %v and created %v, want the stream kept", err, js.created)
+	}
+	if err := EnsureNATSStream(context.Background(), js, "ORDERS", "orders"); err != nil {
+		t.Fatalf("EnsureNATSStream(ORDERS) = %v", err)
+	}
+	if len(js.created) != 1 || js.created[0].Name != "ORDERS" || js.created[0].Subjects[0] != "orders" {
+		t.Errorf("created %v, want ORDERS on subject orders", js.created)
+	}
+
+	failing := errors.New("not authorized")
+	if err := EnsureNATSStream(context.Background(), &failingStreamLookup{err: failing}, "ORDERS", "orders"); !errors.Is(err, failing) {
+		t.Errorf("EnsureNATSStream() = %v, want %v", err, failing)
+	}
+}
+
+// failingStreamLookup fails to look streams up with err.
+type failingStreamLookup struct {
+	fakeJetStream
+	err error
+}
+
+func (f *failingStreamLookup) Stream(context.Context, string) (jetstream.Stream, error) {
+	return nil, f.err
+}
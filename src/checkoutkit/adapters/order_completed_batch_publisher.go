@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// OrderCompletedBatchPublisher implements the OrderEventBatchPublisher port
+// on an OrderEventPublisher, for transports that carry only the OrderResult.
+// It publishes the OrderCompletedEvent of each batch and skips the others.
+type OrderCompletedBatchPublisher struct {
+	publisher ports.OrderEventPublisher
+}
+
+// Compile-time check that OrderCompletedBatchPublisher implements OrderEventBatchPublisher
+var _ ports.OrderEventBatchPublisher = (*OrderCompletedBatchPublisher)(nil)
+
+// NewOrderCompletedBatchPublisher publishes the OrderResult of each batch
+// through publisher.
+func NewOrderCompletedBatchPublisher(publisher ports.OrderEventPublisher) *OrderCompletedBatchPublisher {
+	return &OrderCompletedBatchPublisher{publisher: publisher}
+}
+
+// PublishOrderEvents publishes the OrderCompletedEvent events of events.
+func (p *OrderCompletedBatchPublisher) PublishOrderEvents(ctx context.Context, events []ports.OrderEvent) error {
+	for _, event := range events {
+		if event.Type != ports.OrderCompletedEvent {
+			continue
+		}
+		if err := p.publisher.PublishOrderCompleted(ctx, event.Order); err != nil {
+			return fmt.Errorf("failed to publish order %s: %w", event.Order.GetOrderId(), err)
+		}
+	}
+	return nil
+}
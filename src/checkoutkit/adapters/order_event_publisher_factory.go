@@ -0,0 +1,875 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// defaultInMemoryCapacity is how many order events the memory publisher
+// keeps.
+const defaultInMemoryCapacity = 1000
+
+// Producer modes of config.Kafka.
+const (
+	KafkaProducerAsync         = "async"
+	KafkaProducerSync          = "sync"
+	KafkaProducerTransactional = "transactional"
+)
+
+// Acknowledgment modes of config.Kafka.
+const (
+	KafkaAckWait       = "wait"
+	KafkaAckBackground = "background"
+)
+
+// Message keys of config.Kafka.
+const (
+	KafkaKeyNone    = "none"
+	KafkaKeyOrderID = "order_id"
+)
+
+// Publisher kinds of config.OrderEvents.
+const (
+	PublisherKafka     = "kafka"
+	PublisherWebhook   = "webhook"
+	PublisherNATS      = "nats"
+	PublisherSNS       = "sns"
+	PublisherPubSub    = "pubsub"
+	PublisherMQTT      = "mqtt"
+	PublisherSpool     = "spool"
+	PublisherFile      = "file"
+	PublisherMemory    = "memory"
+	PublisherComposite = "composite"
+	PublisherOutbox    = "outbox"
+	PublisherNoOp      = "noop"
+	PublisherNone      = "none"
+)
+
+// PublisherChain is the order event publisher selected by
+// NewOrderEventPublisherFromConfig.
+type PublisherChain struct {
+	ports.OrderEventPublisher
+
+	// Kafka is the Kafka publisher of the chain, nil if Kafka is not used or
+	// was unreachable on startup
+	Kafka *KafkaOrderEventPublisher
+	// Regions fails Kafka over between regions, nil without
+	// KAFKA_SECONDARY_ADDR or if neither region was reachable on startup
+	Regions *RegionFailoverOrderEventPublisher
+	// Fallback switches between the primary and fallback publishers, nil
+	// without a fallback
+	Fallback *FallbackOrderEventPublisher
+	// Spool is the spool fallback, nil without one
+	Spool *SpoolOrderEventPublisher
+	// Replayer replays the spool fallback to the primary, nil without a
+	// spool fallback or with replaying disabled
+	Replayer *SpoolReplayer
+
+	mu       sync.Mutex
+	lastFail PublishFailure
+}
+
+// PublishFailure is a failed publish.
+type PublishFailure struct {
+	Err error
+	At  time.Time
+}
+
+// PublishOrderCompleted publishes the order through the chain, recording the
+// failure returned by LastFailure.
+func (c *PublisherChain) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	err := c.OrderEventPublisher.PublishOrderCompleted(ctx, order)
+	if err != nil {
+		c.mu.Lock()
+		c.lastFail = PublishFailure{Err: err, At: time.Now()}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// LastFailure returns the last failed publish of the chain, or of its
+// primary when the fallback took over, the zero PublishFailure if none
+// failed.
+func (c *PublisherChain) LastFailure() PublishFailure {
+	c.mu.Lock()
+	last := c.lastFail
+	c.mu.Unlock()
+	if c.Fallback != nil {
+		if primary := c.Fallback.LastFailure(); primary.At.After(last.At) {
+			return primary
+		}
+	}
+	return last
+}
+
+// Pause holds order events in the spool fallback until Resume, and stops
+// replaying it. It fails if the chain has no spool fallback, which would
+// lose the events.
+func (c *PublisherChain) Pause() error {
+	if c.Fallback == nil || c.Spool == nil {
+		return errors.New("publishing can only be paused with ORDER_EVENT_FALLBACK=spool")
+	}
+	c.Fallback.Pause()
+	return nil
+}
+
+// Resume publishes order events to the primary again, and the replayer
+// replays the ones spooled while paused.
+func (c *PublisherChain) Resume() {
+	if c.Fallback != nil {
+		c.Fallback.Resume()
+	}
+}
+
+// Paused reports whether the chain is paused.
+func (c *PublisherChain) Paused() bool {
+	return c.Fallback != nil && c.Fallback.Paused()
+}
+
+// Close stops replaying and closes the publishers of the chain.
+func (c *PublisherChain) Close(ctx context.Context) error {
+	var err error
+	if c.Replayer != nil {
+		err = c.Replayer.Close(ctx)
+	}
+	return errors.Join(err, closeIfLifecycle(ctx, c.OrderEventPublisher))
+}
+
+// NewOrderEventPublisherFromConfig selects the order event publisher from
+// events and, for Kafka, kafkaConfig:
+//
+//   - Publisher is the kind of the primary publisher: kafka, webhook, nats,
+//     sns, pubsub, mqtt, spool, file, memory, composite, outbox, noop or a
+//     kind added with Register.
+//   - Fallback is the spool, file, webhook, noop or none publisher used when
+//     a primary such as kafka, webhook, nats, sns, pubsub, mqtt or composite
+//     fails. The file and webhook fallbacks are configured by their own
+//     variables. A failed primary is
+//     bypassed for FallbackRecheckInterval.
+//   - A spool fallback is replayed to the primary every SpoolReplayInterval
+//     while the primary is healthy.
+//
+// The Kafka publisher is created with kafkaOpts. With a secondary region,
+// there is one Kafka publisher per region and order events fail over between
+// them. While Kafka is the primary, the broker is pinged before switching
+// back to it. If the primary cannot
+// connect, such as a Kafka producer without a broker, the chain starts
+// degraded on the fallback and connects once the primary is reachable.
+//
+// Settings that config.Load would have rejected are returned as an error.
+func NewOrderEventPublisherFromConfig(events config.OrderEvents, kafkaConfig config.Kafka, logger *slog.Logger, kafkaOpts ...KafkaPublisherOption) (*PublisherChain, error) {
+	kind := events.Publisher
+	fallbackKind := events.Fallback
+	interval := events.FallbackRecheckInterval
+	if interval <= 0 {
+		interval = defaultRecheckInterval
+	}
+
+	settings := PublisherSettings{Events: events, Kafka: kafkaConfig, Logger: logger, KafkaOptions: kafkaOpts}
+	var fallback ports.OrderEventPublisher
+	var spool *SpoolOrderEventPublisher
+	switch fallbackKind {
+	case PublisherSpool:
+		spool = NewSpoolOrderEventPublisher(events.SpoolPath, logger)
+		fallback = spool
+	case PublisherFile, PublisherWebhook:
+		if strings.EqualFold(kind, fallbackKind) {
+			return nil, fmt.Errorf("invalid ORDER_EVENT_FALLBACK %q, the primary publisher cannot be its own fallback", fallbackKind)
+		}
+		factory, _ := publisherFactory(fallbackKind)
+		transport, err := factory(settings)
+		if err == nil {
+			fallback, err = transport.Connect()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ORDER_EVENT_FALLBACK=%s: %w", fallbackKind, err)
+		}
+	case PublisherNoOp:
+		fallback = newNoOpPublisher(events, logger)
+	case PublisherNone:
+	default:
+		return nil, fmt.Errorf("invalid ORDER_EVENT_FALLBACK %q, expected spool, file, webhook, noop or none", fallbackKind)
+	}
+
+	factory, ok := publisherFactory(kind)
+	if !ok {
+		return nil, fmt.Errorf("invalid ORDER_EVENT_PUBLISHER %q, expected one of %s", kind, strings.Join(Publishers(), ", "))
+	}
+	transport, err := factory(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &PublisherChain{}
+	var fallbackOpts []FallbackPublisherOption
+	primary, err := transport.Connect()
+	switch {
+	case err != nil && (fallback == nil || transport.NoFallback):
+		return nil, err
+	case err != nil:
+		// Start degraded and connect once the primary is back
+		logger.Warn(fmt.Sprintf("%s unreachable, publishing order events to the %s fallback until it recovers: %v", kind, fallbackKind, err))
+		chain.OrderEventPublisher = &connectingOrderEventPublisher{connect: transport.Connect}
+		fallbackOpts = append(fallbackOpts, WithPrimaryDown())
+	default:
+		chain.OrderEventPublisher = primary
+		chain.Kafka, _ = primary.(*KafkaOrderEventPublisher)
+		chain.Regions, _ = primary.(*RegionFailoverOrderEventPublisher)
+	}
+
+	if fallback != nil && !transport.NoFallback {
+		fallbackOpts = append(fallbackOpts,
+			WithHealthCheck(transport.Check, interval),
+			WithPublisherKinds(strings.ToLower(kind), fallbackKind),
+		)
+		primary := chain.OrderEventPublisher
+		chain.Fallback = NewFallbackOrderEventPublisher(primary, fallback, logger, fallbackOpts...)
+		chain.OrderEventPublisher = chain.Fallback
+		chain.Spool = spool
+		if spool != nil && events.SpoolReplayInterval > 0 {
+			chain.Replayer = NewSpoolReplayer(spool, primary, logger,
+				WithReplayInterval(events.SpoolReplayInterval),
+				WithReplayCondition(chain.Fallback.Healthy),
+			)
+		}
+	}
+	return chain, nil
+}
+
+func init() {
+	Register(PublisherKafka, newKafkaTransport)
+	Register(PublisherWebhook, newWebhookTransport)
+	Register(PublisherNATS, newNATSTransport)
+	Register(PublisherSNS, newSNSTransport)
+	Register(PublisherPubSub, newPubSubTransport)
+	Register(PublisherMQTT, newMQTTTransport)
+	Register(PublisherSpool, func(s PublisherSettings) (Transport, error) {
+		return Transport{
+			Connect: func() (ports.OrderEventPublisher, error) {
+				return NewSpoolOrderEventPublisher(s.Events.SpoolPath, s.Logger), nil
+			},
+			NoFallback: true,
+		}, nil
+	})
+	Register(PublisherFile, newFileTransport)
+	Register(PublisherComposite, newCompositeTransport)
+	Register(PublisherOutbox, newOutboxTransport)
+	Register(PublisherMemory, func(PublisherSettings) (Transport, error) {
+		return Transport{
+			Connect: func() (ports.OrderEventPublisher, error) {
+				return NewInMemoryOrderEventPublisher(WithInMemoryCapacity(defaultInMemoryCapacity)), nil
+			},
+			NoFallback: true,
+		}, nil
+	})
+	Register(PublisherNoOp, func(s PublisherSettings) (Transport, error) {
+		return Transport{
+			Connect:    func() (ports.OrderEventPublisher, error) { return newNoOpPublisher(s.Events, s.Logger), nil },
+			NoFallback: true,
+		}, nil
+	})
+}
+
+// newNoOpPublisher skips order events, and stores them for a later replay
+// when ORDER_EVENT_NOOP_STORE_PATH is set.
+func newNoOpPublisher(events config.OrderEvents, logger *slog.Logger) ports.OrderEventPublisher {
+	if events.NoOpStorePath != "" {
+		return NewStoreAndReplayOrderEventPublisher(events.NoOpStorePath, logger)
+	}
+	return &NoOpOrderEventPublisher{}
+}
+
+// newKafkaTransport creates a Kafka producer on KAFKA_ADDR on connecting, a
+// synchronous one with KAFKA_PRODUCER_MODE=sync, and pings the broker before the fallback switches back to it. With
+// KAFKA_SECONDARY_ADDR it creates a producer per region, and either broker
+// being reachable lets the fallback switch back.
+func newKafkaTransport(s PublisherSettings) (Transport, error) {
+	if s.Kafka.Addr == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=kafka requires KAFKA_ADDR")
+	}
+	switch s.Kafka.ProducerMode {
+	case "", KafkaProducerAsync, KafkaProducerSync:
+	case KafkaProducerTransactional:
+		if s.Kafka.TransactionalID == "" {
+			return Transport{}, fmt.Errorf("KAFKA_PRODUCER_MODE=transactional requires KAFKA_TRANSACTIONAL_ID")
+		}
+	default:
+		return Transport{}, fmt.Errorf("invalid KAFKA_PRODUCER_MODE %q, expected async, sync or transactional", s.Kafka.ProducerMode)
+	}
+	switch s.Kafka.MessageKey {
+	case "", KafkaKeyNone, KafkaKeyOrderID:
+	default:
+		return Transport{}, fmt.Errorf("invalid KAFKA_MESSAGE_KEY %q, expected none or order_id", s.Kafka.MessageKey)
+	}
+	switch s.Kafka.AckMode {
+	case "", KafkaAckWait:
+	case KafkaAckBackground:
+		if s.Kafka.ProducerMode != "" && s.Kafka.ProducerMode != KafkaProducerAsync {
+			return Transport{}, fmt.Errorf("KAFKA_ACK_MODE=background requires KAFKA_PRODUCER_MODE=async")
+		}
+	default:
+		return Transport{}, fmt.Errorf("invalid KAFKA_ACK_MODE %q, expected wait or background", s.Kafka.AckMode)
+	}
+	if s.Kafka.Partitioner != "" && !kafka.ValidPartitioner(s.Kafka.Partitioner) {
+		return Transport{}, fmt.Errorf("invalid KAFKA_PARTITIONER %q, expected hash, random or roundrobin", s.Kafka.Partitioner)
+	}
+	opts := append(kafkaOptions(s.Kafka), s.KafkaOptions...)
+	var producerOpts []kafka.ProducerOption
+	if s.Kafka.Partitioner != "" {
+		producerOpts = append(producerOpts, kafka.WithPartitioner(s.Kafka.Partitioner))
+	}
+	if s.Kafka.Idempotent {
+		producerOpts = append(producerOpts, kafka.WithIdempotence())
+	}
+	if s.Kafka.Batching() {
+		producerOpts = append(producerOpts, kafka.WithBatching(s.Kafka.BatchMessages, s.Kafka.BatchBytes, s.Kafka.BatchLinger))
+	}
+	if s.Kafka.ProducerTracing {
+		producerOpts = append(producerOpts, kafka.WithProducerInterceptors(ProducerInterceptor{}))
+	}
+	connect := func(brokers, region string) func() (ports.OrderEventPublisher, error) {
+		return func() (ports.OrderEventPublisher, error) {
+			regionOpts := append(slices.Clip(opts), WithBrokers(brokers), WithRegion(region))
+			if s.Kafka.ProducerMode == KafkaProducerTransactional {
+				producer, err := kafka.CreateTransactionalProducer([]string{brokers}, s.Logger, publisherTransactionalID(s.Kafka.TransactionalID, region), producerOpts...)
+				if err != nil {
+					return nil, errcode.Errorf(errcode.KafkaProduceFailed, "failed to create kafka producer: %w", err)
+				}
+				return NewKafkaTransactionalOrderEventPublisher(producer, s.Logger, regionOpts...), nil
+			}
+			if s.Kafka.ProducerMode == KafkaProducerSync {
+				producer, err := kafka.CreateSyncProducer([]string{brokers}, s.Logger, producerOpts...)
+				if err != nil {
+					return nil, errcode.Errorf(errcode.KafkaProduceFailed, "failed to create kafka producer: %w", err)
+				}
+				return NewKafkaSyncOrderEventPublisher(producer, s.Logger, regionOpts...), nil
+			}
+			producer, err := kafka.CreateKafkaProducer([]string{brokers}, s.Logger, producerOpts...)
+			if err != nil {
+				return nil, errcode.Errorf(errcode.KafkaProduceFailed, "failed to create kafka producer: %w", err)
+			}
+			return NewKafkaOrderEventPublisher(producer, s.Logger, regionOpts...), nil
+		}
+	}
+	ping := func(brokers string) func(context.Context) error {
+		return func(ctx context.Context) error { return kafka.Ping(ctx, []string{brokers}) }
+	}
+	if !s.Kafka.MultiRegion() {
+		return Transport{Connect: connect(s.Kafka.Addr, s.Kafka.Region), Check: ping(s.Kafka.Addr)}, nil
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			return connectRegions(s,
+				Region{Name: s.Kafka.Region}, connect(s.Kafka.Addr, s.Kafka.Region),
+				Region{Name: s.Kafka.SecondaryRegion}, connect(s.Kafka.SecondaryAddr, s.Kafka.SecondaryRegion),
+				ping(s.Kafka.Addr),
+			)
+		},
+		Check: func(ctx context.Context) error {
+			err := kafka.Ping(ctx, []string{s.Kafka.Addr})
+			if err == nil {
+				return nil
+			}
+			return errors.Join(err, kafka.Ping(ctx, []string{s.Kafka.SecondaryAddr}))
+		},
+	}, nil
+}
+
+// publisherTransactionalID returns the transactional ID of the publisher of
+// region, which differs from the outbox's id and from the other region's, so
+// that neither producer fences off another.
+func publisherTransactionalID(id, region string) string {
+	id += "-publisher"
+	if region != "" {
+		id += "-" + region
+	}
+	return id
+}
+
+// connectRegions connects the publishers of the primary and secondary regions
+// and fails over between them. A region that cannot connect yet connects on
+// its first publish, starting failed over if it is the primary. It fails if
+// neither region can connect.
+func connectRegions(s PublisherSettings, primary Region, connectPrimary func() (ports.OrderEventPublisher, error), secondary Region, connectSecondary func() (ports.OrderEventPublisher, error), check func(context.Context) error) (ports.OrderEventPublisher, error) {
+	opts := []RegionFailoverOption{
+		WithFailoverThreshold(s.Kafka.FailoverAfter),
+		WithFailback(check, s.Kafka.FailbackInterval),
+	}
+	var primaryErr, secondaryErr error
+	primary.Publisher, primaryErr = connectPrimary()
+	secondary.Publisher, secondaryErr = connectSecondary()
+	switch {
+	case primaryErr != nil && secondaryErr != nil:
+		return nil, errors.Join(primaryErr, secondaryErr)
+	case primaryErr != nil:
+		s.Logger.Warn(fmt.Sprintf("region %s unreachable, publishing order events to region %s until it recovers: %v", primary.Name, secondary.Name, primaryErr))
+		primary.Publisher = &connectingOrderEventPublisher{connect: connectPrimary}
+		opts = append(opts, WithFailedOver())
+	case secondaryErr != nil:
+		s.Logger.Warn(fmt.Sprintf("region %s unreachable, order events cannot fail over to it until it recovers: %v", secondary.Name, secondaryErr))
+		secondary.Publisher = &connectingOrderEventPublisher{connect: connectSecondary}
+	}
+	return NewRegionFailoverOrderEventPublisher(primary, secondary, s.Logger, opts...), nil
+}
+
+// newWebhookTransport POSTs order events to every ORDER_EVENT_WEBHOOK_URL,
+// signed with ORDER_EVENT_WEBHOOK_SECRET when it is set.
+func newWebhookTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.Webhook
+	if len(cfg.URLs) == 0 {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=webhook requires ORDER_EVENT_WEBHOOK_URL")
+	}
+	opts := []WebhookPublisherOption{WithWebhookRetries(cfg.MaxAttempts, cfg.Backoff)}
+	if cfg.Secret != "" {
+		opts = append(opts, WithWebhookSecret(cfg.Secret))
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			return NewWebhookOrderEventPublisher(cfg.URLs, nil, s.Logger, opts...), nil
+		},
+	}, nil
+}
+
+// newFileTransport appends order events to ORDER_EVENT_FILE_PATH in
+// ORDER_EVENT_FILE_FORMAT. Like the spool, it has no fallback.
+func newFileTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.File
+	if cfg.Path == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=file requires ORDER_EVENT_FILE_PATH")
+	}
+	format := cfg.Format
+	if format == "" {
+		format = FileFormatJSON
+	}
+	if format != FileFormatJSON && format != FileFormatProtobuf {
+		return Transport{}, fmt.Errorf("invalid ORDER_EVENT_FILE_FORMAT %q, expected json or protobuf", cfg.Format)
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			return NewFileOrderEventPublisher(cfg.Path, s.Logger,
+				WithFileFormat(format),
+				WithFileRotation(int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups),
+			), nil
+		},
+		NoFallback: true,
+	}, nil
+}
+
+// outboxDriver is the database/sql driver of ORDER_EVENT_OUTBOX_DSN.
+const outboxDriver = "pgx"
+
+// outboxPingTimeout bounds the ping of the outbox database on connecting.
+const outboxPingTimeout = 5 * time.Second
+
+// OpenOutboxOrderEventPublisher opens the Postgres database of
+// ORDER_EVENT_OUTBOX_DSN and returns a publisher to its ORDER_EVENT_OUTBOX_TABLE,
+// which closes the database with it.
+func OpenOutboxOrderEventPublisher(events config.OrderEvents) (*OutboxOrderEventPublisher, error) {
+	if events.OutboxDSN == "" {
+		return nil, fmt.Errorf("the outbox requires ORDER_EVENT_OUTBOX_DSN")
+	}
+	db, err := sql.Open(outboxDriver, events.OutboxDSN)
+	if err != nil {
+		return nil, errcode.Errorf(errcode.OutboxWriteFailed, "failed to open the outbox database: %w", err)
+	}
+	outbox := NewOutboxOrderEventPublisher(db, OutboxTable{Name: events.OutboxTable, Postgres: true})
+	outbox.closeDB = true
+	return outbox, nil
+}
+
+// newOutboxTransport writes order events to the outbox table of
+// ORDER_EVENT_OUTBOX_DSN, from which the relay of the service publishes them
+// through ORDER_EVENT_OUTBOX_RELAY_PUBLISHER. The database must answer a ping
+// before the fallback switches back to it.
+func newOutboxTransport(s PublisherSettings) (Transport, error) {
+	if s.Events.OutboxDSN == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=outbox requires ORDER_EVENT_OUTBOX_DSN")
+	}
+	relay := strings.ToLower(s.Events.OutboxRelayPublisher)
+	if _, ok := publisherFactory(relay); !ok || relay == PublisherOutbox || relay == PublisherComposite {
+		return Transport{}, fmt.Errorf("invalid ORDER_EVENT_OUTBOX_RELAY_PUBLISHER %q, expected one of %s other than outbox and composite", s.Events.OutboxRelayPublisher, strings.Join(Publishers(), ", "))
+	}
+	connect := func() (ports.OrderEventPublisher, error) {
+		outbox, err := OpenOutboxOrderEventPublisher(s.Events)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), outboxPingTimeout)
+		defer cancel()
+		if err := outbox.Ping(ctx); err != nil {
+			outbox.Close(ctx)
+			return nil, errcode.Errorf(errcode.OutboxWriteFailed, "outbox database unreachable: %w", err)
+		}
+		return outbox, nil
+	}
+	return Transport{
+		Connect: connect,
+		Check: func(ctx context.Context) error {
+			publisher, err := connect()
+			if err != nil {
+				return err
+			}
+			return closeIfLifecycle(ctx, publisher)
+		},
+	}, nil
+}
+
+// newNATSTransport publishes order events to NATS_SUBJECT of NATS_STREAM on
+// the server at NATS_URL, creating the stream on connecting if it does not
+// exist. The server must answer a new connection before the fallback
+// switches back to it.
+func newNATSTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.NATS
+	if cfg.URL == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=nats requires NATS_URL")
+	}
+	ackWait := cfg.AckWait
+	if ackWait <= 0 {
+		ackWait = defaultNATSAckWait
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			conn, err := nats.Connect(cfg.URL, nats.Name("checkout"))
+			if err != nil {
+				return nil, errcode.Errorf(errcode.NATSPublishFailed, "failed to connect to NATS at %s: %w", cfg.URL, err)
+			}
+			js, err := jetstream.New(conn)
+			if err == nil {
+				ctx, cancel := context.WithTimeout(context.Background(), ackWait)
+				err = EnsureNATSStream(ctx, js, cfg.Stream, cfg.Subject)
+				cancel()
+			}
+			if err != nil {
+				conn.Close()
+				return nil, errcode.Wrap(errcode.NATSPublishFailed, err)
+			}
+			return NewNATSOrderEventPublisher(js, cfg.Stream, cfg.Subject, s.Logger, WithAckWait(ackWait)), nil
+		},
+		Check: func(context.Context) error {
+			conn, err := nats.Connect(cfg.URL, nats.Name("checkout"))
+			if err != nil {
+				return err
+			}
+			conn.Close()
+			return nil
+		},
+	}, nil
+}
+
+// newSNSTransport publishes order events to SNS_TOPIC_ARN, subscribing the
+// SNS_SQS_QUEUE_ARNS to the topic on connecting. The credentials and region
+// come from the AWS SDK defaults, and SNS_ENDPOINT replaces the endpoint of
+// the region. The topic attributes must be readable before the fallback
+// switches back to it.
+func newSNSTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.SNS
+	if cfg.TopicARN == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=sns requires SNS_TOPIC_ARN")
+	}
+	timeout := cfg.PublishTimeout
+	if timeout <= 0 {
+		timeout = defaultSNSPublishTimeout
+	}
+	newClient := func(ctx context.Context) (*sns.Client, error) {
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, errcode.Errorf(errcode.SNSPublishFailed, "failed to load the AWS configuration: %w", err)
+		}
+		return sns.NewFromConfig(awsCfg, func(o *sns.Options) {
+			if cfg.Endpoint != "" {
+				o.BaseEndpoint = aws.String(cfg.Endpoint)
+			}
+		}), nil
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			client, err := newClient(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := SubscribeSQSQueues(ctx, client, cfg.TopicARN, cfg.QueueARNs); err != nil {
+				return nil, errcode.Wrap(errcode.SNSPublishFailed, err)
+			}
+			return NewSNSOrderEventPublisher(client, cfg.TopicARN, s.Logger, WithSNSPublishTimeout(timeout)), nil
+		},
+		Check: func(ctx context.Context) error {
+			client, err := newClient(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = client.GetTopicAttributes(ctx, &sns.GetTopicAttributesInput{TopicArn: aws.String(cfg.TopicARN)})
+			return err
+		},
+	}, nil
+}
+
+// newPubSubTransport publishes order events to PUBSUB_TOPIC of
+// PUBSUB_PROJECT_ID, with the application default credentials or on the
+// emulator at PUBSUB_EMULATOR_HOST. The topic must exist on connecting and
+// before the fallback switches back to it.
+func newPubSubTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.PubSub
+	if cfg.ProjectID == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=pubsub requires PUBSUB_PROJECT_ID")
+	}
+	timeout := cfg.PublishTimeout
+	if timeout <= 0 {
+		timeout = defaultPubSubPublishTimeout
+	}
+	topic := cfg.Topic
+	if strings.Count(topic, "/") != 3 {
+		topic = fmt.Sprintf("projects/%s/topics/%s", cfg.ProjectID, topic)
+	}
+	// connect returns a client once the topic was found
+	connect := func(ctx context.Context) (*pubsub.Client, error) {
+		client, err := pubsub.NewClient(ctx, cfg.ProjectID)
+		if err != nil {
+			return nil, errcode.Errorf(errcode.PubSubPublishFailed, "failed to create pubsub client: %w", err)
+		}
+		if _, err := client.TopicAdminClient.GetTopic(ctx, &pubsubpb.GetTopicRequest{Topic: topic}); err != nil {
+			client.Close()
+			return nil, errcode.Errorf(errcode.PubSubPublishFailed, "failed to get pubsub topic %s: %w", topic, err)
+		}
+		return client, nil
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			client, err := connect(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return NewPubSubOrderEventPublisher(client, topic, s.Logger, WithPubSubPublishTimeout(timeout)), nil
+		},
+		Check: func(ctx context.Context) error {
+			client, err := connect(ctx)
+			if err != nil {
+				return err
+			}
+			return client.Close()
+		},
+	}, nil
+}
+
+// newMQTTTransport publishes order events to MQTT_TOPIC on the MQTT v5
+// broker at MQTT_URL with MQTT_QOS. The connection manager reconnects on its
+// own once connected; the broker must accept a new connection within
+// MQTT_PUBLISH_TIMEOUT on connecting and before the fallback switches back
+// to it.
+func newMQTTTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.MQTT
+	if cfg.URL == "" {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=mqtt requires MQTT_URL")
+	}
+	serverURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return Transport{}, fmt.Errorf("invalid MQTT_URL %q: %w", cfg.URL, err)
+	}
+	if cfg.QoS < 0 || cfg.QoS > 2 {
+		return Transport{}, fmt.Errorf("invalid MQTT_QOS %d, expected 0, 1 or 2", cfg.QoS)
+	}
+	timeout := cfg.PublishTimeout
+	if timeout <= 0 {
+		timeout = defaultMQTTPublishTimeout
+	}
+	// connect returns a connection manager once the broker accepted the
+	// connection
+	connect := func(ctx context.Context) (*autopaho.ConnectionManager, error) {
+		conn, err := autopaho.NewConnection(context.Background(), autopaho.ClientConfig{
+			ServerUrls:      []*url.URL{serverURL},
+			KeepAlive:       30,
+			ConnectTimeout:  timeout,
+			ConnectUsername: cfg.Username,
+			ConnectPassword: []byte(cfg.Password),
+			ClientConfig:    paho.ClientConfig{ClientID: cfg.ClientID},
+		})
+		if err == nil {
+			err = conn.AwaitConnection(ctx)
+			if err != nil {
+				conn.Disconnect(context.Background())
+			}
+		}
+		if err != nil {
+			return nil, errcode.Errorf(errcode.MQTTPublishFailed, "failed to connect to MQTT broker at %s: %w", serverURL.Redacted(), err)
+		}
+		return conn, nil
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			conn, err := connect(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return NewMQTTOrderEventPublisher(conn, cfg.Topic, s.Logger,
+				WithMQTTQoS(byte(cfg.QoS)),
+				WithMQTTPublishTimeout(timeout),
+			), nil
+		},
+		Check: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			conn, err := connect(ctx)
+			if err != nil {
+				return err
+			}
+			return conn.Disconnect(ctx)
+		},
+	}, nil
+}
+
+// newCompositeTransport publishes order events to each kind of
+// ORDER_EVENT_COMPOSITE_PUBLISHERS, configured by its own variables, in
+// ORDER_EVENT_COMPOSITE_MODE. With all, every publisher must connect, and
+// pass its health check before the fallback switches back; with
+// best_effort, one is enough, and the others connect on their first publish.
+func newCompositeTransport(s PublisherSettings) (Transport, error) {
+	cfg := s.Events.Composite
+	if len(cfg.Publishers) == 0 {
+		return Transport{}, fmt.Errorf("ORDER_EVENT_PUBLISHER=composite requires ORDER_EVENT_COMPOSITE_PUBLISHERS")
+	}
+	mode := cfg.Mode
+	if mode == "" {
+		mode = FanOutAll
+	}
+	if mode != FanOutAll && mode != FanOutBestEffort {
+		return Transport{}, fmt.Errorf("invalid ORDER_EVENT_COMPOSITE_MODE %q, expected all or best_effort", cfg.Mode)
+	}
+	kinds := make([]string, len(cfg.Publishers))
+	transports := make([]Transport, len(cfg.Publishers))
+	for i, kind := range cfg.Publishers {
+		kinds[i] = strings.ToLower(kind)
+		factory, ok := publisherFactory(kind)
+		if !ok || kinds[i] == PublisherComposite {
+			return Transport{}, fmt.Errorf("invalid ORDER_EVENT_COMPOSITE_PUBLISHERS kind %q, expected one of %s other than composite", kind, strings.Join(Publishers(), ", "))
+		}
+		transport, err := factory(s)
+		if err != nil {
+			return Transport{}, err
+		}
+		transports[i] = transport
+	}
+	return Transport{
+		Connect: func() (ports.OrderEventPublisher, error) {
+			targets := make([]CompositeTarget, len(transports))
+			var errs []error
+			for i, transport := range transports {
+				publisher, err := transport.Connect()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", kinds[i], err))
+					publisher = &connectingOrderEventPublisher{connect: transport.Connect}
+				}
+				targets[i] = CompositeTarget{Name: kinds[i], Publisher: publisher}
+			}
+			if len(errs) > 0 && (mode == FanOutAll || len(errs) == len(transports)) {
+				for _, target := range targets {
+					closeIfLifecycle(context.Background(), target.Publisher)
+				}
+				return nil, errors.Join(errs...)
+			}
+			for _, err := range errs {
+				s.Logger.Warn(fmt.Sprintf("composite publisher unreachable, publishing order events to the others until it recovers: %v", err))
+			}
+			return NewCompositeOrderEventPublisher(targets, s.Logger, WithFanOutMode(mode)), nil
+		},
+		Check: func(ctx context.Context) error {
+			var errs []error
+			for i, transport := range transports {
+				if transport.Check == nil {
+					continue
+				}
+				if err := transport.Check(ctx); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", kinds[i], err))
+				}
+			}
+			if mode == FanOutBestEffort && len(errs) < len(transports) {
+				return nil
+			}
+			return errors.Join(errs...)
+		},
+	}, nil
+}
+
+// connectingOrderEventPublisher creates its publisher on the first publish
+// that succeeds in doing so, for a transport that was down on startup.
+type connectingOrderEventPublisher struct {
+	connect func() (ports.OrderEventPublisher, error)
+
+	mu        sync.Mutex
+	publisher ports.OrderEventPublisher
+}
+
+func (c *connectingOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	c.mu.Lock()
+	if c.publisher == nil {
+		publisher, err := c.connect()
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		c.publisher = publisher
+	}
+	publisher := c.publisher
+	c.mu.Unlock()
+	return publisher.PublishOrderCompleted(ctx, order)
+}
+
+func (c *connectingOrderEventPublisher) Close(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return closeIfLifecycle(ctx, c.publisher)
+}
+
+// kafkaOptions returns the options of the Kafka publisher set in config.
+func kafkaOptions(config config.Kafka) []KafkaPublisherOption {
+	opts := []KafkaPublisherOption{
+		WithBrokers(config.Addr),
+		WithSemconvMode(ParseSemconvStabilityOptIn(config.SemconvStabilityOptIn)),
+		WithSlowPublishThreshold(config.SlowPublishThreshold),
+		WithStaticHeaders(config.StaticHeaders()),
+	}
+	if config.Topic != "" {
+		opts = append(opts, WithTopic(config.Topic))
+	}
+	if config.MessageKey == KafkaKeyOrderID {
+		opts = append(opts, WithMessageKey(OrderIDKey))
+	}
+	if config.PublishTimeout > 0 {
+		opts = append(opts, WithDetachedPublishTimeout(config.PublishTimeout))
+	}
+	if config.AckMode == KafkaAckBackground {
+		opts = append(opts, WithBackgroundAck())
+	}
+	return opts
+}
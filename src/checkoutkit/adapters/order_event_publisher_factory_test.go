@@ -0,0 +1,351 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestNewOrderEventPublisherFromConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		events       config.OrderEvents
+		kafka        config.Kafka
+		wantErr      bool
+		wantPrimary  any
+		wantFallback bool
+	}{
+		{name: "noop", events: config.OrderEvents{Publisher: PublisherNoOp, Fallback: PublisherSpool}, wantPrimary: &NoOpOrderEventPublisher{}},
+		{
+			name:        "noop with a store",
+			events:      config.OrderEvents{Publisher: PublisherNoOp, Fallback: PublisherSpool, NoOpStorePath: "skipped.jsonl"},
+			wantPrimary: &StoreAndReplayOrderEventPublisher{},
+		},
+		{name: "memory", events: config.OrderEvents{Publisher: PublisherMemory, Fallback: PublisherSpool}, wantPrimary: &InMemoryOrderEventPublisher{}},
+		{name: "spool", events: config.OrderEvents{Publisher: PublisherSpool, Fallback: PublisherSpool}, wantPrimary: &SpoolOrderEventPublisher{}},
+		{name: "file", events: config.OrderEvents{Publisher: PublisherFile, Fallback: PublisherSpool, File: config.File{Path: "orders.jsonl"}}, wantPrimary: &FileOrderEventPublisher{}},
+		{name: "file without path", events: config.OrderEvents{Publisher: PublisherFile, Fallback: PublisherSpool}, wantErr: true},
+		{name: "file in another format", events: config.OrderEvents{Publisher: PublisherFile, Fallback: PublisherSpool, File: config.File{Path: "orders.avro", Format: "avro"}}, wantErr: true},
+		{
+			name:         "webhook with spool fallback",
+			events:       config.OrderEvents{Publisher: PublisherWebhook, Fallback: PublisherSpool, Webhook: config.Webhook{URLs: []string{"http://consumer/orders"}}},
+			wantFallback: true,
+		},
+		{
+			name:        "webhook without fallback",
+			events:      config.OrderEvents{Publisher: PublisherWebhook, Fallback: PublisherNone, Webhook: config.Webhook{URLs: []string{"http://consumer/orders"}}},
+			wantPrimary: &WebhookOrderEventPublisher{},
+		},
+		{name: "webhook without URL", events: config.OrderEvents{Publisher: PublisherWebhook, Fallback: PublisherSpool}, wantErr: true},
+		{
+			name:         "unreachable nats with spool fallback",
+			events:       config.OrderEvents{Publisher: PublisherNATS, Fallback: PublisherSpool, NATS: config.NATS{URL: "nats://127.0.0.1:1"}},
+			wantFallback: true,
+		},
+		{name: "nats without URL", events: config.OrderEvents{Publisher: PublisherNATS, Fallback: PublisherSpool}, wantErr: true},
+		{
+			name:        "sns without fallback",
+			events:      config.OrderEvents{Publisher: PublisherSNS, Fallback: PublisherNone, SNS: config.SNS{TopicARN: "arn:aws:sns:us-east-1:000000000000:orders"}},
+			wantPrimary: &SNSOrderEventPublisher{},
+		},
+		{name: "sns without topic", events: config.OrderEvents{Publisher: PublisherSNS, Fallback: PublisherSpool}, wantErr: true},
+		{name: "pubsub without project", events: config.OrderEvents{Publisher: PublisherPubSub, Fallback: PublisherSpool}, wantErr: true},
+		{
+			name:         "unreachable mqtt with spool fallback",
+			events:       config.OrderEvents{Publisher: PublisherMQTT, Fallback: PublisherSpool, MQTT: config.MQTT{URL: "mqtt://127.0.0.1:1", Topic: "orders", QoS: 1, PublishTimeout: 50 * time.Millisecond}},
+			wantFallback: true,
+		},
+		{name: "mqtt without URL", events: config.OrderEvents{Publisher: PublisherMQTT, Fallback: PublisherSpool}, wantErr: true},
+		{
+			name:        "composite",
+			events:      config.OrderEvents{Publisher: PublisherComposite, Fallback: PublisherNone, Composite: config.Composite{Publishers: []string{PublisherMemory, PublisherNoOp}, Mode: FanOutAll}},
+			wantPrimary: &CompositeOrderEventPublisher{},
+		},
+		{name: "composite without publishers", events: config.OrderEvents{Publisher: PublisherComposite, Fallback: PublisherSpool}, wantErr: true},
+		{name: "composite of composites", events: config.OrderEvents{Publisher: PublisherComposite, Fallback: PublisherSpool, Composite: config.Composite{Publishers: []string{PublisherComposite}}}, wantErr: true},
+		{
+			name:         "webhook with file fallback",
+			events:       config.OrderEvents{Publisher: PublisherWebhook, Fallback: PublisherFile, Webhook: config.Webhook{URLs: []string{"http://consumer/orders"}}, File: config.File{Path: "orders.jsonl"}},
+			wantFallback: true,
+		},
+		{name: "file fallback without path", events: config.OrderEvents{Publisher: PublisherNoOp, Fallback: PublisherFile}, wantErr: true},
+		{name: "webhook as its own fallback", events: config.OrderEvents{Publisher: PublisherWebhook, Fallback: PublisherWebhook, Webhook: config.Webhook{URLs: []string{"http://consumer/orders"}}}, wantErr: true},
+		{name: "kafka without brokers", events: config.OrderEvents{Publisher: PublisherKafka, Fallback: PublisherSpool}, wantErr: true},
+		{
+			name:    "kafka with unknown producer mode",
+			events:  config.OrderEvents{Publisher: PublisherKafka, Fallback: PublisherSpool},
+			kafka:   config.Kafka{Addr: "127.0.0.1:1", ProducerMode: "batch"},
+			wantErr: true,
+		},
+		{
+			name:    "kafka with unknown partitioner",
+			events:  config.OrderEvents{Publisher: PublisherKafka, Fallback: PublisherSpool},
+			kafka:   config.Kafka{Addr: "127.0.0.1:1", Partitioner: "sticky"},
+			wantErr: true,
+		},
+		{
+			name:    "kafka transactional without transactional ID",
+			events:  config.OrderEvents{Publisher: PublisherKafka, Fallback: PublisherSpool},
+			kafka:   config.Kafka{Addr: "127.0.0.1:1", ProducerMode: KafkaProducerTransactional},
+			wantErr: true,
+		},
+		{
+			name:    "kafka background ack with sync producer",
+			events:  config.OrderEvents{Publisher: PublisherKafka, Fallback: PublisherSpool},
+			kafka:   config.Kafka{Addr: "127.0.0.1:1", ProducerMode: KafkaProducerSync, AckMode: KafkaAckBackground},
+			wantErr: true,
+		},
+		{name: "outbox without DSN", events: config.OrderEvents{Publisher: PublisherOutbox, Fallback: PublisherSpool, OutboxRelayPublisher: PublisherKafka}, wantErr: true},
+		{
+			name:    "outbox relaying to an outbox",
+			events:  config.OrderEvents{Publisher: PublisherOutbox, Fallback: PublisherSpool, OutboxDSN: "postgres://127.0.0.1:1/orders", OutboxRelayPublisher: PublisherOutbox},
+			wantErr: true,
+		},
+		{
+			name:         "unreachable outbox with spool fallback",
+			events:       config.OrderEvents{Publisher: PublisherOutbox, Fallback: PublisherSpool, OutboxDSN: "postgres://127.0.0.1:1/orders?connect_timeout=1", OutboxRelayPublisher: PublisherKafka},
+			wantFallback: true,
+		},
+		{name: "unknown publisher", events: config.OrderEvents{Publisher: "carrier-pigeon", Fallback: PublisherSpool}, wantErr: true},
+		{name: "unknown fallback", events: config.OrderEvents{Publisher: PublisherNoOp, Fallback: "disk"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.events.SpoolPath = filepath.Join(t.TempDir(), "orders.spool")
+
+			chain, err := NewOrderEventPublisherFromConfig(tt.events, tt.kafka, discardLogger())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NewOrderEventPublisherFromConfig() = %T, want an error", chain.OrderEventPublisher)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+			}
+			if (chain.Fallback != nil) != tt.wantFallback {
+				t.Errorf("Fallback = %v, want a fallback: %v", chain.Fallback, tt.wantFallback)
+			}
+			if tt.wantPrimary != nil && !sameType(chain.OrderEventPublisher, tt.wantPrimary) {
+				t.Errorf("publisher = %T, want %T", chain.OrderEventPublisher, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestNewOrderEventPublisherFromConfigComposite(t *testing.T) {
+	for _, tt := range []struct {
+		mode        string
+		wantHealthy bool
+	}{
+		{mode: FanOutAll, wantHealthy: false},
+		{mode: FanOutBestEffort, wantHealthy: true},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			events := config.OrderEvents{
+				Publisher: PublisherComposite,
+				Fallback:  PublisherSpool,
+				SpoolPath: filepath.Join(t.TempDir(), "orders.spool"),
+				Composite: config.Composite{Publishers: []string{PublisherMemory, PublisherNATS}, Mode: tt.mode},
+				NATS:      config.NATS{URL: "nats://127.0.0.1:1"},
+			}
+			chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+			if err != nil {
+				t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+			}
+			if got := chain.Fallback.Healthy(); got != tt.wantHealthy {
+				t.Errorf("Healthy() = %v with nats unreachable, want %v", got, tt.wantHealthy)
+			}
+		})
+	}
+}
+
+func TestNewOrderEventPublisherFromConfigPubSub(t *testing.T) {
+	srv, _ := newTestPubSub(t)
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+	events := config.OrderEvents{
+		Publisher: PublisherPubSub,
+		Fallback:  PublisherSpool,
+		SpoolPath: filepath.Join(t.TempDir(), "orders.spool"),
+		PubSub:    config.PubSub{ProjectID: "checkout", Topic: "orders", PublishTimeout: time.Second},
+	}
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	defer chain.Close(context.Background())
+	if !chain.Fallback.Healthy() || !sameType(chain.Fallback.primary, &PubSubOrderEventPublisher{}) {
+		t.Errorf("primary = %T, want a healthy Pub/Sub publisher", chain.Fallback.primary)
+	}
+
+	// A topic that does not exist starts the chain degraded on the fallback
+	events.PubSub.Topic = "missing"
+	chain, err = NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	defer chain.Close(context.Background())
+	if chain.Fallback.Healthy() {
+		t.Error("chain did not start degraded on the fallback without the topic")
+	}
+}
+
+// sameType reports whether a and b have the same dynamic type.
+func sameType(a, b any) bool {
+	return a != nil && b != nil && reflect.TypeOf(a) == reflect.TypeOf(b)
+}
+
+func TestNewOrderEventPublisherFromConfigFallsBackWithoutKafka(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "orders.spool")
+	events := config.OrderEvents{Publisher: PublisherKafka, Fallback: PublisherSpool, SpoolPath: spool}
+
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{Addr: "127.0.0.1:1"}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	if chain.Kafka != nil {
+		t.Error("Kafka publisher created without a reachable broker")
+	}
+	if err := chain.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if _, err := os.Stat(spool); err != nil {
+		t.Errorf("order was not spooled: %v", err)
+	}
+	if chain.Fallback == nil || chain.Fallback.Healthy() {
+		t.Error("chain did not start degraded on the fallback")
+	}
+}
+
+func TestNewOrderEventPublisherFromConfigReplaysTheSpool(t *testing.T) {
+	events := config.OrderEvents{
+		Publisher:           PublisherWebhook,
+		Webhook:             config.Webhook{URLs: []string{"http://127.0.0.1:1"}},
+		Fallback:            PublisherSpool,
+		SpoolPath:           filepath.Join(t.TempDir(), "orders.spool"),
+		SpoolReplayInterval: time.Hour,
+	}
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	if chain.Replayer == nil {
+		t.Fatal("Replayer = nil with a spool fallback")
+	}
+	if err := chain.Close(context.Background()); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+
+	events.SpoolReplayInterval = 0
+	if chain, _ := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger()); chain.Replayer != nil {
+		t.Error("Replayer created with ORDER_EVENT_SPOOL_REPLAY_INTERVAL=0")
+	}
+}
+
+func TestConnectingOrderEventPublisher(t *testing.T) {
+	connectErr := errors.New("kafka unreachable")
+	primary := &recordingPublisher{}
+	var connects int
+	pub := &connectingOrderEventPublisher{connect: func() (ports.OrderEventPublisher, error) {
+		connects++
+		if connectErr != nil {
+			return nil, connectErr
+		}
+		return primary, nil
+	}}
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != connectErr {
+		t.Errorf("PublishOrderCompleted() = %v, want %v", err, connectErr)
+	}
+	connectErr = nil
+	for range 2 {
+		if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+	}
+	if connects != 2 || len(primary.orders) != 2 {
+		t.Errorf("connected %d times and published %d orders, want 2 and 2", connects, len(primary.orders))
+	}
+}
+
+func TestConnectRegions(t *testing.T) {
+	unreachable := errors.New("kafka unreachable")
+	s := PublisherSettings{Kafka: config.Kafka{FailoverAfter: 1, FailbackInterval: time.Hour}, Logger: discardLogger()}
+	connect := func(pub ports.OrderEventPublisher, err error) func() (ports.OrderEventPublisher, error) {
+		return func() (ports.OrderEventPublisher, error) { return pub, err }
+	}
+	primary, secondary := &recordingPublisher{}, &recordingPublisher{}
+
+	pub, err := connectRegions(s, Region{Name: "us-east-1"}, connect(nil, unreachable), Region{Name: "eu-west-1"}, connect(secondary, nil), nil)
+	if err != nil {
+		t.Fatalf("connectRegions(primary unreachable) = %v", err)
+	}
+	if got := pub.(*RegionFailoverOrderEventPublisher).ActiveRegion(); got != "eu-west-1" {
+		t.Errorf("ActiveRegion() = %q with the primary region unreachable, want eu-west-1", got)
+	}
+
+	pub, err = connectRegions(s, Region{Name: "us-east-1"}, connect(primary, nil), Region{Name: "eu-west-1"}, connect(nil, unreachable), nil)
+	if err != nil {
+		t.Fatalf("connectRegions(secondary unreachable) = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil || len(primary.orders) != 1 {
+		t.Errorf("PublishOrderCompleted() = %v and the primary region got %d orders, want nil and 1", err, len(primary.orders))
+	}
+
+	if _, err := connectRegions(s, Region{Name: "us-east-1"}, connect(nil, unreachable), Region{Name: "eu-west-1"}, connect(nil, unreachable), nil); !errors.Is(err, unreachable) {
+		t.Errorf("connectRegions(both unreachable) = %v, want %v", err, unreachable)
+	}
+}
+
+func TestPublisherChainPause(t *testing.T) {
+	events := config.OrderEvents{Publisher: PublisherNoOp, Fallback: PublisherNone}
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	if err := chain.Pause(); err == nil {
+		t.Error("Pause() without a spool fallback = nil, want an error")
+	}
+
+	events = config.OrderEvents{
+		Publisher: PublisherWebhook,
+		Webhook:   config.Webhook{URLs: []string{"http://127.0.0.1:1"}},
+		Fallback:  PublisherSpool,
+		SpoolPath: filepath.Join(t.TempDir(), "orders.spool"),
+	}
+	chain, err = NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	defer chain.Close(context.Background())
+	if err := chain.Pause(); err != nil {
+		t.Fatalf("Pause() = %v", err)
+	}
+	if err := chain.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if depth, _ := chain.Spool.Depth(); depth != 1 || !chain.Paused() {
+		t.Errorf("Spool.Depth() = %d with Paused() = %v, want the order spooled while paused", depth, chain.Paused())
+	}
+	if last := chain.LastFailure(); last.Err != nil {
+		t.Errorf("LastFailure() = %v while paused, want none", last.Err)
+	}
+
+	// The webhook is unreachable, so the first publish after Resume fails over
+	chain.Resume()
+	if err := chain.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if last := chain.LastFailure(); last.Err == nil {
+		t.Error("LastFailure() = nil after the webhook failed")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// PublisherSettings is the configuration a PublisherFactory creates its
+// publisher from.
+type PublisherSettings struct {
+	Events config.OrderEvents
+	Kafka  config.Kafka
+	Logger *slog.Logger
+	// KafkaOptions are the options NewOrderEventPublisherFromConfig was given
+	// for the Kafka publisher
+	KafkaOptions []KafkaPublisherOption
+}
+
+// Transport is the primary publisher of a kind, as its PublisherFactory
+// creates it.
+type Transport struct {
+	// Connect creates the publisher, and is required. When it fails and a
+	// fallback is configured, the chain starts degraded on the fallback and
+	// calls Connect again on the next publish.
+	Connect func() (ports.OrderEventPublisher, error)
+	// Check reports whether the transport is reachable before the fallback
+	// switches back to it, nil to switch back once the recheck interval
+	// passed
+	Check func(context.Context) error
+	// NoFallback uses the publisher without the fallback, for publishers that
+	// do not fail such as the spool
+	NoFallback bool
+}
+
+// PublisherFactory creates the transport of a publisher kind from settings.
+// It returns an error for settings the kind cannot publish with, such as a
+// missing address, and leaves connecting to Transport.Connect.
+type PublisherFactory func(settings PublisherSettings) (Transport, error)
+
+var publisherFactories = struct {
+	sync.RWMutex
+	byKind map[string]PublisherFactory
+}{byKind: map[string]PublisherFactory{}}
+
+// Register makes factory create the publishers of kind, which
+// NewOrderEventPublisherFromConfig selects with ORDER_EVENT_PUBLISHER=kind.
+// Kinds are matched ignoring case. Like database/sql.Register, it is meant to
+// be called from an init function, and panics if factory is nil or kind is
+// empty or already registered.
+//
+// The kafka, webhook, nats, sns, pubsub, mqtt, spool, file, memory,
+// composite and noop publishers are registered by this package.
+func Register(kind string, factory PublisherFactory) {
+	kind = strings.ToLower(kind)
+	publisherFactories.Lock()
+	defer publisherFactories.Unlock()
+	if factory == nil {
+		panic("adapters: Register factory of " + kind + " is nil")
+	}
+	if kind == "" {
+		panic("adapters: Register called without a kind")
+	}
+	if _, dup := publisherFactories.byKind[kind]; dup {
+		panic("adapters: Register called twice for " + kind)
+	}
+	publisherFactories.byKind[kind] = factory
+}
+
+// Publishers returns the registered publisher kinds in alphabetical order.
+func Publishers() []string {
+	publisherFactories.RLock()
+	defer publisherFactories.RUnlock()
+	kinds := make([]string, 0, len(publisherFactories.byKind))
+	for kind := range publisherFactories.byKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// publisherFactory returns the factory registered for kind.
+func publisherFactory(kind string) (PublisherFactory, bool) {
+	publisherFactories.RLock()
+	defer publisherFactories.RUnlock()
+	factory, ok := publisherFactories.byKind[strings.ToLower(kind)]
+	return factory, ok
+}
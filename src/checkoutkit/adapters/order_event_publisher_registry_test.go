@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/config"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// registeredTransport is the transport of the "registered" publisher kind,
+// set by each test using it.
+var registeredTransport Transport
+
+func init() {
+	Register("Registered", func(PublisherSettings) (Transport, error) { return registeredTransport, nil })
+}
+
+func useRegisteredTransport(t *testing.T, transport Transport) {
+	registeredTransport = transport
+	t.Cleanup(func() { registeredTransport = Transport{} })
+}
+
+func TestRegisteredPublisher(t *testing.T) {
+	primary := &recordingPublisher{}
+	useRegisteredTransport(t, Transport{Connect: func() (ports.OrderEventPublisher, error) { return primary, nil }})
+	events := config.OrderEvents{Publisher: "registered", Fallback: PublisherSpool, SpoolPath: filepath.Join(t.TempDir(), "orders.spool")}
+
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	if chain.Fallback == nil || chain.Kafka != nil {
+		t.Errorf("chain = %+v, want the registered publisher behind the fallback", chain)
+	}
+	if err := chain.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(primary.orders) != 1 {
+		t.Errorf("registered publisher got %d orders, want 1", len(primary.orders))
+	}
+}
+
+func TestRegisteredPublisherStartsDegraded(t *testing.T) {
+	connectErr := errors.New("unreachable")
+	primary := &recordingPublisher{}
+	useRegisteredTransport(t, Transport{Connect: func() (ports.OrderEventPublisher, error) {
+		if connectErr != nil {
+			return nil, connectErr
+		}
+		return primary, nil
+	}})
+	events := config.OrderEvents{Publisher: "registered", Fallback: PublisherNoOp}
+
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	if chain.Fallback.Healthy() {
+		t.Error("chain did not start degraded on the fallback")
+	}
+
+	events.Fallback = PublisherNone
+	if _, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger()); !errors.Is(err, connectErr) {
+		t.Errorf("NewOrderEventPublisherFromConfig() without a fallback = %v, want %v", err, connectErr)
+	}
+}
+
+func TestRegisteredPublisherWithoutFallback(t *testing.T) {
+	primary := &recordingPublisher{}
+	useRegisteredTransport(t, Transport{
+		Connect:    func() (ports.OrderEventPublisher, error) { return primary, nil },
+		NoFallback: true,
+	})
+	events := config.OrderEvents{Publisher: "registered", Fallback: PublisherNoOp}
+
+	chain, err := NewOrderEventPublisherFromConfig(events, config.Kafka{}, discardLogger())
+	if err != nil {
+		t.Fatalf("NewOrderEventPublisherFromConfig() = %v", err)
+	}
+	if chain.Fallback != nil || chain.OrderEventPublisher != primary {
+		t.Errorf("publisher = %T, want the registered publisher alone", chain.OrderEventPublisher)
+	}
+}
+
+func TestPublishers(t *testing.T) {
+	got := Publishers()
+	for _, kind := range []string{PublisherComposite, PublisherFile, PublisherKafka, PublisherMemory, PublisherMQTT, PublisherNATS, PublisherNoOp, PublisherPubSub, PublisherSNS, PublisherSpool, PublisherWebhook, "registered"} {
+		if !slices.Contains(got, kind) {
+			t.Errorf("Publishers() = %v, want %s", got, kind)
+		}
+	}
+	if !slices.IsSorted(got) {
+		t.Errorf("Publishers() = %v, want them sorted", got)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	factory := func(PublisherSettings) (Transport, error) { return Transport{}, nil }
+	tests := []struct {
+		name    string
+		kind    string
+		factory PublisherFactory
+	}{
+		{name: "duplicate kind", kind: "KAFKA", factory: factory},
+		{name: "empty kind", kind: "", factory: factory},
+		{name: "nil factory", kind: "unregistered", factory: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() did not panic")
+				}
+			}()
+			Register(tt.kind, tt.factory)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// SchemaVersionHeader is the message header with the version of an order
+// event, set from version 2 on. Version 1 events have no header and carry the
+// OrderResult alone, so that their consumers keep working.
+const SchemaVersionHeader = "order.schema.version"
+
+// PaymentsHeader is the version 2 message header listing the payments of an
+// order as JSON, since OrderResult has no field for them.
+const PaymentsHeader = "order.payments"
+
+// FeesHeader is the version 3 message header breaking the shipping cost of an
+// order down into fees as JSON.
+const FeesHeader = "order.fees"
+
+// LatestSchemaVersion is the latest version of the order events.
+const LatestSchemaVersion = 3
+
+// paymentLine is the version 2 JSON shape of a ports.Payment.
+type paymentLine struct {
+	Type          string     `json:"type"`
+	Amount        moneyValue `json:"amount"`
+	TransactionID string     `json:"transactionId"`
+}
+
+// feeBreakdown is the version 3 JSON shape of ports.ShippingFees.
+type feeBreakdown struct {
+	Base       moneyValue `json:"base"`
+	Insurance  moneyValue `json:"insurance"`
+	Surcharges moneyValue `json:"surcharges"`
+}
+
+// OrderExtensions is what the versions after 1 add to the OrderResult of the
+// order events.
+type OrderExtensions struct {
+	// Payments are added in version 2
+	Payments []ports.Payment
+	// Fees are added in version 3
+	Fees ports.ShippingFees
+}
+
+// Headers returns the message headers of the order events in schema version,
+// to set with WithMessageHeaders, and no headers for version 1.
+func (o OrderExtensions) Headers(version int) (map[string]string, error) {
+	if version < 2 {
+		return nil, nil
+	}
+	payments := make([]paymentLine, 0, len(o.Payments))
+	for _, p := range o.Payments {
+		payments = append(payments, paymentLine{
+			Type:          string(p.Type),
+			Amount:        toMoneyValue(p.Amount),
+			TransactionID: p.TransactionID,
+		})
+	}
+	body, err := json.Marshal(payments)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		SchemaVersionHeader: strconv.Itoa(version),
+		PaymentsHeader:      string(body),
+	}
+	if version < 3 {
+		return headers, nil
+	}
+	body, err = json.Marshal(feeBreakdown{
+		Base:       toMoneyValue(o.Fees.Base),
+		Insurance:  toMoneyValue(o.Fees.Insurance),
+		Surcharges: toMoneyValue(o.Fees.Surcharges),
+	})
+	if err != nil {
+		return nil, err
+	}
+	headers[FeesHeader] = string(body)
+	return headers, nil
+}
@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"maps"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestOrderExtensionsHeaders(t *testing.T) {
+	usd := func(units int64, nanos int32) *pb.Money {
+		return &pb.Money{CurrencyCode: "USD", Units: units, Nanos: nanos}
+	}
+	extensions := OrderExtensions{
+		Payments: []ports.Payment{{Type: ports.CardTender, Amount: usd(11, 0), TransactionID: "tx-1"}},
+		Fees:     ports.ShippingFees{Base: usd(5, 0), Insurance: usd(0, 60_000_000), Surcharges: usd(10, 0)},
+	}
+	payments := `[{"type":"card","amount":{"currencyCode":"USD","units":11,"nanos":0},"transactionId":"tx-1"}]`
+	fees := `{"base":{"currencyCode":"USD","units":5,"nanos":0},"insurance":{"currencyCode":"USD","units":0,"nanos":60000000},"surcharges":{"currencyCode":"USD","units":10,"nanos":0}}`
+
+	tests := []struct {
+		version int
+		want    map[string]string
+	}{
+		{version: 1},
+		{version: 2, want: map[string]string{SchemaVersionHeader: "2", PaymentsHeader: payments}},
+		{version: 3, want: map[string]string{SchemaVersionHeader: "3", PaymentsHeader: payments, FeesHeader: fees}},
+	}
+	for _, tt := range tests {
+		got, err := extensions.Headers(tt.version)
+		if err != nil {
+			t.Fatalf("Headers(%d) = %v", tt.version, err)
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("Headers(%d) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// DefaultOutboxTable is the name of the outbox table unless OutboxTable names
+// another.
+const DefaultOutboxTable = "order_event_outbox"
+
+// OutboxTable describes the database table of an OutboxOrderEventPublisher,
+// which the deployment creates with its migrations. Each row is a batch of
+// the events of one order:
+//
+//	CREATE TABLE order_event_outbox (
+//	    id            BIGSERIAL PRIMARY KEY, -- or INTEGER PRIMARY KEY AUTOINCREMENT
+//	    order_id      TEXT NOT NULL,
+//	    payload       BYTEA NOT NULL,        -- JSON array of the events
+//	    trace_context TEXT NOT NULL,         -- JSON of PropagationHeaders
+//	    headers       TEXT NOT NULL,         -- JSON of MessageHeaders
+//	    created_at    TIMESTAMP NOT NULL
+//	);
+type OutboxTable struct {
+	// Name is the table name, DefaultOutboxTable when empty
+	Name string
+	// Postgres numbers the query placeholders $1, $2, ... instead of ?
+	Postgres bool
+}
+
+// outboxQueries are the statements of an OutboxTable.
+type outboxQueries struct {
+	insert, pending, remove, depth string
+}
+
+func (t OutboxTable) queries() outboxQueries {
+	name := t.Name
+	if name == "" {
+		name = DefaultOutboxTable
+	}
+	bind := func(query string) string {
+		if !t.Postgres {
+			return query
+		}
+		var b strings.Builder
+		n := 0
+		for _, r := range query {
+			if r == '?' {
+				n++
+				fmt.Fprintf(&b, "$%d", n)
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	return outboxQueries{
+		insert:  bind("INSERT INTO " + name + " (order_id, payload, trace_context, headers, created_at) VALUES (?, ?, ?, ?, ?)"),
+		pending: bind("SELECT id, order_id, payload, trace_context, headers FROM " + name + " ORDER BY id LIMIT ?"),
+		remove:  bind("DELETE FROM " + name + " WHERE id = ?"),
+		depth:   "SELECT COUNT(*) FROM " + name,
+	}
+}
+
+// outboxEvent is an event of the payload of an outbox row.
+type outboxEvent struct {
+	Type ports.OrderEventType `json:"type"`
+	// Order is the protobuf OrderResult of the event
+	Order      []byte            `json:"order"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// outboxTxKey is the context key of WithOutboxTx.
+type outboxTxKey struct{}
+
+// WithOutboxTx returns a context whose order events an OutboxOrderEventPublisher
+// writes in tx, so that they are committed, or rolled back, with the rest of
+// the order.
+func WithOutboxTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, outboxTxKey{}, tx)
+}
+
+// OutboxOrderEventPublisher implements the OrderEventOutbox port in an outbox
+// table of a database, from which an OutboxRelay publishes the batches
+// through an OrderEventBatchPublisher. It also implements the
+// OrderEventPublisher port, writing each OrderResult as a batch of its own.
+// A batch written in the transaction of its order, with WithOutboxTx, is
+// stored if and only if the order is, so that no event is lost while the
+// broker is down and none is published for an order that was rolled back.
+// Unlike an InMemoryOrderEventOutbox, the batches outlive the process.
+//
+// The rows carry the trace context and message headers of the commit, so
+// that the relayed events join the order's trace.
+type OutboxOrderEventPublisher struct {
+	db      *sql.DB
+	queries outboxQueries
+	now     func() time.Time
+	// closeDB closes db with the publisher, when the publisher opened it
+	closeDB bool
+}
+
+// Compile-time check that OutboxOrderEventPublisher implements OrderEventOutbox
+var _ ports.OrderEventOutbox = (*OutboxOrderEventPublisher)(nil)
+
+// Compile-time check that OutboxOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*OutboxOrderEventPublisher)(nil)
+
+// Compile-time check that OutboxOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*OutboxOrderEventPublisher)(nil)
+
+// NewOutboxOrderEventPublisher creates a publisher to the outbox table of db.
+// Closing the publisher leaves db open.
+func NewOutboxOrderEventPublisher(db *sql.DB, table OutboxTable) *OutboxOrderEventPublisher {
+	return &OutboxOrderEventPublisher{
+		db:      db,
+		queries: table.queries(),
+		now:     time.Now,
+	}
+}
+
+// Begin starts a unit of work for the order with orderID.
+func (o *OutboxOrderEventPublisher) Begin(orderID string) ports.UnitOfWork {
+	return &sqlOutboxUnitOfWork{outbox: o, orderID: orderID}
+}
+
+// PublishOrderCompleted writes the order to the outbox as a batch of one
+// OrderResult event, in the transaction of ctx if it has one.
+func (o *OutboxOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	return o.write(ctx, order.GetOrderId(), []ports.OrderEvent{{Type: ports.OrderCompletedEvent, Order: order}})
+}
+
+// write stores events as one row, in the transaction of ctx if it has one.
+func (o *OutboxOrderEventPublisher) write(ctx context.Context, orderID string, events []ports.OrderEvent) error {
+	stored := make([]outboxEvent, len(events))
+	for i, event := range events {
+		order, err := proto.Marshal(event.Order)
+		if err != nil {
+			return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to protobuf: %w", err)
+		}
+		stored[i] = outboxEvent{Type: event.Type, Order: order, Attributes: event.Attributes}
+	}
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to encode order events: %w", err)
+	}
+	traceContext, err := json.Marshal(PropagationHeaders(ctx))
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to encode trace context: %w", err)
+	}
+	headers, err := json.Marshal(MessageHeaders(ctx))
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to encode message headers: %w", err)
+	}
+
+	args := []any{orderID, payload, string(traceContext), string(headers), o.now().UTC()}
+	if tx, ok := ctx.Value(outboxTxKey{}).(*sql.Tx); ok {
+		_, err = tx.ExecContext(ctx, o.queries.insert, args...)
+	} else {
+		_, err = o.db.ExecContext(ctx, o.queries.insert, args...)
+	}
+	if err != nil {
+		return errcode.Errorf(errcode.OutboxWriteFailed, "failed to write order events to the outbox: %w", err)
+	}
+	return nil
+}
+
+// Depth returns the number of batches in the outbox.
+func (o *OutboxOrderEventPublisher) Depth() (int, error) {
+	var n int
+	if err := o.db.QueryRow(o.queries.depth).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Ping checks that the database of the outbox is reachable.
+func (o *OutboxOrderEventPublisher) Ping(ctx context.Context) error {
+	return o.db.PingContext(ctx)
+}
+
+// Close closes the database if the publisher opened it. The batches stay in
+// the outbox.
+func (o *OutboxOrderEventPublisher) Close(context.Context) error {
+	if !o.closeDB {
+		return nil
+	}
+	return o.db.Close()
+}
+
+// outboxRow is a batch read from the outbox.
+type outboxRow struct {
+	id                    int64
+	orderID               string
+	payload               []byte
+	traceContext, headers string
+}
+
+// events decodes the events of row.
+func (row outboxRow) events() ([]ports.OrderEvent, error) {
+	var stored []outboxEvent
+	if err := json.Unmarshal(row.payload, &stored); err != nil {
+		return nil, err
+	}
+	events := make([]ports.OrderEvent, len(stored))
+	for i, e := range stored {
+		order := &pb.OrderResult{}
+		if err := proto.Unmarshal(e.Order, order); err != nil {
+			return nil, err
+		}
+		events[i] = ports.OrderEvent{Type: e.Type, Order: order, Attributes: e.Attributes}
+	}
+	return events, nil
+}
+
+// pending returns up to limit batches of the outbox, oldest first.
+func (o *OutboxOrderEventPublisher) pending(ctx context.Context, limit int) ([]outboxRow, error) {
+	rows, err := o.db.QueryContext(ctx, o.queries.pending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var pending []outboxRow
+	for rows.Next() {
+		var row outboxRow
+		if err := rows.Scan(&row.id, &row.orderID, &row.payload, &row.traceContext, &row.headers); err != nil {
+			return nil, err
+		}
+		pending = append(pending, row)
+	}
+	return pending, rows.Err()
+}
+
+// remove deletes the batch with id from the outbox.
+func (o *OutboxOrderEventPublisher) remove(ctx context.Context, id int64) error {
+	_, err := o.db.ExecContext(ctx, o.queries.remove, id)
+	return err
+}
+
+// sqlOutboxUnitOfWork records the events of one order for an
+// OutboxOrderEventPublisher.
+type sqlOutboxUnitOfWork struct {
+	outbox    *OutboxOrderEventPublisher
+	orderID   string
+	events    []ports.OrderEvent
+	committed bool
+}
+
+func (u *sqlOutboxUnitOfWork) Record(event ports.OrderEvent) {
+	u.events = append(u.events, event)
+}
+
+// Commit validates the OrderResult of the unit of work, like an
+// InMemoryOrderEventOutbox, and writes the events to the outbox as one row,
+// in the transaction of ctx if it has one.
+func (u *sqlOutboxUnitOfWork) Commit(ctx context.Context) error {
+	if u.committed {
+		return errors.New("unit of work already committed")
+	}
+	u.committed = true
+	if len(u.events) == 0 {
+		return nil
+	}
+	if err := validateOrderEvents(u.events); err != nil {
+		return err
+	}
+	return u.outbox.write(ctx, u.orderID, u.events)
+}
@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+const (
+	testOutboxInsert  = "INSERT INTO order_event_outbox (order_id, payload, trace_context, headers, created_at) VALUES (?, ?, ?, ?, ?)"
+	testOutboxPending = "SELECT id, order_id, payload, trace_context, headers FROM order_event_outbox ORDER BY id LIMIT ?"
+	testOutboxRemove  = "DELETE FROM order_event_outbox WHERE id = ?"
+	testOutboxDepth   = "SELECT COUNT(*) FROM order_event_outbox"
+)
+
+// newTestOutbox returns an outbox publisher to a mock database whose queries
+// must match the expected ones exactly.
+func newTestOutbox(t *testing.T, table OutboxTable) (*OutboxOrderEventPublisher, *sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return NewOutboxOrderEventPublisher(db, table), db, mock
+}
+
+// newTestOutboxRelay returns a relay of outbox to target that only relays
+// when the test calls Relay or Replay.
+func newTestOutboxRelay(t *testing.T, outbox *OutboxOrderEventPublisher, target ports.OrderEventBatchPublisher) *OutboxRelay {
+	t.Helper()
+	relay := NewOutboxRelay(outbox, target, discardLogger(), WithOutboxPollInterval(time.Hour))
+	t.Cleanup(func() { relay.Close(context.Background()) })
+	return relay
+}
+
+// outboxPayload returns the payload of the row of events.
+func outboxPayload(t *testing.T, events ...ports.OrderEvent) []byte {
+	t.Helper()
+	stored := make([]outboxEvent, len(events))
+	for i, event := range events {
+		order, err := proto.Marshal(event.Order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored[i] = outboxEvent{Type: event.Type, Order: order, Attributes: event.Attributes}
+	}
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+// outboxRows returns the mock rows of batches, with ids from 1.
+func outboxRows(t *testing.T, headers string, payloads ...[]byte) *sqlmock.Rows {
+	t.Helper()
+	rows := sqlmock.NewRows([]string{"id", "order_id", "payload", "trace_context", "headers"})
+	for i, payload := range payloads {
+		rows.AddRow(int64(i+1), "order-1", payload, "{}", headers)
+	}
+	return rows
+}
+
+func TestOutboxOrderEventPublisherWritesInTransaction(t *testing.T) {
+	outbox, db, mock := newTestOutbox(t, OutboxTable{})
+	order := testOrder()
+	payload := outboxPayload(t, ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: order})
+	mock.ExpectBegin()
+	mock.ExpectExec(testOutboxInsert).
+		WithArgs(order.GetOrderId(), payload, "{}", `{"tenant":"acme"}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithMessageHeaders(WithOutboxTx(context.Background(), tx), map[string]string{"tenant": "acme"})
+	if err := outbox.PublishOrderCompleted(ctx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxOrderEventPublisherWriteFailure(t *testing.T) {
+	outbox, _, mock := newTestOutbox(t, OutboxTable{Name: "events", Postgres: true})
+	down := errors.New("connection refused")
+	mock.ExpectExec("INSERT INTO events (order_id, payload, trace_context, headers, created_at) VALUES ($1, $2, $3, $4, $5)").
+		WillReturnError(down)
+
+	err := outbox.PublishOrderCompleted(context.Background(), testOrder())
+	if !errors.Is(err, down) || errcode.Of(err) != errcode.OutboxWriteFailed {
+		t.Errorf("PublishOrderCompleted() = %v, want %v with %s", err, down, errcode.OutboxWriteFailed)
+	}
+}
+
+func TestOutboxRelay(t *testing.T) {
+	outbox, _, mock := newTestOutbox(t, OutboxTable{})
+	target := NewInMemoryOrderEventPublisher()
+	relay := newTestOutboxRelay(t, outbox, NewOrderCompletedBatchPublisher(target))
+	payload := outboxPayload(t, testOrderEvents("order-1")...)
+	mock.ExpectQuery(testOutboxPending).WithArgs(defaultOutboxRelayBatchSize).
+		WillReturnRows(outboxRows(t, `{"tenant":"acme"}`, payload, payload))
+	for id := 1; id <= 2; id++ {
+		mock.ExpectExec(testOutboxRemove).WithArgs(int64(id)).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	n, err := relay.Relay(context.Background())
+	if n != 2 || err != nil {
+		t.Fatalf("Relay() = %d, %v, want 2, nil", n, err)
+	}
+	events := target.Events()
+	if len(events) != 2 || events[0].Headers["tenant"] != "acme" {
+		t.Errorf("target received %+v, want 2 events with their message headers", events)
+	}
+}
+
+func TestOutboxRelayKeepsEventsOnFailure(t *testing.T) {
+	outbox, _, mock := newTestOutbox(t, OutboxTable{})
+	target := NewInMemoryOrderEventPublisher()
+	relay := newTestOutboxRelay(t, outbox, NewOrderCompletedBatchPublisher(target))
+	payload := outboxPayload(t, testOrderEvents("order-1")...)
+	// Without an expected DELETE, the mock fails the test if an event is removed
+	mock.ExpectQuery(testOutboxPending).WithArgs(defaultOutboxRelayBatchSize).
+		WillReturnRows(outboxRows(t, "{}", payload, payload))
+
+	down := errcode.Errorf(errcode.KafkaAckTimeout, "no ack")
+	target.FailNext(down)
+	n, err := relay.Relay(context.Background())
+	if n != 0 || !errors.Is(err, down) {
+		t.Errorf("Relay() = %d, %v, want 0, %v", n, err, down)
+	}
+}
+
+func TestOutboxRelayDropsUnreadableEvents(t *testing.T) {
+	outbox, _, mock := newTestOutbox(t, OutboxTable{})
+	target := NewInMemoryOrderEventPublisher()
+	relay := newTestOutboxRelay(t, outbox, NewOrderCompletedBatchPublisher(target))
+	payload := outboxPayload(t, testOrderEvents("order-1")...)
+	mock.ExpectQuery(testOutboxPending).WithArgs(defaultOutboxRelayBatchSize).
+		WillReturnRows(outboxRows(t, "{}", []byte("not json"), payload))
+	for id := 1; id <= 2; id++ {
+		mock.ExpectExec(testOutboxRemove).WithArgs(int64(id)).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	n, err := relay.Relay(context.Background())
+	if n != 1 || err != nil {
+		t.Errorf("Relay() = %d, %v, want the readable batch relayed", n, err)
+	}
+	if target.Len() != 1 {
+		t.Errorf("target received %d events, want 1", target.Len())
+	}
+}
+
+func TestOutboxOrderEventOutboxCommitsOneRow(t *testing.T) {
+	outbox, db, mock := newTestOutbox(t, OutboxTable{})
+	events := testOrderEvents("order-1")
+	mock.ExpectBegin()
+	mock.ExpectExec(testOutboxInsert).
+		WithArgs("order-1", outboxPayload(t, events...), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uow := outbox.Begin("order-1")
+	for _, event := range events {
+		uow.Record(event)
+	}
+	if err := uow.Commit(WithOutboxTx(context.Background(), tx)); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+	if err := uow.Commit(context.Background()); err == nil {
+		t.Error("second Commit() = nil, want an error")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOutboxOrderEventOutboxRejectsInvalidBatches(t *testing.T) {
+	// Without an expected INSERT, the mock fails the test if the batch is written
+	outbox, _, _ := newTestOutbox(t, OutboxTable{})
+	uow := outbox.Begin("order-1")
+	uow.Record(ports.OrderEvent{Type: ports.OrderCompletedEvent, Order: &pb.OrderResult{OrderId: "order-1"}})
+	if err := uow.Commit(context.Background()); errcode.Of(err) != errcode.ValidationFailed {
+		t.Errorf("Commit() = %v, want %s", err, errcode.ValidationFailed)
+	}
+}
+
+func TestOutboxRelayPauseAndReplay(t *testing.T) {
+	outbox, _, mock := newTestOutbox(t, OutboxTable{})
+	publisher := &recordingBatchPublisher{}
+	relay := newTestOutboxRelay(t, outbox, publisher)
+	payload := outboxPayload(t, testOrderEvents("order-1")...)
+
+	// A paused relay only counts the batches
+	relay.Pause()
+	mock.ExpectQuery(testOutboxDepth).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	if pending := relay.Replay(); pending != 1 || !relay.Paused() {
+		t.Fatalf("Replay() while paused = %d, want 1 batch waiting", pending)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := publisher.published(); len(got) != 0 {
+		t.Fatalf("published %d batches while paused, want 0", len(got))
+	}
+
+	// Replay counts the batches while the relay publishes them
+	relay.Resume()
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery(testOutboxPending).WithArgs(defaultOutboxRelayBatchSize).
+		WillReturnRows(outboxRows(t, "{}", payload))
+	mock.ExpectExec(testOutboxRemove).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(testOutboxDepth).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	relay.Replay()
+	deadline := time.Now().Add(time.Second)
+	for len(publisher.published()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := publisher.published(); len(got) != 1 || len(got[0]) != 3 {
+		t.Errorf("published %v after Replay, want the batch of 3 events", got)
+	}
+}
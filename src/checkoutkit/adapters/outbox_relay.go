@@ -0,0 +1,267 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Defaults of an OutboxRelay.
+const (
+	defaultOutboxRelayInterval  = time.Second
+	defaultOutboxRelayBatchSize = 100
+)
+
+// OutboxRelay publishes the batches of an OutboxOrderEventPublisher through
+// an OrderEventBatchPublisher, such as a KafkaOrderEventBatchPublisher or an
+// OrderCompletedBatchPublisher of any publisher, and removes them from the
+// outbox once they are published. It reports the outbox depth on the
+// messaging.publish.backlog gauge and the relayed batches on the
+// messaging.publish.relayed counter. While paused, it keeps the batches in
+// the outbox.
+//
+// Batches are published in the order they were written. A failed publish
+// stops the relay until the next poll, keeping that batch and the later ones
+// in the outbox, except for batches that can never be published, which are
+// logged and removed. A batch whose row could not be removed after its
+// publish is published again, and so is every batch relayed by two relays
+// of the same table at once, so consumers deduplicate by order ID.
+type OutboxRelay struct {
+	outbox    *OutboxOrderEventPublisher
+	target    ports.OrderEventBatchPublisher
+	logger    *slog.Logger
+	interval  time.Duration
+	batchSize int
+
+	// relayMu lets one Relay run at a time
+	relayMu sync.Mutex
+
+	relayed metric.Int64Counter
+	backlog metric.Registration
+
+	paused atomic.Bool
+	// poke is signalled by Replay to relay before the next poll
+	poke     chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Compile-time check that OutboxRelay implements Lifecycle
+var _ ports.Lifecycle = (*OutboxRelay)(nil)
+
+// OutboxRelayOption configures optional behavior of an OutboxRelay.
+type OutboxRelayOption func(*OutboxRelay)
+
+// WithOutboxPollInterval sets how often the outbox is polled for batches. The
+// default is 1s.
+func WithOutboxPollInterval(interval time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.interval = interval
+	}
+}
+
+// WithOutboxBatchSize sets how many batches are read from the outbox at a
+// time. The default is 100.
+func WithOutboxBatchSize(n int) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.batchSize = n
+	}
+}
+
+// NewOutboxRelay creates a relay that publishes the batches of outbox to
+// target and starts polling the outbox in the background.
+func NewOutboxRelay(outbox *OutboxOrderEventPublisher, target ports.OrderEventBatchPublisher, logger *slog.Logger, opts ...OutboxRelayOption) *OutboxRelay {
+	r := &OutboxRelay{
+		outbox:    outbox,
+		target:    target,
+		logger:    logger,
+		interval:  defaultOutboxRelayInterval,
+		batchSize: defaultOutboxRelayBatchSize,
+		poke:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	var err error
+	r.relayed, err = otel.Meter("checkout-order-events").Int64Counter(
+		"messaging.publish.relayed",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Order event batches published from the outbox table."),
+	)
+	if err != nil {
+		logger.Warn("Failed to create relayed counter", slog.String("error", err.Error()))
+	}
+	r.backlog = observeBacklog(BacklogStoreOutboxTable, outbox.Depth, logger)
+
+	go r.run()
+	return r
+}
+
+// Relay publishes the batches of the outbox now, until the outbox is empty
+// or a publish fails, and returns the number of batches published.
+func (r *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	r.relayMu.Lock()
+	defer r.relayMu.Unlock()
+
+	relayed := 0
+	defer func() {
+		if relayed > 0 {
+			r.relayed.Add(ctx, int64(relayed))
+			r.logger.InfoContext(ctx, "Relayed order event batches from the outbox", slog.Int("relayed", relayed))
+		}
+	}()
+	for {
+		rows, err := r.outbox.pending(ctx, r.batchSize)
+		if err != nil {
+			return relayed, r.failed(ctx, fmt.Errorf("failed to read the outbox: %w", err))
+		}
+		for _, row := range rows {
+			published, err := r.publish(ctx, row)
+			if err != nil {
+				return relayed, r.failed(ctx, err)
+			}
+			if err := r.outbox.remove(ctx, row.id); err != nil {
+				return relayed, r.failed(ctx, fmt.Errorf("failed to remove the order events of %s from the outbox: %w", row.orderID, err))
+			}
+			if published {
+				relayed++
+			}
+		}
+		if len(rows) == 0 || len(rows) < r.batchSize {
+			return relayed, nil
+		}
+	}
+}
+
+// publish publishes the batch of row, with the trace context and message
+// headers it was written with. It returns false, and no error, for a batch
+// that can never be published, which is logged and must be removed so that
+// it does not block the later ones.
+func (r *OutboxRelay) publish(ctx context.Context, row outboxRow) (bool, error) {
+	events, err := row.events()
+	var traceContext, headers map[string]string
+	err = errors.Join(
+		err,
+		json.Unmarshal([]byte(row.traceContext), &traceContext),
+		json.Unmarshal([]byte(row.headers), &headers),
+	)
+	if err == nil {
+		publishCtx := WithMessageHeaders(ExtractPropagationHeaders(ctx, traceContext), headers)
+		err = r.target.PublishOrderEvents(publishCtx, events)
+		if err == nil {
+			return true, nil
+		}
+		if !permanentPublishError(err) {
+			return false, err
+		}
+	}
+	r.logger.ErrorContext(ctx, "Dropping order events from the outbox that cannot be published",
+		slog.String("order_id", row.orderID),
+		slog.String("error", err.Error()),
+		errcode.Attr(err),
+	)
+	return false, nil
+}
+
+// failed logs a relay that stopped on err and returns err.
+func (r *OutboxRelay) failed(ctx context.Context, err error) error {
+	r.logger.WarnContext(ctx, "Failed to relay order events from the outbox, keeping them for the next poll",
+		slog.String("error", err.Error()),
+		errcode.Attr(err),
+	)
+	return err
+}
+
+// Pending returns the number of batches in the outbox, 0 if it cannot be
+// read.
+func (r *OutboxRelay) Pending() int {
+	n, err := r.outbox.Depth()
+	if err != nil {
+		r.logger.Warn("Failed to count the order event batches of the outbox", slog.String("error", err.Error()))
+	}
+	return n
+}
+
+// Pause keeps the batches in the outbox until Resume. A relay in progress
+// still publishes its batches.
+func (r *OutboxRelay) Pause() {
+	r.paused.Store(true)
+}
+
+// Resume relays the batches again from the next poll.
+func (r *OutboxRelay) Resume() {
+	r.paused.Store(false)
+}
+
+// Paused reports whether the relay is paused.
+func (r *OutboxRelay) Paused() bool {
+	return r.paused.Load()
+}
+
+// Replay relays the outbox now, instead of at the next poll, unless paused,
+// and returns the number of batches waiting.
+func (r *OutboxRelay) Replay() int {
+	select {
+	case r.poke <- struct{}{}:
+	default:
+	}
+	return r.Pending()
+}
+
+// Close stops polling and waits for a relay in progress, or until ctx is
+// done. The batches still in the outbox are relayed after the next start.
+func (r *OutboxRelay) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		if r.backlog != nil {
+			r.backlog.Unregister()
+		}
+	})
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run relays the outbox every interval, unless paused, until the relay is
+// closed.
+func (r *OutboxRelay) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.poke:
+		case <-r.stop:
+			return
+		}
+		if r.paused.Load() {
+			continue
+		}
+		// A relay is bounded by the interval, so that a hanging publish
+		// cannot stall the relay
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		r.Relay(ctx)
+		cancel()
+	}
+}
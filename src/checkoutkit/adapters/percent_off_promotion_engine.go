@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// PercentOffDiscountCode is the Discount code of PercentOffPromotionEngine.
+const PercentOffDiscountCode = "PERCENT_OFF"
+
+// nanosPerCent is the number of nanos in a hundredth of a currency unit.
+const nanosPerCent = 10_000_000
+
+// PercentOffPromotionEngine implements the PromotionEngine port with a
+// percentage off the unit cost of some products. Discounted costs are rounded
+// down to the cent.
+type PercentOffPromotionEngine struct {
+	percentOff map[string]int64
+}
+
+// Compile-time check that PercentOffPromotionEngine implements PromotionEngine
+var _ ports.PromotionEngine = (*PercentOffPromotionEngine)(nil)
+
+// NewPercentOffPromotionEngine creates an engine taking percentOff, from 0 to
+// 100, off the products it names.
+func NewPercentOffPromotionEngine(percentOff map[string]int64) *PercentOffPromotionEngine {
+	return &PercentOffPromotionEngine{percentOff: percentOff}
+}
+
+// Apply discounts the items of the products on promotion, with a discount line
+// for each of them.
+func (e *PercentOffPromotionEngine) Apply(ctx context.Context, userID string, items []*pb.OrderItem) (ports.Promotion, error) {
+	promotion := ports.Promotion{Items: make([]*pb.OrderItem, 0, len(items))}
+	for _, item := range items {
+		percent, ok := e.percentOff[item.GetItem().GetProductId()]
+		if !ok || percent <= 0 {
+			promotion.Items = append(promotion.Items, item)
+			continue
+		}
+		cost := toNanos(item.GetCost())
+		discounted := cost * (100 - min(percent, 100)) / 100
+		discounted -= discounted % nanosPerCent
+
+		adjusted := proto.Clone(item).(*pb.OrderItem)
+		adjusted.Cost = fromNanos(item.GetCost().GetCurrencyCode(), discounted)
+		promotion.Items = append(promotion.Items, adjusted)
+		promotion.Discounts = append(promotion.Discounts, ports.Discount{
+			Code:      PercentOffDiscountCode,
+			ProductID: item.GetItem().GetProductId(),
+			Amount:    fromNanos(item.GetCost().GetCurrencyCode(), (cost-discounted)*int64(item.GetItem().GetQuantity())),
+		})
+	}
+	return promotion, nil
+}
+
+// toNanos returns m as a number of nanos of its currency.
+func toNanos(m *pb.Money) int64 {
+	return m.GetUnits()*1_000_000_000 + int64(m.GetNanos())
+}
+
+// fromNanos returns nanos of currencyCode as Money, whose units and nanos
+// have the same sign.
+func fromNanos(currencyCode string, nanos int64) *pb.Money {
+	return &pb.Money{
+		CurrencyCode: currencyCode,
+		Units:        nanos / 1_000_000_000,
+		Nanos:        int32(nanos % 1_000_000_000),
+	}
+}
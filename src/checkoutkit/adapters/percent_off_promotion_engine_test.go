@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestPercentOffPromotionEngine(t *testing.T) {
+	items := []*pb.OrderItem{
+		{Item: &pb.CartItem{ProductId: "SKU-1", Quantity: 2}, Cost: &pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 990_000_000}},
+		{Item: &pb.CartItem{ProductId: "SKU-2", Quantity: 1}, Cost: &pb.Money{CurrencyCode: "USD", Units: 5}},
+	}
+	original := []*pb.OrderItem{proto.Clone(items[0]).(*pb.OrderItem), proto.Clone(items[1]).(*pb.OrderItem)}
+	engine := NewPercentOffPromotionEngine(map[string]int64{"SKU-1": 15})
+
+	got, err := engine.Apply(context.Background(), "user-1", items)
+	if err != nil {
+		t.Fatalf("Apply() = %v", err)
+	}
+
+	// 15% off 19.99 is 16.9915, rounded down to 16.99, so 3.00 off each unit
+	want := &pb.Money{CurrencyCode: "USD", Units: 16, Nanos: 990_000_000}
+	if !proto.Equal(got.Items[0].Cost, want) {
+		t.Errorf("SKU-1 cost = %v, want %v", got.Items[0].Cost, want)
+	}
+	if !proto.Equal(got.Items[1], items[1]) {
+		t.Errorf("SKU-2 = %v, want it unchanged", got.Items[1])
+	}
+	wantDiscount := ports.Discount{Code: PercentOffDiscountCode, ProductID: "SKU-1", Amount: &pb.Money{CurrencyCode: "USD", Units: 6}}
+	if len(got.Discounts) != 1 || got.Discounts[0].Code != wantDiscount.Code || got.Discounts[0].ProductID != wantDiscount.ProductID ||
+		!proto.Equal(got.Discounts[0].Amount, wantDiscount.Amount) {
+		t.Errorf("Discounts = %v, want %v", got.Discounts, wantDiscount)
+	}
+	for i := range items {
+		if !proto.Equal(items[i], original[i]) {
+			t.Errorf("Apply() modified item %d: %v", i, items[i])
+		}
+	}
+}
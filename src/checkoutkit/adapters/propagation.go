@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Baggage members set by the load generator and frontend that downstream
+// consumers use to tell synthetic traffic apart from real orders.
+const (
+	BaggageSyntheticRequest = "synthetic_request"
+	BaggageSessionID        = "session.id"
+)
+
+// PropagationHeaders returns the message headers carrying ctx's trace context
+// and baggage. Baggage is always included, even if the global propagator was
+// configured without it, so consumers can filter synthetic traffic.
+func PropagationHeaders(ctx context.Context) map[string]string {
+	carrier := make(map[string]string)
+	messagePropagator().Inject(ctx, &MapCarrier{m: carrier})
+	return carrier
+}
+
+// ExtractPropagationHeaders returns a copy of ctx carrying the trace context
+// and baggage found in headers. It is the consumer-side counterpart of
+// PropagationHeaders.
+func ExtractPropagationHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return messagePropagator().Extract(ctx, &MapCarrier{m: headers})
+}
+
+func messagePropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(otel.GetTextMapPropagator(), propagation.Baggage{})
+}
+
+// baggageAttributes returns span attributes for the well-known baggage members
+// present in ctx.
+func baggageAttributes(ctx context.Context) []attribute.KeyValue {
+	bag := baggage.FromContext(ctx)
+	var attrs []attribute.KeyValue
+	if v := bag.Member(BaggageSyntheticRequest).Value(); v != "" {
+		attrs = append(attrs, attribute.Bool("app.synthetic_request", v == "true"))
+	}
+	if v := bag.Member(BaggageSessionID).Value(); v != "" {
+		attrs = append(attrs, attribute.String("session.id", v))
+	}
+	return attrs
+}
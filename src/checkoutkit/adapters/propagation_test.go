@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func remoteSpanContext(t *testing.T) context.Context {
+	t.Helper()
+	state, err := trace.ParseTraceState("vendor=a1b2,other=c3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		TraceState: state,
+		Remote:     true,
+	})
+	return trace.ContextWithRemoteSpanContext(context.Background(), sc)
+}
+
+func TestPropagationHeadersPreserveTraceState(t *testing.T) {
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+
+	ctx := remoteSpanContext(t)
+	want := trace.SpanContextFromContext(ctx)
+
+	tests := []struct {
+		name    string
+		headers func(map[string]string) map[string]string
+	}{
+		{
+			name:    "as injected",
+			headers: func(h map[string]string) map[string]string { return h },
+		},
+		{
+			name: "capitalized by the producer",
+			headers: func(h map[string]string) map[string]string {
+				return map[string]string{"Traceparent": h["traceparent"], "Tracestate": h["tracestate"]}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := tt.headers(PropagationHeaders(ctx))
+			got := trace.SpanContextFromContext(ExtractPropagationHeaders(context.Background(), headers))
+			if got.TraceID() != want.TraceID() || got.SpanID() != want.SpanID() {
+				t.Errorf("extracted span context = %v, want %v", got, want)
+			}
+			if got.TraceState().String() != want.TraceState().String() {
+				t.Errorf("extracted tracestate = %q, want %q", got.TraceState(), want.TraceState())
+			}
+		})
+	}
+}
+
+func TestPropagationHeadersUseConfiguredPropagators(t *testing.T) {
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
+	))
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+
+	ctx := remoteSpanContext(t)
+	headers := PropagationHeaders(ctx)
+	for _, key := range []string{"traceparent", "tracestate", "x-b3-traceid", "x-b3-spanid", "x-b3-sampled"} {
+		if headers[key] == "" {
+			t.Errorf("PropagationHeaders() = %v, missing %s", headers, key)
+		}
+	}
+
+	// A legacy consumer that only reads B3 continues the same trace
+	legacy := map[string]string{
+		"x-b3-traceid": headers["x-b3-traceid"],
+		"x-b3-spanid":  headers["x-b3-spanid"],
+		"x-b3-sampled": headers["x-b3-sampled"],
+	}
+	got := trace.SpanContextFromContext(ExtractPropagationHeaders(context.Background(), legacy))
+	if got.TraceID() != trace.SpanContextFromContext(ctx).TraceID() {
+		t.Errorf("trace ID extracted from B3 headers = %v, want %v", got.TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+	}
+}
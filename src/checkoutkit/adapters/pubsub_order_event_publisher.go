@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// defaultPubSubPublishTimeout is how long a publish waits for Pub/Sub to
+// accept it by default.
+const defaultPubSubPublishTimeout = 10 * time.Second
+
+// PubSubOrderEventPublisher implements the OrderEventPublisher port on a
+// Google Cloud Pub/Sub topic. Each order is published in protobuf, as on
+// Kafka, with the order ID as its ordering key and the trace context, baggage
+// and message headers as message attributes. Like the Kafka publisher, it
+// waits until Pub/Sub accepted the message, records the outcome on the
+// producer span and the messaging.publish.duration histogram, and counts the
+// messages in flight, accepted and failed in Stats.
+type PubSubOrderEventPublisher struct {
+	client          *pubsub.Client
+	publisher       *pubsub.Publisher
+	topic           string
+	publishTimeout  time.Duration
+	logger          *slog.Logger
+	tracer          trace.Tracer
+	publishDuration metric.Float64Histogram
+
+	inFlight     atomic.Int64
+	acknowledged atomic.Uint64
+	failed       atomic.Uint64
+
+	// closeMu guards closed, so that no publish starts once Close has
+	// started stopping the publisher
+	closeMu   sync.RWMutex
+	closed    bool
+	publishes sync.WaitGroup
+}
+
+// Compile-time check that PubSubOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*PubSubOrderEventPublisher)(nil)
+
+// Compile-time check that PubSubOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*PubSubOrderEventPublisher)(nil)
+
+// PubSubPublisherOption configures optional behavior of a
+// PubSubOrderEventPublisher.
+type PubSubPublisherOption func(*PubSubOrderEventPublisher)
+
+// WithPubSubPublishTimeout sets how long a publish waits for Pub/Sub to
+// accept it, retries of the client included, unless its context ends first.
+// The default is 10s.
+func WithPubSubPublishTimeout(timeout time.Duration) PubSubPublisherOption {
+	return func(p *PubSubOrderEventPublisher) {
+		p.publishTimeout = timeout
+	}
+}
+
+// NewPubSubOrderEventPublisher creates a publisher of orders to topic, the ID
+// of a topic of the client's project or its full name. It enables message
+// ordering on the topic's publisher, and closes client on Close.
+func NewPubSubOrderEventPublisher(client *pubsub.Client, topic string, logger *slog.Logger, opts ...PubSubPublisherOption) *PubSubOrderEventPublisher {
+	p := &PubSubOrderEventPublisher{
+		client:         client,
+		publisher:      client.Publisher(topic),
+		publishTimeout: defaultPubSubPublishTimeout,
+		logger:         logger,
+		tracer:         otel.Tracer("checkout-pubsub-adapter"),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.topic = p.publisher.ID()
+	p.publisher.EnableMessageOrdering = true
+	p.publisher.PublishSettings.Timeout = p.publishTimeout
+
+	var err error
+	p.publishDuration, err = otel.Meter("checkout-pubsub-adapter").Float64Histogram(
+		"messaging.publish.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Time from queuing an order event to its broker acknowledgment."),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		logger.Warn("Failed to create publish duration histogram", slog.String("error", err.Error()))
+	}
+	return p
+}
+
+// PublishOrderCompleted publishes the order to the topic and waits until
+// Pub/Sub accepted it.
+func (p *PubSubOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	p.closeMu.RLock()
+	if p.closed {
+		p.closeMu.RUnlock()
+		return errcode.Errorf(errcode.PublisherClosed, "pubsub publisher is closed")
+	}
+	p.publishes.Add(1)
+	p.closeMu.RUnlock()
+	defer p.publishes.Done()
+
+	data, err := proto.Marshal(order)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to protobuf: %w", err)
+	}
+	msg := &pubsub.Message{
+		Data:        data,
+		Attributes:  map[string]string{},
+		OrderingKey: order.GetOrderId(),
+	}
+	for key, value := range MessageHeaders(ctx) {
+		msg.Attributes[key] = value
+	}
+
+	spanCtx, span := p.tracer.Start(ctx, fmt.Sprintf("%s publish", p.topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.PeerService("pubsub"),
+			semconv.MessagingSystemGCPPubsub,
+			semconv.MessagingDestinationName(p.topic),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingGCPPubsubMessageOrderingKey(msg.OrderingKey),
+		),
+	)
+	defer span.End()
+	span.SetAttributes(baggageAttributes(ctx)...)
+	for key, value := range PropagationHeaders(spanCtx) {
+		msg.Attributes[key] = value
+	}
+
+	queuedAt := time.Now()
+	p.inFlight.Add(1)
+	result := p.publisher.Publish(spanCtx, msg)
+	span.AddEvent(PublishEventQueued, trace.WithTimestamp(queuedAt))
+
+	waitCtx, cancel := context.WithTimeout(spanCtx, p.publishTimeout)
+	defer cancel()
+	id, err := result.Get(waitCtx)
+	duration := time.Since(queuedAt)
+	p.inFlight.Add(-1)
+	if err != nil {
+		code := errcode.PubSubPublishFailed
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			code = errcode.PubSubAckTimeout
+		}
+		err = errcode.Errorf(code, "failed to publish order event to %s: %w", p.topic, err)
+		// Pub/Sub pauses an ordering key after a failure, which would fail
+		// the replay of this order from the fallback
+		p.publisher.ResumePublish(msg.OrderingKey)
+		p.failed.Add(1)
+		span.AddEvent(PublishEventFailed, trace.WithAttributes(errcode.Key.String(string(code))))
+		errcode.RecordSpan(span, err, "Pub/Sub did not accept the message")
+		p.recordDuration(spanCtx, duration, err)
+		p.logger.ErrorContext(spanCtx, "Failed to publish order event",
+			slog.String(string(semconv.MessagingDestinationNameKey), p.topic),
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+		return err
+	}
+	p.acknowledged.Add(1)
+	span.AddEvent(PublishEventAcked, trace.WithAttributes(semconv.MessagingMessageID(id)))
+	p.recordDuration(spanCtx, duration, nil)
+	p.logger.InfoContext(spanCtx, "Successfully published order event",
+		slog.String(string(semconv.MessagingDestinationNameKey), p.topic),
+		slog.String(string(semconv.MessagingMessageIDKey), id),
+		slog.Int64("messaging.gcp_pubsub.duration_ms", duration.Milliseconds()),
+	)
+	return nil
+}
+
+// recordDuration records the duration of a publish with its outcome.
+func (p *PubSubOrderEventPublisher) recordDuration(ctx context.Context, duration time.Duration, err error) {
+	attrs := publishMetricAttributes(semconv.MessagingSystemGCPPubsub, p.topic, "", err)
+	p.publishDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// Stats returns a snapshot of the publisher's acknowledgment counters.
+func (p *PubSubOrderEventPublisher) Stats() PublisherStats {
+	return PublisherStats{
+		Topic:        p.topic,
+		InFlight:     p.inFlight.Load(),
+		Acknowledged: p.acknowledged.Load(),
+		Failed:       p.failed.Load(),
+	}
+}
+
+// Close stops accepting order events, waits until the publishes in progress
+// have their outcome or ctx is done, and then stops the publisher, which
+// sends the messages still batched, and closes the client. Events published
+// after Close fail with PUBLISHER_CLOSED.
+func (p *PubSubOrderEventPublisher) Close(ctx context.Context) error {
+	p.closeMu.Lock()
+	if p.closed {
+		p.closeMu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.closeMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.publishes.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		p.logger.WarnContext(ctx, "Stopping the Pub/Sub publisher with publishes still waiting for acknowledgment",
+			slog.Int64("messaging.gcp_pubsub.in_flight", p.inFlight.Load()),
+		)
+	}
+	p.publisher.Stop()
+	return p.client.Close()
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+const testPubSubTopic = "projects/checkout/topics/orders"
+
+// newTestPubSub returns a fake Pub/Sub server with the orders topic and a
+// client of it.
+func newTestPubSub(t *testing.T, opts ...pstest.ServerReactorOption) (*pstest.Server, *pubsub.Client) {
+	t.Helper()
+	srv := pstest.NewServer(opts...)
+	t.Cleanup(func() { srv.Close() })
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client, err := pubsub.NewClient(context.Background(), "checkout", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("pubsub.NewClient() = %v", err)
+	}
+	if _, err := client.TopicAdminClient.CreateTopic(context.Background(), &pubsubpb.Topic{Name: testPubSubTopic}); err != nil {
+		t.Fatalf("CreateTopic() = %v", err)
+	}
+	return srv, client
+}
+
+func TestPubSubOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	srv, client := newTestPubSub(t)
+	pub := NewPubSubOrderEventPublisher(client, "orders", discardLogger())
+	defer pub.Close(context.Background())
+	member, _ := baggage.NewMember(BaggageSyntheticRequest, "true")
+	bag, _ := baggage.New(member)
+	ctx := WithMessageHeaders(baggage.ContextWithBaggage(context.Background(), bag), map[string]string{SchemaVersionHeader: "2"})
+
+	order := testOrder()
+	if err := pub.PublishOrderCompleted(ctx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("published %d messages, want 1", len(msgs))
+	}
+	msg := msgs[0]
+	var got pb.OrderResult
+	if err := proto.Unmarshal(msg.Data, &got); err != nil || got.GetOrderId() != order.GetOrderId() {
+		t.Errorf("message = %v (%v), want order %s in protobuf", &got, err, order.GetOrderId())
+	}
+	if msg.OrderingKey != order.GetOrderId() {
+		t.Errorf("ordering key = %q, want the order ID %s", msg.OrderingKey, order.GetOrderId())
+	}
+	for _, key := range []string{"traceparent", "baggage", SchemaVersionHeader} {
+		if msg.Attributes[key] == "" {
+			t.Errorf("attribute %s missing from %v", key, msg.Attributes)
+		}
+	}
+
+	span := endedSpan(t, recorder, "orders publish")
+	if span.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("span kind = %v, want producer", span.SpanKind())
+	}
+	if traceparent := msg.Attributes["traceparent"]; traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("traceparent = %s, want the trace of the producer span %s", traceparent, span.SpanContext().TraceID())
+	}
+	if events := span.Events(); len(events) != 2 || events[0].Name != PublishEventQueued || events[1].Name != PublishEventAcked {
+		t.Errorf("span events = %v, want %s and %s", events, PublishEventQueued, PublishEventAcked)
+	}
+	if stats := pub.Stats(); stats.Topic != "orders" || stats.Acknowledged != 1 || stats.InFlight != 0 {
+		t.Errorf("Stats() = %+v, want 1 order acknowledged on orders", stats)
+	}
+}
+
+// pausedPublishes holds every publish to the fake server until released,
+// including the retries of an abandoned one, and then lets the server answer
+// them.
+type pausedPublishes chan struct{}
+
+func (p pausedPublishes) React(any) (bool, any, error) {
+	<-p
+	return false, nil, nil
+}
+
+func TestPubSubOrderEventPublisherFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		topic    string
+		pause    bool
+		wantCode errcode.Code
+	}{
+		{name: "missing topic", topic: "missing", wantCode: errcode.PubSubPublishFailed},
+		{name: "publish timeout", topic: "orders", pause: true, wantCode: errcode.PubSubAckTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paused := make(pausedPublishes)
+			var opts []pstest.ServerReactorOption
+			if tt.pause {
+				opts = append(opts, pstest.ServerReactorOption{FuncName: "Publish", Reactor: paused})
+			}
+			_, client := newTestPubSub(t, opts...)
+			pub := NewPubSubOrderEventPublisher(client, tt.topic, discardLogger(), WithPubSubPublishTimeout(50*time.Millisecond))
+			defer pub.Close(context.Background())
+
+			order := testOrder()
+			err := pub.PublishOrderCompleted(context.Background(), order)
+			if code := errcode.Of(err); code != tt.wantCode {
+				t.Errorf("PublishOrderCompleted() = %v with code %s, want %s", err, code, tt.wantCode)
+			}
+			if stats := pub.Stats(); stats.Failed != 1 {
+				t.Errorf("Stats() = %+v, want 1 order failed", stats)
+			}
+			// The fake server holds its lock until the abandoned publish
+			// is answered
+			close(paused)
+		})
+	}
+}
+
+func TestPubSubOrderEventPublisherResumesOrderingKey(t *testing.T) {
+	srv, client := newTestPubSub(t)
+	pub := NewPubSubOrderEventPublisher(client, "orders", discardLogger())
+	defer pub.Close(context.Background())
+
+	// A failed publish pauses the ordering key, which must not fail the
+	// order once it is published again
+	srv.SetAutoPublishResponse(false)
+	srv.AddPublishResponse(nil, status.Error(codes.PermissionDenied, "pubsub.topics.publish denied"))
+	order := testOrder()
+	if err := pub.PublishOrderCompleted(context.Background(), order); err == nil {
+		t.Fatal("PublishOrderCompleted() = nil, want the failed publish")
+	}
+	srv.SetAutoPublishResponse(true)
+	if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+		t.Errorf("PublishOrderCompleted() again = %v, want the ordering key resumed", err)
+	}
+}
+
+func TestPubSubOrderEventPublisherClose(t *testing.T) {
+	_, client := newTestPubSub(t)
+	pub := NewPubSubOrderEventPublisher(client, "orders", discardLogger())
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want %s", err, errcode.PublisherClosed)
+	}
+}
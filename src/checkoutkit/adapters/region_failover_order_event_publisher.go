@@ -0,0 +1,235 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Defaults of a RegionFailoverOrderEventPublisher.
+const (
+	defaultFailoverThreshold = 5
+	defaultFailbackInterval  = time.Minute
+)
+
+// Region is the order event publisher of a region, such as a
+// KafkaOrderEventPublisher on the region's brokers created WithRegion.
+type Region struct {
+	Name      string
+	Publisher ports.OrderEventPublisher
+}
+
+// RegionFailoverOrderEventPublisher implements the OrderEventPublisher port by
+// publishing to a primary region and failing over to a secondary region on
+// sustained errors.
+//
+// A failed publish on the primary region is returned to the caller until the
+// primary has failed threshold times in a row. Order events then fail over:
+// the failing event and the following ones go to the secondary region. Once
+// the failback interval has passed, the next event probes the primary region
+// again, after its health check passes if there is one, and events fail back
+// to it when the probe succeeds. Every switch is counted on the
+// messaging.publish.failovers metric with the region switched to.
+type RegionFailoverOrderEventPublisher struct {
+	primary   Region
+	secondary Region
+	logger    *slog.Logger
+	threshold int
+	check     func(context.Context) error
+	interval  time.Duration
+	now       func() time.Time
+	failovers metric.Int64Counter
+
+	mu         sync.Mutex
+	failures   int
+	failedOver bool
+	recheckAt  time.Time
+}
+
+// Compile-time check that RegionFailoverOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*RegionFailoverOrderEventPublisher)(nil)
+
+// Compile-time check that RegionFailoverOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*RegionFailoverOrderEventPublisher)(nil)
+
+// RegionFailoverOption configures a RegionFailoverOrderEventPublisher.
+type RegionFailoverOption func(*RegionFailoverOrderEventPublisher)
+
+// WithFailoverThreshold fails over after threshold consecutive failed
+// publishes on the primary region. The default is 5.
+func WithFailoverThreshold(threshold int) RegionFailoverOption {
+	return func(f *RegionFailoverOrderEventPublisher) {
+		if threshold > 0 {
+			f.threshold = threshold
+		}
+	}
+}
+
+// WithFailback probes the primary region every interval while failed over,
+// and only once check passes if it is not nil. The default interval is one
+// minute.
+func WithFailback(check func(context.Context) error, interval time.Duration) RegionFailoverOption {
+	return func(f *RegionFailoverOrderEventPublisher) {
+		f.check = check
+		if interval > 0 {
+			f.interval = interval
+		}
+	}
+}
+
+// WithFailedOver starts failed over to the secondary region, for a primary
+// region that was unreachable on startup.
+func WithFailedOver() RegionFailoverOption {
+	return func(f *RegionFailoverOrderEventPublisher) {
+		f.failedOver = true
+	}
+}
+
+// NewRegionFailoverOrderEventPublisher creates a publisher that fails over
+// from the primary region to the secondary one.
+func NewRegionFailoverOrderEventPublisher(primary, secondary Region, logger *slog.Logger, opts ...RegionFailoverOption) *RegionFailoverOrderEventPublisher {
+	f := &RegionFailoverOrderEventPublisher{
+		primary:   primary,
+		secondary: secondary,
+		logger:    logger,
+		threshold: defaultFailoverThreshold,
+		interval:  defaultFailbackInterval,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.failedOver {
+		f.recheckAt = f.now().Add(f.interval)
+	}
+
+	var err error
+	f.failovers, err = otel.Meter("checkout-kafka-adapter").Int64Counter(
+		"messaging.publish.failovers",
+		metric.WithUnit("{failover}"),
+		metric.WithDescription("Switches of order event publishing between regions, by the region switched to."),
+	)
+	if err != nil {
+		logger.Warn("Failed to create region failover counter", slog.String("error", err.Error()))
+	}
+	return f
+}
+
+// PublishOrderCompleted publishes the order to the primary region unless
+// order events failed over to the secondary region.
+func (f *RegionFailoverOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if f.usePrimary(ctx) {
+		err := f.primary.Publisher.PublishOrderCompleted(ctx, order)
+		if err == nil {
+			f.primarySucceeded(ctx)
+			return nil
+		}
+		if !f.primaryFailed(ctx, err) {
+			return err
+		}
+	}
+	return f.secondary.Publisher.PublishOrderCompleted(ctx, order)
+}
+
+// Close closes the publishers of both regions.
+func (f *RegionFailoverOrderEventPublisher) Close(ctx context.Context) error {
+	return errors.Join(closeIfLifecycle(ctx, f.primary.Publisher), closeIfLifecycle(ctx, f.secondary.Publisher))
+}
+
+// ActiveRegion returns the name of the region order events currently go to.
+func (f *RegionFailoverOrderEventPublisher) ActiveRegion() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failedOver {
+		return f.secondary.Name
+	}
+	return f.primary.Name
+}
+
+// usePrimary reports whether the primary region should be tried, running the
+// health check once the failback interval has passed.
+func (f *RegionFailoverOrderEventPublisher) usePrimary(ctx context.Context) bool {
+	f.mu.Lock()
+	if !f.failedOver {
+		f.mu.Unlock()
+		return true
+	}
+	if f.now().Before(f.recheckAt) {
+		f.mu.Unlock()
+		return false
+	}
+	// Let one event probe the primary region while the others keep using
+	// the secondary
+	f.recheckAt = f.now().Add(f.interval)
+	f.mu.Unlock()
+
+	if f.check == nil {
+		return true
+	}
+	if err := f.check(ctx); err != nil {
+		f.logger.DebugContext(ctx, "primary region still unhealthy",
+			slog.String(string(semconv.CloudRegionKey), f.primary.Name),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+	return true
+}
+
+// primaryFailed counts a failed publish on the primary region and reports
+// whether the event should go to the secondary region.
+func (f *RegionFailoverOrderEventPublisher) primaryFailed(ctx context.Context, err error) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failedOver {
+		f.recheckAt = f.now().Add(f.interval)
+		return true
+	}
+	f.failures++
+	if f.failures < f.threshold {
+		return false
+	}
+	f.failedOver = true
+	f.failures = 0
+	f.recheckAt = f.now().Add(f.interval)
+	f.logger.WarnContext(ctx, fmt.Sprintf("order events failing over from region %s to %s after %d failed publishes", f.primary.Name, f.secondary.Name, f.threshold),
+		slog.String("error", err.Error()),
+		errcode.Attr(err),
+	)
+	f.recordSwitch(ctx, f.secondary.Name, failoverToSecondary)
+	return true
+}
+
+func (f *RegionFailoverOrderEventPublisher) primarySucceeded(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = 0
+	if !f.failedOver {
+		return
+	}
+	f.failedOver = false
+	f.logger.InfoContext(ctx, fmt.Sprintf("order events failed back to region %s", f.primary.Name))
+	f.recordSwitch(ctx, f.primary.Name, failoverToPrimary)
+}
+
+// recordSwitch counts a switch to region and records it on the span of the
+// publish that caused it.
+func (f *RegionFailoverOrderEventPublisher) recordSwitch(ctx context.Context, region, direction string) {
+	f.failovers.Add(ctx, 1, metric.WithAttributes(semconv.CloudRegion(region), failoverKey.String(direction)))
+	trace.SpanFromContext(ctx).AddEvent("order event publisher region "+direction,
+		trace.WithAttributes(semconv.CloudRegion(region)))
+}
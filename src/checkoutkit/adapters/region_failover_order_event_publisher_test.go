@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestRegionFailoverOrderEventPublisher(t *testing.T) {
+	reader := newTestMetrics(t)
+	primary := &recordingPublisher{}
+	secondary := &recordingPublisher{}
+	checkErr := errors.New("broker unreachable")
+	pub := NewRegionFailoverOrderEventPublisher(
+		Region{Name: "us-east-1", Publisher: primary},
+		Region{Name: "eu-west-1", Publisher: secondary},
+		discardLogger(),
+		WithFailoverThreshold(2),
+		WithFailback(func(context.Context) error { return checkErr }, time.Minute),
+	)
+	clock := testdata.NewClock()
+	pub.now = clock.Now
+	ctx := context.Background()
+
+	publish := func(wantErr bool, wantPrimary, wantSecondary int) {
+		t.Helper()
+		if err := pub.PublishOrderCompleted(ctx, testOrder()); (err != nil) != wantErr {
+			t.Fatalf("PublishOrderCompleted() = %v, want an error: %v", err, wantErr)
+		}
+		if len(primary.orders) != wantPrimary || len(secondary.orders) != wantSecondary {
+			t.Fatalf("primary region got %d orders and secondary %d, want %d and %d", len(primary.orders), len(secondary.orders), wantPrimary, wantSecondary)
+		}
+	}
+
+	publish(false, 1, 0)
+
+	// A single failure is returned, the second in a row fails over
+	primary.err = errors.New("kafka down")
+	publish(true, 2, 0)
+	publish(false, 3, 1)
+	if got := pub.ActiveRegion(); got != "eu-west-1" {
+		t.Errorf("ActiveRegion() = %q after failing over, want eu-west-1", got)
+	}
+	publish(false, 3, 2)
+
+	// The health check gates the failback once the interval has passed
+	clock.Advance(time.Minute)
+	publish(false, 3, 3)
+	clock.Advance(time.Minute)
+	primary.err, checkErr = nil, nil
+	publish(false, 4, 3)
+	if got := pub.ActiveRegion(); got != "us-east-1" {
+		t.Errorf("ActiveRegion() = %q after failing back, want us-east-1", got)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	want := map[attribute.Set]int64{
+		attribute.NewSet(semconv.CloudRegion("eu-west-1"), failoverKey.String(failoverToSecondary)): 1,
+		attribute.NewSet(semconv.CloudRegion("us-east-1"), failoverKey.String(failoverToPrimary)):   1,
+	}
+	got := map[attribute.Set]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "messaging.publish.failovers" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				got[dp.Attributes] = dp.Value
+			}
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("messaging.publish.failovers = %v, want %v", got, want)
+	}
+	for set, n := range want {
+		if got[set] != n {
+			t.Errorf("messaging.publish.failovers{%s} = %d, want %d", set.Encoded(attribute.DefaultEncoder()), got[set], n)
+		}
+	}
+}
+
+func TestRegionFailoverOrderEventPublisherStartsFailedOver(t *testing.T) {
+	primary := &recordingPublisher{}
+	secondary := &recordingPublisher{}
+	pub := NewRegionFailoverOrderEventPublisher(
+		Region{Name: "us-east-1", Publisher: primary},
+		Region{Name: "eu-west-1", Publisher: secondary},
+		discardLogger(),
+		WithFailedOver(),
+	)
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(primary.orders) != 0 || len(secondary.orders) != 1 {
+		t.Errorf("primary region got %d orders and secondary %d, want 0 and 1", len(primary.orders), len(secondary.orders))
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// Attributes of the attempt events a RetryingOrderEventPublisher adds to the
+// caller's span.
+const (
+	// PublishAttemptKey is the number of the attempt, from 1
+	PublishAttemptKey = attribute.Key("app.order_event.attempt")
+	// RetryBackoffKey is how long the publisher waits, in milliseconds,
+	// before the next attempt
+	RetryBackoffKey = attribute.Key("app.order_event.retry_backoff_ms")
+)
+
+// RetryPolicy configures a RetryingOrderEventPublisher. The zero value
+// publishes once.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first
+	MaxAttempts int
+	// Backoff is the wait before the first retry
+	Backoff time.Duration
+	// MaxBackoff caps the wait before a retry, unless it is 0
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each retry, 2 when below 1
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, by which each wait is
+	// randomly shortened, so that publishers that failed together do not
+	// retry together
+	Jitter float64
+	// Retryable reports whether a failed publish is retried,
+	// RetryableError when nil
+	Retryable func(error) bool
+}
+
+// backoff returns the wait before the retry that follows attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	wait := float64(p.Backoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 {
+		wait = min(wait, float64(p.MaxBackoff))
+	}
+	wait -= wait * min(max(p.Jitter, 0), 1) * rand.Float64()
+	return time.Duration(wait)
+}
+
+// RetryableError reports whether a failed publish may succeed when retried.
+// Orders that cannot be encoded or break the event contract fail again, a
+// closed publisher stays closed, and a cancelled publish was given up on.
+func RetryableError(err error) bool {
+	if permanentPublishError(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch errcode.Of(err) {
+	case errcode.RoundTripMismatch, errcode.PublisherClosed:
+		return false
+	}
+	return true
+}
+
+// RetryingOrderEventPublisher is a decorator that retries the failed
+// publishes of the wrapped publisher, waiting longer before each retry. A
+// publish is given up after the last attempt, on an error the policy does not
+// retry, or when its context ends, and fails with the error of its last
+// attempt.
+//
+// Each attempt adds an event to the caller's span with PublishAttemptKey and,
+// for a failed attempt, its error code and RetryBackoffKey. A publish whose
+// acknowledgment timed out may have reached the broker, so a retry can
+// publish an order twice and consumers deduplicate by order ID.
+type RetryingOrderEventPublisher struct {
+	next      ports.OrderEventPublisher
+	policy    RetryPolicy
+	logger    *slog.Logger
+	retryable func(error) bool
+}
+
+// Compile-time check that RetryingOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*RetryingOrderEventPublisher)(nil)
+
+// Compile-time check that RetryingOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*RetryingOrderEventPublisher)(nil)
+
+// NewRetryingOrderEventPublisher wraps next with the retries of policy.
+func NewRetryingOrderEventPublisher(next ports.OrderEventPublisher, policy RetryPolicy, logger *slog.Logger) *RetryingOrderEventPublisher {
+	r := &RetryingOrderEventPublisher{
+		next:      next,
+		policy:    policy,
+		logger:    logger,
+		retryable: policy.Retryable,
+	}
+	if r.retryable == nil {
+		r.retryable = RetryableError
+	}
+	return r
+}
+
+// PublishOrderCompleted publishes the order through the wrapped publisher,
+// retrying it while the policy allows.
+func (r *RetryingOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	span := trace.SpanFromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		err := r.next.PublishOrderCompleted(ctx, order)
+		if err == nil {
+			span.AddEvent("order event publish attempt", trace.WithAttributes(PublishAttemptKey.Int(attempt)))
+			return nil
+		}
+		attrs := []attribute.KeyValue{
+			PublishAttemptKey.Int(attempt),
+			errcode.Key.String(string(errcode.Of(err))),
+		}
+		if attempt >= r.policy.MaxAttempts || !r.retryable(err) || ctx.Err() != nil {
+			span.AddEvent("order event publish attempt", trace.WithAttributes(attrs...))
+			return err
+		}
+		wait := r.policy.backoff(attempt)
+		span.AddEvent("order event publish attempt", trace.WithAttributes(append(attrs, RetryBackoffKey.Int64(wait.Milliseconds()))...))
+		r.logger.WarnContext(ctx, "Failed to publish order event, retrying",
+			slog.String("order_id", order.GetOrderId()),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", wait),
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// Close closes the wrapped publisher.
+func (r *RetryingOrderEventPublisher) Close(ctx context.Context) error {
+	return closeIfLifecycle(ctx, r.next)
+}
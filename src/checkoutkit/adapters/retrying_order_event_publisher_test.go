@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+func TestRetryingOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	next := NewInMemoryOrderEventPublisher()
+	down := errcode.Errorf(errcode.KafkaAckTimeout, "no ack")
+	next.FailNext(down, down)
+	pub := NewRetryingOrderEventPublisher(next, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, discardLogger())
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "PlaceOrder")
+	err := pub.PublishOrderCompleted(ctx, testOrder())
+	span.End()
+	if err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if next.Len() != 1 {
+		t.Errorf("next received %d orders, want 1", next.Len())
+	}
+
+	events := endedSpan(t, recorder, "PlaceOrder").Events()
+	if len(events) != 3 {
+		t.Fatalf("span has events %v, want one per attempt", events)
+	}
+	for i, event := range events {
+		attrs := map[string]any{}
+		for _, attr := range event.Attributes {
+			attrs[string(attr.Key)] = attr.Value.AsInterface()
+		}
+		if attrs[string(PublishAttemptKey)] != int64(i+1) {
+			t.Errorf("event %d has attempt %v, want %d", i, attrs[string(PublishAttemptKey)], i+1)
+		}
+		_, retried := attrs[string(RetryBackoffKey)]
+		if failed := i < 2; retried != failed || (failed && attrs[string(errcode.Key)] != string(errcode.KafkaAckTimeout)) {
+			t.Errorf("event %d has attributes %v", i, attrs)
+		}
+	}
+}
+
+func TestRetryingOrderEventPublisherGivesUp(t *testing.T) {
+	down := errcode.Errorf(errcode.KafkaAckTimeout, "no ack")
+	invalid := errcode.Errorf(errcode.ValidationFailed, "no order ID")
+	tests := []struct {
+		name     string
+		err      error
+		policy   RetryPolicy
+		attempts int
+	}{
+		{"after the last attempt", down, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, 3},
+		{"on a permanent error", invalid, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, 1},
+		{"on an error the policy does not retry", down, RetryPolicy{MaxAttempts: 3, Retryable: func(error) bool { return false }}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &recordingPublisher{err: tt.err}
+			pub := NewRetryingOrderEventPublisher(next, tt.policy, discardLogger())
+
+			if err := pub.PublishOrderCompleted(context.Background(), testOrder()); !errors.Is(err, tt.err) {
+				t.Errorf("PublishOrderCompleted() = %v, want %v", err, tt.err)
+			}
+			if len(next.orders) != tt.attempts {
+				t.Errorf("published %d times, want %d", len(next.orders), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryingOrderEventPublisherStopsWithContext(t *testing.T) {
+	next := NewInMemoryOrderEventPublisher()
+	down := errcode.Errorf(errcode.KafkaAckTimeout, "no ack")
+	next.FailAll(down)
+	pub := NewRetryingOrderEventPublisher(next, RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}, discardLogger())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); !errors.Is(err, down) {
+		t.Errorf("PublishOrderCompleted() = %v, want %v once the context ended", err, down)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 4: 300 * time.Millisecond} {
+		if got := policy.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for range 100 {
+		if got := policy.backoff(2); got < 100*time.Millisecond || got > 200*time.Millisecond {
+			t.Fatalf("backoff(2) with jitter = %v, want between 100ms and 200ms", got)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// ErrRoundTripMismatch is returned when a serialized order does not decode back
+// into the message it was produced from.
+var ErrRoundTripMismatch = errors.New("order event did not survive a serialization round trip")
+
+// RoundTripCheckingOrderEventPublisher is a debug-mode decorator that serializes
+// each order in every format consumers read (protobuf wire format and consumer
+// JSON), decodes it back and deep-compares it with the source message before
+// publishing. It catches serializer configuration bugs, such as int64 units
+// being emitted as JSON strings, before messages hit consumers. The extra work
+// makes it unsuitable for production traffic.
+type RoundTripCheckingOrderEventPublisher struct {
+	next     ports.OrderEventPublisher
+	logger   *slog.Logger
+	encoders roundTripEncoders
+}
+
+// Compile-time check that RoundTripCheckingOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*RoundTripCheckingOrderEventPublisher)(nil)
+
+// Compile-time check that RoundTripCheckingOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*RoundTripCheckingOrderEventPublisher)(nil)
+
+// NewRoundTripCheckingOrderEventPublisher wraps next with round-trip checks.
+func NewRoundTripCheckingOrderEventPublisher(next ports.OrderEventPublisher, logger *slog.Logger) *RoundTripCheckingOrderEventPublisher {
+	return &RoundTripCheckingOrderEventPublisher{
+		next:     next,
+		logger:   logger,
+		encoders: orderEncoders,
+	}
+}
+
+// PublishOrderCompleted verifies the order round-trips and then publishes it
+// through the wrapped publisher.
+func (r *RoundTripCheckingOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if err := r.encoders.check(order); err != nil {
+		errcode.RecordSpan(trace.SpanFromContext(ctx), err, "order event failed round-trip check")
+		r.logger.ErrorContext(ctx, "Order event failed serialization round trip",
+			slog.String("order_id", order.GetOrderId()),
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+		return err
+	}
+	return r.next.PublishOrderCompleted(ctx, order)
+}
+
+// Close closes the wrapped publisher.
+func (r *RoundTripCheckingOrderEventPublisher) Close(ctx context.Context) error {
+	return closeIfLifecycle(ctx, r.next)
+}
+
+// roundTripEncoders are the encoders of the formats consumers read, whose
+// output a round trip decodes back.
+type roundTripEncoders struct {
+	protobuf     func(*pb.OrderResult) ([]byte, error)
+	consumerJSON func(*pb.OrderResult) (map[string]any, error)
+}
+
+// orderEncoders are the encoders the publishers serialize orders with.
+var orderEncoders = roundTripEncoders{
+	protobuf:     func(order *pb.OrderResult) ([]byte, error) { return proto.Marshal(order) },
+	consumerJSON: serialization.ToConsumerJSON,
+}
+
+// CheckRoundTrip serializes order in the protobuf wire format and the consumer
+// JSON format, decodes both and reports any difference from the original.
+// Encoding errors are classified as errcode.SerializationFailed and differences
+// as errcode.RoundTripMismatch.
+func CheckRoundTrip(order *pb.OrderResult) error {
+	return orderEncoders.check(order)
+}
+
+func (e roundTripEncoders) check(order *pb.OrderResult) error {
+	if err := e.roundTrip(order); err != nil {
+		if errors.Is(err, ErrRoundTripMismatch) {
+			return errcode.Wrap(errcode.RoundTripMismatch, err)
+		}
+		return errcode.Wrap(errcode.SerializationFailed, err)
+	}
+	return nil
+}
+
+func (e roundTripEncoders) roundTrip(order *pb.OrderResult) error {
+	wire, err := e.protobuf(order)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order result to protobuf: %w", err)
+	}
+	fromWire := &pb.OrderResult{}
+	if err := proto.Unmarshal(wire, fromWire); err != nil {
+		return fmt.Errorf("%w: protobuf: %v", ErrRoundTripMismatch, err)
+	}
+	if !proto.Equal(order, fromWire) {
+		return fmt.Errorf("%w: protobuf: decoded %s", ErrRoundTripMismatch, protojson.Format(fromWire))
+	}
+
+	jsonObj, err := e.consumerJSON(order)
+	if err != nil {
+		return err
+	}
+	if err := serialization.CheckConsumerTypes(order.ProtoReflect().Descriptor(), jsonObj); err != nil {
+		return fmt.Errorf("%w: consumer JSON: %v", ErrRoundTripMismatch, err)
+	}
+	jsonBytes, err := json.Marshal(jsonObj)
+	if err != nil {
+		return fmt.Errorf("failed to marshal consumer JSON: %w", err)
+	}
+	fromJSON, err := serialization.FromConsumerJSON(jsonBytes)
+	if err != nil {
+		return fmt.Errorf("%w: consumer JSON: %v", ErrRoundTripMismatch, err)
+	}
+	if !proto.Equal(order, fromJSON) {
+		return fmt.Errorf("%w: consumer JSON: decoded %s from %s", ErrRoundTripMismatch, protojson.Format(fromJSON), jsonBytes)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports/mocks"
+)
+
+func TestRoundTripCheckingOrderEventPublisher(t *testing.T) {
+	// Only the order that survives the round trip reaches the wrapped publisher
+	order := testOrder()
+	next := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+	next.EXPECT().PublishOrderCompleted(gomock.Any(), order)
+	pub := NewRoundTripCheckingOrderEventPublisher(next, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+}
+
+// orderWithUnknownField returns an order carrying a field consumer JSON
+// cannot represent, so that it does not decode back from it.
+func orderWithUnknownField() *pb.OrderResult {
+	order := testOrder()
+	order.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 111, protowire.VarintType), 1))
+	return order
+}
+
+func TestRoundTripCheckingOrderEventPublisherRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    *pb.OrderResult
+		encoders roundTripEncoders
+		want     errcode.Code
+	}{
+		{
+			name: "invalid UTF-8",
+			order: func() *pb.OrderResult {
+				order := testOrder()
+				order.ShippingAddress.City = "Any\xfftown"
+				return order
+			}(),
+			encoders: orderEncoders,
+			want:     errcode.SerializationFailed,
+		},
+		{
+			name:  "protobuf encoder dropping a field",
+			order: testOrder(),
+			encoders: roundTripEncoders{
+				protobuf: func(order *pb.OrderResult) ([]byte, error) {
+					order = proto.CloneOf(order)
+					order.ShippingTrackingId = ""
+					return proto.Marshal(order)
+				},
+				consumerJSON: orderEncoders.consumerJSON,
+			},
+			want: errcode.RoundTripMismatch,
+		},
+		{
+			name:  "protobuf encoder writing malformed bytes",
+			order: testOrder(),
+			encoders: roundTripEncoders{
+				protobuf:     func(*pb.OrderResult) ([]byte, error) { return []byte{0xff, 0xff}, nil },
+				consumerJSON: orderEncoders.consumerJSON,
+			},
+			want: errcode.RoundTripMismatch,
+		},
+		{
+			name:  "consumer JSON encoder writing int64 as strings",
+			order: testOrder(),
+			encoders: roundTripEncoders{
+				protobuf: orderEncoders.protobuf,
+				consumerJSON: func(order *pb.OrderResult) (map[string]any, error) {
+					data, err := protojson.Marshal(order)
+					if err != nil {
+						return nil, err
+					}
+					var obj map[string]any
+					return obj, json.Unmarshal(data, &obj)
+				},
+			},
+			want: errcode.RoundTripMismatch,
+		},
+		{
+			name:     "order not decoded back from consumer JSON",
+			order:    orderWithUnknownField(),
+			encoders: orderEncoders,
+			want:     errcode.RoundTripMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// next is never called: the mock fails the test on any call
+			next := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+			pub := NewRoundTripCheckingOrderEventPublisher(next, discardLogger())
+			pub.encoders = tt.encoders
+
+			err := pub.PublishOrderCompleted(context.Background(), tt.order)
+			if errcode.Of(err) != tt.want {
+				t.Fatalf("PublishOrderCompleted() = %v, want %s", err, tt.want)
+			}
+			if mismatch := tt.want == errcode.RoundTripMismatch; errors.Is(err, ErrRoundTripMismatch) != mismatch {
+				t.Errorf("errors.Is(%v, ErrRoundTripMismatch) = %t, want %t", err, !mismatch, mismatch)
+			}
+		})
+	}
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	if err := CheckRoundTrip(testOrder()); err != nil {
+		t.Errorf("CheckRoundTrip() = %v", err)
+	}
+	err := CheckRoundTrip(orderWithUnknownField())
+	if !errors.Is(err, ErrRoundTripMismatch) || errcode.Of(err) != errcode.RoundTripMismatch {
+		t.Errorf("CheckRoundTrip(order with unknown field) = %v, want %s", err, errcode.RoundTripMismatch)
+	}
+}
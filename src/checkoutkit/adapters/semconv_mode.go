@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	semconvstable "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// SemconvMode selects which messaging semantic conventions the Kafka adapters
+// emit. Dashboards and alerts built on the v1.24 attributes keep working in the
+// default mode, and the dup mode lets them migrate before switching to the new
+// attributes only.
+type SemconvMode int
+
+const (
+	// SemconvOld emits the v1.24 messaging attributes. This is the default.
+	SemconvOld SemconvMode = iota
+	// SemconvNew emits the stabilized messaging attributes only.
+	SemconvNew
+	// SemconvDup emits both, for the duration of a migration.
+	SemconvDup
+)
+
+func (m SemconvMode) String() string {
+	switch m {
+	case SemconvNew:
+		return "messaging"
+	case SemconvDup:
+		return "messaging/dup"
+	default:
+		return "old"
+	}
+}
+
+// ParseSemconvStabilityOptIn parses the comma-separated value of
+// OTEL_SEMCONV_STABILITY_OPT_IN. "messaging" selects the new conventions and
+// "messaging/dup" both; dup wins when both are listed. Values for other
+// convention groups are ignored.
+func ParseSemconvStabilityOptIn(value string) SemconvMode {
+	mode := SemconvOld
+	for _, v := range strings.Split(value, ",") {
+		switch strings.TrimSpace(v) {
+		case "messaging/dup":
+			return SemconvDup
+		case "messaging":
+			mode = SemconvNew
+		}
+	}
+	return mode
+}
+
+func (m SemconvMode) emitOld() bool { return m != SemconvNew }
+func (m SemconvMode) emitNew() bool { return m != SemconvOld }
+
+// publishOperation describes a producer span. The stable conventions replace
+// messaging.operation=publish with an operation type and name.
+func (m SemconvMode) publishOperation() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if m.emitOld() {
+		attrs = append(attrs, semconv.MessagingOperationPublish)
+	}
+	if m.emitNew() {
+		attrs = append(attrs, semconvstable.MessagingOperationTypeSend, semconvstable.MessagingOperationName("send"))
+	}
+	return attrs
+}
+
+// deliverOperation describes a consumer span that processes a message.
+func (m SemconvMode) deliverOperation() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if m.emitOld() {
+		attrs = append(attrs, semconv.MessagingOperationDeliver)
+	}
+	if m.emitNew() {
+		attrs = append(attrs, semconvstable.MessagingOperationTypeProcess, semconvstable.MessagingOperationName("process"))
+	}
+	return attrs
+}
+
+// partition describes the partition of a message. The stable conventions
+// moved it to messaging.destination.partition.id, as a string.
+func (m SemconvMode) partition(partition int32) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if m.emitOld() {
+		attrs = append(attrs, semconv.MessagingKafkaDestinationPartition(int(partition)))
+	}
+	if m.emitNew() {
+		attrs = append(attrs, semconvstable.MessagingDestinationPartitionID(strconv.Itoa(int(partition))))
+	}
+	return attrs
+}
+
+// offset describes the offset of a message, renamed to messaging.kafka.offset
+// by the stable conventions.
+func (m SemconvMode) offset(offset int64) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if m.emitOld() {
+		attrs = append(attrs, semconv.MessagingKafkaMessageOffset(int(offset)))
+	}
+	if m.emitNew() {
+		attrs = append(attrs, semconvstable.MessagingKafkaOffset(int(offset)))
+	}
+	return attrs
+}
+
+// slogAttrs converts span attributes to log attributes, so that logs use the
+// same keys as the spans they are correlated with.
+func slogAttrs(attrs []attribute.KeyValue) []any {
+	out := make([]any, len(attrs))
+	for i, kv := range attrs {
+		out[i] = slog.Any(string(kv.Key), kv.Value.AsInterface())
+	}
+	return out
+}
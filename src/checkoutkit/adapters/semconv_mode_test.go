@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafkatest"
+)
+
+func TestParseSemconvStabilityOptIn(t *testing.T) {
+	tests := []struct {
+		value string
+		want  SemconvMode
+	}{
+		{"", SemconvOld},
+		{"http", SemconvOld},
+		{"messaging", SemconvNew},
+		{"http, messaging", SemconvNew},
+		{"messaging/dup", SemconvDup},
+		{"messaging,messaging/dup", SemconvDup},
+	}
+	for _, tt := range tests {
+		if got := ParseSemconvStabilityOptIn(tt.value); got != tt.want {
+			t.Errorf("ParseSemconvStabilityOptIn(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestKafkaOrderEventPublisherSemconvModes(t *testing.T) {
+	const (
+		oldOperation = "messaging.operation"
+		oldOffset    = "messaging.kafka.message.offset"
+		newOperation = "messaging.operation.type"
+		newOffset    = "messaging.kafka.offset"
+	)
+	tests := []struct {
+		mode    SemconvMode
+		want    []string
+		notWant []string
+	}{
+		{SemconvOld, []string{oldOperation, oldOffset}, []string{newOperation, newOffset}},
+		{SemconvNew, []string{newOperation, newOffset}, []string{oldOperation, oldOffset}},
+		{SemconvDup, []string{oldOperation, oldOffset, newOperation, newOffset}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			recorder := newTestTracing(t)
+			producer := kafkatest.NewProducer(t)
+			producer.ExpectSuccess()
+			pub := NewKafkaOrderEventPublisher(producer, discardLogger(), WithSemconvMode(tt.mode))
+
+			if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+				t.Fatalf("PublishOrderCompleted() = %v", err)
+			}
+
+			// The operation is on the producer span, the offset on the ack span
+			attrs := map[string]bool{}
+			for _, name := range []string{"orders publish", "orders ack"} {
+				for _, kv := range endedSpan(t, recorder, name).Attributes() {
+					attrs[string(kv.Key)] = true
+				}
+			}
+			for _, key := range tt.want {
+				if !attrs[key] {
+					t.Errorf("missing attribute %s", key)
+				}
+			}
+			for _, key := range tt.notWant {
+				if attrs[key] {
+					t.Errorf("unexpected attribute %s", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func testShippingAddress() *pb.Address {
+	return &pb.Address{StreetAddress: "1 Main St", City: "Anytown", State: "CA", Country: "USA", ZipCode: "94043"}
+}
+
+func testCartItems() []*pb.CartItem {
+	return []*pb.CartItem{{ProductId: "SKU-1", Quantity: 2}, {ProductId: "SKU-2", Quantity: 1}}
+}
+
+// testShippingProviderContract verifies what the order workflow relies on from
+// every ShippingProvider. newProvider returns a provider whose upstream works,
+// or fails every call when down is set.
+func testShippingProviderContract(t *testing.T, newProvider func(t *testing.T, down bool) ports.ShippingProvider) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("quote is a valid amount", func(t *testing.T) {
+		quote, err := newProvider(t, false).Quote(ctx, testShippingAddress(), testCartItems())
+		if err != nil {
+			t.Fatalf("Quote() = %v", err)
+		}
+		if !currencyCode.MatchString(quote.GetCurrencyCode()) || !money.IsValid(quote) || money.IsNegative(quote) {
+			t.Errorf("Quote() = %v, want a non-negative amount in an ISO 4217 currency", quote)
+		}
+	})
+
+	t.Run("ship returns a tracking ID", func(t *testing.T) {
+		trackingID, err := newProvider(t, false).Ship(ctx, testShippingAddress(), testCartItems())
+		if err != nil || trackingID == "" {
+			t.Errorf("Ship() = %q, %v; want a tracking ID", trackingID, err)
+		}
+	})
+
+	t.Run("upstream failures are errors", func(t *testing.T) {
+		provider := newProvider(t, true)
+		if quote, err := provider.Quote(ctx, testShippingAddress(), testCartItems()); err == nil {
+			t.Errorf("Quote() = %v, want an error", quote)
+		}
+		if trackingID, err := provider.Ship(ctx, testShippingAddress(), testCartItems()); err == nil {
+			t.Errorf("Ship() = %q, want an error", trackingID)
+		}
+	})
+}
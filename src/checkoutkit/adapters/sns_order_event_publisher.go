@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// defaultSNSPublishTimeout bounds a publish to SNS by default.
+const defaultSNSPublishTimeout = 5 * time.Second
+
+// ContentTypeAttribute is the SNS message attribute carrying the content
+// type of the order events, named like the pact metadata.
+const ContentTypeAttribute = "contentType"
+
+// maxSNSMessageAttributes is the number of message attributes SNS accepts on
+// a message.
+const maxSNSMessageAttributes = 10
+
+// SNSClient is the part of the SNS client the SNS publisher uses.
+type SNSClient interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+	Subscribe(ctx context.Context, params *sns.SubscribeInput, optFns ...func(*sns.Options)) (*sns.SubscribeOutput, error)
+	GetTopicAttributes(ctx context.Context, params *sns.GetTopicAttributesInput, optFns ...func(*sns.Options)) (*sns.GetTopicAttributesOutput, error)
+}
+
+// Compile-time check that the SNS client implements SNSClient
+var _ SNSClient = (*sns.Client)(nil)
+
+// SNSOrderEventPublisher implements the OrderEventPublisher port on an AWS
+// SNS topic. Each order is published in the consumer JSON format, since SNS
+// messages are text, with the content type, the trace context, the baggage
+// and the message headers as message attributes. SQS queues subscribed with
+// raw message delivery receive them as SQS message attributes. On a FIFO
+// topic, the order ID is both the message group and the deduplication ID.
+type SNSOrderEventPublisher struct {
+	client         SNSClient
+	topicARN       string
+	topic          string
+	region         string
+	fifo           bool
+	publishTimeout time.Duration
+	logger         *slog.Logger
+	tracer         trace.Tracer
+}
+
+// Compile-time check that SNSOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*SNSOrderEventPublisher)(nil)
+
+// SNSPublisherOption configures optional behavior of an SNSOrderEventPublisher.
+type SNSPublisherOption func(*SNSOrderEventPublisher)
+
+// WithSNSPublishTimeout bounds a publish, retries of the AWS SDK included,
+// unless its context ends first. The default is 5s.
+func WithSNSPublishTimeout(timeout time.Duration) SNSPublisherOption {
+	return func(s *SNSOrderEventPublisher) {
+		s.publishTimeout = timeout
+	}
+}
+
+// NewSNSOrderEventPublisher creates a publisher of orders to the topic with
+// topicARN.
+func NewSNSOrderEventPublisher(client SNSClient, topicARN string, logger *slog.Logger, opts ...SNSPublisherOption) *SNSOrderEventPublisher {
+	s := &SNSOrderEventPublisher{
+		client:         client,
+		topicARN:       topicARN,
+		topic:          topicARN,
+		fifo:           strings.HasSuffix(topicARN, ".fifo"),
+		publishTimeout: defaultSNSPublishTimeout,
+		logger:         logger,
+		tracer:         otel.Tracer("checkout-sns-adapter"),
+	}
+	// arn:partition:sns:region:account:topic
+	if fields := strings.Split(topicARN, ":"); len(fields) == 6 {
+		s.region, s.topic = fields[3], fields[5]
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SubscribeSQSQueues subscribes the SQS queues with queueARNs to the topic
+// with topicARN, with raw message delivery so that the queues receive the
+// order event and its attributes as they were published. Subscribing a queue
+// again changes nothing. The policy of each queue must let the topic send to
+// it.
+func SubscribeSQSQueues(ctx context.Context, client SNSClient, topicARN string, queueARNs []string) error {
+	for _, queueARN := range queueARNs {
+		_, err := client.Subscribe(ctx, &sns.SubscribeInput{
+			TopicArn:              aws.String(topicARN),
+			Protocol:              aws.String("sqs"),
+			Endpoint:              aws.String(queueARN),
+			Attributes:            map[string]string{"RawMessageDelivery": "true"},
+			ReturnSubscriptionArn: true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe %s to %s: %w", queueARN, topicARN, err)
+		}
+	}
+	return nil
+}
+
+// PublishOrderCompleted publishes the order to the topic.
+func (s *SNSOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	event, err := serialization.ToConsumerJSON(order)
+	if err != nil {
+		return errcode.Wrap(errcode.SerializationFailed, err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order event: %w", err)
+	}
+
+	spanCtx, span := s.tracer.Start(ctx, fmt.Sprintf("%s publish", s.topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.PeerService("sns"),
+			semconv.MessagingSystemKey.String("aws_sns"),
+			semconv.MessagingDestinationName(s.topic),
+			semconv.MessagingOperationPublish,
+		),
+	)
+	defer span.End()
+	if s.region != "" {
+		span.SetAttributes(semconv.CloudRegion(s.region))
+	}
+	span.SetAttributes(baggageAttributes(ctx)...)
+
+	attributes, err := snsMessageAttributes(spanCtx, MessageHeaders(ctx))
+	if err != nil {
+		errcode.RecordSpan(span, err, "Order event has too many message attributes")
+		return err
+	}
+	input := &sns.PublishInput{
+		TopicArn:          aws.String(s.topicARN),
+		Message:           aws.String(string(body)),
+		MessageAttributes: attributes,
+	}
+	if s.fifo {
+		input.MessageGroupId = aws.String(order.GetOrderId())
+		input.MessageDeduplicationId = aws.String(order.GetOrderId())
+	}
+
+	publishCtx, cancel := context.WithTimeout(spanCtx, s.publishTimeout)
+	defer cancel()
+	out, err := s.client.Publish(publishCtx, input)
+	if err != nil {
+		err = errcode.Errorf(errcode.SNSPublishFailed, "failed to publish order event to %s: %w", s.topicARN, err)
+		errcode.RecordSpan(span, err, "SNS did not accept the message")
+		return err
+	}
+	messageID := aws.ToString(out.MessageId)
+	span.AddEvent(PublishEventAcked, trace.WithAttributes(semconv.MessagingMessageID(messageID)))
+	s.logger.InfoContext(ctx, "Published order event to SNS",
+		slog.String("order_id", order.GetOrderId()),
+		slog.String("topic", s.topic),
+		slog.String("message_id", messageID),
+	)
+	return nil
+}
+
+// snsMessageAttributes returns the content type, the trace context and
+// baggage of ctx, and headers as string message attributes. It fails with
+// SERIALIZATION_FAILED when they are more than SNS accepts.
+func snsMessageAttributes(ctx context.Context, headers map[string]string) (map[string]snstypes.MessageAttributeValue, error) {
+	values := map[string]string{ContentTypeAttribute: "application/json"}
+	for key, value := range headers {
+		values[key] = value
+	}
+	for key, value := range PropagationHeaders(ctx) {
+		values[key] = value
+	}
+	attributes := make(map[string]snstypes.MessageAttributeValue, len(values))
+	for key, value := range values {
+		// SNS rejects empty string attributes
+		if value == "" {
+			continue
+		}
+		attributes[key] = snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	if len(attributes) > maxSNSMessageAttributes {
+		keys := make([]string, 0, len(attributes))
+		for key := range attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, errcode.Errorf(errcode.SerializationFailed, "order event has %d message attributes, more than the %d SNS accepts: %s",
+			len(attributes), maxSNSMessageAttributes, strings.Join(keys, ", "))
+	}
+	return attributes, nil
+}
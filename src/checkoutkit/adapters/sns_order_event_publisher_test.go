@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+const testTopicARN = "arn:aws:sns:eu-west-1:000000000000:orders"
+
+// fakeSNSClient records the messages published and the queues subscribed to
+// it, or fails them with err.
+type fakeSNSClient struct {
+	published  []*sns.PublishInput
+	subscribed []*sns.SubscribeInput
+	err        error
+}
+
+func (f *fakeSNSClient) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.published = append(f.published, params)
+	return &sns.PublishOutput{MessageId: aws.String(fmt.Sprintf("message-%d", len(f.published)))}, nil
+}
+
+func (f *fakeSNSClient) Subscribe(_ context.Context, params *sns.SubscribeInput, _ ...func(*sns.Options)) (*sns.SubscribeOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.subscribed = append(f.subscribed, params)
+	return &sns.SubscribeOutput{}, nil
+}
+
+func (f *fakeSNSClient) GetTopicAttributes(context.Context, *sns.GetTopicAttributesInput, ...func(*sns.Options)) (*sns.GetTopicAttributesOutput, error) {
+	return &sns.GetTopicAttributesOutput{}, f.err
+}
+
+func TestSNSOrderEventPublisher(t *testing.T) {
+	recorder := newTestTracing(t)
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	client := &fakeSNSClient{}
+	pub := NewSNSOrderEventPublisher(client, testTopicARN, discardLogger())
+	member, _ := baggage.NewMember(BaggageSyntheticRequest, "true")
+	bag, _ := baggage.New(member)
+	ctx := WithMessageHeaders(baggage.ContextWithBaggage(context.Background(), bag), map[string]string{SchemaVersionHeader: "2"})
+
+	order := testOrder()
+	if err := pub.PublishOrderCompleted(ctx, order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if len(client.published) != 1 {
+		t.Fatalf("published %d messages, want 1", len(client.published))
+	}
+	input := client.published[0]
+	var event map[string]any
+	if err := json.Unmarshal([]byte(aws.ToString(input.Message)), &event); err != nil || event["orderId"] != order.GetOrderId() {
+		t.Errorf("message = %s (%v), want order %s in JSON", aws.ToString(input.Message), err, order.GetOrderId())
+	}
+	if aws.ToString(input.TopicArn) != testTopicARN || input.MessageGroupId != nil {
+		t.Errorf("published to %s with group %v, want %s without a group", aws.ToString(input.TopicArn), input.MessageGroupId, testTopicARN)
+	}
+	for _, key := range []string{ContentTypeAttribute, "traceparent", "baggage", SchemaVersionHeader} {
+		if aws.ToString(input.MessageAttributes[key].StringValue) == "" {
+			t.Errorf("message attribute %s missing from %v", key, input.MessageAttributes)
+		}
+	}
+
+	span := endedSpan(t, recorder, "orders publish")
+	if span.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("span kind = %v, want producer", span.SpanKind())
+	}
+	if traceparent := aws.ToString(input.MessageAttributes["traceparent"].StringValue); traceparent[3:35] != span.SpanContext().TraceID().String() {
+		t.Errorf("traceparent = %s, want the trace of the producer span %s", traceparent, span.SpanContext().TraceID())
+	}
+	if !slices.Contains(span.Attributes(), semconv.CloudRegion("eu-west-1")) {
+		t.Errorf("span attributes = %v, want cloud.region eu-west-1", span.Attributes())
+	}
+	if events := span.Events(); len(events) != 1 || events[0].Name != PublishEventAcked {
+		t.Errorf("span events = %v, want %s", events, PublishEventAcked)
+	}
+}
+
+func TestSNSOrderEventPublisherFIFO(t *testing.T) {
+	client := &fakeSNSClient{}
+	pub := NewSNSOrderEventPublisher(client, testTopicARN+".fifo", discardLogger())
+	order := testOrder()
+	if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	input := client.published[0]
+	if aws.ToString(input.MessageGroupId) != order.GetOrderId() || aws.ToString(input.MessageDeduplicationId) != order.GetOrderId() {
+		t.Errorf("group = %v, deduplication ID = %v, want order %s", input.MessageGroupId, input.MessageDeduplicationId, order.GetOrderId())
+	}
+}
+
+func TestSNSOrderEventPublisherFailures(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := range maxSNSMessageAttributes {
+		tooMany[fmt.Sprintf("header-%d", i)] = "value"
+	}
+	tests := []struct {
+		name     string
+		client   *fakeSNSClient
+		headers  map[string]string
+		wantCode errcode.Code
+	}{
+		{name: "rejected", client: &fakeSNSClient{err: errors.New("AuthorizationError")}, wantCode: errcode.SNSPublishFailed},
+		{name: "too many attributes", client: &fakeSNSClient{}, headers: tooMany, wantCode: errcode.SerializationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := NewSNSOrderEventPublisher(tt.client, testTopicARN, discardLogger())
+			ctx := WithMessageHeaders(context.Background(), tt.headers)
+			err := pub.PublishOrderCompleted(ctx, testOrder())
+			if code := errcode.Of(err); code != tt.wantCode {
+				t.Errorf("PublishOrderCompleted() = %v with code %s, want %s", err, code, tt.wantCode)
+			}
+			if len(tt.client.published) != 0 {
+				t.Errorf("published %d messages, want none", len(tt.client.published))
+			}
+		})
+	}
+}
+
+func TestSubscribeSQSQueues(t *testing.T) {
+	client := &fakeSNSClient{}
+	queues := []string{"arn:aws:sqs:eu-west-1:000000000000:shipping", "arn:aws:sqs:eu-west-1:000000000000:accounting"}
+	if err := SubscribeSQSQueues(context.Background(), client, testTopicARN, queues); err != nil {
+		t.Fatalf("SubscribeSQSQueues() = %v", err)
+	}
+	if len(client.subscribed) != len(queues) {
+		t.Fatalf("subscribed %d queues, want %d", len(client.subscribed), len(queues))
+	}
+	for i, input := range client.subscribed {
+		if aws.ToString(input.Protocol) != "sqs" || aws.ToString(input.Endpoint) != queues[i] || input.Attributes["RawMessageDelivery"] != "true" {
+			t.Errorf("subscription %d = %+v, want %s with raw message delivery", i, input, queues[i])
+		}
+	}
+
+	failing := errors.New("AuthorizationError")
+	if err := SubscribeSQSQueues(context.Background(), &fakeSNSClient{err: failing}, testTopicARN, queues); !errors.Is(err, failing) {
+		t.Errorf("SubscribeSQSQueues() = %v, want %v", err, failing)
+	}
+}
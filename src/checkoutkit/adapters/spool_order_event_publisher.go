@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// SpoolOrderEventPublisher implements the OrderEventPublisher port by appending
+// events to a local spool file, one JSON document per line. It is used when the
+// real transport must not be used (for example because the message schema is
+// incompatible with the registry), so that events are kept for later replay
+// instead of being dropped.
+//
+// A line is the OrderResult in proto JSON, or, for an event with message
+// headers, a spoolLine holding the order and its headers, so that a replayed
+// event keeps the payments and fees of schema versions 2 and 3.
+type SpoolOrderEventPublisher struct {
+	path   string
+	logger *slog.Logger
+	mu     sync.Mutex
+	closed bool
+
+	// replayMu lets one Replay run at a time
+	replayMu sync.Mutex
+}
+
+// Compile-time check that SpoolOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*SpoolOrderEventPublisher)(nil)
+
+// Compile-time check that SpoolOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*SpoolOrderEventPublisher)(nil)
+
+// spoolLine is a spooled event with message headers. Events without headers
+// are spooled as the bare OrderResult, which older replayers and jq read.
+type spoolLine struct {
+	Order   json.RawMessage   `json:"order"`
+	Headers map[string]string `json:"headers"`
+}
+
+// SpooledEvent is an order event read from a spool, with the message headers
+// it was published with.
+type SpooledEvent struct {
+	Order   *pb.OrderResult
+	Headers map[string]string
+}
+
+// encodeSpoolLine returns the spool line of order published with headers,
+// without its newline.
+func encodeSpoolLine(order *pb.OrderResult, headers map[string]string) ([]byte, error) {
+	body, err := protojson.Marshal(order)
+	if err != nil || len(headers) == 0 {
+		return body, err
+	}
+	return json.Marshal(spoolLine{Order: body, Headers: headers})
+}
+
+// decodeSpoolLine reads a line written by encodeSpoolLine.
+func decodeSpoolLine(line []byte) (SpooledEvent, error) {
+	var wrapped spoolLine
+	if json.Unmarshal(line, &wrapped) == nil && wrapped.Order != nil {
+		line = wrapped.Order
+	}
+	event := SpooledEvent{Order: &pb.OrderResult{}, Headers: wrapped.Headers}
+	if err := protojson.Unmarshal(line, event.Order); err != nil {
+		return SpooledEvent{}, err
+	}
+	return event, nil
+}
+
+// NewSpoolOrderEventPublisher creates a publisher that spools events to path.
+func NewSpoolOrderEventPublisher(path string, logger *slog.Logger) *SpoolOrderEventPublisher {
+	return &SpoolOrderEventPublisher{
+		path:   path,
+		logger: logger,
+	}
+}
+
+// PublishOrderCompleted appends the order and the message headers of ctx to
+// the spool file.
+func (s *SpoolOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	line, err := encodeSpoolLine(order, MessageHeaders(ctx))
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order result to JSON: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errcode.Errorf(errcode.PublisherClosed, "spool %s is closed", s.path)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errcode.Errorf(errcode.SpoolWriteFailed, "failed to open spool file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(line); err != nil {
+		return errcode.Errorf(errcode.SpoolWriteFailed, "failed to write to spool file: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Spooled order event",
+		slog.String("order_id", order.GetOrderId()),
+		slog.String("path", s.path),
+	)
+	return nil
+}
+
+// Close waits for a write in progress and flushes the spool file to stable
+// storage, so that events spooled during shutdown survive a crash of the node.
+// Later events fail with PUBLISHER_CLOSED.
+func (s *SpoolOrderEventPublisher) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errcode.Errorf(errcode.SpoolWriteFailed, "failed to open spool file: %w", err)
+	}
+	defer f.Close()
+	if err := f.Sync(); err != nil {
+		return errcode.Errorf(errcode.SpoolWriteFailed, "failed to flush spool file: %w", err)
+	}
+	return nil
+}
+
+// replayPath is the file holding the orders of a replay in progress.
+func (s *SpoolOrderEventPublisher) replayPath() string {
+	return s.path + ".replay"
+}
+
+// Replay publishes the spooled orders in order, with the message headers they
+// were spooled with, and removes the ones that were published. It stops at the first failure and keeps that order and the later
+// ones for the next replay. Orders spooled while a replay runs wait for the
+// next one. If the process dies during a replay, the next one starts over, so
+// consumers may receive an order twice.
+func (s *SpoolOrderEventPublisher) Replay(ctx context.Context, publish func(context.Context, *pb.OrderResult) error) (int, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	// Take the spooled orders out of the way of new ones, unless a previous
+	// replay left some behind
+	pending := s.replayPath()
+	s.mu.Lock()
+	_, err := os.Stat(pending)
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.Rename(s.path, pending)
+	}
+	s.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errcode.Errorf(errcode.SpoolWriteFailed, "failed to take the spool for replay: %w", err)
+	}
+
+	data, err := os.ReadFile(pending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read spool file: %w", err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	replayed := 0
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		event, err := decodeSpoolLine(line)
+		if err != nil {
+			// A corrupt line would otherwise block every later order
+			s.logger.WarnContext(ctx, "Dropping unreadable spooled order event",
+				slog.String("path", s.path),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		publishCtx := ctx
+		if len(event.Headers) > 0 {
+			publishCtx = WithMessageHeaders(ctx, event.Headers)
+		}
+		if err := publish(publishCtx, event.Order); err != nil {
+			if writeErr := os.WriteFile(pending, bytes.Join(lines[i:], nil), 0o600); writeErr != nil {
+				return replayed, errors.Join(err, errcode.Errorf(errcode.SpoolWriteFailed, "failed to keep unreplayed orders: %w", writeErr))
+			}
+			return replayed, err
+		}
+		replayed++
+	}
+	if err := os.Remove(pending); err != nil {
+		return replayed, errcode.Errorf(errcode.SpoolWriteFailed, "failed to remove replayed spool file: %w", err)
+	}
+	return replayed, nil
+}
+
+// Depth returns the number of spooled orders, including the ones of a replay
+// in progress.
+func (s *SpoolOrderEventPublisher) Depth() (int, error) {
+	depth := 0
+	for _, path := range []string{s.path, s.replayPath()} {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read spool file: %w", err)
+		}
+		depth += bytes.Count(data, []byte("\n"))
+	}
+	return depth, nil
+}
+
+// ReadSpool returns the orders of the spool at path, those of a replay in
+// progress first, without changing it, for tools that inspect or republish
+// spooled orders outside the service. Unreadable lines are skipped and
+// counted.
+func ReadSpool(path string) (orders []*pb.OrderResult, unreadable int, err error) {
+	events, unreadable, err := ReadSpoolEvents(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, event := range events {
+		orders = append(orders, event.Order)
+	}
+	return orders, unreadable, nil
+}
+
+// ReadSpoolEvents is ReadSpool with the message headers of each order, for
+// tools that republish them.
+func ReadSpoolEvents(path string) (events []SpooledEvent, unreadable int, err error) {
+	for _, file := range []string{path + ".replay", path} {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read spool file: %w", err)
+		}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			event, err := decodeSpoolLine(line)
+			if err != nil {
+				unreadable++
+				continue
+			}
+			events = append(events, event)
+		}
+	}
+	return events, unreadable, nil
+}
@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+func TestSpoolOrderEventPublisherAppendsOrders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.spool")
+	pub := NewSpoolOrderEventPublisher(path, discardLogger())
+
+	first, second := testOrder(), testOrder()
+	second.OrderId = "order-2"
+	for _, order := range []*pb.OrderResult{first, second} {
+		if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	defer f.Close()
+
+	var got []*pb.OrderResult
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		order := &pb.OrderResult{}
+		if err := protojson.Unmarshal(scanner.Bytes(), order); err != nil {
+			t.Fatalf("spool line is not an OrderResult: %v", err)
+		}
+		got = append(got, order)
+	}
+	if len(got) != 2 || !proto.Equal(got[0], first) || !proto.Equal(got[1], second) {
+		t.Errorf("spool contains %v, want both published orders in order", got)
+	}
+}
+
+func TestSpoolOrderEventPublisherClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.spool")
+	pub := NewSpoolOrderEventPublisher(path, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.PublisherClosed {
+		t.Errorf("PublishOrderCompleted() after Close = %v, want a %s error", err, errcode.PublisherClosed)
+	}
+
+	// Closing before anything was spooled creates no file
+	empty := NewSpoolOrderEventPublisher(filepath.Join(t.TempDir(), "empty.spool"), discardLogger())
+	if err := empty.Close(context.Background()); err != nil {
+		t.Errorf("Close() of an empty spool = %v", err)
+	}
+}
+
+func TestSpoolOrderEventPublisherReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.spool")
+	pub := NewSpoolOrderEventPublisher(path, discardLogger())
+	for _, id := range []string{"order-1", "order-2", "order-3"} {
+		order := testOrder()
+		order.OrderId = id
+		if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+	}
+
+	// The primary fails on the second order, which is kept with the third
+	var replayed []string
+	errDown := errors.New("broker down")
+	down := true
+	publish := func(_ context.Context, order *pb.OrderResult) error {
+		if order.OrderId == "order-2" && down {
+			down = false
+			return errDown
+		}
+		replayed = append(replayed, order.OrderId)
+		return nil
+	}
+	if n, err := pub.Replay(context.Background(), publish); n != 1 || !errors.Is(err, errDown) {
+		t.Fatalf("Replay() = %d, %v; want 1, %v", n, err, errDown)
+	}
+	// Orders spooled during the outage are replayed after the kept ones
+	late := testOrder()
+	late.OrderId = "order-4"
+	pub.PublishOrderCompleted(context.Background(), late)
+	if depth, err := pub.Depth(); depth != 3 || err != nil {
+		t.Errorf("Depth() = %d, %v; want 3", depth, err)
+	}
+
+	for range 2 {
+		if _, err := pub.Replay(context.Background(), publish); err != nil {
+			t.Fatalf("Replay() = %v", err)
+		}
+	}
+	if want := []string{"order-1", "order-2", "order-3", "order-4"}; !slices.Equal(replayed, want) {
+		t.Errorf("replayed %v, want %v", replayed, want)
+	}
+	if depth, err := pub.Depth(); depth != 0 || err != nil {
+		t.Errorf("Depth() after replaying everything = %d, %v; want 0", depth, err)
+	}
+}
+
+func TestSpoolOrderEventPublisherReplayKeepsHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.spool")
+	pub := NewSpoolOrderEventPublisher(path, discardLogger())
+	v2, err := OrderExtensions{
+		Payments: []ports.Payment{{Type: ports.CardTender, Amount: testOrder().ShippingCost, TransactionID: "tx-1"}},
+	}.Headers(2)
+	if err != nil {
+		t.Fatalf("Headers(2) = %v", err)
+	}
+	if err := pub.PublishOrderCompleted(WithMessageHeaders(context.Background(), v2), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	// A version 1 event is spooled as the bare order, as before headers were kept
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+
+	next := NewInMemoryOrderEventPublisher()
+	if n, err := pub.Replay(context.Background(), next.PublishOrderCompleted); n != 2 || err != nil {
+		t.Fatalf("Replay() = %d, %v; want 2, nil", n, err)
+	}
+	events := next.Events()
+	if !proto.Equal(events[0].Order, testOrder()) || !maps.Equal(events[0].Headers, v2) {
+		t.Errorf("replayed %v with headers %v, want the order with the version 2 headers %v", events[0].Order, events[0].Headers, v2)
+	}
+	if len(events[1].Headers) != 0 {
+		t.Errorf("replayed the version 1 event with headers %v, want none", events[1].Headers)
+	}
+}
+
+func TestSpoolOrderEventPublisherReplaySkipsUnreadableOrders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.spool")
+	line, _ := protojson.Marshal(testOrder())
+	if err := os.WriteFile(path, append([]byte("not json\n"), append(line, '\n')...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pub := NewSpoolOrderEventPublisher(path, discardLogger())
+
+	next := &recordingPublisher{}
+	if n, err := pub.Replay(context.Background(), next.PublishOrderCompleted); n != 1 || err != nil {
+		t.Errorf("Replay() = %d, %v; want the readable order replayed", n, err)
+	}
+}
+
+func TestReadSpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.spool")
+	if orders, unreadable, err := ReadSpool(path); len(orders) != 0 || unreadable != 0 || err != nil {
+		t.Fatalf("ReadSpool() of no spool = %v, %d, %v; want nothing", orders, unreadable, err)
+	}
+
+	replaying, spooled := testOrder(), testOrder()
+	replaying.OrderId, spooled.OrderId = "order-1", "order-2"
+	first, _ := protojson.Marshal(replaying)
+	second, _ := protojson.Marshal(spooled)
+	if err := os.WriteFile(path+".replay", append(first, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append([]byte("not json\n"), append(second, '\n')...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	orders, unreadable, err := ReadSpool(path)
+	if err != nil || unreadable != 1 {
+		t.Fatalf("ReadSpool() = %d unreadable, %v; want 1, nil", unreadable, err)
+	}
+	if len(orders) != 2 || !proto.Equal(orders[0], replaying) || !proto.Equal(orders[1], spooled) {
+		t.Errorf("ReadSpool() = %v, want the order being replayed, then the spooled one", orders)
+	}
+	if _, err := os.Stat(path + ".replay"); err != nil {
+		t.Errorf("ReadSpool() changed the spool: %v", err)
+	}
+}
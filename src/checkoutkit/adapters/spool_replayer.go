@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// defaultReplayInterval is how often the spool is replayed.
+const defaultReplayInterval = 30 * time.Second
+
+// SpoolReplayer republishes the orders of a spool through the primary
+// publisher once it is available again, so that order events kept during an
+// outage still reach consumers. It reports the spool depth on the
+// messaging.publish.backlog gauge and the republished orders on the
+// messaging.publish.replayed counter.
+type SpoolReplayer struct {
+	spool    *SpoolOrderEventPublisher
+	target   ports.OrderEventPublisher
+	logger   *slog.Logger
+	interval time.Duration
+	ready    func() bool
+	store    string
+
+	replayed metric.Int64Counter
+	backlog  metric.Registration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Compile-time check that SpoolReplayer implements Lifecycle
+var _ ports.Lifecycle = (*SpoolReplayer)(nil)
+
+// SpoolReplayerOption configures optional behavior of a SpoolReplayer.
+type SpoolReplayerOption func(*SpoolReplayer)
+
+// WithReplayInterval sets how often the spool is replayed. The default is 30s.
+func WithReplayInterval(interval time.Duration) SpoolReplayerOption {
+	return func(r *SpoolReplayer) {
+		r.interval = interval
+	}
+}
+
+// WithReplayCondition only replays while ready returns true, for example
+// while a FallbackOrderEventPublisher reports its primary healthy.
+func WithReplayCondition(ready func() bool) SpoolReplayerOption {
+	return func(r *SpoolReplayer) {
+		r.ready = ready
+	}
+}
+
+// WithBacklogStore reports the spool depth as store on the
+// messaging.publish.backlog gauge, instead of BacklogStoreSpool, for a spool
+// other than the fallback of the publisher chain.
+func WithBacklogStore(store string) SpoolReplayerOption {
+	return func(r *SpoolReplayer) {
+		r.store = store
+	}
+}
+
+// NewSpoolReplayer creates a replayer that publishes the orders of spool to
+// target and starts replaying in the background.
+func NewSpoolReplayer(spool *SpoolOrderEventPublisher, target ports.OrderEventPublisher, logger *slog.Logger, opts ...SpoolReplayerOption) *SpoolReplayer {
+	r := &SpoolReplayer{
+		spool:    spool,
+		target:   target,
+		logger:   logger,
+		interval: defaultReplayInterval,
+		store:    BacklogStoreSpool,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	var err error
+	r.replayed, err = otel.Meter("checkout-order-events").Int64Counter(
+		"messaging.publish.replayed",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Spooled order events republished to the primary publisher."),
+	)
+	if err != nil {
+		logger.Warn("Failed to create replayed counter", slog.String("error", err.Error()))
+	}
+	r.backlog = observeBacklog(r.store, spool.Depth, logger)
+
+	go r.run()
+	return r
+}
+
+// Replay republishes the spooled orders now, unless the replay condition
+// does not hold, and returns the number of orders republished.
+func (r *SpoolReplayer) Replay(ctx context.Context) (int, error) {
+	if r.ready != nil && !r.ready() {
+		return 0, nil
+	}
+	n, err := r.spool.Replay(ctx, r.target.PublishOrderCompleted)
+	if n > 0 {
+		r.replayed.Add(ctx, int64(n))
+		r.logger.InfoContext(ctx, "Replayed spooled order events", slog.Int("replayed", n))
+	}
+	if err != nil {
+		r.logger.WarnContext(ctx, "Failed to replay spooled order events, keeping them for the next replay",
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+	}
+	return n, err
+}
+
+// Close stops replaying and waits for a replay in progress, or until ctx is
+// done. The orders still spooled are replayed after the next start.
+func (r *SpoolReplayer) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		if r.backlog != nil {
+			r.backlog.Unregister()
+		}
+	})
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run replays the spool every interval until the replayer is closed. The
+// first replay also picks up the orders left by a previous run.
+func (r *SpoolReplayer) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+		// A replay is bounded by the interval, so that a hanging publish
+		// cannot stall the replayer
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		r.Replay(ctx)
+		cancel()
+	}
+}
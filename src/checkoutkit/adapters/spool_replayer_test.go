@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSpoolReplayer(t *testing.T) {
+	reader := newTestMetrics(t)
+	spool := NewSpoolOrderEventPublisher(filepath.Join(t.TempDir(), "orders.spool"), discardLogger())
+	if err := spool.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if got := backlogDepth(t, reader, BacklogStoreSpool); got != -1 {
+		t.Fatalf("backlog before the replayer = %d, want no gauge", got)
+	}
+
+	healthy := false
+	primary := &recordingPublisher{}
+	replayer := NewSpoolReplayer(spool, primary, discardLogger(),
+		WithReplayInterval(time.Hour),
+		WithReplayCondition(func() bool { return healthy }),
+	)
+	defer replayer.Close(context.Background())
+
+	if n, err := replayer.Replay(context.Background()); n != 0 || err != nil || len(primary.orders) != 0 {
+		t.Fatalf("Replay() while the primary is down = %d, %v; want nothing replayed", n, err)
+	}
+	if got := backlogDepth(t, reader, BacklogStoreSpool); got != 1 {
+		t.Errorf("backlog = %d, want 1", got)
+	}
+
+	healthy = true
+	if n, err := replayer.Replay(context.Background()); n != 1 || err != nil || len(primary.orders) != 1 {
+		t.Fatalf("Replay() = %d, %v; want the spooled order replayed", n, err)
+	}
+	if got := backlogDepth(t, reader, BacklogStoreSpool); got != 0 {
+		t.Errorf("backlog after replaying = %d, want 0", got)
+	}
+}
+
+// backlogDepth returns the messaging.publish.backlog gauge of store, or -1
+// if it is not reported.
+func backlogDepth(t *testing.T, reader interface {
+	Collect(context.Context, *metricdata.ResourceMetrics) error
+}, store string) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "messaging.publish.backlog" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+				if v, _ := dp.Attributes.Value(BacklogStoreKey); v.AsString() == store {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return -1
+}
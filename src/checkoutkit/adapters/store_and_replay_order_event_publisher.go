@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// StoreAndReplayOrderEventPublisher is the NoOpOrderEventPublisher for when
+// no broker is available yet but the events must not be lost: it stores the
+// events it skips in a file, in the format of the spool, and Replay publishes
+// them once a broker is available.
+type StoreAndReplayOrderEventPublisher struct {
+	store *SpoolOrderEventPublisher
+}
+
+// Compile-time check that StoreAndReplayOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*StoreAndReplayOrderEventPublisher)(nil)
+
+// Compile-time check that StoreAndReplayOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*StoreAndReplayOrderEventPublisher)(nil)
+
+// NewStoreAndReplayOrderEventPublisher creates a publisher that stores events
+// in the file at path.
+func NewStoreAndReplayOrderEventPublisher(path string, logger *slog.Logger) *StoreAndReplayOrderEventPublisher {
+	return &StoreAndReplayOrderEventPublisher{store: NewSpoolOrderEventPublisher(path, logger)}
+}
+
+// PublishOrderCompleted stores the order for a later Replay.
+func (s *StoreAndReplayOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	return s.store.PublishOrderCompleted(ctx, order)
+}
+
+// Replay publishes the stored orders to target, in order, and removes the
+// ones that were published. Like a spool replay, it stops at the first
+// failure and keeps the rest, and a replay cut short by the process dying
+// starts over, so consumers deduplicate by order ID.
+func (s *StoreAndReplayOrderEventPublisher) Replay(ctx context.Context, target ports.OrderEventPublisher) (int, error) {
+	return s.store.Replay(ctx, target.PublishOrderCompleted)
+}
+
+// Depth returns the number of stored orders.
+func (s *StoreAndReplayOrderEventPublisher) Depth() (int, error) {
+	return s.store.Depth()
+}
+
+// Close closes the store; the stored orders stay in the file.
+func (s *StoreAndReplayOrderEventPublisher) Close(ctx context.Context) error {
+	return s.store.Close(ctx)
+}
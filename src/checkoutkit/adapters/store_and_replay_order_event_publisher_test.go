@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreAndReplayOrderEventPublisher(t *testing.T) {
+	pub := NewStoreAndReplayOrderEventPublisher(filepath.Join(t.TempDir(), "skipped.jsonl"), discardLogger())
+	ctx := context.Background()
+	for range 2 {
+		if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil {
+			t.Fatalf("PublishOrderCompleted() = %v", err)
+		}
+	}
+
+	target := NewInMemoryOrderEventPublisher()
+	down := errors.New("broker down")
+	target.FailNext(down)
+	if n, err := pub.Replay(ctx, target); n != 0 || !errors.Is(err, down) {
+		t.Errorf("Replay() with the broker down = %d, %v, want 0, %v", n, err, down)
+	}
+	if depth, _ := pub.Depth(); depth != 2 {
+		t.Errorf("Depth() = %d after a failed replay, want 2 orders kept", depth)
+	}
+
+	if n, err := pub.Replay(ctx, target); n != 2 || err != nil {
+		t.Fatalf("Replay() = %d, %v, want 2, nil", n, err)
+	}
+	if depth, _ := pub.Depth(); depth != 0 || target.Len() != 2 {
+		t.Errorf("Depth() = %d and target received %d orders, want 0 and 2", depth, target.Len())
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+// SwappableOrderEventPublisher publishes through a publisher that can be
+// replaced while orders are published, such as the publisher chain when its
+// configuration is reloaded. A publish runs to completion on the publisher it
+// started on, and the replaced publisher is closed once its publishes are
+// done.
+type SwappableOrderEventPublisher struct {
+	mu      sync.RWMutex
+	current *swappedPublisher
+}
+
+// swappedPublisher is a publisher of a SwappableOrderEventPublisher with the
+// publishes in flight on it.
+type swappedPublisher struct {
+	publisher ports.OrderEventPublisher
+	inFlight  sync.WaitGroup
+}
+
+// Compile-time check that SwappableOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*SwappableOrderEventPublisher)(nil)
+
+// Compile-time check that SwappableOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*SwappableOrderEventPublisher)(nil)
+
+// NewSwappableOrderEventPublisher publishes through publisher until it is
+// swapped.
+func NewSwappableOrderEventPublisher(publisher ports.OrderEventPublisher) *SwappableOrderEventPublisher {
+	return &SwappableOrderEventPublisher{current: &swappedPublisher{publisher: publisher}}
+}
+
+// PublishOrderCompleted publishes order through the current publisher.
+func (s *SwappableOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	s.mu.RLock()
+	current := s.current
+	current.inFlight.Add(1)
+	s.mu.RUnlock()
+	defer current.inFlight.Done()
+	return current.publisher.PublishOrderCompleted(ctx, order)
+}
+
+// Current returns the publisher orders are published through.
+func (s *SwappableOrderEventPublisher) Current() ports.OrderEventPublisher {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current.publisher
+}
+
+// Swap publishes the next orders through publisher, then drains the replaced
+// publisher: it waits for the publishes in flight on it and closes it. When
+// ctx ends first, the replaced publisher is closed without waiting further
+// and the error of ctx is returned.
+func (s *SwappableOrderEventPublisher) Swap(ctx context.Context, publisher ports.OrderEventPublisher) error {
+	s.mu.Lock()
+	replaced := s.current
+	s.current = &swappedPublisher{publisher: publisher}
+	s.mu.Unlock()
+	return replaced.drain(ctx)
+}
+
+// Close drains the current publisher.
+func (s *SwappableOrderEventPublisher) Close(ctx context.Context) error {
+	s.mu.RLock()
+	current := s.current
+	s.mu.RUnlock()
+	return current.drain(ctx)
+}
+
+// drain waits for the publishes in flight, up to ctx, then closes the
+// publisher.
+func (p *swappedPublisher) drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.inFlight.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("publishes still in flight: %w", ctx.Err())
+	}
+	return errors.Join(err, closeIfLifecycle(ctx, p.publisher))
+}
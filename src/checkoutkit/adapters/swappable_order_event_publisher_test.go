@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// blockingPublisher holds every publish until release is closed.
+type blockingPublisher struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu     sync.Mutex
+	orders int
+	closed bool
+}
+
+func newBlockingPublisher() *blockingPublisher {
+	return &blockingPublisher{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (b *blockingPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	b.started <- struct{}{}
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.orders++
+	return nil
+}
+
+func (b *blockingPublisher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *blockingPublisher) state() (orders int, closed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.orders, b.closed
+}
+
+func TestSwappableOrderEventPublisherDrainsOnSwap(t *testing.T) {
+	old := newBlockingPublisher()
+	next := &recordingPublisher{}
+	pub := NewSwappableOrderEventPublisher(old)
+
+	published := make(chan error, 1)
+	go func() { published <- pub.PublishOrderCompleted(context.Background(), testOrder()) }()
+	<-old.started
+
+	swapped := make(chan error, 1)
+	go func() { swapped <- pub.Swap(context.Background(), next) }()
+	for pub.Current() != next {
+		time.Sleep(time.Millisecond)
+	}
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() after the swap = %v", err)
+	}
+	if len(next.orders) != 1 {
+		t.Errorf("new publisher got %d orders, want 1", len(next.orders))
+	}
+	if _, closed := old.state(); closed {
+		t.Fatal("Swap() closed the replaced publisher with a publish in flight")
+	}
+
+	close(old.release)
+	if err := <-published; err != nil {
+		t.Errorf("publish in flight = %v", err)
+	}
+	if err := <-swapped; err != nil {
+		t.Errorf("Swap() = %v", err)
+	}
+	if orders, closed := old.state(); orders != 1 || !closed {
+		t.Errorf("replaced publisher published %d orders and closed: %v, want 1 and true", orders, closed)
+	}
+}
+
+func TestSwappableOrderEventPublisherSwapTimeout(t *testing.T) {
+	old := newBlockingPublisher()
+	defer close(old.release)
+	pub := NewSwappableOrderEventPublisher(old)
+	go pub.PublishOrderCompleted(context.Background(), testOrder())
+	<-old.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pub.Swap(ctx, &recordingPublisher{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Swap() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, closed := old.state(); !closed {
+		t.Error("Swap() did not close the replaced publisher after the timeout")
+	}
+}
+
+func TestSwappableOrderEventPublisherClose(t *testing.T) {
+	current := newBlockingPublisher()
+	close(current.release)
+	pub := NewSwappableOrderEventPublisher(current)
+	if err := pub.Close(context.Background()); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if _, closed := current.state(); !closed {
+		t.Error("Close() did not close the current publisher")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+)
+
+//go:embed templates/order_confirmation.*.tmpl
+var confirmationTemplates embed.FS
+
+// TemplateOrderConfirmationRenderer implements the OrderConfirmationRenderer
+// port with the templates in adapters/templates. The subject and the plain
+// text body are text/template templates; the HTML body is an html/template
+// template, so the order's values are escaped.
+type TemplateOrderConfirmationRenderer struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Compile-time check that TemplateOrderConfirmationRenderer implements OrderConfirmationRenderer
+var _ ports.OrderConfirmationRenderer = (*TemplateOrderConfirmationRenderer)(nil)
+
+// NewTemplateOrderConfirmationRenderer creates a renderer from the embedded
+// templates.
+func NewTemplateOrderConfirmationRenderer() *TemplateOrderConfirmationRenderer {
+	return &TemplateOrderConfirmationRenderer{
+		subject: texttemplate.Must(texttemplate.ParseFS(confirmationTemplates, "templates/order_confirmation.subject.tmpl")),
+		text:    texttemplate.Must(texttemplate.ParseFS(confirmationTemplates, "templates/order_confirmation.txt.tmpl")),
+		html:    htmltemplate.Must(htmltemplate.ParseFS(confirmationTemplates, "templates/order_confirmation.html.tmpl")),
+	}
+}
+
+// confirmationView is what the templates render. Amounts are formatted, and
+// the total is the items times their quantity plus shipping.
+type confirmationView struct {
+	OrderID    string
+	TrackingID string
+	Address    *pb.Address
+	Items      []confirmationItem
+	Shipping   string
+	Total      string
+}
+
+type confirmationItem struct {
+	ProductID string
+	Quantity  int32
+	Cost      string
+}
+
+// RenderOrderConfirmation renders the subject and both bodies of the
+// confirmation of order.
+func (r *TemplateOrderConfirmationRenderer) RenderOrderConfirmation(order *pb.OrderResult) (ports.OrderConfirmation, error) {
+	view, err := newConfirmationView(order)
+	if err != nil {
+		return ports.OrderConfirmation{}, err
+	}
+
+	var subject, text, html strings.Builder
+	if err := r.subject.Execute(&subject, view); err != nil {
+		return ports.OrderConfirmation{}, fmt.Errorf("failed to render the confirmation subject: %w", err)
+	}
+	if err := r.text.Execute(&text, view); err != nil {
+		return ports.OrderConfirmation{}, fmt.Errorf("failed to render the confirmation text: %w", err)
+	}
+	if err := r.html.Execute(&html, view); err != nil {
+		return ports.OrderConfirmation{}, fmt.Errorf("failed to render the confirmation HTML: %w", err)
+	}
+	return ports.OrderConfirmation{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func newConfirmationView(order *pb.OrderResult) (confirmationView, error) {
+	view := confirmationView{
+		OrderID:    order.GetOrderId(),
+		TrackingID: order.GetShippingTrackingId(),
+		Address:    order.GetShippingAddress(),
+		Shipping:   formatMoney(order.GetShippingCost()),
+	}
+	total := order.GetShippingCost()
+	for _, item := range order.GetItems() {
+		view.Items = append(view.Items, confirmationItem{
+			ProductID: item.GetItem().GetProductId(),
+			Quantity:  item.GetItem().GetQuantity(),
+			Cost:      formatMoney(item.GetCost()),
+		})
+		var err error
+		total, err = money.Sum(total, money.MultiplySlow(item.GetCost(), uint32(item.GetItem().GetQuantity())))
+		if err != nil {
+			return view, fmt.Errorf("failed to total order %s: %w", order.GetOrderId(), err)
+		}
+	}
+	view.Total = formatMoney(total)
+	return view, nil
+}
+
+// formatMoney formats m as its currency code and amount in cents, for
+// example USD 19.99.
+func formatMoney(m *pb.Money) string {
+	units, nanos, sign := m.GetUnits(), m.GetNanos(), ""
+	if units < 0 || nanos < 0 {
+		units, nanos, sign = -units, -nanos, "-"
+	}
+	return fmt.Sprintf("%s %s%d.%02d", m.GetCurrencyCode(), sign, units, nanos/10_000_000)
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestTemplateOrderConfirmationRendererGolden(t *testing.T) {
+	order := testOrder()
+	order.ShippingCost = &pb.Money{CurrencyCode: "USD", Units: 8, Nanos: 990_000_000}
+	order.ShippingAddress = &pb.Address{StreetAddress: "1600 Amphitheatre Parkway", City: "Mountain View", State: "CA", Country: "USA", ZipCode: "94043"}
+	order.Items = append(order.Items, &pb.OrderItem{
+		Item: &pb.CartItem{ProductId: "<script>", Quantity: 1},
+		Cost: &pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 500_000_000},
+	})
+
+	got, err := NewTemplateOrderConfirmationRenderer().RenderOrderConfirmation(order)
+	if err != nil {
+		t.Fatalf("RenderOrderConfirmation() = %v", err)
+	}
+	testdata.MatchGolden(t, "order_confirmation.subject.golden", []byte(got.Subject))
+	testdata.MatchGolden(t, "order_confirmation.txt.golden", []byte(got.Text))
+	testdata.MatchGolden(t, "order_confirmation.html.golden", []byte(got.HTML))
+	if strings.Contains(got.HTML, "<script>") {
+		t.Error("HTML body contains an unescaped product ID")
+	}
+}
+
+func TestTemplateOrderConfirmationRendererRejectsMixedCurrencies(t *testing.T) {
+	order := testOrder()
+	order.ShippingCost = &pb.Money{CurrencyCode: "EUR", Units: 5}
+
+	if _, err := NewTemplateOrderConfirmationRenderer().RenderOrderConfirmation(order); err == nil {
+		t.Error("RenderOrderConfirmation() with mixed currencies = nil, want an error")
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		m    *pb.Money
+		want string
+	}{
+		{&pb.Money{CurrencyCode: "USD", Units: 19, Nanos: 990_000_000}, "USD 19.99"},
+		{&pb.Money{CurrencyCode: "EUR", Units: 5}, "EUR 5.00"},
+		{&pb.Money{CurrencyCode: "USD", Units: 0, Nanos: 50_000_000}, "USD 0.05"},
+		{&pb.Money{CurrencyCode: "USD", Units: -1, Nanos: -250_000_000}, "USD -1.25"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney(tt.m); got != tt.want {
+			t.Errorf("formatMoney(%v) = %q, want %q", tt.m, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// ValidatingOrderEventPublisher is a decorator that validates an OrderResult
+// before handing it to the wrapped publisher. Invalid orders are never published;
+// instead a *validation.Error describing every violation is returned.
+type ValidatingOrderEventPublisher struct {
+	next   ports.OrderEventPublisher
+	logger *slog.Logger
+}
+
+// Compile-time check that ValidatingOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*ValidatingOrderEventPublisher)(nil)
+
+// Compile-time check that ValidatingOrderEventPublisher implements Lifecycle
+var _ ports.Lifecycle = (*ValidatingOrderEventPublisher)(nil)
+
+// NewValidatingOrderEventPublisher wraps next with OrderResult validation.
+func NewValidatingOrderEventPublisher(next ports.OrderEventPublisher, logger *slog.Logger) *ValidatingOrderEventPublisher {
+	return &ValidatingOrderEventPublisher{
+		next:   next,
+		logger: logger,
+	}
+}
+
+// PublishOrderCompleted validates the order and, if valid, publishes it through
+// the wrapped publisher.
+func (v *ValidatingOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	if err := validation.ValidateOrderResult(order); err != nil {
+		err = errcode.Wrap(errcode.ValidationFailed, err)
+		errcode.RecordSpan(trace.SpanFromContext(ctx), err, "order event failed validation")
+		v.logger.WarnContext(ctx, "Refusing to publish invalid order event",
+			slog.String("order_id", order.GetOrderId()),
+			slog.String("error", err.Error()),
+			errcode.Attr(err),
+		)
+		return err
+	}
+	return v.next.PublishOrderCompleted(ctx, order)
+}
+
+// Close closes the wrapped publisher.
+func (v *ValidatingOrderEventPublisher) Close(ctx context.Context) error {
+	return closeIfLifecycle(ctx, v.next)
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports/mocks"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/validation"
+)
+
+// recordingPublisher records every order that reaches it.
+type recordingPublisher struct {
+	orders []*pb.OrderResult
+	err    error
+}
+
+func (r *recordingPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	r.orders = append(r.orders, order)
+	return r.err
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func testOrder() *pb.OrderResult {
+	return testdata.NewOrder().
+		WithItem("SKU-1", 2, testdata.USD(3)).
+		WithShipping(testdata.USD(5)).
+		Build()
+}
+
+func TestValidatingOrderEventPublisherPassesValidOrders(t *testing.T) {
+	order := testOrder()
+	next := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+	next.EXPECT().PublishOrderCompleted(gomock.Any(), order)
+	pub := NewValidatingOrderEventPublisher(next, discardLogger())
+
+	if err := pub.PublishOrderCompleted(context.Background(), order); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+}
+
+func TestValidatingOrderEventPublisherRejectsInvalidOrders(t *testing.T) {
+	// Without expectations, the mock fails the test if the order reaches it
+	next := mocks.NewMockOrderEventPublisher(gomock.NewController(t))
+	pub := NewValidatingOrderEventPublisher(next, discardLogger())
+
+	order := testOrder()
+	order.OrderId = ""
+	order.ShippingCost.CurrencyCode = "us"
+
+	err := pub.PublishOrderCompleted(context.Background(), order)
+	var verr *validation.Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("PublishOrderCompleted() = %v, want *validation.Error", err)
+	}
+	if len(verr.Violations) != 2 {
+		t.Errorf("got %d violations, want 2", len(verr.Violations))
+	}
+}
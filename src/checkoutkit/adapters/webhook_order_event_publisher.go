@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Headers of a signed webhook POST. The signature is "sha256=" followed by
+// the hex HMAC-SHA256 of the timestamp, a dot and the body, see
+// WebhookSignature.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+// Retries of a webhook POST by default.
+const (
+	defaultWebhookMaxAttempts = 3
+	defaultWebhookBackoff     = 200 * time.Millisecond
+)
+
+// WebhookOrderEventPublisher implements the OrderEventPublisher port by
+// POSTing each order, in the consumer JSON format, to one or more HTTP
+// endpoints. It suits consumers that cannot read from Kafka. Any 2xx response
+// acknowledges the event. Transport errors, 429 and 5xx responses are retried
+// with exponential backoff, other responses fail at once.
+type WebhookOrderEventPublisher struct {
+	urls        []string
+	client      *http.Client
+	logger      *slog.Logger
+	secret      []byte
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// Compile-time check that WebhookOrderEventPublisher implements OrderEventPublisher
+var _ ports.OrderEventPublisher = (*WebhookOrderEventPublisher)(nil)
+
+// WebhookPublisherOption configures optional behavior of a
+// WebhookOrderEventPublisher.
+type WebhookPublisherOption func(*WebhookOrderEventPublisher)
+
+// WithWebhookSecret signs every POST with secret, so that the endpoints can
+// tell the checkout service's events from forged ones.
+func WithWebhookSecret(secret string) WebhookPublisherOption {
+	return func(w *WebhookOrderEventPublisher) {
+		w.secret = []byte(secret)
+	}
+}
+
+// WithWebhookRetries tries a POST to each URL up to maxAttempts times,
+// waiting backoff before the first retry and twice as long before each of the
+// next ones. The default is 3 attempts with a 200ms backoff, and 1 attempt
+// disables retrying.
+func WithWebhookRetries(maxAttempts int, backoff time.Duration) WebhookPublisherOption {
+	return func(w *WebhookOrderEventPublisher) {
+		w.maxAttempts = max(maxAttempts, 1)
+		w.backoff = backoff
+	}
+}
+
+// NewWebhookOrderEventPublisher creates a publisher that POSTs orders to each
+// of urls. A nil client uses one instrumented with otelhttp.
+func NewWebhookOrderEventPublisher(urls []string, client *http.Client, logger *slog.Logger, opts ...WebhookPublisherOption) *WebhookOrderEventPublisher {
+	if client == nil {
+		client = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	}
+	w := &WebhookOrderEventPublisher{
+		urls:        urls,
+		client:      client,
+		logger:      logger,
+		maxAttempts: defaultWebhookMaxAttempts,
+		backoff:     defaultWebhookBackoff,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// WebhookSignature returns the value of WebhookSignatureHeader for a POST of
+// body at timestamp, in Unix seconds, signed with secret. Endpoints verify a
+// POST by comparing it to the header with hmac.Equal, and reject old
+// timestamps to stop replays.
+func WebhookSignature(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PublishOrderCompleted POSTs the order to every webhook at once. It fails
+// unless every webhook acknowledged the order, so that the fallback keeps it;
+// the webhooks that did acknowledge it receive it again when it is replayed.
+func (w *WebhookOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *pb.OrderResult) error {
+	event, err := serialization.ToConsumerJSON(order)
+	if err != nil {
+		return errcode.Wrap(errcode.SerializationFailed, err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errcode.Errorf(errcode.SerializationFailed, "failed to marshal order event: %w", err)
+	}
+
+	errs := make([]error, len(w.urls))
+	var wg sync.WaitGroup
+	for i, url := range w.urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = w.deliver(ctx, url, order.GetOrderId(), body)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return errcode.Wrap(errcode.WebhookDeliveryFailed, err)
+	}
+	return nil
+}
+
+// deliver POSTs body to url until it is acknowledged, it fails for good or
+// the attempts run out.
+func (w *WebhookOrderEventPublisher) deliver(ctx context.Context, url, orderID string, body []byte) error {
+	wait := w.backoff
+	for attempt := 1; ; attempt++ {
+		status, retry, err := w.post(ctx, url, body)
+		if err == nil {
+			w.logger.InfoContext(ctx, "Delivered order event to webhook",
+				slog.String("order_id", orderID),
+				slog.String("url", url),
+				slog.Int("status", status),
+				slog.Int("attempt", attempt),
+			)
+			return nil
+		}
+		if !retry || attempt >= w.maxAttempts {
+			return err
+		}
+		w.logger.WarnContext(ctx, "Failed to deliver order event to webhook, retrying",
+			slog.String("order_id", orderID),
+			slog.String("url", url),
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", wait),
+			slog.String("error", err.Error()),
+		)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("%w, last attempt: %w", ctx.Err(), err)
+		}
+		wait *= 2
+	}
+}
+
+// post POSTs body to url once, and reports whether a failure is worth
+// retrying.
+func (w *WebhookOrderEventPublisher) post(ctx context.Context, url string, body []byte) (status int, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range MessageHeaders(ctx) {
+		req.Header.Set(key, value)
+	}
+	if w.secret != nil {
+		timestamp := time.Now().Unix()
+		req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(w.secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, ctx.Err() == nil, fmt.Errorf("failed to POST order event to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return resp.StatusCode, retry, fmt.Errorf("webhook %s answered %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp.StatusCode, false, nil
+}
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/errcode"
+)
+
+func TestWebhookOrderEventPublisher(t *testing.T) {
+	var event map[string]any
+	var contentType, backfill, signature string
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		backfill = r.Header.Get(BackfillHeader)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		json.NewDecoder(r.Body).Decode(&event)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	pub := NewWebhookOrderEventPublisher([]string{srv.URL}, nil, discardLogger(), WithWebhookRetries(1, 0))
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if contentType != "application/json" || event["orderId"] != testOrder().OrderId || event["shippingTrackingId"] != "trk-1" {
+		t.Errorf("webhook received %v (%s), want the order in consumer JSON", event, contentType)
+	}
+	if signature != "" {
+		t.Errorf("%s = %q without a secret, want none", WebhookSignatureHeader, signature)
+	}
+
+	ctx := WithMessageHeaders(context.Background(), map[string]string{BackfillHeader: "true"})
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); err != nil || backfill != "true" {
+		t.Errorf("PublishOrderCompleted() = %v with %s header %q, want the header forwarded", err, BackfillHeader, backfill)
+	}
+
+	status = http.StatusInternalServerError
+	err := pub.PublishOrderCompleted(context.Background(), testOrder())
+	if errcode.Of(err) != errcode.WebhookDeliveryFailed {
+		t.Errorf("PublishOrderCompleted() = %v, want a %s error", err, errcode.WebhookDeliveryFailed)
+	}
+}
+
+func TestWebhookOrderEventPublisherSignature(t *testing.T) {
+	secret := []byte("s3cret")
+	var verified bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		verified = err == nil && time.Since(time.Unix(timestamp, 0)) < time.Minute &&
+			r.Header.Get(WebhookSignatureHeader) == WebhookSignature(secret, timestamp, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	pub := NewWebhookOrderEventPublisher([]string{srv.URL}, nil, discardLogger(), WithWebhookSecret(string(secret)))
+
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Fatalf("PublishOrderCompleted() = %v", err)
+	}
+	if !verified {
+		t.Error("webhook could not verify the signature of the order event")
+	}
+	if got := WebhookSignature(secret, 1, []byte("{}")); got == WebhookSignature([]byte("other"), 1, []byte("{}")) || got[:7] != "sha256=" {
+		t.Errorf("WebhookSignature() = %s, want a sha256 HMAC depending on the secret", got)
+	}
+}
+
+func TestWebhookOrderEventPublisherRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{name: "unavailable then accepted", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, wantAttempts: 3},
+		{name: "unavailable until attempts run out", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}, wantErr: true, wantAttempts: 3},
+		{name: "bad request not retried", statuses: []int{http.StatusBadRequest, http.StatusOK}, wantErr: true, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[attempts.Add(1)-1])
+			}))
+			defer srv.Close()
+			pub := NewWebhookOrderEventPublisher([]string{srv.URL}, nil, discardLogger(), WithWebhookRetries(3, time.Millisecond))
+
+			err := pub.PublishOrderCompleted(context.Background(), testOrder())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublishOrderCompleted() = %v, want error %v", err, tt.wantErr)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("webhook received %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWebhookOrderEventPublisherRetryStopsWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	pub := NewWebhookOrderEventPublisher([]string{srv.URL}, nil, discardLogger(), WithWebhookRetries(5, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pub.PublishOrderCompleted(ctx, testOrder()); errcode.Of(err) != errcode.WebhookDeliveryFailed {
+		t.Errorf("PublishOrderCompleted() = %v, want a %s error once the context ended", err, errcode.WebhookDeliveryFailed)
+	}
+}
+
+func TestWebhookOrderEventPublisherMultipleURLs(t *testing.T) {
+	var first, second atomic.Int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first.Add(1)
+	}))
+	defer ok.Close()
+	status := http.StatusBadRequest
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		second.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer other.Close()
+	pub := NewWebhookOrderEventPublisher([]string{ok.URL, other.URL}, nil, discardLogger(), WithWebhookRetries(1, 0))
+
+	// One webhook rejecting the order fails it, so that the fallback keeps it
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); errcode.Of(err) != errcode.WebhookDeliveryFailed {
+		t.Errorf("PublishOrderCompleted() = %v, want a %s error", err, errcode.WebhookDeliveryFailed)
+	}
+	status = http.StatusOK
+	if err := pub.PublishOrderCompleted(context.Background(), testOrder()); err != nil {
+		t.Errorf("PublishOrderCompleted() = %v", err)
+	}
+	if first.Load() != 2 || second.Load() != 2 {
+		t.Errorf("webhooks received %d and %d orders, want 2 each", first.Load(), second.Load())
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package checkoutkit is the root of the module holding the ports and
+// adapters of the checkout service, and the pact harness of its contracts,
+// for the services publishing or consuming order events. The packages are in
+// its subdirectories; this one only generates their code.
+package checkoutkit
+
+//go:generate go install google.golang.org/protobuf/cmd/protoc-gen-go
+//go:generate go install google.golang.org/grpc/cmd/protoc-gen-go-grpc
+//go:generate go install go.uber.org/mock/mockgen@v0.5.2
+//go:generate protoc --go_out=./ --go-grpc_out=./ --proto_path=../../pb ../../pb/demo.proto
@@ -0,0 +1,662 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package config reads the configuration of the checkout service from the
+// environment into typed structs, validating every variable up front.
+package config
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/loglevel"
+)
+
+// Config is the configuration of the checkout service.
+type Config struct {
+	// Port is the port of the gRPC server
+	Port string `env:"CHECKOUT_PORT,required"`
+	// HTTPAddr enables the REST and GraphQL facades
+	HTTPAddr string `env:"CHECKOUT_HTTP_ADDR"`
+	// ReadinessAddr enables the HTTP readiness probe
+	ReadinessAddr string `env:"CHECKOUT_READINESS_ADDR"`
+	// DebugAddr enables the debug listener
+	DebugAddr string `env:"CHECKOUT_DEBUG_ADDR"`
+	// AdminAddr enables the PublisherAdmin gRPC service
+	AdminAddr string `env:"CHECKOUT_ADMIN_ADDR"`
+	// Debug checks that every order event survives serialization
+	Debug    bool   `env:"CHECKOUT_DEBUG"`
+	LogLevel string `env:"LOG_LEVEL"`
+	// ShutdownTimeout bounds the graceful shutdown
+	ShutdownTimeout time.Duration `env:"CHECKOUT_SHUTDOWN_TIMEOUT" default:"25s" min:"0s"`
+
+	Startup        Startup
+	Services       Services
+	Carrier        Carrier
+	CurrencyRates  CurrencyRates
+	Kafka          Kafka
+	SchemaRegistry SchemaRegistry
+	OrderEvents    OrderEvents
+	// PublisherReload is read from the environment only
+	PublisherReload PublisherReload
+	PlaceOrder      PlaceOrder
+	PublishSLO      PublishSLO
+	PublishRetry    PublishRetry
+	PublishBuffer   PublishBuffer
+	Telemetry       Telemetry
+}
+
+// Startup configures the dependency probe on boot.
+type Startup struct {
+	// ProbeTimeout is how long to wait for dependencies, 0 disables probing
+	ProbeTimeout time.Duration `env:"CHECKOUT_STARTUP_PROBE_TIMEOUT" default:"60s" min:"0s"`
+	// Degraded starts the service while dependencies are still down
+	Degraded bool `env:"CHECKOUT_STARTUP_DEGRADED"`
+}
+
+// Services are the addresses of the downstream gRPC services.
+type Services struct {
+	Shipping       string `env:"SHIPPING_ADDR,required"`
+	ProductCatalog string `env:"PRODUCT_CATALOG_ADDR,required"`
+	Cart           string `env:"CART_ADDR,required"`
+	Currency       string `env:"CURRENCY_ADDR,required"`
+	Email          string `env:"EMAIL_ADDR,required"`
+	Payment        string `env:"PAYMENT_ADDR,required"`
+}
+
+// Carrier configures the third-party carrier shipping provider, which is
+// registered when URL is set.
+type Carrier struct {
+	URL    string `env:"SHIPPING_CARRIER_API_URL"`
+	APIKey string `env:"SHIPPING_CARRIER_API_KEY"`
+}
+
+// CurrencyRates configures the cache of exchange rates used to convert costs
+// to the currency of the user.
+type CurrencyRates struct {
+	// TTL is how long a rate is used before it is fetched again
+	TTL time.Duration `env:"CURRENCY_RATE_TTL" default:"1m" min:"0s"`
+	// MaxStaleness is how old a rate may get while the currency service fails
+	// to refresh it, after which orders in that currency fail
+	MaxStaleness time.Duration `env:"CURRENCY_RATE_MAX_STALENESS" default:"15m" min:"0s"`
+}
+
+// Kafka configures the Kafka order event publisher.
+type Kafka struct {
+	Addr            string `env:"KAFKA_ADDR"`
+	ProducerTracing bool   `env:"KAFKA_PRODUCER_TRACING"`
+	// ProducerMode is async, which queues order events on a batching
+	// producer, sync, which sends each one and waits for every in-sync
+	// replica before PlaceOrder responds, or transactional, which also sends
+	// each one in a transaction of its own
+	ProducerMode string `env:"KAFKA_PRODUCER_MODE" default:"async" oneof:"async sync transactional"`
+	// Idempotent lets the broker discard the messages the producer sent
+	// again after a lost acknowledgment; transactional producers always are
+	Idempotent bool `env:"KAFKA_IDEMPOTENT"`
+	// Topic receives the order events, prefixed with Region if set
+	Topic string `env:"KAFKA_TOPIC" default:"orders"`
+	// DecodeMode is lenient, which ignores the fields a reader does not
+	// know, or strict, which rejects them and the orders missing fields
+	// consumers require. The tools reading the order topics decode with it
+	DecodeMode string `env:"ORDER_EVENT_DECODE_MODE" default:"lenient" oneof:"lenient strict"`
+	// MessageKey is none or order_id, which keeps the events of an order on
+	// one partition
+	MessageKey  string `env:"KAFKA_MESSAGE_KEY" default:"none" oneof:"none order_id"`
+	Partitioner string `env:"KAFKA_PARTITIONER" default:"hash" oneof:"hash random roundrobin"`
+	// Headers are added to every order event as name=value pairs
+	Headers []string `env:"KAFKA_HEADERS"`
+	// BatchMessages and BatchBytes send the order events of a partition
+	// together once there are that many, and BatchLinger once the first has
+	// waited that long; all zero sends them right away
+	BatchMessages int           `env:"KAFKA_BATCH_MESSAGES" min:"0"`
+	BatchBytes    int           `env:"KAFKA_BATCH_BYTES" min:"0"`
+	BatchLinger   time.Duration `env:"KAFKA_BATCH_LINGER" min:"0s"`
+	// PublishTimeout bounds the wait for an acknowledgment independently of
+	// the request, 0 waits until the request ends
+	PublishTimeout time.Duration `env:"KAFKA_PUBLISH_TIMEOUT" min:"0s"`
+	// AckMode is wait, which answers PlaceOrder once its order event is
+	// acknowledged, or background, which answers once it is queued
+	AckMode string `env:"KAFKA_ACK_MODE" default:"wait" oneof:"wait background"`
+	// SlowPublishThreshold logs publishes slower than it, 0 disables logging
+	SlowPublishThreshold  time.Duration `env:"KAFKA_SLOW_PUBLISH_THRESHOLD" min:"0s"`
+	SemconvStabilityOptIn string        `env:"OTEL_SEMCONV_STABILITY_OPT_IN"`
+	// TransactionalID identifies the transactional producer of the order
+	// event outbox, and defaults to checkout- followed by the host name. The
+	// transactional publisher adds -publisher, and its region, to it
+	TransactionalID string `env:"KAFKA_TRANSACTIONAL_ID"`
+	// Region prefixes the topics of KAFKA_ADDR, such as us-east-1.orders
+	Region string `env:"KAFKA_REGION"`
+	// SecondaryAddr is the broker of the region order events fail over to,
+	// whose topics SecondaryRegion prefixes
+	SecondaryAddr   string `env:"KAFKA_SECONDARY_ADDR"`
+	SecondaryRegion string `env:"KAFKA_SECONDARY_REGION"`
+	// FailoverAfter is the number of consecutive failed publishes after which
+	// order events fail over to the secondary region
+	FailoverAfter int `env:"KAFKA_FAILOVER_AFTER" default:"5" min:"1"`
+	// FailbackInterval is how long order events stay on the secondary region
+	// before the primary region is pinged again
+	FailbackInterval time.Duration `env:"KAFKA_FAILBACK_INTERVAL" default:"1m" min:"1s"`
+}
+
+// MultiRegion reports whether order events fail over to a secondary region.
+func (k Kafka) MultiRegion() bool {
+	return k.SecondaryAddr != ""
+}
+
+// Batching reports whether order events are batched.
+func (k Kafka) Batching() bool {
+	return k.BatchMessages > 0 || k.BatchBytes > 0 || k.BatchLinger > 0
+}
+
+// StaticHeaders returns Headers by name. Invalid pairs, which LoadFrom
+// rejects, are skipped.
+func (k Kafka) StaticHeaders() map[string]string {
+	headers := make(map[string]string, len(k.Headers))
+	for _, pair := range k.Headers {
+		if name, value, ok := parseHeader(pair); ok {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+// parseHeader parses a name=value pair with a non-empty name.
+func parseHeader(pair string) (string, string, bool) {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(value), true
+}
+
+// SchemaRegistry configures the registration of the order event schema,
+// which is skipped when URL is unset.
+type SchemaRegistry struct {
+	URL           string `env:"SCHEMA_REGISTRY_URL"`
+	Kind          string `env:"SCHEMA_REGISTRY_KIND" default:"confluent" oneof:"confluent apicurio"`
+	Compatibility string `env:"SCHEMA_REGISTRY_COMPATIBILITY" default:"BACKWARD" oneof:"NONE BACKWARD BACKWARD_TRANSITIVE FORWARD FORWARD_TRANSITIVE FULL FULL_TRANSITIVE"`
+	// OnIncompatible is fail to stop the service on an incompatible schema,
+	// or spool to spool order events instead
+	OnIncompatible string `env:"SCHEMA_REGISTRY_ON_INCOMPATIBLE" default:"fail" oneof:"fail spool"`
+}
+
+// OrderEvents selects the order event publisher and its fallback.
+type OrderEvents struct {
+	// Publisher is kafka, webhook, nats, sns, pubsub, mqtt, spool, file,
+	// memory, composite, outbox, noop or a kind registered with adapters.Register,
+	// which checks it. It defaults to kafka when KAFKA_ADDR is set and noop
+	// otherwise.
+	Publisher string `env:"ORDER_EVENT_PUBLISHER"`
+	// Fallback is spool, file, webhook, noop or none; file and webhook are
+	// configured by their own variables
+	Fallback string `env:"ORDER_EVENT_FALLBACK" default:"spool" oneof:"spool file webhook noop none"`
+	// FallbackRecheckInterval is how long a failed primary is bypassed
+	FallbackRecheckInterval time.Duration `env:"ORDER_EVENT_FALLBACK_RECHECK_INTERVAL" default:"30s" min:"1ms"`
+	// SpoolPath defaults to checkout-order-events.spool in the temporary directory
+	SpoolPath string `env:"ORDER_EVENT_SPOOL_PATH"`
+	// SpoolReplayInterval is how often orders spooled by the fallback are
+	// replayed to the primary publisher, 0 disables replaying
+	SpoolReplayInterval time.Duration `env:"ORDER_EVENT_SPOOL_REPLAY_INTERVAL" default:"30s" min:"0s"`
+	// NoOpStorePath keeps the order events skipped by the noop publisher and
+	// fallback in a file, for a later replay
+	NoOpStorePath string `env:"ORDER_EVENT_NOOP_STORE_PATH"`
+	// Outbox publishes the OrderPlaced, PaymentCaptured and OrderResult events
+	// of an order together once it completed, from the outbox table of
+	// OutboxDSN if set and from memory otherwise
+	Outbox bool `env:"ORDER_EVENT_OUTBOX"`
+	// OutboxDSN is the Postgres connection string of the database of the
+	// outbox table, which ORDER_EVENT_OUTBOX and the outbox publisher write
+	// to and whose relay runs in the service
+	OutboxDSN string `env:"ORDER_EVENT_OUTBOX_DSN"`
+	// OutboxTable is the name of the outbox table
+	OutboxTable string `env:"ORDER_EVENT_OUTBOX_TABLE" default:"order_event_outbox"`
+	// OutboxPollInterval is how often the relay polls the outbox table
+	OutboxPollInterval time.Duration `env:"ORDER_EVENT_OUTBOX_POLL_INTERVAL" default:"1s" min:"1ms"`
+	// OutboxRelayPublisher is the kind of the publisher the relay publishes
+	// through with ORDER_EVENT_PUBLISHER=outbox, any kind but outbox
+	OutboxRelayPublisher string `env:"ORDER_EVENT_OUTBOX_RELAY_PUBLISHER" default:"kafka"`
+	// SchemaVersion is the version of the order events; version 2 adds the
+	// payments of the order as a header, and version 3 its fees
+	SchemaVersion int `env:"ORDER_EVENT_SCHEMA_VERSION" default:"1" min:"1" max:"3"`
+
+	Webhook   Webhook
+	NATS      NATS
+	SNS       SNS
+	PubSub    PubSub
+	MQTT      MQTT
+	File      File
+	Composite Composite
+}
+
+// Webhook configures the webhook publisher.
+type Webhook struct {
+	// URLs are the endpoints each order event is POSTed to
+	URLs []string `env:"ORDER_EVENT_WEBHOOK_URL"`
+	// Secret signs each POST with HMAC-SHA256, which is left unsigned
+	// without it
+	Secret string `env:"ORDER_EVENT_WEBHOOK_SECRET"`
+	// MaxAttempts is how many times a POST to a URL is tried, the first one
+	// included
+	MaxAttempts int `env:"ORDER_EVENT_WEBHOOK_MAX_ATTEMPTS" default:"3" min:"1"`
+	// Backoff is the wait before the first retry, doubled before each of the
+	// next ones
+	Backoff time.Duration `env:"ORDER_EVENT_WEBHOOK_BACKOFF" default:"200ms" min:"1ms"`
+}
+
+// NATS configures the NATS JetStream publisher.
+type NATS struct {
+	URL string `env:"NATS_URL"`
+	// Stream is created with Subject as its only subject if it does not exist
+	Stream  string `env:"NATS_STREAM" default:"ORDERS"`
+	Subject string `env:"NATS_SUBJECT" default:"orders"`
+	// AckWait is how long a publish waits for the stream to acknowledge it
+	AckWait time.Duration `env:"NATS_ACK_WAIT" default:"5s" min:"1ms"`
+}
+
+// SNS configures the AWS SNS publisher. The AWS credentials and region come
+// from the environment and shared configuration of the AWS SDK.
+type SNS struct {
+	TopicARN string `env:"SNS_TOPIC_ARN"`
+	// QueueARNs are the SQS queues subscribed to the topic on connecting,
+	// with raw message delivery
+	QueueARNs []string `env:"SNS_SQS_QUEUE_ARNS"`
+	// Endpoint replaces the SNS endpoint of the region, such as LocalStack's
+	Endpoint string `env:"SNS_ENDPOINT"`
+	// PublishTimeout bounds a publish, retries of the AWS SDK included
+	PublishTimeout time.Duration `env:"SNS_PUBLISH_TIMEOUT" default:"5s" min:"1ms"`
+}
+
+// PubSub configures the Google Cloud Pub/Sub publisher. The credentials come
+// from the application default credentials, and PUBSUB_EMULATOR_HOST points
+// the client at an emulator instead.
+type PubSub struct {
+	ProjectID string `env:"PUBSUB_PROJECT_ID"`
+	// Topic is the ID of a topic of ProjectID, or its full name
+	Topic string `env:"PUBSUB_TOPIC" default:"orders"`
+	// PublishTimeout is how long a publish waits for Pub/Sub to accept it,
+	// retries of the client included
+	PublishTimeout time.Duration `env:"PUBSUB_PUBLISH_TIMEOUT" default:"10s" min:"1ms"`
+}
+
+// MQTT configures the MQTT v5 publisher.
+type MQTT struct {
+	// URL is the broker, such as mqtt://broker:1883 or mqtts://broker:8883
+	URL   string `env:"MQTT_URL"`
+	Topic string `env:"MQTT_TOPIC" default:"orders"`
+	// QoS is 0 at most once, 1 at least once or 2 exactly once
+	QoS int `env:"MQTT_QOS" default:"1" min:"0" max:"2"`
+	// ClientID defaults to checkout-<hostname>
+	ClientID string `env:"MQTT_CLIENT_ID"`
+	Username string `env:"MQTT_USERNAME"`
+	Password string `env:"MQTT_PASSWORD"`
+	// PublishTimeout is how long connecting, or a publish, waits for the
+	// broker to acknowledge it
+	PublishTimeout time.Duration `env:"MQTT_PUBLISH_TIMEOUT" default:"5s" min:"1ms"`
+}
+
+// File configures the file publisher.
+type File struct {
+	Path string `env:"ORDER_EVENT_FILE_PATH"`
+	// Format is json, one protojson document per line, or protobuf,
+	// length-prefixed binary records
+	Format string `env:"ORDER_EVENT_FILE_FORMAT" default:"json" oneof:"json protobuf"`
+	// MaxSizeMB is the size past which the file is rotated, 0 disables
+	// rotation
+	MaxSizeMB int `env:"ORDER_EVENT_FILE_MAX_SIZE_MB" default:"100" min:"0"`
+	// MaxBackups is how many rotated files are kept
+	MaxBackups int `env:"ORDER_EVENT_FILE_MAX_BACKUPS" default:"5" min:"0"`
+}
+
+// Composite configures the composite publisher, which publishes every order
+// event to several publishers, such as during a broker migration.
+type Composite struct {
+	// Publishers are the kinds of the publishers, configured by their own
+	// variables
+	Publishers []string `env:"ORDER_EVENT_COMPOSITE_PUBLISHERS"`
+	// Mode is all, where every publisher must accept an order event, or
+	// best_effort, where one is enough
+	Mode string `env:"ORDER_EVENT_COMPOSITE_MODE" default:"all" oneof:"all best_effort"`
+}
+
+// PublisherReload configures the source of order event publisher settings
+// that are reloaded while the service runs, which is disabled when neither
+// File nor URL is set. The source holds KEY=value lines of the variables of
+// OrderEvents and Kafka, which override the environment.
+type PublisherReload struct {
+	File string `env:"ORDER_EVENT_CONFIG_FILE"`
+	URL  string `env:"ORDER_EVENT_CONFIG_URL"`
+	// Interval is how often the source is read
+	Interval time.Duration `env:"ORDER_EVENT_CONFIG_INTERVAL" default:"10s" min:"1s"`
+}
+
+// Enabled reports whether a source is set.
+func (r PublisherReload) Enabled() bool {
+	return r.File != "" || r.URL != ""
+}
+
+// PlaceOrder configures order placement.
+type PlaceOrder struct {
+	// IdempotencyTTL is how long retried PlaceOrder calls are deduplicated
+	IdempotencyTTL time.Duration `env:"PLACE_ORDER_IDEMPOTENCY_TTL" default:"24h" min:"0s"`
+	// AsyncWorkers accepts orders asynchronously when positive
+	AsyncWorkers int `env:"PLACE_ORDER_ASYNC_WORKERS" min:"0"`
+	// InventoryStock sets stock levels as product=quantity pairs; products
+	// without one are never out of stock
+	InventoryStock []string `env:"PLACE_ORDER_INVENTORY_STOCK"`
+	// Promotions takes a percentage off products as product=percent pairs
+	Promotions []string `env:"PLACE_ORDER_PROMOTIONS"`
+	// GiftCards sets gift card balances as code=currency:units pairs
+	GiftCards []string `env:"PLACE_ORDER_GIFT_CARDS"`
+	// ShippingInsurancePercent is the cost of shipping insurance, for orders
+	// that ask for it, as a percentage of their items
+	ShippingInsurancePercent float64 `env:"PLACE_ORDER_SHIPPING_INSURANCE_PERCENT" default:"1" min:"0" max:"100"`
+}
+
+// GiftCardBalance is the balance of a gift card, in whole units of its
+// currency.
+type GiftCardBalance struct {
+	CurrencyCode string
+	Units        int64
+}
+
+// GiftCardBalances returns the balances of GiftCards by code. Invalid pairs,
+// which LoadFrom rejects, are skipped.
+func (p PlaceOrder) GiftCardBalances() map[string]GiftCardBalance {
+	balances := make(map[string]GiftCardBalance, len(p.GiftCards))
+	for _, pair := range p.GiftCards {
+		if code, balance, ok := parseGiftCard(pair); ok {
+			balances[code] = balance
+		}
+	}
+	return balances
+}
+
+// parseGiftCard parses a code=currency:units pair with a three-letter currency
+// code and a non-negative balance.
+func parseGiftCard(pair string) (string, GiftCardBalance, bool) {
+	code, balance, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(code) == "" {
+		return "", GiftCardBalance{}, false
+	}
+	currency, units, ok := strings.Cut(balance, ":")
+	currency = strings.TrimSpace(currency)
+	if !ok || len(currency) != 3 {
+		return "", GiftCardBalance{}, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(units), 10, 64)
+	if err != nil || n < 0 {
+		return "", GiftCardBalance{}, false
+	}
+	return strings.TrimSpace(code), GiftCardBalance{CurrencyCode: currency, Units: n}, true
+}
+
+// Stock returns the stock levels of InventoryStock by product ID. Invalid
+// pairs, which LoadFrom rejects, are skipped.
+func (p PlaceOrder) Stock() map[string]int64 {
+	stock := make(map[string]int64, len(p.InventoryStock))
+	for _, pair := range p.InventoryStock {
+		if productID, quantity, ok := parseProductPair(pair, 0, math.MaxInt64); ok {
+			stock[productID] = quantity
+		}
+	}
+	return stock
+}
+
+// PercentOff returns the percentages of Promotions by product ID. Invalid
+// pairs, which LoadFrom rejects, are skipped.
+func (p PlaceOrder) PercentOff() map[string]int64 {
+	percentOff := make(map[string]int64, len(p.Promotions))
+	for _, pair := range p.Promotions {
+		if productID, percent, ok := parseProductPair(pair, 1, 100); ok {
+			percentOff[productID] = percent
+		}
+	}
+	return percentOff
+}
+
+// parseProductPair parses a product=n pair with n from lo to hi.
+func parseProductPair(pair string, lo, hi int64) (string, int64, bool) {
+	productID, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(productID) == "" {
+		return "", 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < lo || n > hi {
+		return "", 0, false
+	}
+	return strings.TrimSpace(productID), n, true
+}
+
+// PublishSLO configures the publish SLO tracker, which is enabled when either
+// objective is set.
+type PublishSLO struct {
+	SuccessRate float64       `env:"PUBLISH_SLO_SUCCESS_RATE" min:"0" max:"1"`
+	Latency     time.Duration `env:"PUBLISH_SLO_LATENCY" min:"0s"`
+	Percentile  float64       `env:"PUBLISH_SLO_PERCENTILE" default:"0.99" min:"0" max:"1"`
+	Window      time.Duration `env:"PUBLISH_SLO_WINDOW" min:"0s"`
+}
+
+// Enabled reports whether an objective is set.
+func (s PublishSLO) Enabled() bool {
+	return s.SuccessRate != 0 || s.Latency != 0
+}
+
+// PublishRetry configures the retries of failed order event publishes, which
+// are enabled when MaxAttempts is above 1.
+type PublishRetry struct {
+	// MaxAttempts is the number of attempts of a publish, including the first
+	MaxAttempts int           `env:"PUBLISH_RETRY_MAX_ATTEMPTS" default:"1" min:"1"`
+	Backoff     time.Duration `env:"PUBLISH_RETRY_BACKOFF" default:"100ms" min:"1ms"`
+	MaxBackoff  time.Duration `env:"PUBLISH_RETRY_MAX_BACKOFF" default:"2s" min:"1ms"`
+	// Jitter is the fraction by which each backoff is randomly shortened
+	Jitter float64 `env:"PUBLISH_RETRY_JITTER" default:"0.2" min:"0" max:"1"`
+}
+
+// Enabled reports whether failed publishes are retried.
+func (r PublishRetry) Enabled() bool {
+	return r.MaxAttempts > 1
+}
+
+// PublishBuffer configures the in-memory queue of order events, which is
+// enabled when Size is above 0.
+type PublishBuffer struct {
+	// Size is the number of events the queue holds
+	Size    int `env:"PUBLISH_BUFFER_SIZE" min:"0"`
+	Workers int `env:"PUBLISH_BUFFER_WORKERS" default:"4" min:"1"`
+	// Overflow is what happens to an event when the queue is full: block,
+	// drop_oldest or spill to a spool next to ORDER_EVENT_SPOOL_PATH
+	Overflow string `env:"PUBLISH_BUFFER_OVERFLOW" default:"block" oneof:"block drop_oldest spill"`
+}
+
+// Enabled reports whether order events are queued.
+func (b PublishBuffer) Enabled() bool {
+	return b.Size > 0
+}
+
+// Telemetry configures the OpenTelemetry SDK beyond the variables it reads
+// itself. Samplers and propagators are validated when the SDK is set up, which
+// keeps the SDK out of this package.
+type Telemetry struct {
+	TracesSampler    string `env:"OTEL_TRACES_SAMPLER"`
+	TracesSamplerArg string `env:"OTEL_TRACES_SAMPLER_ARG"`
+	// PublisherTracesSampler samples publisher spans separately when set
+	PublisherTracesSampler    string   `env:"PUBLISHER_TRACES_SAMPLER"`
+	PublisherTracesSamplerArg string   `env:"PUBLISHER_TRACES_SAMPLER_ARG"`
+	Propagators               []string `env:"OTEL_PROPAGATORS"`
+	MetricsExporter           string   `env:"OTEL_METRICS_EXPORTER" default:"otlp" oneof:"otlp prometheus"`
+	MetricsExemplarFilter     string   `env:"OTEL_METRICS_EXEMPLAR_FILTER"`
+	PrometheusHost            string   `env:"OTEL_EXPORTER_PROMETHEUS_HOST"`
+	PrometheusPort            string   `env:"OTEL_EXPORTER_PROMETHEUS_PORT" default:"9464"`
+}
+
+// Load reads the configuration from the environment.
+func Load() (*Config, error) {
+	return LoadFrom(os.LookupEnv)
+}
+
+// LoadFrom reads the configuration from lookup, fills in the defaults that
+// depend on other variables and validates it. The returned *Error lists every
+// invalid variable.
+func LoadFrom(lookup LookupFunc) (*Config, error) {
+	cfg := &Config{}
+	errs := &Error{}
+	if err := Parse(cfg, lookup); err != nil {
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+	}
+
+	cfg.OrderEvents.Publisher = strings.ToLower(cfg.OrderEvents.Publisher)
+	cfg.OrderEvents.OutboxRelayPublisher = strings.ToLower(cfg.OrderEvents.OutboxRelayPublisher)
+	if cfg.OrderEvents.Publisher == "" {
+		cfg.OrderEvents.Publisher = "noop"
+		if cfg.Kafka.Addr != "" {
+			cfg.OrderEvents.Publisher = "kafka"
+		}
+	}
+	if cfg.OrderEvents.SpoolPath == "" {
+		cfg.OrderEvents.SpoolPath = filepath.Join(os.TempDir(), "checkout-order-events.spool")
+	}
+	if cfg.Kafka.TransactionalID == "" || cfg.OrderEvents.MQTT.ClientID == "" {
+		hostname, _ := os.Hostname()
+		if cfg.Kafka.TransactionalID == "" {
+			cfg.Kafka.TransactionalID = "checkout-" + hostname
+		}
+		if cfg.OrderEvents.MQTT.ClientID == "" {
+			cfg.OrderEvents.MQTT.ClientID = "checkout-" + hostname
+		}
+	}
+	cfg.validate(errs)
+
+	if len(errs.Fields) > 0 {
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// mqttSchemes are the URL schemes of MQTT brokers the paho client dials.
+var mqttSchemes = map[string]bool{
+	"mqtt": true, "mqtts": true, "tcp": true, "ssl": true, "tls": true, "ws": true, "wss": true,
+}
+
+// sqlIdentifier matches the table names the outbox queries can embed.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// validate checks the rules that tags cannot express.
+func (c *Config) validate(errs *Error) {
+	if _, err := loglevel.Parse(c.LogLevel); err != nil {
+		errs.add("LOG_LEVEL", c.LogLevel, "expected debug, info, warn or error")
+	}
+	if c.PublishSLO.Percentile == 0 && !errs.has("PUBLISH_SLO_PERCENTILE") {
+		errs.add("PUBLISH_SLO_PERCENTILE", "0", "expected a number above 0")
+	}
+	if c.PublishRetry.MaxBackoff < c.PublishRetry.Backoff && !errs.has("PUBLISH_RETRY_BACKOFF") && !errs.has("PUBLISH_RETRY_MAX_BACKOFF") {
+		errs.add("PUBLISH_RETRY_MAX_BACKOFF", c.PublishRetry.MaxBackoff.String(), "must not be below PUBLISH_RETRY_BACKOFF")
+	}
+	if c.Kafka.AckMode == "background" && c.Kafka.ProducerMode != "async" && !errs.has("KAFKA_PRODUCER_MODE") {
+		errs.add("KAFKA_ACK_MODE", c.Kafka.AckMode, "requires KAFKA_PRODUCER_MODE=async")
+	}
+	if (c.Kafka.BatchMessages > 0 || c.Kafka.BatchBytes > 0) && c.Kafka.BatchLinger == 0 && !errs.has("KAFKA_BATCH_LINGER") {
+		errs.add("KAFKA_BATCH_LINGER", "0s", "is required with KAFKA_BATCH_MESSAGES or KAFKA_BATCH_BYTES")
+	}
+	for _, pair := range c.Kafka.Headers {
+		if _, _, ok := parseHeader(pair); !ok {
+			errs.add("KAFKA_HEADERS", strings.Join(c.Kafka.Headers, ","), "expected name=value pairs")
+			break
+		}
+	}
+	for _, pair := range c.PlaceOrder.InventoryStock {
+		if _, _, ok := parseProductPair(pair, 0, math.MaxInt64); !ok {
+			errs.add("PLACE_ORDER_INVENTORY_STOCK", strings.Join(c.PlaceOrder.InventoryStock, ","), "expected product=quantity pairs with quantities of at least 0")
+			break
+		}
+	}
+	for _, pair := range c.PlaceOrder.Promotions {
+		if _, _, ok := parseProductPair(pair, 1, 100); !ok {
+			errs.add("PLACE_ORDER_PROMOTIONS", strings.Join(c.PlaceOrder.Promotions, ","), "expected product=percent pairs with percentages from 1 to 100")
+			break
+		}
+	}
+	for _, pair := range c.PlaceOrder.GiftCards {
+		if _, _, ok := parseGiftCard(pair); !ok {
+			errs.add("PLACE_ORDER_GIFT_CARDS", strings.Join(c.PlaceOrder.GiftCards, ","), "expected code=currency:units pairs such as GIFT-1=USD:50")
+			break
+		}
+	}
+	if c.CurrencyRates.MaxStaleness < c.CurrencyRates.TTL {
+		errs.add("CURRENCY_RATE_MAX_STALENESS", c.CurrencyRates.MaxStaleness.String(), "expected at least CURRENCY_RATE_TTL")
+	}
+	if c.PublisherReload.File != "" && c.PublisherReload.URL != "" {
+		errs.add("ORDER_EVENT_CONFIG_URL", c.PublisherReload.URL, "expected either ORDER_EVENT_CONFIG_FILE or ORDER_EVENT_CONFIG_URL, not both")
+	}
+	if c.Kafka.MultiRegion() {
+		switch {
+		case c.Kafka.Region == "" || c.Kafka.SecondaryRegion == "":
+			errs.add("KAFKA_SECONDARY_REGION", c.Kafka.SecondaryRegion, "expected KAFKA_REGION and KAFKA_SECONDARY_REGION with KAFKA_SECONDARY_ADDR")
+		case c.Kafka.Region == c.Kafka.SecondaryRegion:
+			errs.add("KAFKA_SECONDARY_REGION", c.Kafka.SecondaryRegion, "expected a region other than KAFKA_REGION")
+		}
+	}
+	if c.Kafka.MultiRegion() && c.OrderEvents.Outbox {
+		errs.add("KAFKA_SECONDARY_ADDR", c.Kafka.SecondaryAddr, "is not supported with ORDER_EVENT_OUTBOX, whose Kafka transactions do not fail over between regions")
+	}
+	relayKind := ""
+	if c.OrderEvents.Publisher == "outbox" {
+		relayKind = c.OrderEvents.OutboxRelayPublisher
+	}
+	switch {
+	case c.OrderEvents.Publisher == "outbox" && c.OrderEvents.OutboxDSN == "":
+		errs.add("ORDER_EVENT_OUTBOX_DSN", "", "is required when ORDER_EVENT_PUBLISHER=outbox")
+	case relayKind == "outbox" || relayKind == "composite":
+		errs.add("ORDER_EVENT_OUTBOX_RELAY_PUBLISHER", relayKind, "expected a publisher other than outbox and composite")
+	case (c.OrderEvents.Publisher == "kafka" || relayKind == "kafka") && c.Kafka.Addr == "":
+		errs.add("KAFKA_ADDR", "", "is required when ORDER_EVENT_PUBLISHER or ORDER_EVENT_OUTBOX_RELAY_PUBLISHER is kafka")
+	case (c.OrderEvents.Publisher == "webhook" || c.OrderEvents.Fallback == "webhook") && len(c.OrderEvents.Webhook.URLs) == 0:
+		errs.add("ORDER_EVENT_WEBHOOK_URL", "", "is required when ORDER_EVENT_PUBLISHER or ORDER_EVENT_FALLBACK is webhook")
+	case c.OrderEvents.Publisher == "nats" && c.OrderEvents.NATS.URL == "":
+		errs.add("NATS_URL", "", "is required when ORDER_EVENT_PUBLISHER=nats")
+	case c.OrderEvents.Publisher == "sns" && c.OrderEvents.SNS.TopicARN == "":
+		errs.add("SNS_TOPIC_ARN", "", "is required when ORDER_EVENT_PUBLISHER=sns")
+	case c.OrderEvents.Publisher == "pubsub" && c.OrderEvents.PubSub.ProjectID == "":
+		errs.add("PUBSUB_PROJECT_ID", "", "is required when ORDER_EVENT_PUBLISHER=pubsub")
+	case c.OrderEvents.Publisher == "mqtt" && c.OrderEvents.MQTT.URL == "":
+		errs.add("MQTT_URL", "", "is required when ORDER_EVENT_PUBLISHER=mqtt")
+	case (c.OrderEvents.Publisher == "file" || c.OrderEvents.Fallback == "file") && c.OrderEvents.File.Path == "":
+		errs.add("ORDER_EVENT_FILE_PATH", "", "is required when ORDER_EVENT_PUBLISHER or ORDER_EVENT_FALLBACK is file")
+	case c.OrderEvents.Publisher == "composite" && len(c.OrderEvents.Composite.Publishers) == 0:
+		errs.add("ORDER_EVENT_COMPOSITE_PUBLISHERS", "", "is required when ORDER_EVENT_PUBLISHER=composite")
+	case slices.ContainsFunc(c.OrderEvents.Composite.Publishers, func(kind string) bool { return strings.EqualFold(kind, "composite") }):
+		errs.add("ORDER_EVENT_COMPOSITE_PUBLISHERS", strings.Join(c.OrderEvents.Composite.Publishers, ","), "cannot include composite")
+	}
+	if !sqlIdentifier.MatchString(c.OrderEvents.OutboxTable) {
+		errs.add("ORDER_EVENT_OUTBOX_TABLE", c.OrderEvents.OutboxTable, "expected a table name of letters, digits and underscores, optionally schema-qualified")
+	}
+	for _, raw := range c.OrderEvents.Webhook.URLs {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("ORDER_EVENT_WEBHOOK_URL", strings.Join(c.OrderEvents.Webhook.URLs, ","), "expected http or https URLs")
+			break
+		}
+	}
+	if c.OrderEvents.Fallback == c.OrderEvents.Publisher && (c.OrderEvents.Fallback == "file" || c.OrderEvents.Fallback == "webhook") {
+		errs.add("ORDER_EVENT_FALLBACK", c.OrderEvents.Fallback, "expected a publisher other than ORDER_EVENT_PUBLISHER")
+	}
+	if raw := c.OrderEvents.MQTT.URL; raw != "" {
+		if u, err := url.Parse(raw); err != nil || !mqttSchemes[u.Scheme] || u.Host == "" {
+			errs.add("MQTT_URL", raw, "expected an mqtt, mqtts, tcp, ssl, tls, ws or wss URL")
+		}
+	}
+	for _, arn := range c.OrderEvents.SNS.QueueARNs {
+		if !strings.HasPrefix(arn, "arn:") || !strings.Contains(arn, ":sqs:") {
+			errs.add("SNS_SQS_QUEUE_ARNS", strings.Join(c.OrderEvents.SNS.QueueARNs, ","), "expected SQS queue ARNs such as arn:aws:sqs:us-east-1:123456789012:orders")
+			break
+		}
+	}
+}
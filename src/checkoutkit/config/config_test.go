@@ -0,0 +1,268 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// requiredEnv holds the variables without a default.
+var requiredEnv = map[string]string{
+	"CHECKOUT_PORT":        "5050",
+	"SHIPPING_ADDR":        "shipping:50050",
+	"PRODUCT_CATALOG_ADDR": "product-catalog:3550",
+	"CART_ADDR":            "cart:7070",
+	"CURRENCY_ADDR":        "currency:7001",
+	"EMAIL_ADDR":           "email:6060",
+	"PAYMENT_ADDR":         "payment:50051",
+}
+
+func withEnv(env map[string]string) LookupFunc {
+	all := maps.Clone(requiredEnv)
+	maps.Copy(all, env)
+	return mapLookup(all)
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(nil))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	if cfg.Services.Payment != "payment:50051" {
+		t.Errorf("Services.Payment = %q, want payment:50051", cfg.Services.Payment)
+	}
+	if cfg.ShutdownTimeout != 25*time.Second {
+		t.Errorf("ShutdownTimeout = %v, want 25s", cfg.ShutdownTimeout)
+	}
+	if cfg.OrderEvents.Publisher != "noop" || cfg.OrderEvents.Fallback != "spool" || cfg.OrderEvents.SpoolPath == "" {
+		t.Errorf("OrderEvents = %+v, want noop with a spool fallback", cfg.OrderEvents)
+	}
+	if cfg.Kafka.TransactionalID == "" {
+		t.Error("Kafka.TransactionalID is empty, want a default")
+	}
+	if cfg.PublishSLO.Enabled() {
+		t.Error("PublishSLO.Enabled() = true without an objective")
+	}
+}
+
+func TestLoadDefaultsToKafkaWithBrokers(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{"KAFKA_ADDR": "kafka:9092"}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	if cfg.OrderEvents.Publisher != "kafka" {
+		t.Errorf("OrderEvents.Publisher = %q, want kafka", cfg.OrderEvents.Publisher)
+	}
+}
+
+func TestLoadWebhookURLs(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{
+		"ORDER_EVENT_PUBLISHER":   "webhook",
+		"ORDER_EVENT_WEBHOOK_URL": "http://shipping/orders,https://accounting/orders",
+	}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	if want := []string{"http://shipping/orders", "https://accounting/orders"}; !slices.Equal(cfg.OrderEvents.Webhook.URLs, want) {
+		t.Errorf("Webhook.URLs = %v, want %v", cfg.OrderEvents.Webhook.URLs, want)
+	}
+
+	_, err = LoadFrom(withEnv(map[string]string{
+		"ORDER_EVENT_PUBLISHER":   "webhook",
+		"ORDER_EVENT_WEBHOOK_URL": "http://shipping/orders,accounting:8080",
+	}))
+	var errs *Error
+	if !errors.As(err, &errs) || len(errs.Fields) != 1 || errs.Fields[0].Key != "ORDER_EVENT_WEBHOOK_URL" {
+		t.Errorf("LoadFrom() = %v, want ORDER_EVENT_WEBHOOK_URL rejected", err)
+	}
+}
+
+func TestLoadFallbackPublisher(t *testing.T) {
+	tests := []struct {
+		env     map[string]string
+		wantKey string
+	}{
+		{env: map[string]string{"ORDER_EVENT_FALLBACK": "file", "ORDER_EVENT_FILE_PATH": "orders.jsonl"}},
+		{env: map[string]string{"ORDER_EVENT_FALLBACK": "file"}, wantKey: "ORDER_EVENT_FILE_PATH"},
+		{env: map[string]string{"ORDER_EVENT_FALLBACK": "webhook"}, wantKey: "ORDER_EVENT_WEBHOOK_URL"},
+		{env: map[string]string{"ORDER_EVENT_PUBLISHER": "file", "ORDER_EVENT_FALLBACK": "file", "ORDER_EVENT_FILE_PATH": "orders.jsonl"}, wantKey: "ORDER_EVENT_FALLBACK"},
+	}
+	for _, tt := range tests {
+		_, err := LoadFrom(withEnv(tt.env))
+		var errs *Error
+		switch {
+		case tt.wantKey == "" && err != nil:
+			t.Errorf("LoadFrom(%v) = %v", tt.env, err)
+		case tt.wantKey != "" && (!errors.As(err, &errs) || len(errs.Fields) != 1 || errs.Fields[0].Key != tt.wantKey):
+			t.Errorf("LoadFrom(%v) = %v, want %s rejected", tt.env, err, tt.wantKey)
+		}
+	}
+}
+
+func TestLoadOutbox(t *testing.T) {
+	dsn := "postgres://checkout@postgres/orders"
+	tests := []struct {
+		env     map[string]string
+		wantKey string
+	}{
+		{env: map[string]string{"ORDER_EVENT_PUBLISHER": "outbox", "ORDER_EVENT_OUTBOX_DSN": dsn, "ORDER_EVENT_OUTBOX_RELAY_PUBLISHER": "NATS", "NATS_URL": "nats://nats:4222"}},
+		{env: map[string]string{"ORDER_EVENT_OUTBOX": "true", "ORDER_EVENT_OUTBOX_DSN": dsn, "ORDER_EVENT_OUTBOX_TABLE": "checkout.outbox"}},
+		{env: map[string]string{"ORDER_EVENT_PUBLISHER": "outbox", "KAFKA_ADDR": "kafka:9092"}, wantKey: "ORDER_EVENT_OUTBOX_DSN"},
+		{env: map[string]string{"ORDER_EVENT_PUBLISHER": "outbox", "ORDER_EVENT_OUTBOX_DSN": dsn}, wantKey: "KAFKA_ADDR"},
+		{env: map[string]string{"ORDER_EVENT_PUBLISHER": "outbox", "ORDER_EVENT_OUTBOX_DSN": dsn, "ORDER_EVENT_OUTBOX_RELAY_PUBLISHER": "outbox"}, wantKey: "ORDER_EVENT_OUTBOX_RELAY_PUBLISHER"},
+		{env: map[string]string{"ORDER_EVENT_OUTBOX_TABLE": "outbox; DROP TABLE orders"}, wantKey: "ORDER_EVENT_OUTBOX_TABLE"},
+	}
+	for _, tt := range tests {
+		_, err := LoadFrom(withEnv(tt.env))
+		var errs *Error
+		switch {
+		case tt.wantKey == "" && err != nil:
+			t.Errorf("LoadFrom(%v) = %v", tt.env, err)
+		case tt.wantKey != "" && (!errors.As(err, &errs) || len(errs.Fields) != 1 || errs.Fields[0].Key != tt.wantKey):
+			t.Errorf("LoadFrom(%v) = %v, want %s rejected", tt.env, err, tt.wantKey)
+		}
+	}
+}
+
+func TestLoadAcceptsAnyPublisherKind(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{"ORDER_EVENT_PUBLISHER": "Carrier-Pigeon"}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	if cfg.OrderEvents.Publisher != "carrier-pigeon" {
+		t.Errorf("OrderEvents.Publisher = %q, want carrier-pigeon", cfg.OrderEvents.Publisher)
+	}
+}
+
+func TestLoadListsEveryInvalidVariable(t *testing.T) {
+	env := map[string]string{
+		"CART_ADDR":                             "",
+		"LOG_LEVEL":                             "loud",
+		"ORDER_EVENT_PUBLISHER":                 "webhook",
+		"ORDER_EVENT_FALLBACK":                  "disk",
+		"PUBLISH_SLO_PERCENTILE":                "0",
+		"SCHEMA_REGISTRY_COMPATIBILITY":         "sideways",
+		"PLACE_ORDER_ASYNC_WORKERS":             "-1",
+		"ORDER_EVENT_FALLBACK_RECHECK_INTERVAL": "0s",
+		"PLACE_ORDER_INVENTORY_STOCK":           "SKU-1=3,SKU-2",
+		"PLACE_ORDER_PROMOTIONS":                "SKU-1=0",
+		"PLACE_ORDER_GIFT_CARDS":                "GIFT-1=50",
+		"ORDER_EVENT_SCHEMA_VERSION":            "4",
+		"CURRENCY_RATE_TTL":                     "1h",
+		"ORDER_EVENT_CONFIG_FILE":               "publisher.env",
+		"ORDER_EVENT_CONFIG_URL":                "http://config/publisher.env",
+		"KAFKA_SECONDARY_ADDR":                  "kafka.eu-west-1:9092",
+		"KAFKA_REGION":                          "us-east-1",
+		"ORDER_EVENT_OUTBOX":                    "true",
+		"NATS_ACK_WAIT":                         "0s",
+		"SNS_SQS_QUEUE_ARNS":                    "orders-queue",
+		"PUBSUB_PUBLISH_TIMEOUT":                "0s",
+		"ORDER_EVENT_FILE_FORMAT":               "avro",
+		"MQTT_URL":                              "http://broker:1883",
+		"MQTT_QOS":                              "3",
+		"KAFKA_PRODUCER_MODE":                   "batch",
+		"ORDER_EVENT_COMPOSITE_MODE":            "any",
+		"KAFKA_MESSAGE_KEY":                     "user_id",
+		"ORDER_EVENT_DECODE_MODE":               "paranoid",
+		"KAFKA_HEADERS":                         "environment",
+		"KAFKA_BATCH_MESSAGES":                  "100",
+		"KAFKA_ACK_MODE":                        "fire-and-forget",
+		"PUBLISH_RETRY_BACKOFF":                 "5s",
+		"PUBLISH_BUFFER_OVERFLOW":               "drop_newest",
+	}
+	_, err := LoadFrom(withEnv(env))
+
+	var errs *Error
+	if !errors.As(err, &errs) {
+		t.Fatalf("LoadFrom() = %v, want an *Error", err)
+	}
+	var keys []string
+	for _, field := range errs.Fields {
+		keys = append(keys, field.Key)
+	}
+	slices.Sort(keys)
+	want := []string{
+		"CART_ADDR",
+		"CURRENCY_RATE_MAX_STALENESS",
+		"KAFKA_ACK_MODE",
+		"KAFKA_BATCH_LINGER",
+		"KAFKA_HEADERS",
+		"KAFKA_MESSAGE_KEY",
+		"KAFKA_PRODUCER_MODE",
+		"KAFKA_SECONDARY_ADDR",
+		"KAFKA_SECONDARY_REGION",
+		"LOG_LEVEL",
+		"MQTT_QOS",
+		"MQTT_URL",
+		"NATS_ACK_WAIT",
+		"ORDER_EVENT_COMPOSITE_MODE",
+		"ORDER_EVENT_CONFIG_URL",
+		"ORDER_EVENT_DECODE_MODE",
+		"ORDER_EVENT_FALLBACK",
+		"ORDER_EVENT_FALLBACK_RECHECK_INTERVAL",
+		"ORDER_EVENT_FILE_FORMAT",
+		"ORDER_EVENT_SCHEMA_VERSION",
+		"ORDER_EVENT_WEBHOOK_URL",
+		"PLACE_ORDER_ASYNC_WORKERS",
+		"PLACE_ORDER_GIFT_CARDS",
+		"PLACE_ORDER_INVENTORY_STOCK",
+		"PLACE_ORDER_PROMOTIONS",
+		"PUBLISH_BUFFER_OVERFLOW",
+		"PUBLISH_RETRY_MAX_BACKOFF",
+		"PUBLISH_SLO_PERCENTILE",
+		"PUBSUB_PUBLISH_TIMEOUT",
+		"SCHEMA_REGISTRY_COMPATIBILITY",
+		"SNS_SQS_QUEUE_ARNS",
+	}
+	if !slices.Equal(keys, want) {
+		t.Errorf("LoadFrom() reported %v, want %v", keys, want)
+	}
+}
+
+func TestKafkaStaticHeaders(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{"KAFKA_HEADERS": "environment=staging, team = checkout"}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	want := map[string]string{"environment": "staging", "team": "checkout"}
+	if got := cfg.Kafka.StaticHeaders(); !maps.Equal(got, want) {
+		t.Errorf("StaticHeaders() = %v, want %v", got, want)
+	}
+}
+
+func TestPlaceOrderStock(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{"PLACE_ORDER_INVENTORY_STOCK": "OLJCESPC7Z=10, 66VCHSJNUP = 0"}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	want := map[string]int64{"OLJCESPC7Z": 10, "66VCHSJNUP": 0}
+	if got := cfg.PlaceOrder.Stock(); !maps.Equal(got, want) {
+		t.Errorf("Stock() = %v, want %v", got, want)
+	}
+}
+
+func TestPlaceOrderPercentOff(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{"PLACE_ORDER_PROMOTIONS": "OLJCESPC7Z=15,66VCHSJNUP=100"}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	want := map[string]int64{"OLJCESPC7Z": 15, "66VCHSJNUP": 100}
+	if got := cfg.PlaceOrder.PercentOff(); !maps.Equal(got, want) {
+		t.Errorf("PercentOff() = %v, want %v", got, want)
+	}
+}
+
+func TestPlaceOrderGiftCardBalances(t *testing.T) {
+	cfg, err := LoadFrom(withEnv(map[string]string{"PLACE_ORDER_GIFT_CARDS": "GIFT-1=USD:50, GIFT-2 = EUR:0"}))
+	if err != nil {
+		t.Fatalf("LoadFrom() = %v", err)
+	}
+	want := map[string]GiftCardBalance{"GIFT-1": {"USD", 50}, "GIFT-2": {"EUR", 0}}
+	if got := cfg.PlaceOrder.GiftCardBalances(); !maps.Equal(got, want) {
+		t.Errorf("GiftCardBalances() = %v, want %v", got, want)
+	}
+}
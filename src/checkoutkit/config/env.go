@@ -0,0 +1,243 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LookupFunc returns the value of an environment variable and whether it is
+// set, like os.LookupEnv.
+type LookupFunc func(key string) (string, bool)
+
+// FieldError is an environment variable with an invalid value.
+type FieldError struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s %s", e.Key, e.Reason)
+	}
+	return fmt.Sprintf("%s=%q: %s", e.Key, e.Value, e.Reason)
+}
+
+// Error lists every invalid environment variable, so that a deployment can be
+// fixed in one go rather than one restart per variable.
+type Error struct {
+	Fields []*FieldError
+}
+
+func (e *Error) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		reasons[i] = field.Error()
+	}
+	return "invalid configuration: " + strings.Join(reasons, "; ")
+}
+
+func (e *Error) add(key, value, reason string) {
+	e.Fields = append(e.Fields, &FieldError{Key: key, Value: value, Reason: reason})
+}
+
+func (e *Error) has(key string) bool {
+	for _, field := range e.Fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse sets the fields of the struct dst points to from the variables
+// returned by lookup, as described by their tags:
+//
+//	env:"KEY"           reads the field from KEY. An empty value counts as unset
+//	env:"KEY,required"  fails when KEY is unset
+//	default:"value"     is used when KEY is unset
+//	oneof:"a b c"       restricts a string to the listed values, ignoring case
+//	min:"v" max:"v"     bound an int, float64 or time.Duration
+//
+// Untagged struct fields are parsed recursively. Fields may be strings,
+// bools, ints, float64s, time.Durations or comma-separated []strings. Parse
+// returns an *Error listing every invalid variable.
+func Parse(dst any, lookup LookupFunc) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Parse expects a pointer to a struct, got %T", dst)
+	}
+	errs := &Error{}
+	parseStruct(v.Elem(), lookup, errs)
+	if len(errs.Fields) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Keys returns the variables the struct v, or the struct it points to, is
+// parsed from by Parse, in the order of its fields.
+func Keys(v any) []string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var keys []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct {
+				keys = append(keys, Keys(reflect.Zero(field.Type).Interface())...)
+			}
+			continue
+		}
+		key, _, _ := strings.Cut(tag, ",")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func parseStruct(v reflect.Value, lookup LookupFunc, errs *Error) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field, value := t.Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			if value.Kind() == reflect.Struct {
+				parseStruct(value, lookup, errs)
+			}
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		s, _ := lookup(key)
+		if s == "" {
+			if opts == "required" {
+				errs.add(key, "", "is required")
+				continue
+			}
+			if s, ok = field.Tag.Lookup("default"); !ok {
+				continue
+			}
+		}
+		if err := setValue(value, s); err != nil {
+			errs.add(key, s, err.Error())
+			continue
+		}
+		if reason := checkBounds(field, value); reason != "" {
+			errs.add(key, s, reason)
+		}
+	}
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 30s")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number")
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for item := range strings.SplitSeq(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("config: unsupported field type %s", v.Type()))
+	}
+	return nil
+}
+
+// checkBounds returns why v violates the oneof, min or max tag of field, or
+// the empty string. A oneof value is replaced by its spelling in the tag.
+func checkBounds(field reflect.StructField, v reflect.Value) string {
+	if oneof, ok := field.Tag.Lookup("oneof"); ok {
+		allowed := strings.Fields(oneof)
+		for _, a := range allowed {
+			if strings.EqualFold(v.String(), a) {
+				v.SetString(a)
+				return ""
+			}
+		}
+		return "expected " + orList(allowed)
+	}
+	if bound, ok := field.Tag.Lookup("min"); ok && compare(v, bound) < 0 {
+		return "expected at least " + bound
+	}
+	if bound, ok := field.Tag.Lookup("max"); ok && compare(v, bound) > 0 {
+		return "expected at most " + bound
+	}
+	return ""
+}
+
+// compare compares v with bound, parsed as the type of v.
+func compare(v reflect.Value, bound string) int {
+	b := reflect.New(v.Type()).Elem()
+	if err := setValue(b, bound); err != nil {
+		panic(fmt.Sprintf("config: invalid bound %q for %s", bound, v.Type()))
+	}
+	if v.Kind() == reflect.Float64 {
+		switch {
+		case v.Float() < b.Float():
+			return -1
+		case v.Float() > b.Float():
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case v.Int() < b.Int():
+		return -1
+	case v.Int() > b.Int():
+		return 1
+	}
+	return 0
+}
+
+// orList formats values as "a, b or c".
+func orList(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mapLookup looks variables up in env.
+func mapLookup(env map[string]string) LookupFunc {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+type testConfig struct {
+	Name    string        `env:"NAME,required"`
+	Mode    string        `env:"MODE" default:"fast" oneof:"fast safe"`
+	Enabled bool          `env:"ENABLED"`
+	Workers int           `env:"WORKERS" default:"2" min:"1" max:"8"`
+	Ratio   float64       `env:"RATIO" min:"0" max:"1"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	Tags    []string      `env:"TAGS"`
+	Nested  struct {
+		Addr string `env:"NESTED_ADDR"`
+	}
+	ignored string
+}
+
+func TestParse(t *testing.T) {
+	var got testConfig
+	err := Parse(&got, mapLookup(map[string]string{
+		"NAME":        "checkout",
+		"MODE":        "SAFE",
+		"ENABLED":     "true",
+		"RATIO":       "0.5",
+		"TAGS":        "a, b,,c",
+		"NESTED_ADDR": "kafka:9092",
+	}))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+
+	want := testConfig{Name: "checkout", Mode: "safe", Enabled: true, Workers: 2, Ratio: 0.5, Timeout: 5 * time.Second, Tags: []string{"a", "b", "c"}}
+	want.Nested.Addr = "kafka:9092"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	want := []string{"NAME", "MODE", "ENABLED", "WORKERS", "RATIO", "TIMEOUT", "TAGS", "NESTED_ADDR"}
+	if got := Keys(&testConfig{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestParseListsEveryInvalidVariable(t *testing.T) {
+	var cfg testConfig
+	err := Parse(&cfg, mapLookup(map[string]string{
+		"MODE":    "reckless",
+		"ENABLED": "yes please",
+		"WORKERS": "9",
+		"RATIO":   "-1",
+		"TIMEOUT": "soon",
+	}))
+
+	var errs *Error
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse() = %v, want an *Error", err)
+	}
+	want := []*FieldError{
+		{Key: "NAME", Reason: "is required"},
+		{Key: "MODE", Value: "reckless", Reason: "expected fast or safe"},
+		{Key: "ENABLED", Value: "yes please", Reason: "expected true or false"},
+		{Key: "WORKERS", Value: "9", Reason: "expected at most 8"},
+		{Key: "RATIO", Value: "-1", Reason: "expected at least 0"},
+		{Key: "TIMEOUT", Value: "soon", Reason: "expected a duration such as 30s"},
+	}
+	if !reflect.DeepEqual(errs.Fields, want) {
+		t.Errorf("Parse() = %v, want %v", errs, &Error{Fields: want})
+	}
+}
+
+func TestParseRejectsNonStruct(t *testing.T) {
+	var s string
+	if err := Parse(&s, mapLookup(nil)); err == nil {
+		t.Error("Parse(*string) = nil, want an error")
+	}
+}
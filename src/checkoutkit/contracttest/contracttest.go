@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package contracttest holds the pact harness of the checkout contracts for
+// any provider of order events: where the pacts to verify come from, and the
+// pact message for an order as the Kafka adapter, or any adapter publishing
+// headers such as the NATS one, published it.
+//
+//	verifyRequest := provider.VerifyRequest{MessageHandlers: message.Handlers{
+//		"an order-result message": func([]models.ProviderState) (message.Body, message.Metadata, error) {
+//			producer.ExpectSuccess()
+//			if err := publisher.PublishOrderCompleted(ctx, order); err != nil {
+//				return nil, nil, err
+//			}
+//			messages := producer.Messages()
+//			return contracttest.Message(order, messages[len(messages)-1])
+//		},
+//	}}
+//	contracttest.Source{BrokerURL: os.Getenv("PACT_BROKER_URL")}.Configure(t, &verifyRequest, "checkout-provider", pactFile, "TestPaymentsConsumerContract")
+//
+// The provider package of pact-go links the native pact library, so tests
+// importing this package need it installed.
+package contracttest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/pact-foundation/pact-go/v2/message"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/serialization"
+)
+
+// Source is where a provider test takes the pacts it verifies: the broker at
+// BrokerURL, or a local pact file when BrokerURL is empty.
+type Source struct {
+	BrokerURL      string
+	BrokerUsername string
+	BrokerPassword string
+	// ProviderVersion and ProviderBranch are recorded with the verification
+	// results published to the broker
+	ProviderVersion string
+	ProviderBranch  string
+}
+
+// Configure points req at the pacts of source for providerName. From the
+// broker, it selects the pacts of the main branch and the latest ones, and
+// publishes the results. Otherwise it selects pactFile, skipping t when
+// consumerTest has not recorded it yet.
+func (s Source) Configure(t testing.TB, req *provider.VerifyRequest, providerName, pactFile, consumerTest string) {
+	t.Helper()
+	if s.BrokerURL == "" {
+		if _, err := os.Stat(pactFile); err != nil {
+			t.Skipf("no contract at %s, run %s first", pactFile, consumerTest)
+		}
+		req.PactFiles = []string{filepath.ToSlash(pactFile)}
+		return
+	}
+	t.Logf("verifying against the pacts of %s", s.BrokerURL)
+	req.BrokerURL = s.BrokerURL
+	req.BrokerUsername = s.BrokerUsername
+	req.BrokerPassword = s.BrokerPassword
+	req.ConsumerVersionSelectors = []provider.Selector{
+		&provider.ConsumerVersionSelector{Tag: "main"},
+		&provider.ConsumerVersionSelector{Latest: true},
+	}
+	req.Provider = providerName
+	req.ProviderVersion = s.ProviderVersion
+	req.ProviderBranch = s.ProviderBranch
+	req.PublishVerificationResults = true
+}
+
+// Message returns the pact message of order as it was published in msg: the
+// JSON consumers read as the body, and the headers of msg, such as the trace
+// context and the event type, as the metadata.
+func Message(order *pb.OrderResult, msg *sarama.ProducerMessage) (message.Body, message.Metadata, error) {
+	headers := make(map[string]string)
+	if msg != nil {
+		for _, h := range msg.Headers {
+			headers[string(h.Key)] = string(h.Value)
+		}
+	}
+	return HeaderMessage(order, headers)
+}
+
+// HeaderMessage returns the pact message of order as an adapter other than
+// Kafka published it with headers, such as the headers of a NATS message.
+func HeaderMessage(order *pb.OrderResult, headers map[string]string) (message.Body, message.Metadata, error) {
+	body, err := serialization.ToConsumerJSON(order)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert the order to the consumer format: %w", err)
+	}
+	metadata := message.Metadata{"contentType": "application/json"}
+	for key, value := range headers {
+		metadata[key] = value
+	}
+	return body, metadata, nil
+}
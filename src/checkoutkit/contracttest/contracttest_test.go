@@ -0,0 +1,95 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package contracttest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/pact-foundation/pact-go/v2/provider"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func TestConfigure(t *testing.T) {
+	pactFile := filepath.Join(t.TempDir(), "consumer-checkout-provider.json")
+	if err := os.WriteFile(pactFile, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("local", func(t *testing.T) {
+		var req provider.VerifyRequest
+		Source{}.Configure(t, &req, "checkout-provider", pactFile, "TestConsumerContract")
+		if len(req.PactFiles) != 1 || req.PactFiles[0] != filepath.ToSlash(pactFile) || req.BrokerURL != "" {
+			t.Errorf("Configure() = %+v, want only the local pact file", req)
+		}
+	})
+
+	t.Run("broker", func(t *testing.T) {
+		var req provider.VerifyRequest
+		src := Source{BrokerURL: "https://broker.example.com", BrokerUsername: "ci", ProviderVersion: "abc123", ProviderBranch: "main"}
+		src.Configure(t, &req, "checkout-provider", pactFile, "TestConsumerContract")
+		if req.BrokerURL != src.BrokerURL || req.BrokerUsername != "ci" || len(req.PactFiles) != 0 {
+			t.Errorf("Configure() = %+v, want the broker and no pact file", req)
+		}
+		if req.Provider != "checkout-provider" || req.ProviderVersion != "abc123" || req.ProviderBranch != "main" || !req.PublishVerificationResults {
+			t.Errorf("Configure() = %+v, want the results of checkout-provider abc123 on main published", req)
+		}
+		if len(req.ConsumerVersionSelectors) != 2 {
+			t.Errorf("Configure() selectors = %v, want main and latest", req.ConsumerVersionSelectors)
+		}
+	})
+
+	t.Run("missing pact file", func(t *testing.T) {
+		ran := false
+		t.Run("provider", func(t *testing.T) {
+			Source{}.Configure(t, &provider.VerifyRequest{}, "checkout-provider", filepath.Join(t.TempDir(), "missing.json"), "TestConsumerContract")
+			ran = true
+		})
+		if ran {
+			t.Error("Configure() did not skip a test whose pact file is missing")
+		}
+	})
+}
+
+func TestMessage(t *testing.T) {
+	order := testdata.NewOrder().WithID("order-1").Build()
+	msg := &sarama.ProducerMessage{Headers: []sarama.RecordHeader{
+		{Key: []byte("event.type"), Value: []byte("OrderResult")},
+		{Key: []byte("traceparent"), Value: []byte("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")},
+	}}
+
+	body, metadata, err := Message(order, msg)
+	if err != nil {
+		t.Fatalf("Message() = %v", err)
+	}
+	if got := body.(map[string]any)["orderId"]; got != "order-1" {
+		t.Errorf("Message() body orderId = %v, want order-1", got)
+	}
+	want := map[string]any{
+		"contentType": "application/json",
+		"event.type":  "OrderResult",
+		"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}
+	if len(metadata) != len(want) {
+		t.Errorf("Message() metadata = %v, want %v", metadata, want)
+	}
+	for key, value := range want {
+		if metadata[key] != value {
+			t.Errorf("Message() metadata %s = %v, want %v", key, metadata[key], value)
+		}
+	}
+}
+
+func TestHeaderMessage(t *testing.T) {
+	order := testdata.NewOrder().WithID("order-1").Build()
+	_, metadata, err := HeaderMessage(order, map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"})
+	if err != nil {
+		t.Fatalf("HeaderMessage() = %v", err)
+	}
+	if metadata["contentType"] != "application/json" || metadata["traceparent"] == nil {
+		t.Errorf("HeaderMessage() metadata = %v, want the content type and the headers", metadata)
+	}
+}
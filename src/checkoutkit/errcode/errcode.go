@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Code is a stable, low-cardinality identifier for a class of failure. Codes
+// are attached to span statuses, span attributes, exception events and log
+// records so that dashboards and alerts can group failures without parsing
+// error messages.
+type Code string
+
+// Failure classes of the checkout order event pipeline.
+const (
+	// Unknown is reported for errors that were never classified.
+	Unknown Code = "UNKNOWN"
+
+	// KafkaEnqueueTimeout means the context ended before the producer accepted the message.
+	KafkaEnqueueTimeout Code = "KAFKA_ENQUEUE_TIMEOUT"
+	// KafkaAckTimeout means the context ended before the broker acknowledged the message.
+	KafkaAckTimeout Code = "KAFKA_ACK_TIMEOUT"
+	// KafkaProduceFailed means the broker rejected the message.
+	KafkaProduceFailed Code = "KAFKA_PRODUCE_FAILED"
+	// KafkaTransactionAborted means a Kafka transaction of order events was
+	// rolled back, so that none of them is visible to read-committed
+	// consumers.
+	KafkaTransactionAborted Code = "KAFKA_TRANSACTION_ABORTED"
+
+	// SerializationFailed means an order could not be encoded.
+	SerializationFailed Code = "SERIALIZATION_FAILED"
+	// RoundTripMismatch means an encoded order did not decode back to the original.
+	RoundTripMismatch Code = "ROUND_TRIP_MISMATCH"
+	// ValidationFailed means an order broke the event contract.
+	ValidationFailed Code = "VALIDATION_FAILED"
+	// SpoolWriteFailed means an order could not be written to the local spool.
+	SpoolWriteFailed Code = "SPOOL_WRITE_FAILED"
+	// FileWriteFailed means an order could not be written to the order event
+	// file of the file publisher.
+	FileWriteFailed Code = "FILE_WRITE_FAILED"
+	// OutboxWriteFailed means an order could not be written to the outbox
+	// table of the outbox publisher.
+	OutboxWriteFailed Code = "OUTBOX_WRITE_FAILED"
+	// BufferFull means the queue of the buffering publisher had no room for
+	// an order.
+	BufferFull Code = "BUFFER_FULL"
+	// PublisherClosed means an order was published after its publisher was
+	// closed for shutdown.
+	PublisherClosed Code = "PUBLISHER_CLOSED"
+	// WebhookDeliveryFailed means the order webhook could not be reached or
+	// rejected the order.
+	WebhookDeliveryFailed Code = "WEBHOOK_DELIVERY_FAILED"
+	// NATSAckTimeout means the stream did not acknowledge the message within
+	// the ack wait.
+	NATSAckTimeout Code = "NATS_ACK_TIMEOUT"
+	// NATSPublishFailed means the message could not be published to the
+	// stream, such as when no stream binds its subject.
+	NATSPublishFailed Code = "NATS_PUBLISH_FAILED"
+	// SNSPublishFailed means SNS could not be reached within the publish
+	// timeout or rejected the message.
+	SNSPublishFailed Code = "SNS_PUBLISH_FAILED"
+	// PubSubAckTimeout means Pub/Sub did not accept the message within the
+	// publish timeout.
+	PubSubAckTimeout Code = "PUBSUB_ACK_TIMEOUT"
+	// PubSubPublishFailed means Pub/Sub rejected the message, such as when
+	// the topic does not exist.
+	PubSubPublishFailed Code = "PUBSUB_PUBLISH_FAILED"
+	// MQTTAckTimeout means the broker did not acknowledge the message within
+	// the publish timeout.
+	MQTTAckTimeout Code = "MQTT_ACK_TIMEOUT"
+	// MQTTPublishFailed means the broker could not be reached or rejected
+	// the message, such as when the client is not authorized on the topic.
+	MQTTPublishFailed Code = "MQTT_PUBLISH_FAILED"
+
+	// DecodeFailed means a consumed message could not be decoded.
+	DecodeFailed Code = "DECODE_FAILED"
+	// HandlerFailed means the order event handler returned an error.
+	HandlerFailed Code = "HANDLER_FAILED"
+
+	// SchemaIncompatible means the registry rejected the order event schema.
+	SchemaIncompatible Code = "SCHEMA_INCOMPATIBLE"
+	// SchemaRegistryUnavailable means the schema registry could not be reached
+	// or answered with an unexpected error.
+	SchemaRegistryUnavailable Code = "SCHEMA_REGISTRY_UNAVAILABLE"
+)
+
+// Key is the attribute key codes are recorded under on spans, exception events
+// and log records.
+const Key = semconv.ErrorTypeKey
+
+// Error attaches a Code to an error. Its message is that of the wrapped error
+// so that classifying an error does not change what is logged.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Wrap classifies err with code. It returns nil if err is nil and leaves err
+// untouched if it already carries a code.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error like fmt.Errorf and classifies it with code.
+func Errorf(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Of returns the code carried by err, or Unknown if it has none.
+func Of(err error) Code {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return Unknown
+}
+
+// Attr returns the log attribute for the code carried by err.
+func Attr(err error) slog.Attr {
+	return slog.String(string(Key), string(Of(err)))
+}
+
+// RecordSpan marks span as failed with err. The code is recorded as a span
+// attribute and on the exception event, and prefixes the status description.
+func RecordSpan(span trace.Span, err error, description string) {
+	code := string(Of(err))
+	span.SetAttributes(Key.String(code))
+	span.RecordError(err, trace.WithAttributes(Key.String(code)))
+	span.SetStatus(otelcodes.Error, code+": "+description)
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOf(t *testing.T) {
+	base := errors.New("broker down")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"unclassified", base, Unknown},
+		{"wrapped", Wrap(KafkaProduceFailed, base), KafkaProduceFailed},
+		{"formatted", Errorf(KafkaAckTimeout, "waiting: %w", base), KafkaAckTimeout},
+		{"nested", fmt.Errorf("publish: %w", Wrap(SerializationFailed, base)), SerializationFailed},
+		{"first code wins", Wrap(HandlerFailed, Wrap(ValidationFailed, base)), ValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Of(tt.err); got != tt.want {
+				t.Errorf("Of() = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(tt.err, base) {
+				t.Errorf("errors.Is(%v, base) = false, want true", tt.err)
+			}
+		})
+	}
+}
+
+func TestWrapKeepsMessage(t *testing.T) {
+	if err := Wrap(ValidationFailed, nil); err != nil {
+		t.Errorf("Wrap(nil) = %v, want nil", err)
+	}
+	if got := Wrap(ValidationFailed, errors.New("bad order")).Error(); got != "bad order" {
+		t.Errorf("Error() = %q, want %q", got, "bad order")
+	}
+}
+
+func TestRecordSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := tp.Tracer("test").Start(t.Context(), "op")
+	RecordSpan(span, Wrap(KafkaAckTimeout, errors.New("deadline exceeded")), "no acknowledgment")
+	span.End()
+
+	got := recorder.Ended()[0]
+	if got.Status().Code != otelcodes.Error || got.Status().Description != "KAFKA_ACK_TIMEOUT: no acknowledgment" {
+		t.Errorf("status = %v, want Error with KAFKA_ACK_TIMEOUT description", got.Status())
+	}
+	var spanCode, eventCode string
+	for _, kv := range got.Attributes() {
+		if kv.Key == Key {
+			spanCode = kv.Value.AsString()
+		}
+	}
+	for _, ev := range got.Events() {
+		for _, kv := range ev.Attributes {
+			if kv.Key == Key {
+				eventCode = kv.Value.AsString()
+			}
+		}
+	}
+	if spanCode != string(KafkaAckTimeout) || eventCode != string(KafkaAckTimeout) {
+		t.Errorf("span code = %q, event code = %q; want %q on both", spanCode, eventCode, KafkaAckTimeout)
+	}
+}
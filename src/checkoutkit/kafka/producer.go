@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package kafka
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+var (
+	Topic = "orders"
+	// EventsTopic carries the order lifecycle events other than the
+	// OrderResult, which stays on Topic for its existing consumers
+	EventsTopic = "order-events"
+	// DeadLetterTopic receives the order events a subscriber rejected
+	DeadLetterTopic = "orders-dlq"
+	ProtocolVersion = sarama.V3_0_0_0
+)
+
+// RegionalTopic returns topic prefixed with region, such as us-east-1.orders,
+// or topic itself without a region.
+func RegionalTopic(region, topic string) string {
+	if region == "" {
+		return topic
+	}
+	return region + "." + topic
+}
+
+type saramaLogger struct {
+	logger *slog.Logger
+}
+
+func (l *saramaLogger) Printf(format string, v ...interface{}) {
+	l.logger.Info(fmt.Sprintf(format, v...))
+}
+func (l *saramaLogger) Println(v ...interface{}) {
+	l.logger.Info(fmt.Sprint(v...))
+}
+func (l *saramaLogger) Print(v ...interface{}) {
+	l.logger.Info(fmt.Sprint(v...))
+}
+
+// ProducerOption customizes the sarama configuration of a producer.
+type ProducerOption func(*sarama.Config)
+
+// WithProducerInterceptors installs interceptors that sarama calls each time
+// it dispatches a message, including every broker-level retry.
+func WithProducerInterceptors(interceptors ...sarama.ProducerInterceptor) ProducerOption {
+	return func(c *sarama.Config) {
+		c.Producer.Interceptors = append(c.Producer.Interceptors, interceptors...)
+	}
+}
+
+// WithIdempotence makes the producer idempotent: the broker discards the
+// duplicates of a message that the producer sent again after a lost
+// acknowledgment. It waits for every in-sync replica, which replaces the
+// NoResponse of CreateKafkaProducer.
+func WithIdempotence() ProducerOption {
+	return func(c *sarama.Config) {
+		c.Producer.Idempotent = true
+		c.Producer.RequiredAcks = sarama.WaitForAll
+		c.Net.MaxOpenRequests = 1
+	}
+}
+
+// WithBatching holds messages back until a partition has messages of them,
+// or bytes of messages, or linger has passed since the first one, and then
+// sends them in one request, to cut the per-message overhead under high
+// throughput. A zero messages or bytes is not a trigger. linger must be set
+// with either of them, or a partial batch waits for the next message, and on
+// its own it flushes every linger. Closing the producer still sends a
+// partial batch, once linger has passed, so linger also bounds how long
+// Close waits for it.
+func WithBatching(messages, bytes int, linger time.Duration) ProducerOption {
+	return func(c *sarama.Config) {
+		c.Producer.Flush.Messages = messages
+		c.Producer.Flush.Bytes = bytes
+		c.Producer.Flush.Frequency = linger
+	}
+}
+
+// partitioners are the partitioners WithPartitioner accepts by name.
+var partitioners = map[string]sarama.PartitionerConstructor{
+	"hash":       sarama.NewHashPartitioner,
+	"random":     sarama.NewRandomPartitioner,
+	"roundrobin": sarama.NewRoundRobinPartitioner,
+}
+
+// WithPartitioner selects the partitioner of messages by name: hash, the
+// default, which sends messages with the same key to the same partition and
+// spreads the others randomly, random or roundrobin. An unknown name is
+// ignored.
+func WithPartitioner(name string) ProducerOption {
+	return func(c *sarama.Config) {
+		if partitioner, ok := partitioners[name]; ok {
+			c.Producer.Partitioner = partitioner
+		}
+	}
+}
+
+// ValidPartitioner reports whether WithPartitioner accepts name.
+func ValidPartitioner(name string) bool {
+	_, ok := partitioners[name]
+	return ok
+}
+
+func CreateKafkaProducer(brokers []string, logger *slog.Logger, opts ...ProducerOption) (sarama.AsyncProducer, error) {
+	// Set the logger for sarama to use.
+	sarama.Logger = &saramaLogger{logger: logger}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Return.Errors = true
+
+	// Sarama has an issue in a single broker kafka if the kafka broker is restarted.
+	// This setting is to prevent that issue from manifesting itself, but may swallow failed messages.
+	saramaConfig.Producer.RequiredAcks = sarama.NoResponse
+
+	saramaConfig.Version = ProtocolVersion
+
+	// So we can know the partition and offset of messages.
+	saramaConfig.Producer.Return.Successes = true
+
+	for _, opt := range opts {
+		opt(saramaConfig)
+	}
+
+	// The Successes and Errors channels are drained by the adapter that owns
+	// the producer, which matches each acknowledgment to its publish call.
+	producer, err := sarama.NewAsyncProducer(brokers, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+	return producer, nil
+}
+
+// CreateSyncProducer creates a producer whose sends return once every
+// in-sync replica has the message, for deployments that must not answer
+// PlaceOrder before its order event is stored. Unlike the producer of
+// CreateKafkaProducer, it never swallows a failed message.
+func CreateSyncProducer(brokers []string, logger *slog.Logger, opts ...ProducerOption) (sarama.SyncProducer, error) {
+	sarama.Logger = &saramaLogger{logger: logger}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = ProtocolVersion
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Return.Errors = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+
+	for _, opt := range opts {
+		opt(saramaConfig)
+	}
+	return sarama.NewSyncProducer(brokers, saramaConfig)
+}
+
+// CreateTransactionalProducer creates a producer that publishes messages in
+// transactions, so that read-committed consumers see either every message of
+// a transaction or none. Transactions require an idempotent producer waiting
+// for all in-sync replicas. transactionalID must be unique per instance: a
+// producer opened with the same ID fences off the previous one.
+func CreateTransactionalProducer(brokers []string, logger *slog.Logger, transactionalID string, opts ...ProducerOption) (sarama.SyncProducer, error) {
+	sarama.Logger = &saramaLogger{logger: logger}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = ProtocolVersion
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.Return.Errors = true
+	saramaConfig.Producer.Idempotent = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	saramaConfig.Producer.Transaction.ID = transactionalID
+	saramaConfig.Net.MaxOpenRequests = 1
+
+	for _, opt := range opts {
+		opt(saramaConfig)
+	}
+	return sarama.NewSyncProducer(brokers, saramaConfig)
+}
@@ -0,0 +1,22 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func TestWithBatching(t *testing.T) {
+	config := sarama.NewConfig()
+	WithBatching(100, 64<<10, 20*time.Millisecond)(config)
+
+	if config.Producer.Flush.Messages != 100 || config.Producer.Flush.Bytes != 64<<10 || config.Producer.Flush.Frequency != 20*time.Millisecond {
+		t.Errorf("Producer.Flush = %+v, want 100 messages, 64 KiB and 20ms", config.Producer.Flush)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
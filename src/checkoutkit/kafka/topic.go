@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicSize returns the number of messages retained on topic: the sum over
+// its partitions of the newest offset minus the oldest.
+func TopicSize(brokers []string, topic string) (int64, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = ProtocolVersion
+	client, err := sarama.NewClient(brokers, saramaConfig)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the partitions of %s: %w", topic, err)
+	}
+	var size int64
+	for _, partition := range partitions {
+		newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the newest offset of %s/%d: %w", topic, partition, err)
+		}
+		oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the oldest offset of %s/%d: %w", topic, partition, err)
+		}
+		size += newest - oldest
+	}
+	return size, nil
+}
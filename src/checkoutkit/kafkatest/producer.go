@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package kafkatest provides in-process Kafka producers for the unit and
+// contract tests of the Kafka adapters, so that they exercise acknowledged,
+// failed and slowly acknowledged publishes without a broker:
+//
+//	producer := kafkatest.NewProducer(t)
+//	producer.ExpectSuccess()
+//	producer.ExpectError(sarama.ErrNotLeaderForPartition)
+//	producer.ExpectSlowAck(50 * time.Millisecond)
+//	pub := adapters.NewKafkaOrderEventPublisher(producer, logger)
+//
+// The producers wrap sarama's mocks: each message written must match the next
+// expectation in order, and expectations left when the test ends fail it.
+package kafkatest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+)
+
+// Config returns the producer configuration of the service as far as the
+// mocks honour it: successes and errors are both returned.
+func Config() *sarama.Config {
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	return config
+}
+
+// Producer is a sarama.AsyncProducer answering each message with the next
+// expectation and recording the messages it was given. It is closed when the
+// test ends, and closing it again is a no-op.
+type Producer struct {
+	*mocks.AsyncProducer
+
+	closeOnce sync.Once
+	mu        sync.Mutex
+	messages  []*sarama.ProducerMessage
+}
+
+// Compile-time check that Producer implements sarama.AsyncProducer
+var _ sarama.AsyncProducer = (*Producer)(nil)
+
+// NewProducer returns a Producer with the Config of the service.
+func NewProducer(t testing.TB) *Producer {
+	t.Helper()
+	p := &Producer{AsyncProducer: mocks.NewAsyncProducer(t, Config())}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// ExpectSuccess acknowledges the next message.
+func (p *Producer) ExpectSuccess() {
+	p.ExpectMessage(nil)
+}
+
+// ExpectError fails the next message with err, as the broker would once the
+// producer gave up retrying.
+func (p *Producer) ExpectError(err error) {
+	p.AsyncProducer.ExpectInputWithMessageCheckerFunctionAndFail(p.record(nil), err)
+}
+
+// ExpectSlowAck acknowledges the next message after delay. Acks stay in
+// order, so the messages after it are delayed as well.
+func (p *Producer) ExpectSlowAck(delay time.Duration) {
+	p.ExpectMessage(func(*sarama.ProducerMessage) error {
+		time.Sleep(delay)
+		return nil
+	})
+}
+
+// ExpectMessage acknowledges the next message once check accepted it. An
+// error from check fails the test and the message.
+func (p *Producer) ExpectMessage(check func(*sarama.ProducerMessage) error) {
+	p.AsyncProducer.ExpectInputWithMessageCheckerFunctionAndSucceed(p.record(check))
+}
+
+func (p *Producer) record(check func(*sarama.ProducerMessage) error) mocks.MessageChecker {
+	return func(msg *sarama.ProducerMessage) error {
+		p.mu.Lock()
+		p.messages = append(p.messages, msg)
+		p.mu.Unlock()
+		if check == nil {
+			return nil
+		}
+		return check(msg)
+	}
+}
+
+// Messages returns the messages matched against the expectations set with
+// the Expect methods of Producer, in order.
+func (p *Producer) Messages() []*sarama.ProducerMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*sarama.ProducerMessage(nil), p.messages...)
+}
+
+// AsyncClose stops the producer without waiting for it.
+func (p *Producer) AsyncClose() {
+	p.closeOnce.Do(p.AsyncProducer.AsyncClose)
+}
+
+// Close stops the producer and, like sarama's, drains the acknowledgments
+// nobody read and returns the errors among them.
+func (p *Producer) Close() error {
+	p.AsyncClose()
+	for range p.Successes() {
+	}
+	var errs sarama.ProducerErrors
+	for err := range p.Errors() {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// NewSyncProducer returns a sarama.SyncProducer with the Config of the
+// service.
+func NewSyncProducer(t testing.TB) *mocks.SyncProducer {
+	return mocks.NewSyncProducer(t, Config())
+}
+
+// NewTransactionalProducer returns a sarama.SyncProducer configured for
+// transactions as the batch publisher requires, with transactionalID as its
+// transactional ID.
+func NewTransactionalProducer(t testing.TB, transactionalID string) *mocks.SyncProducer {
+	config := mocks.NewTestConfig()
+	config.Version = kafka.ProtocolVersion
+	config.Producer.Idempotent = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Net.MaxOpenRequests = 1
+	config.Producer.Transaction.ID = transactionalID
+	return mocks.NewSyncProducer(t, config)
+}
+
+// NewBroker returns an in-process broker leading partition 0 of topic and
+// acknowledging every produce request, for the tests that need sarama's own
+// producer, such as those of its batching, rather than a mock. It is closed
+// when the test ends, and History returns the requests it received.
+func NewBroker(t testing.TB, topic string) *sarama.MockBroker {
+	t.Helper()
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(topic, 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t),
+	})
+	t.Cleanup(broker.Close)
+	return broker
+}
+
+// Header returns the value of the header key of msg.
+func Header(msg *sarama.ProducerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Headers returns the headers of msg by key.
+func Headers(msg *sarama.ProducerMessage) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[string(h.Key)] = string(h.Value)
+	}
+	return headers
+}
+
+// Consumed returns msg as a consumer reads it once the broker stored it at
+// offset, for tests handing published messages to a subscriber without a
+// broker.
+func Consumed(t testing.TB, msg *sarama.ProducerMessage, offset int64) *sarama.ConsumerMessage {
+	t.Helper()
+	encode := func(e sarama.Encoder) []byte {
+		if e == nil {
+			return nil
+		}
+		b, err := e.Encode()
+		if err != nil {
+			t.Fatalf("failed to encode message %s/%d: %v", msg.Topic, offset, err)
+		}
+		return b
+	}
+	consumed := &sarama.ConsumerMessage{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    offset,
+		Key:       encode(msg.Key),
+		Value:     encode(msg.Value),
+		Timestamp: msg.Timestamp,
+	}
+	for _, h := range msg.Headers {
+		consumed.Headers = append(consumed.Headers, &sarama.RecordHeader{Key: h.Key, Value: h.Value})
+	}
+	return consumed
+}
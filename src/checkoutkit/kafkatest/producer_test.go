@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package kafkatest
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/kafka"
+)
+
+func TestProducer(t *testing.T) {
+	producer := NewProducer(t)
+	brokerErr := sarama.ErrNotLeaderForPartition
+	producer.ExpectSuccess()
+	producer.ExpectError(brokerErr)
+	producer.ExpectSlowAck(20 * time.Millisecond)
+
+	for _, key := range []string{"ok", "fail", "slow"} {
+		producer.Input() <- &sarama.ProducerMessage{
+			Topic:   "orders",
+			Key:     sarama.StringEncoder(key),
+			Headers: []sarama.RecordHeader{{Key: []byte("type"), Value: []byte(key)}},
+		}
+	}
+
+	if msg := <-producer.Successes(); Header(msg, "type") != "ok" {
+		t.Errorf("first ack = %v, want the ok message", Headers(msg))
+	}
+	if perr := <-producer.Errors(); !errors.Is(perr.Err, brokerErr) || Header(perr.Msg, "type") != "fail" {
+		t.Errorf("error = %v for %v, want %v for the fail message", perr.Err, Headers(perr.Msg), brokerErr)
+	}
+	start := time.Now()
+	if msg := <-producer.Successes(); Header(msg, "type") != "slow" {
+		t.Errorf("second ack = %v, want the slow message", Headers(msg))
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("slow ack after %v, want it delayed", elapsed)
+	}
+
+	messages := producer.Messages()
+	if len(messages) != 3 {
+		t.Fatalf("Messages() = %d messages, want 3", len(messages))
+	}
+	for i, want := range []string{"ok", "fail", "slow"} {
+		if got := Header(messages[i], "type"); got != want {
+			t.Errorf("message %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestProducerCloseReturnsUnreadErrors(t *testing.T) {
+	producer := NewProducer(t)
+	producer.ExpectError(sarama.ErrOutOfBrokers)
+	producer.Input() <- &sarama.ProducerMessage{Topic: "orders"}
+
+	err := producer.Close()
+	var errs sarama.ProducerErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs[0].Err, sarama.ErrOutOfBrokers) {
+		t.Errorf("Close() = %v, want the unread %v", err, sarama.ErrOutOfBrokers)
+	}
+	if err := producer.Close(); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+}
+
+func TestConsumed(t *testing.T) {
+	msg := &sarama.ProducerMessage{
+		Topic:     "orders",
+		Key:       sarama.StringEncoder("order-1"),
+		Value:     sarama.ByteEncoder("payload"),
+		Headers:   []sarama.RecordHeader{{Key: []byte("event.type"), Value: []byte("OrderResult")}},
+		Partition: 2,
+	}
+	got := Consumed(t, msg, 7)
+	if got.Topic != "orders" || got.Partition != 2 || got.Offset != 7 || string(got.Key) != "order-1" || string(got.Value) != "payload" {
+		t.Errorf("Consumed() = %+v, want the message at orders/2@7", got)
+	}
+	if len(got.Headers) != 1 || string(got.Headers[0].Key) != "event.type" || string(got.Headers[0].Value) != "OrderResult" {
+		t.Errorf("Consumed() headers = %v, want event.type OrderResult", got.Headers)
+	}
+}
+
+func TestCloseFlushesPartialBatch(t *testing.T) {
+	const linger = 200 * time.Millisecond
+	broker := NewBroker(t, "orders")
+	producer, err := kafka.CreateKafkaProducer([]string{broker.Addr()}, slog.New(slog.DiscardHandler), kafka.WithBatching(100, 0, linger))
+	if err != nil {
+		t.Fatalf("CreateKafkaProducer() = %v", err)
+	}
+	producer.Input() <- &sarama.ProducerMessage{Topic: "orders", Value: sarama.StringEncoder("order-1")}
+
+	select {
+	case msg := <-producer.Successes():
+		t.Fatalf("acknowledged %v before the batch was full or lingered", msg.Value)
+	case <-time.After(linger / 4):
+	}
+
+	// Close sends the partial batch once linger has passed rather than
+	// dropping it or waiting for the batch to fill
+	start := time.Now()
+	producer.AsyncClose()
+	var acked int
+	for range producer.Successes() {
+		acked++
+	}
+	for perr := range producer.Errors() {
+		t.Errorf("Close() failed to flush %v: %v", perr.Msg.Value, perr.Err)
+	}
+	if acked != 1 {
+		t.Errorf("Close() flushed %d messages, want the partial batch of 1", acked)
+	}
+	if elapsed := time.Since(start); elapsed > 5*linger {
+		t.Errorf("Close() took %v, want it bounded by the %v linger", elapsed, linger)
+	}
+	// The producer does not wait for the broker to answer, so the request
+	// may still be on its way
+	produced := func() (n int) {
+		for _, rr := range broker.History() {
+			if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+				n++
+			}
+		}
+		return n
+	}
+	for deadline := time.Now().Add(time.Second); produced() == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := produced(); n != 1 {
+		t.Errorf("broker received %d produce requests, want 1", n)
+	}
+}
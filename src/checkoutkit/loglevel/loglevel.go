@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package loglevel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+// Controller holds the minimum level of the service's logs, so that publish
+// path debugging can be enabled in production without a restart.
+type Controller struct {
+	initial slog.Level
+	level   slog.LevelVar
+}
+
+// NewController creates a Controller starting at initial.
+func NewController(initial slog.Level) *Controller {
+	c := &Controller{initial: initial}
+	c.level.Set(initial)
+	return c
+}
+
+// Parse parses a level name such as "debug" or "warn". An empty string selects
+// slog.LevelInfo.
+func Parse(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// Level returns the current level.
+func (c *Controller) Level() slog.Level {
+	return c.level.Level()
+}
+
+// Set changes the current level.
+func (c *Controller) Set(level slog.Level) {
+	c.level.Set(level)
+}
+
+// ToggleDebug switches between debug and the initial level and returns the
+// new level.
+func (c *Controller) ToggleDebug() slog.Level {
+	level := slog.LevelDebug
+	if c.level.Level() == slog.LevelDebug {
+		level = c.initial
+	}
+	c.level.Set(level)
+	return level
+}
+
+// Handler wraps next so that records below the current level are dropped.
+func (c *Controller) Handler(next slog.Handler) slog.Handler {
+	return &levelHandler{next: next, level: &c.level}
+}
+
+// WatchSignals toggles debug logging each time the process receives one of
+// signals, until ctx is done.
+func (c *Controller) WatchSignals(ctx context.Context, logger *slog.Logger, signals ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+	c.watch(ctx, logger, ch)
+}
+
+func (c *Controller) watch(ctx context.Context, logger *slog.Logger, ch <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			level := c.ToggleDebug()
+			logger.Warn(fmt.Sprintf("log level changed to %s on %s", level, sig))
+		}
+	}
+}
+
+type levelResponse struct {
+	Level string `json:"level"`
+}
+
+// ServeHTTP reports the current level on GET and changes it on PUT or POST,
+// taking the new level from the level query parameter.
+func (c *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		name := r.URL.Query().Get("level")
+		if name == "" {
+			http.Error(w, "missing level query parameter", http.StatusBadRequest)
+			return
+		}
+		level, err := Parse(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.Set(level)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(levelResponse{Level: c.Level().String()})
+}
+
+// levelHandler drops records below level before they reach next.
+type levelHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package loglevel
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    slog.Level
+		wantErr bool
+	}{
+		{in: "", want: slog.LevelInfo},
+		{in: "debug", want: slog.LevelDebug},
+		{in: "WARN", want: slog.LevelWarn},
+		{in: "error", want: slog.LevelError},
+		{in: "verbose", want: slog.LevelInfo, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestControllerHandler(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewController(slog.LevelInfo)
+	logger := slog.New(c.Handler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))).With("component", "publisher")
+
+	logger.Debug("hidden")
+	if c.ToggleDebug() != slog.LevelDebug {
+		t.Fatalf("ToggleDebug() did not switch to debug")
+	}
+	logger.Debug("shown")
+	if c.ToggleDebug() != slog.LevelInfo {
+		t.Fatalf("ToggleDebug() did not switch back to the initial level")
+	}
+	logger.Debug("hidden again")
+
+	if got := buf.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "msg=shown component=publisher") {
+		t.Errorf("logged %q, want only the record logged at debug level", got)
+	}
+}
+
+func TestControllerServeHTTP(t *testing.T) {
+	c := NewController(slog.LevelInfo)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tests := []struct {
+		method     string
+		query      string
+		wantStatus int
+		wantLevel  slog.Level
+	}{
+		{method: http.MethodGet, wantStatus: http.StatusOK, wantLevel: slog.LevelInfo},
+		{method: http.MethodPut, query: "?level=debug", wantStatus: http.StatusOK, wantLevel: slog.LevelDebug},
+		{method: http.MethodPut, query: "?level=verbose", wantStatus: http.StatusBadRequest, wantLevel: slog.LevelDebug},
+		{method: http.MethodPost, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelDebug},
+		{method: http.MethodDelete, wantStatus: http.StatusMethodNotAllowed, wantLevel: slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.query, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.query, resp.StatusCode, tt.wantStatus)
+		}
+		if got := c.Level(); got != tt.wantLevel {
+			t.Errorf("after %s %s level = %v, want %v", tt.method, tt.query, got, tt.wantLevel)
+		}
+	}
+}
+
+func TestControllerWatch(t *testing.T) {
+	c := NewController(slog.LevelInfo)
+	ch := make(chan os.Signal)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.watch(ctx, slog.New(slog.DiscardHandler), ch)
+		close(done)
+	}()
+
+	ch <- syscall.SIGHUP
+	ch <- syscall.SIGHUP
+	ch <- syscall.SIGHUP
+	cancel()
+	<-done
+	if got := c.Level(); got != slog.LevelDebug {
+		t.Errorf("level after three signals = %v, want %v", got, slog.LevelDebug)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pactdir keeps the pact files of the consumer contract tests intact
+// when the tests run in parallel, within one test binary or across several.
+// Each test records its pact into a temporary directory of its own, which is
+// merged into the shared pact directory when the test ends:
+//
+//	p, err := messagev3.NewAsynchronousPact(messagev3.Config{
+//		PactDir: pactdir.For(t, "pacts"),
+//	})
+//
+// A merge holds a lock on the shared directory and replaces each pact file
+// with a rename, so a reader never sees a partly written file.
+package pactdir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// lockFile is the name of the lock file a merge creates in the shared
+// directory.
+const lockFile = ".pactdir.lock"
+
+// lockTimeout bounds the wait for another merge to release the lock. A lock
+// older than that is left over by a killed test run and is broken.
+const lockTimeout = 30 * time.Second
+
+// For returns a new temporary pact directory for t. When t ends, the pact
+// files written to it are merged into dir, unless t failed, so that a failed
+// test leaves the shared pacts as they were.
+func For(t testing.TB, dir string) string {
+	t.Helper()
+	tmp := t.TempDir()
+	t.Cleanup(func() {
+		if t.Failed() {
+			return
+		}
+		if err := Merge(tmp, dir); err != nil {
+			t.Errorf("failed to merge the pact files into %s: %v", dir, err)
+		}
+	})
+	return tmp
+}
+
+// Merge merges every pact file of src into the file of the same name in dst,
+// creating dst if needed.
+func Merge(src, dst string) error {
+	files, err := filepath.Glob(filepath.Join(src, "*.json"))
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	unlock, err := lock(dst)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	for _, file := range files {
+		if err := mergeFile(file, filepath.Join(dst, filepath.Base(file))); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+// mergeFile merges the pact file src into dst. The interactions of src
+// replace those of dst with the same description and provider states, the
+// others of dst are kept, and the other fields are those of src.
+func mergeFile(src, dst string) error {
+	pact, err := readPact(src)
+	if err != nil {
+		return err
+	}
+	existing, err := readPact(dst)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// Message pacts of the V3 specification list messages, the others
+	// interactions
+	for _, field := range []string{"interactions", "messages"} {
+		if _, ok := pact[field]; !ok {
+			continue
+		}
+		merged, err := mergeInteractions(existing[field], pact[field])
+		if err != nil {
+			return err
+		}
+		pact[field] = merged
+	}
+	data, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(dst, data)
+}
+
+func readPact(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pact map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pact); err != nil {
+		return nil, fmt.Errorf("invalid pact file %s: %w", path, err)
+	}
+	return pact, nil
+}
+
+// mergeInteractions returns the interactions of existing, with those of
+// recorded in place of the ones they match, followed by the other recorded
+// interactions.
+func mergeInteractions(existing, recorded json.RawMessage) (json.RawMessage, error) {
+	var old, recent []json.RawMessage
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &old); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(recorded, &recent); err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, interaction := range old {
+		key, err := interactionKey(interaction)
+		if err != nil {
+			return nil, err
+		}
+		index[key] = i
+	}
+	for _, interaction := range recent {
+		key, err := interactionKey(interaction)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[key]; ok {
+			old[i] = interaction
+			continue
+		}
+		index[key] = len(old)
+		old = append(old, interaction)
+	}
+	return json.Marshal(old)
+}
+
+// interactionKey identifies an interaction the way pact does when it merges
+// pact files: by its description and its provider states.
+func interactionKey(interaction json.RawMessage) (string, error) {
+	var key struct {
+		Description    string          `json:"description"`
+		ProviderState  string          `json:"providerState"`
+		ProviderStates json.RawMessage `json:"providerStates"`
+	}
+	if err := json.Unmarshal(interaction, &key); err != nil {
+		return "", err
+	}
+	// Compact the states, which are indented once merged
+	var states bytes.Buffer
+	if len(key.ProviderStates) > 0 {
+		if err := json.Compact(&states, key.ProviderStates); err != nil {
+			return "", err
+		}
+	}
+	return key.Description + "\x00" + key.ProviderState + "\x00" + states.String(), nil
+}
+
+// writeFile replaces path with data through a rename.
+func writeFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".pact-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// lock takes the lock of dir, waiting for other merges for up to
+// lockTimeout, and returns its release.
+func lock(dir string) (func(), error) {
+	path := filepath.Join(dir, lockFile)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockTimeout {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package pactdir
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePact writes a pact file with one interaction per description.
+func writePact(t *testing.T, dir, field string, descriptions ...string) {
+	t.Helper()
+	var interactions []map[string]any
+	for _, d := range descriptions {
+		interactions = append(interactions, map[string]any{
+			"description":    d,
+			"providerStates": []map[string]any{{"name": "user-1 has placed no orders"}},
+			"response":       map[string]any{"status": 200, "recordedIn": dir},
+		})
+	}
+	data, err := json.Marshal(map[string]any{
+		"consumer": map[string]any{"name": "web-client"},
+		"provider": map[string]any{"name": "checkout-provider"},
+		field:      interactions,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "web-client-checkout-provider.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readInteractions returns the interactions of the pact file in dir, by
+// description, with the directory each was recorded in.
+func readInteractions(t *testing.T, dir, field string) map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "web-client-checkout-provider.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pact map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pact); err != nil {
+		t.Fatal(err)
+	}
+	var interactions []struct {
+		Description string `json:"description"`
+		Response    struct {
+			RecordedIn string `json:"recordedIn"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(pact[field], &interactions); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, i := range interactions {
+		if _, ok := got[i.Description]; ok {
+			t.Errorf("interaction %q is in the pact file twice", i.Description)
+		}
+		got[i.Description] = i.Response.RecordedIn
+	}
+	return got
+}
+
+func TestMerge(t *testing.T) {
+	for _, field := range []string{"interactions", "messages"} {
+		t.Run(field, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "pacts")
+			first, second := t.TempDir(), t.TempDir()
+			writePact(t, first, field, "GET /orders/1", "POST /orders")
+			writePact(t, second, field, "POST /orders", "GET /orders/2")
+
+			if err := Merge(first, dst); err != nil {
+				t.Fatalf("Merge() = %v", err)
+			}
+			if err := Merge(second, dst); err != nil {
+				t.Fatalf("Merge() = %v", err)
+			}
+			got := readInteractions(t, dst, field)
+			want := map[string]string{"GET /orders/1": first, "POST /orders": second, "GET /orders/2": second}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("merged interactions = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestForMergesParallelTests(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "pacts")
+	t.Run("group", func(t *testing.T) {
+		for i := range 20 {
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				writePact(t, For(t, dst), "interactions", fmt.Sprintf("GET /orders/%d", i))
+			})
+		}
+	})
+
+	if got := readInteractions(t, dst, "interactions"); len(got) != 20 {
+		t.Errorf("merged %d interactions, want one per test: %v", len(got), got)
+	}
+	if _, err := os.Stat(filepath.Join(dst, lockFile)); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import "context"
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// Alert describes a condition operators should know about, such as a publish
+// that took longer than its configured threshold.
+type Alert struct {
+	// Name identifies the kind of alert, e.g. "slow_publish"
+	Name string
+	// Message is a human readable summary
+	Message string
+	// Attributes carries structured context such as the broker and topic
+	Attributes map[string]string
+}
+
+// AlertNotifier defines the port for raising operational alerts.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters deliver alerts to a pager, chat channel or incident tool
+type AlertNotifier interface {
+	// Notify delivers alert. Implementations should return promptly; callers
+	// do not retry failed notifications.
+	Notify(ctx context.Context, alert Alert) error
+}
@@ -0,0 +1,31 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// CheckoutUseCase defines the port through which clients place and query
+// orders. The gRPC server implements it, and the HTTP and GraphQL adapters
+// translate their requests onto it, so the business logic is the same for
+// every protocol.
+//
+// In hexagonal architecture terms:
+// - This is a Primary Port (input port)
+// - Adapters drive it from gRPC, HTTP or GraphQL requests
+type CheckoutUseCase interface {
+	// PlaceOrder places an order. Idempotency keys and the async mode are read
+	// from the incoming gRPC metadata.
+	PlaceOrder(ctx context.Context, req *pb.PlaceOrderRequest) (*pb.PlaceOrderResponse, error)
+
+	// GetOrder returns an order the user placed.
+	GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error)
+
+	// ListOrders returns a page of the orders the user placed, newest first.
+	ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error)
+}
@@ -0,0 +1,23 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// CurrencyConverter defines the port for converting item and shipping costs to
+// the currency of the user placing an order.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters call the demo currency service, caching its rates
+type CurrencyConverter interface {
+	// Convert returns amount in currency. Amounts already in currency are
+	// returned as is, others are rounded down to the cent.
+	Convert(ctx context.Context, amount *pb.Money, currency string) (*pb.Money, error)
+}
@@ -0,0 +1,21 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// EmailService defines the port for emailing customers.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters call the demo email service or an email provider
+type EmailService interface {
+	// SendOrderConfirmation emails confirmation, rendered for order, to email.
+	SendOrderConfirmation(ctx context.Context, email string, order *pb.OrderResult, confirmation OrderConfirmation) error
+}
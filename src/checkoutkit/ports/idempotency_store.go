@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// ErrRequestInProgress is returned by IdempotencyStore.Reserve while another
+// request with the same key is still being processed.
+var ErrRequestInProgress = errors.New("a request with the same idempotency key is in progress")
+
+// IdempotencyStore defines the port for remembering the outcome of PlaceOrder
+// calls by their client-supplied request ID, so that a retried call returns
+// the original order instead of charging the card and publishing again.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters keep the outcomes in memory, a database or a cache
+type IdempotencyStore interface {
+	// Reserve claims key for a new request and returns a nil order. When a
+	// request with key already completed it returns that request's order
+	// instead, and while one is still being processed it returns
+	// ErrRequestInProgress.
+	Reserve(ctx context.Context, key string) (*pb.OrderResult, error)
+
+	// Complete records order as the outcome of the request holding key.
+	Complete(ctx context.Context, key string, order *pb.OrderResult) error
+
+	// Release frees key after its request failed, so that the client can
+	// retry it.
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// OutOfStockError is returned by InventoryReserver.Reserve when some items of
+// an order are not in stock. Nothing is reserved then.
+type OutOfStockError struct {
+	// ProductIDs are the products short of the ordered quantity
+	ProductIDs []string
+}
+
+func (e *OutOfStockError) Error() string {
+	return "out of stock: " + strings.Join(e.ProductIDs, ", ")
+}
+
+// InventoryReserver defines the port for holding stock for an order while it
+// is charged and shipped.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters keep stock in memory or call an inventory service
+type InventoryReserver interface {
+	// Reserve holds the stock of items for orderID, all of it or none. It
+	// returns an *OutOfStockError when some items are not in stock.
+	Reserve(ctx context.Context, orderID string, items []*pb.CartItem) error
+
+	// Release returns the stock reserved for orderID, for an order that failed.
+	Release(ctx context.Context, orderID string) error
+
+	// Confirm turns the reservation of orderID into a sale, for an order that
+	// completed.
+	Confirm(ctx context.Context, orderID string) error
+}
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import "context"
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// Lifecycle is implemented by adapters that hold work in flight or resources
+// that must be released when the application shuts down, such as a publisher
+// waiting for broker acknowledgments. Decorators forward Close to the adapter
+// they wrap.
+type Lifecycle interface {
+	// Close stops accepting new work and waits until the work in flight has
+	// finished, or ctx is done, before releasing the adapter's resources.
+	// Calling Close more than once has no further effect.
+	Close(ctx context.Context) error
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: checkout_use_case.go
+//
+// Generated by this command:
+//
+//	mockgen -source=checkout_use_case.go -destination=mocks/checkout_use_case.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCheckoutUseCase is a mock of CheckoutUseCase interface.
+type MockCheckoutUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockCheckoutUseCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockCheckoutUseCaseMockRecorder is the mock recorder for MockCheckoutUseCase.
+type MockCheckoutUseCaseMockRecorder struct {
+	mock *MockCheckoutUseCase
+}
+
+// NewMockCheckoutUseCase creates a new mock instance.
+func NewMockCheckoutUseCase(ctrl *gomock.Controller) *MockCheckoutUseCase {
+	mock := &MockCheckoutUseCase{ctrl: ctrl}
+	mock.recorder = &MockCheckoutUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCheckoutUseCase) EXPECT() *MockCheckoutUseCaseMockRecorder {
+	return m.recorder
+}
+
+// GetOrder mocks base method.
+func (m *MockCheckoutUseCase) GetOrder(ctx context.Context, req *oteldemo.GetOrderRequest) (*oteldemo.GetOrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, req)
+	ret0, _ := ret[0].(*oteldemo.GetOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockCheckoutUseCaseMockRecorder) GetOrder(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockCheckoutUseCase)(nil).GetOrder), ctx, req)
+}
+
+// ListOrders mocks base method.
+func (m *MockCheckoutUseCase) ListOrders(ctx context.Context, req *oteldemo.ListOrdersRequest) (*oteldemo.ListOrdersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, req)
+	ret0, _ := ret[0].(*oteldemo.ListOrdersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockCheckoutUseCaseMockRecorder) ListOrders(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockCheckoutUseCase)(nil).ListOrders), ctx, req)
+}
+
+// PlaceOrder mocks base method.
+func (m *MockCheckoutUseCase) PlaceOrder(ctx context.Context, req *oteldemo.PlaceOrderRequest) (*oteldemo.PlaceOrderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceOrder", ctx, req)
+	ret0, _ := ret[0].(*oteldemo.PlaceOrderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PlaceOrder indicates an expected call of PlaceOrder.
+func (mr *MockCheckoutUseCaseMockRecorder) PlaceOrder(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceOrder", reflect.TypeOf((*MockCheckoutUseCase)(nil).PlaceOrder), ctx, req)
+}
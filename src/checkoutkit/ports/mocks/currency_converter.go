@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: currency_converter.go
+//
+// Generated by this command:
+//
+//	mockgen -source=currency_converter.go -destination=mocks/currency_converter.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCurrencyConverter is a mock of CurrencyConverter interface.
+type MockCurrencyConverter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurrencyConverterMockRecorder
+	isgomock struct{}
+}
+
+// MockCurrencyConverterMockRecorder is the mock recorder for MockCurrencyConverter.
+type MockCurrencyConverterMockRecorder struct {
+	mock *MockCurrencyConverter
+}
+
+// NewMockCurrencyConverter creates a new mock instance.
+func NewMockCurrencyConverter(ctrl *gomock.Controller) *MockCurrencyConverter {
+	mock := &MockCurrencyConverter{ctrl: ctrl}
+	mock.recorder = &MockCurrencyConverterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurrencyConverter) EXPECT() *MockCurrencyConverterMockRecorder {
+	return m.recorder
+}
+
+// Convert mocks base method.
+func (m *MockCurrencyConverter) Convert(ctx context.Context, amount *oteldemo.Money, currency string) (*oteldemo.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Convert", ctx, amount, currency)
+	ret0, _ := ret[0].(*oteldemo.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockCurrencyConverterMockRecorder) Convert(ctx, amount, currency any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockCurrencyConverter)(nil).Convert), ctx, amount, currency)
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: email_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=email_service.go -destination=mocks/email_service.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEmailService is a mock of EmailService interface.
+type MockEmailService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailServiceMockRecorder is the mock recorder for MockEmailService.
+type MockEmailServiceMockRecorder struct {
+	mock *MockEmailService
+}
+
+// NewMockEmailService creates a new mock instance.
+func NewMockEmailService(ctrl *gomock.Controller) *MockEmailService {
+	mock := &MockEmailService{ctrl: ctrl}
+	mock.recorder = &MockEmailServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailService) EXPECT() *MockEmailServiceMockRecorder {
+	return m.recorder
+}
+
+// SendOrderConfirmation mocks base method.
+func (m *MockEmailService) SendOrderConfirmation(ctx context.Context, email string, order *oteldemo.OrderResult, confirmation ports.OrderConfirmation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendOrderConfirmation", ctx, email, order, confirmation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendOrderConfirmation indicates an expected call of SendOrderConfirmation.
+func (mr *MockEmailServiceMockRecorder) SendOrderConfirmation(ctx, email, order, confirmation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOrderConfirmation", reflect.TypeOf((*MockEmailService)(nil).SendOrderConfirmation), ctx, email, order, confirmation)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: inventory_reserver.go
+//
+// Generated by this command:
+//
+//	mockgen -source=inventory_reserver.go -destination=mocks/inventory_reserver.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockInventoryReserver is a mock of InventoryReserver interface.
+type MockInventoryReserver struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryReserverMockRecorder
+	isgomock struct{}
+}
+
+// MockInventoryReserverMockRecorder is the mock recorder for MockInventoryReserver.
+type MockInventoryReserverMockRecorder struct {
+	mock *MockInventoryReserver
+}
+
+// NewMockInventoryReserver creates a new mock instance.
+func NewMockInventoryReserver(ctrl *gomock.Controller) *MockInventoryReserver {
+	mock := &MockInventoryReserver{ctrl: ctrl}
+	mock.recorder = &MockInventoryReserverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInventoryReserver) EXPECT() *MockInventoryReserverMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockInventoryReserver) Confirm(ctx context.Context, orderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockInventoryReserverMockRecorder) Confirm(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockInventoryReserver)(nil).Confirm), ctx, orderID)
+}
+
+// Release mocks base method.
+func (m *MockInventoryReserver) Release(ctx context.Context, orderID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockInventoryReserverMockRecorder) Release(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockInventoryReserver)(nil).Release), ctx, orderID)
+}
+
+// Reserve mocks base method.
+func (m *MockInventoryReserver) Reserve(ctx context.Context, orderID string, items []*oteldemo.CartItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, orderID, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockInventoryReserverMockRecorder) Reserve(ctx, orderID, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockInventoryReserver)(nil).Reserve), ctx, orderID, items)
+}
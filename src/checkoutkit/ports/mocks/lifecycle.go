@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lifecycle.go
+//
+// Generated by this command:
+//
+//	mockgen -source=lifecycle.go -destination=mocks/lifecycle.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLifecycle is a mock of Lifecycle interface.
+type MockLifecycle struct {
+	ctrl     *gomock.Controller
+	recorder *MockLifecycleMockRecorder
+	isgomock struct{}
+}
+
+// MockLifecycleMockRecorder is the mock recorder for MockLifecycle.
+type MockLifecycleMockRecorder struct {
+	mock *MockLifecycle
+}
+
+// NewMockLifecycle creates a new mock instance.
+func NewMockLifecycle(ctrl *gomock.Controller) *MockLifecycle {
+	mock := &MockLifecycle{ctrl: ctrl}
+	mock.recorder = &MockLifecycleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLifecycle) EXPECT() *MockLifecycleMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockLifecycle) Close(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockLifecycleMockRecorder) Close(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockLifecycle)(nil).Close), ctx)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: order_compensator.go
+//
+// Generated by this command:
+//
+//	mockgen -source=order_compensator.go -destination=mocks/order_compensator.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderCompensator is a mock of OrderCompensator interface.
+type MockOrderCompensator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderCompensatorMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderCompensatorMockRecorder is the mock recorder for MockOrderCompensator.
+type MockOrderCompensatorMockRecorder struct {
+	mock *MockOrderCompensator
+}
+
+// NewMockOrderCompensator creates a new mock instance.
+func NewMockOrderCompensator(ctrl *gomock.Controller) *MockOrderCompensator {
+	mock := &MockOrderCompensator{ctrl: ctrl}
+	mock.recorder = &MockOrderCompensatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderCompensator) EXPECT() *MockOrderCompensatorMockRecorder {
+	return m.recorder
+}
+
+// PublishOrderFailed mocks base method.
+func (m *MockOrderCompensator) PublishOrderFailed(ctx context.Context, order ports.FailedOrder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishOrderFailed", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishOrderFailed indicates an expected call of PublishOrderFailed.
+func (mr *MockOrderCompensatorMockRecorder) PublishOrderFailed(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishOrderFailed", reflect.TypeOf((*MockOrderCompensator)(nil).PublishOrderFailed), ctx, order)
+}
+
+// RefundPayment mocks base method.
+func (m *MockOrderCompensator) RefundPayment(ctx context.Context, order ports.FailedOrder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundPayment", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefundPayment indicates an expected call of RefundPayment.
+func (mr *MockOrderCompensatorMockRecorder) RefundPayment(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundPayment", reflect.TypeOf((*MockOrderCompensator)(nil).RefundPayment), ctx, order)
+}
@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: order_confirmation_renderer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=order_confirmation_renderer.go -destination=mocks/order_confirmation_renderer.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderConfirmationRenderer is a mock of OrderConfirmationRenderer interface.
+type MockOrderConfirmationRenderer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderConfirmationRendererMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderConfirmationRendererMockRecorder is the mock recorder for MockOrderConfirmationRenderer.
+type MockOrderConfirmationRendererMockRecorder struct {
+	mock *MockOrderConfirmationRenderer
+}
+
+// NewMockOrderConfirmationRenderer creates a new mock instance.
+func NewMockOrderConfirmationRenderer(ctrl *gomock.Controller) *MockOrderConfirmationRenderer {
+	mock := &MockOrderConfirmationRenderer{ctrl: ctrl}
+	mock.recorder = &MockOrderConfirmationRendererMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderConfirmationRenderer) EXPECT() *MockOrderConfirmationRendererMockRecorder {
+	return m.recorder
+}
+
+// RenderOrderConfirmation mocks base method.
+func (m *MockOrderConfirmationRenderer) RenderOrderConfirmation(order *oteldemo.OrderResult) (ports.OrderConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderOrderConfirmation", order)
+	ret0, _ := ret[0].(ports.OrderConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderOrderConfirmation indicates an expected call of RenderOrderConfirmation.
+func (mr *MockOrderConfirmationRendererMockRecorder) RenderOrderConfirmation(order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderOrderConfirmation", reflect.TypeOf((*MockOrderConfirmationRenderer)(nil).RenderOrderConfirmation), order)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: order_event_handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=order_event_handler.go -destination=mocks/order_event_handler.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderEventHandler is a mock of OrderEventHandler interface.
+type MockOrderEventHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventHandlerMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderEventHandlerMockRecorder is the mock recorder for MockOrderEventHandler.
+type MockOrderEventHandlerMockRecorder struct {
+	mock *MockOrderEventHandler
+}
+
+// NewMockOrderEventHandler creates a new mock instance.
+func NewMockOrderEventHandler(ctrl *gomock.Controller) *MockOrderEventHandler {
+	mock := &MockOrderEventHandler{ctrl: ctrl}
+	mock.recorder = &MockOrderEventHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventHandler) EXPECT() *MockOrderEventHandlerMockRecorder {
+	return m.recorder
+}
+
+// HandleOrderCompleted mocks base method.
+func (m *MockOrderEventHandler) HandleOrderCompleted(ctx context.Context, order *oteldemo.OrderResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleOrderCompleted", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleOrderCompleted indicates an expected call of HandleOrderCompleted.
+func (mr *MockOrderEventHandlerMockRecorder) HandleOrderCompleted(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleOrderCompleted", reflect.TypeOf((*MockOrderEventHandler)(nil).HandleOrderCompleted), ctx, order)
+}
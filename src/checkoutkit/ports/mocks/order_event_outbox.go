@@ -0,0 +1,144 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: order_event_outbox.go
+//
+// Generated by this command:
+//
+//	mockgen -source=order_event_outbox.go -destination=mocks/order_event_outbox.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderEventBatchPublisher is a mock of OrderEventBatchPublisher interface.
+type MockOrderEventBatchPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventBatchPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderEventBatchPublisherMockRecorder is the mock recorder for MockOrderEventBatchPublisher.
+type MockOrderEventBatchPublisherMockRecorder struct {
+	mock *MockOrderEventBatchPublisher
+}
+
+// NewMockOrderEventBatchPublisher creates a new mock instance.
+func NewMockOrderEventBatchPublisher(ctrl *gomock.Controller) *MockOrderEventBatchPublisher {
+	mock := &MockOrderEventBatchPublisher{ctrl: ctrl}
+	mock.recorder = &MockOrderEventBatchPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventBatchPublisher) EXPECT() *MockOrderEventBatchPublisherMockRecorder {
+	return m.recorder
+}
+
+// PublishOrderEvents mocks base method.
+func (m *MockOrderEventBatchPublisher) PublishOrderEvents(ctx context.Context, events []ports.OrderEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishOrderEvents", ctx, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishOrderEvents indicates an expected call of PublishOrderEvents.
+func (mr *MockOrderEventBatchPublisherMockRecorder) PublishOrderEvents(ctx, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishOrderEvents", reflect.TypeOf((*MockOrderEventBatchPublisher)(nil).PublishOrderEvents), ctx, events)
+}
+
+// MockOrderEventOutbox is a mock of OrderEventOutbox interface.
+type MockOrderEventOutbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventOutboxMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderEventOutboxMockRecorder is the mock recorder for MockOrderEventOutbox.
+type MockOrderEventOutboxMockRecorder struct {
+	mock *MockOrderEventOutbox
+}
+
+// NewMockOrderEventOutbox creates a new mock instance.
+func NewMockOrderEventOutbox(ctrl *gomock.Controller) *MockOrderEventOutbox {
+	mock := &MockOrderEventOutbox{ctrl: ctrl}
+	mock.recorder = &MockOrderEventOutboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventOutbox) EXPECT() *MockOrderEventOutboxMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockOrderEventOutbox) Begin(orderID string) ports.UnitOfWork {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", orderID)
+	ret0, _ := ret[0].(ports.UnitOfWork)
+	return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockOrderEventOutboxMockRecorder) Begin(orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockOrderEventOutbox)(nil).Begin), orderID)
+}
+
+// MockUnitOfWork is a mock of UnitOfWork interface.
+type MockUnitOfWork struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnitOfWorkMockRecorder
+	isgomock struct{}
+}
+
+// MockUnitOfWorkMockRecorder is the mock recorder for MockUnitOfWork.
+type MockUnitOfWorkMockRecorder struct {
+	mock *MockUnitOfWork
+}
+
+// NewMockUnitOfWork creates a new mock instance.
+func NewMockUnitOfWork(ctrl *gomock.Controller) *MockUnitOfWork {
+	mock := &MockUnitOfWork{ctrl: ctrl}
+	mock.recorder = &MockUnitOfWorkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnitOfWork) EXPECT() *MockUnitOfWorkMockRecorder {
+	return m.recorder
+}
+
+// Commit mocks base method.
+func (m *MockUnitOfWork) Commit(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockUnitOfWorkMockRecorder) Commit(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockUnitOfWork)(nil).Commit), ctx)
+}
+
+// Record mocks base method.
+func (m *MockUnitOfWork) Record(event ports.OrderEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", event)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockUnitOfWorkMockRecorder) Record(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockUnitOfWork)(nil).Record), event)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: order_event_publisher.go
+//
+// Generated by this command:
+//
+//	mockgen -source=order_event_publisher.go -destination=mocks/order_event_publisher.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrderEventPublisher is a mock of OrderEventPublisher interface.
+type MockOrderEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockOrderEventPublisherMockRecorder is the mock recorder for MockOrderEventPublisher.
+type MockOrderEventPublisherMockRecorder struct {
+	mock *MockOrderEventPublisher
+}
+
+// NewMockOrderEventPublisher creates a new mock instance.
+func NewMockOrderEventPublisher(ctrl *gomock.Controller) *MockOrderEventPublisher {
+	mock := &MockOrderEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockOrderEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventPublisher) EXPECT() *MockOrderEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// PublishOrderCompleted mocks base method.
+func (m *MockOrderEventPublisher) PublishOrderCompleted(ctx context.Context, order *oteldemo.OrderResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishOrderCompleted", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishOrderCompleted indicates an expected call of PublishOrderCompleted.
+func (mr *MockOrderEventPublisherMockRecorder) PublishOrderCompleted(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishOrderCompleted", reflect.TypeOf((*MockOrderEventPublisher)(nil).PublishOrderCompleted), ctx, order)
+}
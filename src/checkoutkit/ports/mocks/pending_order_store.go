@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pending_order_store.go
+//
+// Generated by this command:
+//
+//	mockgen -source=pending_order_store.go -destination=mocks/pending_order_store.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPendingOrderStore is a mock of PendingOrderStore interface.
+type MockPendingOrderStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPendingOrderStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockPendingOrderStoreMockRecorder is the mock recorder for MockPendingOrderStore.
+type MockPendingOrderStoreMockRecorder struct {
+	mock *MockPendingOrderStore
+}
+
+// NewMockPendingOrderStore creates a new mock instance.
+func NewMockPendingOrderStore(ctrl *gomock.Controller) *MockPendingOrderStore {
+	mock := &MockPendingOrderStore{ctrl: ctrl}
+	mock.recorder = &MockPendingOrderStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPendingOrderStore) EXPECT() *MockPendingOrderStoreMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockPendingOrderStore) Complete(ctx context.Context, orderID string, result *oteldemo.OrderResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, orderID, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockPendingOrderStoreMockRecorder) Complete(ctx, orderID, result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockPendingOrderStore)(nil).Complete), ctx, orderID, result)
+}
+
+// Fail mocks base method.
+func (m *MockPendingOrderStore) Fail(ctx context.Context, orderID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, orderID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockPendingOrderStoreMockRecorder) Fail(ctx, orderID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockPendingOrderStore)(nil).Fail), ctx, orderID, reason)
+}
+
+// Get mocks base method.
+func (m *MockPendingOrderStore) Get(ctx context.Context, orderID string) (ports.PendingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, orderID)
+	ret0, _ := ret[0].(ports.PendingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPendingOrderStoreMockRecorder) Get(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPendingOrderStore)(nil).Get), ctx, orderID)
+}
+
+// ListPending mocks base method.
+func (m *MockPendingOrderStore) ListPending(ctx context.Context) ([]ports.PendingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPending", ctx)
+	ret0, _ := ret[0].([]ports.PendingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPending indicates an expected call of ListPending.
+func (mr *MockPendingOrderStoreMockRecorder) ListPending(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPending", reflect.TypeOf((*MockPendingOrderStore)(nil).ListPending), ctx)
+}
+
+// Save mocks base method.
+func (m *MockPendingOrderStore) Save(ctx context.Context, order ports.PendingOrder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockPendingOrderStoreMockRecorder) Save(ctx, order any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockPendingOrderStore)(nil).Save), ctx, order)
+}
@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: promotion_engine.go
+//
+// Generated by this command:
+//
+//	mockgen -source=promotion_engine.go -destination=mocks/promotion_engine.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPromotionEngine is a mock of PromotionEngine interface.
+type MockPromotionEngine struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionEngineMockRecorder
+	isgomock struct{}
+}
+
+// MockPromotionEngineMockRecorder is the mock recorder for MockPromotionEngine.
+type MockPromotionEngineMockRecorder struct {
+	mock *MockPromotionEngine
+}
+
+// NewMockPromotionEngine creates a new mock instance.
+func NewMockPromotionEngine(ctrl *gomock.Controller) *MockPromotionEngine {
+	mock := &MockPromotionEngine{ctrl: ctrl}
+	mock.recorder = &MockPromotionEngineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotionEngine) EXPECT() *MockPromotionEngineMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockPromotionEngine) Apply(ctx context.Context, userID string, items []*oteldemo.OrderItem) (ports.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, userID, items)
+	ret0, _ := ret[0].(ports.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockPromotionEngineMockRecorder) Apply(ctx, userID, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockPromotionEngine)(nil).Apply), ctx, userID, items)
+}
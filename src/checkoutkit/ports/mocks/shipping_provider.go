@@ -0,0 +1,180 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: shipping_provider.go
+//
+// Generated by this command:
+//
+//	mockgen -source=shipping_provider.go -destination=mocks/shipping_provider.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	oteldemo "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	ports "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/ports"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockShippingProvider is a mock of ShippingProvider interface.
+type MockShippingProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockShippingProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockShippingProviderMockRecorder is the mock recorder for MockShippingProvider.
+type MockShippingProviderMockRecorder struct {
+	mock *MockShippingProvider
+}
+
+// NewMockShippingProvider creates a new mock instance.
+func NewMockShippingProvider(ctrl *gomock.Controller) *MockShippingProvider {
+	mock := &MockShippingProvider{ctrl: ctrl}
+	mock.recorder = &MockShippingProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShippingProvider) EXPECT() *MockShippingProviderMockRecorder {
+	return m.recorder
+}
+
+// Quote mocks base method.
+func (m *MockShippingProvider) Quote(ctx context.Context, address *oteldemo.Address, items []*oteldemo.CartItem) (*oteldemo.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quote", ctx, address, items)
+	ret0, _ := ret[0].(*oteldemo.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote.
+func (mr *MockShippingProviderMockRecorder) Quote(ctx, address, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockShippingProvider)(nil).Quote), ctx, address, items)
+}
+
+// Ship mocks base method.
+func (m *MockShippingProvider) Ship(ctx context.Context, address *oteldemo.Address, items []*oteldemo.CartItem) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ship", ctx, address, items)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ship indicates an expected call of Ship.
+func (mr *MockShippingProviderMockRecorder) Ship(ctx, address, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ship", reflect.TypeOf((*MockShippingProvider)(nil).Ship), ctx, address, items)
+}
+
+// MockSurchargingShippingProvider is a mock of SurchargingShippingProvider interface.
+type MockSurchargingShippingProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockSurchargingShippingProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockSurchargingShippingProviderMockRecorder is the mock recorder for MockSurchargingShippingProvider.
+type MockSurchargingShippingProviderMockRecorder struct {
+	mock *MockSurchargingShippingProvider
+}
+
+// NewMockSurchargingShippingProvider creates a new mock instance.
+func NewMockSurchargingShippingProvider(ctrl *gomock.Controller) *MockSurchargingShippingProvider {
+	mock := &MockSurchargingShippingProvider{ctrl: ctrl}
+	mock.recorder = &MockSurchargingShippingProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSurchargingShippingProvider) EXPECT() *MockSurchargingShippingProviderMockRecorder {
+	return m.recorder
+}
+
+// Quote mocks base method.
+func (m *MockSurchargingShippingProvider) Quote(ctx context.Context, address *oteldemo.Address, items []*oteldemo.CartItem) (*oteldemo.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quote", ctx, address, items)
+	ret0, _ := ret[0].(*oteldemo.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quote indicates an expected call of Quote.
+func (mr *MockSurchargingShippingProviderMockRecorder) Quote(ctx, address, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quote", reflect.TypeOf((*MockSurchargingShippingProvider)(nil).Quote), ctx, address, items)
+}
+
+// Ship mocks base method.
+func (m *MockSurchargingShippingProvider) Ship(ctx context.Context, address *oteldemo.Address, items []*oteldemo.CartItem) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ship", ctx, address, items)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ship indicates an expected call of Ship.
+func (mr *MockSurchargingShippingProviderMockRecorder) Ship(ctx, address, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ship", reflect.TypeOf((*MockSurchargingShippingProvider)(nil).Ship), ctx, address, items)
+}
+
+// Surcharge mocks base method.
+func (m *MockSurchargingShippingProvider) Surcharge() *oteldemo.Money {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Surcharge")
+	ret0, _ := ret[0].(*oteldemo.Money)
+	return ret0
+}
+
+// Surcharge indicates an expected call of Surcharge.
+func (mr *MockSurchargingShippingProviderMockRecorder) Surcharge() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Surcharge", reflect.TypeOf((*MockSurchargingShippingProvider)(nil).Surcharge))
+}
+
+// MockShippingProviderRegistry is a mock of ShippingProviderRegistry interface.
+type MockShippingProviderRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockShippingProviderRegistryMockRecorder
+	isgomock struct{}
+}
+
+// MockShippingProviderRegistryMockRecorder is the mock recorder for MockShippingProviderRegistry.
+type MockShippingProviderRegistryMockRecorder struct {
+	mock *MockShippingProviderRegistry
+}
+
+// NewMockShippingProviderRegistry creates a new mock instance.
+func NewMockShippingProviderRegistry(ctrl *gomock.Controller) *MockShippingProviderRegistry {
+	mock := &MockShippingProviderRegistry{ctrl: ctrl}
+	mock.recorder = &MockShippingProviderRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShippingProviderRegistry) EXPECT() *MockShippingProviderRegistryMockRecorder {
+	return m.recorder
+}
+
+// Provider mocks base method.
+func (m *MockShippingProviderRegistry) Provider(method string) (ports.ShippingProvider, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Provider", method)
+	ret0, _ := ret[0].(ports.ShippingProvider)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Provider indicates an expected call of Provider.
+func (mr *MockShippingProviderRegistryMockRecorder) Provider(method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Provider", reflect.TypeOf((*MockShippingProviderRegistry)(nil).Provider), method)
+}
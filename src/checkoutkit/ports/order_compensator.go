@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// FailedOrder describes an order PlaceOrder gave up on part way through.
+type FailedOrder struct {
+	OrderID string
+	UserID  string
+	// TransactionID is the payment transaction, empty if the card was not
+	// charged
+	TransactionID string
+	Amount        *pb.Money
+	// Tender is how the refunded amount was paid, a card when empty
+	Tender TenderType
+	// Step is the step that failed, e.g. "charge" or "ship"
+	Step   string
+	Reason string
+	// Compensated reports whether every completed step was undone
+	Compensated bool
+	// OutOfStock are the products that could not be reserved, for orders that
+	// failed at the reserve step
+	OutOfStock []string
+}
+
+// OrderCompensator defines the port for undoing the side effects of an order
+// that failed after some of its steps completed, and for telling downstream
+// systems the order will not be fulfilled.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters call the payment service, a message broker or an operator queue
+type OrderCompensator interface {
+	// RefundPayment refunds the charge made for order.
+	RefundPayment(ctx context.Context, order FailedOrder) error
+
+	// PublishOrderFailed notifies downstream systems that order failed.
+	PublishOrderFailed(ctx context.Context, order FailedOrder) error
+}
@@ -0,0 +1,29 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// OrderConfirmation is the rendered confirmation email of an order.
+type OrderConfirmation struct {
+	Subject string
+	// Text is the plain text body
+	Text string
+	// HTML is the HTML body, with the order's values escaped
+	HTML string
+}
+
+// OrderConfirmationRenderer defines the port for rendering the confirmation
+// email of a placed order.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters render from templates, so the wording can change without the workflow
+type OrderConfirmationRenderer interface {
+	// RenderOrderConfirmation renders the confirmation of order.
+	RenderOrderConfirmation(order *pb.OrderResult) (OrderConfirmation, error)
+}
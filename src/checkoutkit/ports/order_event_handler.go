@@ -0,0 +1,30 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// OrderEventHandler defines the port for reacting to order completion events.
+// It is the consumer-side counterpart of OrderEventPublisher.
+//
+// In hexagonal architecture terms:
+// - This is a Primary Port (input port)
+// - Subscriber adapters decode messages from the transport and drive it
+// - Implementations contain the business reaction to a completed order
+type OrderEventHandler interface {
+	// HandleOrderCompleted processes a decoded order completion event.
+	//
+	// Parameters:
+	//   ctx: Context for cancellation and tracing
+	//   order: The decoded order details
+	//
+	// Returns:
+	//   error: Any error that occurred while handling the event
+	HandleOrderCompleted(ctx context.Context, order *pb.OrderResult) error
+}
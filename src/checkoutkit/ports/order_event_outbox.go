@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// OrderEventType is the kind of an event in the life of an order.
+type OrderEventType string
+
+const (
+	// OrderPlacedEvent is recorded once the order is priced and validated.
+	OrderPlacedEvent OrderEventType = "OrderPlaced"
+	// PaymentCapturedEvent is recorded once the card is charged.
+	PaymentCapturedEvent OrderEventType = "PaymentCaptured"
+	// OrderCompletedEvent is the OrderResult published by OrderEventPublisher.
+	OrderCompletedEvent OrderEventType = "OrderResult"
+	// OutOfStockEvent reports an order rejected because some of its products
+	// could not be reserved. Its Order carries only the items out of stock, and
+	// the product_ids attribute lists their product IDs.
+	OutOfStockEvent OrderEventType = "OutOfStock"
+	// LoyaltyPointsEarnedEvent is recorded once an order completed. Its Order
+	// carries only the order ID, and the customer_id and points attributes
+	// name the customer and the points they earned.
+	LoyaltyPointsEarnedEvent OrderEventType = "LoyaltyPointsEarned"
+	// OrderFailedEvent reports an order PlaceOrder gave up on. Its Order
+	// carries only the order ID, and the user_id, failed_step, reason and
+	// compensated attributes tell whose order failed, where, why and whether
+	// its completed steps were undone.
+	OrderFailedEvent OrderEventType = "OrderFailed"
+)
+
+// OrderEvent is one event of an order. Order is a snapshot of the order as of
+// the event, so that only OrderCompletedEvent carries a shipping tracking ID, and
+// Attributes hold the details the OrderResult message has no field for, such
+// as the payment transaction ID.
+type OrderEvent struct {
+	Type       OrderEventType
+	Order      *pb.OrderResult
+	Attributes map[string]string
+}
+
+// OrderEventBatchPublisher defines the port for publishing the events of one
+// order together: consumers see either all of them or none.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters publish the batch in a Kafka transaction, for example
+type OrderEventBatchPublisher interface {
+	// PublishOrderEvents publishes events atomically, in order.
+	PublishOrderEvents(ctx context.Context, events []OrderEvent) error
+}
+
+// OrderEventOutbox defines the port for recording the events of an order while
+// it is placed, and publishing them only once it completed. An order that fails
+// half-way therefore emits nothing, instead of an OrderPlaced and a
+// PaymentCaptured without their OrderResult.
+type OrderEventOutbox interface {
+	// Begin starts recording the events of the order with orderID.
+	Begin(orderID string) UnitOfWork
+}
+
+// UnitOfWork collects the events of one order until it is committed. A unit of
+// work that is never committed is discarded with all its events.
+type UnitOfWork interface {
+	// Record adds event to the unit of work.
+	Record(event OrderEvent)
+
+	// Commit stores the recorded events as one batch, to be published
+	// together. A unit of work is committed at most once.
+	Commit(ctx context.Context) error
+}
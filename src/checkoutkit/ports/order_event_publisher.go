@@ -5,9 +5,11 @@ package ports
 import (
 	"context"
 
-	pb "github.com/open-telemetry/opentelemetry-demo/src/checkout/genproto/oteldemo"
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
 )
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
 // OrderEventPublisher defines the port for publishing order completion events.
 // This is the interface that the core business logic depends on for notifying
 // downstream systems about completed orders.
@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// ErrInvalidPageToken is returned by OrderRepository.List for a page token it
+// did not issue.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// OrderRepository defines the port for keeping placed orders so that they can
+// be queried through GetOrder and ListOrders.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters keep the orders in memory or a database
+type OrderRepository interface {
+	// Save records order as placed by userID.
+	Save(ctx context.Context, userID string, order *pb.OrderResult) error
+
+	// Get returns the order userID placed with orderID, or ErrOrderNotFound.
+	Get(ctx context.Context, userID, orderID string) (*pb.OrderResult, error)
+
+	// List returns up to pageSize of userID's orders, newest first, starting
+	// at pageToken. The returned token fetches the next page and is empty on
+	// the last one.
+	List(ctx context.Context, userID string, pageSize int, pageToken string) ([]*pb.OrderResult, string, error)
+}
@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// TenderType is a way of paying for an order.
+type TenderType string
+
+const (
+	CardTender     TenderType = "card"
+	GiftCardTender TenderType = "gift_card"
+)
+
+// Tender is one way the customer pays for an order: Card is set for
+// CardTender and GiftCardCode for GiftCardTender.
+type Tender struct {
+	Type         TenderType
+	Card         *pb.CreditCardInfo
+	GiftCardCode string
+}
+
+// Payment is the part of an order paid with one tender.
+type Payment struct {
+	Type          TenderType
+	Amount        *pb.Money
+	TransactionID string
+}
+
+// PaymentService defines the port for charging the tenders of an order. An
+// order paid with split tenders is charged once per tender, gift cards first
+// and the card for the rest.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters call the demo payment service or a gift card ledger
+type PaymentService interface {
+	// Charge takes at most amount from tender and returns the payment made.
+	// Cards are charged the whole amount and gift cards at most their balance.
+	Charge(ctx context.Context, amount *pb.Money, tender Tender) (Payment, error)
+}
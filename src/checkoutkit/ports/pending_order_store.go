@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// ErrOrderNotFound is returned by PendingOrderStore for an unknown order ID.
+var ErrOrderNotFound = errors.New("order not found")
+
+// OrderStatus is the state of an order accepted by asynchronous PlaceOrder.
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderCompleted OrderStatus = "completed"
+	OrderFailed    OrderStatus = "failed"
+)
+
+// PendingOrder is an order accepted by asynchronous PlaceOrder. Result is set
+// once the order completed and Reason once it failed.
+type PendingOrder struct {
+	OrderID        string
+	Request        *pb.PlaceOrderRequest
+	ShippingMethod string
+	GiftCard       string
+	// ShippingInsurance is set for orders that asked for shipping insurance
+	ShippingInsurance bool
+	Status            OrderStatus
+	Result            *pb.OrderResult
+	Reason            string
+}
+
+// PendingOrderStore defines the port for keeping orders that PlaceOrder
+// accepted but has not completed yet, so that a background worker can complete
+// them and a restarted instance can resume them.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters keep the orders in memory or a database
+type PendingOrderStore interface {
+	// Save records a new pending order.
+	Save(ctx context.Context, order PendingOrder) error
+
+	// Complete records result as the outcome of the order.
+	Complete(ctx context.Context, orderID string, result *pb.OrderResult) error
+
+	// Fail records that the order failed for reason.
+	Fail(ctx context.Context, orderID string, reason string) error
+
+	// Get returns the order with orderID, or ErrOrderNotFound.
+	Get(ctx context.Context, orderID string) (PendingOrder, error)
+
+	// ListPending returns the orders that have neither completed nor failed.
+	ListPending(ctx context.Context) ([]PendingOrder, error)
+}
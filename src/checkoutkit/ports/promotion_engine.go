@@ -0,0 +1,40 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// Discount is a discount line of an order: what a promotion took off the
+// cost of one product.
+type Discount struct {
+	// Code identifies the promotion, e.g. "PERCENT_OFF"
+	Code      string
+	ProductID string
+	// Amount is taken off the whole line, every unit included
+	Amount *pb.Money
+}
+
+// Promotion is the outcome of applying promotions to the items of an order.
+type Promotion struct {
+	// Items are the order items with their adjusted unit costs
+	Items     []*pb.OrderItem
+	Discounts []Discount
+}
+
+// PromotionEngine defines the extension point where promotions adjust an
+// order before its OrderResult is built.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters apply configured rules or call a promotion service
+type PromotionEngine interface {
+	// Apply returns items with promotions applied, in the same order and
+	// currency. It must not modify items.
+	Apply(ctx context.Context, userID string, items []*pb.OrderItem) (Promotion, error)
+}
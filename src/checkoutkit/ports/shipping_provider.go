@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package ports
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+// ErrUnknownShippingMethod is returned by ShippingProviderRegistry.Provider for
+// a shipping method no provider is registered for.
+var ErrUnknownShippingMethod = errors.New("unknown shipping method")
+
+// ShippingProvider defines the port for quoting and shipping an order with one
+// shipping method.
+//
+// In hexagonal architecture terms:
+// - This is a Secondary Port (output port)
+// - Adapters call the demo shipping service or a carrier's API
+type ShippingProvider interface {
+	// Quote returns the cost of shipping items to address.
+	Quote(ctx context.Context, address *pb.Address, items []*pb.CartItem) (*pb.Money, error)
+
+	// Ship ships items to address and returns the tracking ID.
+	Ship(ctx context.Context, address *pb.Address, items []*pb.CartItem) (string, error)
+}
+
+// SurchargingShippingProvider is implemented by shipping providers whose
+// quotes include a flat surcharge, so that the shipping cost of an order can
+// be broken down into fees.
+type SurchargingShippingProvider interface {
+	ShippingProvider
+
+	// Surcharge returns the surcharge included in every quote.
+	Surcharge() *pb.Money
+}
+
+// ShippingFees breaks the shipping cost of an order down: the quote of the
+// shipping method without its surcharges, the optional shipping insurance,
+// and the surcharges. They add up to the shipping cost of the order.
+type ShippingFees struct {
+	Base       *pb.Money
+	Insurance  *pb.Money
+	Surcharges *pb.Money
+}
+
+// ShippingProviderRegistry selects the ShippingProvider of an order by the
+// shipping method the client chose.
+type ShippingProviderRegistry interface {
+	// Provider returns the provider of method, or of the default method when
+	// method is empty. It returns ErrUnknownShippingMethod for other methods.
+	Provider(method string) (ShippingProvider, error)
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package providerstate defines the provider states of the checkout contracts
+// in one place, for the consumer tests of every team and for the checkout's
+// provider verification alike. A consumer records the state of an interaction
+// with Given, and the provider binds a fixture to each state with Handlers, so
+// that a state name cannot drift between the two repositories:
+//
+//	p.AddMessage().GivenWithParameter(providerstate.OrderPlaced.Given())
+//
+//	verifyRequest.StateHandlers = providerstate.Handlers(
+//		providerstate.OrderPlaced.Bind(func(setup bool, params providerstate.Params) error { ... }),
+//	)
+package providerstate
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/pact-foundation/pact-go/v2/models"
+)
+
+// Params are the parameters of a provider state, by name.
+type Params map[string]any
+
+// String returns the parameter name as a string, or "" if it is not one.
+func (p Params) String(name string) string {
+	s, _ := p[name].(string)
+	return s
+}
+
+// State is a precondition the provider sets up before an interaction is
+// verified.
+type State struct {
+	// Name identifies the state in the pact files
+	Name string
+	// Params are the parameters of the state with their default values
+	Params Params
+}
+
+// Define returns a state with the given name and parameters, given as pairs
+// of a name and a default value.
+func Define(name string, params ...any) State {
+	if len(params)%2 != 0 {
+		panic(fmt.Sprintf("providerstate: odd number of parameters for %q", name))
+	}
+	s := State{Name: name, Params: Params{}}
+	for i := 0; i < len(params); i += 2 {
+		s.Params[params[i].(string)] = params[i+1]
+	}
+	return s
+}
+
+// With returns a copy of s with the parameter name set to value. It panics if
+// s has no such parameter, since the provider would not know it.
+func (s State) With(name string, value any) State {
+	if _, ok := s.Params[name]; !ok {
+		panic(fmt.Sprintf("providerstate: %q has no parameter %q", s.Name, name))
+	}
+	s.Params = maps.Clone(s.Params)
+	s.Params[name] = value
+	return s
+}
+
+// Given returns the state to record on a consumer interaction.
+func (s State) Given() models.ProviderState {
+	return models.ProviderState{Name: s.Name, Parameters: maps.Clone(s.Params)}
+}
+
+// Fixture sets up a state on the provider before an interaction is verified,
+// when setup is true, and tears it down afterwards. params are those the
+// consumer recorded, over the defaults of the state.
+type Fixture func(setup bool, params Params) error
+
+// Binding is a state with the fixture that sets it up.
+type Binding struct {
+	State   State
+	Fixture Fixture
+}
+
+// Bind returns a binding of fixture to s. A nil fixture means that s holds
+// without any setup.
+func (s State) Bind(fixture Fixture) Binding {
+	return Binding{State: s, Fixture: fixture}
+}
+
+// Handlers returns the state handlers of a provider verification for
+// bindings. It panics if two bindings share a state name.
+func Handlers(bindings ...Binding) models.StateHandlers {
+	handlers := models.StateHandlers{}
+	for _, b := range bindings {
+		if _, ok := handlers[b.State.Name]; ok {
+			panic(fmt.Sprintf("providerstate: %q is bound twice", b.State.Name))
+		}
+		handlers[b.State.Name] = func(setup bool, s models.ProviderState) (models.ProviderStateResponse, error) {
+			if b.Fixture == nil {
+				return nil, nil
+			}
+			params := maps.Clone(b.State.Params)
+			maps.Copy(params, s.Parameters)
+			return nil, b.Fixture(setup, params)
+		}
+	}
+	return handlers
+}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package providerstate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pact-foundation/pact-go/v2/models"
+)
+
+func TestStateNamesAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, s := range All {
+		if seen[s.Name] {
+			t.Errorf("state %q is defined twice", s.Name)
+		}
+		seen[s.Name] = true
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	var got Params
+	var gotSetup bool
+	handlers := Handlers(
+		OrderPlaced.Bind(func(setup bool, params Params) error {
+			gotSetup, got = setup, params
+			return nil
+		}),
+		NoOrders.Bind(func(bool, Params) error { return errors.New("repository down") }),
+		DependenciesAvailable.Bind(nil),
+	)
+
+	// The parameters the consumer recorded override the defaults
+	given := OrderPlaced.With("userId", "user-2").Given()
+	if _, err := handlers[OrderPlaced.Name](true, given); err != nil {
+		t.Fatalf("setting up %q = %v", OrderPlaced.Name, err)
+	}
+	if !gotSetup || got.String("userId") != "user-2" || got.String("orderId") != "order-12345-contract-test" {
+		t.Errorf("fixture got setup %t and %v, want user-2 and the default order", gotSetup, got)
+	}
+	if OrderPlaced.Params.String("userId") != "user-1" {
+		t.Errorf("With() changed the defaults of %q", OrderPlaced.Name)
+	}
+
+	if _, err := handlers[NoOrders.Name](true, models.ProviderState{Name: NoOrders.Name}); err == nil {
+		t.Errorf("setting up %q = nil, want the fixture's error", NoOrders.Name)
+	}
+	if _, err := handlers[DependenciesAvailable.Name](true, DependenciesAvailable.Given()); err != nil {
+		t.Errorf("setting up %q without a fixture = %v", DependenciesAvailable.Name, err)
+	}
+}
+
+func TestWithUnknownParameterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("With() of an unknown parameter did not panic")
+		}
+	}()
+	OrderPlaced.With("orderID", "order-1")
+}
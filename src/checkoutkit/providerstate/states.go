@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package providerstate
+
+// The provider states of the checkout service. Consumers written in another
+// language, such as the accounting service, take the names from here.
+var (
+	// OrderProcessed holds once an order was placed, before its order-result
+	// message is published.
+	OrderProcessed = Define("An order has been successfully processed")
+
+	// DependenciesAvailable holds when every service the checkout calls
+	// answers, so that an order can be placed.
+	DependenciesAvailable = Define("the checkout dependencies are available")
+
+	// OrderPlaced holds when the user has placed the order orderId.
+	OrderPlaced = Define("user-1 has placed order order-12345-contract-test",
+		"userId", "user-1",
+		"orderId", "order-12345-contract-test",
+	)
+
+	// NoOrders holds when the user has not placed any order.
+	NoOrders = Define("user-1 has placed no orders",
+		"userId", "user-1",
+	)
+
+	// OutOfStock holds when productId cannot be reserved.
+	OutOfStock = Define("OLJCESPC7Z is out of stock",
+		"productId", "OLJCESPC7Z",
+	)
+
+	// ShippingFails holds when the order of the user cannot be shipped
+	// after its card was charged.
+	ShippingFails = Define("shipping fails after user-1 paid by card",
+		"userId", "user-1",
+	)
+
+	// LoyaltyOrder holds when the user completed an order of quantity
+	// productId, which earns loyalty points.
+	LoyaltyOrder = Define("user-1 completed an order of 2 OLJCESPC7Z",
+		"userId", "user-1",
+		"productId", "OLJCESPC7Z",
+		"quantity", 2,
+	)
+
+	// EUROrder holds when the user pays in EUR and 1 USD is worth rate EUR.
+	EUROrder = Define("1 USD is 0.9 EUR and user-1 pays in EUR",
+		"userId", "user-1",
+		"currencyCode", "EUR",
+		"rate", 0.9,
+	)
+
+	// DiscountedOrder holds when productId is percentOff percent off.
+	DiscountedOrder = Define("OLJCESPC7Z is 10 percent off",
+		"productId", "OLJCESPC7Z",
+		"percentOff", 10,
+	)
+
+	// OrderPaidByCard holds when the user paid an order by card.
+	OrderPaidByCard = Define("user-1 paid an order by card",
+		"userId", "user-1",
+	)
+
+	// InsuredExpressOrder holds when the user paid an insured order shipped
+	// express by card.
+	InsuredExpressOrder = Define("user-1 paid an insured express order by card",
+		"userId", "user-1",
+	)
+)
+
+// All lists every provider state, so that tests can check that each one is
+// bound.
+var All = []State{
+	OrderProcessed,
+	DependenciesAvailable,
+	OrderPlaced,
+	NoOrders,
+	OutOfStock,
+	ShippingFails,
+	LoyaltyOrder,
+	EUROrder,
+	DiscountedOrder,
+	OrderPaidByCard,
+	InsuredExpressOrder,
+}
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// ToConsumerJSON converts a protobuf OrderResult to the JSON format that
+// consumers expect. This includes handling protobuf-specific serialization
+// quirks like int64 fields being serialized as strings.
+func ToConsumerJSON(orderResult *pb.OrderResult) (map[string]interface{}, error) {
+	// Use protobuf JSON marshaling with options that match consumer expectations
+	marshaler := protojson.MarshalOptions{
+		EmitUnpopulated: true,  // Include zero values like nanos:0
+		UseProtoNames:   false, // Use JSON names (camelCase)
+	}
+
+	jsonBytes, err := marshaler.Marshal(orderResult)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OrderResult to JSON: %w", err)
+	}
+
+	// Parse JSON into a map for further processing
+	var jsonObj map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &jsonObj); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON into map: %w", err)
+	}
+
+	// Fix protobuf int64 serialization issue: units fields come as strings but consumers expect integers
+	fixProtobufSerializationIssues(jsonObj)
+
+	return jsonObj, nil
+}
+
+// FromConsumerJSON parses consumer-format JSON back into an OrderResult.
+func FromConsumerJSON(data []byte) (*pb.OrderResult, error) {
+	order := &pb.OrderResult{}
+	if err := protojson.Unmarshal(data, order); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consumer JSON into OrderResult: %w", err)
+	}
+	return order, nil
+}
+
+// fixProtobufSerializationIssues converts protobuf int64 "units" fields from strings to integers
+// to match consumer expectations. This is necessary because protobuf serializes int64
+// as strings in JSON to prevent precision loss, but our consumers expect integers.
+func fixProtobufSerializationIssues(jsonObj map[string]interface{}) {
+	// Fix shipping cost units field
+	if shippingCost, ok := jsonObj["shippingCost"].(map[string]interface{}); ok {
+		if unitsStr, ok := shippingCost["units"].(string); ok {
+			if units := parseIntFromString(unitsStr); units != nil {
+				shippingCost["units"] = *units
+			}
+		}
+	}
+
+	// Fix order items cost units fields
+	if items, ok := jsonObj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if itemObj, ok := item.(map[string]interface{}); ok {
+				if cost, ok := itemObj["cost"].(map[string]interface{}); ok {
+					if unitsStr, ok := cost["units"].(string); ok {
+						if units := parseIntFromString(unitsStr); units != nil {
+							cost["units"] = *units
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// parseIntFromString safely converts a string to an integer, returning nil if conversion fails.
+// The integer is an int64 rather than an int, which would truncate units on 32-bit platforms.
+func parseIntFromString(s string) *int64 {
+	if val, err := json.Number(s).Int64(); err == nil {
+		return &val
+	}
+	return nil
+}
+
+// CheckConsumerTypes verifies that every field of a consumer-format JSON object
+// has the JSON type consumers expect for its protobuf kind: numbers for integer
+// fields, strings for string fields, objects and arrays for messages and
+// repeated fields. It returns an error naming the first mismatching path.
+func CheckConsumerTypes(md protoreflect.MessageDescriptor, jsonObj map[string]interface{}) error {
+	return checkMessage(md, jsonObj, "$")
+}
+
+func checkMessage(md protoreflect.MessageDescriptor, obj map[string]interface{}, path string) error {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		v, ok := obj[fd.JSONName()]
+		if !ok || v == nil {
+			continue
+		}
+		fieldPath := path + "." + fd.JSONName()
+		if fd.IsList() {
+			list, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s: expected array, got %T", fieldPath, v)
+			}
+			for j, elem := range list {
+				if err := checkValue(fd, elem, fmt.Sprintf("%s[%d]", fieldPath, j)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := checkValue(fd, v, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkValue(fd protoreflect.FieldDescriptor, v interface{}, path string) error {
+	var ok bool
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		var obj map[string]interface{}
+		if obj, ok = v.(map[string]interface{}); ok {
+			return checkMessage(fd.Message(), obj, path)
+		}
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind:
+		_, ok = v.(string)
+	case protoreflect.BoolKind:
+		_, ok = v.(bool)
+	default:
+		// Every remaining kind is numeric; json.Unmarshal yields float64 for
+		// numbers, while ToConsumerJSON may already have converted to int64
+		switch v.(type) {
+		case float64, int64:
+			ok = true
+		}
+	}
+	if !ok {
+		return fmt.Errorf("%s: unexpected JSON type %T for %s field", path, v, fd.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package serialization
+
+import (
+	"encoding/json"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func testOrder() *pb.OrderResult {
+	return testdata.NewOrder().
+		WithItem("SKU-1", 2, testdata.USD(3)).
+		WithShipping(testdata.Money("USD", 8, 500000000)).
+		Build()
+}
+
+func TestToConsumerJSONEmitsIntegerUnits(t *testing.T) {
+	jsonObj, err := ToConsumerJSON(testOrder())
+	if err != nil {
+		t.Fatalf("ToConsumerJSON() = %v", err)
+	}
+	if units, ok := jsonObj["shippingCost"].(map[string]interface{})["units"].(int64); !ok || units != 8 {
+		t.Errorf("shippingCost.units = %#v, want int64 8", jsonObj["shippingCost"].(map[string]interface{})["units"])
+	}
+	item := jsonObj["items"].([]interface{})[0].(map[string]interface{})
+	if units, ok := item["cost"].(map[string]interface{})["units"].(int64); !ok || units != 3 {
+		t.Errorf("items[0].cost.units = %#v, want int64 3", item["cost"].(map[string]interface{})["units"])
+	}
+	if err := CheckConsumerTypes(testOrder().ProtoReflect().Descriptor(), jsonObj); err != nil {
+		t.Errorf("CheckConsumerTypes() = %v", err)
+	}
+}
+
+func TestConsumerJSONRoundTrip(t *testing.T) {
+	order := testOrder()
+	jsonObj, err := ToConsumerJSON(order)
+	if err != nil {
+		t.Fatalf("ToConsumerJSON() = %v", err)
+	}
+	data, err := json.Marshal(jsonObj)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	got, err := FromConsumerJSON(data)
+	if err != nil {
+		t.Fatalf("FromConsumerJSON() = %v", err)
+	}
+	if !proto.Equal(got, order) {
+		t.Errorf("round trip = %v, want %v", got, order)
+	}
+}
+
+// TestToConsumerJSONSnapshots pins the consumer JSON of representative orders
+// in testdata/snapshots, so that any change to the format consumers match on
+// shows in review. Accept an intended change with go test -update.
+func TestToConsumerJSONSnapshots(t *testing.T) {
+	tests := []struct {
+		name  string
+		order *pb.OrderResult
+	}{
+		{"order_result", testOrder()},
+		{"order_result_without_items", testdata.NewOrder().Build()},
+		{"order_result_international", testdata.NewOrder().
+			WithAddress(&pb.Address{StreetAddress: "Königstraße 1", City: "München", Country: "DE", ZipCode: "80331"}).
+			WithItem("SKU-Ä", 1, testdata.Money("EUR", 0, 990000000)).
+			WithShipping(testdata.Money("EUR", 4, 500000000)).
+			Build()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonObj, err := ToConsumerJSON(tt.order)
+			if err != nil {
+				t.Fatalf("ToConsumerJSON() = %v", err)
+			}
+			testdata.MatchJSONSnapshot(t, tt.name, jsonObj)
+		})
+	}
+}
+
+func TestCheckConsumerTypesDetectsStringUnits(t *testing.T) {
+	// Plain protojson output still carries int64 units as strings
+	data, err := protojson.Marshal(testOrder())
+	if err != nil {
+		t.Fatalf("protojson.Marshal() = %v", err)
+	}
+	var jsonObj map[string]interface{}
+	if err := json.Unmarshal(data, &jsonObj); err != nil {
+		t.Fatalf("json.Unmarshal() = %v", err)
+	}
+
+	if err := CheckConsumerTypes(testOrder().ProtoReflect().Descriptor(), jsonObj); err == nil {
+		t.Error("CheckConsumerTypes() = nil, want error for string units")
+	}
+}
+
+// TestDiffJSONReportsRetypedFields checks that the diff of plain protojson
+// output against the consumer JSON names every field protojson retypes.
+func TestDiffJSONReportsRetypedFields(t *testing.T) {
+	want, err := ToConsumerJSON(testOrder())
+	if err != nil {
+		t.Fatalf("ToConsumerJSON() = %v", err)
+	}
+	data, err := (protojson.MarshalOptions{EmitUnpopulated: true}).Marshal(testOrder())
+	if err != nil {
+		t.Fatalf("protojson.Marshal() = %v", err)
+	}
+
+	diffs, err := testdata.DiffJSON(want, json.RawMessage(data))
+	if err != nil {
+		t.Fatalf("DiffJSON() = %v", err)
+	}
+	wantDiffs := []string{
+		`items[0].cost.units: got string "3", want number 3`,
+		`shippingCost.units: got string "8", want number 8`,
+	}
+	if !slices.Equal(diffs, wantDiffs) {
+		t.Errorf("DiffJSON() = %q, want %q", diffs, wantDiffs)
+	}
+}
+
+// consumerOrder is how consumers decode the consumer JSON format.
+type consumerOrder struct {
+	ShippingCost consumerMoney `json:"shippingCost"`
+	Items        []struct {
+		Cost consumerMoney `json:"cost"`
+	} `json:"items"`
+}
+
+type consumerMoney struct {
+	CurrencyCode string `json:"currencyCode"`
+	Units        int64  `json:"units"`
+	Nanos        int32  `json:"nanos"`
+}
+
+// TestConsumerJSONProperties runs random valid orders through the consumer
+// JSON format: every order keeps the JSON types the consumer contracts match
+// on after a trip over the wire, decodes into the consumers' types without
+// losing precision, and round-trips to the same order.
+func TestConsumerJSONProperties(t *testing.T) {
+	property := func(generated testdata.ValidOrder) bool {
+		order := generated.Order
+		jsonObj, err := ToConsumerJSON(order)
+		if err != nil {
+			t.Logf("ToConsumerJSON(%v) = %v", order, err)
+			return false
+		}
+		data, err := json.Marshal(jsonObj)
+		if err != nil {
+			t.Logf("json.Marshal(%v) = %v", jsonObj, err)
+			return false
+		}
+
+		var wire map[string]interface{}
+		if err := json.Unmarshal(data, &wire); err != nil {
+			t.Logf("json.Unmarshal(%s) = %v", data, err)
+			return false
+		}
+		if err := CheckConsumerTypes(order.ProtoReflect().Descriptor(), wire); err != nil {
+			t.Logf("CheckConsumerTypes(%s) = %v", data, err)
+			return false
+		}
+
+		var decoded consumerOrder
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Logf("decoding %s as a consumer = %v", data, err)
+			return false
+		}
+		if decoded.ShippingCost.Units != order.GetShippingCost().GetUnits() || len(decoded.Items) != len(order.GetItems()) {
+			t.Logf("consumer decoded %s as %+v", data, decoded)
+			return false
+		}
+		for i, item := range decoded.Items {
+			if item.Cost.Units != order.GetItems()[i].GetCost().GetUnits() || item.Cost.Nanos != order.GetItems()[i].GetCost().GetNanos() {
+				t.Logf("consumer decoded items[%d] of %s as %+v", i, data, item.Cost)
+				return false
+			}
+		}
+
+		got, err := FromConsumerJSON(data)
+		if err != nil || !proto.Equal(got, order) {
+			t.Logf("round trip of %v = %v, %v", order, got, err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 500, Rand: testdata.Rand(t)}); err != nil {
+		t.Error(err)
+	}
+}
+
+// FuzzToConsumerJSON converts any OrderResult the wire can carry to consumer
+// JSON and back. The seeds are valid orders, which the fuzzer mutates at the
+// protobuf level. Conversion must not fail for an order that decodes, must
+// keep the consumer types, and must round-trip.
+func FuzzToConsumerJSON(f *testing.F) {
+	f.Add([]byte(nil))
+	seeds := []*pb.OrderResult{testOrder(), testdata.NewOrder().Build()}
+	r := rand.New(rand.NewSource(1))
+	for range 8 {
+		seeds = append(seeds, testdata.RandomOrder(r, 4))
+	}
+	for _, order := range seeds {
+		data, err := proto.Marshal(order)
+		if err != nil {
+			f.Fatalf("proto.Marshal() = %v", err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		order := &pb.OrderResult{}
+		if err := (proto.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, order); err != nil {
+			t.Skip()
+		}
+		jsonObj, err := ToConsumerJSON(order)
+		if err != nil {
+			t.Fatalf("ToConsumerJSON(%v) = %v", order, err)
+		}
+		if err := CheckConsumerTypes(order.ProtoReflect().Descriptor(), jsonObj); err != nil {
+			t.Fatalf("CheckConsumerTypes(%v) = %v", jsonObj, err)
+		}
+		wire, err := json.Marshal(jsonObj)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v) = %v", jsonObj, err)
+		}
+		got, err := FromConsumerJSON(wire)
+		if err != nil || !proto.Equal(got, order) {
+			t.Fatalf("round trip of %v = %v, %v", order, got, err)
+		}
+	})
+}
+
+// FuzzFromConsumerJSON decodes malformed and mutated consumer JSON. Decoding
+// must not panic, must fail the same way each time, and must yield an order
+// that converts back to consumer JSON when it succeeds.
+func FuzzFromConsumerJSON(f *testing.F) {
+	valid, err := ToConsumerJSON(testOrder())
+	if err != nil {
+		f.Fatalf("ToConsumerJSON() = %v", err)
+	}
+	data, err := json.Marshal(valid)
+	if err != nil {
+		f.Fatalf("json.Marshal() = %v", err)
+	}
+	for _, seed := range []string{
+		string(data),
+		`{}`,
+		`{`,
+		`null`,
+		`[]`,
+		`{"orderId":1}`,
+		`{"items":"SKU-1"}`,
+		`{"shippingCost":{"units":"abc"}}`,
+		`{"shippingCost":{"units":1e300}}`,
+		`{"shippingCost":{"nanos":"1"}}`,
+		`{"unknownField":true}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		order, err := FromConsumerJSON(data)
+		_, again := FromConsumerJSON(data)
+		if (err == nil) != (again == nil) || (err != nil && err.Error() != again.Error()) {
+			t.Fatalf("FromConsumerJSON(%q) = %v, then %v", data, err, again)
+		}
+		if err != nil {
+			if !strings.HasPrefix(err.Error(), "failed to unmarshal consumer JSON into OrderResult: ") {
+				t.Fatalf("FromConsumerJSON(%q) = %v, want a wrapped decoding error", data, err)
+			}
+			return
+		}
+		if _, err := ToConsumerJSON(order); err != nil {
+			t.Fatalf("ToConsumerJSON(%v) decoded from %q = %v", order, data, err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testdata
+
+import (
+	"sync"
+	"time"
+)
+
+// Epoch is the instant a Clock starts at.
+var Epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a fake clock for the components that read the time through a now
+// function, as in store.now = clock.Now. It starts at Epoch rather than the
+// wall clock, so that times in golden files and contracts are the same on
+// every run, and only moves when the test advances it.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a clock stopped at Epoch.
+func NewClock() *Clock {
+	return &Clock{now: Epoch}
+}
+
+// Now returns the time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
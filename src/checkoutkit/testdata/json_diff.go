@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// MatchJSON fails t with a line per difference between the JSON of want and
+// got, such as:
+//
+//	items[0].cost.units: got string "3", want number 3
+//	shippingAddress.state: missing, want string "CA"
+//
+// Values are compared as JSON, so a map, a struct and consumer JSON from
+// serialization.ToConsumerJSON compare equal when they marshal alike, and
+// numbers compare by value whatever their Go type.
+func MatchJSON(t testing.TB, want, got any) {
+	t.Helper()
+	diffs, err := DiffJSON(want, got)
+	if err != nil {
+		t.Fatalf("DiffJSON() = %v", err)
+	}
+	if len(diffs) > 0 {
+		t.Errorf("JSON differs:\n%s", strings.Join(diffs, "\n"))
+	}
+}
+
+// DiffJSON returns the differences between the JSON of want and got by path,
+// sorted, or none if they are equal.
+func DiffJSON(want, got any) ([]string, error) {
+	w, err := normalizeJSON(want)
+	if err != nil {
+		return nil, fmt.Errorf("want: %w", err)
+	}
+	g, err := normalizeJSON(got)
+	if err != nil {
+		return nil, fmt.Errorf("got: %w", err)
+	}
+	var diffs []string
+	diffJSON("", w, g, &diffs)
+	slices.Sort(diffs)
+	return diffs, nil
+}
+
+// normalizeJSON round-trips v through JSON, keeping numbers as json.Number so
+// that int64 values compare without loss.
+func normalizeJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var n any
+	if err := dec.Decode(&n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func diffJSON(path string, want, got any, diffs *[]string) {
+	if kind(want) != kind(got) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", pathOrRoot(path), describe(got), describe(want)))
+		return
+	}
+	switch w := want.(type) {
+	case map[string]any:
+		g := got.(map[string]any)
+		for key, wv := range w {
+			gv, ok := g[key]
+			if !ok {
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing, want %s", join(path, key), describe(wv)))
+				continue
+			}
+			diffJSON(join(path, key), wv, gv, diffs)
+		}
+		for key, gv := range g {
+			if _, ok := w[key]; !ok {
+				*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want none", join(path, key), describe(gv)))
+			}
+		}
+	case []any:
+		g := got.([]any)
+		if len(w) != len(g) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: got %d elements, want %d", pathOrRoot(path), len(g), len(w)))
+		}
+		for i := range min(len(w), len(g)) {
+			diffJSON(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], diffs)
+		}
+	case json.Number:
+		wr, _ := new(big.Rat).SetString(w.String())
+		gr, _ := new(big.Rat).SetString(got.(json.Number).String())
+		if wr.Cmp(gr) != 0 {
+			*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", pathOrRoot(path), describe(got), describe(want)))
+		}
+	default:
+		if want != got {
+			*diffs = append(*diffs, fmt.Sprintf("%s: got %s, want %s", pathOrRoot(path), describe(got), describe(want)))
+		}
+	}
+}
+
+// kind is the JSON type of a normalized value.
+func kind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func describe(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case []any:
+		return fmt.Sprintf("array of %d elements", len(v))
+	case map[string]any:
+		return fmt.Sprintf("object of %d fields", len(v))
+	default:
+		return fmt.Sprintf("%s %v", kind(v), v)
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
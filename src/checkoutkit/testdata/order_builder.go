@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package testdata builds the orders of the unit and contract tests. Tests
+// state only what they depend on:
+//
+//	order := testdata.NewOrder().
+//		WithItem("SKU-1", 2, testdata.USD(3)).
+//		WithShipping(testdata.USD(5)).
+//		Build()
+//
+// The go tool skips testdata directories in package patterns such as ./...,
+// so the package is built and vetted through the tests importing it.
+package testdata
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// USD returns whole units of US dollars.
+func USD(units int64) *pb.Money {
+	return Money("USD", units, 0)
+}
+
+// Money returns units and nanos of currencyCode.
+func Money(currencyCode string, units int64, nanos int32) *pb.Money {
+	return &pb.Money{CurrencyCode: currencyCode, Units: units, Nanos: nanos}
+}
+
+// Address returns the shipping address of NewOrder.
+func Address() *pb.Address {
+	return &pb.Address{StreetAddress: "1 Main St", City: "Anytown", Country: "USA"}
+}
+
+// OrderBuilder builds an OrderResult. Its methods change the order being
+// built and return the builder, so that calls chain.
+type OrderBuilder struct {
+	order *pb.OrderResult
+}
+
+// NewOrder starts a valid order without items: order-1, tracked as trk-1,
+// shipped to Address for USD 0.
+func NewOrder() *OrderBuilder {
+	return &OrderBuilder{order: &pb.OrderResult{
+		OrderId:            "order-1",
+		ShippingTrackingId: "trk-1",
+		ShippingCost:       USD(0),
+		ShippingAddress:    Address(),
+	}}
+}
+
+// WithID sets the order ID.
+func (b *OrderBuilder) WithID(orderID string) *OrderBuilder {
+	b.order.OrderId = orderID
+	return b
+}
+
+// WithTrackingID sets the shipping tracking ID.
+func (b *OrderBuilder) WithTrackingID(trackingID string) *OrderBuilder {
+	b.order.ShippingTrackingId = trackingID
+	return b
+}
+
+// WithShipping sets the shipping cost.
+func (b *OrderBuilder) WithShipping(cost *pb.Money) *OrderBuilder {
+	b.order.ShippingCost = cost
+	return b
+}
+
+// WithAddress sets the shipping address.
+func (b *OrderBuilder) WithAddress(address *pb.Address) *OrderBuilder {
+	b.order.ShippingAddress = address
+	return b
+}
+
+// WithItem adds quantity of productID at a unit cost.
+func (b *OrderBuilder) WithItem(productID string, quantity int32, cost *pb.Money) *OrderBuilder {
+	b.order.Items = append(b.order.Items, &pb.OrderItem{
+		Item: &pb.CartItem{ProductId: productID, Quantity: quantity},
+		Cost: cost,
+	})
+	return b
+}
+
+// Build returns a copy of the order, so that the builder can go on to build
+// variants of it.
+func (b *OrderBuilder) Build() *pb.OrderResult {
+	return proto.Clone(b.order).(*pb.OrderResult)
+}
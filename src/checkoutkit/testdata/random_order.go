@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testdata
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// Values consumers might not expect, which RandomOrder picks from often.
+var (
+	edgeUnits   = []int64{0, 1, 1<<53 + 1, math.MaxInt32 + 1, math.MaxInt64}
+	edgeNanos   = []int32{0, 1, 10_000_000, 999_999_999}
+	currencies  = []string{"USD", "EUR", "JPY", "CHF"}
+	productIDs  = []string{"OLJCESPC7Z", "SKU-1", "商品-42", "ÖKO-✓", "🚀", "a\"b\\c", "<script>"}
+	streetNames = []string{"1 Main St", "Königstraße 1", "東京都千代田区1-1", "221B Baker St\nFlat 2", "‮reversed", ""}
+)
+
+// ValidOrder is a random OrderResult that passes validation. It implements
+// quick.Generator, so that testing/quick property tests take it as an
+// argument.
+type ValidOrder struct {
+	Order *pb.OrderResult
+}
+
+// Generate returns a ValidOrder with up to size items, and at most 8 so that
+// failing orders stay readable.
+func (ValidOrder) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(ValidOrder{Order: RandomOrder(r, min(size, 8))})
+}
+
+// RandomOrder returns a valid order with up to maxItems items, all in one
+// currency. Orders without items, the largest units and nanos, and non-ASCII
+// product IDs and addresses come up often.
+func RandomOrder(r *rand.Rand, maxItems int) *pb.OrderResult {
+	currency := pick(r, currencies)
+	b := NewOrder().
+		WithID(randomString(r, 1+r.Intn(40))).
+		WithTrackingID(randomString(r, 1+r.Intn(20))).
+		WithShipping(randomMoney(r, currency)).
+		WithAddress(&pb.Address{
+			StreetAddress: pick(r, streetNames),
+			City:          randomString(r, r.Intn(20)),
+			State:         randomString(r, r.Intn(3)),
+			Country:       randomString(r, r.Intn(4)),
+			ZipCode:       randomString(r, r.Intn(10)),
+		})
+	for range r.Intn(maxItems + 1) {
+		quantity := int32(1 + r.Intn(10))
+		if r.Intn(4) == 0 {
+			quantity = math.MaxInt32
+		}
+		b.WithItem(pick(r, productIDs), quantity, randomMoney(r, currency))
+	}
+	return b.Build()
+}
+
+// randomMoney returns a non-negative amount of currency.
+func randomMoney(r *rand.Rand, currency string) *pb.Money {
+	units, nanos := r.Int63n(1_000_000), r.Int31n(1_000_000_000)
+	if r.Intn(2) == 0 {
+		units = pick(r, edgeUnits)
+	}
+	if r.Intn(2) == 0 {
+		nanos = pick(r, edgeNanos)
+	}
+	return Money(currency, units, nanos)
+}
+
+// randomString returns n runes, mostly ASCII with some from other scripts.
+func randomString(r *rand.Rand, n int) string {
+	runes := make([]rune, n)
+	for i := range runes {
+		switch r.Intn(8) {
+		case 0:
+			runes[i] = rune(0x4e00 + r.Intn(0x5000)) // CJK
+		case 1:
+			runes[i] = rune(0x1f600 + r.Intn(0x50)) // emoji
+		default:
+			runes[i] = rune(' ' + r.Intn('~'-' '+1))
+		}
+	}
+	return string(runes)
+}
+
+func pick[T any](r *rand.Rand, values []T) T {
+	return values[r.Intn(len(values))]
+}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testdata
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// scenarioFiles are the named order scenarios, one per file, shared by the
+// consumer and provider sides of the contract tests.
+//
+//go:embed scenarios/*.yaml
+var scenarioFiles embed.FS
+
+// scenario is the YAML shape of an order scenario. Field names follow the
+// consumer JSON of an OrderResult.
+type scenario struct {
+	OrderID            string          `yaml:"orderId"`
+	ShippingTrackingID string          `yaml:"shippingTrackingId"`
+	ShippingCost       scenarioMoney   `yaml:"shippingCost"`
+	ShippingAddress    scenarioAddress `yaml:"shippingAddress"`
+	Items              []scenarioItem  `yaml:"items"`
+}
+
+type scenarioMoney struct {
+	CurrencyCode string `yaml:"currencyCode"`
+	Units        int64  `yaml:"units"`
+	Nanos        int32  `yaml:"nanos"`
+}
+
+type scenarioAddress struct {
+	StreetAddress string `yaml:"streetAddress"`
+	City          string `yaml:"city"`
+	State         string `yaml:"state"`
+	Country       string `yaml:"country"`
+	ZipCode       string `yaml:"zipCode"`
+}
+
+// scenarioItem is an item of a scenario. With Repeat set, it stands for that
+// many items whose product IDs are numbered from ProductID-1.
+type scenarioItem struct {
+	ProductID string        `yaml:"productId"`
+	Quantity  int32         `yaml:"quantity"`
+	Cost      scenarioMoney `yaml:"cost"`
+	Repeat    int           `yaml:"repeat"`
+}
+
+func (m scenarioMoney) money() *pb.Money {
+	return Money(m.CurrencyCode, m.Units, m.Nanos)
+}
+
+func (s scenario) order() *pb.OrderResult {
+	b := NewOrder().
+		WithID(s.OrderID).
+		WithTrackingID(s.ShippingTrackingID).
+		WithShipping(s.ShippingCost.money()).
+		WithAddress(&pb.Address{
+			StreetAddress: s.ShippingAddress.StreetAddress,
+			City:          s.ShippingAddress.City,
+			State:         s.ShippingAddress.State,
+			Country:       s.ShippingAddress.Country,
+			ZipCode:       s.ShippingAddress.ZipCode,
+		})
+	for _, item := range s.Items {
+		if item.Repeat == 0 {
+			b.WithItem(item.ProductID, item.Quantity, item.Cost.money())
+			continue
+		}
+		for i := 1; i <= item.Repeat; i++ {
+			b.WithItem(fmt.Sprintf("%s-%d", item.ProductID, i), item.Quantity, item.Cost.money())
+		}
+	}
+	return b.Build()
+}
+
+// loadScenarios parses every scenario file once, by the file name without
+// its extension. Unknown fields are errors, so that a typo does not silently
+// leave a field empty.
+var loadScenarios = sync.OnceValues(func() (map[string]scenario, error) {
+	paths, err := fs.Glob(scenarioFiles, "scenarios/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	scenarios := make(map[string]scenario, len(paths))
+	for _, p := range paths {
+		f, err := scenarioFiles.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		var s scenario
+		dec := yaml.NewDecoder(f)
+		dec.KnownFields(true)
+		err = dec.Decode(&s)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %w", p, err)
+		}
+		scenarios[strings.TrimSuffix(path.Base(p), ".yaml")] = s
+	}
+	return scenarios, nil
+})
+
+// ScenarioNames returns the names of the order scenarios in
+// testdata/scenarios, sorted.
+func ScenarioNames() []string {
+	scenarios, _ := loadScenarios()
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Scenario returns a new copy of the order of the named scenario in
+// testdata/scenarios, such as "happy_path", and fails t if there is none.
+func Scenario(t testing.TB, name string) *pb.OrderResult {
+	t.Helper()
+	scenarios, err := loadScenarios()
+	if err != nil {
+		t.Fatalf("failed to load the scenarios: %v", err)
+	}
+	s, ok := scenarios[name]
+	if !ok {
+		t.Fatalf("no scenario %q, want one of %v", name, ScenarioNames())
+	}
+	return s.order()
+}
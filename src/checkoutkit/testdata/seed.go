@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testdata
+
+import (
+	"flag"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// SeedEnv is the environment variable setting the seed of the random test
+// data, for when the test flags cannot be changed, such as in CI.
+const SeedEnv = "TESTDATA_SEED"
+
+var seedFlag = flag.Int64("seed", 0, "seed of the random test data, or 0 for "+SeedEnv+" or a new seed")
+
+// testSeed returns the seed of the random test data: -seed if set, else
+// TESTDATA_SEED, else a seed drawn once per test binary.
+var testSeed = sync.OnceValues(func() (int64, error) {
+	if *seedFlag != 0 {
+		return *seedFlag, nil
+	}
+	if env := os.Getenv(SeedEnv); env != "" {
+		return strconv.ParseInt(env, 10, 64)
+	}
+	return time.Now().UnixNano(), nil
+})
+
+// Rand returns a source of random test data seeded with -seed, TESTDATA_SEED
+// or a new seed, and logs the seed if t fails, so that a failure caused by
+// the data can be reproduced.
+func Rand(t testing.TB) *rand.Rand {
+	t.Helper()
+	seed, err := testSeed()
+	if err != nil {
+		t.Fatalf("invalid %s: %v", SeedEnv, err)
+	}
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("random test data seeded with %d, rerun with -seed %d or %s=%d", seed, seed, SeedEnv, seed)
+		}
+	})
+	return rand.New(rand.NewSource(seed))
+}
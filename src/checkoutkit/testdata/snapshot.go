@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package testdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Update makes MatchGolden and MatchJSONSnapshot rewrite the golden files
+// instead of comparing with them. Set it with go test -update.
+var Update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// MatchGolden compares got with the golden file testdata/name of the package
+// under test, or rewrites the file with -update. Golden files are committed,
+// so an accepted change shows in review.
+func MatchGolden(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("update %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("update %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v (run go test -update to create it)", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the output (run go test -update to accept it):\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// MatchJSONSnapshot marshals got as indented JSON, with the keys of objects
+// sorted, and compares it with the golden file testdata/snapshots/name.json.
+func MatchJSONSnapshot(t testing.TB, name string, got any) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal snapshot %s: %v", name, err)
+	}
+	MatchGolden(t, filepath.Join("snapshots", name+".json"), append(data, '\n'))
+}
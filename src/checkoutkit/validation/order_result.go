@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/money"
+)
+
+// Rule identifiers follow the protovalidate naming scheme so that violations
+// read the same way they would if the constraints lived in demo.proto.
+const (
+	RuleRequired         = "required"
+	RuleStringMinLen     = "string.min_len"
+	RuleCurrencyCode     = "string.pattern"
+	RuleMoneyValid       = "money.valid"
+	RuleMoneyNonNegative = "money.non_negative"
+	RuleMoneyCurrency    = "money.currency"
+	RuleMinItems         = "repeated.min_items"
+	RuleInt32Positive    = "int32.gt"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Violation describes a single constraint that a message failed.
+type Violation struct {
+	// Field is the proto field path, e.g. "items[0].cost.currency_code".
+	Field string
+	// Rule is the identifier of the failed constraint.
+	Rule string
+	// Message is a human readable description of the failure.
+	Message string
+}
+
+// Error is returned when a message fails validation. It carries every
+// violation found so callers can report all problems at once.
+type Error struct {
+	Violations []Violation
+
+	// message names the invalid message; empty means an order result
+	message string
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s: %s", v.Field, v.Message))
+	}
+	message := e.message
+	if message == "" {
+		message = "order result"
+	}
+	return "invalid " + message + ": " + strings.Join(parts, "; ")
+}
+
+// GRPCStatus reports e as an INVALID_ARGUMENT status carrying the violations
+// as a google.rpc.BadRequest detail, so that gRPC handlers can return an
+// *Error as is and clients can tell which fields to fix.
+func (e *Error) GRPCStatus() *status.Status {
+	details := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Message,
+			Reason:      v.Rule,
+		})
+	}
+	st := status.New(codes.InvalidArgument, e.Error())
+	if withDetails, err := st.WithDetails(details); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// ViolationsOf returns the violations carried by the BadRequest detail of st,
+// the inverse of GRPCStatus.
+func ViolationsOf(st *status.Status) []Violation {
+	var violations []Violation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				violations = append(violations, Violation{Field: v.GetField(), Rule: v.GetReason(), Message: v.GetDescription()})
+			}
+		}
+	}
+	return violations
+}
+
+// ValidateOrderResult checks that an OrderResult is safe to hand to consumers.
+// It returns nil if the order is valid and an *Error otherwise.
+func ValidateOrderResult(order *pb.OrderResult) error {
+	if order == nil {
+		return &Error{Violations: []Violation{{Field: "order", Rule: RuleRequired, Message: "order is required"}}}
+	}
+
+	var violations []Violation
+	if order.GetOrderId() == "" {
+		violations = append(violations, Violation{Field: "order_id", Rule: RuleStringMinLen, Message: "must not be empty"})
+	}
+	violations = append(violations, validateMoney("shipping_cost", order.GetShippingCost())...)
+	for i, item := range order.GetItems() {
+		violations = append(violations, validateMoney(fmt.Sprintf("items[%d].cost", i), item.GetCost())...)
+	}
+
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+func validateMoney(field string, m *pb.Money) []Violation {
+	if m == nil {
+		return []Violation{{Field: field, Rule: RuleRequired, Message: "must be set"}}
+	}
+
+	var violations []Violation
+	if !currencyCodePattern.MatchString(m.GetCurrencyCode()) {
+		violations = append(violations, Violation{
+			Field:   field + ".currency_code",
+			Rule:    RuleCurrencyCode,
+			Message: fmt.Sprintf("%q is not an ISO 4217 currency code", m.GetCurrencyCode()),
+		})
+	}
+	if !money.IsValid(m) {
+		violations = append(violations, Violation{Field: field, Rule: RuleMoneyValid, Message: "units and nanos have mismatched signs or nanos out of range"})
+	} else if money.IsNegative(m) {
+		violations = append(violations, Violation{Field: field, Rule: RuleMoneyNonNegative, Message: "must not be negative"})
+	}
+	return violations
+}
+
+// ValidateRequiredFields checks that every field consumers rely on is present.
+// Proto3 cannot express required fields, so decoders that want to reject
+// incomplete messages call this in addition to ValidateOrderResult.
+func ValidateRequiredFields(order *pb.OrderResult) error {
+	var violations []Violation
+	if order.GetShippingTrackingId() == "" {
+		violations = append(violations, Violation{Field: "shipping_tracking_id", Rule: RuleRequired, Message: "must be set"})
+	}
+	if order.GetShippingAddress() == nil {
+		violations = append(violations, Violation{Field: "shipping_address", Rule: RuleRequired, Message: "must be set"})
+	}
+	for i, item := range order.GetItems() {
+		if item.GetItem().GetProductId() == "" {
+			violations = append(violations, Violation{Field: fmt.Sprintf("items[%d].item.product_id", i), Rule: RuleRequired, Message: "must be set"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+	"github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/testdata"
+)
+
+func usd(u int64, n int32) *pb.Money { return &pb.Money{CurrencyCode: "USD", Units: u, Nanos: n} }
+
+func validOrder() *pb.OrderResult {
+	return testdata.NewOrder().
+		WithItem("SKU-1", 2, usd(3, 0)).
+		WithShipping(usd(8, 500000000)).
+		Build()
+}
+
+func TestValidateOrderResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(o *pb.OrderResult)
+		wantField string
+		wantRule  string
+	}{
+		{"valid", func(o *pb.OrderResult) {}, "", ""},
+		{"zero shipping", func(o *pb.OrderResult) { o.ShippingCost = usd(0, 0) }, "", ""},
+		{"empty order id", func(o *pb.OrderResult) { o.OrderId = "" }, "order_id", RuleStringMinLen},
+		{"missing shipping cost", func(o *pb.OrderResult) { o.ShippingCost = nil }, "shipping_cost", RuleRequired},
+		{"lowercase currency", func(o *pb.OrderResult) { o.ShippingCost.CurrencyCode = "usd" }, "shipping_cost.currency_code", RuleCurrencyCode},
+		{"empty currency", func(o *pb.OrderResult) { o.Items[0].Cost.CurrencyCode = "" }, "items[0].cost.currency_code", RuleCurrencyCode},
+		{"negative item cost", func(o *pb.OrderResult) { o.Items[0].Cost = usd(-1, 0) }, "items[0].cost", RuleMoneyNonNegative},
+		{"mismatched signs", func(o *pb.OrderResult) { o.ShippingCost = usd(1, -5) }, "shipping_cost", RuleMoneyValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.mutate(order)
+
+			err := ValidateOrderResult(order)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidateOrderResult() = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateOrderResult() = %v, want *Error", err)
+			}
+			if len(verr.Violations) != 1 {
+				t.Fatalf("got %d violations, want 1: %v", len(verr.Violations), verr)
+			}
+			if got := verr.Violations[0]; got.Field != tt.wantField || got.Rule != tt.wantRule {
+				t.Errorf("violation = %s/%s, want %s/%s", got.Field, got.Rule, tt.wantField, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestValidateOrderResultReportsAllViolations(t *testing.T) {
+	order := validOrder()
+	order.OrderId = ""
+	order.ShippingCost.CurrencyCode = "dollars"
+	order.Items[0].Cost = usd(-2, 0)
+
+	var verr *Error
+	if !errors.As(ValidateOrderResult(order), &verr) {
+		t.Fatal("expected *Error")
+	}
+	if len(verr.Violations) != 3 {
+		t.Errorf("got %d violations, want 3: %v", len(verr.Violations), verr)
+	}
+}
+
+func TestValidateOrderResultNil(t *testing.T) {
+	if ValidateOrderResult(nil) == nil {
+		t.Error("ValidateOrderResult(nil) = nil, want error")
+	}
+}
+
+func TestErrorGRPCStatus(t *testing.T) {
+	order := validOrder()
+	order.OrderId = ""
+	order.ShippingCost = nil
+
+	st := status.Convert(fmt.Errorf("publish: %w", ValidateOrderResult(order)))
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("Code() = %v, want %v", st.Code(), codes.InvalidArgument)
+	}
+	want := []Violation{
+		{Field: "order_id", Rule: RuleStringMinLen, Message: "must not be empty"},
+		{Field: "shipping_cost", Rule: RuleRequired, Message: "must be set"},
+	}
+	if got := ViolationsOf(st); !reflect.DeepEqual(got, want) {
+		t.Errorf("ViolationsOf() = %+v, want %+v", got, want)
+	}
+	if got := ViolationsOf(status.New(codes.InvalidArgument, "no details")); got != nil {
+		t.Errorf("ViolationsOf() = %+v, want none", got)
+	}
+}
+
+// TestValidateRandomOrders checks that the orders generated for property tests
+// are valid, so that the properties hold for orders consumers can receive.
+func TestValidateRandomOrders(t *testing.T) {
+	property := func(generated testdata.ValidOrder) bool {
+		if err := ValidateOrderResult(generated.Order); err != nil {
+			t.Logf("ValidateOrderResult(%v) = %v", generated.Order, err)
+			return false
+		}
+		if err := ValidateRequiredFields(generated.Order); err != nil {
+			t.Logf("ValidateRequiredFields(%v) = %v", generated.Order, err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 500, Rand: testdata.Rand(t)}); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestValidateScenarios checks that the shared order scenarios are valid, so
+// that the contract tests using them describe orders consumers can receive.
+func TestValidateScenarios(t *testing.T) {
+	names := testdata.ScenarioNames()
+	if len(names) == 0 {
+		t.Fatal("no scenarios in testdata/scenarios")
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			order := testdata.Scenario(t, name)
+			if err := ValidateOrderResult(order); err != nil {
+				t.Errorf("ValidateOrderResult() = %v", err)
+			}
+			if err := ValidateRequiredFields(order); err != nil {
+				t.Errorf("ValidateRequiredFields() = %v", err)
+			}
+		})
+	}
+}
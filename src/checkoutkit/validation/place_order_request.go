@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package validation
+
+import (
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// ValidatePlaceOrderRequest checks the fields PlaceOrder needs before it talks
+// to any downstream service. Asynchronous PlaceOrder calls it so that requests
+// that could never complete are rejected up front instead of failing in the
+// background. It returns nil if the request is valid and an *Error otherwise.
+func ValidatePlaceOrderRequest(req *pb.PlaceOrderRequest) error {
+	var violations []Violation
+	required := func(field string, set bool) {
+		if !set {
+			violations = append(violations, Violation{Field: field, Rule: RuleRequired, Message: "must be set"})
+		}
+	}
+	required("user_id", req.GetUserId() != "")
+	required("email", req.GetEmail() != "")
+	required("address", req.GetAddress() != nil)
+	if address := req.GetAddress(); address != nil {
+		required("address.street_address", address.GetStreetAddress() != "")
+		required("address.city", address.GetCity() != "")
+		required("address.country", address.GetCountry() != "")
+	}
+	required("credit_card", req.GetCreditCard() != nil)
+	if !currencyCodePattern.MatchString(req.GetUserCurrency()) {
+		violations = append(violations, Violation{
+			Field:   "user_currency",
+			Rule:    RuleCurrencyCode,
+			Message: "is not an ISO 4217 currency code",
+		})
+	}
+
+	if len(violations) > 0 {
+		return &Error{Violations: violations, message: "place order request"}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+func validPlaceOrderRequest() *pb.PlaceOrderRequest {
+	return &pb.PlaceOrderRequest{
+		UserId:       "user-1",
+		UserCurrency: "USD",
+		Email:        "someone@example.com",
+		Address:      &pb.Address{StreetAddress: "1 Main St", City: "Anytown", Country: "USA"},
+		CreditCard:   &pb.CreditCardInfo{CreditCardNumber: "4432-8015-6152-0454"},
+	}
+}
+
+func TestValidatePlaceOrderRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(r *pb.PlaceOrderRequest)
+		wantField string
+		wantRule  string
+	}{
+		{"valid", func(r *pb.PlaceOrderRequest) {}, "", ""},
+		{"missing user", func(r *pb.PlaceOrderRequest) { r.UserId = "" }, "user_id", RuleRequired},
+		{"missing email", func(r *pb.PlaceOrderRequest) { r.Email = "" }, "email", RuleRequired},
+		{"missing address", func(r *pb.PlaceOrderRequest) { r.Address = nil }, "address", RuleRequired},
+		{"missing city", func(r *pb.PlaceOrderRequest) { r.Address.City = "" }, "address.city", RuleRequired},
+		{"missing country", func(r *pb.PlaceOrderRequest) { r.Address.Country = "" }, "address.country", RuleRequired},
+		{"missing card", func(r *pb.PlaceOrderRequest) { r.CreditCard = nil }, "credit_card", RuleRequired},
+		{"lowercase currency", func(r *pb.PlaceOrderRequest) { r.UserCurrency = "usd" }, "user_currency", RuleCurrencyCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validPlaceOrderRequest()
+			tt.mutate(req)
+			err := ValidatePlaceOrderRequest(req)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidatePlaceOrderRequest() = %v, want nil", err)
+				}
+				return
+			}
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidatePlaceOrderRequest() = %v, want *Error", err)
+			}
+			if len(verr.Violations) != 1 || verr.Violations[0].Field != tt.wantField || verr.Violations[0].Rule != tt.wantRule {
+				t.Errorf("violations = %+v, want %s (%s)", verr.Violations, tt.wantField, tt.wantRule)
+			}
+			if !strings.HasPrefix(err.Error(), "invalid place order request: ") {
+				t.Errorf("Error() = %q, want it to name the request", err.Error())
+			}
+		})
+	}
+}
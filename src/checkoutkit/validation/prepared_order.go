@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package validation
+
+import (
+	"fmt"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+// ValidatePreparedOrder checks the items and shipping quote PlaceOrder
+// prepared from the cart before it charges the card: the cart is not empty,
+// every quantity is positive and every amount is a valid, non-negative amount
+// in the user's currency. Amounts in another currency could not be summed
+// into the order total. It returns nil if the order is valid and an *Error
+// otherwise.
+func ValidatePreparedOrder(userCurrency string, items []*pb.OrderItem, shippingCost *pb.Money) error {
+	var violations []Violation
+	if len(items) == 0 {
+		violations = append(violations, Violation{Field: "items", Rule: RuleMinItems, Message: "cart is empty"})
+	}
+	for i, item := range items {
+		if item.GetItem().GetQuantity() <= 0 {
+			violations = append(violations, Violation{
+				Field:   fmt.Sprintf("items[%d].item.quantity", i),
+				Rule:    RuleInt32Positive,
+				Message: "must be greater than 0",
+			})
+		}
+		violations = append(violations, validateAmount(fmt.Sprintf("items[%d].cost", i), item.GetCost(), userCurrency)...)
+	}
+	violations = append(violations, validateAmount("shipping_cost", shippingCost, userCurrency)...)
+
+	if len(violations) > 0 {
+		return &Error{Violations: violations, message: "order"}
+	}
+	return nil
+}
+
+// validateAmount checks m like validateMoney and that it is in currency.
+func validateAmount(field string, m *pb.Money, currency string) []Violation {
+	violations := validateMoney(field, m)
+	if len(violations) == 0 && m.GetCurrencyCode() != currency {
+		violations = append(violations, Violation{
+			Field:   field + ".currency_code",
+			Rule:    RuleMoneyCurrency,
+			Message: fmt.Sprintf("is %s, want the user currency %s", m.GetCurrencyCode(), currency),
+		})
+	}
+	return violations
+}
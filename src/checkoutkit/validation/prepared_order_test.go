@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/open-telemetry/opentelemetry-demo/src/checkoutkit/genproto/oteldemo"
+)
+
+func TestValidatePreparedOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money)
+		wantField string
+		wantRule  string
+	}{
+		{"valid", func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money) {
+			return items, shipping
+		}, "", ""},
+		{"empty cart", func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money) {
+			return nil, shipping
+		}, "items", RuleMinItems},
+		{"zero quantity", func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money) {
+			items[0].Item.Quantity = 0
+			return items, shipping
+		}, "items[0].item.quantity", RuleInt32Positive},
+		{"negative item cost", func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money) {
+			items[0].Cost = usd(-3, 0)
+			return items, shipping
+		}, "items[0].cost", RuleMoneyNonNegative},
+		{"item cost in another currency", func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money) {
+			items[0].Cost.CurrencyCode = "EUR"
+			return items, shipping
+		}, "items[0].cost.currency_code", RuleMoneyCurrency},
+		{"missing shipping cost", func(items []*pb.OrderItem, shipping *pb.Money) ([]*pb.OrderItem, *pb.Money) {
+			return items, nil
+		}, "shipping_cost", RuleRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			items, shipping := tt.mutate(order.Items, order.ShippingCost)
+			err := ValidatePreparedOrder("USD", items, shipping)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidatePreparedOrder() = %v, want nil", err)
+				}
+				return
+			}
+			var verr *Error
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidatePreparedOrder() = %v, want *Error", err)
+			}
+			if len(verr.Violations) != 1 || verr.Violations[0].Field != tt.wantField || verr.Violations[0].Rule != tt.wantRule {
+				t.Errorf("violations = %+v, want %s (%s)", verr.Violations, tt.wantField, tt.wantRule)
+			}
+		})
+	}
+}
@@ -36,13 +36,25 @@ def send_email(data)
   # create and start a manual span
   tracer = OpenTelemetry.tracer_provider.tracer('email')
   tracer.in_span("send_email") do |span|
-    Pony.mail(
-      to:       data.email,
-      from:     "noreply@example.com",
-      subject:  "Your confirmation email",
-      body:     erb(:confirmation, locals: { order: data.order }),
-      via:      :test
-    )
+    # checkout renders the confirmation; older callers only send the order
+    if data.html
+      Pony.mail(
+        to:        data.email,
+        from:      "noreply@example.com",
+        subject:   data.subject,
+        body:      data.text,
+        html_body: data.html,
+        via:       :test
+      )
+    else
+      Pony.mail(
+        to:       data.email,
+        from:     "noreply@example.com",
+        subject:  "Your confirmation email",
+        body:     erb(:confirmation, locals: { order: data.order }),
+        via:      :test
+      )
+    end
     span.set_attribute("app.email.recipient", data.email)
     puts "Order confirmation email sent to: #{data.email}"
   end